@@ -0,0 +1,49 @@
+package azureclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/advisor/armadvisor"
+)
+
+// ListAdvisorRecommendations retrieves the Azure Advisor recommendations of a subscription.
+// The filter is an Advisor OData filter over Category, ResourceGroup, ResourceId or RecommendationTypeGuid.
+func (c *AzureClient) ListAdvisorRecommendations(ctx context.Context, subscriptionID, filter string) ([]*armadvisor.ResourceRecommendationBase, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:advisorrecommendations:%s:%s", subscriptionID, filter)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if recommendations, ok := cached.([]*armadvisor.ResourceRecommendationBase); ok {
+			return recommendations, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &armadvisor.RecommendationsClientListOptions{}
+	if filter != "" {
+		options.Filter = to.Ptr(filter)
+	}
+
+	pager := clients.RecommendationsClient.NewListPager(options)
+	recommendations := []*armadvisor.ResourceRecommendationBase{}
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list advisor recommendations: %v", err)
+		}
+		recommendations = append(recommendations, page.Value...)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, recommendations)
+
+	return recommendations, nil
+}
@@ -4,30 +4,72 @@ package azureclient
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/query/azlogs"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/advisor/armadvisor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/applicationinsights/armapplicationinsights"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcehealth/armresourcehealth"
 )
 
 // SubscriptionClients contains Azure clients for a specific subscription.
 type SubscriptionClients struct {
-	SubscriptionID           string
-	ContainerServiceClient   *armcontainerservice.ManagedClustersClient
-	VNetClient               *armnetwork.VirtualNetworksClient
-	SubnetsClient            *armnetwork.SubnetsClient
-	RouteTableClient         *armnetwork.RouteTablesClient
-	NSGClient                *armnetwork.SecurityGroupsClient
-	LoadBalancerClient       *armnetwork.LoadBalancersClient
-	PrivateEndpointsClient   *armnetwork.PrivateEndpointsClient
-	VMSSClient               *armcompute.VirtualMachineScaleSetsClient
-	VMSSVMsClient            *armcompute.VirtualMachineScaleSetVMsClient
-	DiagnosticSettingsClient *armmonitor.DiagnosticSettingsClient
+	SubscriptionID             string
+	ContainerServiceClient     *armcontainerservice.ManagedClustersClient
+	AgentPoolsClient           *armcontainerservice.AgentPoolsClient
+	VNetClient                 *armnetwork.VirtualNetworksClient
+	SubnetsClient              *armnetwork.SubnetsClient
+	RouteTableClient           *armnetwork.RouteTablesClient
+	NSGClient                  *armnetwork.SecurityGroupsClient
+	LoadBalancerClient         *armnetwork.LoadBalancersClient
+	PrivateEndpointsClient     *armnetwork.PrivateEndpointsClient
+	PublicIPAddressesClient    *armnetwork.PublicIPAddressesClient
+	PublicIPPrefixesClient     *armnetwork.PublicIPPrefixesClient
+	NatGatewaysClient          *armnetwork.NatGatewaysClient
+	ApplicationGatewaysClient  *armnetwork.ApplicationGatewaysClient
+	PrivateDNSZonesClient      *armprivatedns.PrivateZonesClient
+	PrivateDNSRecordSetsClient *armprivatedns.RecordSetsClient
+	PrivateDNSLinksClient      *armprivatedns.VirtualNetworkLinksClient
+	NetworkInterfacesClient    *armnetwork.InterfacesClient
+	AzureFirewallsClient       *armnetwork.AzureFirewallsClient
+	FirewallPoliciesClient     *armnetwork.FirewallPoliciesClient
+	FirewallRuleGroupsClient   *armnetwork.FirewallPolicyRuleCollectionGroupsClient
+	VMSSClient                 *armcompute.VirtualMachineScaleSetsClient
+	VMSSVMsClient              *armcompute.VirtualMachineScaleSetVMsClient
+	DisksClient                *armcompute.DisksClient
+	UsageClient                *armcompute.UsageClient
+	ResourceSKUsClient         *armcompute.ResourceSKUsClient
+	DiagnosticSettingsClient   *armmonitor.DiagnosticSettingsClient
+	MetricsClient              *armmonitor.MetricsClient
+	MetricDefinitionsClient    *armmonitor.MetricDefinitionsClient
+	MetricNamespacesClient     *armmonitor.MetricNamespacesClient
+	ActivityLogsClient         *armmonitor.ActivityLogsClient
+	DCRAssociationsClient      *armmonitor.DataCollectionRuleAssociationsClient
+	DataCollectionRulesClient  *armmonitor.DataCollectionRulesClient
+	MonitorWorkspacesClient    *armmonitor.AzureMonitorWorkspacesClient
+	AvailabilityStatusesClient *armresourcehealth.AvailabilityStatusesClient
+	WorkspacesClient           *armoperationalinsights.WorkspacesClient
+	InsightsComponentsClient   *armapplicationinsights.ComponentsClient
+	ManagedIdentitiesClient    *armmsi.UserAssignedIdentitiesClient
+	KeyVaultsClient            *armkeyvault.VaultsClient
+	RegistriesClient           *armcontainerregistry.RegistriesClient
+	RecommendationsClient      *armadvisor.RecommendationsClient
 }
 
 // AzureClient represents an Azure API client that can handle multiple subscriptions.
@@ -40,6 +82,22 @@ type AzureClient struct {
 	credential *azidentity.DefaultAzureCredential
 	// Cache for Azure resources
 	cache *AzureCache
+	// Tenant-scoped Resource Graph client, created on first use
+	resourceGraphClient *armresourcegraph.Client
+	resourceGraphOnce   sync.Once
+	resourceGraphErr    error
+	// Lazily created client for the Log Analytics query API
+	logsClient     *azlogs.Client
+	logsClientOnce sync.Once
+	logsClientErr  error
+	// Lazily created client for the Application Insights query API used by classic components
+	appInsightsClient     *azcore.Client
+	appInsightsClientOnce sync.Once
+	appInsightsClientErr  error
+	// Lazily created client for the managed Prometheus query API
+	prometheusClient     *azcore.Client
+	prometheusClientOnce sync.Once
+	prometheusClientErr  error
 }
 
 // NewAzureClient creates a new Azure client using default credentials and the provided configuration.
@@ -83,6 +141,11 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 		return nil, fmt.Errorf("failed to create container service client for subscription %s: %v", subscriptionID, err)
 	}
 
+	agentPoolsClient, err := armcontainerservice.NewAgentPoolsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent pools client for subscription %s: %v", subscriptionID, err)
+	}
+
 	vnetClient, err := armnetwork.NewVirtualNetworksClient(subscriptionID, c.credential, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create virtual network client for subscription %s: %v", subscriptionID, err)
@@ -113,6 +176,61 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 		return nil, fmt.Errorf("failed to create private endpoints client for subscription %s: %v", subscriptionID, err)
 	}
 
+	publicIPAddressesClient, err := armnetwork.NewPublicIPAddressesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create public IP addresses client for subscription %s: %v", subscriptionID, err)
+	}
+
+	publicIPPrefixesClient, err := armnetwork.NewPublicIPPrefixesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create public IP prefixes client for subscription %s: %v", subscriptionID, err)
+	}
+
+	natGatewaysClient, err := armnetwork.NewNatGatewaysClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NAT gateways client for subscription %s: %v", subscriptionID, err)
+	}
+
+	applicationGatewaysClient, err := armnetwork.NewApplicationGatewaysClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create application gateways client for subscription %s: %v", subscriptionID, err)
+	}
+
+	privateDNSZonesClient, err := armprivatedns.NewPrivateZonesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create private DNS zones client for subscription %s: %v", subscriptionID, err)
+	}
+
+	privateDNSRecordSetsClient, err := armprivatedns.NewRecordSetsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create private DNS record sets client for subscription %s: %v", subscriptionID, err)
+	}
+
+	privateDNSLinksClient, err := armprivatedns.NewVirtualNetworkLinksClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create private DNS virtual network links client for subscription %s: %v", subscriptionID, err)
+	}
+
+	networkInterfacesClient, err := armnetwork.NewInterfacesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network interfaces client for subscription %s: %v", subscriptionID, err)
+	}
+
+	azureFirewallsClient, err := armnetwork.NewAzureFirewallsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure firewalls client for subscription %s: %v", subscriptionID, err)
+	}
+
+	firewallPoliciesClient, err := armnetwork.NewFirewallPoliciesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firewall policies client for subscription %s: %v", subscriptionID, err)
+	}
+
+	firewallRuleGroupsClient, err := armnetwork.NewFirewallPolicyRuleCollectionGroupsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firewall policy rule collection groups client for subscription %s: %v", subscriptionID, err)
+	}
+
 	vmssClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscriptionID, c.credential, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create VMSS client for subscription %s: %v", subscriptionID, err)
@@ -123,24 +241,138 @@ func (c *AzureClient) GetOrCreateClientsForSubscription(subscriptionID string) (
 		return nil, fmt.Errorf("failed to create VMSS VMs client for subscription %s: %v", subscriptionID, err)
 	}
 
+	disksClient, err := armcompute.NewDisksClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create disks client for subscription %s: %v", subscriptionID, err)
+	}
+
+	usageClient, err := armcompute.NewUsageClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute usage client for subscription %s: %v", subscriptionID, err)
+	}
+
+	resourceSKUsClient, err := armcompute.NewResourceSKUsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource SKUs client for subscription %s: %v", subscriptionID, err)
+	}
+
 	diagnosticSettingsClient, err := armmonitor.NewDiagnosticSettingsClient(c.credential, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create diagnostic settings client for subscription %s: %v", subscriptionID, err)
 	}
 
+	metricsClient, err := armmonitor.NewMetricsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client for subscription %s: %v", subscriptionID, err)
+	}
+
+	metricDefinitionsClient, err := armmonitor.NewMetricDefinitionsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric definitions client for subscription %s: %v", subscriptionID, err)
+	}
+
+	metricNamespacesClient, err := armmonitor.NewMetricNamespacesClient(c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metric namespaces client for subscription %s: %v", subscriptionID, err)
+	}
+
+	activityLogsClient, err := armmonitor.NewActivityLogsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity logs client for subscription %s: %v", subscriptionID, err)
+	}
+
+	dcrAssociationsClient, err := armmonitor.NewDataCollectionRuleAssociationsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data collection rule associations client for subscription %s: %v", subscriptionID, err)
+	}
+
+	dataCollectionRulesClient, err := armmonitor.NewDataCollectionRulesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data collection rules client for subscription %s: %v", subscriptionID, err)
+	}
+
+	monitorWorkspacesClient, err := armmonitor.NewAzureMonitorWorkspacesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Monitor workspaces client for subscription %s: %v", subscriptionID, err)
+	}
+
+	availabilityStatusesClient, err := armresourcehealth.NewAvailabilityStatusesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create availability statuses client for subscription %s: %v", subscriptionID, err)
+	}
+
+	workspacesClient, err := armoperationalinsights.NewWorkspacesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Log Analytics workspaces client for subscription %s: %v", subscriptionID, err)
+	}
+
+	insightsComponentsClient, err := armapplicationinsights.NewComponentsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Application Insights components client for subscription %s: %v", subscriptionID, err)
+	}
+
+	managedIdentitiesClient, err := armmsi.NewUserAssignedIdentitiesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create managed identities client for subscription %s: %v", subscriptionID, err)
+	}
+
+	keyVaultsClient, err := armkeyvault.NewVaultsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key vaults client for subscription %s: %v", subscriptionID, err)
+	}
+
+	registriesClient, err := armcontainerregistry.NewRegistriesClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container registries client for subscription %s: %v", subscriptionID, err)
+	}
+
+	recommendationsClient, err := armadvisor.NewRecommendationsClient(subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create advisor recommendations client for subscription %s: %v", subscriptionID, err)
+	}
+
 	// Create and store the clients
 	clients = &SubscriptionClients{
-		SubscriptionID:           subscriptionID,
-		ContainerServiceClient:   containerServiceClient,
-		VNetClient:               vnetClient,
-		SubnetsClient:            subnetsClient,
-		RouteTableClient:         routeTableClient,
-		NSGClient:                nsgClient,
-		LoadBalancerClient:       loadBalancerClient,
-		PrivateEndpointsClient:   privateEndpointsClient,
-		VMSSClient:               vmssClient,
-		VMSSVMsClient:            vmssVMsClient,
-		DiagnosticSettingsClient: diagnosticSettingsClient,
+		SubscriptionID:             subscriptionID,
+		ContainerServiceClient:     containerServiceClient,
+		AgentPoolsClient:           agentPoolsClient,
+		VNetClient:                 vnetClient,
+		SubnetsClient:              subnetsClient,
+		RouteTableClient:           routeTableClient,
+		NSGClient:                  nsgClient,
+		LoadBalancerClient:         loadBalancerClient,
+		PrivateEndpointsClient:     privateEndpointsClient,
+		PublicIPAddressesClient:    publicIPAddressesClient,
+		PublicIPPrefixesClient:     publicIPPrefixesClient,
+		NatGatewaysClient:          natGatewaysClient,
+		ApplicationGatewaysClient:  applicationGatewaysClient,
+		PrivateDNSZonesClient:      privateDNSZonesClient,
+		PrivateDNSRecordSetsClient: privateDNSRecordSetsClient,
+		PrivateDNSLinksClient:      privateDNSLinksClient,
+		NetworkInterfacesClient:    networkInterfacesClient,
+		AzureFirewallsClient:       azureFirewallsClient,
+		FirewallPoliciesClient:     firewallPoliciesClient,
+		FirewallRuleGroupsClient:   firewallRuleGroupsClient,
+		VMSSClient:                 vmssClient,
+		VMSSVMsClient:              vmssVMsClient,
+		DisksClient:                disksClient,
+		UsageClient:                usageClient,
+		ResourceSKUsClient:         resourceSKUsClient,
+		DiagnosticSettingsClient:   diagnosticSettingsClient,
+		MetricsClient:              metricsClient,
+		MetricDefinitionsClient:    metricDefinitionsClient,
+		MetricNamespacesClient:     metricNamespacesClient,
+		ActivityLogsClient:         activityLogsClient,
+		DCRAssociationsClient:      dcrAssociationsClient,
+		DataCollectionRulesClient:  dataCollectionRulesClient,
+		MonitorWorkspacesClient:    monitorWorkspacesClient,
+		AvailabilityStatusesClient: availabilityStatusesClient,
+		WorkspacesClient:           workspacesClient,
+		InsightsComponentsClient:   insightsComponentsClient,
+		ManagedIdentitiesClient:    managedIdentitiesClient,
+		KeyVaultsClient:            keyVaultsClient,
+		RegistriesClient:           registriesClient,
+		RecommendationsClient:      recommendationsClient,
 	}
 
 	c.clientsMap[subscriptionID] = clients
@@ -358,7 +590,7 @@ func (c *AzureClient) GetPrivateEndpointByID(ctx context.Context, privateEndpoin
 	}
 
 	// Validate that the resource type is actually a private endpoint
-	if parsedID.ResourceType.String() != "Microsoft.Network/privateEndpoints" {
+	if !strings.EqualFold(parsedID.ResourceType.String(), "Microsoft.Network/privateEndpoints") {
 		return nil, fmt.Errorf("invalid resource type: expected Microsoft.Network/privateEndpoints, got %s", parsedID.ResourceType.String())
 	}
 
@@ -394,6 +626,485 @@ func (c *AzureClient) GetVMSS(ctx context.Context, subscriptionID, resourceGroup
 	return vmss, nil
 }
 
+// GetPublicIPAddress retrieves information about the specified public IP address.
+func (c *AzureClient) GetPublicIPAddress(ctx context.Context, subscriptionID, resourceGroup, publicIPName string) (*armnetwork.PublicIPAddress, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:publicip:%s:%s:%s", subscriptionID, resourceGroup, publicIPName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if pip, ok := cached.(*armnetwork.PublicIPAddress); ok {
+			return pip, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.PublicIPAddressesClient.Get(ctx, resourceGroup, publicIPName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public IP address: %v", err)
+	}
+
+	pip := &resp.PublicIPAddress
+	// Store in cache
+	c.cache.Set(cacheKey, pip)
+
+	return pip, nil
+}
+
+// GetPublicIPPrefix retrieves information about the specified public IP prefix.
+func (c *AzureClient) GetPublicIPPrefix(ctx context.Context, subscriptionID, resourceGroup, prefixName string) (*armnetwork.PublicIPPrefix, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:publicipprefix:%s:%s:%s", subscriptionID, resourceGroup, prefixName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if prefix, ok := cached.(*armnetwork.PublicIPPrefix); ok {
+			return prefix, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.PublicIPPrefixesClient.Get(ctx, resourceGroup, prefixName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public IP prefix: %v", err)
+	}
+
+	prefix := &resp.PublicIPPrefix
+	// Store in cache
+	c.cache.Set(cacheKey, prefix)
+
+	return prefix, nil
+}
+
+// GetNatGateway retrieves information about the specified NAT gateway.
+func (c *AzureClient) GetNatGateway(ctx context.Context, subscriptionID, resourceGroup, natGatewayName string) (*armnetwork.NatGateway, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:natgateway:%s:%s:%s", subscriptionID, resourceGroup, natGatewayName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if natGateway, ok := cached.(*armnetwork.NatGateway); ok {
+			return natGateway, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.NatGatewaysClient.Get(ctx, resourceGroup, natGatewayName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAT gateway: %v", err)
+	}
+
+	natGateway := &resp.NatGateway
+	// Store in cache
+	c.cache.Set(cacheKey, natGateway)
+
+	return natGateway, nil
+}
+
+// GetApplicationGateway retrieves information about the specified application gateway.
+func (c *AzureClient) GetApplicationGateway(ctx context.Context, subscriptionID, resourceGroup, appGatewayName string) (*armnetwork.ApplicationGateway, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:applicationgateway:%s:%s:%s", subscriptionID, resourceGroup, appGatewayName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if appGateway, ok := cached.(*armnetwork.ApplicationGateway); ok {
+			return appGateway, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.ApplicationGatewaysClient.Get(ctx, resourceGroup, appGatewayName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application gateway: %v", err)
+	}
+
+	appGateway := &resp.ApplicationGateway
+	// Store in cache
+	c.cache.Set(cacheKey, appGateway)
+
+	return appGateway, nil
+}
+
+// GetPrivateDNSZone retrieves information about the specified private DNS zone.
+func (c *AzureClient) GetPrivateDNSZone(ctx context.Context, subscriptionID, resourceGroup, zoneName string) (*armprivatedns.PrivateZone, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:privatednszone:%s:%s:%s", subscriptionID, resourceGroup, zoneName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if zone, ok := cached.(*armprivatedns.PrivateZone); ok {
+			return zone, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.PrivateDNSZonesClient.Get(ctx, resourceGroup, zoneName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private DNS zone: %v", err)
+	}
+
+	zone := &resp.PrivateZone
+	// Store in cache
+	c.cache.Set(cacheKey, zone)
+
+	return zone, nil
+}
+
+// GetPrivateDNSRecordSet retrieves a record set of the specified private DNS zone.
+func (c *AzureClient) GetPrivateDNSRecordSet(ctx context.Context, subscriptionID, resourceGroup, zoneName string, recordType armprivatedns.RecordType, relativeName string) (*armprivatedns.RecordSet, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:privatednsrecordset:%s:%s:%s:%s:%s", subscriptionID, resourceGroup, zoneName, recordType, relativeName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if recordSet, ok := cached.(*armprivatedns.RecordSet); ok {
+			return recordSet, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.PrivateDNSRecordSetsClient.Get(ctx, resourceGroup, zoneName, recordType, relativeName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private DNS record set: %v", err)
+	}
+
+	recordSet := &resp.RecordSet
+	// Store in cache
+	c.cache.Set(cacheKey, recordSet)
+
+	return recordSet, nil
+}
+
+// ListPrivateDNSZoneLinks retrieves the virtual network links of the specified private DNS zone.
+func (c *AzureClient) ListPrivateDNSZoneLinks(ctx context.Context, subscriptionID, resourceGroup, zoneName string) ([]*armprivatedns.VirtualNetworkLink, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:privatednslinks:%s:%s:%s", subscriptionID, resourceGroup, zoneName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if links, ok := cached.([]*armprivatedns.VirtualNetworkLink); ok {
+			return links, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.PrivateDNSLinksClient.NewListPager(resourceGroup, zoneName, nil)
+	var links []*armprivatedns.VirtualNetworkLink
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list private DNS zone links: %v", err)
+		}
+		links = append(links, page.Value...)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, links)
+
+	return links, nil
+}
+
+// GetNetworkInterface retrieves information about the specified network interface.
+func (c *AzureClient) GetNetworkInterface(ctx context.Context, subscriptionID, resourceGroup, nicName string) (*armnetwork.Interface, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:networkinterface:%s:%s:%s", subscriptionID, resourceGroup, nicName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if nic, ok := cached.(*armnetwork.Interface); ok {
+			return nic, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.NetworkInterfacesClient.Get(ctx, resourceGroup, nicName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network interface: %v", err)
+	}
+
+	nic := &resp.Interface
+	// Store in cache
+	c.cache.Set(cacheKey, nic)
+
+	return nic, nil
+}
+
+// GetAzureFirewall retrieves information about the specified Azure Firewall.
+func (c *AzureClient) GetAzureFirewall(ctx context.Context, subscriptionID, resourceGroup, firewallName string) (*armnetwork.AzureFirewall, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:azurefirewall:%s:%s:%s", subscriptionID, resourceGroup, firewallName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if firewall, ok := cached.(*armnetwork.AzureFirewall); ok {
+			return firewall, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.AzureFirewallsClient.Get(ctx, resourceGroup, firewallName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure firewall: %v", err)
+	}
+
+	firewall := &resp.AzureFirewall
+	// Store in cache
+	c.cache.Set(cacheKey, firewall)
+
+	return firewall, nil
+}
+
+// ListAzureFirewalls retrieves all Azure Firewalls in the specified subscription.
+func (c *AzureClient) ListAzureFirewalls(ctx context.Context, subscriptionID string) ([]*armnetwork.AzureFirewall, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:azurefirewalls:%s", subscriptionID)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if firewalls, ok := cached.([]*armnetwork.AzureFirewall); ok {
+			return firewalls, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.AzureFirewallsClient.NewListAllPager(nil)
+	var firewalls []*armnetwork.AzureFirewall
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure firewalls: %v", err)
+		}
+		firewalls = append(firewalls, page.Value...)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, firewalls)
+
+	return firewalls, nil
+}
+
+// GetFirewallPolicy retrieves information about the specified firewall policy.
+func (c *AzureClient) GetFirewallPolicy(ctx context.Context, subscriptionID, resourceGroup, policyName string) (*armnetwork.FirewallPolicy, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:firewallpolicy:%s:%s:%s", subscriptionID, resourceGroup, policyName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if policy, ok := cached.(*armnetwork.FirewallPolicy); ok {
+			return policy, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.FirewallPoliciesClient.Get(ctx, resourceGroup, policyName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get firewall policy: %v", err)
+	}
+
+	policy := &resp.FirewallPolicy
+	// Store in cache
+	c.cache.Set(cacheKey, policy)
+
+	return policy, nil
+}
+
+// ListFirewallPolicyRuleCollectionGroups retrieves the rule collection groups of the specified firewall policy.
+func (c *AzureClient) ListFirewallPolicyRuleCollectionGroups(ctx context.Context, subscriptionID, resourceGroup, policyName string) ([]*armnetwork.FirewallPolicyRuleCollectionGroup, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:firewallrulegroups:%s:%s:%s", subscriptionID, resourceGroup, policyName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if groups, ok := cached.([]*armnetwork.FirewallPolicyRuleCollectionGroup); ok {
+			return groups, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.FirewallRuleGroupsClient.NewListPager(resourceGroup, policyName, nil)
+	var groups []*armnetwork.FirewallPolicyRuleCollectionGroup
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list firewall policy rule collection groups: %v", err)
+		}
+		groups = append(groups, page.Value...)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, groups)
+
+	return groups, nil
+}
+
+// GetManagedIdentity retrieves information about the specified user-assigned managed identity.
+func (c *AzureClient) GetManagedIdentity(ctx context.Context, subscriptionID, resourceGroup, identityName string) (*armmsi.Identity, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:managedidentity:%s:%s:%s", subscriptionID, resourceGroup, identityName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if identity, ok := cached.(*armmsi.Identity); ok {
+			return identity, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.ManagedIdentitiesClient.Get(ctx, resourceGroup, identityName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed identity: %v", err)
+	}
+
+	identity := &resp.Identity
+	// Store in cache
+	c.cache.Set(cacheKey, identity)
+
+	return identity, nil
+}
+
+// GetDisk retrieves information about the specified managed disk.
+func (c *AzureClient) GetDisk(ctx context.Context, subscriptionID, resourceGroup, diskName string) (*armcompute.Disk, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:disk:%s:%s:%s", subscriptionID, resourceGroup, diskName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if disk, ok := cached.(*armcompute.Disk); ok {
+			return disk, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.DisksClient.Get(ctx, resourceGroup, diskName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk: %v", err)
+	}
+
+	disk := &resp.Disk
+	// Store in cache
+	c.cache.Set(cacheKey, disk)
+
+	return disk, nil
+}
+
+// GetKeyVault retrieves information about the specified Key Vault.
+func (c *AzureClient) GetKeyVault(ctx context.Context, subscriptionID, resourceGroup, vaultName string) (*armkeyvault.Vault, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:keyvault:%s:%s:%s", subscriptionID, resourceGroup, vaultName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if vault, ok := cached.(*armkeyvault.Vault); ok {
+			return vault, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.KeyVaultsClient.Get(ctx, resourceGroup, vaultName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key vault: %v", err)
+	}
+
+	vault := &resp.Vault
+	// Store in cache
+	c.cache.Set(cacheKey, vault)
+
+	return vault, nil
+}
+
+// GetContainerRegistry retrieves information about the specified container registry.
+func (c *AzureClient) GetContainerRegistry(ctx context.Context, subscriptionID, resourceGroup, registryName string) (*armcontainerregistry.Registry, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:containerregistry:%s:%s:%s", subscriptionID, resourceGroup, registryName)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if registry, ok := cached.(*armcontainerregistry.Registry); ok {
+			return registry, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.RegistriesClient.Get(ctx, resourceGroup, registryName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container registry: %v", err)
+	}
+
+	registry := &resp.Registry
+	// Store in cache
+	c.cache.Set(cacheKey, registry)
+
+	return registry, nil
+}
+
 // Helper methods for working with resource IDs
 
 // GetResourceByID retrieves a resource by its full Azure resource ID.
@@ -405,26 +1116,53 @@ func (c *AzureClient) GetResourceByID(ctx context.Context, resourceID string) (i
 		return nil, fmt.Errorf("failed to parse resource ID: %v", err)
 	}
 
-	// Based on the resource type, call the appropriate method
-	switch parsed.ResourceType.String() {
-	case "Microsoft.ContainerService/managedClusters":
+	// Based on the resource type, call the appropriate method. Resource types are case-insensitive,
+	// and IDs returned by AKS or given by users vary in casing
+	switch strings.ToLower(parsed.ResourceType.String()) {
+	case "microsoft.containerservice/managedclusters":
 		return c.GetAKSCluster(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
-	case "Microsoft.Network/virtualNetworks":
+	case "microsoft.network/virtualnetworks":
 		return c.GetVirtualNetwork(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
-	case "Microsoft.Network/routeTables":
+	case "microsoft.network/routetables":
 		return c.GetRouteTable(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
-	case "Microsoft.Network/networkSecurityGroups":
+	case "microsoft.network/networksecuritygroups":
 		return c.GetNetworkSecurityGroup(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
-	case "Microsoft.Network/loadBalancers":
+	case "microsoft.network/loadbalancers":
 		return c.GetLoadBalancer(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
-	case "Microsoft.Network/virtualNetworks/subnets":
+	case "microsoft.network/virtualnetworks/subnets":
 		// For subnets, we need the VNet name from parent and subnet name
 		if parsed.Parent != nil {
 			return c.GetSubnet(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Parent.Name, parsed.Name)
 		}
 		return nil, fmt.Errorf("invalid subnet resource ID format: %s", resourceID)
-	case "Microsoft.Compute/virtualMachineScaleSets":
+	case "microsoft.compute/virtualmachinescalesets":
 		return c.GetVMSS(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.compute/disks":
+		return c.GetDisk(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/privateendpoints":
+		return c.GetPrivateEndpoint(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/publicipaddresses":
+		return c.GetPublicIPAddress(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/publicipprefixes":
+		return c.GetPublicIPPrefix(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/natgateways":
+		return c.GetNatGateway(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/applicationgateways":
+		return c.GetApplicationGateway(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/networkinterfaces":
+		return c.GetNetworkInterface(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/azurefirewalls":
+		return c.GetAzureFirewall(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/firewallpolicies":
+		return c.GetFirewallPolicy(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.network/privatednszones":
+		return c.GetPrivateDNSZone(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.managedidentity/userassignedidentities":
+		return c.GetManagedIdentity(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.keyvault/vaults":
+		return c.GetKeyVault(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	case "microsoft.containerregistry/registries":
+		return c.GetContainerRegistry(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
 	default:
 		return nil, fmt.Errorf("unsupported resource type: %s", parsed.ResourceType)
 	}
@@ -468,3 +1206,38 @@ func (c *AzureClient) GetDiagnosticSettings(ctx context.Context, subscriptionID,
 
 	return diagnosticSettings, nil
 }
+
+// QueryResourceGraph executes an Azure Resource Graph query against the given subscriptions.
+// Results are not cached because callers page through them with skip tokens.
+func (c *AzureClient) QueryResourceGraph(ctx context.Context, query string, subscriptions []string, top int32, skipToken string) (*armresourcegraph.QueryResponse, error) {
+	c.resourceGraphOnce.Do(func() {
+		c.resourceGraphClient, c.resourceGraphErr = armresourcegraph.NewClient(c.credential, nil)
+	})
+	if c.resourceGraphErr != nil {
+		return nil, fmt.Errorf("failed to create resource graph client: %v", c.resourceGraphErr)
+	}
+
+	subscriptionPtrs := make([]*string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscriptionPtrs = append(subscriptionPtrs, to.Ptr(subscription))
+	}
+
+	options := &armresourcegraph.QueryRequestOptions{
+		ResultFormat: to.Ptr(armresourcegraph.ResultFormatObjectArray),
+		Top:          to.Ptr(top),
+	}
+	if skipToken != "" {
+		options.SkipToken = to.Ptr(skipToken)
+	}
+
+	resp, err := c.resourceGraphClient.Resources(ctx, armresourcegraph.QueryRequest{
+		Query:         to.Ptr(query),
+		Subscriptions: subscriptionPtrs,
+		Options:       options,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resource graph: %v", err)
+	}
+
+	return &resp.QueryResponse, nil
+}
@@ -1,10 +1,18 @@
 package azureclient
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
 )
 
 func TestNewAzureClientWithConfigurableTimeout(t *testing.T) {
@@ -40,3 +48,83 @@ func TestNewAzureClientWithConfigurableTimeout(t *testing.T) {
 		t.Errorf("Expected custom cache timeout to be 5 minutes, got %v", customClient.cache.defaultTimeout)
 	}
 }
+
+func TestGetResourceByIDServesCachedResources(t *testing.T) {
+	client, err := NewAzureClient(config.NewConfig())
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	const prefix = "/subscriptions/sub/resourceGroups/rg/providers/"
+	tests := []struct {
+		name       string
+		resourceID string
+		cacheKey   string
+		resource   interface{}
+	}{
+		{"public IP", prefix + "Microsoft.Network/publicIPAddresses/pip", "resource:publicip:sub:rg:pip", &armnetwork.PublicIPAddress{}},
+		{"public IP prefix", prefix + "Microsoft.Network/publicIPPrefixes/ippre", "resource:publicipprefix:sub:rg:ippre", &armnetwork.PublicIPPrefix{}},
+		{"NAT gateway", prefix + "Microsoft.Network/natGateways/nat", "resource:natgateway:sub:rg:nat", &armnetwork.NatGateway{}},
+		{"application gateway", prefix + "Microsoft.Network/applicationGateways/agw", "resource:applicationgateway:sub:rg:agw", &armnetwork.ApplicationGateway{}},
+		{"private endpoint", prefix + "Microsoft.Network/privateEndpoints/pe", "resource:privateendpoint:sub:rg:pe", &armnetwork.PrivateEndpoint{}},
+		{"network interface", prefix + "Microsoft.Network/networkInterfaces/nic", "resource:networkinterface:sub:rg:nic", &armnetwork.Interface{}},
+		{"azure firewall", prefix + "Microsoft.Network/azureFirewalls/fw", "resource:azurefirewall:sub:rg:fw", &armnetwork.AzureFirewall{}},
+		{"firewall policy", prefix + "Microsoft.Network/firewallPolicies/policy", "resource:firewallpolicy:sub:rg:policy", &armnetwork.FirewallPolicy{}},
+		{"private DNS zone", prefix + "Microsoft.Network/privateDnsZones/privatelink.eastus.azmk8s.io", "resource:privatednszone:sub:rg:privatelink.eastus.azmk8s.io", &armprivatedns.PrivateZone{}},
+		{"managed identity", prefix + "Microsoft.ManagedIdentity/userAssignedIdentities/id", "resource:managedidentity:sub:rg:id", &armmsi.Identity{}},
+		{"disk", prefix + "Microsoft.Compute/disks/osdisk", "resource:disk:sub:rg:osdisk", &armcompute.Disk{}},
+		{"key vault", prefix + "Microsoft.KeyVault/vaults/kv", "resource:keyvault:sub:rg:kv", &armkeyvault.Vault{}},
+		{"container registry", prefix + "Microsoft.ContainerRegistry/registries/acr", "resource:containerregistry:sub:rg:acr", &armcontainerregistry.Registry{}},
+		{"private DNS zone with upper-case type", prefix + "Microsoft.Network/privateDNSZones/privatelink.westus.azmk8s.io", "resource:privatednszone:sub:rg:privatelink.westus.azmk8s.io", &armprivatedns.PrivateZone{}},
+		{"lower-case provider", prefix + "microsoft.network/publicipaddresses/pip2", "resource:publicip:sub:rg:pip2", &armnetwork.PublicIPAddress{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.cache.Set(tt.cacheKey, tt.resource)
+
+			got, err := client.GetResourceByID(context.Background(), tt.resourceID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.resource {
+				t.Errorf("Expected cached resource %T to be returned, got %T", tt.resource, got)
+			}
+		})
+	}
+}
+
+func TestGetResourceByIDUnsupportedType(t *testing.T) {
+	client, err := NewAzureClient(config.NewConfig())
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	_, err = client.GetResourceByID(context.Background(), "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Web/sites/app")
+	if err == nil || !strings.Contains(err.Error(), "unsupported resource type") {
+		t.Errorf("Expected unsupported resource type error, got %v", err)
+	}
+}
+
+func TestGetPrivateEndpointByIDIgnoresTypeCase(t *testing.T) {
+	client, err := NewAzureClient(config.NewConfig())
+	if err != nil {
+		t.Fatalf("Failed to create Azure client: %v", err)
+	}
+
+	pe := &armnetwork.PrivateEndpoint{}
+	client.cache.Set("resource:privateendpoint:sub:rg:pe", pe)
+
+	got, err := client.GetPrivateEndpointByID(context.Background(), "/subscriptions/sub/resourceGroups/rg/providers/microsoft.network/privateendpoints/pe")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != pe {
+		t.Errorf("Expected the cached private endpoint to be returned")
+	}
+
+	_, err = client.GetPrivateEndpointByID(context.Background(), "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip")
+	if err == nil || !strings.Contains(err.Error(), "invalid resource type") {
+		t.Errorf("Expected invalid resource type error, got %v", err)
+	}
+}
@@ -0,0 +1,527 @@
+package azureclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/query/azlogs"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/applicationinsights/armapplicationinsights"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcehealth/armresourcehealth"
+)
+
+// Endpoints of the Azure Monitor query APIs that have no SDK client
+const (
+	prometheusScope     = "https://prometheus.monitor.azure.com/.default"
+	appInsightsEndpoint = "https://api.applicationinsights.io"
+	appInsightsScope    = "https://api.applicationinsights.io/.default"
+	// maxLogsServerTimeout is the longest server-side timeout the Log Analytics query API accepts
+	maxLogsServerTimeout = 10 * time.Minute
+	moduleName           = "aks-mcp"
+	moduleVersion        = "v0.0.0"
+)
+
+// LogsRecords converts the rows of the primary result table of a logs query into records keyed by column name
+func LogsRecords(results *azlogs.QueryResults) []map[string]interface{} {
+	records := []map[string]interface{}{}
+	if results == nil || len(results.Tables) == 0 || results.Tables[0] == nil {
+		return records
+	}
+
+	table := results.Tables[0]
+	for _, row := range table.Rows {
+		record := make(map[string]interface{}, len(table.Columns))
+		for i, column := range table.Columns {
+			if i < len(row) && column != nil && column.Name != nil {
+				record[*column.Name] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// ListMetrics retrieves metric values for the specified resource.
+// Results are not cached because metric values change continuously.
+func (c *AzureClient) ListMetrics(ctx context.Context, subscriptionID, resourceURI string, options *armmonitor.MetricsClientListOptions) (*armmonitor.Response, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.MetricsClient.List(ctx, resourceURI, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metrics: %v", err)
+	}
+
+	return &resp.Response, nil
+}
+
+// ListMetricDefinitions retrieves the metric definitions available for the specified resource.
+func (c *AzureClient) ListMetricDefinitions(ctx context.Context, subscriptionID, resourceURI, namespace string) ([]*armmonitor.MetricDefinition, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:metricdefinitions:%s:%s:%s", subscriptionID, resourceURI, namespace)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if definitions, ok := cached.([]*armmonitor.MetricDefinition); ok {
+			return definitions, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &armmonitor.MetricDefinitionsClientListOptions{}
+	if namespace != "" {
+		options.Metricnamespace = to.Ptr(namespace)
+	}
+
+	pager := clients.MetricDefinitionsClient.NewListPager(resourceURI, options)
+	var definitions []*armmonitor.MetricDefinition
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list metric definitions: %v", err)
+		}
+		definitions = append(definitions, page.Value...)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, definitions)
+
+	return definitions, nil
+}
+
+// ListMetricNamespaces retrieves the metric namespaces available for the specified resource.
+func (c *AzureClient) ListMetricNamespaces(ctx context.Context, subscriptionID, resourceURI, startTime string) ([]*armmonitor.MetricNamespace, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:metricnamespaces:%s:%s:%s", subscriptionID, resourceURI, startTime)
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if namespaces, ok := cached.([]*armmonitor.MetricNamespace); ok {
+			return namespaces, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &armmonitor.MetricNamespacesClientListOptions{}
+	if startTime != "" {
+		options.StartTime = to.Ptr(startTime)
+	}
+
+	pager := clients.MetricNamespacesClient.NewListPager(resourceURI, options)
+	var namespaces []*armmonitor.MetricNamespace
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list metric namespaces: %v", err)
+		}
+		namespaces = append(namespaces, page.Value...)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, namespaces)
+
+	return namespaces, nil
+}
+
+// ListActivityLogs retrieves activity log events matching the OData filter and the optional include
+// predicate, following next links until maxEvents events have been collected. A maxEvents of zero means no limit.
+func (c *AzureClient) ListActivityLogs(ctx context.Context, subscriptionID, filter string, include func(*armmonitor.EventData) bool, maxEvents int) ([]*armmonitor.EventData, error) {
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := clients.ActivityLogsClient.NewListPager(filter, nil)
+	events := []*armmonitor.EventData{}
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list activity logs: %v", err)
+		}
+		for _, event := range page.Value {
+			if include != nil && !include(event) {
+				continue
+			}
+			events = append(events, event)
+			if maxEvents > 0 && len(events) >= maxEvents {
+				return events, nil
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// ListDataCollectionRulesForResource retrieves the data collection rules associated with a resource,
+// such as the Container Insights and managed Prometheus rules of an AKS cluster. Rules may live in
+// another resource group or subscription than the resource.
+func (c *AzureClient) ListDataCollectionRulesForResource(ctx context.Context, subscriptionID, resourceURI string) ([]*armmonitor.DataCollectionRuleResource, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:datacollectionrules:%s", strings.ToLower(resourceURI))
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if rules, ok := cached.([]*armmonitor.DataCollectionRuleResource); ok {
+			return rules, nil
+		}
+	}
+
+	clients, err := c.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ruleIDs []string
+	pager := clients.DCRAssociationsClient.NewListByResourcePager(resourceURI, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list data collection rule associations: %v", err)
+		}
+		for _, association := range page.Value {
+			if association != nil && association.Properties != nil && association.Properties.DataCollectionRuleID != nil {
+				ruleIDs = append(ruleIDs, *association.Properties.DataCollectionRuleID)
+			}
+		}
+	}
+
+	rules := []*armmonitor.DataCollectionRuleResource{}
+	for _, ruleID := range ruleIDs {
+		id, err := arm.ParseResourceID(ruleID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data collection rule ID %s: %v", ruleID, err)
+		}
+		ruleClients, err := c.GetOrCreateClientsForSubscription(id.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		rule, err := ruleClients.DataCollectionRulesClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data collection rule %s: %v", id.Name, err)
+		}
+		rules = append(rules, &rule.DataCollectionRuleResource)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, rules)
+
+	return rules, nil
+}
+
+// GetPrometheusQueryEndpoint retrieves the Prometheus query endpoint of an Azure Monitor workspace
+func (c *AzureClient) GetPrometheusQueryEndpoint(ctx context.Context, workspaceResourceID string) (string, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:prometheusqueryendpoint:%s", strings.ToLower(workspaceResourceID))
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if endpoint, ok := cached.(string); ok {
+			return endpoint, nil
+		}
+	}
+
+	id, err := arm.ParseResourceID(workspaceResourceID)
+	if err != nil {
+		return "", fmt.Errorf("invalid Azure Monitor workspace ID %s: %v", workspaceResourceID, err)
+	}
+	clients, err := c.GetOrCreateClientsForSubscription(id.SubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	workspace, err := clients.MonitorWorkspacesClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Azure Monitor workspace %s: %v", id.Name, err)
+	}
+	if workspace.Properties == nil || workspace.Properties.Metrics == nil || workspace.Properties.Metrics.PrometheusQueryEndpoint == nil || *workspace.Properties.Metrics.PrometheusQueryEndpoint == "" {
+		return "", fmt.Errorf("azure Monitor workspace %s has no Prometheus query endpoint", id.Name)
+	}
+	endpoint := *workspace.Properties.Metrics.PrometheusQueryEndpoint
+
+	// Store in cache
+	c.cache.Set(cacheKey, endpoint)
+
+	return endpoint, nil
+}
+
+// DoPrometheusRequest sends a Prometheus HTTP API request to the query endpoint of an Azure Monitor
+// workspace, authenticated with a Microsoft Entra token for managed Prometheus
+func (c *AzureClient) DoPrometheusRequest(req *http.Request) (*http.Response, error) {
+	c.prometheusClientOnce.Do(func() {
+		c.prometheusClient, c.prometheusClientErr = azcore.NewClient(moduleName, moduleVersion, runtime.PipelineOptions{
+			PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(c.credential, []string{prometheusScope}, nil)},
+		}, nil)
+	})
+	if c.prometheusClientErr != nil {
+		return nil, fmt.Errorf("failed to create Prometheus query client: %v", c.prometheusClientErr)
+	}
+
+	policyReq, err := runtime.NewRequestFromRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	return c.prometheusClient.Pipeline().Do(policyReq)
+}
+
+// GetAvailabilityStatus retrieves the current Resource Health availability status of the specified resource.
+func (c *AzureClient) GetAvailabilityStatus(ctx context.Context, resourceID string) (*armresourcehealth.AvailabilityStatus, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource ID %s: %v", resourceID, err)
+	}
+	clients, err := c.GetOrCreateClientsForSubscription(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.AvailabilityStatusesClient.GetByResource(ctx, resourceID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability status: %w", err)
+	}
+
+	return &resp.AvailabilityStatus, nil
+}
+
+// GetLogAnalyticsWorkspaceCustomerID retrieves the workspace GUID (customer ID) used by the Log Analytics query API.
+func (c *AzureClient) GetLogAnalyticsWorkspaceCustomerID(ctx context.Context, workspaceResourceID string) (string, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:workspacecustomerid:%s", strings.ToLower(workspaceResourceID))
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if customerID, ok := cached.(string); ok {
+			return customerID, nil
+		}
+	}
+
+	id, err := arm.ParseResourceID(workspaceResourceID)
+	if err != nil {
+		return "", fmt.Errorf("invalid Log Analytics workspace ID %s: %v", workspaceResourceID, err)
+	}
+	clients, err := c.GetOrCreateClientsForSubscription(id.SubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	workspace, err := clients.WorkspacesClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Log Analytics workspace: %w", err)
+	}
+	if workspace.Properties == nil || workspace.Properties.CustomerID == nil || *workspace.Properties.CustomerID == "" {
+		return "", fmt.Errorf("workspace %s has no customer ID", workspaceResourceID)
+	}
+	customerID := *workspace.Properties.CustomerID
+
+	// Store in cache
+	c.cache.Set(cacheKey, customerID)
+
+	return customerID, nil
+}
+
+// QueryLogAnalyticsWorkspace runs a KQL query against a Log Analytics workspace identified by its GUID.
+// The timespan is an ISO 8601 interval or duration; an empty timespan leaves the time range to the query.
+func (c *AzureClient) QueryLogAnalyticsWorkspace(ctx context.Context, workspaceID, query, timespan string) (*azlogs.QueryResults, error) {
+	client, err := c.getLogsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.QueryWorkspace(ctx, workspaceID, newLogsQueryBody(query, timespan), &azlogs.QueryWorkspaceOptions{
+		Options: logsQueryOptions(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+
+	return &resp.QueryResults, nil
+}
+
+// QueryLogAnalyticsResource runs a resource-centric KQL query against the logs of an Azure resource,
+// such as a workspace-based Application Insights component.
+func (c *AzureClient) QueryLogAnalyticsResource(ctx context.Context, resourceID, query, timespan string) (*azlogs.QueryResults, error) {
+	client, err := c.getLogsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.QueryResource(ctx, resourceID, newLogsQueryBody(query, timespan), &azlogs.QueryResourceOptions{
+		Options: logsQueryOptions(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+
+	return &resp.QueryResults, nil
+}
+
+// GetAppInsightsComponent retrieves an Application Insights component
+func (c *AzureClient) GetAppInsightsComponent(ctx context.Context, componentResourceID string) (*armapplicationinsights.Component, error) {
+	// Create cache key
+	cacheKey := fmt.Sprintf("resource:appinsightscomponent:%s", strings.ToLower(componentResourceID))
+
+	// Check cache first
+	if cached, found := c.cache.Get(cacheKey); found {
+		if component, ok := cached.(*armapplicationinsights.Component); ok {
+			return component, nil
+		}
+	}
+
+	id, err := arm.ParseResourceID(componentResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Application Insights component ID %s: %v", componentResourceID, err)
+	}
+	clients, err := c.GetOrCreateClientsForSubscription(id.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := clients.InsightsComponentsClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Application Insights component: %w", err)
+	}
+
+	component := &resp.Component
+	// Store in cache
+	c.cache.Set(cacheKey, component)
+
+	return component, nil
+}
+
+// QueryAppInsights runs a KQL query against an Application Insights component. Workspace-based
+// components are queried through the resource-centric Log Analytics API, and classic components,
+// which have no workspace, through the Application Insights query API with their application ID.
+func (c *AzureClient) QueryAppInsights(ctx context.Context, componentResourceID, query, timespan string) (*azlogs.QueryResults, error) {
+	component, err := c.GetAppInsightsComponent(ctx, componentResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if component.Properties != nil && component.Properties.WorkspaceResourceID != nil && *component.Properties.WorkspaceResourceID != "" {
+		return c.QueryLogAnalyticsResource(ctx, componentResourceID, query, timespan)
+	}
+
+	endpoint, err := classicAppInsightsQueryEndpoint(componentResourceID, component)
+	if err != nil {
+		return nil, err
+	}
+
+	c.appInsightsClientOnce.Do(func() {
+		c.appInsightsClient, c.appInsightsClientErr = azcore.NewClient(moduleName, moduleVersion, runtime.PipelineOptions{
+			PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(c.credential, []string{appInsightsScope}, nil)},
+		}, nil)
+	})
+	if c.appInsightsClientErr != nil {
+		return nil, fmt.Errorf("failed to create Application Insights query client: %v", c.appInsightsClientErr)
+	}
+
+	// The Application Insights query API shares the request and response format of the Log Analytics query API
+	req, err := newAppInsightsQueryRequest(ctx, endpoint, query, timespan)
+	if err != nil {
+		return nil, err
+	}
+	var results azlogs.QueryResults
+	if err := doJSONRequest(c.appInsightsClient.Pipeline(), req, &results); err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+
+	return &results, nil
+}
+
+// classicAppInsightsQueryEndpoint returns the Application Insights query API endpoint of a classic component
+func classicAppInsightsQueryEndpoint(componentResourceID string, component *armapplicationinsights.Component) (string, error) {
+	if component.Properties == nil || component.Properties.AppID == nil || *component.Properties.AppID == "" {
+		return "", fmt.Errorf("application Insights component %s has neither a Log Analytics workspace nor an application ID to query", componentResourceID)
+	}
+	return runtime.JoinPaths(appInsightsEndpoint, "/v1/apps", url.PathEscape(*component.Properties.AppID), "/query"), nil
+}
+
+// newLogsQueryBody builds the body of a logs query; an empty timespan leaves the time range to the query
+func newLogsQueryBody(query, timespan string) azlogs.QueryBody {
+	body := azlogs.QueryBody{Query: to.Ptr(query)}
+	if timespan != "" {
+		body.Timespan = to.Ptr(azlogs.TimeInterval(timespan))
+	}
+	return body
+}
+
+// logsServerTimeout returns the server-side timeout in seconds for a logs query, derived from the context deadline
+func logsServerTimeout(ctx context.Context) int {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	wait := min(time.Until(deadline), maxLogsServerTimeout)
+	return max(int(wait.Seconds()), 0)
+}
+
+// logsQueryOptions passes the context deadline on to the Log Analytics query API as the server-side timeout
+func logsQueryOptions(ctx context.Context) *azlogs.QueryOptions {
+	if seconds := logsServerTimeout(ctx); seconds > 0 {
+		return &azlogs.QueryOptions{Wait: to.Ptr(seconds)}
+	}
+	return nil
+}
+
+// newAppInsightsQueryRequest builds an Application Insights query request, passing the context deadline on as the server-side timeout
+func newAppInsightsQueryRequest(ctx context.Context, endpoint, query, timespan string) (*policy.Request, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Raw().Header.Set("Accept", "application/json")
+
+	if seconds := logsServerTimeout(ctx); seconds > 0 {
+		req.Raw().Header.Set("Prefer", fmt.Sprintf("wait=%d", seconds))
+	}
+
+	if err := runtime.MarshalAsJSON(req, newLogsQueryBody(query, timespan)); err != nil {
+		return nil, fmt.Errorf("failed to marshal query request: %v", err)
+	}
+
+	return req, nil
+}
+
+// doJSONRequest sends a request through the pipeline and decodes a successful JSON response into result
+func doJSONRequest(pipeline runtime.Pipeline, req *policy.Request, result interface{}) error {
+	resp, err := pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, result)
+}
+
+// getLogsClient lazily creates the client for the Log Analytics query API
+func (c *AzureClient) getLogsClient() (*azlogs.Client, error) {
+	c.logsClientOnce.Do(func() {
+		c.logsClient, c.logsClientErr = azlogs.NewClient(c.credential, nil)
+	})
+	if c.logsClientErr != nil {
+		return nil, fmt.Errorf("failed to create logs query client: %v", c.logsClientErr)
+	}
+	return c.logsClient, nil
+}
@@ -0,0 +1,151 @@
+package azureclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/query/azlogs"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/applicationinsights/armapplicationinsights"
+)
+
+func TestLogsRecords(t *testing.T) {
+	results := &azlogs.QueryResults{
+		Tables: []*azlogs.Table{{
+			Name:    to.Ptr("PrimaryResult"),
+			Columns: []*azlogs.Column{{Name: to.Ptr("TimeGenerated")}, {Name: to.Ptr("Message")}},
+			Rows: []azlogs.Row{
+				{"2025-01-01T00:00:00Z", "first"},
+				{"2025-01-01T00:01:00Z"},
+			},
+		}},
+	}
+
+	records := LogsRecords(results)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0]["Message"] != "first" {
+		t.Errorf("Expected Message 'first', got %v", records[0]["Message"])
+	}
+	if _, ok := records[1]["Message"]; ok {
+		t.Error("Expected short row to omit missing columns")
+	}
+
+	if records := LogsRecords(nil); records == nil || len(records) != 0 {
+		t.Errorf("Expected empty non-nil records for nil result, got %v", records)
+	}
+}
+
+func TestLogsQueryOptions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	options := logsQueryOptions(ctx)
+	if options == nil || options.Wait == nil || *options.Wait <= 0 || *options.Wait > 30 {
+		t.Errorf("Expected server timeout from context deadline, got %+v", options)
+	}
+
+	if options := logsQueryOptions(context.Background()); options != nil {
+		t.Errorf("Expected no options without deadline, got %+v", options)
+	}
+}
+
+func TestNewAppInsightsQueryRequest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := newAppInsightsQueryRequest(ctx, "https://api.applicationinsights.io/v1/apps/id/query", "requests | take 1", "PT1H")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if prefer := req.Raw().Header.Get("Prefer"); !strings.HasPrefix(prefer, "wait=") {
+		t.Errorf("Expected Prefer wait header from context deadline, got %q", prefer)
+	}
+
+	body, err := io.ReadAll(req.Body())
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Failed to parse body: %v", err)
+	}
+	if payload["query"] != "requests | take 1" || payload["timespan"] != "PT1H" {
+		t.Errorf("Unexpected request body: %v", payload)
+	}
+
+	req, err = newAppInsightsQueryRequest(context.Background(), "https://api.applicationinsights.io/v1/apps/id/query", "T", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prefer := req.Raw().Header.Get("Prefer"); prefer != "" {
+		t.Errorf("Expected no Prefer header without deadline, got %q", prefer)
+	}
+}
+
+func TestDoJSONRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"NotFound","message":"not found"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"tables":[{"name":"PrimaryResult","columns":[{"name":"name","type":"string"}],"rows":[["GET /"]]}]}`))
+	}))
+	defer server.Close()
+
+	pipeline := runtime.NewPipeline("test", "v0.0.0", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport: server.Client(),
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, server.URL+"/query")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var results azlogs.QueryResults
+	if err := doJSONRequest(pipeline, req, &results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if records := LogsRecords(&results); len(records) != 1 || records[0]["name"] != "GET /" {
+		t.Errorf("Unexpected records: %v", records)
+	}
+
+	req, err = runtime.NewRequest(context.Background(), http.MethodPost, server.URL+"/missing")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := doJSONRequest(pipeline, req, &results); err == nil || !strings.Contains(err.Error(), "NotFound") {
+		t.Errorf("Expected NotFound response error, got %v", err)
+	}
+}
+
+func TestClassicAppInsightsQueryEndpoint(t *testing.T) {
+	const componentID = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Insights/components/app"
+
+	var component armapplicationinsights.Component
+	if err := json.Unmarshal([]byte(`{"id":"`+componentID+`","kind":"web","location":"eastus","properties":{"AppId":"1234-abcd","IngestionMode":"ApplicationInsights"}}`), &component); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if component.Properties == nil || component.Properties.WorkspaceResourceID != nil {
+		t.Errorf("Expected a classic component without workspace, got %+v", component.Properties)
+	}
+	endpoint, err := classicAppInsightsQueryEndpoint(componentID, &component)
+	if err != nil || endpoint != "https://api.applicationinsights.io/v1/apps/1234-abcd/query" {
+		t.Errorf("Unexpected endpoint %q, %v", endpoint, err)
+	}
+
+	if _, err := classicAppInsightsQueryEndpoint(componentID, &armapplicationinsights.Component{}); err == nil || !strings.Contains(err.Error(), "neither a Log Analytics workspace nor an application ID") {
+		t.Errorf("Expected an error for a component without application ID, got %v", err)
+	}
+}
@@ -3,26 +3,24 @@ package advisor
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/advisor/armadvisor"
 )
 
 // Test data
-var mockCLIRecommendations = []CLIRecommendation{
+var mockRecommendations = []Recommendation{
 	{
 		ID:            "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1",
 		Name:          "rec1",
 		Category:      "Cost",
 		Impact:        "High",
 		ImpactedValue: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1",
-		LastUpdated:   "2024-01-15T10:30:00Z",
-		ShortDescription: struct {
-			Problem  string `json:"problem"`
-			Solution string `json:"solution"`
-		}{
-			Problem:  "Underutilized AKS cluster nodes",
-			Solution: "Consider reducing node count or using smaller VM sizes for your AKS cluster",
-		},
+		LastUpdated:   mustParseTime("2024-01-15T10:30:00Z"),
+		Problem:       "Underutilized AKS cluster nodes",
+		Solution:      "Consider reducing node count or using smaller VM sizes for your AKS cluster",
 	},
 	{
 		ID:            "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/agentPools/nodepool1",
@@ -30,14 +28,9 @@ var mockCLIRecommendations = []CLIRecommendation{
 		Category:      "Security",
 		Impact:        "Medium",
 		ImpactedValue: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/agentPools/nodepool1",
-		LastUpdated:   "2024-01-15T09:15:00Z",
-		ShortDescription: struct {
-			Problem  string `json:"problem"`
-			Solution string `json:"solution"`
-		}{
-			Problem:  "AKS node pool missing security configurations",
-			Solution: "Enable Azure Policy and security monitoring for AKS node pools",
-		},
+		LastUpdated:   mustParseTime("2024-01-15T09:15:00Z"),
+		Problem:       "AKS node pool missing security configurations",
+		Solution:      "Enable Azure Policy and security monitoring for AKS node pools",
 	},
 	{
 		ID:            "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Storage/storageAccounts/mystorage",
@@ -45,19 +38,14 @@ var mockCLIRecommendations = []CLIRecommendation{
 		Category:      "Performance",
 		Impact:        "Low",
 		ImpactedValue: "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Storage/storageAccounts/mystorage",
-		LastUpdated:   "2024-01-15T08:00:00Z",
-		ShortDescription: struct {
-			Problem  string `json:"problem"`
-			Solution string `json:"solution"`
-		}{
-			Problem:  "Storage account performance issue",
-			Solution: "Upgrade storage account tier",
-		},
+		LastUpdated:   mustParseTime("2024-01-15T08:00:00Z"),
+		Problem:       "Storage account performance issue",
+		Solution:      "Upgrade storage account tier",
 	},
 }
 
-func TestFilterAKSRecommendationsFromCLI(t *testing.T) {
-	aksRecommendations := filterAKSRecommendationsFromCLI(mockCLIRecommendations)
+func TestFilterAKSRecommendations(t *testing.T) {
+	aksRecommendations := filterAKSRecommendations(mockRecommendations)
 
 	// Should filter out the storage account recommendation and keep only AKS-related ones
 	expectedCount := 2
@@ -67,13 +55,13 @@ func TestFilterAKSRecommendationsFromCLI(t *testing.T) {
 
 	// Verify the filtered recommendations are AKS-related
 	for _, rec := range aksRecommendations {
-		if !isAKSRelatedCLI(rec.ImpactedValue) {
+		if !isAKSRelated(rec.ImpactedValue) {
 			t.Errorf("Non-AKS recommendation found in filtered results: %s", rec.ImpactedValue)
 		}
 	}
 }
 
-func TestIsAKSRelatedCLI(t *testing.T) {
+func TestIsAKSRelated(t *testing.T) {
 	testCases := []struct {
 		resourceID string
 		expected   bool
@@ -88,14 +76,14 @@ func TestIsAKSRelatedCLI(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		result := isAKSRelatedCLI(tc.resourceID)
+		result := isAKSRelated(tc.resourceID)
 		if result != tc.expected {
 			t.Errorf("For resourceID %s, expected %v, got %v", tc.resourceID, tc.expected, result)
 		}
 	}
 }
 
-func TestExtractAKSClusterNameFromCLI(t *testing.T) {
+func TestExtractAKSClusterName(t *testing.T) {
 	testCases := []struct {
 		resourceID   string
 		expectedName string
@@ -107,7 +95,7 @@ func TestExtractAKSClusterNameFromCLI(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		result := extractAKSClusterNameFromCLI(tc.resourceID)
+		result := extractAKSClusterName(tc.resourceID)
 		if result != tc.expectedName {
 			t.Errorf("For resourceID %s, expected cluster name %s, got %s", tc.resourceID, tc.expectedName, result)
 		}
@@ -134,7 +122,7 @@ func TestExtractResourceGroupFromResourceID(t *testing.T) {
 }
 
 func TestConvertToAKSRecommendationSummary(t *testing.T) {
-	rec := mockCLIRecommendations[0] // Cost recommendation for AKS cluster
+	rec := mockRecommendations[0] // Cost recommendation for AKS cluster
 	summary := convertToAKSRecommendationSummary(rec)
 
 	if summary.ID != rec.ID {
@@ -164,19 +152,19 @@ func TestConvertToAKSRecommendationSummary(t *testing.T) {
 
 func TestFilterBySeverity(t *testing.T) {
 	// Filter for High severity
-	highSeverity := filterBySeverity(mockCLIRecommendations, "High")
+	highSeverity := filterBySeverity(mockRecommendations, "High")
 	if len(highSeverity) != 1 {
 		t.Errorf("Expected 1 high severity recommendation, got %d", len(highSeverity))
 	}
 
 	// Filter for Medium severity
-	mediumSeverity := filterBySeverity(mockCLIRecommendations, "Medium")
+	mediumSeverity := filterBySeverity(mockRecommendations, "Medium")
 	if len(mediumSeverity) != 1 {
 		t.Errorf("Expected 1 medium severity recommendation, got %d", len(mediumSeverity))
 	}
 
 	// Filter for Low severity
-	lowSeverity := filterBySeverity(mockCLIRecommendations, "Low")
+	lowSeverity := filterBySeverity(mockRecommendations, "Low")
 	if len(lowSeverity) != 1 {
 		t.Errorf("Expected 1 low severity recommendation, got %d", len(lowSeverity))
 	}
@@ -184,7 +172,7 @@ func TestFilterBySeverity(t *testing.T) {
 
 func TestGenerateAKSAdvisorReport(t *testing.T) {
 	// Convert mock data to AKS recommendations
-	aksRecommendations := filterAKSRecommendationsFromCLI(mockCLIRecommendations)
+	aksRecommendations := filterAKSRecommendations(mockRecommendations)
 	summaries := convertToAKSRecommendationSummaries(aksRecommendations)
 
 	// Generate report
@@ -242,7 +230,7 @@ func TestHandleAdvisorRecommendationInvalidOperation(t *testing.T) {
 		"operation": "invalid_operation",
 	}
 
-	_, err := HandleAdvisorRecommendation(context.Background(), params, cfg)
+	_, err := HandleAdvisorRecommendation(context.Background(), params, nil, cfg)
 	if err == nil {
 		t.Error("Expected error for invalid operation, got nil")
 	}
@@ -257,7 +245,7 @@ func TestHandleAdvisorRecommendationMissingOperation(t *testing.T) {
 	cfg := &config.ConfigData{}
 	params := map[string]interface{}{}
 
-	_, err := HandleAdvisorRecommendation(context.Background(), params, cfg)
+	_, err := HandleAdvisorRecommendation(context.Background(), params, nil, cfg)
 	if err == nil {
 		t.Error("Expected error for missing operation, got nil")
 	}
@@ -268,6 +256,183 @@ func TestHandleAdvisorRecommendationMissingOperation(t *testing.T) {
 	}
 }
 
+func TestHandleAdvisorRecommendationDefaultSubscriptions(t *testing.T) {
+	cfg := &config.ConfigData{AllowedSubscriptions: []string{"11111111-1111-1111-1111-111111111111"}}
+
+	for _, operation := range []string{"list", "report"} {
+		params := map[string]interface{}{
+			"operation": operation,
+		}
+
+		// Without subscription_id the allowlist is queried, which fails only on the missing client
+		_, err := HandleAdvisorRecommendation(context.Background(), params, nil, cfg)
+		if err == nil || !contains(err.Error(), "azure client is required") {
+			t.Errorf("Expected %s to resolve the allowed subscriptions and fail on the missing client, got %v", operation, err)
+		}
+	}
+
+	params := map[string]interface{}{
+		"operation":       "list",
+		"subscription_id": "22222222-2222-2222-2222-222222222222",
+	}
+	_, err := HandleAdvisorRecommendation(context.Background(), params, nil, cfg)
+	if err == nil || !contains(err.Error(), "not in the allowed subscriptions list") {
+		t.Errorf("Expected allowlist error, got %v", err)
+	}
+}
+
+func TestNewRecommendation(t *testing.T) {
+	lastUpdated := mustParseTime("2024-01-15T10:30:00Z")
+	rec := &armadvisor.ResourceRecommendationBase{
+		ID:   to.Ptr("/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/providers/Microsoft.Advisor/recommendations/rec1"),
+		Name: to.Ptr("rec1"),
+		Properties: &armadvisor.RecommendationProperties{
+			Category:           to.Ptr(armadvisor.CategoryCost),
+			Impact:             to.Ptr(armadvisor.ImpactHigh),
+			LastUpdated:        &lastUpdated,
+			ShortDescription:   &armadvisor.ShortDescription{Problem: to.Ptr("problem"), Solution: to.Ptr("solution")},
+			ExtendedProperties: map[string]*string{"savingsAmount": to.Ptr("100")},
+		},
+	}
+
+	result := newRecommendation("sub1", rec)
+	expectedResourceID := "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1"
+	if result.ResourceID != expectedResourceID {
+		t.Errorf("Expected resource ID %s, got %s", expectedResourceID, result.ResourceID)
+	}
+	if result.SubscriptionID != "sub1" || result.Category != "Cost" || result.Impact != "High" || result.Problem != "problem" || result.Solution != "solution" {
+		t.Errorf("Unexpected recommendation fields: %+v", result)
+	}
+	if !result.LastUpdated.Equal(lastUpdated) {
+		t.Errorf("Expected last updated %v, got %v", lastUpdated, result.LastUpdated)
+	}
+	if result.ExtendedProperties["savingsAmount"] != "100" {
+		t.Errorf("Expected extended properties to be copied, got %v", result.ExtendedProperties)
+	}
+
+	// Resource metadata takes precedence over the recommendation ID
+	resourceID := "/subscriptions/sub1/resourceGroups/rg2/providers/Microsoft.ContainerService/managedClusters/aks-cluster-2"
+	rec.Properties.ResourceMetadata = &armadvisor.ResourceMetadata{ResourceID: to.Ptr(resourceID)}
+	result = newRecommendation("sub1", rec)
+	if result.ResourceID != resourceID {
+		t.Errorf("Expected resource ID %s, got %s", resourceID, result.ResourceID)
+	}
+
+	// Recommendations without properties keep their ID
+	result = newRecommendation("sub1", &armadvisor.ResourceRecommendationBase{ID: to.Ptr("rec-id")})
+	if result.ID != "rec-id" || result.ResourceID != "" {
+		t.Errorf("Unexpected recommendation for missing properties: %+v", result)
+	}
+}
+
+func TestBuildRecommendationFilter(t *testing.T) {
+	testCases := []struct {
+		resourceGroup string
+		category      string
+		expected      string
+		expectError   bool
+	}{
+		{"", "", "", false},
+		{"", "cost", "Category eq 'Cost'", false},
+		{"my-rg", "", "ResourceGroup eq 'my-rg'", false},
+		{"my-rg", "HighAvailability", "Category eq 'HighAvailability' and ResourceGroup eq 'my-rg'", false},
+		{"", "Unknown", "", true},
+		{"rg' or Category eq 'Cost", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		result, err := buildRecommendationFilter(tc.resourceGroup, tc.category)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected error for resource group %q and category %q", tc.resourceGroup, tc.category)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for resource group %q and category %q: %v", tc.resourceGroup, tc.category, err)
+		}
+		if result != tc.expected {
+			t.Errorf("Expected filter %q, got %q", tc.expected, result)
+		}
+	}
+}
+
+func TestExtractCostSavings(t *testing.T) {
+	testCases := []struct {
+		name       string
+		properties map[string]string
+		expected   *CostSavings
+	}{
+		{"no savings", map[string]string{"other": "value"}, nil},
+		{"nil properties", nil, nil},
+		{"invalid amount", map[string]string{"savingsAmount": "n/a"}, nil},
+		{"monthly only", map[string]string{"savingsAmount": "100", "savingsCurrency": "EUR"}, &CostSavings{Currency: "EUR", MonthlySavings: 100, AnnualSavings: 1200}},
+		{"annual only", map[string]string{"annualSavingsAmount": "1200"}, &CostSavings{Currency: "USD", MonthlySavings: 100, AnnualSavings: 1200}},
+		{"both", map[string]string{"savingsAmount": "90", "annualSavingsAmount": "1000", "savingsCurrency": "USD"}, &CostSavings{Currency: "USD", MonthlySavings: 90, AnnualSavings: 1000}},
+	}
+
+	for _, tc := range testCases {
+		result := extractCostSavings(tc.properties)
+		if tc.expected == nil {
+			if result != nil {
+				t.Errorf("%s: expected no savings, got %+v", tc.name, result)
+			}
+			continue
+		}
+		if result == nil || *result != *tc.expected {
+			t.Errorf("%s: expected %+v, got %+v", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestGenerateAKSAdvisorReportSavings(t *testing.T) {
+	recommendations := []Recommendation{
+		{
+			ID:                 "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1",
+			ResourceID:         "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1",
+			Category:           "Cost",
+			Impact:             "High",
+			ExtendedProperties: map[string]string{"savingsAmount": "100", "savingsCurrency": "USD"},
+		},
+		{
+			ID:                 "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/agentPools/nodepool1",
+			ResourceID:         "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/agentPools/nodepool1",
+			Category:           "Cost",
+			Impact:             "Medium",
+			ExtendedProperties: map[string]string{"annualSavingsAmount": "600", "savingsCurrency": "USD"},
+		},
+		{
+			ID:                 "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/agentPools/nodepool2",
+			ResourceID:         "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.ContainerService/managedClusters/aks-cluster-1/agentPools/nodepool2",
+			Category:           "Cost",
+			Impact:             "Low",
+			ExtendedProperties: map[string]string{"savingsAmount": "10", "savingsCurrency": "EUR"},
+		},
+	}
+
+	report := generateAKSAdvisorReport("sub1", convertToAKSRecommendationSummaries(recommendations), "detailed")
+
+	expected := CostSavings{Currency: "USD", MonthlySavings: 150, AnnualSavings: 1800}
+	if report.Summary.TotalPotentialSavings == nil || *report.Summary.TotalPotentialSavings != expected {
+		t.Errorf("Expected total savings %+v, got %+v", expected, report.Summary.TotalPotentialSavings)
+	}
+
+	if len(report.ClusterBreakdown) != 1 {
+		t.Fatalf("Expected 1 cluster summary, got %d", len(report.ClusterBreakdown))
+	}
+	if report.ClusterBreakdown[0].TotalSavings == nil || *report.ClusterBreakdown[0].TotalSavings != expected {
+		t.Errorf("Expected cluster savings %+v, got %+v", expected, report.ClusterBreakdown[0].TotalSavings)
+	}
+}
+
+func mustParseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 || s[len(s)-len(substr):] == substr || s[:len(substr)] == substr || containsInMiddle(s, substr))
@@ -4,16 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Azure/aks-mcp/internal/azcli"
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/logger"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/advisor/armadvisor"
 )
 
+// supportedCategories lists the Azure Advisor recommendation categories accepted by the category filter
+var supportedCategories = []string{"Cost", "HighAvailability", "OperationalExcellence", "Performance", "Security"}
+
+// resourceGroupPattern matches valid resource group names
+var resourceGroupPattern = regexp.MustCompile(`^[-\w._()]{1,90}$`)
+
 // HandleAdvisorRecommendation is the main handler for Azure Advisor recommendation operations
-func HandleAdvisorRecommendation(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func HandleAdvisorRecommendation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	operation, ok := params["operation"].(string)
 	if !ok {
 		logger.Errorf("[ADVISOR] Missing operation parameter")
@@ -24,9 +35,9 @@ func HandleAdvisorRecommendation(ctx context.Context, params map[string]interfac
 
 	switch operation {
 	case "list":
-		return handleAKSAdvisorRecommendationList(ctx, params, cfg)
+		return handleAKSAdvisorRecommendationList(ctx, params, azClient, cfg)
 	case "report":
-		return handleAKSAdvisorRecommendationReport(ctx, params, cfg)
+		return handleAKSAdvisorRecommendationReport(ctx, params, azClient, cfg)
 	default:
 		logger.Errorf("[ADVISOR] Invalid operation: %s", operation)
 		return "", fmt.Errorf("invalid operation: %s. Allowed values: list, report", operation)
@@ -34,20 +45,17 @@ func HandleAdvisorRecommendation(ctx context.Context, params map[string]interfac
 }
 
 // handleAKSAdvisorRecommendationList lists AKS-related recommendations
-func handleAKSAdvisorRecommendationList(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
-	subscriptionID, ok := params["subscription_id"].(string)
-	if !ok {
-		logger.Errorf("[ADVISOR] Missing subscription_id parameter")
-		return "", fmt.Errorf("subscription_id parameter is required")
-	}
+func handleAKSAdvisorRecommendationList(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// An empty subscription_id resolves to the allowed or default subscriptions
+	subscriptionParam, _ := params["subscription_id"].(string)
 
 	// Get optional parameters
 	resourceGroup, _ := params["resource_group"].(string)
 	category, _ := params["category"].(string)
 	severity, _ := params["severity"].(string)
 
-	logger.Debugf("[ADVISOR] Listing recommendations for subscription: %s, resource_group: %s, category: %s, severity: %s",
-		subscriptionID, resourceGroup, category, severity)
+	logger.Debugf("[ADVISOR] Listing recommendations for subscriptions: %s, resource_group: %s, category: %s, severity: %s",
+		subscriptionParam, resourceGroup, category, severity)
 
 	// Get cluster names filter if provided
 	var clusterNames []string
@@ -61,8 +69,13 @@ func handleAKSAdvisorRecommendationList(ctx context.Context, params map[string]i
 		logger.Debugf("[ADVISOR] Filtering by cluster names: %v", clusterNames)
 	}
 
-	// Execute Azure CLI command to get recommendations
-	recommendations, err := listRecommendationsViaCLI(ctx, subscriptionID, resourceGroup, category, cfg)
+	subscriptions, err := common.ResolveSubscriptions(subscriptionParam, cfg, common.GetDefaultSubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	// Category and resource group are filtered server-side
+	recommendations, err := listRecommendations(ctx, azClient, cfg, subscriptions, resourceGroup, category)
 	if err != nil {
 		logger.Errorf("[ADVISOR] Failed to list recommendations: %v", err)
 		return "", fmt.Errorf("failed to list recommendations: %w", err)
@@ -71,7 +84,7 @@ func handleAKSAdvisorRecommendationList(ctx context.Context, params map[string]i
 	logger.Infof("[ADVISOR] Found %d total recommendations", len(recommendations))
 
 	// Filter for AKS-related recommendations
-	aksRecommendations := filterAKSRecommendationsFromCLI(recommendations)
+	aksRecommendations := filterAKSRecommendations(recommendations)
 	logger.Infof("[ADVISOR] Found %d AKS-related recommendations", len(aksRecommendations))
 
 	// Apply additional filters
@@ -99,11 +112,9 @@ func handleAKSAdvisorRecommendationList(ctx context.Context, params map[string]i
 }
 
 // handleAKSAdvisorRecommendationReport generates a comprehensive report
-func handleAKSAdvisorRecommendationReport(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
-	subscriptionID, ok := params["subscription_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("subscription_id parameter is required")
-	}
+func handleAKSAdvisorRecommendationReport(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// An empty subscription_id resolves to the allowed or default subscriptions
+	subscriptionParam, _ := params["subscription_id"].(string)
 
 	// Get optional parameters
 	resourceGroup, _ := params["resource_group"].(string)
@@ -112,18 +123,23 @@ func handleAKSAdvisorRecommendationReport(ctx context.Context, params map[string
 		format = "summary"
 	}
 
+	subscriptions, err := common.ResolveSubscriptions(subscriptionParam, cfg, common.GetDefaultSubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
 	// Get all AKS recommendations
-	recommendations, err := listRecommendationsViaCLI(ctx, subscriptionID, resourceGroup, "", cfg)
+	recommendations, err := listRecommendations(ctx, azClient, cfg, subscriptions, resourceGroup, "")
 	if err != nil {
 		return "", fmt.Errorf("failed to list recommendations: %w", err)
 	}
 
 	// Filter for AKS-related recommendations
-	aksRecommendations := filterAKSRecommendationsFromCLI(recommendations)
+	aksRecommendations := filterAKSRecommendations(recommendations)
 	summaries := convertToAKSRecommendationSummaries(aksRecommendations)
 
 	// Generate report
-	report := generateAKSAdvisorReport(subscriptionID, summaries, format)
+	report := generateAKSAdvisorReport(strings.Join(subscriptions, ","), summaries, format)
 
 	// Return JSON response
 	result, err := json.MarshalIndent(report, "", "  ")
@@ -134,57 +150,183 @@ func handleAKSAdvisorRecommendationReport(ctx context.Context, params map[string
 	return string(result), nil
 }
 
-// listRecommendationsViaCLI executes Azure CLI command to list recommendations
-func listRecommendationsViaCLI(ctx context.Context, subscriptionID, resourceGroup, category string, cfg *config.ConfigData) ([]CLIRecommendation, error) {
-	executor := azcli.NewExecutor()
+// listRecommendations lists Azure Advisor recommendations across subscriptions using the Advisor API
+func listRecommendations(ctx context.Context, azClient *azureclient.AzureClient, cfg *config.ConfigData, subscriptions []string, resourceGroup, category string) ([]Recommendation, error) {
+	filter, err := buildRecommendationFilter(resourceGroup, category)
+	if err != nil {
+		return nil, err
+	}
+
+	if azClient == nil {
+		return nil, fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
 
-	// Build command arguments
-	args := []string{"advisor", "recommendation", "list", "--subscription", subscriptionID, "--output", "json"}
+	var recommendations []Recommendation
+	for _, subscriptionID := range subscriptions {
+		logger.Debugf("[ADVISOR] Listing recommendations for subscription %s with filter: %s", subscriptionID, filter)
+
+		results, err := azClient.ListAdvisorRecommendations(ctx, subscriptionID, filter)
+		if err != nil {
+			return nil, fmt.Errorf("subscription %s: %w", subscriptionID, err)
+		}
+		for _, rec := range results {
+			recommendations = append(recommendations, newRecommendation(subscriptionID, rec))
+		}
+	}
+
+	return recommendations, nil
+}
+
+// buildRecommendationFilter builds the Advisor OData filter for the server-side resource group and category filters
+func buildRecommendationFilter(resourceGroup, category string) (string, error) {
+	var clauses []string
+
+	if category != "" {
+		idx := slices.IndexFunc(supportedCategories, func(c string) bool { return strings.EqualFold(c, category) })
+		if idx < 0 {
+			return "", fmt.Errorf("invalid category: %s. Allowed values: %s", category, strings.Join(supportedCategories, ", "))
+		}
+		clauses = append(clauses, fmt.Sprintf("Category eq '%s'", supportedCategories[idx]))
+	}
 
 	if resourceGroup != "" {
-		args = append(args, "--resource-group", resourceGroup)
+		if !resourceGroupPattern.MatchString(resourceGroup) {
+			return "", fmt.Errorf("invalid resource group name: %s", resourceGroup)
+		}
+		clauses = append(clauses, fmt.Sprintf("ResourceGroup eq '%s'", resourceGroup))
 	}
 
-	// Create command parameters
-	cmdParams := map[string]interface{}{
-		"command": "az " + strings.Join(args, " "),
+	return strings.Join(clauses, " and "), nil
+}
+
+// newRecommendation flattens an Advisor API recommendation
+func newRecommendation(subscriptionID string, rec *armadvisor.ResourceRecommendationBase) Recommendation {
+	recommendation := Recommendation{
+		ID:             stringValue(rec.ID),
+		Name:           stringValue(rec.Name),
+		SubscriptionID: subscriptionID,
 	}
 
-	logger.Debugf("[ADVISOR] Executing command: %s", cmdParams["command"])
+	if props := rec.Properties; props != nil {
+		if props.Category != nil {
+			recommendation.Category = string(*props.Category)
+		}
+		if props.Impact != nil {
+			recommendation.Impact = string(*props.Impact)
+		}
+		recommendation.ImpactedField = stringValue(props.ImpactedField)
+		recommendation.ImpactedValue = stringValue(props.ImpactedValue)
+		if props.ShortDescription != nil {
+			recommendation.Problem = stringValue(props.ShortDescription.Problem)
+			recommendation.Solution = stringValue(props.ShortDescription.Solution)
+		}
+		if props.ResourceMetadata != nil {
+			recommendation.ResourceID = stringValue(props.ResourceMetadata.ResourceID)
+		}
+		if props.LastUpdated != nil {
+			recommendation.LastUpdated = *props.LastUpdated
+		}
+		if len(props.ExtendedProperties) > 0 {
+			recommendation.ExtendedProperties = make(map[string]string, len(props.ExtendedProperties))
+			for key, value := range props.ExtendedProperties {
+				recommendation.ExtendedProperties[key] = stringValue(value)
+			}
+		}
+	}
 
-	// Execute command
-	output, err := executor.Execute(ctx, cmdParams, cfg)
-	if err != nil {
-		logger.Errorf("[ADVISOR] Command execution failed: %v", err)
-		return nil, fmt.Errorf("failed to execute Azure CLI command: %w", err)
+	if recommendation.ResourceID == "" {
+		// Recommendation IDs are nested under the impacted resource
+		if idx := strings.Index(strings.ToLower(recommendation.ID), "/providers/microsoft.advisor/recommendations/"); idx > 0 {
+			recommendation.ResourceID = recommendation.ID[:idx]
+		}
 	}
+	return recommendation
+}
 
-	logger.Debugf("[ADVISOR] Command output length: %d characters", len(output))
+// stringValue returns the value of an optional string, or an empty string when it is unset
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
 
-	// Parse JSON output
-	var recommendations []CLIRecommendation
-	if err := json.Unmarshal([]byte(output), &recommendations); err != nil {
-		logger.Errorf("[ADVISOR] Failed to parse JSON output: %v", err)
-		return nil, fmt.Errorf("failed to parse recommendations JSON: %w", err)
+// extractCostSavings reads the savings amounts Advisor reports in the extended properties of cost recommendations
+func extractCostSavings(extendedProperties map[string]string) *CostSavings {
+	monthly, hasMonthly := parseAmount(extendedProperties["savingsAmount"])
+	annual, hasAnnual := parseAmount(extendedProperties["annualSavingsAmount"])
+	if !hasMonthly && !hasAnnual {
+		return nil
 	}
 
-	logger.Debugf("[ADVISOR] Successfully parsed %d recommendations from CLI output", len(recommendations))
-	return recommendations, nil
+	if !hasAnnual {
+		annual = monthly * 12
+	}
+	if !hasMonthly {
+		monthly = annual / 12
+	}
+
+	currency := extendedProperties["savingsCurrency"]
+	if currency == "" {
+		currency = "USD"
+	}
+
+	return &CostSavings{
+		Currency:       currency,
+		AnnualSavings:  annual,
+		MonthlySavings: monthly,
+	}
+}
+
+// parseAmount parses a savings amount, reporting whether a positive amount was present
+func parseAmount(value string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+	return amount, true
+}
+
+// sumCostSavings totals the potential savings of recommendations sharing the currency of the first saving found
+func sumCostSavings(recommendations []AKSRecommendationSummary) *CostSavings {
+	var total *CostSavings
+	for _, rec := range recommendations {
+		if rec.PotentialSavings == nil {
+			continue
+		}
+		if total == nil {
+			total = &CostSavings{Currency: rec.PotentialSavings.Currency}
+		}
+		if rec.PotentialSavings.Currency != total.Currency {
+			logger.Warnf("[ADVISOR] Skipping savings in %s for recommendation %s when totaling %s savings",
+				rec.PotentialSavings.Currency, rec.ID, total.Currency)
+			continue
+		}
+		total.AnnualSavings += rec.PotentialSavings.AnnualSavings
+		total.MonthlySavings += rec.PotentialSavings.MonthlySavings
+	}
+	return total
 }
 
-// filterAKSRecommendationsFromCLI filters recommendations to only AKS-related resources
-func filterAKSRecommendationsFromCLI(recommendations []CLIRecommendation) []CLIRecommendation {
-	var aksRecommendations []CLIRecommendation
+// filterAKSRecommendations filters recommendations to only AKS-related resources
+func filterAKSRecommendations(recommendations []Recommendation) []Recommendation {
+	var aksRecommendations []Recommendation
 	for _, rec := range recommendations {
-		if isAKSRelatedCLI(rec.ID) {
+		if isAKSRelated(rec.ResourceID) || isAKSRelated(rec.ID) {
 			aksRecommendations = append(aksRecommendations, rec)
 		}
 	}
 	return aksRecommendations
 }
 
-// isAKSRelatedCLI checks if a resource ID is related to AKS
-func isAKSRelatedCLI(resourceID string) bool {
+// isAKSRelated checks if a resource ID is related to AKS
+func isAKSRelated(resourceID string) bool {
 	if resourceID == "" {
 		return false
 	}
@@ -195,8 +337,8 @@ func isAKSRelatedCLI(resourceID string) bool {
 }
 
 // filterBySeverity filters recommendations by severity level
-func filterBySeverity(recommendations []CLIRecommendation, severity string) []CLIRecommendation {
-	var filtered []CLIRecommendation
+func filterBySeverity(recommendations []Recommendation, severity string) []Recommendation {
+	var filtered []Recommendation
 	for _, rec := range recommendations {
 		if strings.EqualFold(rec.Impact, severity) {
 			filtered = append(filtered, rec)
@@ -206,10 +348,10 @@ func filterBySeverity(recommendations []CLIRecommendation, severity string) []CL
 }
 
 // filterByClusterNames filters recommendations by cluster names
-func filterByClusterNames(recommendations []CLIRecommendation, clusterNames []string) []CLIRecommendation {
-	var filtered []CLIRecommendation
+func filterByClusterNames(recommendations []Recommendation, clusterNames []string) []Recommendation {
+	var filtered []Recommendation
 	for _, rec := range recommendations {
-		clusterName := extractAKSClusterNameFromCLI(rec.ID)
+		clusterName := extractAKSClusterName(rec.ID)
 		for _, filterName := range clusterNames {
 			if strings.EqualFold(clusterName, filterName) {
 				filtered = append(filtered, rec)
@@ -220,19 +362,19 @@ func filterByClusterNames(recommendations []CLIRecommendation, clusterNames []st
 	return filtered
 }
 
-// extractAKSClusterNameFromCLI extracts AKS cluster name from resource ID
-func extractAKSClusterNameFromCLI(resourceID string) string {
+// extractAKSClusterName extracts AKS cluster name from resource ID
+func extractAKSClusterName(resourceID string) string {
 	parts := strings.Split(resourceID, "/")
 	for i, part := range parts {
-		if part == "managedClusters" && i+1 < len(parts) {
+		if strings.EqualFold(part, "managedClusters") && i+1 < len(parts) {
 			return parts[i+1]
 		}
 	}
 	return ""
 }
 
-// convertToAKSRecommendationSummaries converts CLI recommendations to AKS recommendation summaries
-func convertToAKSRecommendationSummaries(recommendations []CLIRecommendation) []AKSRecommendationSummary {
+// convertToAKSRecommendationSummaries converts Advisor recommendations to AKS recommendation summaries
+func convertToAKSRecommendationSummaries(recommendations []Recommendation) []AKSRecommendationSummary {
 	var summaries []AKSRecommendationSummary
 	for _, rec := range recommendations {
 		summary := convertToAKSRecommendationSummary(rec)
@@ -241,25 +383,26 @@ func convertToAKSRecommendationSummaries(recommendations []CLIRecommendation) []
 	return summaries
 }
 
-// convertToAKSRecommendationSummary converts a single CLI recommendation to AKS recommendation summary
-func convertToAKSRecommendationSummary(rec CLIRecommendation) AKSRecommendationSummary {
-	clusterName := extractAKSClusterNameFromCLI(rec.ID)
-	resourceGroup := extractResourceGroupFromResourceID(rec.ID)
-
-	// Parse last updated time
-	lastUpdated, _ := time.Parse(time.RFC3339, rec.LastUpdated)
+// convertToAKSRecommendationSummary converts a single Advisor recommendation to AKS recommendation summary
+func convertToAKSRecommendationSummary(rec Recommendation) AKSRecommendationSummary {
+	resourceID := rec.ResourceID
+	if resourceID == "" {
+		resourceID = rec.ID
+	}
 
 	return AKSRecommendationSummary{
-		ID:            rec.ID,
-		Category:      rec.Category,
-		Impact:        rec.Impact,
-		ClusterName:   clusterName,
-		ResourceGroup: resourceGroup,
-		ResourceID:    rec.ID,
-		Description:   rec.ShortDescription.Problem + " " + rec.ShortDescription.Solution,
-		Severity:      rec.Impact, // Map impact to severity
-		LastUpdated:   lastUpdated,
-		Status:        "Active",
+		ID:               rec.ID,
+		Category:         rec.Category,
+		Impact:           rec.Impact,
+		ClusterName:      extractAKSClusterName(resourceID),
+		ResourceGroup:    extractResourceGroupFromResourceID(resourceID),
+		ResourceID:       resourceID,
+		SubscriptionID:   rec.SubscriptionID,
+		Description:      strings.TrimSpace(rec.Problem + " " + rec.Solution),
+		Severity:         rec.Impact, // Map impact to severity
+		PotentialSavings: extractCostSavings(rec.ExtendedProperties),
+		LastUpdated:      rec.LastUpdated,
+		Status:           "Active",
 		AKSSpecific: AKSRecommendationDetails{
 			ConfigurationArea: mapCategoryToConfigArea(rec.Category),
 		},
@@ -270,7 +413,7 @@ func convertToAKSRecommendationSummary(rec CLIRecommendation) AKSRecommendationS
 func extractResourceGroupFromResourceID(resourceID string) string {
 	parts := strings.Split(resourceID, "/")
 	for i, part := range parts {
-		if part == "resourceGroups" && i+1 < len(parts) {
+		if strings.EqualFold(part, "resourceGroups") && i+1 < len(parts) {
 			return parts[i+1]
 		}
 	}
@@ -329,10 +472,11 @@ func generateReportSummary(recommendations []AKSRecommendationSummary) AKSReport
 	}
 
 	return AKSReportSummary{
-		TotalRecommendations: len(recommendations),
-		ByCategory:           byCategory,
-		BySeverity:           bySeverity,
-		ClustersAffected:     len(clustersMap),
+		TotalRecommendations:  len(recommendations),
+		ByCategory:            byCategory,
+		BySeverity:            bySeverity,
+		TotalPotentialSavings: sumCostSavings(recommendations),
+		ClustersAffected:      len(clustersMap),
 	}
 }
 
@@ -406,6 +550,7 @@ func groupRecommendationsByCluster(recommendations []AKSRecommendationSummary) [
 			ClusterName:     clusterName,
 			ResourceGroup:   rgMap[clusterName],
 			Recommendations: recs,
+			TotalSavings:    sumCostSavings(recs),
 		})
 	}
 
@@ -3,6 +3,7 @@ package advisor
 import (
 	"context"
 
+	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/tools"
 )
@@ -12,9 +13,9 @@ import (
 // =============================================================================
 
 // GetAdvisorRecommendationHandler returns a handler for the aks_advisor_recommendation command
-func GetAdvisorRecommendationHandler(cfg *config.ConfigData) tools.ResourceHandler {
+func GetAdvisorRecommendationHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
 		// Use the advisor package handler directly
-		return HandleAdvisorRecommendation(ctx, params, cfg)
+		return HandleAdvisorRecommendation(ctx, params, azClient, cfg)
 	})
 }
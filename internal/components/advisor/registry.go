@@ -18,8 +18,7 @@ func RegisterAdvisorRecommendationTool() mcp.Tool {
 			mcp.Required(),
 		),
 		mcp.WithString("subscription_id",
-			mcp.Description("Azure subscription ID to query recommendations"),
-			mcp.Required(),
+			mcp.Description("Azure subscription ID to query recommendations. Multiple subscriptions can be given as a comma-separated list. Defaults to the allowed subscriptions, or to the default subscription"),
 		),
 		mcp.WithString("resource_group",
 			mcp.Description("Filter by specific resource group containing AKS clusters"),
@@ -28,7 +27,7 @@ func RegisterAdvisorRecommendationTool() mcp.Tool {
 			mcp.Description("Comma-separated list of specific AKS cluster names to filter recommendations"),
 		),
 		mcp.WithString("category",
-			mcp.Description("Filter by recommendation category: Cost, HighAvailability, Performance, Security"),
+			mcp.Description("Filter by recommendation category: Cost, HighAvailability, OperationalExcellence, Performance, Security"),
 		),
 		mcp.WithString("severity",
 			mcp.Description("Filter by severity level: High, Medium, Low"),
@@ -12,6 +12,7 @@ type AKSRecommendationSummary struct {
 	ClusterName      string                   `json:"cluster_name"`
 	ResourceGroup    string                   `json:"resource_group"`
 	ResourceID       string                   `json:"resource_id"`
+	SubscriptionID   string                   `json:"subscription_id,omitempty"`
 	Description      string                   `json:"description"`
 	Severity         string                   `json:"severity"`
 	PotentialSavings *CostSavings             `json:"potential_savings,omitempty"`
@@ -73,16 +74,18 @@ type ClusterRecommendations struct {
 	TotalSavings    *CostSavings               `json:"total_savings,omitempty"`
 }
 
-// CLIRecommendation represents the raw Azure CLI recommendation structure
-type CLIRecommendation struct {
-	ID               string `json:"id"`
-	Name             string `json:"name"`
-	Category         string `json:"category"`
-	Impact           string `json:"impact"`
-	ImpactedValue    string `json:"impactedValue"`
-	LastUpdated      string `json:"lastUpdated"`
-	ShortDescription struct {
-		Problem  string `json:"problem"`
-		Solution string `json:"solution"`
-	} `json:"shortDescription"`
+// Recommendation is a flattened Azure Advisor recommendation
+type Recommendation struct {
+	ID                 string
+	Name               string
+	SubscriptionID     string
+	ResourceID         string
+	Category           string
+	Impact             string
+	ImpactedField      string
+	ImpactedValue      string
+	Problem            string
+	Solution           string
+	LastUpdated        time.Time
+	ExtendedProperties map[string]string
 }
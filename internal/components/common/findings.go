@@ -0,0 +1,39 @@
+package common
+
+import "slices"
+
+// Severity levels of findings
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Finding is an observation of a diagnostic analysis, identified by a stable code
+type Finding struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// Findings is the list of findings of an analysis
+type Findings []Finding
+
+// Add records a finding
+func (f *Findings) Add(severity, code, message string) {
+	*f = append(*f, Finding{Severity: severity, Code: code, Message: message})
+}
+
+// Codes returns the codes of the findings in order
+func (f Findings) Codes() []string {
+	codes := make([]string, 0, len(f))
+	for _, finding := range f {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+// Has reports whether a finding with the code was recorded
+func (f Findings) Has(code string) bool {
+	return slices.ContainsFunc(f, func(finding Finding) bool { return finding.Code == code })
+}
@@ -0,0 +1,29 @@
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFindings(t *testing.T) {
+	var findings Findings
+	if findings.Has("any") || len(findings.Codes()) != 0 {
+		t.Errorf("Expected no findings, got %v", findings)
+	}
+
+	findings.Add(SeverityError, "first", "First finding")
+	findings.Add(SeverityWarning, "second", "Second finding")
+
+	if !reflect.DeepEqual(findings.Codes(), []string{"first", "second"}) {
+		t.Errorf("Unexpected codes: %v", findings.Codes())
+	}
+	if !findings.Has("second") || findings.Has("third") {
+		t.Errorf("Unexpected Has results for %v", findings)
+	}
+
+	data, err := json.Marshal(findings[:1])
+	if err != nil || string(data) != `[{"severity":"error","code":"first","message":"First finding"}]` {
+		t.Errorf("Unexpected encoding %s, %v", data, err)
+	}
+}
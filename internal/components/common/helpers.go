@@ -3,9 +3,12 @@ package common
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
@@ -14,6 +17,15 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
 )
 
+var (
+	// subscriptionIDPattern matches a subscription GUID
+	subscriptionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// resourceGroupNamePattern matches valid resource group names
+	resourceGroupNamePattern = regexp.MustCompile(`^[-\w.()]{1,90}$`)
+	// clusterNamePattern matches valid AKS cluster names
+	clusterNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+)
+
 // ExtractAKSParameters extracts and validates the common AKS parameters from the params map
 func ExtractAKSParameters(params map[string]interface{}) (subscriptionID, resourceGroup, clusterName string, err error) {
 	subID, ok := params["subscription_id"].(string)
@@ -34,6 +46,33 @@ func ExtractAKSParameters(params map[string]interface{}) (subscriptionID, resour
 	return subID, rg, clusterNameParam, nil
 }
 
+// ValidateSubscriptionID checks that a subscription ID is a GUID, so it can be embedded in resource
+// IDs and OData filters
+func ValidateSubscriptionID(subscriptionID string) error {
+	if !subscriptionIDPattern.MatchString(subscriptionID) {
+		return fmt.Errorf("invalid subscription ID: %s", subscriptionID)
+	}
+	return nil
+}
+
+// ValidateResourceGroupName checks that a resource group name is valid, so it can be embedded in
+// resource IDs and OData filters
+func ValidateResourceGroupName(resourceGroup string) error {
+	if !resourceGroupNamePattern.MatchString(resourceGroup) {
+		return fmt.Errorf("invalid resource group name: %s", resourceGroup)
+	}
+	return nil
+}
+
+// ValidateClusterName checks that an AKS cluster name is valid, so it can be embedded in resource
+// IDs and OData filters
+func ValidateClusterName(clusterName string) error {
+	if !clusterNamePattern.MatchString(clusterName) {
+		return fmt.Errorf("invalid cluster name: %s", clusterName)
+	}
+	return nil
+}
+
 // ExtractAKSParametersFromResourceID extracts and validates AKS parameters from aks_resource_id
 func ExtractAKSParametersFromResourceID(params map[string]interface{}) (subscriptionID, resourceGroup, clusterName string, err error) {
 	aksResourceID, ok := params["aks_resource_id"].(string)
@@ -89,3 +128,87 @@ func GetDefaultSubscriptionID(cfg *config.ConfigData) (string, error) {
 
 	return subID, nil
 }
+
+// WithConfigTimeout derives a context bounded by the configured command timeout.
+// The parent context is returned unchanged when no timeout is configured.
+func WithConfigTimeout(ctx context.Context, cfg *config.ConfigData) (context.Context, context.CancelFunc) {
+	if cfg == nil || cfg.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(cfg.Timeout)*time.Second)
+}
+
+// ResolveSubscriptions determines the subscriptions a multi-subscription query runs against.
+// Requested subscriptions must be part of the allowlist when one is configured;
+// an empty request resolves to the whole allowlist, or to the default subscription without one.
+func ResolveSubscriptions(requested string, cfg *config.ConfigData, defaultSubscription func(*config.ConfigData) (string, error)) ([]string, error) {
+	var allowed []string
+	if cfg != nil {
+		allowed = cfg.AllowedSubscriptions
+	}
+
+	var subscriptions []string
+	for _, sub := range strings.Split(requested, ",") {
+		if sub = strings.TrimSpace(sub); sub == "" {
+			continue
+		}
+		if err := ValidateSubscriptionID(sub); err != nil {
+			return nil, err
+		}
+		if len(allowed) > 0 && !containsFold(allowed, sub) {
+			return nil, fmt.Errorf("subscription %s is not in the allowed subscriptions list", sub)
+		}
+		if !containsFold(subscriptions, sub) {
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+
+	if len(subscriptions) > 0 {
+		return subscriptions, nil
+	}
+	if len(allowed) > 0 {
+		return allowed, nil
+	}
+
+	sub, err := defaultSubscription(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []string{sub}, nil
+}
+
+// containsFold reports whether values contains target, ignoring case
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeOperationParams merges the top-level parameters of an operation tool with its nested "parameters" JSON string.
+// Top-level parameters take precedence.
+func MergeOperationParams(params map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		merged[key] = value
+	}
+
+	parametersStr, ok := params["parameters"].(string)
+	if !ok || parametersStr == "" {
+		return merged, nil
+	}
+
+	var nestedParams map[string]interface{}
+	if err := json.Unmarshal([]byte(parametersStr), &nestedParams); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters JSON: %w", err)
+	}
+	for key, value := range nestedParams {
+		if _, exists := merged[key]; !exists {
+			merged[key] = value
+		}
+	}
+
+	return merged, nil
+}
@@ -1,8 +1,12 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-mcp/internal/config"
 )
@@ -101,6 +105,45 @@ func TestExtractAKSParameters(t *testing.T) {
 	}
 }
 
+func TestValidateSubscriptionID(t *testing.T) {
+	for _, id := range []string{"00000000-0000-0000-0000-000000000000", "AAAAAAAA-bbbb-CCCC-dddd-0123456789ab"} {
+		if err := ValidateSubscriptionID(id); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "sub", "00000000-0000-0000-0000-000000000000' or resourceUri ne '", "00000000-0000-0000-0000-000000000000/resourceGroups/x"} {
+		if err := ValidateSubscriptionID(id); err == nil {
+			t.Errorf("Expected %q to be invalid", id)
+		}
+	}
+}
+
+func TestValidateResourceGroupName(t *testing.T) {
+	for _, name := range []string{"rg", "MC_rg_cluster_eastus", "my.rg(1)-x", strings.Repeat("a", 90)} {
+		if err := ValidateResourceGroupName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "rg' or resourceGroupName eq 'other", "rg/x", strings.Repeat("a", 91)} {
+		if err := ValidateResourceGroupName(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestValidateClusterName(t *testing.T) {
+	for _, name := range []string{"c", "my-cluster_1", strings.Repeat("a", 63)} {
+		if err := ValidateClusterName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-cluster", "cluster'", "my.cluster", strings.Repeat("a", 64)} {
+		if err := ValidateClusterName(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
+
 func TestGetDefaultSubscriptionID_FromEnv(t *testing.T) {
 	originalEnv := os.Getenv("AZURE_SUBSCRIPTION_ID")
 	defer func() {
@@ -131,3 +174,86 @@ func TestGetDefaultSubscriptionID_FromEnv(t *testing.T) {
 func TestGetDefaultSubscriptionID_NoEnv(t *testing.T) {
 	t.Skip("Skipping test that requires Azure CLI authentication")
 }
+
+func TestWithConfigTimeout(t *testing.T) {
+	ctx, cancel := WithConfigTimeout(context.Background(), &config.ConfigData{Timeout: 30})
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("Expected context to have a deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > 30*time.Second {
+		t.Errorf("Expected deadline within 30s, got %v", remaining)
+	}
+
+	ctx, cancel = WithConfigTimeout(context.Background(), &config.ConfigData{})
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline when timeout is not configured")
+	}
+}
+
+func TestResolveSubscriptions(t *testing.T) {
+	const (
+		subA = "11111111-1111-1111-1111-111111111111"
+		subB = "22222222-2222-2222-2222-222222222222"
+		subC = "33333333-3333-3333-3333-333333333333"
+	)
+	defaultSub := func(*config.ConfigData) (string, error) { return subC, nil }
+	noDefault := func(*config.ConfigData) (string, error) { return "", errors.New("no default subscription") }
+
+	tests := []struct {
+		name       string
+		requested  string
+		allowed    []string
+		defaultSub func(*config.ConfigData) (string, error)
+		want       []string
+		wantErr    string
+	}{
+		{name: "allowlist used when nothing requested", allowed: []string{subA, subB}, defaultSub: noDefault, want: []string{subA, subB}},
+		{name: "requested subset of allowlist", requested: subB, allowed: []string{subA, subB}, defaultSub: noDefault, want: []string{subB}},
+		{name: "requested outside allowlist", requested: subC, allowed: []string{subA}, defaultSub: noDefault, wantErr: "not in the allowed"},
+		{name: "duplicates removed", requested: subA + ", " + strings.ToUpper(subA), allowed: []string{subA}, defaultSub: noDefault, want: []string{subA}},
+		{name: "invalid subscription", requested: "not-a-guid", defaultSub: noDefault, wantErr: "invalid subscription ID"},
+		{name: "no allowlist uses requested", requested: subB, defaultSub: noDefault, want: []string{subB}},
+		{name: "no allowlist falls back to default", defaultSub: defaultSub, want: []string{subC}},
+		{name: "no allowlist and no default", defaultSub: noDefault, wantErr: "no default subscription"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ConfigData{AllowedSubscriptions: tt.allowed}
+			got, err := ResolveSubscriptions(tt.requested, cfg, tt.defaultSub)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMergeOperationParams(t *testing.T) {
+	merged, err := MergeOperationParams(map[string]interface{}{
+		"operation":  "network_flow_check",
+		"port":       float64(80),
+		"parameters": `{"source":"Internet","port":443}`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if merged["source"] != "Internet" {
+		t.Errorf("Expected nested source to be merged, got %v", merged["source"])
+	}
+	if merged["port"] != float64(80) {
+		t.Errorf("Expected top-level port to take precedence, got %v", merged["port"])
+	}
+}
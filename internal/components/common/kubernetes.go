@@ -0,0 +1,47 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KubernetesClientForCluster creates a client from the kubeconfig and checks that its current
+// context points at the API server of the cluster
+func KubernetesClientForCluster(cluster *armcontainerservice.ManagedCluster) (kubernetes.Interface, error) {
+	restConfig, err := genericclioptions.NewConfigFlags(false).ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	if !APIServerHostMatchesCluster(restConfig.Host, cluster) {
+		return nil, fmt.Errorf("the current kubeconfig context points at %s, not at the API server of this cluster; run 'az aks get-credentials' for the cluster first", restConfig.Host)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	return kubeClient, nil
+}
+
+// APIServerHostMatchesCluster reports whether a kubeconfig server URL is one of the API server FQDNs of the cluster
+func APIServerHostMatchesCluster(server string, cluster *armcontainerservice.ManagedCluster) bool {
+	if cluster == nil || cluster.Properties == nil {
+		return false
+	}
+	parsed, err := url.Parse(server)
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	properties := cluster.Properties
+	for _, fqdn := range []*string{properties.Fqdn, properties.PrivateFQDN, properties.AzurePortalFQDN} {
+		if fqdn != nil && strings.EqualFold(parsed.Hostname(), *fqdn) {
+			return true
+		}
+	}
+	return false
+}
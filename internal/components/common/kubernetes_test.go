@@ -0,0 +1,28 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+func TestAPIServerHostMatchesCluster(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			Fqdn:        to.Ptr("aks-abc.hcp.eastus.azmk8s.io"),
+			PrivateFQDN: to.Ptr("aks-abc.privatelink.eastus.azmk8s.io"),
+		},
+	}
+	tests := map[string]bool{
+		"https://aks-abc.hcp.eastus.azmk8s.io:443":         true,
+		"https://AKS-ABC.privatelink.eastus.azmk8s.io:443": true,
+		"https://other.hcp.eastus.azmk8s.io:443":           false,
+		"":                                                 false,
+	}
+	for server, expected := range tests {
+		if matches := APIServerHostMatchesCluster(server, cluster); matches != expected {
+			t.Errorf("Expected %v for %q, got %v", expected, server, matches)
+		}
+	}
+}
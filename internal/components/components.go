@@ -22,6 +22,7 @@ func GetAllComponents() []Component {
 		{Name: "compute", Description: "Azure compute resources (VMSS/VM) for AKS"},
 		{Name: "detectors", Description: "AppLens detector integration for AKS"},
 		{Name: "advisor", Description: "Azure Advisor recommendations for AKS"},
+		{Name: "resourcegraph", Description: "Azure Resource Graph inventory queries for AKS"},
 		{Name: "inspektorgadget", Description: "eBPF-based observability tools"},
 
 		// Kubernetes Components
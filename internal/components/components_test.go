@@ -35,8 +35,8 @@ func TestValidateComponents_ValidComponents(t *testing.T) {
 		},
 		{
 			name:       "all azure components",
-			components: []string{"az_cli", "monitor", "fleet", "network", "compute", "detectors", "advisor", "inspektorgadget"},
-			wantValid:  8,
+			components: []string{"az_cli", "monitor", "fleet", "network", "compute", "detectors", "advisor", "resourcegraph", "inspektorgadget"},
+			wantValid:  9,
 		},
 		{
 			name:       "all kubernetes components",
@@ -175,13 +175,13 @@ func TestIsComponentEnabled_InvalidComponent(t *testing.T) {
 
 func TestGetAllComponents(t *testing.T) {
 	components := GetAllComponents()
-	if len(components) != 12 {
-		t.Errorf("Expected 12 components, got: %d", len(components))
+	if len(components) != 13 {
+		t.Errorf("Expected 13 components, got: %d", len(components))
 	}
 
 	// Verify all expected components exist
 	expectedComponents := []string{
-		"az_cli", "monitor", "fleet", "network", "compute", "detectors", "advisor", "inspektorgadget",
+		"az_cli", "monitor", "fleet", "network", "compute", "detectors", "advisor", "resourcegraph", "inspektorgadget",
 		"kubectl", "helm", "cilium", "hubble",
 	}
 
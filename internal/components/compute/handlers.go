@@ -33,6 +33,18 @@ func GetAKSVMSSInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigDa
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
 		}
 
+		// The instance report covers the same scopes as the VMSS model
+		if includeInstances, _ := params["include_instances"].(bool); includeInstances {
+			nodePoolName, _ := params["node_pool_name"].(string)
+			nodeName, _ := params["node_name"].(string)
+			return getVMSSInstanceReports(ctx, client, cluster, nodePoolName, nodeName, rg, clusterName)
+		}
+
+		// A node name or providerID selects the VMSS of that node
+		if nodeName, _ := params["node_name"].(string); nodeName != "" {
+			return getVMSSByNode(ctx, client, cluster, nodeName, rg, clusterName)
+		}
+
 		// Check if node_pool_name is provided
 		nodePoolName, hasNodePool := params["node_pool_name"].(string)
 
@@ -95,6 +107,39 @@ func getSingleVMSSByNodePool(ctx context.Context, client *azureclient.AzureClien
 	return string(resultJSON), nil
 }
 
+// getVMSSByNode handles getting VMSS info for the scale set of a single node
+func getVMSSByNode(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, nodeName, rg, clusterName string) (string, error) {
+	instance, err := ResolveNodeInstance(ctx, cluster, nodeName, client)
+	if err != nil {
+		return "", err
+	}
+
+	vmssInterface, err := client.GetResourceByID(ctx, instance.VMSSID())
+	if err != nil {
+		return "", fmt.Errorf("failed to get VMSS details: %v", err)
+	}
+
+	vmss, ok := vmssInterface.(*armcompute.VirtualMachineScaleSet)
+	if !ok {
+		return "", fmt.Errorf("unexpected resource type returned for VMSS")
+	}
+
+	result := map[string]interface{}{
+		"cluster_name":   clusterName,
+		"resource_group": rg,
+		"node":           instance,
+		"vmss_id":        instance.VMSSID(),
+		"vmss":           vmss,
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal VMSS info to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
 // getAllVMSSByCluster handles getting VMSS info for all node pools in the cluster
 func getAllVMSSByCluster(ctx context.Context, client *azureclient.AzureClient, cluster interface{}, subID, rg, clusterName string) (string, error) {
 	// Type assert cluster to the correct type
@@ -0,0 +1,234 @@
+// Package imagedrift compares the node image, OS SKU and Kubernetes version of AKS node pools and
+// their VMSS instances with the latest available node image.
+package imagedrift
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+// Instance is the image a VMSS instance of a node pool was created or last reimaged from
+type Instance struct {
+	InstanceID string
+	NodeName   string
+	// Image is the gallery and image definition, e.g. AKSUbuntu/2204gen2containerd
+	Image   string
+	Version string
+}
+
+// Pool is the node image state of a node pool
+type Pool struct {
+	Name                string
+	Mode                string
+	OSType              string
+	OSSKU               string
+	OrchestratorVersion string
+	// NodeImageVersion is the agent pool nodeImageVersion, e.g. AKSUbuntu-2204gen2containerd-202410.09.0
+	NodeImageVersion       string
+	LatestNodeImageVersion string
+	// LatestError is set when the latest node image version could not be determined
+	LatestError    string
+	Instances      []Instance
+	InstancesError string
+}
+
+// Input is the state of the cluster node pools
+type Input struct {
+	KubernetesVersion string
+	UpgradeChannel    string
+	Pools             []Pool
+}
+
+// InstanceReport is an instance whose image differs from the node image of its pool
+type InstanceReport struct {
+	InstanceID string `json:"instance_id"`
+	NodeName   string `json:"node_name,omitempty"`
+	Image      string `json:"image,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// PoolReport is the node image report of a node pool
+type PoolReport struct {
+	Name                   string           `json:"name"`
+	Mode                   string           `json:"mode,omitempty"`
+	OSType                 string           `json:"os_type,omitempty"`
+	OSSKU                  string           `json:"os_sku,omitempty"`
+	OrchestratorVersion    string           `json:"orchestrator_version,omitempty"`
+	NodeImageVersion       string           `json:"node_image_version,omitempty"`
+	LatestNodeImageVersion string           `json:"latest_node_image_version,omitempty"`
+	BehindLatest           bool             `json:"behind_latest"`
+	InstanceCount          int              `json:"instance_count"`
+	LaggingInstances       []InstanceReport `json:"lagging_instances"`
+	Error                  string           `json:"error,omitempty"`
+}
+
+// Result is the node image drift report of a cluster
+type Result struct {
+	KubernetesVersion string          `json:"kubernetes_version,omitempty"`
+	UpgradeChannel    string          `json:"upgrade_channel"`
+	PoolsBehindLatest []string        `json:"pools_behind_latest"`
+	Pools             []PoolReport    `json:"pools"`
+	Findings          common.Findings `json:"findings"`
+}
+
+// ParseNodeImageVersion splits an agent pool nodeImageVersion into the image and the version,
+// e.g. AKSUbuntu-2204gen2containerd-202410.09.0 into AKSUbuntu-2204gen2containerd and 202410.09.0
+func ParseNodeImageVersion(nodeImageVersion string) (string, string) {
+	i := strings.LastIndex(nodeImageVersion, "-")
+	if i < 0 {
+		return "", nodeImageVersion
+	}
+	return nodeImageVersion[:i], nodeImageVersion[i+1:]
+}
+
+// sameImage reports whether the gallery image of an instance, e.g. AKSUbuntu/2204gen2containerd,
+// is the image of a nodeImageVersion prefix, e.g. AKSUbuntu-2204gen2containerd. Gallery names
+// don't map to the prefix (AKSWindows/windows-2022-containerd is AKSWindows-2022-containerd,
+// community galleries are named AKSUbuntu-<guid>), so only the image definition is matched against
+// the end of the prefix, ignoring case and separators.
+func sameImage(instanceImage, nodeImage string) bool {
+	definition := instanceImage[strings.LastIndex(instanceImage, "/")+1:]
+	return strings.HasSuffix(alphanumeric(nodeImage), alphanumeric(definition))
+}
+
+// alphanumeric returns the lower case letters and digits of s
+func alphanumeric(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// CompareVersions compares dotted versions numerically, e.g. 202409.30.0 < 202410.09.0 and
+// 1.29.9 < 1.29.10. Missing segments count as 0 and non-numeric segments are compared as strings.
+func CompareVersions(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		aNum, aErr := strconv.Atoi(aPart)
+		bNum, bErr := strconv.Atoi(bPart)
+		if aErr == nil && bErr == nil {
+			if aNum != bNum {
+				return aNum - bNum
+			}
+			continue
+		}
+		if c := strings.Compare(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Analyze compares each pool with the latest node image and each instance with its pool
+func Analyze(input Input) *Result {
+	result := &Result{
+		KubernetesVersion: input.KubernetesVersion,
+		UpgradeChannel:    input.UpgradeChannel,
+		PoolsBehindLatest: []string{},
+		Pools:             []PoolReport{},
+		Findings:          common.Findings{},
+	}
+	if result.UpgradeChannel == "" {
+		result.UpgradeChannel = "none"
+	}
+
+	linuxSKUs := []string{}
+	for _, pool := range input.Pools {
+		report := result.analyzePool(pool, input.KubernetesVersion)
+		result.Pools = append(result.Pools, report)
+		if report.BehindLatest {
+			result.PoolsBehindLatest = append(result.PoolsBehindLatest, pool.Name)
+		}
+		if strings.EqualFold(pool.OSType, "Linux") && pool.OSSKU != "" && !slices.Contains(linuxSKUs, pool.OSSKU) {
+			linuxSKUs = append(linuxSKUs, pool.OSSKU)
+		}
+	}
+
+	if len(result.PoolsBehindLatest) > 0 && strings.EqualFold(result.UpgradeChannel, "none") {
+		result.Findings.Add(common.SeverityInfo, "no_auto_upgrade_channel",
+			"the cluster has no auto-upgrade channel, node images are only updated by manual upgrades; consider the node-image channel or a planned maintenance window")
+	}
+	if len(linuxSKUs) > 1 {
+		result.Findings.Add(common.SeverityInfo, "os_sku_mixed",
+			fmt.Sprintf("Linux node pools run different OS SKUs (%s), so they receive different node images and security patches", strings.Join(linuxSKUs, ", ")))
+	}
+	return result
+}
+
+// analyzePool builds the report of a node pool and records its findings
+func (r *Result) analyzePool(pool Pool, kubernetesVersion string) PoolReport {
+	report := PoolReport{
+		Name:                   pool.Name,
+		Mode:                   pool.Mode,
+		OSType:                 pool.OSType,
+		OSSKU:                  pool.OSSKU,
+		OrchestratorVersion:    pool.OrchestratorVersion,
+		NodeImageVersion:       pool.NodeImageVersion,
+		LatestNodeImageVersion: pool.LatestNodeImageVersion,
+		InstanceCount:          len(pool.Instances),
+		LaggingInstances:       []InstanceReport{},
+		Error:                  pool.InstancesError,
+	}
+	poolImage, poolVersion := ParseNodeImageVersion(pool.NodeImageVersion)
+
+	switch {
+	case pool.LatestError != "":
+		r.Findings.Add(common.SeverityInfo, "latest_image_unknown",
+			fmt.Sprintf("the latest node image of node pool %s could not be determined: %s", pool.Name, pool.LatestError))
+	case pool.LatestNodeImageVersion != "" && pool.NodeImageVersion != "":
+		_, latestVersion := ParseNodeImageVersion(pool.LatestNodeImageVersion)
+		if CompareVersions(poolVersion, latestVersion) < 0 {
+			report.BehindLatest = true
+			r.Findings.Add(common.SeverityWarning, "pool_image_behind",
+				fmt.Sprintf("node pool %s runs node image %s, the latest is %s; upgrade it with az aks nodepool upgrade --node-image-only", pool.Name, pool.NodeImageVersion, pool.LatestNodeImageVersion))
+		}
+	}
+
+	if kubernetesVersion != "" && pool.OrchestratorVersion != "" && CompareVersions(pool.OrchestratorVersion, kubernetesVersion) < 0 {
+		r.Findings.Add(common.SeverityInfo, "pool_kubernetes_behind",
+			fmt.Sprintf("node pool %s runs Kubernetes %s, the control plane runs %s", pool.Name, pool.OrchestratorVersion, kubernetesVersion))
+	}
+
+	if poolVersion == "" {
+		return report
+	}
+	for _, instance := range pool.Instances {
+		reason := ""
+		switch {
+		case instance.Image != "" && poolImage != "" && !sameImage(instance.Image, poolImage):
+			reason = fmt.Sprintf("runs image %s instead of %s", instance.Image, poolImage)
+		case instance.Version != "" && CompareVersions(instance.Version, poolVersion) < 0:
+			reason = fmt.Sprintf("runs image version %s, the node pool is on %s", instance.Version, poolVersion)
+		default:
+			continue
+		}
+		report.LaggingInstances = append(report.LaggingInstances, InstanceReport{
+			InstanceID: instance.InstanceID,
+			NodeName:   instance.NodeName,
+			Image:      instance.Image,
+			Version:    instance.Version,
+			Reason:     reason,
+		})
+	}
+	if len(report.LaggingInstances) > 0 {
+		r.Findings.Add(common.SeverityWarning, "instances_image_behind",
+			fmt.Sprintf("%d of %d instances of node pool %s do not run the node image of the pool; reimage them or rerun the node image upgrade", len(report.LaggingInstances), len(pool.Instances), pool.Name))
+	}
+	return report
+}
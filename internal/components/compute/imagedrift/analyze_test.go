@@ -0,0 +1,153 @@
+package imagedrift
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseNodeImageVersion(t *testing.T) {
+	image, version := ParseNodeImageVersion("AKSUbuntu-2204gen2containerd-202410.09.0")
+	if image != "AKSUbuntu-2204gen2containerd" || version != "202410.09.0" {
+		t.Errorf("Unexpected image %q and version %q", image, version)
+	}
+
+	image, version = ParseNodeImageVersion("202410.09.0")
+	if image != "" || version != "202410.09.0" {
+		t.Errorf("Expected a bare version, got image %q and version %q", image, version)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"202409.30.0", "202410.09.0", -1},
+		{"202410.09.0", "202410.09.0", 0},
+		{"1.29.10", "1.29.9", 1},
+		{"v1.30", "1.30.0", 0},
+		{"20348.2700.240911", "20348.2762.241009", -1},
+	}
+	for _, tt := range tests {
+		got := CompareVersions(tt.a, tt.b)
+		if (got < 0 && tt.want >= 0) || (got > 0 && tt.want <= 0) || (got == 0 && tt.want != 0) {
+			t.Errorf("CompareVersions(%s, %s) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSameImage(t *testing.T) {
+	tests := []struct {
+		instanceImage string
+		nodeImage     string
+		expected      bool
+	}{
+		{"AKSUbuntu/2204gen2containerd", "AKSUbuntu-2204gen2containerd", true},
+		{"AKSAzureLinux/V2gen2", "AKSAzureLinux-V2gen2", true},
+		{"AKSWindows/windows-2022-containerd", "AKSWindows-2022-containerd", true},
+		{"AKSUbuntu-38d80f77-467a-481f-a8d4-09b6d4220bd2/2204gen2containerd", "AKSUbuntu-2204gen2containerd", true},
+		{"AKSUbuntu/2204containerd", "AKSUbuntu-2204gen2containerd", false},
+		{"AKSWindows/windows-2019-containerd", "AKSWindows-2022-containerd", false},
+		{"AKSUbuntu/2204gen2containerd", "AKSAzureLinux-V2gen2", false},
+	}
+
+	for _, tt := range tests {
+		if result := sameImage(tt.instanceImage, tt.nodeImage); result != tt.expected {
+			t.Errorf("sameImage(%q, %q) = %v, want %v", tt.instanceImage, tt.nodeImage, result, tt.expected)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	input := Input{
+		KubernetesVersion: "1.30.5",
+		Pools: []Pool{
+			{
+				Name:                   "system",
+				OSType:                 "Linux",
+				OSSKU:                  "Ubuntu",
+				OrchestratorVersion:    "1.30.5",
+				NodeImageVersion:       "AKSUbuntu-2204gen2containerd-202409.30.0",
+				LatestNodeImageVersion: "AKSUbuntu-2204gen2containerd-202410.09.0",
+				Instances: []Instance{
+					{InstanceID: "0", Image: "AKSUbuntu/2204gen2containerd", Version: "202409.30.0"},
+					{InstanceID: "1", Image: "AKSUbuntu/2204gen2containerd", Version: "202409.15.0"},
+				},
+			},
+			{
+				Name:                   "user",
+				OSType:                 "Linux",
+				OSSKU:                  "AzureLinux",
+				OrchestratorVersion:    "1.29.9",
+				NodeImageVersion:       "AKSAzureLinux-V2gen2-202410.09.0",
+				LatestNodeImageVersion: "AKSAzureLinux-V2gen2-202410.09.0",
+				Instances: []Instance{
+					{InstanceID: "3", NodeName: "aks-user-1234-vmss000003", Image: "AKSUbuntu/2204gen2containerd", Version: "202410.09.0"},
+				},
+			},
+			{
+				Name:        "win",
+				OSType:      "Windows",
+				LatestError: "upgrade profile not found",
+			},
+		},
+	}
+
+	result := Analyze(input)
+
+	if result.UpgradeChannel != "none" {
+		t.Errorf("Expected an unset upgrade channel to be reported as none, got %s", result.UpgradeChannel)
+	}
+	if !slices.Equal(result.PoolsBehindLatest, []string{"system"}) {
+		t.Errorf("Unexpected pools behind latest: %v", result.PoolsBehindLatest)
+	}
+	if lagging := result.Pools[0].LaggingInstances; len(lagging) != 1 || lagging[0].InstanceID != "1" {
+		t.Errorf("Expected instance 1 of the system pool to lag, got %+v", lagging)
+	}
+	if lagging := result.Pools[1].LaggingInstances; len(lagging) != 1 || lagging[0].NodeName != "aks-user-1234-vmss000003" {
+		t.Errorf("Expected the instance with another image to lag, got %+v", lagging)
+	}
+
+	for _, code := range []string{"pool_image_behind", "instances_image_behind", "pool_kubernetes_behind", "latest_image_unknown", "no_auto_upgrade_channel", "os_sku_mixed"} {
+		if !result.Findings.Has(code) {
+			t.Errorf("Expected finding %s, got %v", code, result.Findings.Codes())
+		}
+	}
+}
+
+func TestAnalyzeUpToDate(t *testing.T) {
+	result := Analyze(Input{
+		KubernetesVersion: "1.30.5",
+		UpgradeChannel:    "node-image",
+		Pools: []Pool{{
+			Name:                   "system",
+			OSType:                 "Linux",
+			OSSKU:                  "Ubuntu",
+			OrchestratorVersion:    "1.30.5",
+			NodeImageVersion:       "AKSUbuntu-2204gen2containerd-202410.09.0",
+			LatestNodeImageVersion: "AKSUbuntu-2204gen2containerd-202410.09.0",
+			Instances:              []Instance{{InstanceID: "0", Image: "AKSUbuntu/2204gen2containerd", Version: "202410.09.0"}},
+		}, {
+			Name:                   "community",
+			OSType:                 "Linux",
+			OSSKU:                  "Ubuntu",
+			OrchestratorVersion:    "1.30.5",
+			NodeImageVersion:       "AKSUbuntu-2204gen2containerd-202410.09.0",
+			LatestNodeImageVersion: "AKSUbuntu-2204gen2containerd-202410.09.0",
+			// Image of a CommunityGalleryImageID, whose gallery name ends with a GUID
+			Instances: []Instance{{InstanceID: "0", Image: "AKSUbuntu-38d80f77-467a-481f-a8d4-09b6d4220bd2/2204gen2containerd", Version: "202410.09.0"}},
+		}, {
+			Name:                   "win",
+			OSType:                 "Windows",
+			OSSKU:                  "Windows2022",
+			OrchestratorVersion:    "1.30.5",
+			NodeImageVersion:       "AKSWindows-2022-containerd-20348.2700.240911",
+			LatestNodeImageVersion: "AKSWindows-2022-containerd-20348.2700.240911",
+			Instances:              []Instance{{InstanceID: "0", Image: "AKSWindows/windows-2022-containerd", Version: "20348.2700.240911"}},
+		}},
+	})
+
+	if len(result.Findings) != 0 || len(result.PoolsBehindLatest) != 0 {
+		t.Errorf("Expected no drift, got %+v", result)
+	}
+}
@@ -0,0 +1,213 @@
+// Package jobs runs long operations such as VMSS run commands in the background and keeps their
+// results in memory for a limited time, so that MCP requests return immediately with a job ID.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Status of a job
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+const (
+	// DefaultTTL is how long a completed job and its result are kept
+	DefaultTTL = time.Hour
+	// DefaultMaxJobs is the number of jobs kept at the same time, running or completed
+	DefaultMaxJobs = 100
+	// DefaultMaxResultBytes is the size a job result is truncated to
+	DefaultMaxResultBytes = 1 << 20
+)
+
+// Info is the status of a job as returned to callers
+type Info struct {
+	ID              string     `json:"job_id"`
+	Kind            string     `json:"kind"`
+	Description     string     `json:"description"`
+	Status          Status     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	ResultBytes     int        `json:"result_bytes,omitempty"`
+	ResultTruncated bool       `json:"result_truncated,omitempty"`
+}
+
+// job is a job and its result
+type job struct {
+	info   Info
+	result string
+}
+
+// Manager runs jobs and keeps them until their TTL expires after completion
+type Manager struct {
+	mu             sync.Mutex
+	jobs           map[string]*job
+	ttl            time.Duration
+	maxJobs        int
+	maxResultBytes int
+	now            func() time.Time
+}
+
+// NewManager creates a job manager keeping at most maxJobs jobs, completed jobs for ttl, and
+// results of at most maxResultBytes
+func NewManager(ttl time.Duration, maxJobs, maxResultBytes int) *Manager {
+	return &Manager{
+		jobs:           make(map[string]*job),
+		ttl:            ttl,
+		maxJobs:        maxJobs,
+		maxResultBytes: maxResultBytes,
+		now:            time.Now,
+	}
+}
+
+// Start runs fn in the background and returns the running job. The job context is detached from
+// ctx, which usually ends with the MCP request, and is cancelled after timeout.
+func (m *Manager) Start(ctx context.Context, kind, description string, timeout time.Duration, fn func(ctx context.Context) (string, error)) (Info, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Info{}, err
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	if len(m.jobs) >= m.maxJobs && !m.evictOldestCompletedLocked() {
+		m.mu.Unlock()
+		return Info{}, fmt.Errorf("too many running jobs (%d), wait for a job to complete", len(m.jobs))
+	}
+	j := &job{info: Info{
+		ID:          id,
+		Kind:        kind,
+		Description: description,
+		Status:      StatusRunning,
+		CreatedAt:   m.now(),
+	}}
+	m.jobs[id] = j
+	info := m.infoLocked(j)
+	m.mu.Unlock()
+
+	go func() {
+		jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		result, err := fn(jobCtx)
+		m.complete(j, result, err)
+	}()
+
+	return info, nil
+}
+
+// Get returns the status of a job
+func (m *Manager) Get(id string) (Info, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Info{}, false
+	}
+	return m.infoLocked(j), true
+}
+
+// Result returns the status and the result of a job
+func (m *Manager) Result(id string) (Info, string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Info{}, "", false
+	}
+	return m.infoLocked(j), j.result, true
+}
+
+// complete records the result of a job
+func (m *Manager) complete(j *job, result string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	completedAt := m.now()
+	j.info.CompletedAt = &completedAt
+	if err != nil {
+		j.info.Status = StatusFailed
+		j.info.Error = err.Error()
+	} else {
+		j.info.Status = StatusSucceeded
+	}
+	if len(result) > m.maxResultBytes {
+		// Cut at the start of a rune so that a multi-byte character is not split
+		end := m.maxResultBytes
+		for end > 0 && !utf8.RuneStart(result[end]) {
+			end--
+		}
+		result = result[:end]
+		j.info.ResultTruncated = true
+	}
+	j.result = result
+	j.info.ResultBytes = len(result)
+}
+
+// infoLocked returns a copy of the job status with its duration and expiry
+func (m *Manager) infoLocked(j *job) Info {
+	info := j.info
+	end := m.now()
+	if info.CompletedAt != nil {
+		end = *info.CompletedAt
+		expiresAt := info.CompletedAt.Add(m.ttl)
+		info.ExpiresAt = &expiresAt
+	}
+	info.DurationSeconds = end.Sub(info.CreatedAt).Round(time.Millisecond).Seconds()
+	return info
+}
+
+// pruneLocked removes the completed jobs whose TTL expired
+func (m *Manager) pruneLocked() {
+	now := m.now()
+	for id, j := range m.jobs {
+		if j.info.CompletedAt != nil && now.Sub(*j.info.CompletedAt) >= m.ttl {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// evictOldestCompletedLocked removes the oldest completed job to make room for a new one.
+// Running jobs are never evicted.
+func (m *Manager) evictOldestCompletedLocked() bool {
+	oldestID := ""
+	var oldest time.Time
+	for id, j := range m.jobs {
+		if j.info.CompletedAt == nil {
+			continue
+		}
+		if oldestID == "" || j.info.CompletedAt.Before(oldest) {
+			oldestID, oldest = id, *j.info.CompletedAt
+		}
+	}
+	if oldestID == "" {
+		return false
+	}
+	delete(m.jobs, oldestID)
+	return true
+}
+
+// newJobID returns a random job ID
+func newJobID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// waitForCompletion polls the job until it is no longer running
+func waitForCompletion(t *testing.T, m *Manager, id string) Info {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		info, ok := m.Get(id)
+		if !ok {
+			t.Fatalf("Job %s not found", id)
+		}
+		if info.Status != StatusRunning {
+			return info
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not complete", id)
+	return Info{}
+}
+
+func TestManagerStartAndResult(t *testing.T) {
+	m := NewManager(time.Hour, 10, 1024)
+	release := make(chan struct{})
+
+	info, err := m.Start(context.Background(), "node_logs", "kubelet logs", time.Minute, func(ctx context.Context) (string, error) {
+		<-release
+		return "log output", nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.ID == "" || info.Status != StatusRunning || info.ExpiresAt != nil {
+		t.Errorf("Unexpected running job: %+v", info)
+	}
+
+	close(release)
+	info = waitForCompletion(t, m, info.ID)
+	if info.Status != StatusSucceeded || info.CompletedAt == nil || info.ExpiresAt == nil {
+		t.Errorf("Unexpected completed job: %+v", info)
+	}
+
+	_, result, ok := m.Result(info.ID)
+	if !ok || result != "log output" {
+		t.Errorf("Expected the job result, got %q, %v", result, ok)
+	}
+}
+
+func TestManagerFailedJob(t *testing.T) {
+	m := NewManager(time.Hour, 10, 1024)
+	info, err := m.Start(context.Background(), "node_logs", "", time.Minute, func(ctx context.Context) (string, error) {
+		return "", errors.New("run command conflict")
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info = waitForCompletion(t, m, info.ID)
+	if info.Status != StatusFailed || info.Error != "run command conflict" {
+		t.Errorf("Unexpected failed job: %+v", info)
+	}
+}
+
+func TestManagerDetachesRequestContext(t *testing.T) {
+	m := NewManager(time.Hour, 10, 1024)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	info, err := m.Start(ctx, "node_logs", "", time.Minute, func(jobCtx context.Context) (string, error) {
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		return "done", jobCtx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if info = waitForCompletion(t, m, info.ID); info.Status != StatusSucceeded {
+		t.Errorf("Expected the job to outlive the request context, got %+v", info)
+	}
+}
+
+func TestManagerTimeout(t *testing.T) {
+	m := NewManager(time.Hour, 10, 1024)
+	info, err := m.Start(context.Background(), "node_logs", "", 10*time.Millisecond, func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if info = waitForCompletion(t, m, info.ID); info.Status != StatusFailed || !strings.Contains(info.Error, "deadline exceeded") {
+		t.Errorf("Expected the job to time out, got %+v", info)
+	}
+}
+
+func TestManagerTruncatesResult(t *testing.T) {
+	m := NewManager(time.Hour, 10, 8)
+	info, _ := m.Start(context.Background(), "node_logs", "", time.Minute, func(ctx context.Context) (string, error) {
+		return "0123456789", nil
+	})
+
+	waitForCompletion(t, m, info.ID)
+	info, result, _ := m.Result(info.ID)
+	if result != "01234567" || !info.ResultTruncated || info.ResultBytes != 8 {
+		t.Errorf("Expected a truncated result, got %q, %+v", result, info)
+	}
+
+	// The limit falls inside the 3 byte "€", which is dropped instead of being split
+	info, _ = m.Start(context.Background(), "node_logs", "", time.Minute, func(ctx context.Context) (string, error) {
+		return "012345€9", nil
+	})
+
+	waitForCompletion(t, m, info.ID)
+	info, result, _ = m.Result(info.ID)
+	if result != "012345" || !info.ResultTruncated || info.ResultBytes != 6 || !utf8.ValidString(result) {
+		t.Errorf("Expected the result to be truncated at a rune boundary, got %q, %+v", result, info)
+	}
+}
+
+func TestManagerTTLAndCapacity(t *testing.T) {
+	now := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	m := NewManager(time.Hour, 2, 1024)
+	m.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	done, _ := m.Start(context.Background(), "node_logs", "", time.Minute, func(ctx context.Context) (string, error) {
+		return "", nil
+	})
+	waitForCompletion(t, m, done.ID)
+
+	release := make(chan struct{})
+	defer close(release)
+	blocking := func(ctx context.Context) (string, error) {
+		<-release
+		return "", nil
+	}
+	running, err := m.Start(context.Background(), "node_logs", "", time.Minute, blocking)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The manager is full, the completed job makes room for the new one
+	if _, err := m.Start(context.Background(), "node_logs", "", time.Minute, blocking); err != nil {
+		t.Fatalf("Expected the completed job to be evicted, got %v", err)
+	}
+	if _, ok := m.Get(done.ID); ok {
+		t.Error("Expected the completed job to be evicted")
+	}
+
+	// Running jobs are never evicted
+	if _, err := m.Start(context.Background(), "node_logs", "", time.Minute, blocking); err == nil || !strings.Contains(err.Error(), "too many running jobs") {
+		t.Errorf("Expected a capacity error, got %v", err)
+	}
+
+	// Running jobs do not expire
+	advance(2 * time.Hour)
+	if _, ok := m.Get(running.ID); !ok {
+		t.Error("Expected the running job to be kept past the TTL")
+	}
+}
+
+func TestManagerExpiresCompletedJobs(t *testing.T) {
+	now := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	m := NewManager(time.Hour, 10, 1024)
+	m.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	info, _ := m.Start(context.Background(), "node_logs", "", time.Minute, func(ctx context.Context) (string, error) {
+		return "", nil
+	})
+	waitForCompletion(t, m, info.ID)
+
+	mu.Lock()
+	now = now.Add(time.Hour)
+	mu.Unlock()
+	if _, ok := m.Get(info.ID); ok {
+		t.Error("Expected the completed job to expire after the TTL")
+	}
+}
@@ -0,0 +1,130 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute/nodediag"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/logger"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// NodeDiagnosticsResult is the result of the collect_aks_node_diagnostics tool
+type NodeDiagnosticsResult struct {
+	Cluster           string             `json:"cluster"`
+	NodeResourceGroup string             `json:"node_resource_group"`
+	VMSSName          string             `json:"vmss_name"`
+	InstanceID        string             `json:"instance_id"`
+	Checks            []string           `json:"checks"`
+	Summary           string             `json:"summary"`
+	Findings          common.Findings    `json:"findings"`
+	Sections          []nodediag.Section `json:"sections"`
+	Stderr            string             `json:"stderr,omitempty"`
+}
+
+// CollectAKSNodeDiagnosticsHandler returns a handler for the collect_aks_node_diagnostics tool
+func CollectAKSNodeDiagnosticsHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]any, _ *config.ConfigData) (string, error) {
+		// Extract AKS resource parameters from aks_resource_id
+		subID, rg, clusterName, err := common.ExtractAKSParametersFromResourceID(params)
+		if err != nil {
+			return "", err
+		}
+
+		// Validate the check groups before any Azure call
+		checksParam, _ := params["checks"].(string)
+		checks, err := nodediag.ParseChecks(checksParam)
+		if err != nil {
+			return "", err
+		}
+		script, err := nodediag.BuildScript(checks)
+		if err != nil {
+			return "", err
+		}
+
+		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get cluster details: %w", err)
+		}
+		if cluster.Properties == nil || cluster.Properties.NodeResourceGroup == nil {
+			return "", fmt.Errorf("cluster node resource group not found")
+		}
+		nodeResourceGroup := *cluster.Properties.NodeResourceGroup
+
+		// Resolve the VMSS instance from node_name or from vmss_name and instance_id
+		vmssName, instanceID, err := resolveNodeTargetParams(ctx, client, cluster, params)
+		if err != nil {
+			return "", err
+		}
+
+		osType, err := validateVMSSSupport(ctx, client, subID, nodeResourceGroup, vmssName)
+		if err != nil {
+			return "", err
+		}
+		if osType != OSTypeLinux {
+			return "", fmt.Errorf("node diagnostics are only available on Linux nodes, VMSS %s runs %s; use collect_aks_node_logs for Windows nodes", vmssName, osType)
+		}
+
+		logger.Debugf("CollectAKSNodeDiagnostics: cluster=%s/%s, nodeRG=%s, vmss=%s, instance=%s, checks=%v",
+			rg, clusterName, nodeResourceGroup, vmssName, instanceID, checks)
+
+		collect := func(ctx context.Context) (string, error) {
+			executor := NewVMRunCommandExecutor(client)
+			output, err := executor.ExecuteOnVMSSInstance(ctx, subID, nodeResourceGroup, vmssName, instanceID, script)
+			if err != nil {
+				return "", fmt.Errorf("failed to collect diagnostics from VMSS %s/%s instance %s: %w",
+					nodeResourceGroup, vmssName, instanceID, err)
+			}
+
+			result := buildNodeDiagnosticsResult(clusterName, nodeResourceGroup, vmssName, instanceID, checks, output)
+			resultJSON, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return "", fmt.Errorf("failed to marshal node diagnostics: %w", err)
+			}
+			return string(resultJSON), nil
+		}
+
+		if isAsyncRequest(params) {
+			description := fmt.Sprintf("%s checks on VMSS %s instance %s", strings.Join(checks, ","), vmssName, instanceID)
+			return startRunCommandJob(ctx, JobKindNodeDiagnostics, description, collect)
+		}
+		return collect(ctx)
+	})
+}
+
+// buildNodeDiagnosticsResult parses the run command output of the diagnostics script
+func buildNodeDiagnosticsResult(clusterName, nodeResourceGroup, vmssName, instanceID string, checks []string, output string) *NodeDiagnosticsResult {
+	lines, stderr := splitRunCommandOutput(output)
+	sections := nodediag.ParseOutput(strings.Join(lines, "\n"))
+	findings := append(nodediag.Analyze(sections), nodediag.MissingSections(checks, sections)...)
+	if sections == nil {
+		sections = []nodediag.Section{}
+	}
+
+	errors, warnings := 0, 0
+	for _, finding := range findings {
+		switch finding.Severity {
+		case common.SeverityError:
+			errors++
+		case common.SeverityWarning:
+			warnings++
+		}
+	}
+
+	return &NodeDiagnosticsResult{
+		Cluster:           clusterName,
+		NodeResourceGroup: nodeResourceGroup,
+		VMSSName:          vmssName,
+		InstanceID:        instanceID,
+		Checks:            checks,
+		Summary:           fmt.Sprintf("%d checks, %d errors, %d warnings", len(checks), errors, warnings),
+		Findings:          findings,
+		Sections:          sections,
+		Stderr:            stderr,
+	}
+}
@@ -0,0 +1,48 @@
+package compute
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBuildNodeDiagnosticsResult(t *testing.T) {
+	output := "Enable succeeded: \n[stdout]\n### disk\ndisk_used_pct:/=95\n### services\nservice_active:kubelet=active\nservice_active:containerd=failed\n\n[stderr]\niptables-save: command not found\n"
+	result := buildNodeDiagnosticsResult("test-cluster", "MC_rg", "aks-nodepool1-12345678-vmss", "0",
+		[]string{"disk", "dns", "services"}, output)
+
+	if len(result.Sections) != 2 || result.Sections[0].Check != "disk" || result.Sections[1].Check != "services" {
+		t.Fatalf("Unexpected sections: %+v", result.Sections)
+	}
+	if result.Stderr != "iptables-save: command not found" {
+		t.Errorf("Unexpected stderr: %q", result.Stderr)
+	}
+
+	codes := result.Findings.Codes()
+	joined := strings.Join(codes, ",")
+	for _, code := range []string{"disk_usage_high", "service_not_active", "check_output_missing"} {
+		if !strings.Contains(joined, code) {
+			t.Errorf("Expected finding %s, got %v", code, codes)
+		}
+	}
+	if result.Summary != "3 checks, 2 errors, 1 warnings" {
+		t.Errorf("Unexpected summary: %s", result.Summary)
+	}
+}
+
+func TestCollectAKSNodeDiagnosticsValidation(t *testing.T) {
+	handler := CollectAKSNodeDiagnosticsHandler(nil, nil)
+
+	_, err := handler.Handle(context.Background(), map[string]any{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "aks_resource_id") {
+		t.Errorf("Expected aks_resource_id error, got %v", err)
+	}
+
+	_, err = handler.Handle(context.Background(), map[string]any{
+		"aks_resource_id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks",
+		"checks":          "disk,cpu",
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid check") {
+		t.Errorf("Expected invalid check error, got %v", err)
+	}
+}
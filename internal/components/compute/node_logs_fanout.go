@@ -0,0 +1,446 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/logger"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Concurrent run commands when collecting logs from many nodes
+	DefaultFanOutConcurrency = 5
+	MaxFanOutConcurrency     = 20
+
+	// Time allowed for the run command on each node
+	DefaultNodeTimeoutSeconds = 300
+	MaxNodeTimeoutSeconds     = 1800
+
+	// Maximum number of merged log lines returned across all nodes
+	MaxFanOutLines = 5000
+)
+
+var (
+	// journalctl and syslog short format, e.g. "Oct 18 10:00:00"
+	shortTimestampRegex = regexp.MustCompile(`^([A-Z][a-z]{2} [ 0-9]?[0-9] [0-9]{2}:[0-9]{2}:[0-9]{2})`)
+	// dmesg -T format, e.g. "[Sat Oct 18 10:00:00 2025]"
+	dmesgTimestampRegex = regexp.MustCompile(`^\[([A-Z][a-z]{2} [A-Z][a-z]{2} [ 0-9]?[0-9] [0-9]{2}:[0-9]{2}:[0-9]{2} [0-9]{4})\]`)
+	// ISO 8601 format, e.g. "2025-10-18T10:00:00.123456Z" or "2025-10-18 10:00:00+0000"
+	isoTimestampRegex = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2}[T ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:?[0-9]{2})?)`)
+)
+
+// fanOutOptions controls log collection across many nodes
+type fanOutOptions struct {
+	concurrency int
+	timeout     time.Duration
+	onlyMatches bool
+}
+
+// nodeLogResult is the log output of one node
+type nodeLogResult struct {
+	node   *NodeInstance
+	lines  []string
+	stderr string
+	err    error
+}
+
+// taggedLogLine is a log line tagged with its node, ordered by its timestamp
+type taggedLogLine struct {
+	time time.Time
+	node string
+	text string
+}
+
+// collectFanOutNodeLogs runs the log command on every node of a node pool or every node matching
+// a label selector and merges the output into a single time ordered stream
+func collectFanOutNodeLogs(
+	ctx context.Context,
+	client *azureclient.AzureClient,
+	cluster *armcontainerservice.ManagedCluster,
+	clusterName string,
+	params map[string]any,
+	request nodeLogRequest,
+) (string, error) {
+	nodePool, _ := params["node_pool"].(string)
+	labelSelector, _ := params["label_selector"].(string)
+
+	options, err := parseFanOutOptions(params)
+	if err != nil {
+		return "", err
+	}
+
+	var targets []*NodeInstance
+	var failed []nodeLogResult
+	scope := fanOutScope(params)
+	if nodePool != "" {
+		targets, err = nodePoolTargets(ctx, client, cluster, nodePool)
+	} else {
+		targets, failed, err = labelSelectorTargets(ctx, cluster, labelSelector)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(targets) == 0 && len(failed) == 0 {
+		return "", fmt.Errorf("no nodes found for %s", scope)
+	}
+
+	targets, unsupported := filterSupportedTargets(ctx, client, targets)
+	failed = append(failed, unsupported...)
+
+	logger.Debugf("CollectAKSNodeLogs: fan-out over %s, nodes=%d, concurrency=%d, timeout=%s, type=%s",
+		scope, len(targets), options.concurrency, options.timeout, request.logType)
+
+	// Label selectors may span Linux and Windows scale sets, so the command is built per node
+	executor := NewVMRunCommandExecutor(client)
+	results := runOnNodes(ctx, targets, options, func(ctx context.Context, node *NodeInstance) (string, error) {
+		commandID, command, err := request.command(node.OSType)
+		if err != nil {
+			return "", err
+		}
+		return executor.ExecuteScriptOnVMSSInstance(ctx, node.SubscriptionID, node.ResourceGroup, node.VMSSName, node.InstanceID, commandID, command)
+	})
+	results = append(results, failed...)
+
+	return formatFanOutLogOutput(clusterName, scope, request.logType, results, options.onlyMatches, time.Now()), nil
+}
+
+// isFanOutRequest reports whether the parameters select many nodes with node_pool or label_selector
+func isFanOutRequest(params map[string]any) (bool, error) {
+	nodePool, _ := params["node_pool"].(string)
+	labelSelector, _ := params["label_selector"].(string)
+	if nodePool == "" && labelSelector == "" {
+		return false, nil
+	}
+	if nodePool != "" && labelSelector != "" {
+		return false, fmt.Errorf("specify either node_pool or label_selector, not both")
+	}
+	for _, key := range []string{"node_name", "vmss_name", "instance_id"} {
+		if value, _ := params[key].(string); value != "" {
+			return false, fmt.Errorf("%s cannot be combined with node_pool or label_selector", key)
+		}
+	}
+	return true, nil
+}
+
+// fanOutScope describes the nodes selected by node_pool or label_selector
+func fanOutScope(params map[string]any) string {
+	if nodePool, _ := params["node_pool"].(string); nodePool != "" {
+		return fmt.Sprintf("node pool %s", nodePool)
+	}
+	labelSelector, _ := params["label_selector"].(string)
+	return fmt.Sprintf("nodes matching %s", labelSelector)
+}
+
+// parseFanOutOptions reads the concurrency, per node timeout and summary parameters
+func parseFanOutOptions(params map[string]any) (fanOutOptions, error) {
+	options := fanOutOptions{
+		concurrency: DefaultFanOutConcurrency,
+		timeout:     DefaultNodeTimeoutSeconds * time.Second,
+	}
+
+	if c, ok := params["max_concurrency"].(float64); ok {
+		if c < 1 || c > MaxFanOutConcurrency {
+			return options, fmt.Errorf("invalid max_concurrency: %v (must be between 1 and %d)", c, MaxFanOutConcurrency)
+		}
+		options.concurrency = int(c)
+	}
+
+	if s, ok := params["node_timeout_seconds"].(float64); ok {
+		if s < 1 || s > MaxNodeTimeoutSeconds {
+			return options, fmt.Errorf("invalid node_timeout_seconds: %v (must be between 1 and %d)", s, MaxNodeTimeoutSeconds)
+		}
+		options.timeout = time.Duration(s) * time.Second
+	}
+
+	if o, ok := params["only_matches"].(bool); ok {
+		options.onlyMatches = o
+	}
+
+	return options, nil
+}
+
+// nodePoolTargets returns the VMSS instances of a node pool
+func nodePoolTargets(
+	ctx context.Context,
+	client *azureclient.AzureClient,
+	cluster *armcontainerservice.ManagedCluster,
+	nodePool string,
+) ([]*NodeInstance, error) {
+	instances, err := GetVMSSInstancesFromNodePool(ctx, cluster, nodePool, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances of node pool %s: %w", nodePool, err)
+	}
+
+	var targets []*NodeInstance
+	for _, instance := range instances {
+		vm, ok := instance.(*armcompute.VirtualMachineScaleSetVM)
+		if !ok || vm == nil || vm.ID == nil || vm.InstanceID == nil {
+			continue
+		}
+		target, err := parseNodeProviderID(azureProviderIDPrefix + *vm.ID)
+		if err != nil {
+			return nil, err
+		}
+		target.NodeName = vmssInstanceNodeName(vm, target.VMSSName)
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// vmssInstanceNodeName returns the computer name of an instance, which is its node name, or
+// derives it from the scale set name and the instance ID in base 36
+func vmssInstanceNodeName(vm *armcompute.VirtualMachineScaleSetVM, vmssName string) string {
+	if vm.Properties != nil && vm.Properties.OSProfile != nil && vm.Properties.OSProfile.ComputerName != nil {
+		return *vm.Properties.OSProfile.ComputerName
+	}
+	instanceID, err := strconv.ParseInt(*vm.InstanceID, 10, 64)
+	if err != nil {
+		return vmssName + "_" + *vm.InstanceID
+	}
+	return fmt.Sprintf("%s%06s", vmssName, strconv.FormatInt(instanceID, 36))
+}
+
+// labelSelectorTargets returns the VMSS instances of the nodes matching a label selector
+func labelSelectorTargets(
+	ctx context.Context,
+	cluster *armcontainerservice.ManagedCluster,
+	labelSelector string,
+) ([]*NodeInstance, []nodeLogResult, error) {
+	kubeClient, err := common.KubernetesClientForCluster(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes matching %s: %w", labelSelector, err)
+	}
+
+	nodeResourceGroup := *cluster.Properties.NodeResourceGroup
+	var targets []*NodeInstance
+	var failed []nodeLogResult
+	for _, node := range nodes.Items {
+		target, err := parseNodeProviderID(node.Spec.ProviderID)
+		if err == nil && !strings.EqualFold(target.ResourceGroup, nodeResourceGroup) {
+			err = fmt.Errorf("node is not in the cluster node resource group %s", nodeResourceGroup)
+		}
+		if err != nil {
+			failed = append(failed, nodeLogResult{node: &NodeInstance{NodeName: node.Name}, err: err})
+			continue
+		}
+		target.NodeName = node.Name
+		targets = append(targets, target)
+	}
+	return targets, failed, nil
+}
+
+// filterSupportedTargets validates each scale set once and fails the nodes of unsupported scale sets
+func filterSupportedTargets(ctx context.Context, client *azureclient.AzureClient, targets []*NodeInstance) ([]*NodeInstance, []nodeLogResult) {
+	type validation struct {
+		osType string
+		err    error
+	}
+	validated := make(map[string]validation)
+	var supported []*NodeInstance
+	var failed []nodeLogResult
+	for _, target := range targets {
+		key := strings.ToLower(target.VMSSID())
+		result, ok := validated[key]
+		if !ok {
+			result.osType, result.err = validateVMSSSupport(ctx, client, target.SubscriptionID, target.ResourceGroup, target.VMSSName)
+			validated[key] = result
+		}
+		if result.err != nil {
+			failed = append(failed, nodeLogResult{node: target, err: result.err})
+			continue
+		}
+		target.OSType = result.osType
+		supported = append(supported, target)
+	}
+	return supported, failed
+}
+
+// runOnNodes runs a command on every node with at most options.concurrency commands in flight,
+// each bounded by options.timeout. Results are returned in the order of the nodes.
+func runOnNodes(
+	ctx context.Context,
+	nodes []*NodeInstance,
+	options fanOutOptions,
+	run func(ctx context.Context, node *NodeInstance) (string, error),
+) []nodeLogResult {
+	results := make([]nodeLogResult, len(nodes))
+	semaphore := make(chan struct{}, options.concurrency)
+	var wg sync.WaitGroup
+
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].node = node
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i].err = ctx.Err()
+				return
+			}
+
+			nodeCtx, cancel := context.WithTimeout(ctx, options.timeout)
+			defer cancel()
+			output, err := run(nodeCtx, node)
+			if err != nil {
+				if nodeCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+					err = fmt.Errorf("timed out after %s: %w", options.timeout, err)
+				}
+				results[i].err = err
+				return
+			}
+			results[i].lines, results[i].stderr = splitRunCommandOutput(output)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// splitRunCommandOutput returns the non-empty stdout lines and the stderr of a run command,
+// whose output is formatted as "Enable succeeded: \n[stdout]\n...\n[stderr]\n..."
+func splitRunCommandOutput(output string) ([]string, string) {
+	stdout, stderr := output, ""
+	if _, after, found := strings.Cut(output, "[stdout]\n"); found {
+		stdout = after
+	}
+	if before, after, found := strings.Cut(stdout, "[stderr]\n"); found {
+		stdout, stderr = before, after
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, "\r"))
+		}
+	}
+	return lines, strings.TrimSpace(stderr)
+}
+
+// parseLogTimestamp parses the timestamp at the start of a journalctl, syslog, dmesg -T or ISO 8601
+// log line. Timestamps without a year get the year of now, or the previous year when that would
+// put them in the future.
+func parseLogTimestamp(line string, now time.Time) (time.Time, bool) {
+	if match := isoTimestampRegex.FindString(line); match != "" {
+		for _, layout := range []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999"} {
+			if parsed, err := time.Parse(layout, strings.Replace(match, " ", "T", 1)); err == nil {
+				return parsed, true
+			}
+		}
+	}
+
+	if match := dmesgTimestampRegex.FindStringSubmatch(line); match != nil {
+		if parsed, err := time.Parse("Mon Jan _2 15:04:05 2006", match[1]); err == nil {
+			return parsed, true
+		}
+	}
+
+	if match := shortTimestampRegex.FindStringSubmatch(line); match != nil {
+		if parsed, err := time.Parse("Jan _2 15:04:05", match[1]); err == nil {
+			parsed = parsed.AddDate(now.Year(), 0, 0)
+			// A timestamp ahead of now belongs to the previous year
+			if parsed.After(now.Add(24 * time.Hour)) {
+				parsed = parsed.AddDate(-1, 0, 0)
+			}
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// mergeNodeLogs merges the lines of all nodes in timestamp order. Lines without a timestamp
+// (e.g. continuation lines) keep the timestamp of the line before them.
+func mergeNodeLogs(results []nodeLogResult, now time.Time) []taggedLogLine {
+	var merged []taggedLogLine
+	for _, result := range results {
+		var last time.Time
+		for _, line := range result.lines {
+			if parsed, ok := parseLogTimestamp(line, now); ok {
+				last = parsed
+			}
+			merged = append(merged, taggedLogLine{time: last, node: result.node.NodeName, text: line})
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].time.Before(merged[j].time)
+	})
+	return merged
+}
+
+// formatFanOutLogOutput formats the per node status and the merged log stream
+func formatFanOutLogOutput(clusterName, scope, logType string, results []nodeLogResult, onlyMatches bool, now time.Time) string {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].node.NodeName < results[j].node.NodeName
+	})
+
+	var result strings.Builder
+	result.WriteString("=== AKS Node Logs ===\n")
+	fmt.Fprintf(&result, "Cluster: %s\n", clusterName)
+	fmt.Fprintf(&result, "Nodes: %s (%d)\n", scope, len(results))
+	fmt.Fprintf(&result, "Log Type: %s\n", logType)
+	result.WriteString("=====================\n\n")
+
+	failed, withoutMatches := 0, 0
+	result.WriteString("--- Node Summary ---\n")
+	for _, node := range results {
+		switch {
+		case node.err != nil:
+			failed++
+			fmt.Fprintf(&result, "%s: ERROR %v\n", node.node.NodeName, node.err)
+		case len(node.lines) == 0:
+			withoutMatches++
+			if !onlyMatches {
+				fmt.Fprintf(&result, "%s: 0 lines\n", node.node.NodeName)
+			}
+		default:
+			fmt.Fprintf(&result, "%s: %d lines", node.node.NodeName, len(node.lines))
+			if node.stderr != "" {
+				fmt.Fprintf(&result, " (stderr: %s)", firstLine(node.stderr))
+			}
+			result.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&result, "Nodes with lines: %d, without lines: %d, failed: %d\n", len(results)-failed-withoutMatches, withoutMatches, failed)
+
+	// The summary alone answers which nodes are affected
+	if onlyMatches {
+		return result.String()
+	}
+
+	merged := mergeNodeLogs(results, now)
+	result.WriteString("\n--- Merged Logs ---\n")
+	if len(merged) > MaxFanOutLines {
+		fmt.Fprintf(&result, "(showing the most recent %d of %d lines)\n", MaxFanOutLines, len(merged))
+		merged = merged[len(merged)-MaxFanOutLines:]
+	}
+	for _, line := range merged {
+		fmt.Fprintf(&result, "[%s] %s\n", line.node, line.text)
+	}
+
+	return result.String()
+}
+
+// firstLine returns the first line of a multi-line string
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
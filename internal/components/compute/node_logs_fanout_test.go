@@ -0,0 +1,222 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+)
+
+func testNodes(names ...string) []*NodeInstance {
+	var nodes []*NodeInstance
+	for i, name := range names {
+		nodes = append(nodes, &NodeInstance{NodeName: name, VMSSName: "aks-nodepool1-12345678-vmss", InstanceID: string(rune('0' + i))})
+	}
+	return nodes
+}
+
+func TestIsFanOutRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]any
+		expected bool
+		wantErr  bool
+	}{
+		{"single node", map[string]any{"node_name": "aks-nodepool1-12345678-vmss000000"}, false, false},
+		{"node pool", map[string]any{"node_pool": "nodepool1"}, true, false},
+		{"label selector", map[string]any{"label_selector": "agentpool=nodepool1"}, true, false},
+		{"both", map[string]any{"node_pool": "nodepool1", "label_selector": "agentpool=nodepool1"}, false, true},
+		{"combined with node", map[string]any{"node_pool": "nodepool1", "vmss_name": "aks-nodepool1-12345678-vmss"}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fanOut, err := isFanOutRequest(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("isFanOutRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fanOut != tt.expected {
+				t.Errorf("isFanOutRequest() = %v, want %v", fanOut, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFanOutOptions(t *testing.T) {
+	options, err := parseFanOutOptions(map[string]any{})
+	if err != nil || options.concurrency != DefaultFanOutConcurrency || options.timeout != DefaultNodeTimeoutSeconds*time.Second || options.onlyMatches {
+		t.Errorf("Unexpected default options: %+v, %v", options, err)
+	}
+
+	options, err = parseFanOutOptions(map[string]any{"max_concurrency": float64(10), "node_timeout_seconds": float64(60), "only_matches": true})
+	if err != nil || options.concurrency != 10 || options.timeout != time.Minute || !options.onlyMatches {
+		t.Errorf("Unexpected options: %+v, %v", options, err)
+	}
+
+	for _, params := range []map[string]any{
+		{"max_concurrency": float64(0)},
+		{"max_concurrency": float64(MaxFanOutConcurrency + 1)},
+		{"node_timeout_seconds": float64(MaxNodeTimeoutSeconds + 1)},
+	} {
+		if _, err := parseFanOutOptions(params); err == nil {
+			t.Errorf("Expected error for %v", params)
+		}
+	}
+}
+
+func TestRunOnNodesConcurrencyAndTimeout(t *testing.T) {
+	nodes := testNodes("node-a", "node-b", "node-c", "node-d", "node-e")
+	var inFlight, peak atomic.Int32
+
+	results := runOnNodes(context.Background(), nodes, fanOutOptions{concurrency: 2, timeout: 50 * time.Millisecond},
+		func(ctx context.Context, node *NodeInstance) (string, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+
+			switch node.NodeName {
+			case "node-b":
+				return "", errors.New("run command conflict")
+			case "node-c":
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			time.Sleep(5 * time.Millisecond)
+			return "Enable succeeded: \n[stdout]\nOct 18 10:00:00 " + node.NodeName + " kubelet[1]: ok\n\n[stderr]\n", nil
+		})
+
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent commands, got %d", peak.Load())
+	}
+	if len(results) != len(nodes) {
+		t.Fatalf("Expected %d results, got %d", len(nodes), len(results))
+	}
+	for i, result := range results {
+		if result.node != nodes[i] {
+			t.Errorf("Expected results in node order, got %s at %d", result.node.NodeName, i)
+		}
+	}
+	if results[0].err != nil || len(results[0].lines) != 1 {
+		t.Errorf("Unexpected result for node-a: %+v", results[0])
+	}
+	if results[1].err == nil || !strings.Contains(results[1].err.Error(), "conflict") {
+		t.Errorf("Expected the run command error for node-b, got %v", results[1].err)
+	}
+	if results[2].err == nil || !strings.Contains(results[2].err.Error(), "timed out") {
+		t.Errorf("Expected a timeout for node-c, got %v", results[2].err)
+	}
+}
+
+func TestSplitRunCommandOutput(t *testing.T) {
+	lines, stderr := splitRunCommandOutput("Enable succeeded: \n[stdout]\nline 1\n\nline 2\n\n[stderr]\ngrep: warning\n")
+	if len(lines) != 2 || lines[0] != "line 1" || lines[1] != "line 2" {
+		t.Errorf("Unexpected stdout lines: %q", lines)
+	}
+	if stderr != "grep: warning" {
+		t.Errorf("Unexpected stderr: %q", stderr)
+	}
+
+	// Output without run command markers is kept as is
+	if lines, _ := splitRunCommandOutput("plain\noutput"); len(lines) != 2 {
+		t.Errorf("Expected 2 lines, got %q", lines)
+	}
+}
+
+func TestParseLogTimestamp(t *testing.T) {
+	now := time.Date(2026, time.January, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		line     string
+		expected time.Time
+		ok       bool
+	}{
+		{"journalctl", "Jan  2 10:00:00 node kubelet[1]: E0102 message", time.Date(2026, time.January, 2, 10, 0, 0, 0, time.UTC), true},
+		{"previous year", "Dec 31 23:59:59 node kubelet[1]: message", time.Date(2025, time.December, 31, 23, 59, 59, 0, time.UTC), true},
+		{"dmesg", "[Fri Jan  2 09:00:00 2026] eth0: link up", time.Date(2026, time.January, 2, 9, 0, 0, 0, time.UTC), true},
+		{"iso", "2026-01-02T08:00:00.5Z message", time.Date(2026, time.January, 2, 8, 0, 0, 500000000, time.UTC), true},
+		{"continuation", "    at stack frame", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, ok := parseLogTimestamp(tt.line, now)
+			if ok != tt.ok || !parsed.Equal(tt.expected) {
+				t.Errorf("parseLogTimestamp(%q) = %v, %v, want %v, %v", tt.line, parsed, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMergeNodeLogs(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	nodes := testNodes("node-a", "node-b")
+	results := []nodeLogResult{
+		{node: nodes[0], lines: []string{"Oct 18 10:00:02 a kubelet[1]: second", "  continuation of second", "Oct 18 10:00:04 a kubelet[1]: fourth"}},
+		{node: nodes[1], lines: []string{"Oct 18 10:00:01 b kubelet[1]: first", "Oct 18 10:00:03 b kubelet[1]: third"}},
+	}
+
+	merged := mergeNodeLogs(results, now)
+	expected := []string{"first", "second", "continuation of second", "third", "fourth"}
+	if len(merged) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(merged))
+	}
+	for i, line := range merged {
+		if !strings.HasSuffix(line.text, expected[i]) {
+			t.Errorf("Line %d: expected %q, got [%s] %q", i, expected[i], line.node, line.text)
+		}
+	}
+	if merged[0].node != "node-b" || merged[1].node != "node-a" {
+		t.Errorf("Expected lines tagged with their node, got %s and %s", merged[0].node, merged[1].node)
+	}
+}
+
+func TestFormatFanOutLogOutput(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	nodes := testNodes("node-a", "node-b", "node-c")
+	results := []nodeLogResult{
+		{node: nodes[2], err: errors.New("instance is deallocated")},
+		{node: nodes[0], lines: []string{"Oct 18 10:00:00 a kubelet[1]: PLEG is not healthy"}},
+		{node: nodes[1]},
+	}
+
+	output := formatFanOutLogOutput("test-cluster", "node pool nodepool1", LogTypeKubelet, results, false, now)
+	for _, expected := range []string{
+		"Nodes: node pool nodepool1 (3)",
+		"node-a: 1 lines",
+		"node-b: 0 lines",
+		"node-c: ERROR instance is deallocated",
+		"Nodes with lines: 1, without lines: 1, failed: 1",
+		"[node-a] Oct 18 10:00:00 a kubelet[1]: PLEG is not healthy",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	summary := formatFanOutLogOutput("test-cluster", "node pool nodepool1", LogTypeKubelet, results, true, now)
+	if strings.Contains(summary, "node-b") || strings.Contains(summary, "Merged Logs") || !strings.Contains(summary, "node-a: 1 lines") {
+		t.Errorf("Expected only the nodes with lines and errors in the summary, got:\n%s", summary)
+	}
+}
+
+func TestVMSSInstanceNodeName(t *testing.T) {
+	vm := &armcompute.VirtualMachineScaleSetVM{InstanceID: to.Ptr("10")}
+	if name := vmssInstanceNodeName(vm, "aks-nodepool1-12345678-vmss"); name != "aks-nodepool1-12345678-vmss00000a" {
+		t.Errorf("Expected the base 36 node name, got %s", name)
+	}
+
+	vm.Properties = &armcompute.VirtualMachineScaleSetVMProperties{OSProfile: &armcompute.OSProfile{ComputerName: to.Ptr("custom-name")}}
+	if name := vmssInstanceNodeName(vm, "aks-nodepool1-12345678-vmss"); name != "custom-name" {
+		t.Errorf("Expected the computer name, got %s", name)
+	}
+}
@@ -51,22 +51,6 @@ func CollectAKSNodeLogsHandler(client *azureclient.AzureClient, cfg *config.Conf
 
 		nodeResourceGroup := *cluster.Properties.NodeResourceGroup
 
-		// Extract VMSS parameters
-		vmssName, ok := params["vmss_name"].(string)
-		if !ok || vmssName == "" {
-			return "", fmt.Errorf("vmss_name is required")
-		}
-
-		instanceID, ok := params["instance_id"].(string)
-		if !ok || instanceID == "" {
-			return "", fmt.Errorf("instance_id is required")
-		}
-
-		// Validate that the VMSS is Linux-based
-		if err := validateVMSSSupport(ctx, client, subID, nodeResourceGroup, vmssName); err != nil {
-			return "", err
-		}
-
 		// Extract log collection parameters
 		logType, ok := params["log_type"].(string)
 		if !ok || logType == "" {
@@ -75,7 +59,15 @@ func CollectAKSNodeLogsHandler(client *azureclient.AzureClient, cfg *config.Conf
 
 		// Validate log type
 		if !isValidLogType(logType) {
-			return "", fmt.Errorf("invalid log_type: %s (must be one of: kubelet, containerd, kernel, syslog)", logType)
+			return "", fmt.Errorf("invalid log_type: %s (must be one of: kubelet, containerd, kernel, syslog, kube-proxy, cse, cloud-init, waagent, extensions, node-problem-detector, container on Linux nodes; kubelet, containerd, kube-proxy, cse, eventlog-system, eventlog-application, eventlog-hyperv-compute on Windows nodes)", logType)
+		}
+
+		// The container log type reads the log files of one container, given by namespace, pod and container
+		var container containerLogTarget
+		if logType == LogTypeContainer {
+			if container, err = parseContainerLogTarget(params); err != nil {
+				return "", err
+			}
 		}
 
 		// Extract lines parameter
@@ -123,34 +115,103 @@ func CollectAKSNodeLogsHandler(client *azureclient.AzureClient, cfg *config.Conf
 			return "", err
 		}
 
-		// Build the command
-		command, err := buildLogCommand(logType, lines, since, level, filter)
+		// The command is built per node OS, once the target nodes are known
+		request := nodeLogRequest{logType: logType, lines: lines, since: since, level: level, filter: filter, container: container}
+
+		// A node pool or label selector collects from many nodes at once
+		if fanOut, err := isFanOutRequest(params); err != nil {
+			return "", err
+		} else if fanOut {
+			if isAsyncRequest(params) {
+				// Report invalid fan-out options now rather than as a failed job
+				if _, err := parseFanOutOptions(params); err != nil {
+					return "", err
+				}
+				description := fmt.Sprintf("%s logs from %s", logType, fanOutScope(params))
+				return startRunCommandJob(ctx, JobKindNodeLogs, description, func(ctx context.Context) (string, error) {
+					return collectFanOutNodeLogs(ctx, client, cluster, clusterName, params, request)
+				})
+			}
+			return collectFanOutNodeLogs(ctx, client, cluster, clusterName, params, request)
+		}
+
+		// Resolve the VMSS instance from node_name or from vmss_name and instance_id
+		vmssName, instanceID, err := resolveNodeTargetParams(ctx, client, cluster, params)
+		if err != nil {
+			return "", err
+		}
+
+		// Validate that the node is a VMSS instance and get its OS
+		osType, err := validateVMSSSupport(ctx, client, subID, nodeResourceGroup, vmssName)
+		if err != nil {
+			return "", err
+		}
+
+		commandID, command, err := request.command(osType)
 		if err != nil {
 			return "", fmt.Errorf("failed to build log command: %w", err)
 		}
 
-		logger.Debugf("CollectAKSNodeLogs: cluster=%s/%s, nodeRG=%s, vmss=%s, instance=%s, type=%s, lines=%d, since=%s, level=%s, filter=%s",
-			rg, clusterName, nodeResourceGroup, vmssName, instanceID, logType, lines, since, level, filter)
+		logger.Debugf("CollectAKSNodeLogs: cluster=%s/%s, nodeRG=%s, vmss=%s, instance=%s, os=%s, type=%s, lines=%d, since=%s, level=%s, filter=%s",
+			rg, clusterName, nodeResourceGroup, vmssName, instanceID, osType, logType, lines, since, level, filter)
 		logger.Debugf("CollectAKSNodeLogs: command=%s", command)
 
-		// Execute the command on VMSS instance (using node resource group)
-		executor := NewVMRunCommandExecutor(client)
-		output, err := executor.ExecuteOnVMSSInstance(ctx, subID, nodeResourceGroup, vmssName, instanceID, command)
-		if err != nil {
-			return "", fmt.Errorf("failed to collect %s logs from VMSS %s/%s instance %s: %w",
-				logType, nodeResourceGroup, vmssName, instanceID, err)
+		collect := func(ctx context.Context) (string, error) {
+			// Execute the command on VMSS instance (using node resource group)
+			executor := NewVMRunCommandExecutor(client)
+			output, err := executor.ExecuteScriptOnVMSSInstance(ctx, subID, nodeResourceGroup, vmssName, instanceID, commandID, command)
+			if err != nil {
+				return "", fmt.Errorf("failed to collect %s logs from VMSS %s/%s instance %s: %w",
+					logType, nodeResourceGroup, vmssName, instanceID, err)
+			}
+
+			// Format the output with metadata
+			return formatLogOutput(clusterName, nodeResourceGroup, vmssName, instanceID, logType, output), nil
 		}
 
-		// Format the output with metadata
-		result := formatLogOutput(clusterName, nodeResourceGroup, vmssName, instanceID, logType, output)
-		return result, nil
+		if isAsyncRequest(params) {
+			description := fmt.Sprintf("%s logs from VMSS %s instance %s", logType, vmssName, instanceID)
+			return startRunCommandJob(ctx, JobKindNodeLogs, description, collect)
+		}
+		return collect(ctx)
 	})
 }
 
+// nodeLogRequest holds the validated log collection parameters of a collect_aks_node_logs call
+type nodeLogRequest struct {
+	logType string
+	lines   int
+	since   string
+	level   string
+	filter  string
+	// container is the target of the container log type
+	container containerLogTarget
+}
+
+// command returns the run command ID and the script collecting the logs on a node of the given OS
+func (r nodeLogRequest) command(osType string) (string, string, error) {
+	if osType == OSTypeWindows {
+		script, err := buildWindowsLogCommand(r.logType, r.lines, r.since, r.level, r.filter)
+		return CommandIDRunPowerShellScript, script, err
+	}
+
+	switch r.logType {
+	case LogTypeEventLogSystem, LogTypeEventLogApplication, LogTypeEventLogHyperVCompute:
+		return "", "", fmt.Errorf("log type %s is only available on Windows nodes", r.logType)
+	case LogTypeContainer:
+		script, err := buildContainerLogCommand(r.container, r.lines, r.since, r.level, r.filter)
+		return CommandIDRunShellScript, script, err
+	}
+	script, err := buildLogCommand(r.logType, r.lines, r.since, r.level, r.filter)
+	return CommandIDRunShellScript, script, err
+}
+
 // isValidLogType checks if the log type is valid
 func isValidLogType(logType string) bool {
 	switch logType {
-	case LogTypeKubelet, LogTypeContainerd, LogTypeKernel, LogTypeSyslog:
+	case LogTypeKubelet, LogTypeContainerd, LogTypeKernel, LogTypeSyslog,
+		LogTypeKubeProxy, LogTypeCSE, LogTypeCloudInit, LogTypeWAAgent, LogTypeExtensions, LogTypeNodeProblemDetector, LogTypeContainer,
+		LogTypeEventLogSystem, LogTypeEventLogApplication, LogTypeEventLogHyperVCompute:
 		return true
 	default:
 		return false
@@ -226,6 +287,14 @@ func buildLogCommand(logType string, lines int, since string, level string, filt
 		cmd = buildDmesgCommand(lines, level, filter)
 	case LogTypeSyslog:
 		cmd = buildSyslogCommand(lines, since, level, filter)
+	case LogTypeCSE, LogTypeCloudInit, LogTypeWAAgent:
+		return buildFileLogCommand(logType, lines, since, level, filter)
+	case LogTypeExtensions:
+		return buildExtensionLogCommand(lines, since, level, filter)
+	case LogTypeKubeProxy:
+		return buildKubeProxyLogCommand(lines, since, level, filter)
+	case LogTypeNodeProblemDetector:
+		return buildNodeProblemDetectorCommand(lines, since, level, filter)
 	default:
 		return "", fmt.Errorf("unsupported log type: %s", logType)
 	}
@@ -409,34 +478,34 @@ func formatLogOutput(clusterName, rg, vmssName, instanceID, logType, output stri
 	return result.String()
 }
 
-// validateVMSSSupport validates that the node is a Linux VMSS
-// Currently only Linux VMSS are supported (not standalone VMs or Windows nodes)
+// validateVMSSSupport validates that the node is a VMSS instance and returns the OS type of the scale set
+// Currently only VMSS are supported (not standalone VMs)
 func validateVMSSSupport(
 	ctx context.Context,
 	client *azureclient.AzureClient,
 	subscriptionID string,
 	nodeResourceGroup string,
 	vmssName string,
-) error {
+) (string, error) {
 	// Get VMSS client for the subscription
 	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
 	if err != nil {
-		return fmt.Errorf("failed to get clients for subscription: %w", err)
+		return "", fmt.Errorf("failed to get clients for subscription: %w", err)
 	}
 
 	// Try to get VMSS to verify it exists (and is not a standalone VM)
 	vmss, err := clients.VMSSClient.Get(ctx, nodeResourceGroup, vmssName, nil)
 	if err != nil {
 		// If VMSS not found, it's likely a standalone VM
-		return fmt.Errorf("VMSS '%s' not found. This tool currently only supports VMSS-based nodes. Standalone VMs are not supported yet", vmssName)
+		return "", fmt.Errorf("VMSS '%s' not found. This tool currently only supports VMSS-based nodes. Standalone VMs are not supported yet", vmssName)
 	}
 
 	// Check if it's a Windows VMSS
 	if vmss.Properties != nil && vmss.Properties.VirtualMachineProfile != nil &&
 		vmss.Properties.VirtualMachineProfile.OSProfile != nil &&
 		vmss.Properties.VirtualMachineProfile.OSProfile.WindowsConfiguration != nil {
-		return fmt.Errorf("windows nodes are not supported yet, this tool only supports Linux VMSS nodes")
+		return OSTypeWindows, nil
 	}
 
-	return nil
+	return OSTypeLinux, nil
 }
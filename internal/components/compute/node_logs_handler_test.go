@@ -1,6 +1,7 @@
 package compute
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -15,6 +16,15 @@ func TestIsValidLogType(t *testing.T) {
 		{"valid containerd", LogTypeContainerd, true},
 		{"valid kernel", LogTypeKernel, true},
 		{"valid syslog", LogTypeSyslog, true},
+		{"valid kube-proxy", LogTypeKubeProxy, true},
+		{"valid cse", LogTypeCSE, true},
+		{"valid system event log", LogTypeEventLogSystem, true},
+		{"valid hyper-v compute event log", LogTypeEventLogHyperVCompute, true},
+		{"valid cloud-init", LogTypeCloudInit, true},
+		{"valid waagent", LogTypeWAAgent, true},
+		{"valid extensions", LogTypeExtensions, true},
+		{"valid node-problem-detector", LogTypeNodeProblemDetector, true},
+		{"valid container", LogTypeContainer, true},
 		{"invalid empty", "", false},
 		{"invalid unknown", "unknown", false},
 		{"invalid case", "KUBELET", false},
@@ -568,3 +578,38 @@ func TestValidateFilterParameter(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeLogRequestCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   nodeLogRequest
+		osType    string
+		commandID string
+		prefix    string
+		wantErr   bool
+	}{
+		{"linux kubelet", nodeLogRequest{logType: LogTypeKubelet, lines: 10, level: LogLevelInfo}, OSTypeLinux, CommandIDRunShellScript, "journalctl -u kubelet", false},
+		{"windows kubelet", nodeLogRequest{logType: LogTypeKubelet, lines: 10, level: LogLevelInfo}, OSTypeWindows, CommandIDRunPowerShellScript, "Get-Content", false},
+		{"windows event log", nodeLogRequest{logType: LogTypeEventLogSystem, lines: 10, level: LogLevelInfo}, OSTypeWindows, CommandIDRunPowerShellScript, "Get-WinEvent", false},
+		{"windows only type on linux", nodeLogRequest{logType: LogTypeEventLogSystem, lines: 10, level: LogLevelInfo}, OSTypeLinux, "", "", true},
+		{"linux only type on windows", nodeLogRequest{logType: LogTypeKernel, lines: 10, level: LogLevelInfo}, OSTypeWindows, "", "", true},
+		{"linux cse", nodeLogRequest{logType: LogTypeCSE, lines: 10, level: LogLevelInfo}, OSTypeLinux, CommandIDRunShellScript, "cat /var/log/azure/cluster-provision.log", false},
+		{"linux container", nodeLogRequest{logType: LogTypeContainer, lines: 10, level: LogLevelInfo, container: containerLogTarget{namespace: "default", pod: "web-0", container: "app"}}, OSTypeLinux, CommandIDRunShellScript, "ls -1tr /var/log/pods/default_web-0_", false},
+		{"container on windows", nodeLogRequest{logType: LogTypeContainer, lines: 10, level: LogLevelInfo, container: containerLogTarget{namespace: "default", pod: "web-0", container: "app"}}, OSTypeWindows, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandID, command, err := tt.request.command(tt.osType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("command(%q) error = %v, wantErr %v", tt.osType, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if commandID != tt.commandID || !strings.HasPrefix(command, tt.prefix) {
+				t.Errorf("command(%q) = %q, %q, want %q, prefix %q", tt.osType, commandID, command, tt.commandID, tt.prefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package compute
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Log types available on both Linux and Windows nodes
+	LogTypeKubeProxy = "kube-proxy"
+	LogTypeCSE       = "cse"
+
+	// Windows only log types
+	LogTypeEventLogSystem        = "eventlog-system"
+	LogTypeEventLogApplication   = "eventlog-application"
+	LogTypeEventLogHyperVCompute = "eventlog-hyperv-compute"
+
+	// OS types of node scale sets
+	OSTypeLinux   = "Linux"
+	OSTypeWindows = "Windows"
+)
+
+// windowsLogFiles are the log files written by the Windows node components
+var windowsLogFiles = map[string][]string{
+	LogTypeKubelet:    {`C:\k\kubelet.log`, `C:\k\kubelet.err.log`},
+	LogTypeContainerd: {`C:\k\containerd.log`, `C:\k\containerd.err.log`},
+	LogTypeKubeProxy:  {`C:\k\kubeproxy.log`, `C:\k\kubeproxy.err.log`},
+	LogTypeCSE:        {`C:\AzureData\CustomDataSetupScript.log`},
+}
+
+// windowsEventLogs are the event log channels queried for each event log type
+var windowsEventLogs = map[string][]string{
+	LogTypeEventLogSystem:        {"System"},
+	LogTypeEventLogApplication:   {"Application"},
+	LogTypeEventLogHyperVCompute: {"Microsoft-Windows-Hyper-V-Compute-Admin", "Microsoft-Windows-Hyper-V-Compute-Operational"},
+}
+
+// buildWindowsLogCommand builds the PowerShell script to collect logs from a Windows node.
+// since and filter go through the same validation as the Linux builders before they are quoted.
+func buildWindowsLogCommand(logType string, lines int, since string, level string, filter string) (string, error) {
+	if err := validateSinceParameter(since); err != nil {
+		return "", err
+	}
+	if err := validateFilterParameter(filter); err != nil {
+		return "", err
+	}
+	if !isValidLogLevel(level) {
+		return "", fmt.Errorf("invalid level: %s (must be one of: ERROR, WARN, INFO)", level)
+	}
+
+	if files, ok := windowsLogFiles[logType]; ok {
+		if since != "" {
+			return "", fmt.Errorf("since is not supported for %s logs on Windows nodes, which are read from log files; use lines instead", logType)
+		}
+		return buildWindowsFileLogCommand(files, lines, level, filter), nil
+	}
+	if logNames, ok := windowsEventLogs[logType]; ok {
+		return buildWindowsEventLogCommand(logNames, lines, since, level, filter), nil
+	}
+	return "", fmt.Errorf("log type %s is not available on Windows nodes", logType)
+}
+
+// buildWindowsFileLogCommand builds a Get-Content pipeline for the log files of a Windows node component
+func buildWindowsFileLogCommand(files []string, lines int, level string, filter string) string {
+	quoted := make([]string, 0, len(files))
+	for _, file := range files {
+		quoted = append(quoted, powerShellQuote(file))
+	}
+	cmd := fmt.Sprintf("Get-Content -Path %s -ErrorAction SilentlyContinue", strings.Join(quoted, ","))
+
+	// Filter by the klog severity prefix (E1018, W1018) or error/warning keywords
+	switch level {
+	case LogLevelError:
+		cmd += " | Select-String -Pattern '^E[0-9]{4} ','error'"
+	case LogLevelWarn:
+		cmd += " | Select-String -Pattern '^[EW][0-9]{4} ','error','warn'"
+	}
+
+	// Add text filter (case insensitive, fixed string match)
+	if filter != "" {
+		cmd += fmt.Sprintf(" | Select-String -SimpleMatch -Pattern %s", powerShellQuote(filter))
+	}
+
+	// Add line limit at the end (after all filters)
+	cmd += fmt.Sprintf(" | Select-Object -Last %d | ForEach-Object { $_.ToString() }", lines)
+
+	return cmd
+}
+
+// buildWindowsEventLogCommand builds a Get-WinEvent query for Windows event log channels. Events
+// are printed oldest first as "<ISO time> <level> <provider>[<event id>]: <message>".
+func buildWindowsEventLogCommand(logNames []string, lines int, since string, level string, filter string) string {
+	quoted := make([]string, 0, len(logNames))
+	for _, name := range logNames {
+		quoted = append(quoted, powerShellQuote(name))
+	}
+
+	filters := []string{fmt.Sprintf("LogName=%s", strings.Join(quoted, ","))}
+	if since != "" {
+		filters = append(filters, fmt.Sprintf("StartTime=%s", powerShellSinceExpression(since)))
+	}
+	// Event levels: 1 critical, 2 error, 3 warning, 4 information
+	switch level {
+	case LogLevelError:
+		filters = append(filters, "Level=1,2")
+	case LogLevelWarn:
+		filters = append(filters, "Level=1,2,3")
+	}
+
+	cmd := fmt.Sprintf("Get-WinEvent -FilterHashtable @{%s} -ErrorAction SilentlyContinue", strings.Join(filters, "; "))
+
+	// Get-WinEvent returns the newest events first, so the text filter runs before the limit
+	if filter != "" {
+		cmd += fmt.Sprintf(" | Where-Object { $_.Message -and $_.Message.IndexOf(%s, [StringComparison]::OrdinalIgnoreCase) -ge 0 }", powerShellQuote(filter))
+	}
+	cmd += fmt.Sprintf(" | Select-Object -First %d | Sort-Object TimeCreated", lines)
+	cmd += ` | ForEach-Object { '{0} {1} {2}[{3}]: {4}' -f $_.TimeCreated.ToUniversalTime().ToString('yyyy-MM-ddTHH:mm:ssZ'), $_.LevelDisplayName, $_.ProviderName, $_.Id, ($_.Message -replace '\s+', ' ') }`
+
+	return cmd
+}
+
+// powerShellSinceExpression converts a validated since parameter to a PowerShell datetime expression
+// Examples: "1h" -> "(Get-Date).AddHours(-1)", "2 days ago" -> "(Get-Date).AddDays(-2)",
+// "2024-01-01 10:00" -> "[datetime]'2024-01-01 10:00'"
+func powerShellSinceExpression(since string) string {
+	if sinceTimestampRegex.MatchString(since) {
+		return fmt.Sprintf("[datetime]%s", powerShellQuote(since))
+	}
+
+	// Shorthand values are converted to the relative format first
+	relative := strings.Fields(formatSinceParameter(since))
+	if len(relative) != 3 {
+		return fmt.Sprintf("[datetime]%s", powerShellQuote(since))
+	}
+
+	value, unit := relative[0], strings.TrimSuffix(relative[1], "s")
+	switch unit {
+	case "hour":
+		return fmt.Sprintf("(Get-Date).AddHours(-%s)", value)
+	case "minute":
+		return fmt.Sprintf("(Get-Date).AddMinutes(-%s)", value)
+	case "second":
+		return fmt.Sprintf("(Get-Date).AddSeconds(-%s)", value)
+	case "day":
+		return fmt.Sprintf("(Get-Date).AddDays(-%s)", value)
+	case "week":
+		return fmt.Sprintf("(Get-Date).AddDays(-(%s*7))", value)
+	default:
+		return fmt.Sprintf("[datetime]%s", powerShellQuote(since))
+	}
+}
+
+// powerShellSingleQuotes are the characters PowerShell accepts as single quotes: the ASCII
+// apostrophe and the typographic quotes U+2018 to U+201B
+const powerShellSingleQuotes = "'‘’‚‛"
+
+// powerShellQuote quotes a string as a PowerShell single-quoted literal. Any of the single quote
+// characters would end the literal, so each one is escaped by doubling it.
+func powerShellQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		if strings.ContainsRune(powerShellSingleQuotes, r) {
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
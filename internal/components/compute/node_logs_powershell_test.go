@@ -0,0 +1,197 @@
+package compute
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildWindowsLogCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		logType     string
+		lines       int
+		since       string
+		level       string
+		filter      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "kubelet log",
+			logType:  LogTypeKubelet,
+			lines:    500,
+			level:    LogLevelInfo,
+			expected: `Get-Content -Path 'C:\k\kubelet.log','C:\k\kubelet.err.log' -ErrorAction SilentlyContinue | Select-Object -Last 500 | ForEach-Object { $_.ToString() }`,
+		},
+		{
+			name:     "containerd log with ERROR",
+			logType:  LogTypeContainerd,
+			lines:    100,
+			level:    LogLevelError,
+			expected: `Get-Content -Path 'C:\k\containerd.log','C:\k\containerd.err.log' -ErrorAction SilentlyContinue | Select-String -Pattern '^E[0-9]{4} ','error' | Select-Object -Last 100 | ForEach-Object { $_.ToString() }`,
+		},
+		{
+			name:     "kube-proxy log with WARN and filter",
+			logType:  LogTypeKubeProxy,
+			lines:    50,
+			level:    LogLevelWarn,
+			filter:   "hns",
+			expected: `Get-Content -Path 'C:\k\kubeproxy.log','C:\k\kubeproxy.err.log' -ErrorAction SilentlyContinue | Select-String -Pattern '^[EW][0-9]{4} ','error','warn' | Select-String -SimpleMatch -Pattern 'hns' | Select-Object -Last 50 | ForEach-Object { $_.ToString() }`,
+		},
+		{
+			name:     "filter with typographic quotes",
+			logType:  LogTypeKubelet,
+			lines:    10,
+			level:    LogLevelInfo,
+			filter:   "can’t pull",
+			expected: `Get-Content -Path 'C:\k\kubelet.log','C:\k\kubelet.err.log' -ErrorAction SilentlyContinue | Select-String -SimpleMatch -Pattern 'can’’t pull' | Select-Object -Last 10 | ForEach-Object { $_.ToString() }`,
+		},
+		{
+			name:     "cse log",
+			logType:  LogTypeCSE,
+			lines:    200,
+			level:    LogLevelInfo,
+			expected: `Get-Content -Path 'C:\AzureData\CustomDataSetupScript.log' -ErrorAction SilentlyContinue | Select-Object -Last 200 | ForEach-Object { $_.ToString() }`,
+		},
+		{
+			name:        "since on a file log",
+			logType:     LogTypeKubelet,
+			lines:       500,
+			since:       "1h",
+			level:       LogLevelInfo,
+			expectError: true,
+		},
+		{
+			name:        "linux only log type",
+			logType:     LogTypeKernel,
+			lines:       500,
+			level:       LogLevelInfo,
+			expectError: true,
+		},
+		{
+			name:        "filter injection",
+			logType:     LogTypeKubelet,
+			lines:       500,
+			level:       LogLevelInfo,
+			filter:      "x'; Remove-Item C:\\k -Recurse; '",
+			expectError: true,
+		},
+		{
+			name:        "filter subexpression",
+			logType:     LogTypeEventLogSystem,
+			lines:       500,
+			level:       LogLevelInfo,
+			filter:      "$(Stop-Computer)",
+			expectError: true,
+		},
+		{
+			name:        "since injection",
+			logType:     LogTypeEventLogSystem,
+			lines:       500,
+			since:       "1h'; Stop-Computer; '",
+			level:       LogLevelInfo,
+			expectError: true,
+		},
+		{
+			name:        "invalid level",
+			logType:     LogTypeEventLogSystem,
+			lines:       500,
+			level:       "DEBUG",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := buildWindowsLogCommand(tt.logType, tt.lines, tt.since, tt.level, tt.filter)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("buildWindowsLogCommand() expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildWindowsLogCommand() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("buildWindowsLogCommand() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildWindowsEventLogCommand(t *testing.T) {
+	command, err := buildWindowsLogCommand(LogTypeEventLogHyperVCompute, 100, "2h", LogLevelError, "container")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"Get-WinEvent -FilterHashtable @{LogName='Microsoft-Windows-Hyper-V-Compute-Admin','Microsoft-Windows-Hyper-V-Compute-Operational'; StartTime=(Get-Date).AddHours(-2); Level=1,2}",
+		"$_.Message.IndexOf('container', [StringComparison]::OrdinalIgnoreCase) -ge 0",
+		"Select-Object -First 100 | Sort-Object TimeCreated",
+		"ToString('yyyy-MM-ddTHH:mm:ssZ')",
+	} {
+		if !strings.Contains(command, expected) {
+			t.Errorf("Expected command to contain %q, got %q", expected, command)
+		}
+	}
+
+	command, err = buildWindowsLogCommand(LogTypeEventLogSystem, 10, "", LogLevelInfo, "can’t pull")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "$_.Message.IndexOf('can’’t pull', [StringComparison]::OrdinalIgnoreCase)"; !strings.Contains(command, expected) {
+		t.Errorf("Expected command to contain %q, got %q", expected, command)
+	}
+
+	command, err = buildWindowsLogCommand(LogTypeEventLogApplication, 10, "", LogLevelInfo, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(command, "Get-WinEvent -FilterHashtable @{LogName='Application'} -ErrorAction SilentlyContinue | Select-Object -First 10") {
+		t.Errorf("Unexpected command: %q", command)
+	}
+}
+
+func TestPowerShellSinceExpression(t *testing.T) {
+	tests := []struct {
+		since    string
+		expected string
+	}{
+		{"1h", "(Get-Date).AddHours(-1)"},
+		{"30m", "(Get-Date).AddMinutes(-30)"},
+		{"45s", "(Get-Date).AddSeconds(-45)"},
+		{"2d", "(Get-Date).AddDays(-2)"},
+		{"1w", "(Get-Date).AddDays(-(1*7))"},
+		{"3 hours ago", "(Get-Date).AddHours(-3)"},
+		{"1 day ago", "(Get-Date).AddDays(-1)"},
+		{"2024-01-01", "[datetime]'2024-01-01'"},
+		{"2024-01-01 10:00:00", "[datetime]'2024-01-01 10:00:00'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			if result := powerShellSinceExpression(tt.since); result != tt.expected {
+				t.Errorf("powerShellSinceExpression(%q) = %q, want %q", tt.since, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPowerShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"it's", "'it''s'"},
+		// PowerShell also ends single-quoted literals at the typographic quotes U+2018 to U+201B
+		{"x’; Stop-Computer; ’", "'x’’; Stop-Computer; ’’'"},
+		{"‘a‚b‛", "'‘‘a‚‚b‛‛'"},
+	}
+
+	for _, tt := range tests {
+		if result := powerShellQuote(tt.input); result != tt.expected {
+			t.Errorf("powerShellQuote(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
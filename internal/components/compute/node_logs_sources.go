@@ -0,0 +1,183 @@
+package compute
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Node bootstrap and agent log types
+	LogTypeCloudInit           = "cloud-init"
+	LogTypeWAAgent             = "waagent"
+	LogTypeExtensions          = "extensions"
+	LogTypeNodeProblemDetector = "node-problem-detector"
+	LogTypeContainer           = "container"
+
+	// Kubernetes writes container logs to /var/log/pods/{namespace}_{pod}_{uid}/{container}/{restart}.log
+	podLogsDir = "/var/log/pods"
+	// Extensions log to /var/log/azure/{extension}/ (the CSE writes cluster-provision.log at the top level)
+	extensionLogsDir = "/var/log/azure"
+)
+
+// linuxLogFiles are the log files read for the file based log types of Linux nodes
+var linuxLogFiles = map[string][]string{
+	LogTypeCSE:       {"/var/log/azure/cluster-provision.log", "/var/log/azure/cluster-provision-cse-output.log"},
+	LogTypeCloudInit: {"/var/log/cloud-init.log", "/var/log/cloud-init-output.log"},
+	LogTypeWAAgent:   {"/var/log/waagent.log"},
+}
+
+// Compiled regexes for container log target validation
+var (
+	// Namespaces and container names are DNS-1123 labels
+	dnsLabelRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	// Pod names are DNS-1123 subdomains
+	dnsSubdomainRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+)
+
+// containerLogTarget identifies the container whose log files are read with the container log type
+type containerLogTarget struct {
+	namespace string
+	pod       string
+	container string
+}
+
+// parseContainerLogTarget extracts and validates the namespace, pod and container parameters
+func parseContainerLogTarget(params map[string]any) (containerLogTarget, error) {
+	namespace, _ := params["namespace"].(string)
+	pod, _ := params["pod"].(string)
+	container, _ := params["container"].(string)
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	target := containerLogTarget{namespace: namespace, pod: pod, container: container}
+	if err := target.validate(); err != nil {
+		return containerLogTarget{}, err
+	}
+	return target, nil
+}
+
+// validate checks the target against the Kubernetes naming rules. The names are used in a file
+// glob, so this also rules out path traversal and shell metacharacters.
+func (t containerLogTarget) validate() error {
+	if t.pod == "" || t.container == "" {
+		return fmt.Errorf("pod and container are required for log_type %s", LogTypeContainer)
+	}
+	if !dnsLabelRegex.MatchString(t.namespace) {
+		return fmt.Errorf("invalid namespace %q: must be a lowercase RFC 1123 label", t.namespace)
+	}
+	if !dnsSubdomainRegex.MatchString(t.pod) {
+		return fmt.Errorf("invalid pod %q: must be a lowercase RFC 1123 subdomain", t.pod)
+	}
+	if !dnsLabelRegex.MatchString(t.container) {
+		return fmt.Errorf("invalid container %q: must be a lowercase RFC 1123 label", t.container)
+	}
+	return nil
+}
+
+// buildFileLogCommand builds the command to read the log files of a file based log type (cse,
+// cloud-init, waagent). The files have no common timestamp format, so since is not supported.
+func buildFileLogCommand(logType string, lines int, since string, level string, filter string) (string, error) {
+	files, ok := linuxLogFiles[logType]
+	if !ok {
+		return "", fmt.Errorf("unsupported log file type: %s", logType)
+	}
+	if err := validateFileLogParameters(logType, since, level, filter); err != nil {
+		return "", err
+	}
+
+	cmd := fmt.Sprintf("cat %s 2>/dev/null", strings.Join(files, " "))
+	return appendFileLogFilters(cmd, lines, level, filter), nil
+}
+
+// buildExtensionLogCommand builds the command to read the logs of the VM extensions, each line
+// prefixed with the path of its log file
+func buildExtensionLogCommand(lines int, since string, level string, filter string) (string, error) {
+	if err := validateFileLogParameters(LogTypeExtensions, since, level, filter); err != nil {
+		return "", err
+	}
+
+	cmd := fmt.Sprintf("find %s -mindepth 2 -type f -name '*.log' -exec grep -H '' {} + 2>/dev/null", extensionLogsDir)
+	return appendFileLogFilters(cmd, lines, level, filter), nil
+}
+
+// buildNodeProblemDetectorCommand builds journalctl command for the node-problem-detector service
+func buildNodeProblemDetectorCommand(lines int, since string, level string, filter string) (string, error) {
+	if err := validateSinceParameter(since); err != nil {
+		return "", err
+	}
+	if err := validateFilterParameter(filter); err != nil {
+		return "", err
+	}
+	if !isValidLogLevel(level) {
+		return "", fmt.Errorf("invalid level: %s (must be one of: ERROR, WARN, INFO)", level)
+	}
+	return buildJournalctlCommand("node-problem-detector", lines, since, level, filter), nil
+}
+
+// buildKubeProxyLogCommand builds the command to read the container logs of the kube-proxy pod of the node
+func buildKubeProxyLogCommand(lines int, since string, level string, filter string) (string, error) {
+	if err := validateFileLogParameters(LogTypeKubeProxy, since, level, filter); err != nil {
+		return "", err
+	}
+
+	cmd := podLogFilesCommand(fmt.Sprintf("%s/kube-system_kube-proxy-*/kube-proxy/*.log", podLogsDir))
+	return appendFileLogFilters(cmd, lines, level, filter), nil
+}
+
+// buildContainerLogCommand builds the command to read the log files of a container, oldest restart first
+func buildContainerLogCommand(target containerLogTarget, lines int, since string, level string, filter string) (string, error) {
+	if err := target.validate(); err != nil {
+		return "", err
+	}
+	if err := validateFileLogParameters(LogTypeContainer, since, level, filter); err != nil {
+		return "", err
+	}
+
+	// Pod names cannot contain underscores, so the glob only matches the UIDs of this pod
+	cmd := podLogFilesCommand(fmt.Sprintf("%s/%s_%s_*/%s/*.log", podLogsDir, target.namespace, target.pod, target.container))
+	return appendFileLogFilters(cmd, lines, level, filter), nil
+}
+
+// validateFileLogParameters validates the parameters of the log types read from files
+func validateFileLogParameters(logType string, since string, level string, filter string) error {
+	if since != "" {
+		return fmt.Errorf("since is not supported for %s logs, which are read from log files; use lines instead", logType)
+	}
+	if err := validateFilterParameter(filter); err != nil {
+		return err
+	}
+	if !isValidLogLevel(level) {
+		return fmt.Errorf("invalid level: %s (must be one of: ERROR, WARN, INFO)", level)
+	}
+	return nil
+}
+
+// podLogFilesCommand prints the log files matching a glob in modification order
+func podLogFilesCommand(glob string) string {
+	return fmt.Sprintf("ls -1tr %s 2>/dev/null | xargs -r cat", glob)
+}
+
+// appendFileLogFilters adds the level filter, the text filter and the line limit to a file log command
+func appendFileLogFilters(cmd string, lines int, level string, filter string) string {
+	// Match klog severity prefixes (E1018, W1018) and error/warning keywords
+	switch level {
+	case LogLevelError:
+		cmd += " | grep -iE '(^| )E[0-9]{4} |error|fatal|fail'"
+	case LogLevelWarn:
+		cmd += " | grep -iE '(^| )[EW][0-9]{4} |error|fatal|fail|warn'"
+	}
+
+	// Add text filter (case insensitive, fixed string match)
+	if filter != "" {
+		// Escape single quotes in the filter text
+		escapedFilter := strings.ReplaceAll(filter, "'", "'\\''")
+		cmd += fmt.Sprintf(" | grep -iF '%s'", escapedFilter)
+	}
+
+	// Add line limit at the end (after all filters)
+	cmd += fmt.Sprintf(" | tail -n %d", lines)
+
+	return cmd
+}
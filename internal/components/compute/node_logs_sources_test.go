@@ -0,0 +1,194 @@
+package compute
+
+import (
+	"testing"
+)
+
+func TestBuildFileLogCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		logType     string
+		lines       int
+		since       string
+		level       string
+		filter      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "cse log",
+			logType:  LogTypeCSE,
+			lines:    500,
+			level:    LogLevelInfo,
+			expected: "cat /var/log/azure/cluster-provision.log /var/log/azure/cluster-provision-cse-output.log 2>/dev/null | tail -n 500",
+		},
+		{
+			name:     "cloud-init log with ERROR",
+			logType:  LogTypeCloudInit,
+			lines:    100,
+			level:    LogLevelError,
+			expected: "cat /var/log/cloud-init.log /var/log/cloud-init-output.log 2>/dev/null | grep -iE '(^| )E[0-9]{4} |error|fatal|fail' | tail -n 100",
+		},
+		{
+			name:     "waagent log with WARN and filter",
+			logType:  LogTypeWAAgent,
+			lines:    50,
+			level:    LogLevelWarn,
+			filter:   "Microsoft.Azure.Extensions",
+			expected: "cat /var/log/waagent.log 2>/dev/null | grep -iE '(^| )[EW][0-9]{4} |error|fatal|fail|warn' | grep -iF 'Microsoft.Azure.Extensions' | tail -n 50",
+		},
+		{
+			name:        "since is not supported",
+			logType:     LogTypeCSE,
+			lines:       500,
+			since:       "1h",
+			level:       LogLevelInfo,
+			expectError: true,
+		},
+		{
+			name:        "filter injection",
+			logType:     LogTypeWAAgent,
+			lines:       500,
+			level:       LogLevelInfo,
+			filter:      "x'; rm -rf /; echo '",
+			expectError: true,
+		},
+		{
+			name:        "not a file log type",
+			logType:     LogTypeKubelet,
+			lines:       500,
+			level:       LogLevelInfo,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := buildFileLogCommand(tt.logType, tt.lines, tt.since, tt.level, tt.filter)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("buildFileLogCommand() expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildFileLogCommand() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("buildFileLogCommand() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildExtensionLogCommand(t *testing.T) {
+	result, err := buildExtensionLogCommand(200, "", LogLevelInfo, "enable")
+	if err != nil {
+		t.Fatalf("buildExtensionLogCommand() unexpected error: %v", err)
+	}
+	expected := "find /var/log/azure -mindepth 2 -type f -name '*.log' -exec grep -H '' {} + 2>/dev/null | grep -iF 'enable' | tail -n 200"
+	if result != expected {
+		t.Errorf("buildExtensionLogCommand() = %q, want %q", result, expected)
+	}
+
+	if _, err := buildExtensionLogCommand(200, "", LogLevelInfo, "$(reboot)"); err == nil {
+		t.Error("buildExtensionLogCommand() expected error for filter injection")
+	}
+}
+
+func TestBuildNodeProblemDetectorCommand(t *testing.T) {
+	result, err := buildNodeProblemDetectorCommand(100, "2h", LogLevelInfo, "")
+	if err != nil {
+		t.Fatalf("buildNodeProblemDetectorCommand() unexpected error: %v", err)
+	}
+	expected := "journalctl -u node-problem-detector --no-pager --since '2 hours ago' | tail -n 100"
+	if result != expected {
+		t.Errorf("buildNodeProblemDetectorCommand() = %q, want %q", result, expected)
+	}
+
+	if _, err := buildNodeProblemDetectorCommand(100, "1h'; reboot; echo '", LogLevelInfo, ""); err == nil {
+		t.Error("buildNodeProblemDetectorCommand() expected error for since injection")
+	}
+}
+
+func TestBuildKubeProxyLogCommand(t *testing.T) {
+	result, err := buildKubeProxyLogCommand(100, "", LogLevelError, "")
+	if err != nil {
+		t.Fatalf("buildKubeProxyLogCommand() unexpected error: %v", err)
+	}
+	expected := "ls -1tr /var/log/pods/kube-system_kube-proxy-*/kube-proxy/*.log 2>/dev/null | xargs -r cat | grep -iE '(^| )E[0-9]{4} |error|fatal|fail' | tail -n 100"
+	if result != expected {
+		t.Errorf("buildKubeProxyLogCommand() = %q, want %q", result, expected)
+	}
+}
+
+func TestBuildContainerLogCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      containerLogTarget
+		filter      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "container log",
+			target:   containerLogTarget{namespace: "ingress", pod: "nginx-7d9f8c6b5-abcde", container: "controller"},
+			expected: "ls -1tr /var/log/pods/ingress_nginx-7d9f8c6b5-abcde_*/controller/*.log 2>/dev/null | xargs -r cat | tail -n 500",
+		},
+		{
+			name:     "container log with filter",
+			target:   containerLogTarget{namespace: "default", pod: "web-0", container: "app"},
+			filter:   "timeout",
+			expected: "ls -1tr /var/log/pods/default_web-0_*/app/*.log 2>/dev/null | xargs -r cat | grep -iF 'timeout' | tail -n 500",
+		},
+		{
+			name:        "path traversal in pod",
+			target:      containerLogTarget{namespace: "default", pod: "../../etc", container: "app"},
+			expectError: true,
+		},
+		{
+			name:        "glob in namespace",
+			target:      containerLogTarget{namespace: "*", pod: "web-0", container: "app"},
+			expectError: true,
+		},
+		{
+			name:        "command in container",
+			target:      containerLogTarget{namespace: "default", pod: "web-0", container: "app;reboot"},
+			expectError: true,
+		},
+		{
+			name:        "missing container",
+			target:      containerLogTarget{namespace: "default", pod: "web-0"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := buildContainerLogCommand(tt.target, 500, "", LogLevelInfo, tt.filter)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("buildContainerLogCommand() expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildContainerLogCommand() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("buildContainerLogCommand() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseContainerLogTarget(t *testing.T) {
+	target, err := parseContainerLogTarget(map[string]any{"pod": "web-0", "container": "app"})
+	if err != nil || target.namespace != "default" || target.pod != "web-0" || target.container != "app" {
+		t.Errorf("parseContainerLogTarget() = %+v, %v", target, err)
+	}
+
+	if _, err := parseContainerLogTarget(map[string]any{"namespace": "Kube-System", "pod": "web-0", "container": "app"}); err == nil {
+		t.Error("parseContainerLogTarget() expected error for an uppercase namespace")
+	}
+}
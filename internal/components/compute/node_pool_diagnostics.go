@@ -0,0 +1,217 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute/imagedrift"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// LatestNodeImageProvider returns the latest node image version available to a node pool
+type LatestNodeImageProvider interface {
+	LatestNodeImageVersion(ctx context.Context, subscriptionID, resourceGroup, clusterName, nodePool string) (string, error)
+}
+
+// agentPoolUpgradeProfileProvider reads the latest node image version from the agent pool
+// upgrade profile, the data behind az aks nodepool get-upgrades
+type agentPoolUpgradeProfileProvider struct {
+	client *azureclient.AzureClient
+}
+
+// LatestNodeImageVersion implements LatestNodeImageProvider
+func (p agentPoolUpgradeProfileProvider) LatestNodeImageVersion(ctx context.Context, subscriptionID, resourceGroup, clusterName, nodePool string) (string, error) {
+	clients, err := p.client.GetOrCreateClientsForSubscription(subscriptionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
+	}
+	profile, err := clients.AgentPoolsClient.GetUpgradeProfile(ctx, resourceGroup, clusterName, nodePool, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get upgrade profile of node pool %s: %v", nodePool, err)
+	}
+	if profile.Properties == nil || profile.Properties.LatestNodeImageVersion == nil {
+		return "", fmt.Errorf("upgrade profile of node pool %s has no latest node image version", nodePool)
+	}
+	return *profile.Properties.LatestNodeImageVersion, nil
+}
+
+// GetAksNodePoolDiagnosticsHandler returns a handler for the aks_node_pool_diagnostics command
+func GetAksNodePoolDiagnosticsHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return newNodePoolDiagnosticsHandler(client, cfg, agentPoolUpgradeProfileProvider{client: client})
+}
+
+// newNodePoolDiagnosticsHandler returns the aks_node_pool_diagnostics handler with the given
+// latest node image provider
+func newNodePoolDiagnosticsHandler(client *azureclient.AzureClient, cfg *config.ConfigData, latestImages LatestNodeImageProvider) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		// Extract operation parameter
+		operation, ok := params["operation"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing or invalid 'operation' parameter")
+		}
+
+		// Validate operation
+		if !ValidateNodePoolDiagnosticsOperation(operation) {
+			supportedOps := GetSupportedNodePoolDiagnosticsOperations()
+			return "", fmt.Errorf("unsupported operation: %s. Supported operations: %v", operation, supportedOps)
+		}
+
+		mergedParams, err := common.MergeOperationParams(params)
+		if err != nil {
+			return "", err
+		}
+
+		// Extract common AKS parameters
+		subID, rg, clusterName, err := common.ExtractAKSParameters(mergedParams)
+		if err != nil {
+			return "", err
+		}
+
+		ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+		defer cancel()
+
+		// Handle different operations
+		switch operation {
+		case string(OpImageDrift):
+			return handleImageDrift(ctx, client, latestImages, mergedParams, subID, rg, clusterName)
+		case string(OpQuotaPreflight):
+			return handleQuotaPreflight(ctx, client, mergedParams, subID, rg, clusterName)
+		case string(OpScaleTimeline):
+			return handleScaleTimeline(ctx, client, cfg, mergedParams, subID, rg, clusterName)
+		default:
+			return "", fmt.Errorf("operation '%s' not implemented", operation)
+		}
+	})
+}
+
+// handleImageDrift compares the node image of each node pool with the latest node image and the
+// image of each VMSS instance with its node pool
+func handleImageDrift(ctx context.Context, client *azureclient.AzureClient, latestImages LatestNodeImageProvider, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	nodePool, _ := params["node_pool"].(string)
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	pools, err := selectNodePools(ctx, client, cluster, nodePool)
+	if err != nil {
+		return "", err
+	}
+
+	input := imageDriftInput(cluster, pools)
+	for i := range input.Pools {
+		pool := &input.Pools[i]
+		latest, err := latestImages.LatestNodeImageVersion(ctx, subID, rg, clusterName, pool.Name)
+		if err != nil {
+			pool.LatestError = err.Error()
+		} else {
+			pool.LatestNodeImageVersion = latest
+		}
+
+		instances, err := GetVMSSInstancesFromNodePool(ctx, cluster, pool.Name, client)
+		if err != nil {
+			pool.InstancesError = err.Error()
+			continue
+		}
+		pool.Instances = imageDriftInstances(instances)
+	}
+
+	result := imagedrift.Analyze(input)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal image drift to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// selectNodePools returns the node pools of the cluster, or only the named node pool
+func selectNodePools(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, nodePool string) ([]*armcontainerservice.ManagedClusterAgentPoolProfile, error) {
+	pools, err := GetNodePoolsFromAKS(ctx, cluster, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node pools: %v", err)
+	}
+	if nodePool == "" {
+		return pools, nil
+	}
+
+	var names []string
+	for _, pool := range pools {
+		if pool != nil && pool.Name != nil {
+			if strings.EqualFold(*pool.Name, nodePool) {
+				return []*armcontainerservice.ManagedClusterAgentPoolProfile{pool}, nil
+			}
+			names = append(names, *pool.Name)
+		}
+	}
+	return nil, fmt.Errorf("node pool %s not found in cluster (node pools: %s)", nodePool, strings.Join(names, ", "))
+}
+
+// imageDriftInput builds the drift input from the cluster and its agent pool profiles
+func imageDriftInput(cluster *armcontainerservice.ManagedCluster, pools []*armcontainerservice.ManagedClusterAgentPoolProfile) imagedrift.Input {
+	input := imagedrift.Input{}
+	if cluster.Properties != nil {
+		input.KubernetesVersion = stringValue(cluster.Properties.CurrentKubernetesVersion)
+		if input.KubernetesVersion == "" {
+			input.KubernetesVersion = stringValue(cluster.Properties.KubernetesVersion)
+		}
+		if profile := cluster.Properties.AutoUpgradeProfile; profile != nil && profile.UpgradeChannel != nil {
+			input.UpgradeChannel = string(*profile.UpgradeChannel)
+		}
+	}
+
+	for _, pool := range pools {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		driftPool := imagedrift.Pool{
+			Name:                *pool.Name,
+			OrchestratorVersion: stringValue(pool.CurrentOrchestratorVersion),
+			NodeImageVersion:    stringValue(pool.NodeImageVersion),
+		}
+		if driftPool.OrchestratorVersion == "" {
+			driftPool.OrchestratorVersion = stringValue(pool.OrchestratorVersion)
+		}
+		if pool.Mode != nil {
+			driftPool.Mode = string(*pool.Mode)
+		}
+		if pool.OSType != nil {
+			driftPool.OSType = string(*pool.OSType)
+		}
+		if pool.OSSKU != nil {
+			driftPool.OSSKU = string(*pool.OSSKU)
+		}
+		input.Pools = append(input.Pools, driftPool)
+	}
+	return input
+}
+
+// imageDriftInstances returns the image reference of each VMSS instance
+func imageDriftInstances(instances []interface{}) []imagedrift.Instance {
+	var result []imagedrift.Instance
+	for _, instance := range instances {
+		vm, ok := instance.(*armcompute.VirtualMachineScaleSetVM)
+		if !ok || vm == nil {
+			continue
+		}
+		driftInstance := imagedrift.Instance{InstanceID: stringValue(vm.InstanceID)}
+		if vm.Properties != nil {
+			if vm.Properties.OSProfile != nil {
+				driftInstance.NodeName = stringValue(vm.Properties.OSProfile.ComputerName)
+			}
+			if vm.Properties.StorageProfile != nil {
+				driftInstance.Image, driftInstance.Version = imageReferenceVersion(vm.Properties.StorageProfile.ImageReference)
+			}
+		}
+		result = append(result, driftInstance)
+	}
+	return result
+}
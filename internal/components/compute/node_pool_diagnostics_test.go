@@ -0,0 +1,132 @@
+package compute
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+type fakeLatestNodeImageProvider map[string]string
+
+func (p fakeLatestNodeImageProvider) LatestNodeImageVersion(_ context.Context, _, _, _, nodePool string) (string, error) {
+	return p[nodePool], nil
+}
+
+func TestAksNodePoolDiagnosticsHandlerValidation(t *testing.T) {
+	handler := newNodePoolDiagnosticsHandler(nil, nil, fakeLatestNodeImageProvider{})
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		expectError string
+	}{
+		{
+			name:        "missing operation",
+			params:      map[string]interface{}{},
+			expectError: "missing or invalid 'operation' parameter",
+		},
+		{
+			name:        "unsupported operation",
+			params:      map[string]interface{}{"operation": "reimage"},
+			expectError: "unsupported operation: reimage",
+		},
+		{
+			name: "missing cluster name",
+			params: map[string]interface{}{
+				"operation":       string(OpImageDrift),
+				"subscription_id": "sub-123",
+				"resource_group":  "rg-test",
+			},
+			expectError: "missing or invalid cluster_name parameter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.Handle(context.Background(), tt.params, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestImageDriftInput(t *testing.T) {
+	channel := armcontainerservice.UpgradeChannelPatch
+	mode := armcontainerservice.AgentPoolModeSystem
+	osType := armcontainerservice.OSTypeLinux
+	osSKU := armcontainerservice.OSSKUUbuntu
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			CurrentKubernetesVersion: to.Ptr("1.30.5"),
+			AutoUpgradeProfile:       &armcontainerservice.ManagedClusterAutoUpgradeProfile{UpgradeChannel: &channel},
+		},
+	}
+	pools := []*armcontainerservice.ManagedClusterAgentPoolProfile{{
+		Name:                       to.Ptr("nodepool1"),
+		Mode:                       &mode,
+		OSType:                     &osType,
+		OSSKU:                      &osSKU,
+		OrchestratorVersion:        to.Ptr("1.30"),
+		CurrentOrchestratorVersion: to.Ptr("1.30.4"),
+		NodeImageVersion:           to.Ptr("AKSUbuntu-2204gen2containerd-202410.09.0"),
+	}}
+
+	input := imageDriftInput(cluster, pools)
+	if input.KubernetesVersion != "1.30.5" || input.UpgradeChannel != "patch" {
+		t.Errorf("Unexpected cluster input: %+v", input)
+	}
+	if len(input.Pools) != 1 {
+		t.Fatalf("Expected 1 pool, got %d", len(input.Pools))
+	}
+	pool := input.Pools[0]
+	if pool.Mode != "System" || pool.OSType != "Linux" || pool.OSSKU != "Ubuntu" || pool.OrchestratorVersion != "1.30.4" {
+		t.Errorf("Unexpected pool input: %+v", pool)
+	}
+}
+
+func TestImageDriftInstances(t *testing.T) {
+	instances := []interface{}{
+		&armcompute.VirtualMachineScaleSetVM{
+			InstanceID: to.Ptr("3"),
+			Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+				OSProfile: &armcompute.OSProfile{ComputerName: to.Ptr("aks-nodepool1-12345678-vmss000003")},
+				StorageProfile: &armcompute.StorageProfile{ImageReference: &armcompute.ImageReference{
+					ID: to.Ptr("/subscriptions/sub/resourceGroups/AKS-Ubuntu/providers/Microsoft.Compute/galleries/AKSUbuntu/images/2204gen2containerd/versions/202409.30.0"),
+				}},
+			},
+		},
+		"not a VM",
+	}
+
+	result := imageDriftInstances(instances)
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 instance, got %d", len(result))
+	}
+	if result[0].NodeName != "aks-nodepool1-12345678-vmss000003" || result[0].Image != "AKSUbuntu/2204gen2containerd" || result[0].Version != "202409.30.0" {
+		t.Errorf("Unexpected instance: %+v", result[0])
+	}
+}
+
+func TestSelectNodePools(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("system")},
+				{Name: to.Ptr("user")},
+			},
+		},
+	}
+
+	pools, err := selectNodePools(context.Background(), nil, cluster, "USER")
+	if err != nil || len(pools) != 1 || *pools[0].Name != "user" {
+		t.Errorf("Expected the user pool, got %v, %v", pools, err)
+	}
+	if _, err := selectNodePools(context.Background(), nil, cluster, "gpu"); err == nil || !strings.Contains(err.Error(), "system, user") {
+		t.Errorf("Expected a not found error listing the pools, got %v", err)
+	}
+}
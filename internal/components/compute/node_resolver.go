@@ -0,0 +1,190 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// azureProviderIDPrefix is the scheme of the spec.providerID of Azure nodes
+const azureProviderIDPrefix = "azure://"
+
+var (
+	// AKS scale sets are named aks-{nodepool}-{hash}-vmss
+	vmssNameRegex = regexp.MustCompile(`^aks-([a-z0-9]+)-([a-z0-9]+)-vmss$`)
+	// VMSS node names are the computer name prefix aks-{nodepool}-{hash}-vmss followed by
+	// the instance ID in base 36, padded to 6 characters
+	vmssNodeNameRegex = regexp.MustCompile(`^aks-([a-z0-9]+)-([a-z0-9]+)-vmss([0-9a-z]{6})$`)
+	// providerID format: azure:///subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Compute/virtualMachineScaleSets/{vmss}/virtualMachines/{instance}
+	vmssProviderIDRegex = regexp.MustCompile(`(?i)^azure:///subscriptions/([^/]+)/resourceGroups/([^/]+)/providers/Microsoft\.Compute/virtualMachineScaleSets/([^/]+)/virtualMachines/([0-9]+)$`)
+)
+
+// NodeInstance identifies the VMSS instance backing a Kubernetes node
+type NodeInstance struct {
+	NodeName       string `json:"node_name,omitempty"`
+	NodePool       string `json:"node_pool,omitempty"`
+	SubscriptionID string `json:"subscription_id"`
+	ResourceGroup  string `json:"resource_group"`
+	VMSSName       string `json:"vmss_name"`
+	InstanceID     string `json:"instance_id"`
+	OSType         string `json:"os_type,omitempty"`
+}
+
+// VMSSID returns the resource ID of the scale set of the instance
+func (n *NodeInstance) VMSSID() string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s",
+		n.SubscriptionID, n.ResourceGroup, n.VMSSName)
+}
+
+// ResolveNodeInstance maps a Kubernetes node name (e.g. aks-nodepool1-12345678-vmss00000a) or a
+// node spec.providerID to the subscription, node resource group, VMSS and instance ID of the node
+func ResolveNodeInstance(
+	ctx context.Context,
+	cluster *armcontainerservice.ManagedCluster,
+	node string,
+	client *azureclient.AzureClient,
+) (*NodeInstance, error) {
+	node = strings.TrimSpace(node)
+	if node == "" {
+		return nil, fmt.Errorf("node_name is required")
+	}
+	if cluster == nil || cluster.Properties == nil || cluster.Properties.NodeResourceGroup == nil {
+		return nil, fmt.Errorf("cluster node resource group not found")
+	}
+	nodeResourceGroup := *cluster.Properties.NodeResourceGroup
+
+	if strings.HasPrefix(strings.ToLower(node), azureProviderIDPrefix) {
+		instance, err := parseNodeProviderID(node)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(instance.ResourceGroup, nodeResourceGroup) {
+			return nil, fmt.Errorf("node %s is in resource group %s, not in the cluster node resource group %s", node, instance.ResourceGroup, nodeResourceGroup)
+		}
+		return instance, nil
+	}
+
+	nodePool, suffix, err := parseVMSSNodeName(node)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := GetVMSSInstancesFromNodePool(ctx, cluster, nodePool, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances of node pool %s: %w", nodePool, err)
+	}
+	vm := findVMSSInstanceForNode(instances, node, suffix)
+	if vm == nil || vm.ID == nil || vm.InstanceID == nil {
+		return nil, fmt.Errorf("no VMSS instance of node pool %s found for node %s", nodePool, node)
+	}
+
+	// Format: /subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Compute/virtualMachineScaleSets/{vmss}/virtualMachines/{instance}
+	parts := strings.Split(*vm.ID, "/")
+	if len(parts) < 9 {
+		return nil, fmt.Errorf("invalid VMSS instance resource ID format: %s", *vm.ID)
+	}
+
+	return &NodeInstance{
+		NodeName:       node,
+		NodePool:       nodePool,
+		SubscriptionID: parts[2],
+		ResourceGroup:  parts[4],
+		VMSSName:       parts[8],
+		InstanceID:     *vm.InstanceID,
+	}, nil
+}
+
+// parseNodeProviderID parses the spec.providerID of a VMSS node
+func parseNodeProviderID(providerID string) (*NodeInstance, error) {
+	matches := vmssProviderIDRegex.FindStringSubmatch(strings.TrimSpace(providerID))
+	if matches == nil {
+		return nil, fmt.Errorf("invalid providerID %q: expected azure:///subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Compute/virtualMachineScaleSets/{vmss}/virtualMachines/{instance_id}. Standalone VMs are not supported yet", providerID)
+	}
+
+	instance := &NodeInstance{
+		SubscriptionID: matches[1],
+		ResourceGroup:  matches[2],
+		VMSSName:       matches[3],
+		InstanceID:     matches[4],
+	}
+	if nameMatches := vmssNameRegex.FindStringSubmatch(strings.ToLower(instance.VMSSName)); nameMatches != nil {
+		instance.NodePool = nameMatches[1]
+	}
+	return instance, nil
+}
+
+// parseVMSSNodeName returns the node pool and the base 36 instance suffix of a VMSS node name
+func parseVMSSNodeName(nodeName string) (string, string, error) {
+	matches := vmssNodeNameRegex.FindStringSubmatch(strings.ToLower(nodeName))
+	if matches == nil {
+		return "", "", fmt.Errorf("invalid node_name %q: expected a VMSS node name like aks-nodepool1-12345678-vmss000000 or a node spec.providerID", nodeName)
+	}
+	return matches[1], matches[3], nil
+}
+
+// findVMSSInstanceForNode finds the instance whose computer name is the node name, falling back
+// to the instance ID encoded in base 36 in the node name suffix
+func findVMSSInstanceForNode(instances []interface{}, nodeName, suffix string) *armcompute.VirtualMachineScaleSetVM {
+	var vms []*armcompute.VirtualMachineScaleSetVM
+	for _, instance := range instances {
+		if vm, ok := instance.(*armcompute.VirtualMachineScaleSetVM); ok && vm != nil {
+			vms = append(vms, vm)
+		}
+	}
+
+	for _, vm := range vms {
+		if vm.Properties != nil && vm.Properties.OSProfile != nil && vm.Properties.OSProfile.ComputerName != nil &&
+			strings.EqualFold(*vm.Properties.OSProfile.ComputerName, nodeName) {
+			return vm
+		}
+	}
+
+	instanceID, err := strconv.ParseInt(suffix, 36, 64)
+	if err != nil {
+		return nil
+	}
+	for _, vm := range vms {
+		if vm.InstanceID != nil && *vm.InstanceID == strconv.FormatInt(instanceID, 10) {
+			return vm
+		}
+	}
+	return nil
+}
+
+// resolveNodeTargetParams returns the VMSS and instance ID of the node a tool targets, given
+// either node_name or both vmss_name and instance_id
+func resolveNodeTargetParams(
+	ctx context.Context,
+	client *azureclient.AzureClient,
+	cluster *armcontainerservice.ManagedCluster,
+	params map[string]any,
+) (string, string, error) {
+	nodeName, _ := params["node_name"].(string)
+	vmssName, _ := params["vmss_name"].(string)
+	instanceID, _ := params["instance_id"].(string)
+
+	if strings.TrimSpace(nodeName) != "" {
+		if vmssName != "" || instanceID != "" {
+			return "", "", fmt.Errorf("specify either node_name or vmss_name and instance_id, not both")
+		}
+		instance, err := ResolveNodeInstance(ctx, cluster, nodeName, client)
+		if err != nil {
+			return "", "", err
+		}
+		return instance.VMSSName, instance.InstanceID, nil
+	}
+
+	if vmssName == "" {
+		return "", "", fmt.Errorf("node_name or vmss_name is required")
+	}
+	if instanceID == "" {
+		return "", "", fmt.Errorf("instance_id is required")
+	}
+	return vmssName, instanceID, nil
+}
@@ -0,0 +1,150 @@
+package compute
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+const testVMSSID = "/subscriptions/sub/resourceGroups/MC_rg_aks_eastus/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss"
+
+func TestParseVMSSNodeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodeName string
+		nodePool string
+		suffix   string
+		wantErr  bool
+	}{
+		{"first instance", "aks-nodepool1-12345678-vmss000000", "nodepool1", "000000", false},
+		{"base36 suffix", "aks-userpool-87654321-vmss00000a", "userpool", "00000a", false},
+		{"standalone VM", "aks-nodepool1-12345678-0", "", "", true},
+		{"provider ID", "azure:///subscriptions/sub", "", "", true},
+		{"empty", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodePool, suffix, err := parseVMSSNodeName(tt.nodeName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVMSSNodeName(%q) error = %v, wantErr %v", tt.nodeName, err, tt.wantErr)
+			}
+			if nodePool != tt.nodePool || suffix != tt.suffix {
+				t.Errorf("parseVMSSNodeName(%q) = %q, %q, want %q, %q", tt.nodeName, nodePool, suffix, tt.nodePool, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestParseNodeProviderID(t *testing.T) {
+	instance, err := parseNodeProviderID("azure://" + testVMSSID + "/virtualMachines/10")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := NodeInstance{
+		NodePool:       "nodepool1",
+		SubscriptionID: "sub",
+		ResourceGroup:  "MC_rg_aks_eastus",
+		VMSSName:       "aks-nodepool1-12345678-vmss",
+		InstanceID:     "10",
+	}
+	if *instance != expected {
+		t.Errorf("parseNodeProviderID() = %+v, want %+v", *instance, expected)
+	}
+	if instance.VMSSID() != testVMSSID {
+		t.Errorf("VMSSID() = %s, want %s", instance.VMSSID(), testVMSSID)
+	}
+
+	invalid := []string{
+		"azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/aks-nodepool1-12345678-0",
+		"azure://" + testVMSSID + "/virtualMachines/1; reboot",
+	}
+	for _, providerID := range invalid {
+		if _, err := parseNodeProviderID(providerID); err == nil {
+			t.Errorf("Expected error for providerID %q", providerID)
+		}
+	}
+}
+
+func TestFindVMSSInstanceForNode(t *testing.T) {
+	instances := []interface{}{
+		&armcompute.VirtualMachineScaleSetVM{
+			ID:         to.Ptr(testVMSSID + "/virtualMachines/3"),
+			InstanceID: to.Ptr("3"),
+			Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+				OSProfile: &armcompute.OSProfile{ComputerName: to.Ptr("aks-nodepool1-12345678-vmss000003")},
+			},
+		},
+		&armcompute.VirtualMachineScaleSetVM{
+			ID:         to.Ptr(testVMSSID + "/virtualMachines/10"),
+			InstanceID: to.Ptr("10"),
+		},
+	}
+
+	if vm := findVMSSInstanceForNode(instances, "aks-nodepool1-12345678-vmss000003", "000003"); vm == nil || *vm.InstanceID != "3" {
+		t.Errorf("Expected instance 3 matched by computer name, got %v", vm)
+	}
+	// Instances without an OS profile are matched by the base 36 suffix
+	if vm := findVMSSInstanceForNode(instances, "aks-nodepool1-12345678-vmss00000a", "00000a"); vm == nil || *vm.InstanceID != "10" {
+		t.Errorf("Expected instance 10 matched by suffix, got %v", vm)
+	}
+	if vm := findVMSSInstanceForNode(instances, "aks-nodepool1-12345678-vmss00000z", "00000z"); vm != nil {
+		t.Errorf("Expected no instance, got %v", vm)
+	}
+}
+
+func TestResolveNodeInstanceProviderID(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{NodeResourceGroup: to.Ptr("mc_rg_aks_eastus")},
+	}
+
+	// Provider IDs are parsed without Azure calls
+	instance, err := ResolveNodeInstance(context.Background(), cluster, "azure://"+testVMSSID+"/virtualMachines/2", nil)
+	if err != nil || instance.InstanceID != "2" || instance.VMSSName != "aks-nodepool1-12345678-vmss" {
+		t.Errorf("Unexpected resolution: %+v, %v", instance, err)
+	}
+
+	other := "azure:///subscriptions/sub/resourceGroups/other/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss/virtualMachines/2"
+	if _, err := ResolveNodeInstance(context.Background(), cluster, other, nil); err == nil || !strings.Contains(err.Error(), "node resource group") {
+		t.Errorf("Expected node resource group error, got %v", err)
+	}
+}
+
+func TestResolveNodeTargetParams(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{NodeResourceGroup: to.Ptr("MC_rg_aks_eastus")},
+	}
+
+	tests := []struct {
+		name       string
+		params     map[string]any
+		vmssName   string
+		instanceID string
+		wantErr    string
+	}{
+		{"vmss and instance", map[string]any{"vmss_name": "aks-nodepool1-12345678-vmss", "instance_id": "0"}, "aks-nodepool1-12345678-vmss", "0", ""},
+		{"provider ID", map[string]any{"node_name": "azure://" + testVMSSID + "/virtualMachines/7"}, "aks-nodepool1-12345678-vmss", "7", ""},
+		{"both", map[string]any{"node_name": "aks-nodepool1-12345678-vmss000000", "vmss_name": "aks-nodepool1-12345678-vmss"}, "", "", "not both"},
+		{"missing", map[string]any{}, "", "", "node_name or vmss_name is required"},
+		{"missing instance", map[string]any{"vmss_name": "aks-nodepool1-12345678-vmss"}, "", "", "instance_id is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vmssName, instanceID, err := resolveNodeTargetParams(context.Background(), nil, cluster, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || vmssName != tt.vmssName || instanceID != tt.instanceID {
+				t.Errorf("resolveNodeTargetParams() = %q, %q, %v, want %q, %q", vmssName, instanceID, err, tt.vmssName, tt.instanceID)
+			}
+		})
+	}
+}
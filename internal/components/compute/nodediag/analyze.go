@@ -0,0 +1,201 @@
+package nodediag
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+// Thresholds of the findings. The disk and memory thresholds sit around the default kubelet
+// eviction thresholds (nodefs.available<10%, memory.available<750Mi on AKS).
+const (
+	DiskWarningPercent          = 80
+	DiskErrorPercent            = 90
+	MemoryAvailableWarningPct   = 20
+	MemoryAvailableErrorPct     = 10
+	MemoryPressureWarning       = 10.0
+	ConntrackWarningPercent     = 75
+	ConntrackErrorPercent       = 90
+	IPTablesRulesWarning        = 20000
+	ClockOffsetWarningSeconds   = 1.0
+	ServiceRestartsWarningCount = 5
+)
+
+// Metric is a value reported by the diagnostics script, optionally about a subject such as a
+// mount point, a service or a resolv.conf file
+type Metric struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject,omitempty"`
+	Value   string `json:"value"`
+}
+
+// Section is the output of one check group
+type Section struct {
+	Check   string   `json:"check"`
+	Metrics []Metric `json:"metrics"`
+	// Unparsed holds the lines that are not metrics, e.g. errors of the commands
+	Unparsed []string `json:"unparsed,omitempty"`
+}
+
+// ParseOutput splits the stdout of the diagnostics script into sections
+func ParseOutput(stdout string) []Section {
+	var sections []Section
+	var current *Section
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if check, ok := strings.CutPrefix(line, sectionMarker); ok {
+			sections = append(sections, Section{Check: strings.TrimSpace(check), Metrics: []Metric{}})
+			current = &sections[len(sections)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			current.Unparsed = append(current.Unparsed, line)
+			continue
+		}
+		metric := Metric{Name: name, Value: value}
+		if n, subject, ok := strings.Cut(name, ":"); ok {
+			metric.Name, metric.Subject = n, subject
+		}
+		current.Metrics = append(current.Metrics, metric)
+	}
+	return sections
+}
+
+// Analyze evaluates the metrics of the sections against the thresholds. Finding codes are
+// specific to a check, e.g. conntrack_table_full for the conntrack check.
+func Analyze(sections []Section) common.Findings {
+	findings := common.Findings{}
+	add := func(severity, code, format string, args ...any) {
+		findings.Add(severity, code, fmt.Sprintf(format, args...))
+	}
+
+	for _, section := range sections {
+		switch section.Check {
+		case CheckDisk:
+			for _, metric := range section.Metrics {
+				used, ok := intValue(metric.Value)
+				if !ok {
+					continue
+				}
+				kind, code := "disk", "disk_usage_high"
+				if metric.Name == "inode_used_pct" {
+					kind, code = "inode", "inode_usage_high"
+				}
+				if used >= DiskErrorPercent {
+					add(common.SeverityError, code, "%s usage of %s is %d%%, kubelet evicts pods below 10%% free", kind, metric.Subject, used)
+				} else if used >= DiskWarningPercent {
+					add(common.SeverityWarning, code, "%s usage of %s is %d%%", kind, metric.Subject, used)
+				}
+			}
+
+		case CheckMemory:
+			if available, ok := intValue(metricValue(section, "mem_available_pct", "")); ok {
+				if available < MemoryAvailableErrorPct {
+					add(common.SeverityError, "memory_available_low", "only %d%% of memory is available, the node is close to memory pressure eviction", available)
+				} else if available < MemoryAvailableWarningPct {
+					add(common.SeverityWarning, "memory_available_low", "only %d%% of memory is available", available)
+				}
+			}
+			if pressure, err := strconv.ParseFloat(metricValue(section, "mem_pressure_avg10", ""), 64); err == nil && pressure >= MemoryPressureWarning {
+				add(common.SeverityWarning, "memory_pressure", "tasks were stalled on memory %.1f%% of the last 10 seconds (PSI)", pressure)
+			}
+
+		case CheckNetwork:
+			count, countOK := intValue(metricValue(section, "conntrack_count", ""))
+			limit, limitOK := intValue(metricValue(section, "conntrack_max", ""))
+			if countOK && limitOK && limit > 0 {
+				used := count * 100 / limit
+				if used >= ConntrackErrorPercent {
+					add(common.SeverityError, "conntrack_table_full", "conntrack table is %d%% full (%d/%d), new connections are dropped when it is full", used, count, limit)
+				} else if used >= ConntrackWarningPercent {
+					add(common.SeverityWarning, "conntrack_table_full", "conntrack table is %d%% full (%d/%d)", used, count, limit)
+				}
+			}
+			if rules, ok := intValue(metricValue(section, "iptables_rules", "")); ok && rules >= IPTablesRulesWarning {
+				add(common.SeverityWarning, "iptables_rules_high", "%d iptables rules slow down kube-proxy syncs and packet processing", rules)
+			}
+
+		case CheckDNS:
+			if metricValue(section, "nameserver", "/etc/resolv.conf") == "" {
+				add(common.SeverityError, "no_nameserver", "/etc/resolv.conf has no nameserver")
+			}
+			for _, metric := range section.Metrics {
+				if metric.Name == "dns_lookup" && metric.Value != "ok" {
+					add(common.SeverityError, "dns_lookup_failed", "resolving %s from the node failed", metric.Subject)
+				}
+			}
+
+		case CheckTime:
+			if synchronized := metricValue(section, "ntp_synchronized", ""); synchronized != "" && synchronized != "yes" {
+				add(common.SeverityWarning, "clock_not_synchronized", "the system clock is not synchronized (NTPSynchronized=%s)", synchronized)
+			}
+			if offset, err := strconv.ParseFloat(metricValue(section, "clock_offset_seconds", ""), 64); err == nil && math.Abs(offset) >= ClockOffsetWarningSeconds {
+				add(common.SeverityWarning, "clock_offset_high", "the system clock is %.3fs off NTP time, which breaks token and certificate validation", offset)
+			}
+
+		case CheckServices:
+			for _, metric := range section.Metrics {
+				switch metric.Name {
+				case "service_active":
+					if metric.Value != "active" {
+						add(common.SeverityError, "service_not_active", "%s is %s", metric.Subject, valueOrUnknown(metric.Value))
+					}
+				case "service_restarts":
+					if restarts, ok := intValue(metric.Value); ok && restarts >= ServiceRestartsWarningCount {
+						add(common.SeverityWarning, "service_restarting", "%s restarted %d times since boot", metric.Subject, restarts)
+					}
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// metricValue returns the value of the first metric with the name and subject, or an empty string
+func metricValue(section Section, name, subject string) string {
+	for _, metric := range section.Metrics {
+		if metric.Name == name && (subject == "" || metric.Subject == subject) {
+			return metric.Value
+		}
+	}
+	return ""
+}
+
+// intValue parses an integer metric value
+func intValue(value string) (int, bool) {
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	return parsed, err == nil
+}
+
+// valueOrUnknown returns the value, or "unknown" for empty values
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+// MissingSections reports the selected checks without output, e.g. when the run command output
+// was truncated or the script stopped early
+func MissingSections(checks []string, sections []Section) common.Findings {
+	findings := common.Findings{}
+	for _, check := range checks {
+		if !slices.ContainsFunc(sections, func(section Section) bool { return section.Check == check }) {
+			findings.Add(common.SeverityWarning, "check_output_missing",
+				fmt.Sprintf("no output for the %s check, the run command output may have been truncated; run it on its own with checks=%s", check, check))
+		}
+	}
+	return findings
+}
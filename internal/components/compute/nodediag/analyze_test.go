@@ -0,0 +1,134 @@
+package nodediag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+const testOutput = `### disk
+disk_used_pct:/=93
+inode_used_pct:/=12
+disk_used_pct:/var/lib/kubelet=82
+### memory
+mem_total_kb=16374252
+mem_available_kb=1310000
+mem_available_pct=8
+mem_pressure_avg10=0.00
+### network
+conntrack_count=120000
+conntrack_max=131072
+iptables_rules=2450
+nftables_rules=0
+### dns
+nameserver:/etc/resolv.conf=168.63.129.16
+dns_lookup:mcr.microsoft.com=failed
+### time
+ntp_synchronized=no
+clock_offset_seconds=-2.5
+### services
+service_active:kubelet=activating
+service_restarts:kubelet=12
+service_active:containerd=active
+service_restarts:containerd=0
+### kubelet_config
+kubelet_flag:max-pods=110
+kubelet_flag:eviction-hard=memory.available<750Mi,nodefs.available<10%
+kubelet_config_file=absent
+`
+
+func TestParseOutput(t *testing.T) {
+	sections := ParseOutput("preamble=ignored\n" + testOutput + "### dns\nawk: cannot open file\n")
+	if len(sections) != 8 {
+		t.Fatalf("Expected 8 sections, got %d", len(sections))
+	}
+	if sections[0].Check != CheckDisk || len(sections[0].Metrics) != 3 {
+		t.Errorf("Unexpected disk section: %+v", sections[0])
+	}
+	if metric := sections[0].Metrics[2]; metric.Name != "disk_used_pct" || metric.Subject != "/var/lib/kubelet" || metric.Value != "82" {
+		t.Errorf("Unexpected metric: %+v", metric)
+	}
+	if flag := sections[6].Metrics[1]; flag.Subject != "eviction-hard" || flag.Value != "memory.available<750Mi,nodefs.available<10%" {
+		t.Errorf("Expected the value to keep everything after the first '=', got %+v", flag)
+	}
+	if len(sections[7].Unparsed) != 1 || sections[7].Unparsed[0] != "awk: cannot open file" {
+		t.Errorf("Expected the command error as unparsed, got %+v", sections[7])
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	findings := Analyze(ParseOutput(testOutput))
+
+	expected := map[string]string{
+		"disk_usage_high":        common.SeverityError,
+		"memory_available_low":   common.SeverityError,
+		"conntrack_table_full":   common.SeverityError,
+		"dns_lookup_failed":      common.SeverityError,
+		"clock_not_synchronized": common.SeverityWarning,
+		"clock_offset_high":      common.SeverityWarning,
+		"service_not_active":     common.SeverityError,
+		"service_restarting":     common.SeverityWarning,
+	}
+	found := map[string]string{}
+	for _, finding := range findings {
+		if _, ok := found[finding.Code]; !ok {
+			found[finding.Code] = finding.Severity
+		}
+	}
+	for code, severity := range expected {
+		if found[code] != severity {
+			t.Errorf("Expected %s finding %s, got %q", severity, code, found[code])
+		}
+	}
+	for _, code := range []string{"inode_usage_high", "iptables_rules_high", "memory_pressure", "no_nameserver"} {
+		if _, ok := found[code]; ok {
+			t.Errorf("Unexpected finding %s", code)
+		}
+	}
+
+	// /var/lib/kubelet at 82% is a warning next to the error for /
+	warnings := 0
+	for _, finding := range findings {
+		if finding.Code == "disk_usage_high" && finding.Severity == common.SeverityWarning {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("Expected 1 disk warning, got %d", warnings)
+	}
+}
+
+func TestAnalyzeHealthyNode(t *testing.T) {
+	output := `### disk
+disk_used_pct:/=40
+### memory
+mem_available_pct=60
+### network
+conntrack_count=100
+conntrack_max=131072
+### dns
+nameserver:/etc/resolv.conf=168.63.129.16
+dns_lookup:mcr.microsoft.com=ok
+### time
+ntp_synchronized=yes
+clock_offset_seconds=0.000012
+### services
+service_active:kubelet=active
+service_restarts:kubelet=0
+`
+	if findings := Analyze(ParseOutput(output)); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", findings)
+	}
+
+	if findings := Analyze(ParseOutput("### dns\n")); len(findings) != 1 || findings[0].Code != "no_nameserver" {
+		t.Errorf("Expected no_nameserver, got %+v", findings)
+	}
+}
+
+func TestMissingSections(t *testing.T) {
+	findings := MissingSections([]string{CheckDisk, CheckTime}, ParseOutput("### disk\ndisk_used_pct:/=10\n"))
+	if len(findings) != 1 || !strings.Contains(findings[0].Message, "the time check") || findings[0].Code != "check_output_missing" {
+		t.Errorf("Expected a missing time section, got %+v", findings)
+	}
+}
@@ -0,0 +1,102 @@
+// Package nodediag builds the fixed, read-only diagnostics script run on AKS Linux nodes and
+// turns its output into structured sections and threshold-based findings.
+package nodediag
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Check groups of the diagnostics script
+const (
+	CheckDisk          = "disk"
+	CheckMemory        = "memory"
+	CheckNetwork       = "network"
+	CheckDNS           = "dns"
+	CheckTime          = "time"
+	CheckServices      = "services"
+	CheckKubeletConfig = "kubelet_config"
+)
+
+// AllChecks lists the check groups in the order they run
+var AllChecks = []string{CheckDisk, CheckMemory, CheckNetwork, CheckDNS, CheckTime, CheckServices, CheckKubeletConfig}
+
+// sectionMarker starts the output of a check group
+const sectionMarker = "### "
+
+// checkScripts are the reviewed shell snippets of each check group. They only read node state and
+// print "metric=value" or "metric:subject=value" lines, kept short because the run command
+// returns at most the last 4096 bytes of stdout.
+var checkScripts = map[string]string{
+	CheckDisk: `df -P / /var/lib/kubelet /var/lib/containerd 2>/dev/null | awk 'NR>1 && !seen[$6]++ {sub("%","",$5); print "disk_used_pct:"$6"="$5}'
+df -P -i / /var/lib/kubelet /var/lib/containerd 2>/dev/null | awk 'NR>1 && !seen[$6]++ {sub("%","",$5); print "inode_used_pct:"$6"="$5}'`,
+
+	CheckMemory: `awk '/^MemTotal:/{t=$2} /^MemAvailable:/{a=$2} END{print "mem_total_kb="t; print "mem_available_kb="a; if (t>0) print "mem_available_pct="int(a*100/t)}' /proc/meminfo
+awk '/^some/{split($2,v,"="); print "mem_pressure_avg10="v[2]}' /proc/pressure/memory 2>/dev/null`,
+
+	CheckNetwork: `echo "conntrack_count=$(cat /proc/sys/net/netfilter/nf_conntrack_count 2>/dev/null)"
+echo "conntrack_max=$(cat /proc/sys/net/netfilter/nf_conntrack_max 2>/dev/null)"
+echo "iptables_rules=$(iptables-save 2>/dev/null | grep -c '^-A')"
+echo "nftables_rules=$(nft -a list ruleset 2>/dev/null | grep -vE '^[[:space:]]*(table|chain) ' | grep -c '# handle')"`,
+
+	CheckDNS: `for f in /etc/resolv.conf /run/systemd/resolve/resolv.conf; do [ -r "$f" ] && awk -v f="$f" '/^nameserver/{print "nameserver:"f"="$2}' "$f"; done
+if getent hosts mcr.microsoft.com >/dev/null 2>&1; then echo "dns_lookup:mcr.microsoft.com=ok"; else echo "dns_lookup:mcr.microsoft.com=failed"; fi`,
+
+	CheckTime: `echo "ntp_synchronized=$(timedatectl show -p NTPSynchronized --value 2>/dev/null)"
+chronyc tracking 2>/dev/null | awk '/^System time/{s=$4; if ($6=="slow") s=-s; print "clock_offset_seconds="s}'`,
+
+	CheckServices: `for s in kubelet containerd; do
+echo "service_active:$s=$(systemctl is-active $s 2>/dev/null)"
+echo "service_restarts:$s=$(systemctl show -p NRestarts --value $s 2>/dev/null)"
+done`,
+
+	CheckKubeletConfig: `grep -oE -- '--(max-pods|eviction-hard|kube-reserved|system-reserved|image-gc-high-threshold|image-gc-low-threshold|cluster-dns|resolv-conf|cgroup-driver)=[^ ]*' /etc/default/kubelet 2>/dev/null | sed 's/^--/kubelet_flag:/'
+if [ -f /etc/default/kubeletconfig.json ]; then echo "kubelet_config_file=present"; else echo "kubelet_config_file=absent"; fi`,
+}
+
+// ParseChecks parses a comma separated list of check groups. An empty list or "all" selects every group.
+func ParseChecks(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "all") {
+		return AllChecks, nil
+	}
+
+	var checks []string
+	for _, check := range strings.Split(value, ",") {
+		check = strings.ToLower(strings.TrimSpace(check))
+		if check == "" {
+			continue
+		}
+		if !slices.Contains(AllChecks, check) {
+			return nil, fmt.Errorf("invalid check %q (must be one of: %s)", check, strings.Join(AllChecks, ", "))
+		}
+		if !slices.Contains(checks, check) {
+			checks = append(checks, check)
+		}
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("no checks selected (must be one of: %s)", strings.Join(AllChecks, ", "))
+	}
+
+	// Run in catalogue order regardless of the order given
+	slices.SortFunc(checks, func(a, b string) int {
+		return slices.Index(AllChecks, a) - slices.Index(AllChecks, b)
+	})
+	return checks, nil
+}
+
+// BuildScript returns the script running the given check groups. Only the fixed snippets of the
+// catalogue are included, no caller input reaches the script.
+func BuildScript(checks []string) (string, error) {
+	var script strings.Builder
+	script.WriteString("export LC_ALL=C\n")
+	for _, check := range checks {
+		snippet, ok := checkScripts[check]
+		if !ok {
+			return "", fmt.Errorf("unknown check: %s", check)
+		}
+		fmt.Fprintf(&script, "echo '%s%s'\n%s\n", sectionMarker, check, snippet)
+	}
+	return script.String(), nil
+}
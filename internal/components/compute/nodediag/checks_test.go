@@ -0,0 +1,65 @@
+package nodediag
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+		wantErr  bool
+	}{
+		{"empty", "", AllChecks, false},
+		{"all", "ALL", AllChecks, false},
+		{"single", "disk", []string{CheckDisk}, false},
+		{"catalogue order", "services, DNS,disk,dns", []string{CheckDisk, CheckDNS, CheckServices}, false},
+		{"unknown", "disk,cpu", nil, true},
+		{"only separators", " , ", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := ParseChecks(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChecks(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !slices.Equal(checks, tt.expected) {
+				t.Errorf("ParseChecks(%q) = %v, want %v", tt.value, checks, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildScript(t *testing.T) {
+	script, err := BuildScript([]string{CheckDisk, CheckServices})
+	if err != nil {
+		t.Fatalf("BuildScript() unexpected error: %v", err)
+	}
+	for _, expected := range []string{"export LC_ALL=C\n", "echo '### disk'\ndf -P", "echo '### services'\n"} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("Expected script to contain %q, got:\n%s", expected, script)
+		}
+	}
+	if strings.Contains(script, "### memory") {
+		t.Errorf("Expected only the selected checks, got:\n%s", script)
+	}
+
+	if _, err := BuildScript([]string{"disk; reboot"}); err == nil {
+		t.Error("BuildScript() expected error for an unknown check")
+	}
+}
+
+func TestCheckScriptsCoverAllChecks(t *testing.T) {
+	for _, check := range AllChecks {
+		if checkScripts[check] == "" {
+			t.Errorf("No script for check %s", check)
+		}
+	}
+	if len(checkScripts) != len(AllChecks) {
+		t.Errorf("Expected %d check scripts, got %d", len(AllChecks), len(checkScripts))
+	}
+}
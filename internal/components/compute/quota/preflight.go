@@ -0,0 +1,215 @@
+// Package quota checks whether a node pool scale-out or a new node pool fits the regional vCPU
+// quota of the subscription and the restrictions of the VM size, before anything is changed.
+package quota
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+// Regional quotas shared by every VM family
+const (
+	// TotalRegionalCores is the usage name of the total regional vCPU quota
+	TotalRegionalCores = "cores"
+	// LowPriorityCores is the usage name of the Spot vCPU quota, which Spot VMs use instead of the
+	// family and total regional quotas
+	LowPriorityCores = "lowPriorityCores"
+)
+
+// LowHeadroomPercent is the remaining quota, in percent of the limit, below which the headroom
+// after the change is reported as low
+const LowHeadroomPercent = 10
+
+// SKU is a VM size offered in the region, with its restrictions for the subscription
+type SKU struct {
+	Name   string
+	Family string
+	VCPUs  int
+	// Zones are the availability zones the size is offered in
+	Zones []string
+	// LocationRestriction is the reason code when the size cannot be deployed in the region
+	LocationRestriction string
+	// ZoneRestrictions maps the zones the size cannot be deployed in to the reason code
+	ZoneRestrictions map[string]string
+}
+
+// Usage is the current usage and limit of a compute quota in the region
+type Usage struct {
+	Name          string
+	LocalizedName string
+	Current       int64
+	Limit         int64
+}
+
+// Request is the scale-out or new node pool to check
+type Request struct {
+	Location     string
+	NodePool     string
+	VMSize       string
+	Spot         bool
+	CurrentNodes int
+	TargetNodes  int
+	Zones        []string
+}
+
+// QuotaCheck is the headroom of a quota after the change
+type QuotaCheck struct {
+	Name          string `json:"name"`
+	LocalizedName string `json:"localized_name,omitempty"`
+	Limit         int64  `json:"limit"`
+	Current       int64  `json:"current"`
+	Required      int64  `json:"required"`
+	// Remaining is the quota left after the change, negative when the change exceeds the quota
+	Remaining int64 `json:"remaining"`
+	// NodesHeadroom is how many more nodes of the size fit in the quota after the change
+	NodesHeadroom int64 `json:"nodes_headroom"`
+	Sufficient    bool  `json:"sufficient"`
+}
+
+// ZoneCheck is the availability of the VM size in a zone
+type ZoneCheck struct {
+	Zone        string `json:"zone"`
+	Available   bool   `json:"available"`
+	Restriction string `json:"restriction,omitempty"`
+}
+
+// Result is the preflight report of the change
+type Result struct {
+	Location        string          `json:"location"`
+	NodePool        string          `json:"node_pool,omitempty"`
+	VMSize          string          `json:"vm_size"`
+	Family          string          `json:"family,omitempty"`
+	Spot            bool            `json:"spot,omitempty"`
+	VCPUsPerNode    int             `json:"vcpus_per_node"`
+	CurrentNodes    int             `json:"current_nodes"`
+	TargetNodes     int             `json:"target_nodes"`
+	AdditionalNodes int             `json:"additional_nodes"`
+	AdditionalVCPUs int64           `json:"additional_vcpus"`
+	CanSucceed      bool            `json:"can_succeed"`
+	Quotas          []QuotaCheck    `json:"quotas"`
+	Zones           []ZoneCheck     `json:"zones"`
+	Findings        common.Findings `json:"findings"`
+}
+
+// Preflight checks the request against the VM size, which is nil when the size is not offered in
+// the region, and the compute usages of the region
+func Preflight(request Request, sku *SKU, usages []Usage) *Result {
+	result := &Result{
+		Location:        request.Location,
+		NodePool:        request.NodePool,
+		VMSize:          request.VMSize,
+		Spot:            request.Spot,
+		CurrentNodes:    request.CurrentNodes,
+		TargetNodes:     request.TargetNodes,
+		AdditionalNodes: max(request.TargetNodes-request.CurrentNodes, 0),
+		Quotas:          []QuotaCheck{},
+		Zones:           []ZoneCheck{},
+		Findings:        common.Findings{},
+	}
+
+	if sku == nil {
+		result.Findings.Add(common.SeverityError, "sku_not_available",
+			fmt.Sprintf("VM size %s is not offered in %s; choose another size or region", request.VMSize, request.Location))
+		return result
+	}
+	result.Family = sku.Family
+	result.VCPUsPerNode = sku.VCPUs
+	result.AdditionalVCPUs = int64(result.AdditionalNodes) * int64(sku.VCPUs)
+
+	if sku.LocationRestriction != "" {
+		result.Findings.Add(common.SeverityError, "sku_restricted",
+			fmt.Sprintf("VM size %s is restricted in %s for this subscription (%s); request access or choose another size", sku.Name, request.Location, sku.LocationRestriction))
+	}
+	result.checkZones(request, sku)
+
+	if result.AdditionalNodes == 0 {
+		result.Findings.Add(common.SeverityInfo, "no_additional_nodes",
+			fmt.Sprintf("the target of %d nodes does not add nodes to the current %d, no quota is needed", request.TargetNodes, request.CurrentNodes))
+	}
+	result.checkQuotas(request, sku, usages)
+
+	result.CanSucceed = !slices.ContainsFunc(result.Findings, func(finding common.Finding) bool { return finding.Severity == common.SeverityError })
+	return result
+}
+
+// checkZones reports the availability of the size in the requested zones, or in every zone it is
+// offered in when no zone is requested
+func (r *Result) checkZones(request Request, sku *SKU) {
+	zones := request.Zones
+	if len(zones) == 0 {
+		zones = sku.Zones
+	}
+	for _, zone := range zones {
+		check := ZoneCheck{Zone: zone, Available: true}
+		if reason, ok := sku.ZoneRestrictions[zone]; ok {
+			check.Available, check.Restriction = false, reason
+		} else if !slices.Contains(sku.Zones, zone) {
+			check.Available, check.Restriction = false, "NotOffered"
+		}
+		r.Zones = append(r.Zones, check)
+
+		// Only the zones of the pool matter, the others are informational
+		if !check.Available && len(request.Zones) > 0 {
+			r.Findings.Add(common.SeverityError, "zone_unavailable",
+				fmt.Sprintf("VM size %s cannot be deployed in zone %s of %s (%s); remove the zone from the node pool or choose another size", sku.Name, zone, request.Location, check.Restriction))
+		}
+	}
+}
+
+// checkQuotas checks the family and total regional vCPU quotas, or the Spot quota for Spot pools
+func (r *Result) checkQuotas(request Request, sku *SKU, usages []Usage) {
+	names := []string{sku.Family, TotalRegionalCores}
+	if request.Spot {
+		names = []string{LowPriorityCores}
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		index := slices.IndexFunc(usages, func(usage Usage) bool { return strings.EqualFold(usage.Name, name) })
+		if index < 0 {
+			r.Findings.Add(common.SeverityWarning, "quota_unknown",
+				fmt.Sprintf("no usage reported for quota %s in %s, the vCPU headroom of the change is unknown", name, request.Location))
+			continue
+		}
+		usage := usages[index]
+
+		check := QuotaCheck{
+			Name:          usage.Name,
+			LocalizedName: usage.LocalizedName,
+			Limit:         usage.Limit,
+			Current:       usage.Current,
+			Required:      r.AdditionalVCPUs,
+			Remaining:     usage.Limit - usage.Current - r.AdditionalVCPUs,
+		}
+		check.Sufficient = check.Remaining >= 0
+		if check.Sufficient && sku.VCPUs > 0 {
+			check.NodesHeadroom = check.Remaining / int64(sku.VCPUs)
+		}
+		r.Quotas = append(r.Quotas, check)
+
+		label := quotaLabel(usage)
+		switch {
+		case !check.Sufficient:
+			r.Findings.Add(common.SeverityError, "quota_exceeded",
+				fmt.Sprintf("%s quota is %d vCPUs with %d in use, the change needs %d more (%d short); request a quota increase for %s in %s",
+					label, usage.Limit, usage.Current, check.Required, -check.Remaining, usage.Name, request.Location))
+		case usage.Limit > 0 && check.Remaining*100 < usage.Limit*LowHeadroomPercent:
+			r.Findings.Add(common.SeverityWarning, "quota_headroom_low",
+				fmt.Sprintf("%s quota has %d vCPUs left after the change (%d nodes of %s), upgrades with max surge and further scale-outs may fail",
+					label, check.Remaining, check.NodesHeadroom, sku.Name))
+		}
+	}
+}
+
+// quotaLabel returns the display name of a quota
+func quotaLabel(usage Usage) string {
+	if usage.LocalizedName != "" {
+		return usage.LocalizedName
+	}
+	return usage.Name
+}
@@ -0,0 +1,113 @@
+package quota
+
+import (
+	"slices"
+	"testing"
+)
+
+var testSKU = &SKU{
+	Name:             "Standard_D4s_v3",
+	Family:           "standardDSv3Family",
+	VCPUs:            4,
+	Zones:            []string{"1", "2", "3"},
+	ZoneRestrictions: map[string]string{"3": "NotAvailableForSubscription"},
+}
+
+var testUsages = []Usage{
+	{Name: "cores", LocalizedName: "Total Regional vCPUs", Current: 40, Limit: 100},
+	{Name: "standardDSv3Family", LocalizedName: "Standard DSv3 Family vCPUs", Current: 32, Limit: 64},
+	{Name: "lowPriorityCores", LocalizedName: "Total Regional Low-priority vCPUs", Current: 2, Limit: 10},
+}
+
+func TestPreflightFits(t *testing.T) {
+	result := Preflight(Request{
+		Location:     "westeurope",
+		NodePool:     "nodepool1",
+		VMSize:       "Standard_D4s_v3",
+		CurrentNodes: 3,
+		TargetNodes:  5,
+		Zones:        []string{"1", "2"},
+	}, testSKU, testUsages)
+
+	if !result.CanSucceed || len(result.Findings) != 0 {
+		t.Fatalf("Expected the scale-out to fit, got %+v", result.Findings)
+	}
+	if result.AdditionalNodes != 2 || result.AdditionalVCPUs != 8 {
+		t.Errorf("Unexpected additional capacity: %d nodes, %d vCPUs", result.AdditionalNodes, result.AdditionalVCPUs)
+	}
+	if len(result.Quotas) != 2 {
+		t.Fatalf("Expected the family and regional quotas, got %+v", result.Quotas)
+	}
+	family := result.Quotas[0]
+	if family.Name != "standardDSv3Family" || family.Remaining != 24 || family.NodesHeadroom != 6 || !family.Sufficient {
+		t.Errorf("Unexpected family quota check: %+v", family)
+	}
+}
+
+func TestPreflightQuotaExceeded(t *testing.T) {
+	result := Preflight(Request{
+		Location:     "westeurope",
+		VMSize:       "Standard_D4s_v3",
+		CurrentNodes: 0,
+		TargetNodes:  10,
+	}, testSKU, testUsages)
+
+	if result.CanSucceed {
+		t.Error("Expected 40 more vCPUs to exceed the family quota")
+	}
+	if codes := result.Findings.Codes(); !slices.Contains(codes, "quota_exceeded") {
+		t.Errorf("Expected a quota_exceeded finding, got %v", codes)
+	}
+	if regional := result.Quotas[1]; !regional.Sufficient || regional.Remaining != 20 {
+		t.Errorf("Unexpected regional quota check: %+v", regional)
+	}
+	// Without requested zones every zone of the size is reported, but none fails the change
+	if len(result.Zones) != 3 || result.Zones[2].Available {
+		t.Errorf("Unexpected zones: %+v", result.Zones)
+	}
+}
+
+func TestPreflightRestrictions(t *testing.T) {
+	sku := *testSKU
+	sku.LocationRestriction = "NotAvailableForSubscription"
+	result := Preflight(Request{
+		Location:    "westeurope",
+		VMSize:      "Standard_D4s_v3",
+		TargetNodes: 1,
+		Zones:       []string{"1", "3"},
+	}, &sku, testUsages)
+
+	codes := result.Findings.Codes()
+	for _, code := range []string{"sku_restricted", "zone_unavailable"} {
+		if !slices.Contains(codes, code) {
+			t.Errorf("Expected finding %s, got %v", code, codes)
+		}
+	}
+	if result.CanSucceed {
+		t.Error("Expected restrictions to fail the change")
+	}
+}
+
+func TestPreflightSpotAndLowHeadroom(t *testing.T) {
+	result := Preflight(Request{
+		Location:    "westeurope",
+		VMSize:      "Standard_D4s_v3",
+		Spot:        true,
+		TargetNodes: 2,
+	}, testSKU, testUsages)
+
+	if len(result.Quotas) != 1 || result.Quotas[0].Name != LowPriorityCores {
+		t.Fatalf("Expected only the Spot quota for Spot pools, got %+v", result.Quotas)
+	}
+	if !result.CanSucceed || !slices.Contains(result.Findings.Codes(), "quota_headroom_low") {
+		t.Errorf("Expected a low headroom warning, got %+v", result.Findings)
+	}
+}
+
+func TestPreflightSKUNotAvailable(t *testing.T) {
+	result := Preflight(Request{Location: "westeurope", VMSize: "Standard_ND96amsr_A100_v4", TargetNodes: 1}, nil, testUsages)
+
+	if result.CanSucceed || !slices.Equal(result.Findings.Codes(), []string{"sku_not_available"}) {
+		t.Errorf("Expected only a sku_not_available finding, got %+v", result)
+	}
+}
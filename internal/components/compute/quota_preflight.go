@@ -0,0 +1,240 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute/quota"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// DefaultNewNodePoolCount is the node count of a new node pool when node_count is not set, as
+// for az aks nodepool add
+const DefaultNewNodePoolCount = 3
+
+// handleQuotaPreflight checks a node pool scale-out or a new node pool against the regional vCPU
+// quotas and the VM size restrictions, without changing anything
+func handleQuotaPreflight(ctx context.Context, client *azureclient.AzureClient, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+	if cluster.Location == nil || *cluster.Location == "" {
+		return "", fmt.Errorf("cluster location not found")
+	}
+
+	request, err := quotaRequest(ctx, client, cluster, params)
+	if err != nil {
+		return "", err
+	}
+
+	clients, err := client.GetOrCreateClientsForSubscription(subID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subID, err)
+	}
+
+	sku, err := findVMSizeSKU(ctx, clients.ResourceSKUsClient, request.Location, request.VMSize)
+	if err != nil {
+		return "", err
+	}
+	usages, err := listComputeUsages(ctx, clients.UsageClient, request.Location)
+	if err != nil {
+		return "", err
+	}
+
+	result := quota.Preflight(request, sku, usages)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal quota preflight to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// quotaRequest builds the change to check: a scale-out of node_pool to target_count, or a new
+// pool of node_count vm_size nodes
+func quotaRequest(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, params map[string]interface{}) (quota.Request, error) {
+	request := quota.Request{Location: normalizeLocation(*cluster.Location)}
+	nodePool, _ := params["node_pool"].(string)
+	vmSize, _ := params["vm_size"].(string)
+
+	if nodePool == "" {
+		if vmSize == "" {
+			return request, fmt.Errorf("either node_pool (scale an existing node pool) or vm_size (add a node pool) is required")
+		}
+		request.VMSize = vmSize
+		request.TargetNodes = DefaultNewNodePoolCount
+		if count, ok := params["node_count"].(float64); ok {
+			if count < 1 {
+				return request, fmt.Errorf("invalid node_count: %v (must be at least 1)", count)
+			}
+			request.TargetNodes = int(count)
+		}
+		request.Spot, _ = params["spot"].(bool)
+		request.Zones = parseZones(params["zones"])
+		return request, nil
+	}
+
+	pools, err := selectNodePools(ctx, client, cluster, nodePool)
+	if err != nil {
+		return request, err
+	}
+	pool := pools[0]
+	request.NodePool = *pool.Name
+	request.VMSize = stringValue(pool.VMSize)
+	if vmSize != "" && !strings.EqualFold(vmSize, request.VMSize) {
+		return request, fmt.Errorf("node pool %s runs %s, the VM size of an existing node pool cannot be changed; omit vm_size or leave out node_pool to check a new node pool", request.NodePool, request.VMSize)
+	}
+	if pool.Count != nil {
+		request.CurrentNodes = int(*pool.Count)
+	}
+	request.Spot = pool.ScaleSetPriority != nil && *pool.ScaleSetPriority == armcontainerservice.ScaleSetPrioritySpot
+	for _, zone := range pool.AvailabilityZones {
+		if zone != nil {
+			request.Zones = append(request.Zones, *zone)
+		}
+	}
+
+	// Autoscaled pools are checked at their maximum unless a target is given
+	if count, ok := params["target_count"].(float64); ok {
+		if count < 0 {
+			return request, fmt.Errorf("invalid target_count: %v", count)
+		}
+		request.TargetNodes = int(count)
+	} else if pool.EnableAutoScaling != nil && *pool.EnableAutoScaling && pool.MaxCount != nil {
+		request.TargetNodes = int(*pool.MaxCount)
+	} else {
+		return request, fmt.Errorf("target_count is required for node pool %s, which does not use the cluster autoscaler", request.NodePool)
+	}
+	return request, nil
+}
+
+// parseZones reads zones given as a comma separated string or a list
+func parseZones(value interface{}) []string {
+	var zones []string
+	switch v := value.(type) {
+	case string:
+		for _, zone := range strings.Split(v, ",") {
+			if zone = strings.TrimSpace(zone); zone != "" {
+				zones = append(zones, zone)
+			}
+		}
+	case []interface{}:
+		for _, zone := range v {
+			if s, ok := zone.(string); ok && s != "" {
+				zones = append(zones, s)
+			}
+		}
+	}
+	return zones
+}
+
+// findVMSizeSKU returns the virtual machine SKU of the size in the location, or nil when the size
+// is not offered there
+func findVMSizeSKU(ctx context.Context, skusClient *armcompute.ResourceSKUsClient, location, vmSize string) (*quota.SKU, error) {
+	pager := skusClient.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: to.Ptr(fmt.Sprintf("location eq '%s'", location)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resource SKUs in %s: %v", location, err)
+		}
+		for _, sku := range page.Value {
+			if sku == nil || !strings.EqualFold(stringValue(sku.ResourceType), "virtualMachines") || !strings.EqualFold(stringValue(sku.Name), vmSize) {
+				continue
+			}
+			return quotaSKU(sku, location), nil
+		}
+	}
+	return nil, nil
+}
+
+// quotaSKU converts a resource SKU into the VM size, its zones and its restrictions in the location
+func quotaSKU(sku *armcompute.ResourceSKU, location string) *quota.SKU {
+	result := &quota.SKU{
+		Name:             stringValue(sku.Name),
+		Family:           stringValue(sku.Family),
+		ZoneRestrictions: map[string]string{},
+	}
+	for _, capability := range sku.Capabilities {
+		if capability != nil && stringValue(capability.Name) == "vCPUs" {
+			result.VCPUs, _ = strconv.Atoi(stringValue(capability.Value))
+		}
+	}
+	for _, info := range sku.LocationInfo {
+		if info == nil || !strings.EqualFold(normalizeLocation(stringValue(info.Location)), location) {
+			continue
+		}
+		for _, zone := range info.Zones {
+			if zone != nil {
+				result.Zones = append(result.Zones, *zone)
+			}
+		}
+	}
+
+	for _, restriction := range sku.Restrictions {
+		if restriction == nil || restriction.Type == nil {
+			continue
+		}
+		reason := "Restricted"
+		if restriction.ReasonCode != nil {
+			reason = string(*restriction.ReasonCode)
+		}
+		switch *restriction.Type {
+		case armcompute.ResourceSKURestrictionsTypeLocation:
+			result.LocationRestriction = reason
+		case armcompute.ResourceSKURestrictionsTypeZone:
+			if restriction.RestrictionInfo == nil {
+				continue
+			}
+			for _, zone := range restriction.RestrictionInfo.Zones {
+				if zone != nil {
+					result.ZoneRestrictions[*zone] = reason
+				}
+			}
+		}
+	}
+	return result
+}
+
+// listComputeUsages returns the compute quotas of the subscription in the location
+func listComputeUsages(ctx context.Context, usageClient *armcompute.UsageClient, location string) ([]quota.Usage, error) {
+	var usages []quota.Usage
+	pager := usageClient.NewListPager(location, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list compute usages in %s: %v", location, err)
+		}
+		for _, usage := range page.Value {
+			if usage == nil || usage.Name == nil {
+				continue
+			}
+			converted := quota.Usage{
+				Name:          stringValue(usage.Name.Value),
+				LocalizedName: stringValue(usage.Name.LocalizedValue),
+			}
+			if usage.CurrentValue != nil {
+				converted.Current = int64(*usage.CurrentValue)
+			}
+			if usage.Limit != nil {
+				converted.Limit = *usage.Limit
+			}
+			usages = append(usages, converted)
+		}
+	}
+	return usages, nil
+}
+
+// normalizeLocation returns the name of a location, e.g. westeurope for "West Europe"
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
@@ -0,0 +1,116 @@
+package compute
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+func TestQuotaSKU(t *testing.T) {
+	sku := &armcompute.ResourceSKU{
+		Name:         to.Ptr("Standard_D4s_v3"),
+		ResourceType: to.Ptr("virtualMachines"),
+		Family:       to.Ptr("standardDSv3Family"),
+		Capabilities: []*armcompute.ResourceSKUCapabilities{
+			{Name: to.Ptr("MemoryGB"), Value: to.Ptr("16")},
+			{Name: to.Ptr("vCPUs"), Value: to.Ptr("4")},
+		},
+		LocationInfo: []*armcompute.ResourceSKULocationInfo{
+			{Location: to.Ptr("WestEurope"), Zones: []*string{to.Ptr("1"), to.Ptr("2"), to.Ptr("3")}},
+		},
+		Restrictions: []*armcompute.ResourceSKURestrictions{
+			{
+				Type:            to.Ptr(armcompute.ResourceSKURestrictionsTypeZone),
+				ReasonCode:      to.Ptr(armcompute.ResourceSKURestrictionsReasonCodeNotAvailableForSubscription),
+				RestrictionInfo: &armcompute.ResourceSKURestrictionInfo{Zones: []*string{to.Ptr("3")}},
+			},
+		},
+	}
+
+	result := quotaSKU(sku, "westeurope")
+	if result.VCPUs != 4 || result.Family != "standardDSv3Family" {
+		t.Errorf("Unexpected SKU: %+v", result)
+	}
+	if !slices.Equal(result.Zones, []string{"1", "2", "3"}) {
+		t.Errorf("Unexpected zones: %v", result.Zones)
+	}
+	if result.ZoneRestrictions["3"] != "NotAvailableForSubscription" || result.LocationRestriction != "" {
+		t.Errorf("Unexpected restrictions: %+v", result)
+	}
+}
+
+func TestQuotaRequest(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Location: to.Ptr("West Europe"),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{
+					Name:              to.Ptr("system"),
+					VMSize:            to.Ptr("Standard_D4s_v3"),
+					Count:             to.Ptr[int32](3),
+					AvailabilityZones: []*string{to.Ptr("1"), to.Ptr("2")},
+				},
+				{
+					Name:              to.Ptr("spot"),
+					VMSize:            to.Ptr("Standard_D8s_v3"),
+					Count:             to.Ptr[int32](2),
+					EnableAutoScaling: to.Ptr(true),
+					MaxCount:          to.Ptr[int32](10),
+					ScaleSetPriority:  to.Ptr(armcontainerservice.ScaleSetPrioritySpot),
+				},
+			},
+		},
+	}
+
+	request, err := quotaRequest(context.Background(), nil, cluster, map[string]interface{}{"node_pool": "system", "target_count": float64(6)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if request.Location != "westeurope" || request.VMSize != "Standard_D4s_v3" || request.CurrentNodes != 3 || request.TargetNodes != 6 || len(request.Zones) != 2 {
+		t.Errorf("Unexpected scale-out request: %+v", request)
+	}
+
+	request, err = quotaRequest(context.Background(), nil, cluster, map[string]interface{}{"node_pool": "spot"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !request.Spot || request.TargetNodes != 10 {
+		t.Errorf("Expected the autoscaler maximum of the Spot pool, got %+v", request)
+	}
+
+	request, err = quotaRequest(context.Background(), nil, cluster, map[string]interface{}{"vm_size": "Standard_NC24ads_A100_v4", "zones": "1, 2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if request.TargetNodes != DefaultNewNodePoolCount || request.CurrentNodes != 0 || !slices.Equal(request.Zones, []string{"1", "2"}) {
+		t.Errorf("Unexpected new pool request: %+v", request)
+	}
+
+	for _, tt := range []struct {
+		params      map[string]interface{}
+		expectError string
+	}{
+		{map[string]interface{}{}, "either node_pool"},
+		{map[string]interface{}{"node_pool": "system"}, "target_count is required"},
+		{map[string]interface{}{"node_pool": "system", "vm_size": "Standard_D8s_v3", "target_count": float64(4)}, "cannot be changed"},
+		{map[string]interface{}{"vm_size": "Standard_D4s_v3", "node_count": float64(0)}, "invalid node_count"},
+	} {
+		if _, err := quotaRequest(context.Background(), nil, cluster, tt.params); err == nil || !strings.Contains(err.Error(), tt.expectError) {
+			t.Errorf("Expected error containing %q for %v, got %v", tt.expectError, tt.params, err)
+		}
+	}
+}
+
+func TestParseZones(t *testing.T) {
+	if zones := parseZones([]interface{}{"1", "", "3"}); !slices.Equal(zones, []string{"1", "3"}) {
+		t.Errorf("Unexpected zones from a list: %v", zones)
+	}
+	if zones := parseZones(nil); zones != nil {
+		t.Errorf("Expected no zones, got %v", zones)
+	}
+}
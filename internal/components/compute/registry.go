@@ -1,6 +1,8 @@
 package compute
 
 import (
+	"slices"
+
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -10,7 +12,7 @@ import (
 func RegisterAKSVMSSInfoTool() mcp.Tool {
 	return mcp.NewTool(
 		"get_aks_vmss_info",
-		mcp.WithDescription("Get detailed VMSS configuration for a specific node pool or all node pools in the AKS cluster (provides low-level VMSS settings not available in az aks nodepool show). Leave node_pool_name empty to get info for all node pools."),
+		mcp.WithDescription("Get detailed VMSS configuration for a specific node pool, a specific node or all node pools in the AKS cluster (provides low-level VMSS settings not available in az aks nodepool show). Leave node_pool_name and node_name empty to get info for all node pools."),
 		mcp.WithTitleAnnotation("Get AKS VMSS Info"),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("subscription_id",
@@ -28,6 +30,14 @@ func RegisterAKSVMSSInfoTool() mcp.Tool {
 		mcp.WithString("node_pool_name",
 			mcp.Description("Name of the node pool to get VMSS information for. Leave empty to get info for all node pools."),
 		),
+		mcp.WithString("node_name",
+			mcp.Description("Kubernetes node name (e.g. aks-nodepool1-12345678-vmss00000a) or node spec.providerID; returns the VMSS and instance ID of that node"),
+		),
+		mcp.WithBoolean("include_instances",
+			mcp.Description("Return a per-instance report instead of the VMSS model: power and provisioning state, latest model applied, zone, fault and update domain, "+
+				"extension and extension handler statuses (including the AKS CSE), boot diagnostics and OS image version. "+
+				"Instances out of date with the scale set model or with failed extensions are listed per scale set"),
+		),
 	)
 }
 
@@ -36,11 +46,17 @@ func RegisterCollectAKSNodeLogsTool() mcp.Tool {
 	return mcp.NewTool(
 		"collect_aks_node_logs",
 		mcp.WithDescription(
-			"Collect kubelet, containerd, kernel (dmesg), or syslog logs from AKS node using VMSS run command. "+
+			"Collect kubelet, containerd, kernel (dmesg), syslog, kube-proxy or node-problem-detector logs from AKS node using VMSS run command. "+
+				"Node bootstrap failures can be investigated with the CSE (cluster-provision.log), cloud-init, waagent and VM extension logs, "+
+				"and the log files of a single container can be read with log_type container and namespace, pod and container. "+
+				"On Windows nodes, collects the kubelet, containerd, kube-proxy and CSE logs from C:\\k and the System, Application and Hyper-V Compute event logs with a PowerShell run command. "+
 				"Useful for debugging node-level issues. Supports filtering by time range and log level. "+
 				"IMPORTANT: Only ONE run command can execute at a time per VMSS instance - wait for completion before running another command on the same instance. "+
-				"To get vmss_name and instance_id for a specific node A, use 'kubectl get node A -o json' and parse .spec.providerID "+
-				"(format: azure:///.../virtualMachineScaleSets/{vmss_name}/virtualMachines/{instance_id}).",
+				"Identify the node with node_name (the name shown by 'kubectl get nodes', or its .spec.providerID), "+
+				"or with vmss_name and instance_id. "+
+				"To collect from many nodes at once, set node_pool or label_selector instead: the command runs concurrently on every matching node "+
+				"and the output is merged into one time-ordered stream tagged with the node name, with per-node errors. "+
+				"Run commands can take minutes; set async to true to get a job_id immediately and poll get_job_status and get_job_result.",
 		),
 		mcp.WithTitleAnnotation("Collect AKS Node Logs"),
 		mcp.WithReadOnlyHintAnnotation(false),
@@ -48,23 +64,25 @@ func RegisterCollectAKSNodeLogsTool() mcp.Tool {
 			mcp.Description("AKS cluster resource ID (/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.ContainerService/managedClusters/{name})"),
 			mcp.Required(),
 		),
+		mcp.WithString("node_name",
+			mcp.Description("Kubernetes node name (e.g. aks-nodepool1-12345678-vmss00000a) or node spec.providerID. Alternative to vmss_name and instance_id"),
+		),
 		mcp.WithString("vmss_name",
-			mcp.Description("VMSS name (can be obtained from get_aks_vmss_info or kubectl get nodes)"),
-			mcp.Required(),
+			mcp.Description("VMSS name (can be obtained from get_aks_vmss_info). Required with instance_id when node_name is not set"),
 		),
 		mcp.WithString("instance_id",
-			mcp.Description("VMSS instance ID (can be obtained from get_aks_vmss_info or kubectl get nodes)"),
-			mcp.Required(),
+			mcp.Description("VMSS instance ID (can be obtained from get_aks_vmss_info). Required with vmss_name when node_name is not set"),
 		),
 		mcp.WithString("log_type",
-			mcp.Description("Type of logs to collect: kubelet, containerd, kernel, syslog"),
+			mcp.Description("Type of logs to collect. Linux nodes: kubelet, containerd, kernel, syslog, kube-proxy, cse, cloud-init, waagent, extensions, node-problem-detector, container. "+
+				"Windows nodes: kubelet, containerd, kube-proxy, cse, eventlog-system, eventlog-application, eventlog-hyperv-compute"),
 			mcp.Required(),
 		),
 		mcp.WithNumber("lines",
 			mcp.Description("Number of most recent log lines to return (default: 500, max: 2000)"),
 		),
 		mcp.WithString("since",
-			mcp.Description("Time range for logs, e.g., '1h', '30m', '2d' (takes precedence over lines)"),
+			mcp.Description("Time range for logs, e.g., '1h', '30m', '2d' (takes precedence over lines). Only supported for journal and event log types (kubelet, containerd and syslog on Linux, node-problem-detector, Windows event logs)"),
 		),
 		mcp.WithString("level",
 			mcp.Description("Log level filter: ERROR, WARN, INFO (default: INFO shows all logs)"),
@@ -72,5 +90,193 @@ func RegisterCollectAKSNodeLogsTool() mcp.Tool {
 		mcp.WithString("filter",
 			mcp.Description("Filter logs by keyword (case-insensitive text match, not regex)"),
 		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod with log_type container (default: default)"),
+		),
+		mcp.WithString("pod",
+			mcp.Description("Pod name with log_type container"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Container name with log_type container"),
+		),
+		mcp.WithString("node_pool",
+			mcp.Description("Collect from every node of this node pool (alternative to node_name, vmss_name and instance_id)"),
+		),
+		mcp.WithString("label_selector",
+			mcp.Description("Collect from every node matching this Kubernetes label selector, e.g. 'agentpool=nodepool1,kubernetes.azure.com/mode=user'. Requires a kubeconfig context for the cluster"),
+		),
+		mcp.WithNumber("max_concurrency",
+			mcp.Description("Maximum number of nodes to collect from at the same time with node_pool or label_selector (default: 5, max: 20)"),
+		),
+		mcp.WithNumber("node_timeout_seconds",
+			mcp.Description("Timeout of the run command on each node with node_pool or label_selector (default: 300, max: 1800)"),
+		),
+		mcp.WithBoolean("only_matches",
+			mcp.Description("With node_pool or label_selector, return only a summary of the nodes that returned lines instead of the merged logs (useful with filter to find affected nodes)"),
+		),
+		mcp.WithBoolean("async",
+			mcp.Description("Run the collection in the background and return a job_id immediately; poll get_job_status and fetch the logs with get_job_result (recommended for node_pool, label_selector or large since ranges)"),
+		),
+	)
+}
+
+// RegisterCollectAKSNodeDiagnosticsTool registers the collect_aks_node_diagnostics tool
+func RegisterCollectAKSNodeDiagnosticsTool() mcp.Tool {
+	return mcp.NewTool(
+		"collect_aks_node_diagnostics",
+		mcp.WithDescription(
+			"Run a fixed, read-only diagnostics script on an AKS Linux node using VMSS run command, e.g. to debug a NotReady node. "+
+				"Checks disk and inode usage, memory availability and pressure, conntrack table fill and iptables/nftables rule counts, "+
+				"DNS nameservers and resolution, time synchronization, kubelet and containerd service status, and the kubelet configuration. "+
+				"Returns the values of each check group and threshold-based findings. "+
+				"IMPORTANT: Only ONE run command can execute at a time per VMSS instance - wait for completion before running another command on the same instance. "+
+				"Identify the node with node_name (the name shown by 'kubectl get nodes', or its .spec.providerID), or with vmss_name and instance_id. "+
+				"Set async to true to get a job_id immediately and poll get_job_status and get_job_result.",
+		),
+		mcp.WithTitleAnnotation("Collect AKS Node Diagnostics"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithString("aks_resource_id",
+			mcp.Description("AKS cluster resource ID (/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.ContainerService/managedClusters/{name})"),
+			mcp.Required(),
+		),
+		mcp.WithString("node_name",
+			mcp.Description("Kubernetes node name (e.g. aks-nodepool1-12345678-vmss00000a) or node spec.providerID. Alternative to vmss_name and instance_id"),
+		),
+		mcp.WithString("vmss_name",
+			mcp.Description("VMSS name (can be obtained from get_aks_vmss_info). Required with instance_id when node_name is not set"),
+		),
+		mcp.WithString("instance_id",
+			mcp.Description("VMSS instance ID (can be obtained from get_aks_vmss_info). Required with vmss_name when node_name is not set"),
+		),
+		mcp.WithString("checks",
+			mcp.Description("Comma separated check groups to run: disk, memory, network, dns, time, services, kubelet_config (default: all)"),
+		),
+		mcp.WithBoolean("async",
+			mcp.Description("Run the diagnostics in the background and return a job_id immediately; poll get_job_status and fetch the result with get_job_result"),
+		),
+	)
+}
+
+// RegisterGetJobStatusTool registers the get_job_status tool
+func RegisterGetJobStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_job_status",
+		mcp.WithDescription(
+			"Get the status of a background run command job started with async=true by collect_aks_node_logs or collect_aks_node_diagnostics. "+
+				"Returns running, succeeded or failed, the elapsed time and the error of failed jobs. "+
+				"Completed jobs are kept for one hour.",
+		),
+		mcp.WithTitleAnnotation("Get Job Status"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("job_id",
+			mcp.Description("The job_id returned when the job was started"),
+			mcp.Required(),
+		),
+	)
+}
+
+// RegisterGetJobResultTool registers the get_job_result tool
+func RegisterGetJobResultTool() mcp.Tool {
+	return mcp.NewTool(
+		"get_job_result",
+		mcp.WithDescription(
+			"Get the result of a completed background run command job, in the same format the tool returns without async. "+
+				"Fails while the job is running; poll get_job_status first.",
+		),
+		mcp.WithTitleAnnotation("Get Job Result"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("job_id",
+			mcp.Description("The job_id returned when the job was started"),
+			mcp.Required(),
+		),
+	)
+}
+
+// NodePoolDiagnosticsOperationType defines the type of node pool diagnostics operation
+type NodePoolDiagnosticsOperationType string
+
+const (
+	OpImageDrift     NodePoolDiagnosticsOperationType = "image_drift"
+	OpQuotaPreflight NodePoolDiagnosticsOperationType = "quota_preflight"
+	OpScaleTimeline  NodePoolDiagnosticsOperationType = "scale_timeline"
+)
+
+// RegisterAksNodePoolDiagnostics registers the node pool diagnostics tool
+func RegisterAksNodePoolDiagnostics() mcp.Tool {
+	description := `Node pool diagnostics for AKS clusters that compare the node pools and their VMSS instances instead of returning raw resources.
+
+Supported operations:
+
+1. image_drift - Report node image version and OS SKU drift across the node pools
+   Reads the node image version, OS SKU and Kubernetes version of each node pool, the cluster auto-upgrade channel
+   and the latest node image of each pool from its upgrade profile (az aks nodepool get-upgrades). Compares the
+   image reference of each VMSS instance with the node image of its pool. Flags pools behind the latest node image,
+   instances that still run an older image or another image than their pool (e.g. after an OS SKU change),
+   pools behind the control plane Kubernetes version, Linux pools on different OS SKUs and clusters without an
+   auto-upgrade channel.
+   Optional: node_pool (report a single node pool)
+
+2. quota_preflight - Check whether a node pool scale-out or a new node pool can succeed, without changing anything
+   Reads the regional vCPU quotas and current usage of the subscription (compute usage API) and the VM size from the
+   resource SKUs of the cluster region. Checks the VM family quota and the total regional vCPU quota, or the Spot
+   vCPU quota for Spot pools, against the vCPUs of the added nodes, and the subscription restrictions of the VM size
+   in the region and in each zone of the pool. Reports whether the change can succeed and the remaining vCPU and node
+   headroom of each quota.
+   Scale an existing node pool: node_pool, target_count (defaults to the autoscaler maximum count)
+   Add a node pool: vm_size, node_count (default 3), zones (e.g. "1,2,3"), spot (true for a Spot pool)
+
+3. scale_timeline - Explain why node pools grew or shrank with one ordered event timeline per node pool
+   Merges VMSS instance creations and deletions and Spot evictions from the activity log of the node resource group,
+   AKS operations on the cluster and its agent pools (scale, upgrade, node image upgrade) and the scale-up and
+   scale-down decisions of the cluster autoscaler from the control plane logs (requires the cluster-autoscaler
+   diagnostic category in a Log Analytics workspace). Each VMSS change and instance deletion is attributed to a
+   Spot eviction, the cluster autoscaler, an upgrade or another AKS operation within 10 minutes, or else to a manual
+   or unknown change. Sources that cannot be read are reported in source_errors.
+   Optional: node_pool, start_time (RFC3339, default 24 hours ago), end_time (RFC3339, default now)
+
+Examples:
+- Find node pools behind the latest node image: operation="image_drift"
+- Check the instances of a node pool after a node image upgrade: operation="image_drift", parameters="{\"node_pool\":\"nodepool1\"}"
+- Check that a node pool can scale to 50 nodes: operation="quota_preflight", parameters="{\"node_pool\":\"nodepool1\", \"target_count\":50}"
+- Check a new GPU node pool in two zones: operation="quota_preflight", parameters="{\"vm_size\":\"Standard_NC24ads_A100_v4\", \"node_count\":2, \"zones\":\"1,2\"}"
+- Find out why a Spot pool lost nodes overnight: operation="scale_timeline", parameters="{\"node_pool\":\"spot\", \"start_time\":\"2025-01-01T18:00:00Z\"}"`
+
+	return mcp.NewTool("aks_node_pool_diagnostics",
+		mcp.WithDescription(description),
+		mcp.WithTitleAnnotation("AKS Node Pool Diagnostics"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("The node pool diagnostics operation to perform: 'image_drift' (node image version and OS SKU drift), 'quota_preflight' (vCPU quota and VM size availability of a scale-out or new node pool), 'scale_timeline' (scale, eviction and autoscaler event history per node pool)"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("parameters",
+			mcp.Description("JSON string with operation parameters. image_drift: node_pool. quota_preflight: node_pool, target_count, or vm_size, node_count, zones, spot. scale_timeline: node_pool, start_time, end_time"),
+		),
 	)
 }
+
+// ValidateNodePoolDiagnosticsOperation checks if the node pool diagnostics operation is supported
+func ValidateNodePoolDiagnosticsOperation(operation string) bool {
+	return slices.Contains(GetSupportedNodePoolDiagnosticsOperations(), operation)
+}
+
+// GetSupportedNodePoolDiagnosticsOperations returns all supported node pool diagnostics operations
+func GetSupportedNodePoolDiagnosticsOperations() []string {
+	return []string{
+		string(OpImageDrift),
+		string(OpQuotaPreflight),
+		string(OpScaleTimeline),
+	}
+}
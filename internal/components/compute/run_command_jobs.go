@@ -0,0 +1,105 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/compute/jobs"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/logger"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// Kinds of run command jobs
+const (
+	JobKindNodeLogs        = "collect_aks_node_logs"
+	JobKindNodeDiagnostics = "collect_aks_node_diagnostics"
+)
+
+// RunCommandJobTimeout is the time allowed for an asynchronous run command job, including every
+// node of a node pool or label selector
+const RunCommandJobTimeout = 30 * time.Minute
+
+// runCommandJobs holds the asynchronous run command jobs of the server
+var runCommandJobs = jobs.NewManager(jobs.DefaultTTL, jobs.DefaultMaxJobs, jobs.DefaultMaxResultBytes)
+
+// AsyncJobResponse is returned instead of the result when a tool runs asynchronously
+type AsyncJobResponse struct {
+	jobs.Info
+	Message string `json:"message"`
+}
+
+// isAsyncRequest reports whether the caller asked to run the tool as a background job
+func isAsyncRequest(params map[string]any) bool {
+	async, _ := params["async"].(bool)
+	return async
+}
+
+// startRunCommandJob runs fn as a background job and returns the job ID and how to poll it
+func startRunCommandJob(ctx context.Context, kind, description string, fn func(ctx context.Context) (string, error)) (string, error) {
+	info, err := runCommandJobs.Start(ctx, kind, description, RunCommandJobTimeout, fn)
+	if err != nil {
+		return "", fmt.Errorf("failed to start %s job: %w", kind, err)
+	}
+	logger.Debugf("RunCommandJobs: started job %s (%s): %s", info.ID, kind, description)
+
+	response := AsyncJobResponse{
+		Info:    info,
+		Message: fmt.Sprintf("The run command is running in the background. Poll get_job_status with job_id %s until it completes, then call get_job_result.", info.ID),
+	}
+	responseJSON, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal job: %w", err)
+	}
+	return string(responseJSON), nil
+}
+
+// GetJobStatusHandler returns a handler for the get_job_status tool
+func GetJobStatusHandler(cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]any, _ *config.ConfigData) (string, error) {
+		jobID, ok := params["job_id"].(string)
+		if !ok || jobID == "" {
+			return "", fmt.Errorf("job_id is required")
+		}
+
+		info, ok := runCommandJobs.Get(jobID)
+		if !ok {
+			return "", fmt.Errorf("job %s not found, it may have expired (completed jobs are kept for %s)", jobID, jobs.DefaultTTL)
+		}
+
+		infoJSON, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal job status: %w", err)
+		}
+		return string(infoJSON), nil
+	})
+}
+
+// GetJobResultHandler returns a handler for the get_job_result tool
+func GetJobResultHandler(cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]any, _ *config.ConfigData) (string, error) {
+		jobID, ok := params["job_id"].(string)
+		if !ok || jobID == "" {
+			return "", fmt.Errorf("job_id is required")
+		}
+
+		info, result, ok := runCommandJobs.Result(jobID)
+		if !ok {
+			return "", fmt.Errorf("job %s not found, it may have expired (completed jobs are kept for %s)", jobID, jobs.DefaultTTL)
+		}
+
+		switch info.Status {
+		case jobs.StatusRunning:
+			return "", fmt.Errorf("job %s is still running (%.0fs elapsed), poll get_job_status until it completes", jobID, info.DurationSeconds)
+		case jobs.StatusFailed:
+			return "", fmt.Errorf("job %s failed: %s", jobID, info.Error)
+		}
+
+		if info.ResultTruncated {
+			result += fmt.Sprintf("\n\n[result truncated to %d bytes]", info.ResultBytes)
+		}
+		return result, nil
+	})
+}
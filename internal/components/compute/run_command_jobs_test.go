@@ -0,0 +1,108 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/compute/jobs"
+)
+
+// waitForJob polls get_job_status until the job is no longer running
+func waitForJob(t *testing.T, jobID string) jobs.Info {
+	t.Helper()
+	statusHandler := GetJobStatusHandler(nil)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		output, err := statusHandler.Handle(context.Background(), map[string]any{"job_id": jobID}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected get_job_status error: %v", err)
+		}
+		var info jobs.Info
+		if err := json.Unmarshal([]byte(output), &info); err != nil {
+			t.Fatalf("Failed to parse job status: %v", err)
+		}
+		if info.Status != jobs.StatusRunning {
+			return info
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not complete", jobID)
+	return jobs.Info{}
+}
+
+func TestRunCommandJobLifecycle(t *testing.T) {
+	release := make(chan struct{})
+	output, err := startRunCommandJob(context.Background(), JobKindNodeLogs, "kubelet logs from VMSS aks-nodepool1-vmss instance 0", func(ctx context.Context) (string, error) {
+		<-release
+		return "=== AKS Node Logs ===", nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var response AsyncJobResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("Failed to parse job response: %v", err)
+	}
+	if response.ID == "" || response.Status != jobs.StatusRunning || !strings.Contains(response.Message, "get_job_status") {
+		t.Fatalf("Unexpected job response: %+v", response)
+	}
+
+	resultHandler := GetJobResultHandler(nil)
+	if _, err := resultHandler.Handle(context.Background(), map[string]any{"job_id": response.ID}, nil); err == nil || !strings.Contains(err.Error(), "still running") {
+		t.Errorf("Expected a still running error, got %v", err)
+	}
+
+	close(release)
+	if info := waitForJob(t, response.ID); info.Status != jobs.StatusSucceeded || info.Kind != JobKindNodeLogs {
+		t.Errorf("Unexpected job status: %+v", info)
+	}
+
+	result, err := resultHandler.Handle(context.Background(), map[string]any{"job_id": response.ID}, nil)
+	if err != nil || result != "=== AKS Node Logs ===" {
+		t.Errorf("Expected the job result, got %q, %v", result, err)
+	}
+}
+
+func TestRunCommandJobFailure(t *testing.T) {
+	output, err := startRunCommandJob(context.Background(), JobKindNodeDiagnostics, "disk checks", func(ctx context.Context) (string, error) {
+		return "", errors.New("Conflict: run command already in progress")
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var response AsyncJobResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("Failed to parse job response: %v", err)
+	}
+
+	if info := waitForJob(t, response.ID); info.Status != jobs.StatusFailed {
+		t.Errorf("Expected the job to fail, got %+v", info)
+	}
+	_, err = GetJobResultHandler(nil).Handle(context.Background(), map[string]any{"job_id": response.ID}, nil)
+	if err == nil || !strings.Contains(err.Error(), "run command already in progress") {
+		t.Errorf("Expected the job error, got %v", err)
+	}
+}
+
+func TestJobHandlersValidation(t *testing.T) {
+	if _, err := GetJobStatusHandler(nil).Handle(context.Background(), map[string]any{}, nil); err == nil || !strings.Contains(err.Error(), "job_id is required") {
+		t.Errorf("Expected a missing job_id error, got %v", err)
+	}
+	if _, err := GetJobResultHandler(nil).Handle(context.Background(), map[string]any{"job_id": "unknown"}, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestIsAsyncRequest(t *testing.T) {
+	if isAsyncRequest(map[string]any{}) || isAsyncRequest(map[string]any{"async": "true"}) {
+		t.Error("Expected async to require a boolean true")
+	}
+	if !isAsyncRequest(map[string]any{"async": true}) {
+		t.Error("Expected async=true to be detected")
+	}
+}
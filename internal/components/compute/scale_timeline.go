@@ -0,0 +1,328 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/compute/scaletimeline"
+	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+const (
+	// DefaultScaleTimelineWindow is the time range of the timeline when start_time is not set
+	DefaultScaleTimelineWindow = 24 * time.Hour
+	// MaxScaleTimelineWindow is the activity log retention
+	MaxScaleTimelineWindow = 90 * 24 * time.Hour
+	// maxScaleTimelineActivityEvents caps the events read from each activity log query
+	maxScaleTimelineActivityEvents = 1000
+	// clusterAutoscalerCategory is the diagnostic log category of the cluster autoscaler
+	clusterAutoscalerCategory = "cluster-autoscaler"
+)
+
+// ScaleTimelineResult is the scale and eviction history of the node pools of a cluster
+type ScaleTimelineResult struct {
+	ClusterName  string                   `json:"cluster_name"`
+	StartTime    string                   `json:"start_time"`
+	EndTime      string                   `json:"end_time"`
+	Timelines    []scaletimeline.Timeline `json:"timelines"`
+	SourceErrors map[string]string        `json:"source_errors,omitempty"`
+	Notes        []string                 `json:"notes,omitempty"`
+}
+
+// handleScaleTimeline merges the activity log, AKS operations and cluster autoscaler decisions
+// into one ordered timeline per node pool. Each source is best effort.
+func handleScaleTimeline(ctx context.Context, client *azureclient.AzureClient, cfg *config.ConfigData, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	nodePool, _ := params["node_pool"].(string)
+	start, end, err := scaleTimelineWindow(params, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	// The names are embedded in the activity log filter and the KQL query
+	if err := common.ValidateClusterName(clusterName); err != nil {
+		return "", err
+	}
+	aksFilter, err := activityLogResourceGroupFilter(rg, start, end)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	pools, err := selectNodePools(ctx, client, cluster, nodePool)
+	if err != nil {
+		return "", err
+	}
+	var poolNames []string
+	for _, pool := range pools {
+		if pool != nil && pool.Name != nil {
+			poolNames = append(poolNames, strings.ToLower(*pool.Name))
+		}
+	}
+
+	clusterID := stringValue(cluster.ID)
+	if clusterID == "" {
+		clusterID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s", subID, rg, clusterName)
+	}
+
+	result := ScaleTimelineResult{
+		ClusterName:  clusterName,
+		StartTime:    start.Format(time.RFC3339),
+		EndTime:      end.Format(time.RFC3339),
+		SourceErrors: map[string]string{},
+	}
+	var events []scaletimeline.Event
+
+	// AKS operations on the cluster and its agent pools
+	aksEvents, err := client.ListActivityLogs(ctx, subID, aksFilter, scaleActivityFilter(clusterID), maxScaleTimelineActivityEvents)
+	if err != nil {
+		result.SourceErrors[scaletimeline.SourceAKSOperation] = err.Error()
+	} else {
+		events = append(events, activityLogTimelineEvents(aksEvents, scaletimeline.SourceAKSOperation)...)
+		if len(aksEvents) >= maxScaleTimelineActivityEvents {
+			result.Notes = append(result.Notes, fmt.Sprintf("AKS operations were truncated to %d events, narrow the time range", maxScaleTimelineActivityEvents))
+		}
+	}
+
+	// VMSS instance changes and Spot evictions in the node resource group
+	nodeResourceGroup := ""
+	if cluster.Properties != nil {
+		nodeResourceGroup = stringValue(cluster.Properties.NodeResourceGroup)
+	}
+	if nodeResourceGroup == "" {
+		result.SourceErrors[scaletimeline.SourceActivityLog] = "cluster has no node resource group"
+	} else {
+		nodeResourceGroupID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/", subID, nodeResourceGroup)
+		vmssEvents, err := listNodeResourceGroupActivity(ctx, client, subID, nodeResourceGroup, nodeResourceGroupID, start, end)
+		if err != nil {
+			result.SourceErrors[scaletimeline.SourceActivityLog] = err.Error()
+		} else {
+			events = append(events, activityLogTimelineEvents(vmssEvents, scaletimeline.SourceActivityLog)...)
+			if len(vmssEvents) >= maxScaleTimelineActivityEvents {
+				result.Notes = append(result.Notes, fmt.Sprintf("VMSS activity was truncated to %d events, narrow the time range", maxScaleTimelineActivityEvents))
+			}
+		}
+	}
+
+	// Scale decisions of the cluster autoscaler from the control plane logs
+	autoscalerEvents, truncated, err := clusterAutoscalerTimelineEvents(ctx, client, cfg, subID, rg, clusterName, clusterID, start, end)
+	if err != nil {
+		result.SourceErrors[scaletimeline.SourceClusterAutoscaler] = err.Error()
+	} else {
+		events = append(events, autoscalerEvents...)
+		if truncated {
+			result.Notes = append(result.Notes, fmt.Sprintf("Only the latest %d cluster autoscaler log lines were read, narrow the time range", diagnostics.MaxMaxRecords))
+		}
+	}
+
+	// A single node pool keeps its own and the cluster-wide events only
+	if nodePool != "" {
+		var filtered []scaletimeline.Event
+		for _, event := range events {
+			if event.NodePool == "" || event.NodePool == poolNames[0] {
+				filtered = append(filtered, event)
+			}
+		}
+		events = filtered
+	}
+
+	result.Timelines = scaletimeline.Build(events, poolNames)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal scale timeline to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// scaleTimelineWindow reads start_time and end_time, defaulting to the last 24 hours
+func scaleTimelineWindow(params map[string]interface{}, now time.Time) (time.Time, time.Time, error) {
+	end := now
+	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
+		parsed, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_time format, expected RFC3339 (ISO 8601): %v", err)
+		}
+		end = parsed.UTC()
+	}
+	start := end.Add(-DefaultScaleTimelineWindow)
+	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
+		parsed, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start_time format, expected RFC3339 (ISO 8601): %v", err)
+		}
+		start = parsed.UTC()
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_time must be before end_time")
+	}
+	if end.Sub(start) > MaxScaleTimelineWindow {
+		return time.Time{}, time.Time{}, fmt.Errorf("time range cannot exceed 90 days, the activity log retention")
+	}
+	return start, end, nil
+}
+
+// activityLogResourceGroupFilter builds the OData filter selecting activity log events of a
+// resource group within a time range
+func activityLogResourceGroupFilter(resourceGroup string, start, end time.Time) (string, error) {
+	if err := common.ValidateResourceGroupName(resourceGroup); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceGroupName eq '%s'",
+		start.Format(time.RFC3339), end.Format(time.RFC3339), resourceGroup), nil
+}
+
+// listNodeResourceGroupActivity lists the scale set changes in the node resource group
+func listNodeResourceGroupActivity(ctx context.Context, client *azureclient.AzureClient, subID, nodeResourceGroup, nodeResourceGroupID string, start, end time.Time) ([]*armmonitor.EventData, error) {
+	filter, err := activityLogResourceGroupFilter(nodeResourceGroup, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListActivityLogs(ctx, subID, filter, scaleActivityFilter(nodeResourceGroupID), maxScaleTimelineActivityEvents)
+}
+
+// scaleActivityFilter matches completed node pool changes of resources under the resource ID prefix
+func scaleActivityFilter(resourceIDPrefix string) func(*armmonitor.EventData) bool {
+	prefix := strings.ToLower(resourceIDPrefix)
+	return func(event *armmonitor.EventData) bool {
+		if event == nil || event.OperationName == nil || !strings.HasPrefix(strings.ToLower(stringValue(event.ResourceID)), prefix) {
+			return false
+		}
+		if _, ok := scaletimeline.ClassifyOperation(stringValue(event.OperationName.Value)); !ok {
+			return false
+		}
+		// Each operation logs Started and Accepted entries before its outcome
+		status := ""
+		if event.Status != nil {
+			status = stringValue(event.Status.Value)
+		}
+		return !strings.EqualFold(status, "Started") && !strings.EqualFold(status, "Accepted")
+	}
+}
+
+// activityLogTimelineEvents converts activity log events into timeline events
+func activityLogTimelineEvents(events []*armmonitor.EventData, source string) []scaletimeline.Event {
+	var result []scaletimeline.Event
+	for _, event := range events {
+		if event == nil || event.OperationName == nil || event.EventTimestamp == nil {
+			continue
+		}
+		kind, ok := scaletimeline.ClassifyOperation(stringValue(event.OperationName.Value))
+		if !ok {
+			continue
+		}
+
+		message := stringValue(event.OperationName.LocalizedValue)
+		if message == "" {
+			message = stringValue(event.OperationName.Value)
+		}
+		if event.SubStatus != nil && stringValue(event.SubStatus.LocalizedValue) != "" {
+			message += " (" + stringValue(event.SubStatus.LocalizedValue) + ")"
+		}
+		timelineEvent := scaletimeline.Event{
+			Time:     event.EventTimestamp.UTC(),
+			NodePool: scaletimeline.PoolFromResourceID(stringValue(event.ResourceID)),
+			Source:   source,
+			Kind:     kind,
+			Resource: stringValue(event.ResourceID),
+			Caller:   stringValue(event.Caller),
+			Message:  message,
+		}
+		if event.Status != nil {
+			timelineEvent.Status = stringValue(event.Status.Value)
+		}
+		result = append(result, timelineEvent)
+	}
+	return result
+}
+
+// clusterAutoscalerTimelineEvents queries the cluster autoscaler control plane logs with the
+// diagnostics KQL builder and returns its scale decisions, and whether the query hit its limit
+func clusterAutoscalerTimelineEvents(ctx context.Context, client *azureclient.AzureClient, cfg *config.ConfigData, subID, rg, clusterName, clusterID string, start, end time.Time) ([]scaletimeline.Event, bool, error) {
+	workspaceResourceID, isResourceSpecific, err := diagnostics.FindDiagnosticSettingForCategory(subID, rg, clusterName, clusterAutoscalerCategory, client, cfg)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to find diagnostic setting for log category %s: %v", clusterAutoscalerCategory, err)
+	}
+	workspaceGUID, err := client.GetLogAnalyticsWorkspaceCustomerID(ctx, workspaceResourceID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	query, err := clusterAutoscalerQuery(clusterID, isResourceSpecific)
+	if err != nil {
+		return nil, false, err
+	}
+	timespan, err := diagnostics.CalculateTimespan(start.Format(time.RFC3339), end.Format(time.RFC3339))
+	if err != nil {
+		return nil, false, err
+	}
+
+	logs, err := client.QueryLogAnalyticsWorkspace(ctx, workspaceGUID, query, timespan)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query cluster autoscaler logs: %v", err)
+	}
+	records := azureclient.LogsRecords(logs)
+	return autoscalerTimelineEvents(records), len(records) >= diagnostics.MaxMaxRecords, nil
+}
+
+// clusterAutoscalerQuery builds the KQL query of the latest cluster autoscaler scale decisions.
+// Lines are filtered by the decision terms before the record limit, so that the main loop noise of
+// a busy autoscaler doesn't push the decisions out of the result.
+func clusterAutoscalerQuery(clusterID string, isResourceSpecific bool) (string, error) {
+	tableMode := diagnostics.AzureDiagnosticsMode
+	if isResourceSpecific {
+		tableMode = diagnostics.ResourceSpecificMode
+	}
+	builder, err := diagnostics.NewKQLQueryBuilder(clusterAutoscalerCategory, "", diagnostics.MaxMaxRecords, clusterID, tableMode)
+	if err != nil {
+		return "", err
+	}
+	if err := builder.WithMessageTerms(scaletimeline.AutoscalerMessageTerms...); err != nil {
+		return "", err
+	}
+	return builder.Build()
+}
+
+// autoscalerTimelineEvents converts cluster autoscaler log records into timeline events, one per
+// node pool named by a scale decision. Records come from AzureDiagnostics (log_s) or the
+// resource-specific AKSControlPlane table (Message).
+func autoscalerTimelineEvents(records []map[string]interface{}) []scaletimeline.Event {
+	var result []scaletimeline.Event
+	for _, record := range records {
+		message, _ := record["Message"].(string)
+		if message == "" {
+			message, _ = record["log_s"].(string)
+		}
+		timeGenerated, _ := record["TimeGenerated"].(string)
+		eventTime, err := time.Parse(time.RFC3339Nano, timeGenerated)
+		if err != nil {
+			continue
+		}
+
+		kind, pools, ok := scaletimeline.ParseAutoscalerMessage(message)
+		if !ok {
+			continue
+		}
+		for _, pool := range pools {
+			result = append(result, scaletimeline.Event{
+				Time:     eventTime.UTC(),
+				NodePool: pool,
+				Source:   scaletimeline.SourceClusterAutoscaler,
+				Kind:     kind,
+				Message:  strings.TrimSpace(message),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package compute
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/compute/scaletimeline"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+func TestScaleTimelineWindow(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	start, end, err := scaleTimelineWindow(map[string]interface{}{}, now)
+	if err != nil || !end.Equal(now) || end.Sub(start) != DefaultScaleTimelineWindow {
+		t.Errorf("Unexpected default window: %v - %v, %v", start, end, err)
+	}
+
+	start, _, err = scaleTimelineWindow(map[string]interface{}{"start_time": "2026-10-18T02:00:00+02:00"}, now)
+	if err != nil || !start.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start time: %v, %v", start, err)
+	}
+
+	for _, tt := range []struct {
+		params      map[string]interface{}
+		expectError string
+	}{
+		{map[string]interface{}{"start_time": "yesterday"}, "invalid start_time"},
+		{map[string]interface{}{"end_time": "2026-10-18"}, "invalid end_time"},
+		{map[string]interface{}{"start_time": "2026-10-18T13:00:00Z"}, "must be before"},
+		{map[string]interface{}{"start_time": "2026-01-01T00:00:00Z"}, "90 days"},
+	} {
+		if _, _, err := scaleTimelineWindow(tt.params, now); err == nil || !strings.Contains(err.Error(), tt.expectError) {
+			t.Errorf("Expected error containing %q for %v, got %v", tt.expectError, tt.params, err)
+		}
+	}
+}
+
+func activityEvent(operation, status, resourceID string, timestamp time.Time) *armmonitor.EventData {
+	return &armmonitor.EventData{
+		OperationName:  &armmonitor.LocalizableString{Value: to.Ptr(operation), LocalizedValue: to.Ptr(operation)},
+		Status:         &armmonitor.LocalizableString{Value: to.Ptr(status)},
+		ResourceID:     to.Ptr(resourceID),
+		EventTimestamp: to.Ptr(timestamp),
+		Caller:         to.Ptr("caller"),
+	}
+}
+
+func TestScaleActivityFilter(t *testing.T) {
+	prefix := "/subscriptions/s/resourceGroups/MC_rg/providers/Microsoft.Compute/virtualMachineScaleSets/"
+	vmss := "/subscriptions/s/resourceGroups/mc_rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-user-12345678-vmss"
+	include := scaleActivityFilter(prefix)
+	now := time.Now()
+
+	tests := []struct {
+		event    *armmonitor.EventData
+		expected bool
+	}{
+		{activityEvent("Microsoft.Compute/virtualMachineScaleSets/write", "Succeeded", vmss, now), true},
+		{activityEvent("Microsoft.Compute/virtualMachineScaleSets/write", "Started", vmss, now), false},
+		{activityEvent("Microsoft.Compute/virtualMachineScaleSets/read", "Succeeded", vmss, now), false},
+		{activityEvent("Microsoft.Compute/disks/write", "Succeeded", "/subscriptions/s/resourceGroups/MC_rg/providers/Microsoft.Compute/disks/d", now), false},
+		{nil, false},
+	}
+	for i, tt := range tests {
+		if include(tt.event) != tt.expected {
+			t.Errorf("Test %d: expected include %v", i, tt.expected)
+		}
+	}
+}
+
+func TestActivityLogTimelineEvents(t *testing.T) {
+	timestamp := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	eviction := activityEvent("Microsoft.Compute/virtualMachineScaleSets/virtualMachines/evictSpotVM/action", "Succeeded",
+		"/subscriptions/s/resourceGroups/MC_rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-spot-12345678-vmss/virtualMachines/4", timestamp)
+	eviction.SubStatus = &armmonitor.LocalizableString{LocalizedValue: to.Ptr("OK")}
+	upgrade := activityEvent("Microsoft.ContainerService/managedClusters/agentPools/upgradeNodeImageVersion/action", "Succeeded",
+		"/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c/agentPools/user", timestamp)
+	noTime := activityEvent("Microsoft.Compute/virtualMachineScaleSets/write", "Succeeded", "/x", timestamp)
+	noTime.EventTimestamp = nil
+
+	events := activityLogTimelineEvents([]*armmonitor.EventData{eviction, upgrade, noTime}, scaletimeline.SourceActivityLog)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", events)
+	}
+	if events[0].Kind != scaletimeline.KindSpotEviction || events[0].NodePool != "spot" || !strings.HasSuffix(events[0].Message, "(OK)") {
+		t.Errorf("Unexpected eviction event: %+v", events[0])
+	}
+	if events[1].Kind != scaletimeline.KindNodeImageUpgrade || events[1].NodePool != "user" || events[1].Caller != "caller" {
+		t.Errorf("Unexpected upgrade event: %+v", events[1])
+	}
+}
+
+func TestAutoscalerTimelineEvents(t *testing.T) {
+	records := []map[string]interface{}{
+		{"TimeGenerated": "2026-10-18T10:00:01.123Z", "Message": "I1018 10:00:01 1 scale_up.go:477] Final scale-up plan: [{aks-user-12345678-vmss 3->5 (max: 10)}]"},
+		{"TimeGenerated": "2026-10-18T11:00:00Z", "log_s": "I1018 11:00:00 1 scale_down.go:1102] Scale-down: removing empty node aks-spot-12345678-vmss000003"},
+		{"TimeGenerated": "2026-10-18T11:00:00Z", "Message": "I1018 11:00:00 1 static_autoscaler.go:230] Starting main loop"},
+		{"TimeGenerated": "not a time", "Message": "Final scale-up plan: [{aks-user-12345678-vmss 5->6 (max: 10)}]"},
+	}
+
+	events := autoscalerTimelineEvents(records)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", events)
+	}
+	if events[0].NodePool != "user" || events[0].Kind != scaletimeline.KindAutoscalerScaleUp || events[0].Source != scaletimeline.SourceClusterAutoscaler {
+		t.Errorf("Unexpected scale-up event: %+v", events[0])
+	}
+	if events[1].NodePool != "spot" || events[1].Kind != scaletimeline.KindAutoscalerScaleDn {
+		t.Errorf("Unexpected scale-down event: %+v", events[1])
+	}
+}
+
+func TestClusterAutoscalerQuery(t *testing.T) {
+	clusterID := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks"
+
+	query, err := clusterAutoscalerQuery(clusterID, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The decision terms are filtered before the limit, not after
+	if expected := "| where Message has_any ('scale-up', 'scale-down', 'removing', 'removed', 'deleted') | order by TimeGenerated desc | limit 1000"; !strings.Contains(query, expected) {
+		t.Errorf("Expected query to contain %q, got %q", expected, query)
+	}
+
+	query, err = clusterAutoscalerQuery(clusterID, false)
+	if err != nil || !strings.Contains(query, "| where log_s has_any ('scale-up',") {
+		t.Errorf("Expected an AzureDiagnostics query filtered on log_s, got %q, %v", query, err)
+	}
+}
+
+func TestActivityLogResourceGroupFilter(t *testing.T) {
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	filter, err := activityLogResourceGroupFilter("MC_rg_aks_westeurope", start, start.Add(time.Hour))
+	if err != nil || filter != "eventTimestamp ge '2026-10-18T00:00:00Z' and eventTimestamp le '2026-10-18T01:00:00Z' and resourceGroupName eq 'MC_rg_aks_westeurope'" {
+		t.Errorf("Unexpected filter %q, %v", filter, err)
+	}
+
+	if _, err := activityLogResourceGroupFilter("rg' or resourceGroupName ne '", start, start.Add(time.Hour)); err == nil {
+		t.Error("Expected an error for a resource group name injecting into the filter")
+	}
+}
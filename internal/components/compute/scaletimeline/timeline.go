@@ -0,0 +1,231 @@
+// Package scaletimeline merges VMSS activity, Spot evictions, AKS operations and cluster
+// autoscaler decisions into one ordered timeline per node pool, and attributes node removals to
+// their most likely cause.
+package scaletimeline
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sources of events
+const (
+	SourceActivityLog       = "activity_log"
+	SourceAKSOperation      = "aks_operation"
+	SourceClusterAutoscaler = "cluster_autoscaler"
+)
+
+// Kinds of events
+const (
+	KindVMSSUpdated        = "vmss_updated"
+	KindInstanceDeleted    = "instance_deleted"
+	KindInstanceReimaged   = "instance_reimaged"
+	KindSpotEviction       = "spot_eviction"
+	KindNodePoolOperation  = "node_pool_operation"
+	KindNodePoolDeleted    = "node_pool_deleted"
+	KindNodeImageUpgrade   = "node_image_upgrade"
+	KindClusterOperation   = "cluster_operation"
+	KindAutoscalerScaleUp  = "autoscaler_scale_up"
+	KindAutoscalerScaleDn  = "autoscaler_scale_down"
+	KindAutoscalerNodeGone = "autoscaler_node_removed"
+)
+
+// Causes attributed to node pool size changes
+const (
+	CauseClusterAutoscaler = "cluster_autoscaler"
+	CauseSpotEviction      = "spot_eviction"
+	CauseUpgrade           = "upgrade"
+	CauseAKSOperation      = "aks_operation"
+	CauseManual            = "manual_or_unknown"
+)
+
+// AttributionWindow is how close a cause must be to a size change to explain it
+const AttributionWindow = 10 * time.Minute
+
+// Event is an entry of a node pool timeline. Events without a node pool apply to the whole cluster.
+type Event struct {
+	Time     time.Time `json:"time"`
+	NodePool string    `json:"node_pool,omitempty"`
+	Source   string    `json:"source"`
+	Kind     string    `json:"kind"`
+	Resource string    `json:"resource,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Caller   string    `json:"caller,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	// Cause is the attributed cause of VMSS size changes and instance deletions
+	Cause string `json:"cause,omitempty"`
+}
+
+// Timeline is the ordered history of a node pool
+type Timeline struct {
+	NodePool string         `json:"node_pool"`
+	Summary  map[string]int `json:"summary"`
+	Events   []Event        `json:"events"`
+}
+
+// activityOperations maps activity log operation names to event kinds, most specific first
+var activityOperations = []struct {
+	operation string
+	kind      string
+}{
+	{"microsoft.compute/virtualmachinescalesets/virtualmachines/evictspotvm", KindSpotEviction},
+	{"microsoft.compute/virtualmachinescalesets/virtualmachines/delete", KindInstanceDeleted},
+	{"microsoft.compute/virtualmachinescalesets/virtualmachines/reimage", KindInstanceReimaged},
+	{"microsoft.compute/virtualmachinescalesets/delete/action", KindInstanceDeleted},
+	{"microsoft.compute/virtualmachinescalesets/reimage", KindInstanceReimaged},
+	{"microsoft.compute/virtualmachinescalesets/write", KindVMSSUpdated},
+	{"microsoft.containerservice/managedclusters/agentpools/upgradenodeimageversion", KindNodeImageUpgrade},
+	{"microsoft.containerservice/managedclusters/agentpools/delete", KindNodePoolDeleted},
+	{"microsoft.containerservice/managedclusters/agentpools/write", KindNodePoolOperation},
+	{"microsoft.containerservice/managedclusters/write", KindClusterOperation},
+}
+
+// ClassifyOperation returns the event kind of an activity log operation name, or false for
+// operations that do not change node pools
+func ClassifyOperation(operationName string) (string, bool) {
+	operation := strings.ToLower(operationName)
+	if strings.Contains(operation, "evict") {
+		return KindSpotEviction, true
+	}
+	for _, candidate := range activityOperations {
+		if strings.HasPrefix(operation, candidate.operation) {
+			return candidate.kind, true
+		}
+	}
+	return "", false
+}
+
+var (
+	// vmssNamePattern matches AKS scale set names, e.g. aks-nodepool1-12345678-vmss
+	vmssNamePattern = regexp.MustCompile(`(?i)\baks-([a-z0-9]+)-[0-9]+-vmss`)
+	// agentPoolPattern matches agent pool resource IDs
+	agentPoolPattern = regexp.MustCompile(`(?i)/agentpools/([^/]+)`)
+
+	// autoscaler decisions, e.g. "Final scale-up plan: [{aks-nodepool1-12345678-vmss 3->5 (max: 10)}]"
+	scaleUpPattern = regexp.MustCompile(`(?i)(final scale-up plan|scale-up: setting group|scale-up: group)`)
+	// e.g. "Scale-down: removing empty node aks-nodepool1-12345678-vmss000003" or "Scale-down: removing node ..."
+	scaleDownPattern = regexp.MustCompile(`(?i)(scale-down: removing|removing (empty |unneeded )?node|scale-down: starting deletion)`)
+	// e.g. "Successfully deleted node aks-nodepool1-12345678-vmss000003"
+	nodeRemovedPattern = regexp.MustCompile(`(?i)(successfully (deleted|removed) node|node .* removed|deleted nodes?:)`)
+)
+
+// AutoscalerMessageTerms are the terms of which every scale decision recognized by
+// ParseAutoscalerMessage contains at least one, to filter the autoscaler logs before their limit
+var AutoscalerMessageTerms = []string{"scale-up", "scale-down", "removing", "removed", "deleted"}
+
+// PoolFromResourceID returns the node pool of an agent pool or AKS scale set resource ID
+func PoolFromResourceID(resourceID string) string {
+	if match := agentPoolPattern.FindStringSubmatch(resourceID); match != nil {
+		return strings.ToLower(match[1])
+	}
+	return PoolFromVMSSName(resourceID)
+}
+
+// PoolFromVMSSName returns the node pool of an AKS scale set or node name
+func PoolFromVMSSName(name string) string {
+	if match := vmssNamePattern.FindStringSubmatch(name); match != nil {
+		return strings.ToLower(match[1])
+	}
+	return ""
+}
+
+// ParseAutoscalerMessage classifies a cluster autoscaler log line and returns the node pools it
+// mentions. Lines that are not scale decisions return false.
+func ParseAutoscalerMessage(message string) (string, []string, bool) {
+	kind := ""
+	switch {
+	case scaleUpPattern.MatchString(message):
+		kind = KindAutoscalerScaleUp
+	case scaleDownPattern.MatchString(message):
+		kind = KindAutoscalerScaleDn
+	case nodeRemovedPattern.MatchString(message):
+		kind = KindAutoscalerNodeGone
+	default:
+		return "", nil, false
+	}
+
+	var pools []string
+	for _, match := range vmssNamePattern.FindAllStringSubmatch(message, -1) {
+		pool := strings.ToLower(match[1])
+		if !slices.Contains(pools, pool) {
+			pools = append(pools, pool)
+		}
+	}
+	return kind, pools, true
+}
+
+// Build returns one ordered timeline per node pool. Cluster-wide events appear in every timeline
+// and events of pools not listed get a timeline of their own, e.g. for deleted pools.
+func Build(events []Event, pools []string) []Timeline {
+	byPool := map[string][]Event{}
+	var clusterEvents []Event
+	order := slices.Clone(pools)
+	for _, event := range events {
+		if event.NodePool == "" {
+			clusterEvents = append(clusterEvents, event)
+			continue
+		}
+		if _, ok := byPool[event.NodePool]; !ok && !slices.Contains(order, event.NodePool) {
+			order = append(order, event.NodePool)
+		}
+		byPool[event.NodePool] = append(byPool[event.NodePool], event)
+	}
+
+	timelines := make([]Timeline, 0, len(order))
+	for _, pool := range order {
+		poolEvents := append(slices.Clone(byPool[pool]), clusterEvents...)
+		slices.SortStableFunc(poolEvents, func(a, b Event) int { return a.Time.Compare(b.Time) })
+		attributeCauses(poolEvents)
+
+		summary := map[string]int{}
+		for _, event := range poolEvents {
+			summary[event.Kind]++
+			if event.Cause != "" {
+				summary["cause:"+event.Cause]++
+			}
+		}
+		if poolEvents == nil {
+			poolEvents = []Event{}
+		}
+		timelines = append(timelines, Timeline{NodePool: pool, Summary: summary, Events: poolEvents})
+	}
+	return timelines
+}
+
+// attributeCauses sets the cause of VMSS updates and instance deletions from the evictions,
+// autoscaler decisions and AKS operations within the attribution window
+func attributeCauses(events []Event) {
+	for i := range events {
+		if events[i].Kind != KindInstanceDeleted && events[i].Kind != KindVMSSUpdated {
+			continue
+		}
+		events[i].Cause = CauseManual
+		// The most specific cause wins: an eviction, the autoscaler, an upgrade, then any other AKS
+		// operation such as az aks nodepool scale
+		best := 0
+		for _, other := range events {
+			if other.Time.Sub(events[i].Time).Abs() > AttributionWindow {
+				continue
+			}
+			rank, cause := 0, ""
+			switch other.Kind {
+			case KindSpotEviction:
+				rank, cause = 4, CauseSpotEviction
+			case KindAutoscalerScaleUp, KindAutoscalerScaleDn, KindAutoscalerNodeGone:
+				rank, cause = 3, CauseClusterAutoscaler
+			case KindNodeImageUpgrade:
+				rank, cause = 2, CauseUpgrade
+			case KindNodePoolOperation, KindClusterOperation:
+				rank, cause = 1, CauseAKSOperation
+				if strings.Contains(strings.ToLower(other.Message), "upgrade") {
+					rank, cause = 2, CauseUpgrade
+				}
+			}
+			if rank > best {
+				best, events[i].Cause = rank, cause
+			}
+		}
+	}
+}
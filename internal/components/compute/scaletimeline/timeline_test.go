@@ -0,0 +1,123 @@
+package scaletimeline
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyOperation(t *testing.T) {
+	tests := []struct {
+		operation string
+		kind      string
+		ok        bool
+	}{
+		{"Microsoft.Compute/virtualMachineScaleSets/write", KindVMSSUpdated, true},
+		{"Microsoft.Compute/virtualMachineScaleSets/delete/action", KindInstanceDeleted, true},
+		{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/delete", KindInstanceDeleted, true},
+		{"Microsoft.Compute/virtualMachineScaleSets/virtualMachines/evictSpotVM/action", KindSpotEviction, true},
+		{"Microsoft.ContainerService/managedClusters/agentPools/upgradeNodeImageVersion/action", KindNodeImageUpgrade, true},
+		{"Microsoft.ContainerService/managedClusters/agentPools/write", KindNodePoolOperation, true},
+		{"Microsoft.ContainerService/managedClusters/write", KindClusterOperation, true},
+		{"Microsoft.ContainerService/managedClusters/listClusterUserCredential/action", "", false},
+		{"Microsoft.Compute/virtualMachineScaleSets/read", "", false},
+	}
+	for _, tt := range tests {
+		kind, ok := ClassifyOperation(tt.operation)
+		if kind != tt.kind || ok != tt.ok {
+			t.Errorf("ClassifyOperation(%q) = %q, %v, want %q, %v", tt.operation, kind, ok, tt.kind, tt.ok)
+		}
+	}
+}
+
+func TestPoolFromResourceID(t *testing.T) {
+	tests := map[string]string{
+		"/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c/agentPools/UserPool":                                   "userpool",
+		"/subscriptions/s/resourceGroups/MC_rg_c_westeurope/providers/Microsoft.Compute/virtualMachineScaleSets/aks-spot-12345678-vmss/virtualMachines/3": "spot",
+		"/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c":                                                       "",
+	}
+	for resourceID, expected := range tests {
+		if pool := PoolFromResourceID(resourceID); pool != expected {
+			t.Errorf("PoolFromResourceID(%q) = %q, want %q", resourceID, pool, expected)
+		}
+	}
+}
+
+func TestParseAutoscalerMessage(t *testing.T) {
+	kind, pools, ok := ParseAutoscalerMessage("I1018 10:00:01.000000 1 scale_up.go:477] Final scale-up plan: [{aks-user-12345678-vmss 3->5 (max: 10)}]")
+	if !ok || kind != KindAutoscalerScaleUp || !slices.Equal(pools, []string{"user"}) {
+		t.Errorf("Unexpected scale-up parse: %q %v %v", kind, pools, ok)
+	}
+
+	kind, pools, ok = ParseAutoscalerMessage("I1018 11:00:00.000000 1 scale_down.go:1102] Scale-down: removing empty node aks-spot-12345678-vmss000003")
+	if !ok || kind != KindAutoscalerScaleDn || !slices.Equal(pools, []string{"spot"}) {
+		t.Errorf("Unexpected scale-down parse: %q %v %v", kind, pools, ok)
+	}
+
+	if _, _, ok := ParseAutoscalerMessage("I1018 11:00:00.000000 1 static_autoscaler.go:230] Starting main loop"); ok {
+		t.Error("Expected the main loop line to be ignored")
+	}
+
+	// Scale decisions must contain one of the terms filtering the autoscaler logs
+	for _, message := range []string{
+		"Final scale-up plan: [{aks-user-12345678-vmss 3->5 (max: 10)}]",
+		"Scale-up: setting group aks-user-12345678-vmss size to 5",
+		"Scale-down: removing node aks-user-12345678-vmss000001, utilization: 0.1",
+		"Removing unneeded node aks-user-12345678-vmss000001",
+		"Successfully deleted node aks-user-12345678-vmss000001",
+		"Scale-down: starting deletion of node aks-user-12345678-vmss000001",
+	} {
+		if _, _, ok := ParseAutoscalerMessage(message); !ok || !slices.ContainsFunc(AutoscalerMessageTerms, func(term string) bool {
+			return strings.Contains(strings.ToLower(message), term)
+		}) {
+			t.Errorf("Expected %q to be a scale decision containing one of %v", message, AutoscalerMessageTerms)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	base := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: base.Add(2 * time.Minute), NodePool: "user", Source: SourceActivityLog, Kind: KindVMSSUpdated},
+		{Time: base, NodePool: "user", Source: SourceClusterAutoscaler, Kind: KindAutoscalerScaleUp},
+		{Time: base.Add(time.Hour), NodePool: "spot", Source: SourceActivityLog, Kind: KindSpotEviction},
+		{Time: base.Add(time.Hour + time.Minute), NodePool: "spot", Source: SourceActivityLog, Kind: KindInstanceDeleted},
+		{Time: base.Add(3 * time.Hour), NodePool: "user", Source: SourceActivityLog, Kind: KindInstanceDeleted},
+		{Time: base.Add(5 * time.Hour), Source: SourceAKSOperation, Kind: KindClusterOperation, Message: "Upgrade Managed Cluster"},
+		{Time: base.Add(5*time.Hour + 5*time.Minute), NodePool: "user", Source: SourceActivityLog, Kind: KindVMSSUpdated},
+		{Time: base.Add(6 * time.Hour), NodePool: "old", Source: SourceAKSOperation, Kind: KindNodePoolDeleted},
+	}
+
+	timelines := Build(events, []string{"user", "spot", "idle"})
+	if len(timelines) != 4 {
+		t.Fatalf("Expected timelines for the three pools and the deleted pool, got %d", len(timelines))
+	}
+
+	user := timelines[0]
+	if user.NodePool != "user" || len(user.Events) != 5 {
+		t.Fatalf("Unexpected user timeline: %+v", user)
+	}
+	if user.Events[0].Kind != KindAutoscalerScaleUp {
+		t.Errorf("Expected events ordered by time, got %+v", user.Events)
+	}
+	causes := []string{user.Events[1].Cause, user.Events[2].Cause, user.Events[4].Cause}
+	if !slices.Equal(causes, []string{CauseClusterAutoscaler, CauseManual, CauseUpgrade}) {
+		t.Errorf("Unexpected causes: %v", causes)
+	}
+	if user.Summary["cause:"+CauseManual] != 1 || user.Summary[KindClusterOperation] != 1 {
+		t.Errorf("Unexpected summary: %v", user.Summary)
+	}
+
+	spot := timelines[1]
+	if spot.Events[1].Cause != CauseSpotEviction {
+		t.Errorf("Expected the deletion to be attributed to the eviction, got %+v", spot.Events[1])
+	}
+
+	if idle := timelines[2]; len(idle.Events) != 1 || idle.Events[0].Kind != KindClusterOperation {
+		t.Errorf("Expected only the cluster operation for the idle pool, got %+v", idle.Events)
+	}
+	if timelines[3].NodePool != "old" {
+		t.Errorf("Expected a timeline for the deleted pool, got %s", timelines[3].NodePool)
+	}
+}
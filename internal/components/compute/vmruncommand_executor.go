@@ -23,14 +23,31 @@ func NewVMRunCommandExecutor(azClient *azureclient.AzureClient) *VMRunCommandExe
 	}
 }
 
+// Run command IDs of the scripts executed on Linux and Windows instances
+const (
+	CommandIDRunShellScript      = "RunShellScript"
+	CommandIDRunPowerShellScript = "RunPowerShellScript"
+)
+
 // ExecuteOnVMSSInstance executes a shell command on a specific VMSS instance
 func (e *VMRunCommandExecutor) ExecuteOnVMSSInstance(
 	ctx context.Context,
 	subscriptionID, resourceGroup, vmssName, instanceID string,
 	command string,
 ) (string, error) {
-	logger.Debugf("VMRunCommandExecutor: Executing command on VMSS %s/%s instance %s in subscription %s",
-		resourceGroup, vmssName, instanceID, subscriptionID)
+	return e.ExecuteScriptOnVMSSInstance(ctx, subscriptionID, resourceGroup, vmssName, instanceID, CommandIDRunShellScript, command)
+}
+
+// ExecuteScriptOnVMSSInstance executes a script with the given run command ID (RunShellScript
+// or RunPowerShellScript) on a specific VMSS instance
+func (e *VMRunCommandExecutor) ExecuteScriptOnVMSSInstance(
+	ctx context.Context,
+	subscriptionID, resourceGroup, vmssName, instanceID string,
+	commandID string,
+	command string,
+) (string, error) {
+	logger.Debugf("VMRunCommandExecutor: Executing %s on VMSS %s/%s instance %s in subscription %s",
+		commandID, resourceGroup, vmssName, instanceID, subscriptionID)
 
 	// Get or create clients for the subscription
 	clients, err := e.azClient.GetOrCreateClientsForSubscription(subscriptionID)
@@ -44,7 +61,7 @@ func (e *VMRunCommandExecutor) ExecuteOnVMSSInstance(
 
 	// Build RunCommandInput
 	runCommandInput := armcompute.RunCommandInput{
-		CommandID: to.Ptr("RunShellScript"),
+		CommandID: to.Ptr(commandID),
 		Script:    []*string{to.Ptr(command)},
 	}
 
@@ -0,0 +1,397 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// aksCSEExtensionName is the name of the custom script extension that bootstraps AKS nodes
+const aksCSEExtensionName = "vmssCSE"
+
+// maxStatusMessageLength bounds extension status messages, which embed the script output on failures
+const maxStatusMessageLength = 500
+
+// VMSSInstanceReport is the state of a single VMSS instance
+type VMSSInstanceReport struct {
+	InstanceID         string                       `json:"instance_id"`
+	NodeName           string                       `json:"node_name,omitempty"`
+	Zone               string                       `json:"zone,omitempty"`
+	PowerState         string                       `json:"power_state,omitempty"`
+	ProvisioningState  string                       `json:"provisioning_state,omitempty"`
+	LatestModelApplied *bool                        `json:"latest_model_applied,omitempty"`
+	FaultDomain        *int32                       `json:"fault_domain,omitempty"`
+	UpdateDomain       *int32                       `json:"update_domain,omitempty"`
+	OSImage            string                       `json:"os_image,omitempty"`
+	OSImageVersion     string                       `json:"os_image_version,omitempty"`
+	OSName             string                       `json:"os_name,omitempty"`
+	OSVersion          string                       `json:"os_version,omitempty"`
+	VMAgentVersion     string                       `json:"vm_agent_version,omitempty"`
+	BootDiagnostics    string                       `json:"boot_diagnostics,omitempty"`
+	Extensions         []VMSSExtensionStatus        `json:"extensions,omitempty"`
+	ExtensionHandlers  []VMSSExtensionHandlerStatus `json:"extension_handlers,omitempty"`
+	Issues             []string                     `json:"issues,omitempty"`
+}
+
+// VMSSExtensionStatus is the status reported by an extension of an instance
+type VMSSExtensionStatus struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	AKSCSE  bool   `json:"aks_cse,omitempty"`
+	Failed  bool   `json:"failed,omitempty"`
+}
+
+// VMSSExtensionHandlerStatus is the status of an extension handler reported by the VM agent
+type VMSSExtensionHandlerStatus struct {
+	Type    string `json:"type"`
+	Version string `json:"version,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// VMSSInstancesReport is the per-instance report of a scale set, highlighting the instances
+// that are out of date with the scale set model or have failed extensions
+type VMSSInstancesReport struct {
+	NodePool                 string               `json:"node_pool,omitempty"`
+	VMSSName                 string               `json:"vmss_name"`
+	VMSSID                   string               `json:"vmss_id"`
+	InstanceCount            int                  `json:"instance_count"`
+	OutOfDateInstances       []string             `json:"out_of_date_instances"`
+	FailedExtensionInstances []string             `json:"failed_extension_instances"`
+	NotRunningInstances      []string             `json:"not_running_instances"`
+	Instances                []VMSSInstanceReport `json:"instances"`
+	Error                    string               `json:"error,omitempty"`
+}
+
+// getVMSSInstanceReports handles the instance report of get_aks_vmss_info for a node, a node pool
+// or all node pools of the cluster
+func getVMSSInstanceReports(
+	ctx context.Context,
+	client *azureclient.AzureClient,
+	cluster *armcontainerservice.ManagedCluster,
+	nodePoolName, nodeName, rg, clusterName string,
+) (string, error) {
+	var reports []*VMSSInstancesReport
+
+	switch {
+	case nodeName != "":
+		instance, err := ResolveNodeInstance(ctx, cluster, nodeName, client)
+		if err != nil {
+			return "", err
+		}
+		vm, err := getVMSSInstanceView(ctx, client, instance)
+		if err != nil {
+			return "", err
+		}
+		report := buildVMSSInstancesReport(instance.NodePool, instance.VMSSID(), []*armcompute.VirtualMachineScaleSetVM{vm})
+		reports = append(reports, report)
+
+	case nodePoolName != "":
+		vmssID, err := GetVMSSIDFromNodePool(ctx, cluster, nodePoolName, client)
+		if err != nil {
+			return "", fmt.Errorf("failed to get VMSS ID: %v", err)
+		}
+		vms, err := listVMSSInstanceViews(ctx, client, vmssID)
+		if err != nil {
+			return "", err
+		}
+		reports = append(reports, buildVMSSInstancesReport(nodePoolName, vmssID, vms))
+
+	default:
+		nodePools, err := GetNodePoolsFromAKS(ctx, cluster, client)
+		if err != nil {
+			return "", fmt.Errorf("failed to get node pools: %v", err)
+		}
+		for _, nodePool := range nodePools {
+			if nodePool.Name == nil {
+				continue
+			}
+			// Errors of a node pool are reported without failing the other node pools
+			vmssID, err := GetVMSSIDFromNodePool(ctx, cluster, *nodePool.Name, client)
+			if err != nil {
+				reports = append(reports, &VMSSInstancesReport{NodePool: *nodePool.Name, Error: fmt.Sprintf("Failed to get VMSS ID: %v", err)})
+				continue
+			}
+			vms, err := listVMSSInstanceViews(ctx, client, vmssID)
+			if err != nil {
+				reports = append(reports, &VMSSInstancesReport{NodePool: *nodePool.Name, VMSSID: vmssID, Error: err.Error()})
+				continue
+			}
+			reports = append(reports, buildVMSSInstancesReport(*nodePool.Name, vmssID, vms))
+		}
+	}
+
+	result := map[string]interface{}{
+		"cluster_name":   clusterName,
+		"resource_group": rg,
+		"vmss_instances": reports,
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal VMSS instance report to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// listVMSSInstanceViews lists the instances of a scale set with their instance view
+func listVMSSInstanceViews(ctx context.Context, client *azureclient.AzureClient, vmssID string) ([]*armcompute.VirtualMachineScaleSetVM, error) {
+	// Format: /subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Compute/virtualMachineScaleSets/{vmss}
+	parts := strings.Split(vmssID, "/")
+	if len(parts) < 9 {
+		return nil, fmt.Errorf("invalid VMSS resource ID format: %s", vmssID)
+	}
+
+	clients, err := client.GetOrCreateClientsForSubscription(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients for subscription %s: %v", parts[2], err)
+	}
+
+	var vms []*armcompute.VirtualMachineScaleSetVM
+	pager := clients.VMSSVMsClient.NewListPager(parts[4], parts[8], &armcompute.VirtualMachineScaleSetVMsClientListOptions{
+		Expand: to.Ptr(string(armcompute.InstanceViewTypesInstanceView)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list VMSS instances of %s: %v", parts[8], err)
+		}
+		vms = append(vms, page.Value...)
+	}
+	return vms, nil
+}
+
+// getVMSSInstanceView gets a single instance with its instance view
+func getVMSSInstanceView(ctx context.Context, client *azureclient.AzureClient, instance *NodeInstance) (*armcompute.VirtualMachineScaleSetVM, error) {
+	clients, err := client.GetOrCreateClientsForSubscription(instance.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients for subscription %s: %v", instance.SubscriptionID, err)
+	}
+
+	resp, err := clients.VMSSVMsClient.Get(ctx, instance.ResourceGroup, instance.VMSSName, instance.InstanceID,
+		&armcompute.VirtualMachineScaleSetVMsClientGetOptions{Expand: to.Ptr(armcompute.InstanceViewTypesInstanceView)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VMSS instance %s/%s: %v", instance.VMSSName, instance.InstanceID, err)
+	}
+	return &resp.VirtualMachineScaleSetVM, nil
+}
+
+// buildVMSSInstancesReport builds the report of the instances of a scale set
+func buildVMSSInstancesReport(nodePool, vmssID string, vms []*armcompute.VirtualMachineScaleSetVM) *VMSSInstancesReport {
+	vmssName := vmssID[strings.LastIndex(vmssID, "/")+1:]
+	report := &VMSSInstancesReport{
+		NodePool:                 nodePool,
+		VMSSName:                 vmssName,
+		VMSSID:                   vmssID,
+		OutOfDateInstances:       []string{},
+		FailedExtensionInstances: []string{},
+		NotRunningInstances:      []string{},
+		Instances:                []VMSSInstanceReport{},
+	}
+
+	for _, vm := range vms {
+		if vm == nil || vm.InstanceID == nil {
+			continue
+		}
+		instance := buildVMSSInstanceReport(vm, vmssName)
+		report.Instances = append(report.Instances, instance)
+
+		if instance.LatestModelApplied != nil && !*instance.LatestModelApplied {
+			report.OutOfDateInstances = append(report.OutOfDateInstances, instance.InstanceID)
+		}
+		for _, extension := range instance.Extensions {
+			if extension.Failed {
+				report.FailedExtensionInstances = append(report.FailedExtensionInstances, instance.InstanceID)
+				break
+			}
+		}
+		if instance.PowerState != "" && instance.PowerState != "running" {
+			report.NotRunningInstances = append(report.NotRunningInstances, instance.InstanceID)
+		}
+	}
+	report.InstanceCount = len(report.Instances)
+	return report
+}
+
+// buildVMSSInstanceReport extracts the state of an instance from its model and instance view
+func buildVMSSInstanceReport(vm *armcompute.VirtualMachineScaleSetVM, vmssName string) VMSSInstanceReport {
+	instance := VMSSInstanceReport{
+		InstanceID: *vm.InstanceID,
+		NodeName:   vmssInstanceNodeName(vm, vmssName),
+	}
+	if len(vm.Zones) > 0 && vm.Zones[0] != nil {
+		instance.Zone = *vm.Zones[0]
+	}
+
+	if props := vm.Properties; props != nil {
+		instance.LatestModelApplied = props.LatestModelApplied
+		if props.ProvisioningState != nil {
+			instance.ProvisioningState = *props.ProvisioningState
+		}
+		if props.StorageProfile != nil {
+			instance.OSImage, instance.OSImageVersion = imageReferenceVersion(props.StorageProfile.ImageReference)
+		}
+
+		if view := props.InstanceView; view != nil {
+			instance.FaultDomain = view.PlatformFaultDomain
+			instance.UpdateDomain = view.PlatformUpdateDomain
+			instance.OSName = stringValue(view.OSName)
+			instance.OSVersion = stringValue(view.OSVersion)
+			for _, status := range view.Statuses {
+				if status == nil || status.Code == nil {
+					continue
+				}
+				if state, ok := strings.CutPrefix(*status.Code, "PowerState/"); ok {
+					instance.PowerState = state
+				}
+			}
+			if view.BootDiagnostics != nil && view.BootDiagnostics.Status != nil {
+				instance.BootDiagnostics = statusText(view.BootDiagnostics.Status)
+			} else if view.BootDiagnostics != nil {
+				instance.BootDiagnostics = "enabled"
+			}
+			for _, extension := range view.Extensions {
+				if extension != nil {
+					instance.Extensions = append(instance.Extensions, buildExtensionStatus(extension))
+				}
+			}
+			if agent := view.VMAgent; agent != nil {
+				instance.VMAgentVersion = stringValue(agent.VMAgentVersion)
+				for _, handler := range agent.ExtensionHandlers {
+					if handler == nil {
+						continue
+					}
+					status := VMSSExtensionHandlerStatus{Type: stringValue(handler.Type), Version: stringValue(handler.TypeHandlerVersion)}
+					if handler.Status != nil {
+						status.Status = statusText(handler.Status)
+					}
+					instance.ExtensionHandlers = append(instance.ExtensionHandlers, status)
+				}
+			}
+		}
+	}
+
+	if instance.LatestModelApplied != nil && !*instance.LatestModelApplied {
+		instance.Issues = append(instance.Issues, "instance is not running the latest scale set model; upgrade or reimage it to apply the model")
+	}
+	if instance.ProvisioningState != "" && !strings.EqualFold(instance.ProvisioningState, "Succeeded") {
+		instance.Issues = append(instance.Issues, fmt.Sprintf("provisioning state is %s", instance.ProvisioningState))
+	}
+	if instance.PowerState != "" && instance.PowerState != "running" {
+		instance.Issues = append(instance.Issues, fmt.Sprintf("power state is %s", instance.PowerState))
+	}
+	for _, extension := range instance.Extensions {
+		if !extension.Failed {
+			continue
+		}
+		if extension.AKSCSE {
+			instance.Issues = append(instance.Issues, "the AKS node bootstrap extension (CSE) failed; check cluster-provision.log with collect_aks_node_logs log_type cse")
+		} else {
+			instance.Issues = append(instance.Issues, fmt.Sprintf("extension %s failed", extension.Name))
+		}
+	}
+	return instance
+}
+
+// buildExtensionStatus summarizes the instance view of an extension
+func buildExtensionStatus(extension *armcompute.VirtualMachineExtensionInstanceView) VMSSExtensionStatus {
+	status := VMSSExtensionStatus{
+		Name:    stringValue(extension.Name),
+		Type:    stringValue(extension.Type),
+		Version: stringValue(extension.TypeHandlerVersion),
+		AKSCSE:  strings.EqualFold(stringValue(extension.Name), aksCSEExtensionName),
+	}
+
+	for _, s := range append(extension.Statuses, extension.Substatuses...) {
+		if s == nil {
+			continue
+		}
+		if status.Status == "" {
+			status.Status = statusText(s)
+		}
+		// The first failed status explains the failure
+		if statusFailed(s) && !status.Failed {
+			status.Failed = true
+			status.Status = statusText(s)
+			if s.Message != nil {
+				status.Message = truncateMessage(strings.TrimSpace(*s.Message), maxStatusMessageLength)
+			}
+		}
+	}
+	return status
+}
+
+// imageReferenceVersion returns the image and version of an image reference. AKS node images are
+// shared gallery image versions, e.g. .../galleries/AKSUbuntu/images/2204gen2containerd/versions/202410.09.0
+func imageReferenceVersion(ref *armcompute.ImageReference) (string, string) {
+	if ref == nil {
+		return "", ""
+	}
+
+	for _, id := range []*string{ref.ID, ref.SharedGalleryImageID, ref.CommunityGalleryImageID} {
+		if id == nil || *id == "" {
+			continue
+		}
+		parts := strings.Split(strings.Trim(*id, "/"), "/")
+		image, version := "", ""
+		for i := 0; i+1 < len(parts); i++ {
+			switch strings.ToLower(parts[i]) {
+			case "galleries", "sharedgalleries", "communitygalleries":
+				image = parts[i+1]
+			case "images":
+				image = strings.TrimPrefix(image+"/"+parts[i+1], "/")
+			case "versions":
+				version = parts[i+1]
+			}
+		}
+		return image, version
+	}
+
+	image := strings.Trim(strings.Join([]string{stringValue(ref.Publisher), stringValue(ref.Offer), stringValue(ref.SKU)}, ":"), ":")
+	version := stringValue(ref.ExactVersion)
+	if version == "" {
+		version = stringValue(ref.Version)
+	}
+	return image, version
+}
+
+// statusFailed reports whether an instance view status is an error or a failed state
+func statusFailed(status *armcompute.InstanceViewStatus) bool {
+	if status.Level != nil && *status.Level == armcompute.StatusLevelTypesError {
+		return true
+	}
+	return status.Code != nil && strings.HasSuffix(strings.ToLower(*status.Code), "/failed")
+}
+
+// statusText returns the display status of an instance view status, falling back to its code
+func statusText(status *armcompute.InstanceViewStatus) string {
+	if status.DisplayStatus != nil && *status.DisplayStatus != "" {
+		return *status.DisplayStatus
+	}
+	return stringValue(status.Code)
+}
+
+// truncateMessage shortens a message to at most max bytes
+func truncateMessage(message string, max int) string {
+	if len(message) <= max {
+		return message
+	}
+	return message[:max] + "..."
+}
+
+// stringValue dereferences an optional string
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,117 @@
+package compute
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+)
+
+const testGalleryImage = "/subscriptions/sub/resourceGroups/AKS-Ubuntu/providers/Microsoft.Compute/galleries/AKSUbuntu/images/2204gen2containerd/versions/202410.09.0"
+
+func testInstanceView(instanceID string, latest bool, powerState string, cseStatus *armcompute.InstanceViewStatus) *armcompute.VirtualMachineScaleSetVM {
+	return &armcompute.VirtualMachineScaleSetVM{
+		InstanceID: to.Ptr(instanceID),
+		Zones:      []*string{to.Ptr("2")},
+		Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+			LatestModelApplied: to.Ptr(latest),
+			ProvisioningState:  to.Ptr("Succeeded"),
+			StorageProfile:     &armcompute.StorageProfile{ImageReference: &armcompute.ImageReference{ID: to.Ptr(testGalleryImage)}},
+			InstanceView: &armcompute.VirtualMachineScaleSetVMInstanceView{
+				PlatformFaultDomain:  to.Ptr[int32](1),
+				PlatformUpdateDomain: to.Ptr[int32](0),
+				Statuses: []*armcompute.InstanceViewStatus{
+					{Code: to.Ptr("ProvisioningState/succeeded")},
+					{Code: to.Ptr("PowerState/" + powerState)},
+				},
+				BootDiagnostics: &armcompute.BootDiagnosticsInstanceView{},
+				Extensions: []*armcompute.VirtualMachineExtensionInstanceView{
+					{Name: to.Ptr("vmssCSE"), Type: to.Ptr("Microsoft.Azure.Extensions.CustomScript"), TypeHandlerVersion: to.Ptr("2.1"), Statuses: []*armcompute.InstanceViewStatus{cseStatus}},
+				},
+				VMAgent: &armcompute.VirtualMachineAgentInstanceView{
+					VMAgentVersion: to.Ptr("2.11.1.12"),
+					ExtensionHandlers: []*armcompute.VirtualMachineExtensionHandlerInstanceView{
+						{Type: to.Ptr("Microsoft.Azure.Extensions.CustomScript"), TypeHandlerVersion: to.Ptr("2.1.10"), Status: &armcompute.InstanceViewStatus{Code: to.Ptr("ProvisioningState/succeeded"), DisplayStatus: to.Ptr("Ready")}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildVMSSInstancesReport(t *testing.T) {
+	succeeded := &armcompute.InstanceViewStatus{Code: to.Ptr("ProvisioningState/succeeded"), DisplayStatus: to.Ptr("Provisioning succeeded")}
+	failed := &armcompute.InstanceViewStatus{
+		Code:          to.Ptr("ProvisioningState/failed/1"),
+		Level:         to.Ptr(armcompute.StatusLevelTypesError),
+		DisplayStatus: to.Ptr("Provisioning failed"),
+		Message:       to.Ptr("Enable failed: exit status 50 " + strings.Repeat("x", 1000)),
+	}
+
+	report := buildVMSSInstancesReport("nodepool1", testVMSSID, []*armcompute.VirtualMachineScaleSetVM{
+		testInstanceView("0", true, "running", succeeded),
+		testInstanceView("1", false, "running", succeeded),
+		testInstanceView("2", true, "deallocated", failed),
+	})
+
+	if report.VMSSName != "aks-nodepool1-12345678-vmss" || report.InstanceCount != 3 {
+		t.Errorf("Unexpected report: %s, %d instances", report.VMSSName, report.InstanceCount)
+	}
+	if !slices.Equal(report.OutOfDateInstances, []string{"1"}) {
+		t.Errorf("Expected instance 1 out of date, got %v", report.OutOfDateInstances)
+	}
+	if !slices.Equal(report.FailedExtensionInstances, []string{"2"}) {
+		t.Errorf("Expected instance 2 with failed extensions, got %v", report.FailedExtensionInstances)
+	}
+	if !slices.Equal(report.NotRunningInstances, []string{"2"}) {
+		t.Errorf("Expected instance 2 not running, got %v", report.NotRunningInstances)
+	}
+
+	healthy := report.Instances[0]
+	if healthy.NodeName != "aks-nodepool1-12345678-vmss000000" || healthy.Zone != "2" || *healthy.FaultDomain != 1 ||
+		healthy.OSImage != "AKSUbuntu/2204gen2containerd" || healthy.OSImageVersion != "202410.09.0" ||
+		healthy.VMAgentVersion != "2.11.1.12" || healthy.BootDiagnostics != "enabled" || len(healthy.Issues) != 0 {
+		t.Errorf("Unexpected healthy instance: %+v", healthy)
+	}
+	if len(healthy.ExtensionHandlers) != 1 || healthy.ExtensionHandlers[0].Status != "Ready" {
+		t.Errorf("Unexpected extension handlers: %+v", healthy.ExtensionHandlers)
+	}
+
+	broken := report.Instances[2]
+	cse := broken.Extensions[0]
+	if !cse.AKSCSE || !cse.Failed || cse.Status != "Provisioning failed" || len(cse.Message) != maxStatusMessageLength+3 {
+		t.Errorf("Unexpected CSE status: %+v", cse)
+	}
+	issues := strings.Join(broken.Issues, "\n")
+	if !strings.Contains(issues, "power state is deallocated") || !strings.Contains(issues, "CSE") {
+		t.Errorf("Expected power state and CSE issues, got %v", broken.Issues)
+	}
+	if !strings.Contains(strings.Join(report.Instances[1].Issues, "\n"), "latest scale set model") {
+		t.Errorf("Expected model drift issue, got %v", report.Instances[1].Issues)
+	}
+}
+
+func TestImageReferenceVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     *armcompute.ImageReference
+		image   string
+		version string
+	}{
+		{"nil", nil, "", ""},
+		{"gallery image", &armcompute.ImageReference{ID: to.Ptr(testGalleryImage)}, "AKSUbuntu/2204gen2containerd", "202410.09.0"},
+		{"shared gallery", &armcompute.ImageReference{SharedGalleryImageID: to.Ptr("/SharedGalleries/abc/Images/windows-2022/Versions/20348.2700.240911")}, "abc/windows-2022", "20348.2700.240911"},
+		{"marketplace", &armcompute.ImageReference{Publisher: to.Ptr("microsoft-aks"), Offer: to.Ptr("aks"), SKU: to.Ptr("aks-ubuntu"), ExactVersion: to.Ptr("2024.10.01")}, "microsoft-aks:aks:aks-ubuntu", "2024.10.01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, version := imageReferenceVersion(tt.ref)
+			if image != tt.image || version != tt.version {
+				t.Errorf("imageReferenceVersion() = %q, %q, want %q, %q", image, version, tt.image, tt.version)
+			}
+		})
+	}
+}
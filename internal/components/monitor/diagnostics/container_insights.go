@@ -0,0 +1,234 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Container Insights query templates
+const (
+	TemplatePodLogs       = "pod_logs"
+	TemplatePodRestarts   = "pod_restarts"
+	TemplateOOMKilled     = "oom_killed"
+	TemplateNodeInventory = "node_inventory"
+	TemplateKubeEvents    = "kube_events"
+)
+
+// Container Insights query limits
+const (
+	MaxTextMatchLength         = 256
+	DefaultRestartInterval     = "1h"
+	maxKubernetesNameLength    = 253
+	maxKubernetesLabelLength   = 63
+	maxKubernetesReasonLength  = 128
+	containerInsightsTimeOrder = " | order by TimeGenerated desc"
+)
+
+// containerInsightsFilters lists the filters each template accepts
+var containerInsightsFilters = map[string][]string{
+	TemplatePodLogs:       {"namespace", "pod_name", "container", "contains"},
+	TemplatePodRestarts:   {"namespace", "pod_name", "container", "interval"},
+	TemplateOOMKilled:     {"namespace", "pod_name", "container"},
+	TemplateNodeInventory: {"node_name"},
+	TemplateKubeEvents:    {"namespace", "pod_name", "reason", "contains"},
+}
+
+// validRestartIntervals are the bin sizes of the pod_restarts template
+var validRestartIntervals = []string{"5m", "15m", "30m", "1h", "6h", "12h", "1d"}
+
+var (
+	// kubernetesLabelPattern matches DNS-1123 labels such as namespace and container names
+	kubernetesLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// kubernetesNamePattern matches DNS-1123 subdomains such as pod and node names
+	kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+	// eventReasonPattern matches Kubernetes event reasons such as BackOff or FailedScheduling
+	eventReasonPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+)
+
+// ContainerInsightsQueryParams holds the parameters of a Container Insights query template
+type ContainerInsightsQueryParams struct {
+	Template          string
+	Namespace         string
+	PodName           string
+	Container         string
+	NodeName          string
+	Reason            string
+	TextMatch         string
+	Interval          string
+	MaxRecords        int
+	ClusterResourceID string
+}
+
+// GetSupportedContainerInsightsTemplates returns the Container Insights query templates
+func GetSupportedContainerInsightsTemplates() []string {
+	return []string{TemplatePodLogs, TemplatePodRestarts, TemplateOOMKilled, TemplateNodeInventory, TemplateKubeEvents}
+}
+
+// ValidateContainerInsightsQueryParams validates all parameters of a Container Insights query
+// template with the same guarantees as ValidateKQLQueryParams: names must be valid Kubernetes
+// names, the record limit and cluster resource ID are checked, and free text is length limited.
+func ValidateContainerInsightsQueryParams(p ContainerInsightsQueryParams) error {
+	allowed, ok := containerInsightsFilters[p.Template]
+	if !ok {
+		return fmt.Errorf("invalid template '%s'. Valid templates: %s", p.Template, strings.Join(GetSupportedContainerInsightsTemplates(), ", "))
+	}
+
+	// Reject filters the template cannot apply rather than silently ignoring them
+	filters := map[string]string{
+		"namespace": p.Namespace,
+		"pod_name":  p.PodName,
+		"container": p.Container,
+		"node_name": p.NodeName,
+		"reason":    p.Reason,
+		"contains":  p.TextMatch,
+		"interval":  p.Interval,
+	}
+	for name, value := range filters {
+		if value != "" && !slices.Contains(allowed, name) {
+			return fmt.Errorf("parameter %s is not supported by template %s (supported: %s)", name, p.Template, strings.Join(allowed, ", "))
+		}
+	}
+
+	if p.Namespace != "" && !isKubernetesName(p.Namespace, kubernetesLabelPattern, maxKubernetesLabelLength) {
+		return fmt.Errorf("invalid namespace '%s': must be a valid Kubernetes namespace name", p.Namespace)
+	}
+	if p.Container != "" && !isKubernetesName(p.Container, kubernetesLabelPattern, maxKubernetesLabelLength) {
+		return fmt.Errorf("invalid container '%s': must be a valid Kubernetes container name", p.Container)
+	}
+	if p.PodName != "" && !isKubernetesName(p.PodName, kubernetesNamePattern, maxKubernetesNameLength) {
+		return fmt.Errorf("invalid pod_name '%s': must be a valid Kubernetes object name", p.PodName)
+	}
+	if p.NodeName != "" && !isKubernetesName(p.NodeName, kubernetesNamePattern, maxKubernetesNameLength) {
+		return fmt.Errorf("invalid node_name '%s': must be a valid Kubernetes node name", p.NodeName)
+	}
+	if p.Reason != "" && !isKubernetesName(p.Reason, eventReasonPattern, maxKubernetesReasonLength) {
+		return fmt.Errorf("invalid reason '%s': must be an event reason such as BackOff or FailedScheduling", p.Reason)
+	}
+	if p.Interval != "" && !slices.Contains(validRestartIntervals, p.Interval) {
+		return fmt.Errorf("invalid interval '%s'. Valid intervals: %s", p.Interval, strings.Join(validRestartIntervals, ", "))
+	}
+
+	// Free text is escaped into a string literal, but control characters are never needed to match log lines
+	if len(p.TextMatch) > MaxTextMatchLength {
+		return fmt.Errorf("contains cannot exceed %d characters, got %d", MaxTextMatchLength, len(p.TextMatch))
+	}
+	if strings.ContainsFunc(p.TextMatch, unicode.IsControl) {
+		return fmt.Errorf("contains cannot include control characters")
+	}
+
+	// Validate maxRecords
+	if p.MaxRecords < MinMaxRecords {
+		return fmt.Errorf("maxRecords must be at least %d, got %d", MinMaxRecords, p.MaxRecords)
+	}
+	if p.MaxRecords > MaxMaxRecords {
+		return fmt.Errorf("maxRecords cannot exceed %d, got %d", MaxMaxRecords, p.MaxRecords)
+	}
+
+	// Validate clusterResourceID
+	if p.ClusterResourceID == "" {
+		return fmt.Errorf("clusterResourceID cannot be empty")
+	}
+	if !azureResourceIDPattern.MatchString(p.ClusterResourceID) {
+		return fmt.Errorf("invalid clusterResourceID format. Expected format: /subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.ContainerService/managedClusters/{cluster-name}")
+	}
+
+	return nil
+}
+
+// isKubernetesName checks a name against its pattern and maximum length
+func isKubernetesName(name string, pattern *regexp.Regexp, maxLength int) bool {
+	return len(name) <= maxLength && pattern.MatchString(name)
+}
+
+// quoteKQLString returns a KQL string literal of the value, escaping backslashes and quotes
+func quoteKQLString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// BuildContainerInsightsQuery validates the parameters and builds the KQL query of a Container
+// Insights template scoped to the cluster. All values are embedded as escaped string literals.
+func BuildContainerInsightsQuery(p ContainerInsightsQueryParams) (string, error) {
+	if err := ValidateContainerInsightsQueryParams(p); err != nil {
+		return "", fmt.Errorf("invalid Container Insights query parameters: %w", err)
+	}
+
+	scope := " | where _ResourceId =~ " + quoteKQLString(strings.ToLower(p.ClusterResourceID))
+	limit := fmt.Sprintf(" | limit %d", p.MaxRecords)
+
+	switch p.Template {
+	case TemplatePodLogs:
+		query := "ContainerLogV2" + scope
+		query += optionalFilter("PodNamespace", p.Namespace)
+		query += optionalFilter("PodName", p.PodName)
+		query += optionalFilter("ContainerName", p.Container)
+		if p.TextMatch != "" {
+			query += " | where tostring(LogMessage) contains " + quoteKQLString(p.TextMatch)
+		}
+		return query + containerInsightsTimeOrder + limit +
+			" | project TimeGenerated, Computer, PodNamespace, PodName, ContainerName, LogSource, LogMessage", nil
+
+	case TemplatePodRestarts:
+		interval := p.Interval
+		if interval == "" {
+			interval = DefaultRestartInterval
+		}
+		// KubePodInventory reports ContainerName as <pod uid>/<container>
+		query := "KubePodInventory" + scope + " | where isnotempty(ContainerName)" +
+			" | extend ContainerName = tostring(split(ContainerName, '/')[-1])"
+		query += optionalFilter("Namespace", p.Namespace)
+		query += optionalFilter("Name", p.PodName)
+		query += optionalFilter("ContainerName", p.Container)
+		// Restarts in a bin are the increase of the restart count since the previous bin of the container
+		return query +
+			" | summarize MaxRestarts = max(ContainerRestartCount), MinRestarts = min(ContainerRestartCount) by bin(TimeGenerated, " + interval + "), Namespace, Name, ContainerName" +
+			" | order by Namespace asc, Name asc, ContainerName asc, TimeGenerated asc" +
+			" | extend Restarts = iff(Namespace == prev(Namespace) and Name == prev(Name) and ContainerName == prev(ContainerName), MaxRestarts - prev(MaxRestarts), MaxRestarts - MinRestarts)" +
+			" | where Restarts > 0" +
+			containerInsightsTimeOrder + limit +
+			" | project TimeGenerated, Namespace, PodName = Name, ContainerName, Restarts, TotalRestarts = MaxRestarts", nil
+
+	case TemplateOOMKilled:
+		query := "KubePodInventory" + scope + " | where ContainerLastStatus has 'OOMKilled'" +
+			" | extend LastStatus = parse_json(ContainerLastStatus), ContainerName = tostring(split(ContainerName, '/')[-1])" +
+			" | where tostring(LastStatus.reason) == 'OOMKilled'"
+		query += optionalFilter("Namespace", p.Namespace)
+		query += optionalFilter("Name", p.PodName)
+		query += optionalFilter("ContainerName", p.Container)
+		return query +
+			" | summarize LastSeen = max(TimeGenerated), FinishedAt = max(todatetime(LastStatus.finishedAt)), ExitCode = take_any(toint(LastStatus.exitCode)), RestartCount = max(ContainerRestartCount) by Namespace, PodName = Name, ContainerName, Computer" +
+			" | order by FinishedAt desc" + limit, nil
+
+	case TemplateNodeInventory:
+		query := "KubeNodeInventory" + scope
+		query += optionalFilter("Computer", p.NodeName)
+		return query + " | summarize arg_max(TimeGenerated, *) by Computer" +
+			" | order by Computer asc" + limit +
+			" | project TimeGenerated, Computer, Status, KubeletVersion, KubeProxyVersion, OperatingSystem, DockerVersion, CreationTimeStamp, Labels", nil
+
+	case TemplateKubeEvents:
+		query := "KubeEvents" + scope
+		query += optionalFilter("Namespace", p.Namespace)
+		query += optionalFilter("Name", p.PodName)
+		query += optionalFilter("Reason", p.Reason)
+		if p.TextMatch != "" {
+			query += " | where Message contains " + quoteKQLString(p.TextMatch)
+		}
+		return query + containerInsightsTimeOrder + limit +
+			" | project TimeGenerated, Namespace, ObjectKind, Name, Reason, KubeEventType, Count, FirstSeen, LastSeen, SourceComponent, Computer, Message", nil
+
+	default:
+		// This should never happen if validation is working correctly
+		return "", fmt.Errorf("unexpected template: %s. This indicates an internal error in query builder", p.Template)
+	}
+}
+
+// optionalFilter returns an equality filter on the column, or nothing for an empty value
+func optionalFilter(column, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf(" | where %s == %s", column, quoteKQLString(value))
+}
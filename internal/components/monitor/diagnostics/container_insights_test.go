@@ -0,0 +1,144 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+)
+
+const testContainerInsightsClusterID = "/subscriptions/test-sub/resourceGroups/test-rg/providers/Microsoft.ContainerService/managedClusters/test-cluster"
+
+func TestBuildContainerInsightsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   ContainerInsightsQueryParams
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "pod logs with filters and text match",
+			params: ContainerInsightsQueryParams{
+				Template: TemplatePodLogs, Namespace: "shop", PodName: "api-7d9f8-abcde", Container: "api",
+				TextMatch: "connection refused", MaxRecords: 50, ClusterResourceID: testContainerInsightsClusterID,
+			},
+			contains: []string{
+				"ContainerLogV2 | where _ResourceId =~ '/subscriptions/test-sub/resourcegroups/test-rg/providers/microsoft.containerservice/managedclusters/test-cluster'",
+				"| where PodNamespace == 'shop'",
+				"| where PodName == 'api-7d9f8-abcde'",
+				"| where ContainerName == 'api'",
+				"| where tostring(LogMessage) contains 'connection refused'",
+				"| order by TimeGenerated desc | limit 50",
+			},
+		},
+		{
+			name:     "pod logs without filters",
+			params:   ContainerInsightsQueryParams{Template: TemplatePodLogs, MaxRecords: 10, ClusterResourceID: testContainerInsightsClusterID},
+			contains: []string{"ContainerLogV2", "| limit 10"},
+			excludes: []string{"PodNamespace ==", "contains"},
+		},
+		{
+			name:     "pod restarts with default interval",
+			params:   ContainerInsightsQueryParams{Template: TemplatePodRestarts, Namespace: "shop", MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID},
+			contains: []string{"KubePodInventory", "bin(TimeGenerated, 1h)", "| where Namespace == 'shop'", "prev(MaxRestarts)", "| where Restarts > 0"},
+		},
+		{
+			name:     "pod restarts with interval",
+			params:   ContainerInsightsQueryParams{Template: TemplatePodRestarts, Interval: "15m", MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID},
+			contains: []string{"bin(TimeGenerated, 15m)"},
+		},
+		{
+			name:     "OOMKilled containers",
+			params:   ContainerInsightsQueryParams{Template: TemplateOOMKilled, Container: "worker", MaxRecords: 20, ClusterResourceID: testContainerInsightsClusterID},
+			contains: []string{"KubePodInventory", "tostring(LastStatus.reason) == 'OOMKilled'", "| where ContainerName == 'worker'", "| limit 20"},
+		},
+		{
+			name:     "node inventory",
+			params:   ContainerInsightsQueryParams{Template: TemplateNodeInventory, NodeName: "aks-nodepool1-12345678-vmss000000", MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID},
+			contains: []string{"KubeNodeInventory", "| where Computer == 'aks-nodepool1-12345678-vmss000000'", "arg_max(TimeGenerated, *) by Computer"},
+		},
+		{
+			name:     "kube events by reason",
+			params:   ContainerInsightsQueryParams{Template: TemplateKubeEvents, Reason: "FailedScheduling", TextMatch: "Insufficient cpu", MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID},
+			contains: []string{"KubeEvents", "| where Reason == 'FailedScheduling'", "| where Message contains 'Insufficient cpu'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildContainerInsightsQuery(tt.params)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range tt.contains {
+				if !strings.Contains(query, expected) {
+					t.Errorf("Expected query to contain %q, got %s", expected, query)
+				}
+			}
+			for _, unexpected := range tt.excludes {
+				if strings.Contains(query, unexpected) {
+					t.Errorf("Expected query not to contain %q, got %s", unexpected, query)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildContainerInsightsQueryEscapesTextMatch(t *testing.T) {
+	query, err := BuildContainerInsightsQuery(ContainerInsightsQueryParams{
+		Template:          TemplatePodLogs,
+		TextMatch:         `it's a \ test' | take 1000000 //`,
+		MaxRecords:        10,
+		ClusterResourceID: testContainerInsightsClusterID,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `| where tostring(LogMessage) contains 'it\'s a \\ test\' | take 1000000 //' | order by`
+	if !strings.Contains(query, expected) {
+		t.Errorf("Expected the text match to stay inside one string literal, got %s", query)
+	}
+}
+
+func TestValidateContainerInsightsQueryParams(t *testing.T) {
+	valid := ContainerInsightsQueryParams{Template: TemplatePodLogs, MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID}
+	if err := ValidateContainerInsightsQueryParams(valid); err != nil {
+		t.Fatalf("Expected valid parameters, got %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(*ContainerInsightsQueryParams)
+		expectError string
+	}{
+		{"unknown template", func(p *ContainerInsightsQueryParams) { p.Template = "custom" }, "invalid template"},
+		{"injection in namespace", func(p *ContainerInsightsQueryParams) { p.Namespace = "shop' or 1==1 //" }, "invalid namespace"},
+		{"uppercase namespace", func(p *ContainerInsightsQueryParams) { p.Namespace = "Shop" }, "invalid namespace"},
+		{"namespace too long", func(p *ContainerInsightsQueryParams) { p.Namespace = strings.Repeat("a", 64) }, "invalid namespace"},
+		{"invalid pod name", func(p *ContainerInsightsQueryParams) { p.PodName = "api pod" }, "invalid pod_name"},
+		{"invalid container", func(p *ContainerInsightsQueryParams) { p.Container = "api.v1" }, "invalid container"},
+		{"filter not supported by template", func(p *ContainerInsightsQueryParams) { p.Reason = "BackOff" }, "not supported by template pod_logs"},
+		{"text match too long", func(p *ContainerInsightsQueryParams) { p.TextMatch = strings.Repeat("x", MaxTextMatchLength+1) }, "cannot exceed"},
+		{"control characters in text match", func(p *ContainerInsightsQueryParams) { p.TextMatch = "error\n| take 5" }, "control characters"},
+		{"max records too low", func(p *ContainerInsightsQueryParams) { p.MaxRecords = 0 }, "at least"},
+		{"max records too high", func(p *ContainerInsightsQueryParams) { p.MaxRecords = MaxMaxRecords + 1 }, "cannot exceed"},
+		{"invalid cluster resource ID", func(p *ContainerInsightsQueryParams) { p.ClusterResourceID = "/subscriptions/x/resourceGroups/y" }, "invalid clusterResourceID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			err := ValidateContainerInsightsQueryParams(p)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+
+	kubeEvents := ContainerInsightsQueryParams{Template: TemplateKubeEvents, Reason: "Back-Off", MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID}
+	if err := ValidateContainerInsightsQueryParams(kubeEvents); err == nil || !strings.Contains(err.Error(), "invalid reason") {
+		t.Errorf("Expected an invalid reason error, got %v", err)
+	}
+	restarts := ContainerInsightsQueryParams{Template: TemplatePodRestarts, Interval: "2h", MaxRecords: 100, ClusterResourceID: testContainerInsightsClusterID}
+	if err := ValidateContainerInsightsQueryParams(restarts); err == nil || !strings.Contains(err.Error(), "invalid interval") {
+		t.Errorf("Expected an invalid interval error, got %v", err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/config"
@@ -50,6 +49,17 @@ func HandleControlPlaneDiagnosticSettings(ctx context.Context, params map[string
 	return string(result), nil
 }
 
+// ControlPlaneLogsResult is the result of a control plane logs query
+type ControlPlaneLogsResult struct {
+	ClusterName  string                   `json:"cluster_name"`
+	LogCategory  string                   `json:"log_category"`
+	WorkspaceID  string                   `json:"workspace_id"`
+	Timespan     string                   `json:"timespan"`
+	Count        int                      `json:"count"`
+	Records      []map[string]interface{} `json:"records"`
+	PartialError string                   `json:"partial_error,omitempty"`
+}
+
 // HandleControlPlaneLogs queries specific control plane logs
 func HandleControlPlaneLogs(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Extract and validate AKS parameters using common helper
@@ -77,8 +87,11 @@ func HandleControlPlaneLogs(ctx context.Context, params map[string]interface{},
 		return "", fmt.Errorf("failed to find diagnostic setting for log category %s in cluster %s: %w", logCategory, clusterName, err)
 	}
 
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
+
 	// Get workspace GUID from the workspace resource ID
-	workspaceGUID, err := getWorkspaceGUID(workspaceResourceID, cfg)
+	workspaceGUID, err := getWorkspaceGUID(ctx, workspaceResourceID, azClient)
 	if err != nil {
 		return "", fmt.Errorf("failed to get workspace GUID for cluster %s: %w", clusterName, err)
 	}
@@ -98,27 +111,127 @@ func HandleControlPlaneLogs(ctx context.Context, params map[string]interface{},
 		return "", fmt.Errorf("failed to calculate timespan: %w", err)
 	}
 
-	// Execute log query with properly quoted KQL
-	executor := azcli.NewExecutor()
+	logger.Debugf("Executing KQL query against workspace %s (timespan %s): %s", workspaceGUID, timespan, kqlQuery)
+
+	logs, err := azClient.QueryLogAnalyticsWorkspace(ctx, workspaceGUID, kqlQuery, timespan)
+	if err != nil {
+		return "", fmt.Errorf("failed to query control plane logs for category %s in cluster %s: %w", logCategory, clusterName, err)
+	}
+
+	records := azureclient.LogsRecords(logs)
+	result := ControlPlaneLogsResult{
+		ClusterName: clusterName,
+		LogCategory: logCategory,
+		WorkspaceID: workspaceGUID,
+		Timespan:    timespan,
+		Count:       len(records),
+		Records:     records,
+	}
+	if logs.Error != nil {
+		result.PartialError = logs.Error.Error()
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal control plane logs to JSON: %w", err)
+	}
+
+	return string(resultJSON), nil
+}
 
-	// Build command string with proper quoting for the KQL query
-	cmd := fmt.Sprintf("az monitor log-analytics query --workspace %s --analytics-query \"%s\" --timespan %s --output json",
-		workspaceGUID, kqlQuery, timespan)
+// ContainerInsightsResult is the result of a Container Insights query template
+type ContainerInsightsResult struct {
+	ClusterName         string                   `json:"cluster_name"`
+	Template            string                   `json:"template"`
+	WorkspaceID         string                   `json:"workspace_id"`
+	WorkspaceResourceID string                   `json:"workspace_resource_id"`
+	WorkspaceSource     string                   `json:"workspace_source"`
+	Query               string                   `json:"query"`
+	Timespan            string                   `json:"timespan"`
+	Count               int                      `json:"count"`
+	Records             []map[string]interface{} `json:"records"`
+	PartialError        string                   `json:"partial_error,omitempty"`
+}
 
-	// Log the query command for debugging
-	logger.Debugf("Executing KQL query command: %s", cmd)
+// HandleContainerInsightsQuery runs a Container Insights query template against the Log Analytics
+// workspace of the cluster's monitoring addon or data collection rule
+func HandleContainerInsightsQuery(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Validate parameters
+	if err := ValidateContainerInsightsParams(params); err != nil {
+		return "", err
+	}
 
-	cmdParams := map[string]interface{}{
-		"command": cmd,
+	subscriptionID, resourceGroup, clusterName, _ := common.ExtractAKSParameters(params)
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+
+	// Build and validate the query before any Azure call
+	queryParams := ContainerInsightsQueryParams{
+		Template:          params["template"].(string),
+		MaxRecords:        GetMaxRecords(params),
+		ClusterResourceID: buildClusterResourceID(subscriptionID, resourceGroup, clusterName),
 	}
+	queryParams.Namespace, _ = params["namespace"].(string)
+	queryParams.PodName, _ = params["pod_name"].(string)
+	queryParams.Container, _ = params["container"].(string)
+	queryParams.NodeName, _ = params["node_name"].(string)
+	queryParams.Reason, _ = params["reason"].(string)
+	queryParams.TextMatch, _ = params["contains"].(string)
+	queryParams.Interval, _ = params["interval"].(string)
 
-	result, err := executor.Execute(ctx, cmdParams, cfg)
+	kqlQuery, err := BuildContainerInsightsQuery(queryParams)
 	if err != nil {
-		return "", fmt.Errorf("failed to query control plane logs for category %s in cluster %s: %w", logCategory, clusterName, err)
+		return "", fmt.Errorf("failed to build Container Insights query for cluster %s: %w", clusterName, err)
+	}
+
+	// Calculate timespan for the query
+	timespan, err := CalculateTimespan(startTime, endTime)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate timespan: %w", err)
+	}
+
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
+
+	workspaceResourceID, source, err := FindContainerInsightsWorkspace(ctx, subscriptionID, resourceGroup, clusterName, azClient)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceGUID, err := getWorkspaceGUID(ctx, workspaceResourceID, azClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workspace GUID for cluster %s: %w", clusterName, err)
+	}
+
+	logger.Debugf("Executing Container Insights query against workspace %s (timespan %s): %s", workspaceGUID, timespan, kqlQuery)
+
+	logs, err := azClient.QueryLogAnalyticsWorkspace(ctx, workspaceGUID, kqlQuery, timespan)
+	if err != nil {
+		return "", fmt.Errorf("failed to run Container Insights template %s for cluster %s: %w", queryParams.Template, clusterName, err)
 	}
 
-	// Return raw JSON result from Azure CLI
-	return result, nil
+	records := azureclient.LogsRecords(logs)
+	result := ContainerInsightsResult{
+		ClusterName:         clusterName,
+		Template:            queryParams.Template,
+		WorkspaceID:         workspaceGUID,
+		WorkspaceResourceID: workspaceResourceID,
+		WorkspaceSource:     source,
+		Query:               kqlQuery,
+		Timespan:            timespan,
+		Count:               len(records),
+		Records:             records,
+	}
+	if logs.Error != nil {
+		result.PartialError = logs.Error.Error()
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Container Insights result to JSON: %w", err)
+	}
+
+	return string(resultJSON), nil
 }
 
 // Resource handler functions for control plane diagnostics tools
@@ -136,3 +249,10 @@ func GetControlPlaneLogsHandler(azClient *azureclient.AzureClient, cfg *config.C
 		return HandleControlPlaneLogs(ctx, params, azClient, cfg)
 	})
 }
+
+// GetContainerInsightsHandler returns handler for the Container Insights query tool
+func GetContainerInsightsHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleContainerInsightsQuery(ctx, params, azClient, cfg)
+	})
+}
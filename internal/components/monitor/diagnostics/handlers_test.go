@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/security"
@@ -159,3 +160,44 @@ func TestHandleControlPlaneLogs_ParameterValidation(t *testing.T) {
 		t.Errorf("Expected validation error, got: %v", err)
 	}
 }
+
+func TestHandleContainerInsightsQuery_ParameterValidation(t *testing.T) {
+	cfg := &config.ConfigData{
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: "readonly",
+		},
+	}
+	base := map[string]interface{}{
+		"subscription_id": "12345678-1234-1234-1234-123456789012",
+		"resource_group":  "test-rg",
+		"cluster_name":    "test-cluster",
+		"start_time":      time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}
+
+	tests := []struct {
+		name     string
+		extra    map[string]interface{}
+		errorMsg string
+	}{
+		{"missing template", map[string]interface{}{}, "missing or invalid template"},
+		{"unknown template", map[string]interface{}{"template": "custom"}, "invalid template"},
+		{"invalid filter", map[string]interface{}{"template": "pod_logs", "namespace": "kube-system' | take 1"}, "invalid namespace"},
+		// A valid query reaches workspace discovery, which needs an Azure client
+		{"valid query", map[string]interface{}{"template": "pod_logs", "namespace": "kube-system"}, "azure client is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{}
+			for key, value := range base {
+				params[key] = value
+			}
+			for key, value := range tt.extra {
+				params[key] = value
+			}
+			_, err := HandleContainerInsightsQuery(context.Background(), params, nil, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -50,6 +50,7 @@ type KQLQueryBuilder struct {
 	tableMode           TableMode // Specifies the mode of the table being queried (e.g., AzureDiagnosticsMode or ResourceSpecificMode).
 	selectedTable       string    // The name of the table selected for the query.
 	processedResourceID string    // The processed resource ID used in the query.
+	messageTerms        []string  // Terms of which log lines must contain at least one, empty for all lines.
 }
 
 // TableMode represents the type of table being used
@@ -74,6 +75,9 @@ const (
 	DefaultKQLMaxRecords = 100
 )
 
+// messageTermPattern matches message terms that can be embedded in a KQL string literal
+var messageTermPattern = regexp.MustCompile(`^[A-Za-z0-9 _.:-]{1,64}$`)
+
 // azureResourceIDPattern matches Azure resource IDs (case-insensitive, allows test IDs)
 var azureResourceIDPattern = regexp.MustCompile(`(?i)^/subscriptions/[a-zA-Z0-9-]+/resourcegroups?/[^/]+/providers/microsoft\.containerservice/managedclusters/[^/]+$`)
 
@@ -134,6 +138,21 @@ func NewKQLQueryBuilder(category, logLevel string, maxRecords int, clusterResour
 	}, nil
 }
 
+// WithMessageTerms restricts the query to log lines containing any of the terms. The filter runs
+// before the limit, so that the records returned are the latest matching lines.
+func (q *KQLQueryBuilder) WithMessageTerms(terms ...string) error {
+	if q.isAuditCategory() {
+		return fmt.Errorf("message terms are not supported for audit category '%s'", q.category)
+	}
+	for _, term := range terms {
+		if !messageTermPattern.MatchString(term) {
+			return fmt.Errorf("invalid message term '%s': only letters, digits, spaces and _.:- are allowed", term)
+		}
+	}
+	q.messageTerms = terms
+	return nil
+}
+
 // determineTableStrategy decides which table to use and processes the resource ID accordingly
 func (q *KQLQueryBuilder) determineTableStrategy() error {
 	if q.tableMode == ResourceSpecificMode {
@@ -207,6 +226,23 @@ func (q *KQLQueryBuilder) addLogLevelFilter(baseQuery string) string {
 	}
 }
 
+// addMessageFilter adds the message terms filter if applicable
+func (q *KQLQueryBuilder) addMessageFilter(baseQuery string) string {
+	if len(q.messageTerms) == 0 {
+		return baseQuery
+	}
+
+	quoted := make([]string, 0, len(q.messageTerms))
+	for _, term := range q.messageTerms {
+		quoted = append(quoted, fmt.Sprintf("'%s'", term))
+	}
+	field := "log_s"
+	if q.tableMode == ResourceSpecificMode {
+		field = "Message"
+	}
+	return baseQuery + fmt.Sprintf(" | where %s has_any (%s)", field, strings.Join(quoted, ", "))
+}
+
 // addOrderingAndLimit adds the ordering and limit clauses
 func (q *KQLQueryBuilder) addOrderingAndLimit(query string) string {
 	query += " | order by TimeGenerated desc"
@@ -257,10 +293,13 @@ func (q *KQLQueryBuilder) Build() (string, error) {
 	// Step 3: Add log level filtering
 	query = q.addLogLevelFilter(query)
 
-	// Step 4: Add ordering and limit
+	// Step 4: Add message terms filtering
+	query = q.addMessageFilter(query)
+
+	// Step 5: Add ordering and limit
 	query = q.addOrderingAndLimit(query)
 
-	// Step 5: Add field projection
+	// Step 6: Add field projection
 	query = q.addProjection(query)
 
 	return query, nil
@@ -1494,3 +1494,62 @@ func TestBuildSafeKQLQueryErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+// TestKQLQueryBuilder_WithMessageTerms tests that message terms are filtered before the limit
+func TestKQLQueryBuilder_WithMessageTerms(t *testing.T) {
+	testResourceID := "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.ContainerService/managedClusters/myCluster"
+
+	tests := []struct {
+		name      string
+		tableMode TableMode
+		expected  string
+	}{
+		{
+			name:      "azure diagnostics",
+			tableMode: AzureDiagnosticsMode,
+			expected:  "| where log_s has_any ('scale-up', 'Removing') | order by TimeGenerated desc | limit 1000",
+		},
+		{
+			name:      "resource specific",
+			tableMode: ResourceSpecificMode,
+			expected:  "| where Message has_any ('scale-up', 'Removing') | order by TimeGenerated desc | limit 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := NewKQLQueryBuilder("cluster-autoscaler", "", MaxMaxRecords, testResourceID, tt.tableMode)
+			if err != nil {
+				t.Fatalf("Failed to create builder: %v", err)
+			}
+			if err := builder.WithMessageTerms("scale-up", "Removing"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			query, err := builder.Build()
+			if err != nil {
+				t.Fatalf("Build failed: %v", err)
+			}
+			if !strings.Contains(query, tt.expected) {
+				t.Errorf("Expected query to contain %q, got %q", tt.expected, query)
+			}
+		})
+	}
+
+	builder, err := NewKQLQueryBuilder("cluster-autoscaler", "", 100, testResourceID, AzureDiagnosticsMode)
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+	for _, term := range []string{"x') | take 1 //", "a\"b", ""} {
+		if err := builder.WithMessageTerms(term); err == nil {
+			t.Errorf("Expected error for message term %q", term)
+		}
+	}
+
+	audit, err := NewKQLQueryBuilder("kube-audit", "", 100, testResourceID, ResourceSpecificMode)
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+	if err := audit.WithMessageTerms("delete"); err == nil {
+		t.Errorf("Expected error for message terms on an audit category")
+	}
+}
@@ -2,6 +2,7 @@ package diagnostics
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -87,6 +88,33 @@ func ValidateControlPlaneLogsParams(params map[string]interface{}) error {
 	return nil
 }
 
+// ValidateContainerInsightsParams validates the parameters of a Container Insights query. The
+// template filters are validated by the query builder.
+func ValidateContainerInsightsParams(params map[string]interface{}) error {
+	// Validate AKS parameters using common helper
+	_, _, _, err := common.ExtractAKSParameters(params)
+	if err != nil {
+		return err
+	}
+
+	// Validate remaining required parameters
+	required := []string{"template", "start_time"}
+	for _, param := range required {
+		if value, ok := params[param].(string); !ok || value == "" {
+			return fmt.Errorf("missing or invalid %s parameter", param)
+		}
+	}
+
+	// Validate template
+	template := params["template"].(string)
+	if !slices.Contains(GetSupportedContainerInsightsTemplates(), template) {
+		return fmt.Errorf("invalid template: %s. Valid templates: %s", template, strings.Join(GetSupportedContainerInsightsTemplates(), ", "))
+	}
+
+	// Validate time range
+	return ValidateTimeRange(params["start_time"].(string), params)
+}
+
 // ValidateTimeRange validates start and end time parameters
 func ValidateTimeRange(startTime string, params map[string]interface{}) error {
 	start, err := time.Parse(time.RFC3339, startTime)
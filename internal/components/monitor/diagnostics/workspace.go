@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/logger"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 )
 
 // ExtractWorkspaceGUIDFromDiagnosticSettings extracts workspace GUID from diagnostic settings
@@ -33,15 +35,15 @@ func ExtractWorkspaceGUIDFromDiagnosticSettings(subscriptionID, resourceGroup, c
 		setting := diagnosticSettings[0]
 		if setting.Properties != nil && setting.Properties.WorkspaceID != nil && *setting.Properties.WorkspaceID != "" {
 			// Extract workspace GUID from the workspace resource ID
-			return getWorkspaceGUID(*setting.Properties.WorkspaceID, cfg)
+			return getWorkspaceGUID(ctx, *setting.Properties.WorkspaceID, azClient)
 		}
 	}
 
 	return "", fmt.Errorf("no Log Analytics workspace found in diagnostic settings")
 }
 
-// getWorkspaceGUID extracts the workspace GUID from a workspace resource ID
-func getWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (string, error) {
+// getWorkspaceGUID resolves the workspace GUID (customer ID) of a workspace resource ID
+func getWorkspaceGUID(ctx context.Context, workspaceResourceID string, azClient *azureclient.AzureClient) (string, error) {
 	// Parse the workspace resource ID to extract resource group and workspace name
 	// Format: /subscriptions/{sub}/resourcegroups/{rg}/providers/microsoft.operationalinsights/workspaces/{workspace-name}
 	parts := strings.Split(workspaceResourceID, "/")
@@ -63,23 +65,14 @@ func getWorkspaceGUID(workspaceResourceID string, cfg *config.ConfigData) (strin
 		return "", fmt.Errorf("could not extract resource group and workspace name from: %s", workspaceResourceID)
 	}
 
-	// Query the workspace to get its GUID (customerId)
-	executor := azcli.NewExecutor()
-	cmd := fmt.Sprintf("az monitor log-analytics workspace show --resource-group %s --workspace-name %s --query customerId --output tsv", resourceGroup, workspaceName)
-
-	cmdParams := map[string]interface{}{
-		"command": cmd,
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
 	}
 
-	result, err := executor.Execute(context.Background(), cmdParams, cfg)
+	// Query the workspace to get its GUID (customerId)
+	workspaceGUID, err := azClient.GetLogAnalyticsWorkspaceCustomerID(ctx, workspaceResourceID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get workspace GUID: %w", err)
-	}
-
-	// The result should be the workspace GUID, trim any whitespace
-	workspaceGUID := strings.TrimSpace(result)
-	if workspaceGUID == "" {
-		return "", fmt.Errorf("empty workspace GUID returned for workspace: %s", workspaceName)
+		return "", fmt.Errorf("failed to get workspace GUID for workspace %s: %w", workspaceName, err)
 	}
 
 	return workspaceGUID, nil
@@ -149,3 +142,103 @@ func FindDiagnosticSettingForCategory(subscriptionID, resourceGroup, clusterName
 
 	return "", false, fmt.Errorf("no diagnostic setting found with log category '%s' enabled", logCategory)
 }
+
+// Sources of the Container Insights workspace
+const (
+	WorkspaceSourceMonitoringAddon    = "monitoring_addon"
+	WorkspaceSourceDataCollectionRule = "data_collection_rule"
+)
+
+// containerInsightsStreamPrefixes identify the data flows of Container Insights, e.g.
+// Microsoft-ContainerInsights-Group-Default or Microsoft-ContainerLogV2
+var containerInsightsStreamPrefixes = []string{"Microsoft-ContainerInsights", "Microsoft-ContainerLog", "Microsoft-Kube"}
+
+// FindContainerInsightsWorkspace finds the Log Analytics workspace receiving the Container Insights
+// data of a cluster, from the monitoring addon or else from the cluster's data collection rules.
+// Returns the workspace resource ID and where it was found.
+func FindContainerInsightsWorkspace(ctx context.Context, subscriptionID, resourceGroup, clusterName string, azClient *azureclient.AzureClient) (string, string, error) {
+	// Azure client is required
+	if azClient == nil {
+		return "", "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, azClient, subscriptionID, resourceGroup, clusterName)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get cluster details: %w", err)
+	}
+	if workspaceResourceID := containerInsightsWorkspaceFromAddon(cluster); workspaceResourceID != "" {
+		return workspaceResourceID, WorkspaceSourceMonitoringAddon, nil
+	}
+
+	clusterResourceID := buildClusterResourceID(subscriptionID, resourceGroup, clusterName)
+	rules, err := azClient.ListDataCollectionRulesForResource(ctx, subscriptionID, clusterResourceID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get data collection rules: %w", err)
+	}
+	if workspaceResourceID := containerInsightsWorkspaceFromRules(rules); workspaceResourceID != "" {
+		return workspaceResourceID, WorkspaceSourceDataCollectionRule, nil
+	}
+
+	return "", "", fmt.Errorf("no Container Insights workspace found for cluster %s: the monitoring addon has no workspace and no data collection rule sends Container Insights data to Log Analytics", clusterName)
+}
+
+// containerInsightsWorkspaceFromAddon returns the workspace configured in the monitoring addon
+func containerInsightsWorkspaceFromAddon(cluster *armcontainerservice.ManagedCluster) string {
+	if cluster == nil || cluster.Properties == nil {
+		return ""
+	}
+	for name, addon := range cluster.Properties.AddonProfiles {
+		if !strings.EqualFold(name, "omsagent") || addon == nil || addon.Enabled == nil || !*addon.Enabled {
+			continue
+		}
+		for key, value := range addon.Config {
+			if strings.EqualFold(key, "logAnalyticsWorkspaceResourceID") && value != nil && *value != "" {
+				return *value
+			}
+		}
+	}
+	return ""
+}
+
+// containerInsightsWorkspaceFromRules returns the Log Analytics destination of the first data flow
+// carrying Container Insights streams
+func containerInsightsWorkspaceFromRules(rules []*armmonitor.DataCollectionRuleResource) string {
+	for _, rule := range rules {
+		if rule == nil || rule.Properties == nil || rule.Properties.Destinations == nil {
+			continue
+		}
+		workspaces := map[string]string{}
+		for _, destination := range rule.Properties.Destinations.LogAnalytics {
+			if destination != nil && destination.Name != nil && destination.WorkspaceResourceID != nil {
+				workspaces[*destination.Name] = *destination.WorkspaceResourceID
+			}
+		}
+
+		for _, flow := range rule.Properties.DataFlows {
+			if flow == nil || !hasContainerInsightsStream(flow.Streams) {
+				continue
+			}
+			for _, destination := range flow.Destinations {
+				if destination != nil && workspaces[*destination] != "" {
+					return workspaces[*destination]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hasContainerInsightsStream checks whether a data flow carries Container Insights data
+func hasContainerInsightsStream(streams []*armmonitor.KnownDataFlowStreams) bool {
+	for _, stream := range streams {
+		if stream == nil {
+			continue
+		}
+		for _, prefix := range containerInsightsStreamPrefixes {
+			if strings.HasPrefix(string(*stream), prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
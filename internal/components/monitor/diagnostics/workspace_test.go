@@ -1,11 +1,15 @@
 package diagnostics
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/security"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 )
 
 func TestGetWorkspaceGUID(t *testing.T) {
@@ -30,24 +34,20 @@ func TestGetWorkspaceGUID(t *testing.T) {
 		{
 			name:                "valid resource ID format structure",
 			workspaceResourceID: "/subscriptions/test/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/workspace",
-			wantError:           true, // Will fail at Azure CLI execution level in test
+			wantError:           true,
+			errorMsg:            "azure client is required",
 		},
 		{
 			name:                "case insensitive resource ID parsing",
 			workspaceResourceID: "/subscriptions/test/RESOURCEGROUPS/rg/providers/microsoft.operationalinsights/WORKSPACES/workspace",
-			wantError:           true, // Will fail at Azure CLI execution level in test
-		},
-	}
-
-	cfg := &config.ConfigData{
-		SecurityConfig: &security.SecurityConfig{
-			AccessLevel: "readonly",
+			wantError:           true,
+			errorMsg:            "azure client is required",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := getWorkspaceGUID(tt.workspaceResourceID, cfg)
+			_, err := getWorkspaceGUID(context.Background(), tt.workspaceResourceID, nil)
 			if tt.wantError {
 				if err == nil {
 					t.Errorf("Expected error but got none")
@@ -69,18 +69,11 @@ func TestGetWorkspaceGUID_ResourceIDParsing(t *testing.T) {
 	// Test that we can properly extract resource group and workspace name from resource ID
 	validResourceID := "/subscriptions/12345/resourceGroups/test-rg/providers/Microsoft.OperationalInsights/workspaces/test-workspace"
 
-	cfg := &config.ConfigData{
-		SecurityConfig: &security.SecurityConfig{
-			AccessLevel: "readonly",
-		},
-	}
+	// Without an Azure client this fails after parsing, so a parsing error means the ID was rejected
+	_, err := getWorkspaceGUID(context.Background(), validResourceID, nil)
 
-	// This will fail at Azure CLI execution but we can check that parsing doesn't fail immediately
-	_, err := getWorkspaceGUID(validResourceID, cfg)
-
-	// Should get an Azure CLI execution error, not a parsing error
-	if err != nil && strings.Contains(err.Error(), "invalid workspace resource ID format") {
-		t.Errorf("Expected Azure CLI execution error, got parsing error: %v", err)
+	if err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected azure client error after successful parsing, got: %v", err)
 	}
 }
 
@@ -126,11 +119,6 @@ func TestExtractWorkspaceGUIDFromDiagnosticSettings_JSONParsing(t *testing.T) {
 }
 
 func TestGetWorkspaceGUID_EdgeCases(t *testing.T) {
-	cfg := &config.ConfigData{
-		SecurityConfig: &security.SecurityConfig{
-			AccessLevel: "readonly",
-		},
-	}
 
 	testCases := []struct {
 		name        string
@@ -161,7 +149,7 @@ func TestGetWorkspaceGUID_EdgeCases(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := getWorkspaceGUID(tc.resourceID, cfg)
+			_, err := getWorkspaceGUID(context.Background(), tc.resourceID, nil)
 			if err == nil {
 				t.Errorf("Expected error for case '%s', got nil", tc.name)
 				return
@@ -190,19 +178,19 @@ func TestFindDiagnosticSettingForCategory(t *testing.T) {
 		{
 			name:          "kube-apiserver category",
 			logCategory:   "kube-apiserver",
-			expectError:   true, // Will fail during Azure CLI execution in test environment
+			expectError:   true, // Will fail without an Azure client in test environment
 			expectedError: "",
 		},
 		{
 			name:          "kube-audit category",
 			logCategory:   "kube-audit",
-			expectError:   true, // Will fail during Azure CLI execution in test environment
+			expectError:   true, // Will fail without an Azure client in test environment
 			expectedError: "",
 		},
 		{
 			name:          "invalid category",
 			logCategory:   "invalid-category",
-			expectError:   true, // Will fail during Azure CLI execution in test environment
+			expectError:   true, // Will fail without an Azure client in test environment
 			expectedError: "",
 		},
 	}
@@ -313,7 +301,7 @@ func TestFindDiagnosticSettingForCategory_JSONStructureEdgeCases(t *testing.T) {
 		t.Run("cluster_name_with_special_chars", func(t *testing.T) {
 			_, _, err := FindDiagnosticSettingForCategory("test-sub", "test-rg", invalidCluster, "kube-apiserver", nil, cfg)
 
-			// Should get an error (likely from Azure CLI execution)
+			// Should get an error (no Azure client is provided)
 			if err == nil {
 				t.Errorf("Expected error for cluster name with special characters, got nil")
 			}
@@ -441,7 +429,7 @@ func TestFindDiagnosticSettingForCategory_ErrorPathCoverage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_, _, err := FindDiagnosticSettingForCategory(tt.subscriptionID, tt.resourceGroup, tt.clusterName, tt.logCategory, nil, cfg)
 
-			// All these cases should result in errors (either from parameter validation or Azure CLI execution)
+			// All these cases should result in errors (either from parameter validation or the missing Azure client)
 			if err == nil {
 				t.Errorf("Expected error for %s, got nil", tt.description)
 				return
@@ -510,3 +498,64 @@ func TestFindDiagnosticSettingForCategory_ConcurrencyAndStress(t *testing.T) {
 		}
 	}
 }
+
+func TestContainerInsightsWorkspaceFromAddon(t *testing.T) {
+	workspaceID := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/ws"
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AddonProfiles: map[string]*armcontainerservice.ManagedClusterAddonProfile{
+				"omsAgent": {
+					Enabled: to.Ptr(true),
+					Config:  map[string]*string{"logAnalyticsWorkspaceResourceID": to.Ptr(workspaceID)},
+				},
+			},
+		},
+	}
+	if got := containerInsightsWorkspaceFromAddon(cluster); got != workspaceID {
+		t.Errorf("Expected workspace %s, got %q", workspaceID, got)
+	}
+
+	cluster.Properties.AddonProfiles["omsAgent"].Enabled = to.Ptr(false)
+	if got := containerInsightsWorkspaceFromAddon(cluster); got != "" {
+		t.Errorf("Expected no workspace for a disabled addon, got %q", got)
+	}
+	if got := containerInsightsWorkspaceFromAddon(&armcontainerservice.ManagedCluster{}); got != "" {
+		t.Errorf("Expected no workspace without properties, got %q", got)
+	}
+}
+
+func TestContainerInsightsWorkspaceFromRules(t *testing.T) {
+	ciWorkspace := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.OperationalInsights/workspaces/ci"
+	rules := []*armmonitor.DataCollectionRuleResource{
+		{
+			// A syslog rule on another workspace must not be picked
+			Properties: &armmonitor.DataCollectionRuleResourceProperties{
+				Destinations: &armmonitor.DataCollectionRuleDestinations{
+					LogAnalytics: []*armmonitor.LogAnalyticsDestination{{Name: to.Ptr("syslog"), WorkspaceResourceID: to.Ptr("/other")}},
+				},
+				DataFlows: []*armmonitor.DataFlow{{
+					Streams:      []*armmonitor.KnownDataFlowStreams{to.Ptr(armmonitor.KnownDataFlowStreamsMicrosoftSyslog)},
+					Destinations: []*string{to.Ptr("syslog")},
+				}},
+			},
+		},
+		{
+			Properties: &armmonitor.DataCollectionRuleResourceProperties{
+				Destinations: &armmonitor.DataCollectionRuleDestinations{
+					LogAnalytics: []*armmonitor.LogAnalyticsDestination{{Name: to.Ptr("ciworkspace"), WorkspaceResourceID: to.Ptr(ciWorkspace)}},
+				},
+				DataFlows: []*armmonitor.DataFlow{{
+					Streams:      []*armmonitor.KnownDataFlowStreams{to.Ptr(armmonitor.KnownDataFlowStreams("Microsoft-ContainerInsights-Group-Default"))},
+					Destinations: []*string{to.Ptr("ciworkspace")},
+				}},
+			},
+		},
+	}
+
+	if got := containerInsightsWorkspaceFromRules(rules); got != ciWorkspace {
+		t.Errorf("Expected workspace %s, got %q", ciWorkspace, got)
+	}
+	if got := containerInsightsWorkspaceFromRules(rules[:1]); got != "" {
+		t.Errorf("Expected no workspace without Container Insights streams, got %q", got)
+	}
+}
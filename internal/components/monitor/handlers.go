@@ -7,41 +7,41 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Azure/aks-mcp/internal/azcli"
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/aks-mcp/internal/components/common"
 	"github.com/Azure/aks-mcp/internal/components/monitor/diagnostics"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcehealth/armresourcehealth"
 )
 
-// mergeMonitoringParams merges top-level parameters with nested "parameters" JSON string
-func mergeMonitoringParams(params map[string]interface{}) (map[string]interface{}, error) {
-	merged := make(map[string]interface{})
-
-	// Copy all top-level parameters first
-	for key, value := range params {
-		merged[key] = value
-	}
-
-	// Parse and merge parameters from JSON string if present
-	if parametersStr, ok := params["parameters"].(string); ok && parametersStr != "" {
-		var nestedParams map[string]interface{}
-		if err := json.Unmarshal([]byte(parametersStr), &nestedParams); err == nil {
-			// Merge nested parameters, giving precedence to top-level params
-			for key, value := range nestedParams {
-				if _, exists := merged[key]; !exists {
-					merged[key] = value
-				}
-			}
-		}
-	}
+// maxResourceHealthEvents caps the number of resource health events returned by a single query
+const maxResourceHealthEvents = 500
+
+// ResourceHealthResult is the result of a resource health query
+type ResourceHealthResult struct {
+	ResourceID         string                                `json:"resource_id"`
+	StartTime          string                                `json:"start_time"`
+	EndTime            string                                `json:"end_time"`
+	CurrentStatus      *armresourcehealth.AvailabilityStatus `json:"current_status,omitempty"`
+	CurrentStatusError string                                `json:"current_status_error,omitempty"`
+	Count              int                                   `json:"count"`
+	Events             []*armmonitor.EventData               `json:"events"`
+}
 
-	return merged, nil
+// AppInsightsQueryResult is the result of an Application Insights query
+type AppInsightsQueryResult struct {
+	ResourceID   string                   `json:"resource_id"`
+	Query        string                   `json:"query"`
+	Timespan     string                   `json:"timespan,omitempty"`
+	Count        int                      `json:"count"`
+	Records      []map[string]interface{} `json:"records"`
+	PartialError string                   `json:"partial_error,omitempty"`
 }
 
 // HandleResourceHealthQuery handles the resource health query for AKS clusters
-func HandleResourceHealthQuery(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func HandleResourceHealthQuery(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Extract and validate parameters
 	subscriptionID, ok := params["subscription_id"].(string)
 	if !ok || subscriptionID == "" {
@@ -72,44 +72,72 @@ func HandleResourceHealthQuery(ctx context.Context, params map[string]interface{
 		return "", err
 	}
 
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
 	// Build resource ID
 	resourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
 		subscriptionID, resourceGroup, clusterName)
 
-	// Build Azure CLI command
-	executor := azcli.NewExecutor()
-	args := []string{
-		"monitor", "activity-log", "list",
-		"--resource-id", resourceID,
-		"--start-time", startTime,
-		"--query", "[?category.value=='ResourceHealth']",
-		"--output", "json",
-	}
-
-	// Add end time if provided
+	start, _ := time.Parse(time.RFC3339, startTime)
+	end := time.Now().UTC()
 	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		args = append(args, "--end-time", endTime)
+		end, _ = time.Parse(time.RFC3339, endTime)
 	}
+	status, _ := params["status"].(string)
 
-	// Add status filter if provided
-	if status, ok := params["status"].(string); ok && status != "" {
-		// Apply status filter in the query
-		statusFilter := fmt.Sprintf("[?category.value=='ResourceHealth' && properties.currentHealthStatus=='%s']", status)
-		args[len(args)-3] = statusFilter // Replace the query parameter
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
+
+	result := ResourceHealthResult{
+		ResourceID: resourceID,
+		StartTime:  start.Format(time.RFC3339),
+		EndTime:    end.Format(time.RFC3339),
 	}
 
-	// Execute command
-	cmdParams := map[string]interface{}{
-		"command": "az " + strings.Join(args, " "),
+	// The current status is best effort: callers may lack read access to Microsoft.ResourceHealth
+	currentStatus, err := azClient.GetAvailabilityStatus(ctx, resourceID)
+	if err != nil {
+		result.CurrentStatusError = err.Error()
+	} else {
+		result.CurrentStatus = currentStatus
 	}
 
-	result, err := executor.Execute(ctx, cmdParams, cfg)
+	events, err := azClient.ListActivityLogs(ctx, subscriptionID, buildActivityLogFilter(resourceID, start, end),
+		resourceHealthEventFilter(status), maxResourceHealthEvents)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute resource health query: %w", err)
 	}
+	result.Events = events
+	result.Count = len(events)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal resource health result to JSON: %v", err)
+	}
 
-	// Return the raw JSON result from Azure CLI
-	return result, nil
+	return string(resultJSON), nil
+}
+
+// buildActivityLogFilter builds the OData filter selecting activity log events of a resource within a time range
+func buildActivityLogFilter(resourceID string, start, end time.Time) string {
+	return fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceUri eq '%s'",
+		start.Format(time.RFC3339), end.Format(time.RFC3339), resourceID)
+}
+
+// resourceHealthEventFilter matches ResourceHealth activity log events, optionally with the given current health status
+func resourceHealthEventFilter(status string) func(*armmonitor.EventData) bool {
+	return func(event *armmonitor.EventData) bool {
+		if event == nil || event.Category == nil || event.Category.Value == nil || *event.Category.Value != "ResourceHealth" {
+			return false
+		}
+		if status == "" {
+			return true
+		}
+		current, ok := event.Properties["currentHealthStatus"]
+		return ok && current != nil && *current == status
+	}
 }
 
 // validateResourceHealthParams validates the parameters for resource health queries
@@ -122,6 +150,17 @@ func validateResourceHealthParams(params map[string]interface{}) error {
 		}
 	}
 
+	// The subscription and names are embedded in the resource ID of the activity log filter
+	if err := common.ValidateSubscriptionID(params["subscription_id"].(string)); err != nil {
+		return err
+	}
+	if err := common.ValidateResourceGroupName(params["resource_group"].(string)); err != nil {
+		return err
+	}
+	if err := common.ValidateClusterName(params["cluster_name"].(string)); err != nil {
+		return err
+	}
+
 	// Validate time format
 	startTime := params["start_time"].(string)
 	if _, err := time.Parse(time.RFC3339, startTime); err != nil {
@@ -154,14 +193,14 @@ func validateResourceHealthParams(params map[string]interface{}) error {
 }
 
 // GetResourceHealthHandler returns a ResourceHandler for the resource health tool
-func GetResourceHealthHandler(cfg *config.ConfigData) tools.ResourceHandler {
+func GetResourceHealthHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
-		return HandleResourceHealthQuery(ctx, params, cfg)
+		return HandleResourceHealthQuery(ctx, params, azClient, cfg)
 	})
 }
 
 // HandleAppInsightsQuery handles Application Insights telemetry queries for AKS clusters
-func HandleAppInsightsQuery(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func HandleAppInsightsQuery(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Extract and validate parameters
 	subscriptionID, ok := params["subscription_id"].(string)
 	if !ok || subscriptionID == "" {
@@ -192,46 +231,70 @@ func HandleAppInsightsQuery(ctx context.Context, params map[string]interface{},
 		return "", err
 	}
 
+	timespan, err := buildAppInsightsTimespan(params, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
 	// Build Application Insights resource ID
 	appResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Insights/components/%s",
 		subscriptionID, resourceGroup, appInsightsName)
 
-	// Build Azure CLI command
-	executor := azcli.NewExecutor()
-	args := []string{
-		"monitor", "app-insights", "query",
-		"--app", appResourceID,
-		"--analytics-query", query,
-		"--output", "json",
-	}
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
 
-	// Add start time if provided
-	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
-		args = append(args, "--start-time", startTime)
-	}
-
-	// Add end time if provided
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		args = append(args, "--end-time", endTime)
+	logs, err := azClient.QueryAppInsights(ctx, appResourceID, query, timespan)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute Application Insights query: %w", err)
 	}
 
-	// Add timespan if provided
-	if timespan, ok := params["timespan"].(string); ok && timespan != "" {
-		args = append(args, "--timespan", timespan)
+	records := azureclient.LogsRecords(logs)
+	result := AppInsightsQueryResult{
+		ResourceID: appResourceID,
+		Query:      query,
+		Timespan:   timespan,
+		Count:      len(records),
+		Records:    records,
 	}
-
-	// Execute command
-	cmdParams := map[string]interface{}{
-		"command": "az " + strings.Join(args, " "),
+	if logs.Error != nil {
+		result.PartialError = logs.Error.Error()
 	}
 
-	result, err := executor.Execute(ctx, cmdParams, cfg)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to execute Application Insights query: %w", err)
+		return "", fmt.Errorf("failed to marshal Application Insights result to JSON: %v", err)
 	}
 
-	// Return the raw JSON result from Azure CLI
-	return result, nil
+	return string(resultJSON), nil
+}
+
+// buildAppInsightsTimespan converts start_time/end_time or timespan into an ISO 8601 query timespan.
+// An empty result leaves the time range to the query itself.
+func buildAppInsightsTimespan(params map[string]interface{}, now time.Time) (string, error) {
+	startTime, _ := params["start_time"].(string)
+	endTime, _ := params["end_time"].(string)
+	timespan, _ := params["timespan"].(string)
+
+	if timespan != "" {
+		if startTime != "" || endTime != "" {
+			return "", fmt.Errorf("specify either start_time/end_time or timespan, not both")
+		}
+		return timespan, nil
+	}
+	if startTime == "" {
+		if endTime != "" {
+			return "", fmt.Errorf("start_time is required when end_time is specified")
+		}
+		return "", nil
+	}
+	if endTime == "" {
+		endTime = now.Format(time.RFC3339)
+	}
+	return startTime + "/" + endTime, nil
 }
 
 // validateAppInsightsParams validates the parameters for Application Insights queries
@@ -244,6 +307,14 @@ func validateAppInsightsParams(params map[string]interface{}) error {
 		}
 	}
 
+	// The subscription and resource group are embedded in the component resource ID
+	if err := common.ValidateSubscriptionID(params["subscription_id"].(string)); err != nil {
+		return err
+	}
+	if err := common.ValidateResourceGroupName(params["resource_group"].(string)); err != nil {
+		return err
+	}
+
 	// Validate time format for start_time if provided
 	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
 		if _, err := time.Parse(time.RFC3339, startTime); err != nil {
@@ -269,9 +340,9 @@ func validateAppInsightsParams(params map[string]interface{}) error {
 }
 
 // GetAppInsightsHandler returns a ResourceHandler for the Application Insights tool
-func GetAppInsightsHandler(cfg *config.ConfigData) tools.ResourceHandler {
+func GetAppInsightsHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
-		return HandleAppInsightsQuery(ctx, params, cfg)
+		return HandleAppInsightsQuery(ctx, params, azClient, cfg)
 	})
 }
 
@@ -293,15 +364,19 @@ func GetAksMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Conf
 		// Handle different operations
 		switch operation {
 		case string(OpMetrics):
-			return handleMetricsOperation(ctx, params, cfg)
+			return handleMetricsOperation(ctx, params, azClient, cfg)
 		case string(OpResourceHealth):
-			return handleResourceHealthOperation(ctx, params, cfg)
+			return handleResourceHealthOperation(ctx, params, azClient, cfg)
 		case string(OpAppInsights):
-			return handleAppInsightsOperation(ctx, params, cfg)
+			return handleAppInsightsOperation(ctx, params, azClient, cfg)
 		case string(OpDiagnostics):
 			return handleDiagnosticsOperation(ctx, params, azClient, cfg)
 		case string(OpControlPlaneLogs):
 			return handleLogsOperation(ctx, params, azClient, cfg)
+		case string(OpContainerInsights):
+			return handleContainerInsightsOperation(ctx, params, azClient, cfg)
+		case string(OpPrometheus):
+			return handlePrometheusOperation(ctx, params, azClient, cfg)
 		default:
 			return "", fmt.Errorf("operation '%s' not implemented", operation)
 		}
@@ -310,77 +385,31 @@ func GetAksMonitoringHandler(azClient *azureclient.AzureClient, cfg *config.Conf
 
 // Helper functions for different monitoring operations
 
-func handleMetricsOperation(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
-	queryType, ok := params["query_type"].(string)
-	if !ok {
-		return "", fmt.Errorf("missing or invalid 'query_type' parameter for metrics operation")
-	}
-
-	if !ValidateMetricsQueryType(queryType) {
-		return "", fmt.Errorf("invalid query_type: %s. Supported types: list, list-definitions, list-namespaces", queryType)
-	}
-
-	// Extract parameters from JSON string
-	parametersStr, ok := params["parameters"].(string)
-	if !ok {
-		return "", fmt.Errorf("missing or invalid 'parameters' parameter")
-	}
-
-	// Parse JSON parameters and convert to command-line arguments
-	var jsonParams map[string]interface{}
-	if err := json.Unmarshal([]byte(parametersStr), &jsonParams); err != nil {
-		return "", fmt.Errorf("failed to parse parameters JSON: %w", err)
-	}
-
-	// Convert JSON parameters to command-line argument format [--key1 value1 --key2 value2]
-	var args []string
-	for key, value := range jsonParams {
-		args = append(args, "--"+key)
-		args = append(args, fmt.Sprintf("%v", value))
-	}
-
-	args = ensureOutputArg(args)
-
-	// Map query type to command
-	baseCommand, err := MapMetricsQueryTypeToCommand(queryType)
-	if err != nil {
-		return "", err
-	}
-
-	// Execute the command
-	executor := azcli.NewExecutor()
-	cmdParams := map[string]interface{}{
-		"command": baseCommand + " " + strings.Join(args, " "),
-	}
-
-	return executor.Execute(ctx, cmdParams, cfg)
-}
-
-func handleResourceHealthOperation(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleResourceHealthOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Merge parameters from top-level and nested JSON
-	mergedParams, err := mergeMonitoringParams(params)
+	mergedParams, err := common.MergeOperationParams(params)
 	if err != nil {
 		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
 
 	// Use existing resource health handler
-	return GetResourceHealthHandler(cfg).Handle(ctx, mergedParams, cfg)
+	return GetResourceHealthHandler(azClient, cfg).Handle(ctx, mergedParams, cfg)
 }
 
-func handleAppInsightsOperation(ctx context.Context, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+func handleAppInsightsOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Merge parameters from top-level and nested JSON
-	mergedParams, err := mergeMonitoringParams(params)
+	mergedParams, err := common.MergeOperationParams(params)
 	if err != nil {
 		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
 
 	// Use existing app insights handler
-	return GetAppInsightsHandler(cfg).Handle(ctx, mergedParams, cfg)
+	return GetAppInsightsHandler(azClient, cfg).Handle(ctx, mergedParams, cfg)
 }
 
 func handleDiagnosticsOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Merge parameters from top-level and nested JSON
-	mergedParams, err := mergeMonitoringParams(params)
+	mergedParams, err := common.MergeOperationParams(params)
 	if err != nil {
 		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
@@ -391,7 +420,7 @@ func handleDiagnosticsOperation(ctx context.Context, params map[string]interface
 
 func handleLogsOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
 	// Merge parameters from top-level and nested JSON
-	mergedParams, err := mergeMonitoringParams(params)
+	mergedParams, err := common.MergeOperationParams(params)
 	if err != nil {
 		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
@@ -400,21 +429,13 @@ func handleLogsOperation(ctx context.Context, params map[string]interface{}, azC
 	return diagnostics.GetControlPlaneLogsHandler(azClient, cfg).Handle(ctx, mergedParams, cfg)
 }
 
-// ensureOutputArg appends --output json when no explicit output flag is present
-func ensureOutputArg(args []string) []string {
-	for _, arg := range args {
-		lower := strings.ToLower(arg)
-		switch {
-		case lower == "--output":
-			return args
-		case strings.HasPrefix(lower, "--output="):
-			return args
-		case lower == "-o":
-			return args
-		case strings.HasPrefix(lower, "-o") && len(lower) > 2:
-			return args
-		}
+func handleContainerInsightsOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := common.MergeOperationParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
 	}
 
-	return append(args, "--output", "json")
+	// Use Container Insights query handler
+	return diagnostics.GetContainerInsightsHandler(azClient, cfg).Handle(ctx, mergedParams, cfg)
 }
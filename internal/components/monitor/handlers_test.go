@@ -1,60 +1,18 @@
 package monitor
 
 import (
+	"context"
+	"strings"
 	"testing"
-)
-
-func TestEnsureOutputArg(t *testing.T) {
-	tests := []struct {
-		name string
-		in   []string
-		out  []string
-	}{
-		{
-			name: "appends when missing",
-			in:   []string{"--name", "test"},
-			out:  []string{"--name", "test", "--output", "json"},
-		},
-		{
-			name: "no change with long flag",
-			in:   []string{"--name", "test", "--output", "table"},
-			out:  []string{"--name", "test", "--output", "table"},
-		},
-		{
-			name: "no change with equals",
-			in:   []string{"--output=json", "--name", "test"},
-			out:  []string{"--output=json", "--name", "test"},
-		},
-		{
-			name: "no change with short flag",
-			in:   []string{"-o", "table", "--name", "test"},
-			out:  []string{"-o", "table", "--name", "test"},
-		},
-		{
-			name: "no change with combined short flag",
-			in:   []string{"-otable", "--name", "test"},
-			out:  []string{"-otable", "--name", "test"},
-		},
-	}
+	"time"
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			result := ensureOutputArg(tc.in)
-			if len(result) != len(tc.out) {
-				t.Fatalf("length mismatch: got %d, want %d", len(result), len(tc.out))
-			}
-			for i := range tc.out {
-				if result[i] != tc.out[i] {
-					t.Fatalf("index %d mismatch: got %q, want %q", i, result[i], tc.out[i])
-				}
-			}
-		})
-	}
-}
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
 
 func TestHandleAppInsightsQuery_ValidParameters(t *testing.T) {
 	params := map[string]interface{}{
-		"subscription_id":   "test-subscription",
+		"subscription_id":   "11111111-1111-1111-1111-111111111111",
 		"resource_group":    "test-resource-group",
 		"app_insights_name": "test-app-insights",
 		"query":             "requests | where timestamp > ago(1h) | limit 10",
@@ -84,7 +42,7 @@ func TestHandleAppInsightsQuery_MissingParameters(t *testing.T) {
 		{
 			name: "missing resource_group",
 			params: map[string]interface{}{
-				"subscription_id":   "test-sub",
+				"subscription_id":   "11111111-1111-1111-1111-111111111111",
 				"app_insights_name": "test-ai",
 				"query":             "requests | limit 10",
 			},
@@ -92,7 +50,7 @@ func TestHandleAppInsightsQuery_MissingParameters(t *testing.T) {
 		{
 			name: "missing app_insights_name",
 			params: map[string]interface{}{
-				"subscription_id": "test-sub",
+				"subscription_id": "11111111-1111-1111-1111-111111111111",
 				"resource_group":  "test-rg",
 				"query":           "requests | limit 10",
 			},
@@ -100,7 +58,7 @@ func TestHandleAppInsightsQuery_MissingParameters(t *testing.T) {
 		{
 			name: "missing query",
 			params: map[string]interface{}{
-				"subscription_id":   "test-sub",
+				"subscription_id":   "11111111-1111-1111-1111-111111111111",
 				"resource_group":    "test-rg",
 				"app_insights_name": "test-ai",
 			},
@@ -126,7 +84,7 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 		{
 			name: "valid RFC3339 start_time",
 			params: map[string]interface{}{
-				"subscription_id":   "test-sub",
+				"subscription_id":   "11111111-1111-1111-1111-111111111111",
 				"resource_group":    "test-rg",
 				"app_insights_name": "test-ai",
 				"query":             "requests | limit 10",
@@ -137,7 +95,7 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 		{
 			name: "invalid start_time format",
 			params: map[string]interface{}{
-				"subscription_id":   "test-sub",
+				"subscription_id":   "11111111-1111-1111-1111-111111111111",
 				"resource_group":    "test-rg",
 				"app_insights_name": "test-ai",
 				"query":             "requests | limit 10",
@@ -148,7 +106,7 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 		{
 			name: "valid timespan",
 			params: map[string]interface{}{
-				"subscription_id":   "test-sub",
+				"subscription_id":   "11111111-1111-1111-1111-111111111111",
 				"resource_group":    "test-rg",
 				"app_insights_name": "test-ai",
 				"query":             "requests | limit 10",
@@ -159,7 +117,7 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 		{
 			name: "invalid timespan format",
 			params: map[string]interface{}{
-				"subscription_id":   "test-sub",
+				"subscription_id":   "11111111-1111-1111-1111-111111111111",
 				"resource_group":    "test-rg",
 				"app_insights_name": "test-ai",
 				"query":             "requests | limit 10",
@@ -180,3 +138,152 @@ func TestValidateAppInsightsParams_TimeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildAppInsightsTimespan(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr string
+	}{
+		{name: "no time range", params: map[string]interface{}{}, want: ""},
+		{name: "timespan", params: map[string]interface{}{"timespan": "PT1H"}, want: "PT1H"},
+		{name: "start and end", params: map[string]interface{}{"start_time": "2025-01-01T00:00:00Z", "end_time": "2025-01-01T06:00:00Z"}, want: "2025-01-01T00:00:00Z/2025-01-01T06:00:00Z"},
+		{name: "start only defaults end to now", params: map[string]interface{}{"start_time": "2025-01-01T00:00:00Z"}, want: "2025-01-01T00:00:00Z/2025-01-01T12:00:00Z"},
+		{name: "end only", params: map[string]interface{}{"end_time": "2025-01-01T06:00:00Z"}, wantErr: "start_time is required"},
+		{name: "both forms", params: map[string]interface{}{"timespan": "PT1H", "start_time": "2025-01-01T00:00:00Z"}, wantErr: "not both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildAppInsightsTimespan(tt.params, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResourceHealthEventFilter(t *testing.T) {
+	event := func(category, status string) *armmonitor.EventData {
+		return &armmonitor.EventData{
+			Category:   &armmonitor.LocalizableString{Value: to.Ptr(category)},
+			Properties: map[string]*string{"currentHealthStatus": to.Ptr(status)},
+		}
+	}
+
+	all := resourceHealthEventFilter("")
+	if !all(event("ResourceHealth", "Available")) {
+		t.Error("Expected ResourceHealth event to match")
+	}
+	if all(event("Administrative", "Available")) {
+		t.Error("Expected Administrative event not to match")
+	}
+	if all(&armmonitor.EventData{}) {
+		t.Error("Expected event without category not to match")
+	}
+
+	degraded := resourceHealthEventFilter("Degraded")
+	if degraded(event("ResourceHealth", "Available")) {
+		t.Error("Expected Available event not to match Degraded filter")
+	}
+	if !degraded(event("ResourceHealth", "Degraded")) {
+		t.Error("Expected Degraded event to match Degraded filter")
+	}
+}
+
+func TestBuildActivityLogFilter(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := buildActivityLogFilter("/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c", start, start.Add(time.Hour))
+	want := "eventTimestamp ge '2025-01-01T00:00:00Z' and eventTimestamp le '2025-01-01T01:00:00Z' and resourceUri eq '/subscriptions/s/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/c'"
+	if filter != want {
+		t.Errorf("Expected filter %q, got %q", want, filter)
+	}
+}
+
+func TestHandleResourceHealthAndAppInsights_RequireClient(t *testing.T) {
+	healthParams := map[string]interface{}{
+		"subscription_id": "11111111-1111-1111-1111-111111111111",
+		"resource_group":  "rg",
+		"cluster_name":    "cluster",
+		"start_time":      "2025-01-01T00:00:00Z",
+	}
+	if _, err := HandleResourceHealthQuery(context.Background(), healthParams, nil, nil); err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected azure client error, got %v", err)
+	}
+
+	appInsightsParams := map[string]interface{}{
+		"subscription_id":   "11111111-1111-1111-1111-111111111111",
+		"resource_group":    "rg",
+		"app_insights_name": "app",
+		"query":             "requests | take 1",
+	}
+	if _, err := HandleAppInsightsQuery(context.Background(), appInsightsParams, nil, nil); err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected azure client error, got %v", err)
+	}
+}
+
+func TestValidateParams_RejectFilterInjection(t *testing.T) {
+	healthParams := func(resourceGroup, clusterName string) map[string]interface{} {
+		return map[string]interface{}{
+			"subscription_id": "11111111-1111-1111-1111-111111111111",
+			"resource_group":  resourceGroup,
+			"cluster_name":    clusterName,
+			"start_time":      "2025-01-01T00:00:00Z",
+		}
+	}
+	if err := validateResourceHealthParams(healthParams("rg", "cluster")); err != nil {
+		t.Errorf("Expected valid parameters, got %v", err)
+	}
+	if err := validateResourceHealthParams(healthParams("rg' or resourceUri ne '", "cluster")); err == nil || !strings.Contains(err.Error(), "invalid resource group name") {
+		t.Errorf("Expected invalid resource group error, got %v", err)
+	}
+	if err := validateResourceHealthParams(healthParams("rg", "cluster'")); err == nil || !strings.Contains(err.Error(), "invalid cluster name") {
+		t.Errorf("Expected invalid cluster name error, got %v", err)
+	}
+	invalidSubscription := healthParams("rg", "cluster")
+	invalidSubscription["subscription_id"] = "sub' or resourceUri ne '"
+	if err := validateResourceHealthParams(invalidSubscription); err == nil || !strings.Contains(err.Error(), "invalid subscription ID") {
+		t.Errorf("Expected invalid subscription ID error, got %v", err)
+	}
+
+	appInsightsParams := map[string]interface{}{
+		"subscription_id":   "11111111-1111-1111-1111-111111111111",
+		"resource_group":    "rg/../other",
+		"app_insights_name": "app",
+		"query":             "requests | take 1",
+	}
+	if err := validateAppInsightsParams(appInsightsParams); err == nil || !strings.Contains(err.Error(), "invalid resource group name") {
+		t.Errorf("Expected invalid resource group error, got %v", err)
+	}
+
+	appInsightsParams["resource_group"] = "rg"
+	appInsightsParams["subscription_id"] = "sub/resourceGroups/other"
+	if err := validateAppInsightsParams(appInsightsParams); err == nil || !strings.Contains(err.Error(), "invalid subscription ID") {
+		t.Errorf("Expected invalid subscription ID error, got %v", err)
+	}
+}
+
+func TestAksMonitoringHandler_InvalidParametersJSON(t *testing.T) {
+	params := map[string]interface{}{
+		"operation":       "resource_health",
+		"subscription_id": "11111111-1111-1111-1111-111111111111",
+		"resource_group":  "rg",
+		"cluster_name":    "cluster",
+		"parameters":      `{"start_time": `,
+	}
+	_, err := GetAksMonitoringHandler(nil, nil).Handle(context.Background(), params, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to parse parameters JSON") {
+		t.Errorf("Expected parameters JSON error, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// defaultMetricsWindow is the time range queried when no start-time is given
+const defaultMetricsWindow = time.Hour
+
+// supportedAggregations lists the aggregation types accepted by the metrics API
+var supportedAggregations = []string{"Average", "Count", "Maximum", "Minimum", "Total"}
+
+// supportedMetricsParameters lists the keys accepted in the metrics parameters JSON
+var supportedMetricsParameters = []string{
+	"resource", "metrics", "aggregation", "start-time", "end-time", "interval", "filter", "namespace", "top", "orderby",
+}
+
+// intervalShorthandPattern matches interval shorthands such as 5m, 1h or 1d
+var intervalShorthandPattern = regexp.MustCompile(`^(\d+)([mhd])$`)
+
+// metricsQuery holds the validated parameters of a metrics operation
+type metricsQuery struct {
+	ResourceID     string
+	SubscriptionID string
+	Metrics        []string
+	Aggregations   []string
+	StartTime      time.Time
+	EndTime        time.Time
+	Interval       string
+	Filter         string
+	Namespace      string
+	OrderBy        string
+	Top            int32
+	hasStartTime   bool
+}
+
+// handleMetricsOperation queries Azure Monitor metrics through the armmonitor SDK
+func handleMetricsOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	queryType, ok := params["query_type"].(string)
+	if !ok {
+		return "", fmt.Errorf("missing or invalid 'query_type' parameter for metrics operation")
+	}
+
+	if !ValidateMetricsQueryType(queryType) {
+		return "", fmt.Errorf("invalid query_type: %s. Supported types: list, list-definitions, list-namespaces", queryType)
+	}
+
+	// Extract parameters from JSON string
+	parametersStr, ok := params["parameters"].(string)
+	if !ok {
+		return "", fmt.Errorf("missing or invalid 'parameters' parameter")
+	}
+
+	var jsonParams map[string]interface{}
+	if err := json.Unmarshal([]byte(parametersStr), &jsonParams); err != nil {
+		return "", fmt.Errorf("failed to parse parameters JSON: %w", err)
+	}
+
+	query, err := parseMetricsParameters(jsonParams, queryType, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
+
+	var result interface{}
+	switch queryType {
+	case "list":
+		result, err = azClient.ListMetrics(ctx, query.SubscriptionID, query.ResourceID, query.listOptions())
+	case "list-definitions":
+		result, err = azClient.ListMetricDefinitions(ctx, query.SubscriptionID, query.ResourceID, query.Namespace)
+	case "list-namespaces":
+		startTime := ""
+		if query.hasStartTime {
+			startTime = query.StartTime.Format(time.RFC3339)
+		}
+		result, err = azClient.ListMetricNamespaces(ctx, query.SubscriptionID, query.ResourceID, startTime)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query metrics for %s: %w", query.ResourceID, err)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metrics result to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// parseMetricsParameters validates the metrics parameters JSON for the given query type.
+// Keys may use either hyphens or underscores, e.g. start-time or start_time.
+func parseMetricsParameters(raw map[string]interface{}, queryType string, now time.Time) (*metricsQuery, error) {
+	values := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		normalized := strings.ReplaceAll(strings.ToLower(key), "_", "-")
+		if !slices.Contains(supportedMetricsParameters, normalized) {
+			return nil, fmt.Errorf("unsupported metrics parameter '%s'. Supported parameters: %s", key, strings.Join(supportedMetricsParameters, ", "))
+		}
+		values[normalized] = value
+	}
+
+	query := &metricsQuery{}
+
+	resource, err := stringParameter(values, "resource")
+	if err != nil {
+		return nil, err
+	}
+	if resource == "" {
+		return nil, fmt.Errorf("missing required metrics parameter 'resource'")
+	}
+	parsed, err := arm.ParseResourceID(resource)
+	if err != nil || parsed.SubscriptionID == "" {
+		return nil, fmt.Errorf("invalid resource ID: %s", resource)
+	}
+	query.ResourceID = resource
+	query.SubscriptionID = parsed.SubscriptionID
+
+	if query.Metrics, err = listParameter(values, "metrics", ","); err != nil {
+		return nil, err
+	}
+	if queryType == "list" && len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("missing required metrics parameter 'metrics' for query_type 'list'")
+	}
+
+	aggregations, err := listParameter(values, "aggregation", ", ")
+	if err != nil {
+		return nil, err
+	}
+	for _, aggregation := range aggregations {
+		idx := slices.IndexFunc(supportedAggregations, func(s string) bool { return strings.EqualFold(s, aggregation) })
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid aggregation '%s'. Supported aggregations: %s", aggregation, strings.Join(supportedAggregations, ", "))
+		}
+		query.Aggregations = append(query.Aggregations, supportedAggregations[idx])
+	}
+
+	if err := query.parseTimeRange(values, now); err != nil {
+		return nil, err
+	}
+
+	interval, err := stringParameter(values, "interval")
+	if err != nil {
+		return nil, err
+	}
+	if query.Interval, err = normalizeInterval(interval); err != nil {
+		return nil, err
+	}
+
+	if query.Filter, err = stringParameter(values, "filter"); err != nil {
+		return nil, err
+	}
+	if query.Namespace, err = stringParameter(values, "namespace"); err != nil {
+		return nil, err
+	}
+	if query.OrderBy, err = stringParameter(values, "orderby"); err != nil {
+		return nil, err
+	}
+
+	top, err := stringParameter(values, "top")
+	if err != nil {
+		return nil, err
+	}
+	if top != "" {
+		n, err := strconv.ParseInt(top, 10, 32)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid top '%s', must be a positive integer", top)
+		}
+		query.Top = int32(n)
+	}
+
+	return query, nil
+}
+
+// parseTimeRange resolves start-time and end-time, defaulting to the last hour
+func (q *metricsQuery) parseTimeRange(values map[string]interface{}, now time.Time) error {
+	startTime, err := stringParameter(values, "start-time")
+	if err != nil {
+		return err
+	}
+	endTime, err := stringParameter(values, "end-time")
+	if err != nil {
+		return err
+	}
+
+	q.EndTime = now
+	if endTime != "" {
+		if q.EndTime, err = time.Parse(time.RFC3339, endTime); err != nil {
+			return fmt.Errorf("invalid end-time format, expected RFC3339 (ISO 8601): %w", err)
+		}
+	}
+
+	q.StartTime = q.EndTime.Add(-defaultMetricsWindow)
+	if startTime != "" {
+		if q.StartTime, err = time.Parse(time.RFC3339, startTime); err != nil {
+			return fmt.Errorf("invalid start-time format, expected RFC3339 (ISO 8601): %w", err)
+		}
+		q.hasStartTime = true
+	}
+
+	if !q.StartTime.Before(q.EndTime) {
+		return fmt.Errorf("start-time must be before end-time")
+	}
+	return nil
+}
+
+// listOptions converts the query into armmonitor list options
+func (q *metricsQuery) listOptions() *armmonitor.MetricsClientListOptions {
+	options := &armmonitor.MetricsClientListOptions{
+		Metricnames: to.Ptr(strings.Join(q.Metrics, ",")),
+		Timespan:    to.Ptr(q.StartTime.Format(time.RFC3339) + "/" + q.EndTime.Format(time.RFC3339)),
+	}
+	if len(q.Aggregations) > 0 {
+		options.Aggregation = to.Ptr(strings.Join(q.Aggregations, ","))
+	}
+	if q.Interval != "" {
+		options.Interval = to.Ptr(q.Interval)
+	}
+	if q.Filter != "" {
+		options.Filter = to.Ptr(q.Filter)
+	}
+	if q.Namespace != "" {
+		options.Metricnamespace = to.Ptr(q.Namespace)
+	}
+	if q.OrderBy != "" {
+		options.Orderby = to.Ptr(q.OrderBy)
+	}
+	if q.Top > 0 {
+		options.Top = to.Ptr(q.Top)
+	}
+	return options
+}
+
+// normalizeInterval converts interval shorthands such as 5m into ISO 8601 durations
+func normalizeInterval(interval string) (string, error) {
+	if interval == "" {
+		return "", nil
+	}
+	if strings.EqualFold(interval, "FULL") {
+		return "FULL", nil
+	}
+	if strings.HasPrefix(strings.ToUpper(interval), "P") {
+		return strings.ToUpper(interval), nil
+	}
+
+	match := intervalShorthandPattern.FindStringSubmatch(strings.ToLower(interval))
+	if match == nil {
+		return "", fmt.Errorf("invalid interval '%s', expected an ISO 8601 duration (e.g. PT5M) or a shorthand such as 5m, 1h, 1d", interval)
+	}
+	switch match[2] {
+	case "m":
+		return "PT" + match[1] + "M", nil
+	case "h":
+		return "PT" + match[1] + "H", nil
+	default:
+		return "P" + match[1] + "D", nil
+	}
+}
+
+// stringParameter reads a scalar parameter as a string
+func stringParameter(values map[string]interface{}, key string) (string, error) {
+	switch value := values[key].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.TrimSpace(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("parameter '%s' must be a string", key)
+	}
+}
+
+// listParameter reads a parameter given either as a JSON array or as a string split on any of the separators
+func listParameter(values map[string]interface{}, key, separators string) ([]string, error) {
+	var items []string
+	switch value := values[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		items = strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(separators, r) })
+	case []interface{}:
+		for _, item := range value {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("metrics parameter '%s' must contain only strings", key)
+			}
+			items = append(items, str)
+		}
+	default:
+		return nil, fmt.Errorf("metrics parameter '%s' must be a string or an array of strings", key)
+	}
+
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
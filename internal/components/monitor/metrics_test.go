@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testClusterID = "/subscriptions/11111111-1111-1111-1111-111111111111/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/cluster"
+
+func TestParseMetricsParameters(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	query, err := parseMetricsParameters(map[string]interface{}{
+		"resource":    testClusterID,
+		"metrics":     "node_cpu_usage_percentage, node_memory_working_set_percentage",
+		"aggregation": "average maximum",
+		"start_time":  "2025-01-01T10:00:00Z",
+		"interval":    "5m",
+		"top":         float64(5),
+	}, "list", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if query.SubscriptionID != "11111111-1111-1111-1111-111111111111" {
+		t.Errorf("Unexpected subscription ID: %s", query.SubscriptionID)
+	}
+
+	options := query.listOptions()
+	if *options.Metricnames != "node_cpu_usage_percentage,node_memory_working_set_percentage" {
+		t.Errorf("Unexpected metric names: %s", *options.Metricnames)
+	}
+	if *options.Aggregation != "Average,Maximum" {
+		t.Errorf("Unexpected aggregation: %s", *options.Aggregation)
+	}
+	if *options.Timespan != "2025-01-01T10:00:00Z/2025-01-01T12:00:00Z" {
+		t.Errorf("Unexpected timespan: %s", *options.Timespan)
+	}
+	if *options.Interval != "PT5M" {
+		t.Errorf("Unexpected interval: %s", *options.Interval)
+	}
+	if *options.Top != 5 {
+		t.Errorf("Unexpected top: %d", *options.Top)
+	}
+}
+
+func TestParseMetricsParameters_MetricNamesWithSpaces(t *testing.T) {
+	query, err := parseMetricsParameters(map[string]interface{}{
+		"resource": testClusterID,
+		"metrics":  []interface{}{"Percentage CPU", "Available Memory Bytes"},
+	}, "list", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(query.Metrics, "|"); got != "Percentage CPU|Available Memory Bytes" {
+		t.Errorf("Unexpected metrics: %s", got)
+	}
+}
+
+func TestParseMetricsParameters_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		queryType string
+		wantErr   string
+	}{
+		{name: "missing resource", params: map[string]interface{}{}, queryType: "list-definitions", wantErr: "missing required metrics parameter 'resource'"},
+		{name: "invalid resource", params: map[string]interface{}{"resource": "not-a-resource"}, queryType: "list-definitions", wantErr: "invalid resource ID"},
+		{name: "missing metrics for list", params: map[string]interface{}{"resource": testClusterID}, queryType: "list", wantErr: "missing required metrics parameter 'metrics'"},
+		{name: "unknown parameter", params: map[string]interface{}{"resource": testClusterID, "output": "table"}, queryType: "list-definitions", wantErr: "unsupported metrics parameter 'output'"},
+		{name: "invalid aggregation", params: map[string]interface{}{"resource": testClusterID, "metrics": "m", "aggregation": "median"}, queryType: "list", wantErr: "invalid aggregation"},
+		{name: "invalid start time", params: map[string]interface{}{"resource": testClusterID, "metrics": "m", "start-time": "yesterday"}, queryType: "list", wantErr: "invalid start-time"},
+		{name: "start after end", params: map[string]interface{}{"resource": testClusterID, "metrics": "m", "start-time": "2025-01-02T00:00:00Z", "end-time": "2025-01-01T00:00:00Z"}, queryType: "list", wantErr: "start-time must be before end-time"},
+		{name: "invalid interval", params: map[string]interface{}{"resource": testClusterID, "metrics": "m", "interval": "often"}, queryType: "list", wantErr: "invalid interval"},
+		{name: "invalid top", params: map[string]interface{}{"resource": testClusterID, "metrics": "m", "top": float64(-1)}, queryType: "list", wantErr: "invalid top"},
+		{name: "non-string filter", params: map[string]interface{}{"resource": testClusterID, "metrics": "m", "filter": true}, queryType: "list", wantErr: "must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseMetricsParameters(tt.params, tt.queryType, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeInterval(t *testing.T) {
+	tests := map[string]string{
+		"":     "",
+		"PT1M": "PT1M",
+		"pt1h": "PT1H",
+		"full": "FULL",
+		"5m":   "PT5M",
+		"6h":   "PT6H",
+		"1d":   "P1D",
+	}
+	for input, want := range tests {
+		got, err := normalizeInterval(input)
+		if err != nil {
+			t.Errorf("normalizeInterval(%q) returned error: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("normalizeInterval(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestHandleMetricsOperation_RequiresClient(t *testing.T) {
+	params := map[string]interface{}{
+		"query_type": "list-definitions",
+		"parameters": `{"resource":"` + testClusterID + `"}`,
+	}
+	_, err := handleMetricsOperation(context.Background(), params, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "azure client is required") {
+		t.Errorf("Expected azure client error, got %v", err)
+	}
+}
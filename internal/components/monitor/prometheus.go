@@ -0,0 +1,262 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/monitor/prometheus"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// prometheusMetricsStream is the data collection rule stream carrying managed Prometheus metrics
+const prometheusMetricsStream = "Microsoft-PrometheusMetrics"
+
+// maxPrometheusTableRows caps the max_rows parameter of the prometheus operation
+const maxPrometheusTableRows = 5000
+
+// Prometheus query types
+const (
+	prometheusQueryInstant = "instant"
+	prometheusQueryRange   = "range"
+)
+
+// prometheusQuery holds the validated parameters of a prometheus operation
+type prometheusQuery struct {
+	Query     string
+	Type      string
+	Time      time.Time
+	StartTime time.Time
+	EndTime   time.Time
+	Step      time.Duration
+	MaxRows   int
+}
+
+// PrometheusQueryResult is the result of a PromQL query against the cluster's Azure Monitor workspace
+type PrometheusQueryResult struct {
+	ClusterName         string              `json:"cluster_name"`
+	WorkspaceResourceID string              `json:"workspace_resource_id"`
+	QueryEndpoint       string              `json:"query_endpoint"`
+	Query               string              `json:"query"`
+	QueryType           string              `json:"query_type"`
+	Time                string              `json:"time,omitempty"`
+	StartTime           string              `json:"start_time,omitempty"`
+	EndTime             string              `json:"end_time,omitempty"`
+	Step                string              `json:"step,omitempty"`
+	ResultType          string              `json:"result_type"`
+	SeriesCount         int                 `json:"series_count"`
+	Table               *prometheus.Table   `json:"table"`
+	Series              []prometheus.Series `json:"series,omitempty"`
+	Scalar              *prometheus.Sample  `json:"scalar,omitempty"`
+	Warnings            []string            `json:"warnings,omitempty"`
+}
+
+// handlePrometheusOperation runs a PromQL query against the Azure Monitor workspace that receives
+// the cluster's managed Prometheus metrics
+func handlePrometheusOperation(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	// Merge parameters from top-level and nested JSON
+	mergedParams, err := common.MergeOperationParams(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge parameters: %w", err)
+	}
+
+	subscriptionID, resourceGroup, clusterName, err := common.ExtractAKSParameters(mergedParams)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := parsePrometheusParameters(mergedParams, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+	defer cancel()
+
+	clusterResourceID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		subscriptionID, resourceGroup, clusterName)
+	rules, err := azClient.ListDataCollectionRulesForResource(ctx, subscriptionID, clusterResourceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get data collection rules: %w", err)
+	}
+	workspaceResourceID := prometheusWorkspaceFromRules(rules)
+	if workspaceResourceID == "" {
+		return "", fmt.Errorf("no Azure Monitor workspace found for cluster %s: no data collection rule sends Prometheus metrics to an Azure Monitor workspace, check that managed Prometheus is enabled", clusterName)
+	}
+
+	endpoint, err := azClient.GetPrometheusQueryEndpoint(ctx, workspaceResourceID)
+	if err != nil {
+		return "", err
+	}
+	client, err := prometheus.NewClient(endpoint, prometheus.DoerFunc(azClient.DoPrometheusRequest))
+	if err != nil {
+		return "", err
+	}
+
+	result, err := executePrometheusQuery(ctx, client, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to query managed Prometheus for cluster %s: %w", clusterName, err)
+	}
+	result.ClusterName = clusterName
+	result.WorkspaceResourceID = workspaceResourceID
+	result.QueryEndpoint = endpoint
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Prometheus result to JSON: %w", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// executePrometheusQuery runs the instant or range query and builds the tabular and raw result
+func executePrometheusQuery(ctx context.Context, client *prometheus.Client, query *prometheusQuery) (*PrometheusQueryResult, error) {
+	result := &PrometheusQueryResult{Query: query.Query, QueryType: query.Type}
+
+	var data *prometheus.Result
+	var err error
+	if query.Type == prometheusQueryRange {
+		result.StartTime = query.StartTime.Format(time.RFC3339)
+		result.EndTime = query.EndTime.Format(time.RFC3339)
+		result.Step = query.Step.String()
+		data, err = client.QueryRange(ctx, query.Query, query.StartTime, query.EndTime, query.Step)
+	} else {
+		result.Time = query.Time.Format(time.RFC3339)
+		data, err = client.Query(ctx, query.Query, query.Time)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.ResultType = data.ResultType
+	result.SeriesCount = len(data.Series)
+	result.Table = data.ToTable(query.MaxRows)
+	result.Series = data.Series
+	result.Scalar = data.Scalar
+	result.Warnings = data.Warnings
+	return result, nil
+}
+
+// parsePrometheusParameters validates the prometheus parameters. The query is a range query when
+// start_time or step is given, and an instant query otherwise.
+func parsePrometheusParameters(params map[string]interface{}, now time.Time) (*prometheusQuery, error) {
+	query := &prometheusQuery{MaxRows: prometheus.DefaultMaxTableRows}
+
+	var err error
+	if query.Query, err = stringParameter(params, "query"); err != nil {
+		return nil, err
+	}
+	if query.Query == "" {
+		return nil, fmt.Errorf("missing required parameter 'query' (PromQL expression) for prometheus operation")
+	}
+
+	timeParams := map[string]*time.Time{"time": &query.Time, "start_time": &query.StartTime, "end_time": &query.EndTime}
+	given := map[string]bool{}
+	for _, key := range []string{"time", "start_time", "end_time"} {
+		value, err := stringParameter(params, key)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+		if *timeParams[key], err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("invalid %s format, expected RFC3339 (ISO 8601): %w", key, err)
+		}
+		given[key] = true
+	}
+
+	step, err := stringParameter(params, "step")
+	if err != nil {
+		return nil, err
+	}
+
+	maxRows, err := stringParameter(params, "max_rows")
+	if err != nil {
+		return nil, err
+	}
+	if maxRows != "" {
+		n, err := strconv.Atoi(maxRows)
+		if err != nil || n < 1 || n > maxPrometheusTableRows {
+			return nil, fmt.Errorf("invalid max_rows '%s', must be between 1 and %d", maxRows, maxPrometheusTableRows)
+		}
+		query.MaxRows = n
+	}
+
+	if !given["start_time"] && step == "" {
+		if given["end_time"] {
+			return nil, fmt.Errorf("end_time requires start_time: use time for an instant query")
+		}
+		query.Type = prometheusQueryInstant
+		if !given["time"] {
+			query.Time = now
+		}
+		return query, nil
+	}
+
+	query.Type = prometheusQueryRange
+	if given["time"] {
+		return nil, fmt.Errorf("time applies to instant queries only: use start_time and end_time for a range query")
+	}
+	if !given["start_time"] {
+		return nil, fmt.Errorf("missing required parameter 'start_time' for a range query")
+	}
+	if !given["end_time"] {
+		query.EndTime = now
+	}
+	if step != "" {
+		if query.Step, err = prometheus.ParseStep(step); err != nil {
+			return nil, err
+		}
+	} else if query.StartTime.Before(query.EndTime) {
+		query.Step = prometheus.DefaultStep(query.StartTime, query.EndTime)
+	}
+	if err := prometheus.ValidateRange(query.StartTime, query.EndTime, query.Step); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// prometheusWorkspaceFromRules returns the Azure Monitor workspace destination of the first data
+// flow carrying managed Prometheus metrics
+func prometheusWorkspaceFromRules(rules []*armmonitor.DataCollectionRuleResource) string {
+	for _, rule := range rules {
+		if rule == nil || rule.Properties == nil || rule.Properties.Destinations == nil {
+			continue
+		}
+		accounts := map[string]string{}
+		for _, destination := range rule.Properties.Destinations.MonitoringAccounts {
+			if destination != nil && destination.Name != nil && destination.AccountResourceID != nil {
+				accounts[*destination.Name] = *destination.AccountResourceID
+			}
+		}
+
+		for _, flow := range rule.Properties.DataFlows {
+			if flow == nil || !slices.ContainsFunc(flow.Streams, func(stream *armmonitor.KnownDataFlowStreams) bool {
+				return stream != nil && strings.EqualFold(string(*stream), prometheusMetricsStream)
+			}) {
+				continue
+			}
+			for _, destination := range flow.Destinations {
+				if destination == nil {
+					continue
+				}
+				if accountResourceID, ok := accounts[*destination]; ok {
+					return accountResourceID
+				}
+			}
+		}
+	}
+	return ""
+}
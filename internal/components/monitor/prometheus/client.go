@@ -0,0 +1,283 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query limits of the managed Prometheus query API
+const (
+	// MaxRangeDuration is the longest time range of a range query
+	MaxRangeDuration = 32 * 24 * time.Hour
+	// MaxPointsPerSeries is the largest number of samples a range query may return per series
+	MaxPointsPerSeries = 11000
+	// MinStep is the smallest accepted range query resolution
+	MinStep = time.Second
+	// DefaultPointsPerSeries is the number of samples per series targeted when no step is given
+	DefaultPointsPerSeries = 250
+	// maxQueryLength caps the length of a PromQL expression
+	maxQueryLength = 10000
+	// maxResponseBytes caps the size of a query response that is read into memory
+	maxResponseBytes = 16 << 20
+)
+
+// Result types returned by the Prometheus HTTP API
+const (
+	ResultTypeVector = "vector"
+	ResultTypeMatrix = "matrix"
+	ResultTypeScalar = "scalar"
+	ResultTypeString = "string"
+)
+
+// Doer sends HTTP requests, e.g. an *http.Client or an authenticated pipeline
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to the Doer interface
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Client queries the Prometheus HTTP API of a query endpoint
+type Client struct {
+	endpoint string
+	doer     Doer
+}
+
+// NewClient creates a client for the Prometheus query endpoint
+func NewClient(endpoint string, doer Doer) (*Client, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return nil, fmt.Errorf("invalid Prometheus query endpoint '%s'", endpoint)
+	}
+	if doer == nil {
+		return nil, fmt.Errorf("an HTTP client is required")
+	}
+	return &Client{endpoint: strings.TrimSuffix(endpoint, "/"), doer: doer}, nil
+}
+
+// Sample is a single value of a series. It is encoded as [<unix time>, "<value>"] like in the
+// Prometheus HTTP API, keeping the value as a string so NaN and Inf survive JSON encoding.
+type Sample struct {
+	Time  time.Time
+	Value string
+}
+
+// MarshalJSON encodes the sample as [<unix time>, "<value>"]
+func (s Sample) MarshalJSON() ([]byte, error) {
+	timestamp := float64(s.Time.UnixMilli()) / 1000
+	return json.Marshal([]interface{}{timestamp, s.Value})
+}
+
+// UnmarshalJSON decodes a sample from [<unix time>, "<value>"]
+func (s *Sample) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) != 2 {
+		return fmt.Errorf("invalid sample %s: expected [<time>, \"<value>\"]", string(data))
+	}
+	var timestamp float64
+	if err := json.Unmarshal(raw[0], &timestamp); err != nil {
+		return fmt.Errorf("invalid sample time %s: %w", string(raw[0]), err)
+	}
+	if err := json.Unmarshal(raw[1], &s.Value); err != nil {
+		return fmt.Errorf("invalid sample value %s: %w", string(raw[1]), err)
+	}
+	s.Time = time.UnixMilli(int64(math.Round(timestamp * 1000))).UTC()
+	return nil
+}
+
+// Series is a labelled series of an instant (Value) or range (Values) query
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Value  *Sample           `json:"value,omitempty"`
+	Values []Sample          `json:"values,omitempty"`
+}
+
+// Result is the data of a successful query
+type Result struct {
+	ResultType string   `json:"result_type"`
+	Series     []Series `json:"series,omitempty"`
+	Scalar     *Sample  `json:"scalar,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// apiResponse is the envelope of every Prometheus HTTP API response
+type apiResponse struct {
+	Status    string   `json:"status"`
+	ErrorType string   `json:"errorType"`
+	Error     string   `json:"error"`
+	Warnings  []string `json:"warnings"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Query runs an instant query evaluated at the given time
+func (c *Client) Query(ctx context.Context, query string, at time.Time) (*Result, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("query", query)
+	form.Set("time", formatTime(at))
+	return c.do(ctx, "/api/v1/query", form)
+}
+
+// QueryRange runs a range query after checking the range and step against the query limits
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (*Result, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	if err := ValidateRange(start, end, step); err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("query", query)
+	form.Set("start", formatTime(start))
+	form.Set("end", formatTime(end))
+	form.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	return c.do(ctx, "/api/v1/query_range", form)
+}
+
+// ValidateRange checks a range query against MaxRangeDuration, MinStep and MaxPointsPerSeries
+func ValidateRange(start, end time.Time, step time.Duration) error {
+	if !start.Before(end) {
+		return fmt.Errorf("start_time must be before end_time")
+	}
+	if end.Sub(start) > MaxRangeDuration {
+		return fmt.Errorf("time range cannot exceed %d days, got %s", int(MaxRangeDuration.Hours()/24), end.Sub(start))
+	}
+	if step < MinStep {
+		return fmt.Errorf("step must be at least %s, got %s", MinStep, step)
+	}
+	if points := int64(end.Sub(start)/step) + 1; points > MaxPointsPerSeries {
+		return fmt.Errorf("step %s returns %d points per series over %s, the limit is %d: use a larger step or a shorter time range",
+			step, points, end.Sub(start), MaxPointsPerSeries)
+	}
+	return nil
+}
+
+// DefaultStep returns the step giving about DefaultPointsPerSeries samples over the range, rounded
+// up to whole seconds
+func DefaultStep(start, end time.Time) time.Duration {
+	step := (end.Sub(start) + DefaultPointsPerSeries - 1) / DefaultPointsPerSeries
+	step = ((step + time.Second - 1) / time.Second) * time.Second
+	return max(step, MinStep)
+}
+
+// ParseStep parses a step given as a duration such as 30s or 5m, or as a number of seconds
+func ParseStep(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds <= 0 {
+			return 0, fmt.Errorf("invalid step '%s': must be positive", value)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	step, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid step '%s', expected a duration such as 30s, 5m or 1h, or a number of seconds", value)
+	}
+	if step <= 0 {
+		return 0, fmt.Errorf("invalid step '%s': must be positive", value)
+	}
+	return step, nil
+}
+
+// validateQuery checks that a PromQL expression is present and of reasonable length
+func validateQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("query cannot be empty")
+	}
+	if len(query) > maxQueryLength {
+		return fmt.Errorf("query cannot exceed %d characters, got %d", maxQueryLength, len(query))
+	}
+	return nil
+}
+
+// formatTime formats a time as Unix seconds with millisecond precision
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64)
+}
+
+// do posts the form to the API path and decodes the result
+func (c *Client) do(ctx context.Context, path string, form url.Values) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Prometheus request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send Prometheus request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Prometheus response: %w", err)
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("prometheus response exceeds %d MB: narrow the query with label matchers or aggregation", maxResponseBytes>>20)
+	}
+
+	return parseResponse(resp.StatusCode, body)
+}
+
+// parseResponse decodes a Prometheus API response, surfacing API errors with their type
+func parseResponse(statusCode int, body []byte) (*Result, error) {
+	var response apiResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		if statusCode < 200 || statusCode >= 300 {
+			return nil, fmt.Errorf("prometheus query failed with HTTP %d: %s", statusCode, truncate(strings.TrimSpace(string(body)), 500))
+		}
+		return nil, fmt.Errorf("failed to parse Prometheus response: %w", err)
+	}
+	if response.Status != "success" {
+		if response.Error == "" {
+			return nil, fmt.Errorf("prometheus query failed with HTTP %d", statusCode)
+		}
+		return nil, fmt.Errorf("prometheus query failed (%s): %s", response.ErrorType, response.Error)
+	}
+
+	result := &Result{ResultType: response.Data.ResultType, Warnings: response.Warnings}
+	switch response.Data.ResultType {
+	case ResultTypeVector, ResultTypeMatrix:
+		if err := json.Unmarshal(response.Data.Result, &result.Series); err != nil {
+			return nil, fmt.Errorf("failed to parse Prometheus %s result: %w", response.Data.ResultType, err)
+		}
+		if result.Series == nil {
+			result.Series = []Series{}
+		}
+	case ResultTypeScalar, ResultTypeString:
+		result.Scalar = &Sample{}
+		if err := json.Unmarshal(response.Data.Result, result.Scalar); err != nil {
+			return nil, fmt.Errorf("failed to parse Prometheus %s result: %w", response.Data.ResultType, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Prometheus result type '%s'", response.Data.ResultType)
+	}
+	return result, nil
+}
+
+// truncate shortens a string to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
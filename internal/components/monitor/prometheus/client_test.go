@@ -0,0 +1,211 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer starts a Prometheus HTTP API stand-in that records the last request form
+func newTestServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client, server
+}
+
+func TestQuery(t *testing.T) {
+	var form map[string][]string
+	var path string
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		path, form = r.URL.Path, r.PostForm
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"up","job":"kubelet"},"value":[1760781600.5,"1"]},
+			{"metric":{"__name__":"up","job":"node"},"value":[1760781600.5,"0"]}]},"warnings":["partial data"]}`))
+	})
+
+	at := time.Date(2025, 10, 18, 10, 0, 0, 500_000_000, time.UTC)
+	result, err := client.Query(context.Background(), "up", at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/api/v1/query" || form["query"][0] != "up" || form["time"][0] != "1760781600.5" {
+		t.Errorf("Unexpected request %s %v", path, form)
+	}
+	if result.ResultType != ResultTypeVector || len(result.Series) != 2 || len(result.Warnings) != 1 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Series[1].Metric["job"] != "node" || result.Series[1].Value.Value != "0" || !result.Series[1].Value.Time.Equal(at) {
+		t.Errorf("Unexpected series: %+v", result.Series[1])
+	}
+}
+
+func TestQueryRange(t *testing.T) {
+	var form map[string][]string
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		_ = r.ParseForm()
+		form = r.PostForm
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"pod":"api"},"values":[[1760781600,"0.25"],[1760781660,"NaN"]]}]}}`))
+	})
+
+	start := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	result, err := client.QueryRange(context.Background(), "rate(x[5m])", start, start.Add(time.Minute), 30*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if form["start"][0] != "1760781600" || form["end"][0] != "1760781660" || form["step"][0] != "30" {
+		t.Errorf("Unexpected form: %v", form)
+	}
+	if len(result.Series) != 1 || len(result.Series[0].Values) != 2 || result.Series[0].Values[1].Value != "NaN" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestQueryScalarResult(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1760781600,"42"]}}`))
+	})
+	result, err := client.Query(context.Background(), "scalar(vector(42))", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Scalar == nil || result.Scalar.Value != "42" || result.Series != nil {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expectError string
+	}{
+		{"bad query", http.StatusBadRequest, `{"status":"error","errorType":"bad_data","error":"parse error at char 5"}`, "prometheus query failed (bad_data): parse error at char 5"},
+		{"timeout", http.StatusServiceUnavailable, `{"status":"error","errorType":"timeout","error":"query timed out"}`, "(timeout)"},
+		{"unauthorized", http.StatusUnauthorized, `Unauthorized`, "HTTP 401: Unauthorized"},
+		{"invalid JSON", http.StatusOK, `not json`, "failed to parse Prometheus response"},
+		{"unknown result type", http.StatusOK, `{"status":"success","data":{"resultType":"histogram","result":[]}}`, "unsupported Prometheus result type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			_, err := client.Query(context.Background(), "up", time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestQueryRangeValidatesBeforeSending(t *testing.T) {
+	requests := 0
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) { requests++ })
+	start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		query       string
+		end         time.Time
+		step        time.Duration
+		expectError string
+	}{
+		{"empty query", " ", start.Add(time.Hour), time.Minute, "query cannot be empty"},
+		{"end before start", "up", start.Add(-time.Hour), time.Minute, "must be before"},
+		{"range too long", "up", start.Add(33 * 24 * time.Hour), time.Hour, "cannot exceed 32 days"},
+		{"step too small", "up", start.Add(time.Hour), 100 * time.Millisecond, "at least 1s"},
+		{"too many points", "up", start.Add(24 * time.Hour), 5 * time.Second, "17281 points per series"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.QueryRange(context.Background(), tt.query, start, tt.end, tt.step)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests for invalid queries, got %d", requests)
+	}
+}
+
+func TestParseStep(t *testing.T) {
+	for value, expected := range map[string]time.Duration{"30s": 30 * time.Second, "5m": 5 * time.Minute, "60": time.Minute, "0.5": 500 * time.Millisecond} {
+		if step, err := ParseStep(value); err != nil || step != expected {
+			t.Errorf("ParseStep(%q) = %v, %v; expected %v", value, step, err, expected)
+		}
+	}
+	for _, value := range []string{"", "fast", "-1m", "0", "NaN"} {
+		if _, err := ParseStep(value); err == nil {
+			t.Errorf("Expected error for step %q", value)
+		}
+	}
+}
+
+func TestDefaultStep(t *testing.T) {
+	start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		window   time.Duration
+		expected time.Duration
+	}{
+		{time.Minute, time.Second},
+		{time.Hour, 15 * time.Second},
+		{24 * time.Hour, 346 * time.Second},
+		{MaxRangeDuration, 11060 * time.Second},
+	}
+	for _, tt := range tests {
+		step := DefaultStep(start, start.Add(tt.window))
+		if step != tt.expected {
+			t.Errorf("DefaultStep over %s = %s, expected %s", tt.window, step, tt.expected)
+		}
+		if err := ValidateRange(start, start.Add(tt.window), step); err != nil {
+			t.Errorf("Default step over %s is invalid: %v", tt.window, err)
+		}
+	}
+}
+
+func TestSampleJSONRoundTrip(t *testing.T) {
+	var sample Sample
+	if err := json.Unmarshal([]byte(`[1760781600.123,"+Inf"]`), &sample); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(sample)
+	if err != nil || string(data) != `[1760781600.123,"+Inf"]` {
+		t.Errorf("Unexpected encoding %s, %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`[1760781600]`), &sample); err == nil {
+		t.Error("Expected error for a sample without value")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient("https://ws-1.eastus.prometheus.monitor.azure.com", http.DefaultClient); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, endpoint := range []string{"", "ws-1.eastus.prometheus.monitor.azure.com", "ftp://host"} {
+		if _, err := NewClient(endpoint, http.DefaultClient); err == nil {
+			t.Errorf("Expected error for endpoint %q", endpoint)
+		}
+	}
+	if _, err := NewClient("https://host", nil); err == nil {
+		t.Error("Expected error without an HTTP client")
+	}
+}
@@ -0,0 +1,93 @@
+package prometheus
+
+import (
+	"slices"
+	"time"
+)
+
+// DefaultMaxTableRows is the default number of rows of a compact table
+const DefaultMaxTableRows = 500
+
+// metricNameLabel is the label holding the metric name
+const metricNameLabel = "__name__"
+
+// Table is a compact tabular view of a query result with one row per sample
+type Table struct {
+	Columns   []string   `json:"columns"`
+	Rows      [][]string `json:"rows"`
+	TotalRows int        `json:"total_rows"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// ToTable flattens the result into rows of time, the union of all labels and value. Label columns
+// are sorted with the metric name first, and at most maxRows rows are kept.
+func (r *Result) ToTable(maxRows int) *Table {
+	table := &Table{Rows: [][]string{}}
+	if r == nil {
+		return table
+	}
+
+	if r.Scalar != nil {
+		table.Columns = []string{"time", "value"}
+		table.addRow([]string{formatSampleTime(r.Scalar.Time), r.Scalar.Value}, maxRows)
+		return table
+	}
+
+	labels := labelColumns(r.Series)
+	table.Columns = append(append([]string{"time"}, labels...), "value")
+
+	for _, series := range r.Series {
+		samples := series.Values
+		if series.Value != nil {
+			samples = []Sample{*series.Value}
+		}
+		for _, sample := range samples {
+			row := make([]string, 0, len(table.Columns))
+			row = append(row, formatSampleTime(sample.Time))
+			for _, label := range labels {
+				row = append(row, series.Metric[label])
+			}
+			table.addRow(append(row, sample.Value), maxRows)
+		}
+	}
+	return table
+}
+
+// addRow appends the row unless the table is full, counting every row
+func (t *Table) addRow(row []string, maxRows int) {
+	t.TotalRows++
+	if len(t.Rows) >= maxRows {
+		t.Truncated = true
+		return
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// labelColumns returns the sorted union of the label names of all series, metric name first
+func labelColumns(series []Series) []string {
+	seen := map[string]bool{}
+	var labels []string
+	hasName := false
+	for _, s := range series {
+		for label := range s.Metric {
+			if label == metricNameLabel {
+				hasName = true
+				continue
+			}
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	slices.Sort(labels)
+	if hasName {
+		labels = append([]string{metricNameLabel}, labels...)
+	}
+	return labels
+}
+
+// formatSampleTime formats a sample time as RFC3339 with millisecond precision when needed
+func formatSampleTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.999Z07:00")
+}
@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToTable(t *testing.T) {
+	t0 := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	result := &Result{
+		ResultType: ResultTypeMatrix,
+		Series: []Series{
+			{Metric: map[string]string{"__name__": "up", "pod": "api"}, Values: []Sample{{t0, "1"}, {t0.Add(time.Minute), "0"}}},
+			{Metric: map[string]string{"__name__": "up", "node": "n1"}, Values: []Sample{{t0.Add(1500 * time.Millisecond), "1"}}},
+		},
+	}
+
+	table := result.ToTable(10)
+	if !reflect.DeepEqual(table.Columns, []string{"time", "__name__", "node", "pod", "value"}) {
+		t.Errorf("Unexpected columns: %v", table.Columns)
+	}
+	expected := [][]string{
+		{"2025-10-18T10:00:00Z", "up", "", "api", "1"},
+		{"2025-10-18T10:01:00Z", "up", "", "api", "0"},
+		{"2025-10-18T10:00:01.5Z", "up", "n1", "", "1"},
+	}
+	if !reflect.DeepEqual(table.Rows, expected) || table.TotalRows != 3 || table.Truncated {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+
+	truncated := result.ToTable(2)
+	if len(truncated.Rows) != 2 || truncated.TotalRows != 3 || !truncated.Truncated {
+		t.Errorf("Expected a truncated table, got %+v", truncated)
+	}
+}
+
+func TestToTableInstantAndScalar(t *testing.T) {
+	t0 := time.Date(2025, 10, 18, 10, 0, 0, 0, time.UTC)
+	vector := &Result{ResultType: ResultTypeVector, Series: []Series{{Metric: map[string]string{}, Value: &Sample{t0, "3"}}}}
+	table := vector.ToTable(10)
+	if !reflect.DeepEqual(table.Columns, []string{"time", "value"}) || !reflect.DeepEqual(table.Rows, [][]string{{"2025-10-18T10:00:00Z", "3"}}) {
+		t.Errorf("Unexpected vector table: %+v", table)
+	}
+
+	scalar := &Result{ResultType: ResultTypeScalar, Scalar: &Sample{t0, "42"}}
+	table = scalar.ToTable(10)
+	if !reflect.DeepEqual(table.Rows, [][]string{{"2025-10-18T10:00:00Z", "42"}}) {
+		t.Errorf("Unexpected scalar table: %+v", table)
+	}
+
+	empty := &Result{ResultType: ResultTypeVector, Series: []Series{}}
+	if table := empty.ToTable(10); len(table.Rows) != 0 || table.TotalRows != 0 {
+		t.Errorf("Unexpected empty table: %+v", table)
+	}
+}
@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/components/monitor/prometheus"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+func TestParsePrometheusParameters(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	instant, err := parsePrometheusParameters(map[string]interface{}{"query": " up "}, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if instant.Type != prometheusQueryInstant || instant.Query != "up" || !instant.Time.Equal(now) || instant.MaxRows != prometheus.DefaultMaxTableRows {
+		t.Errorf("Unexpected instant query: %+v", instant)
+	}
+
+	ranged, err := parsePrometheusParameters(map[string]interface{}{"query": "up", "start_time": "2026-10-18T11:00:00Z", "max_rows": float64(50)}, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ranged.Type != prometheusQueryRange || !ranged.EndTime.Equal(now) || ranged.Step != 15*time.Second || ranged.MaxRows != 50 {
+		t.Errorf("Unexpected range query: %+v", ranged)
+	}
+
+	stepped, err := parsePrometheusParameters(map[string]interface{}{"query": "up", "start_time": "2026-10-18T10:00:00Z", "end_time": "2026-10-18T11:00:00Z", "step": "5m"}, now)
+	if err != nil || stepped.Step != 5*time.Minute || !stepped.EndTime.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Unexpected range query with step: %+v, %v", stepped, err)
+	}
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		expectError string
+	}{
+		{"missing query", map[string]interface{}{}, "missing required parameter 'query'"},
+		{"invalid time", map[string]interface{}{"query": "up", "time": "now"}, "invalid time format"},
+		{"end time without start time", map[string]interface{}{"query": "up", "end_time": "2026-10-18T11:00:00Z"}, "end_time requires start_time"},
+		{"step without start time", map[string]interface{}{"query": "up", "step": "1m"}, "missing required parameter 'start_time'"},
+		{"time with range", map[string]interface{}{"query": "up", "time": "2026-10-18T11:00:00Z", "start_time": "2026-10-18T10:00:00Z"}, "instant queries only"},
+		{"invalid step", map[string]interface{}{"query": "up", "start_time": "2026-10-18T10:00:00Z", "step": "often"}, "invalid step"},
+		{"too many points", map[string]interface{}{"query": "up", "start_time": "2026-10-11T12:00:00Z", "step": "10s"}, "points per series"},
+		{"range too long", map[string]interface{}{"query": "up", "start_time": "2026-09-01T00:00:00Z"}, "cannot exceed 32 days"},
+		{"start after end", map[string]interface{}{"query": "up", "start_time": "2026-10-18T13:00:00Z"}, "must be before"},
+		{"invalid max rows", map[string]interface{}{"query": "up", "max_rows": "0"}, "invalid max_rows"},
+		{"non-string query", map[string]interface{}{"query": []interface{}{"up"}}, "must be a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePrometheusParameters(tt.params, now); err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestPrometheusWorkspaceFromRules(t *testing.T) {
+	workspaceID := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Monitor/accounts/amw"
+	containerInsights := &armmonitor.DataCollectionRuleResource{
+		Properties: &armmonitor.DataCollectionRuleResourceProperties{
+			Destinations: &armmonitor.DataCollectionRuleDestinations{
+				LogAnalytics: []*armmonitor.LogAnalyticsDestination{{Name: to.Ptr("ciworkspace"), WorkspaceResourceID: to.Ptr("/law")}},
+			},
+			DataFlows: []*armmonitor.DataFlow{{
+				Streams:      []*armmonitor.KnownDataFlowStreams{to.Ptr(armmonitor.KnownDataFlowStreams("Microsoft-ContainerLogV2"))},
+				Destinations: []*string{to.Ptr("ciworkspace")},
+			}},
+		},
+	}
+	managedPrometheus := &armmonitor.DataCollectionRuleResource{
+		Properties: &armmonitor.DataCollectionRuleResourceProperties{
+			Destinations: &armmonitor.DataCollectionRuleDestinations{
+				MonitoringAccounts: []*armmonitor.MonitoringAccountDestination{{Name: to.Ptr("MonitoringAccount1"), AccountResourceID: to.Ptr(workspaceID)}},
+			},
+			DataFlows: []*armmonitor.DataFlow{{
+				Streams:      []*armmonitor.KnownDataFlowStreams{to.Ptr(armmonitor.KnownDataFlowStreams("Microsoft-PrometheusMetrics"))},
+				Destinations: []*string{to.Ptr("MonitoringAccount1")},
+			}},
+		},
+	}
+
+	if got := prometheusWorkspaceFromRules([]*armmonitor.DataCollectionRuleResource{nil, containerInsights, managedPrometheus}); got != workspaceID {
+		t.Errorf("Expected %s, got %q", workspaceID, got)
+	}
+	if got := prometheusWorkspaceFromRules([]*armmonitor.DataCollectionRuleResource{containerInsights}); got != "" {
+		t.Errorf("Expected no workspace, got %q", got)
+	}
+}
+
+func TestExecutePrometheusQuery(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if path == "/api/v1/query_range" {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"node":"n1"},"values":[[1792310400,"1.5"],[1792310700,"2"]]},
+				{"metric":{"node":"n2"},"values":[[1792310400,"3"]]}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up"},"value":[1792310400,"1"]}]}}`))
+	}))
+	defer server.Close()
+
+	client, err := prometheus.NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	ranged, err := executePrometheusQuery(context.Background(), client, &prometheusQuery{
+		Query: "sum by (node) (x)", Type: prometheusQueryRange, StartTime: start, EndTime: start.Add(time.Hour), Step: 5 * time.Minute, MaxRows: 2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/api/v1/query_range" || ranged.ResultType != prometheus.ResultTypeMatrix || ranged.SeriesCount != 2 || ranged.Step != "5m0s" {
+		t.Errorf("Unexpected range result: %+v", ranged)
+	}
+	if len(ranged.Table.Rows) != 2 || ranged.Table.TotalRows != 3 || !ranged.Table.Truncated || len(ranged.Series[0].Values) != 2 {
+		t.Errorf("Expected truncated table and complete raw series, got %+v", ranged)
+	}
+
+	instant, err := executePrometheusQuery(context.Background(), client, &prometheusQuery{Query: "up", Type: prometheusQueryInstant, Time: start, MaxRows: 10})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(instant)
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	for _, expected := range []string{`"query_type":"instant"`, `"time":"2026-10-18T00:00:00Z"`, `"columns":["time","__name__","value"]`, `"value":[1792310400,"1"]`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected result to contain %s, got %s", expected, data)
+		}
+	}
+}
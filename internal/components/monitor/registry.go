@@ -8,11 +8,13 @@ import (
 type MonitoringOperationType string
 
 const (
-	OpMetrics          MonitoringOperationType = "metrics"
-	OpResourceHealth   MonitoringOperationType = "resource_health"
-	OpAppInsights      MonitoringOperationType = "app_insights"
-	OpDiagnostics      MonitoringOperationType = "diagnostics"
-	OpControlPlaneLogs MonitoringOperationType = "control_plane_logs"
+	OpMetrics           MonitoringOperationType = "metrics"
+	OpResourceHealth    MonitoringOperationType = "resource_health"
+	OpAppInsights       MonitoringOperationType = "app_insights"
+	OpDiagnostics       MonitoringOperationType = "diagnostics"
+	OpControlPlaneLogs  MonitoringOperationType = "control_plane_logs"
+	OpContainerInsights MonitoringOperationType = "container_insights"
+	OpPrometheus        MonitoringOperationType = "prometheus"
 )
 
 // RegisterAksMonitoring registers the monitoring tool
@@ -28,8 +30,9 @@ Supported Operations:
    
    Use for: CPU usage, memory consumption, network traffic, pod counts, node health
    Required parameters: resource (Azure resource ID)
-   Additional for 'list': metrics (metric names)
-   Optional: aggregation, start-time, end-time, interval, filter
+   Additional for 'list': metrics (comma-separated string or array of metric names)
+   Optional: aggregation (Average, Count, Maximum, Minimum, Total), start-time, end-time (default: last hour),
+   interval (ISO 8601 such as PT5M, or 5m/1h/1d), filter, namespace, top, orderby
 
 2. Resource Health - Get Azure Resource Health events for AKS clusters
    Use for: Cluster availability issues, platform problems, service health events
@@ -63,6 +66,25 @@ Supported Operations:
    - fleet-mcs-controller-manager
    PLEASE NOTE: you need to check if the category is enabled in your cluster's diagnostic settings by using the diagnostics tool.
 
+6. Container Insights - Query application-level Container Insights data with safe, parameterized templates
+   The Log Analytics workspace is discovered from the monitoring addon or the cluster's data collection rule.
+   Templates:
+   - pod_logs: container logs from ContainerLogV2. Filters: namespace, pod_name, container, contains (text match)
+   - pod_restarts: container restarts per interval from KubePodInventory. Filters: namespace, pod_name, container, interval (5m, 15m, 30m, 1h, 6h, 12h, 1d; default 1h)
+   - oom_killed: containers last terminated with OOMKilled from KubePodInventory. Filters: namespace, pod_name, container
+   - node_inventory: latest status, versions and labels of each node from KubeNodeInventory. Filters: node_name
+   - kube_events: Kubernetes events from KubeEvents. Filters: namespace, pod_name (object name), reason (e.g. BackOff, FailedScheduling), contains
+   Required parameters: subscription_id, resource_group, cluster_name, template, start_time
+   Optional: end_time (time range up to 24 hours), max_records (default 100, max 1000)
+
+7. Prometheus - Run PromQL queries against Azure Monitor managed Prometheus
+   The Azure Monitor workspace is discovered from the cluster's data collection rule, and queried with a Microsoft Entra token.
+   - Instant query: query (PromQL), time (optional, default now)
+   - Range query: query (PromQL), start_time, end_time (optional, default now), step (optional, e.g. 30s, 5m or seconds; default about 250 points)
+   Limits: time range up to 32 days, step at least 1s, at most 11000 points per series
+   Optional: max_rows (rows of the compact table, default 500, max 5000)
+   Returns a compact table (time, labels, value) and the raw series.
+
 Use This Tool When You Need To:
 - Monitor cluster or other azure resource performance and usage (use metrics)
 - Check cluster availability and platform health (use resource_health)
@@ -73,6 +95,8 @@ Use This Tool When You Need To:
 - Troubleshoot pod scheduling issues (use control_plane_logs with kube-scheduler)
 - Check storage-related problems (use control_plane_logs with csi-azuredisk-controller, csi-azurefile-controller)
 - Analyze cluster scaling behavior (use control_plane_logs with cluster-autoscaler)
+- Read application logs, restarts, OOM kills and Kubernetes events (use container_insights)
+- Query Prometheus metrics such as container CPU, memory or request rates (use prometheus)
 - Review security audit events (use control_plane_logs with kube-audit, kube-audit-admin)
 
 Examples:
@@ -96,6 +120,15 @@ control_plane_logs:
 - Query API server logs: operation="control_plane_logs", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"log_category\":\"kube-apiserver\", \"start_time\":\"<start-time>\", \"end_time\":\"<end-time>\", \"max_records\":\"50\"}"
 - Debug authentication issues: operation="control_plane_logs", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"log_category\":\"guard\", \"start_time\":\"<start-time>\", \"end_time\":\"<end-time>\", \"max_records\":\"100\"}"
 - Analyze audit events: operation="control_plane_logs", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"log_category\":\"kube-audit\", \"log_level\":\"error\", \"start_time\":\"<start-time>\", \"end_time\":\"<end-time>\", \"max_records\":\"50\"}"
+
+container_insights:
+- Search pod logs for an error: operation="container_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"template\":\"pod_logs\", \"namespace\":\"shop\", \"container\":\"api\", \"contains\":\"connection refused\", \"start_time\":\"<start-time>\"}"
+- Find OOMKilled containers: operation="container_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"template\":\"oom_killed\", \"start_time\":\"<start-time>\"}"
+- List scheduling failures: operation="container_insights", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"template\":\"kube_events\", \"reason\":\"FailedScheduling\", \"start_time\":\"<start-time>\"}"
+
+prometheus:
+- Current pod CPU usage: operation="prometheus", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"query\":\"sum by (pod) (rate(container_cpu_usage_seconds_total[5m]))\"}"
+- Memory working set over time: operation="prometheus", subscription_id="<subscription-id>", resource_group="<resource-group>", cluster_name="<cluster-name>", parameters="{\"query\":\"sum by (node) (container_memory_working_set_bytes)\", \"start_time\":\"<start-time>\", \"end_time\":\"<end-time>\", \"step\":\"5m\"}"
 `
 
 	return mcp.NewTool("aks_monitoring",
@@ -104,23 +137,23 @@ control_plane_logs:
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("operation",
 			mcp.Required(),
-			mcp.Description("The monitoring operation to perform: 'metrics' (CPU/memory/network), 'resource_health' (cluster availability), 'app_insights' (telemetry analysis), 'diagnostics' (logging config), 'control_plane_logs' (Kubernetes logs like kube-apiserver, kube-audit, guard, etc.)"),
+			mcp.Description("The monitoring operation to perform: 'metrics' (CPU/memory/network), 'resource_health' (cluster availability), 'app_insights' (telemetry analysis), 'diagnostics' (logging config), 'control_plane_logs' (Kubernetes logs like kube-apiserver, kube-audit, guard, etc.), 'container_insights' (pod logs, restarts, OOM kills, node inventory and events), 'prometheus' (PromQL queries against managed Prometheus)"),
 		),
 		mcp.WithString("query_type",
 			mcp.Description("For metrics operations only: 'list' (get metric values), 'list-definitions' (available metrics), 'list-namespaces' (metric categories)"),
 		),
 		mcp.WithString("parameters",
 			mcp.Required(),
-			mcp.Description("JSON string with operation parameters. metrics: resource (required), metrics (required for 'list' query_type), aggregation/start-time/end-time/interval/filter (optional). resource_health: start_time, end_time, status. app_insights: app_insights_name, query, start_time/end_time OR timespan (optional). diagnostics: none required. control_plane_logs: log_category (kube-apiserver/kube-audit/guard/etc), start_time, end_time, max_records, log_level"),
+			mcp.Description("JSON string with operation parameters. metrics: resource (required), metrics (required for 'list' query_type), aggregation/start-time/end-time/interval/filter/namespace/top/orderby (optional). resource_health: start_time, end_time, status. app_insights: app_insights_name, query, start_time/end_time OR timespan (optional). diagnostics: none required. control_plane_logs: log_category (kube-apiserver/kube-audit/guard/etc), start_time, end_time, max_records, log_level. container_insights: template (pod_logs/pod_restarts/oom_killed/node_inventory/kube_events), start_time, end_time, max_records, namespace, pod_name, container, node_name, reason, contains, interval. prometheus: query (PromQL), time (instant) OR start_time/end_time/step (range), max_rows"),
 		),
 		mcp.WithString("subscription_id",
-			mcp.Description("Azure subscription ID (required for resource_health, app_insights, diagnostics, control_plane_logs)"),
+			mcp.Description("Azure subscription ID (required for resource_health, app_insights, diagnostics, control_plane_logs, container_insights, prometheus)"),
 		),
 		mcp.WithString("resource_group",
-			mcp.Description("Resource group name (required for resource_health, app_insights, diagnostics, control_plane_logs)"),
+			mcp.Description("Resource group name (required for resource_health, app_insights, diagnostics, control_plane_logs, container_insights, prometheus)"),
 		),
 		mcp.WithString("cluster_name",
-			mcp.Description("AKS cluster name (required for resource_health, diagnostics, control_plane_logs)"),
+			mcp.Description("AKS cluster name (required for resource_health, diagnostics, control_plane_logs, container_insights, prometheus)"),
 		),
 	)
 }
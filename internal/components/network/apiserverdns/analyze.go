@@ -0,0 +1,243 @@
+// Package apiserverdns checks whether the private API server FQDN of an AKS cluster resolves to the
+// API server address from the node and client virtual networks.
+package apiserverdns
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+// Private DNS zone modes of a private cluster
+const (
+	ZoneModeSystem = "system"
+	ZoneModeCustom = "custom"
+	ZoneModeNone   = "none"
+)
+
+// Ways the API server is exposed inside the virtual network
+const (
+	AccessPrivateEndpoint = "private_endpoint"
+	AccessVNetIntegration = "vnet_integration"
+)
+
+// Roles of a virtual network in the analysis
+const (
+	RoleNode   = "node"
+	RoleClient = "client"
+)
+
+// Resolution outcomes of the API server FQDN from a virtual network
+const (
+	ResolutionOK        = "ok"
+	ResolutionFails     = "fails"
+	ResolutionCustomDNS = "depends_on_custom_dns"
+)
+
+// AzureDNSAddress is the Azure provided DNS resolver that answers from linked private DNS zones
+const AzureDNSAddress = "168.63.129.16"
+
+// linkStateCompleted is the state of a virtual network link that is ready to resolve records
+const linkStateCompleted = "Completed"
+
+// Link is a virtual network link of the private DNS zone
+type Link struct {
+	Name                string
+	VirtualNetworkID    string
+	State               string
+	RegistrationEnabled bool
+}
+
+// VirtualNetwork is a virtual network that needs to resolve the API server FQDN
+type VirtualNetwork struct {
+	ID         string
+	Role       string
+	DNSServers []string
+}
+
+// Input holds the DNS configuration of a private cluster
+type Input struct {
+	FQDN     string
+	ZoneMode string
+	Zone     string
+	// RecordFound is false when the A record of the FQDN does not exist in the zone
+	RecordFound bool
+	Records     []netip.Addr
+	// ExpectedIPs are the API server addresses: the private endpoint NIC or the API server load balancer
+	ExpectedIPs     []netip.Addr
+	Access          string
+	Links           []Link
+	VirtualNetworks []VirtualNetwork
+	// PublicRecords is the public DNS resolution of the FQDN, used when the cluster has no private zone
+	PublicRecords []netip.Addr
+}
+
+// VirtualNetworkCheck is the resolution of the API server FQDN from a virtual network
+type VirtualNetworkCheck struct {
+	VirtualNetworkID string   `json:"virtual_network_id"`
+	Role             string   `json:"role"`
+	Linked           bool     `json:"linked"`
+	LinkState        string   `json:"link_state,omitempty"`
+	DNSServers       []string `json:"dns_servers,omitempty"`
+	Resolution       string   `json:"resolution"`
+}
+
+// Result is the outcome of the DNS analysis
+type Result struct {
+	FQDN            string                `json:"fqdn"`
+	ZoneMode        string                `json:"private_dns_zone_mode"`
+	Zone            string                `json:"private_dns_zone,omitempty"`
+	Access          string                `json:"api_server_access"`
+	Records         []string              `json:"a_records"`
+	ExpectedIPs     []string              `json:"expected_ips"`
+	RecordMatches   bool                  `json:"record_matches"`
+	VirtualNetworks []VirtualNetworkCheck `json:"virtual_networks"`
+	Findings        common.Findings       `json:"findings,omitempty"`
+}
+
+// RelativeRecordName returns the record name of the FQDN within the zone, or false when the
+// FQDN is not in the zone
+func RelativeRecordName(fqdn, zone string) (string, bool) {
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+	zone = strings.TrimSuffix(strings.ToLower(zone), ".")
+	name, ok := strings.CutSuffix(fqdn, "."+zone)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// SystemZoneName returns the AKS managed private DNS zone of a private FQDN, which is the FQDN
+// without its first label
+func SystemZoneName(fqdn string) string {
+	_, zone, _ := strings.Cut(strings.TrimSuffix(fqdn, "."), ".")
+	return zone
+}
+
+// Analyze compares the A record of the API server FQDN with the API server addresses and checks
+// that every node and client virtual network can resolve it
+func Analyze(input Input) Result {
+	result := Result{
+		FQDN:            input.FQDN,
+		ZoneMode:        input.ZoneMode,
+		Zone:            input.Zone,
+		Access:          input.Access,
+		Records:         addressStrings(input.Records),
+		ExpectedIPs:     addressStrings(input.ExpectedIPs),
+		VirtualNetworks: []VirtualNetworkCheck{},
+	}
+
+	if input.FQDN == "" {
+		result.Findings.Add(common.SeverityError, "private_fqdn_missing", "The cluster has no private FQDN")
+		return result
+	}
+	if len(input.ExpectedIPs) == 0 {
+		result.Findings.Add(common.SeverityWarning, "api_server_ip_unknown", "The API server address could not be determined, so the DNS record cannot be validated")
+	}
+
+	if input.ZoneMode == ZoneModeNone {
+		// Without a private zone AKS publishes the private address in public DNS
+		result.Records = addressStrings(input.PublicRecords)
+		result.RecordMatches = recordsMatch(input.PublicRecords, input.ExpectedIPs)
+		switch {
+		case len(input.PublicRecords) == 0:
+			result.Findings.Add(common.SeverityError, "public_record_missing",
+				fmt.Sprintf("The cluster has no private DNS zone and %s does not resolve in public DNS", input.FQDN))
+		case len(input.ExpectedIPs) > 0 && !result.RecordMatches:
+			result.Findings.Add(common.SeverityError, "public_record_mismatch",
+				fmt.Sprintf("%s resolves to %s in public DNS but the API server is at %s", input.FQDN, strings.Join(result.Records, ", "), strings.Join(result.ExpectedIPs, ", ")))
+		}
+		for _, vnet := range input.VirtualNetworks {
+			check := VirtualNetworkCheck{VirtualNetworkID: vnet.ID, Role: vnet.Role, DNSServers: vnet.DNSServers, Resolution: ResolutionOK}
+			if len(input.PublicRecords) == 0 {
+				check.Resolution = ResolutionFails
+			}
+			result.VirtualNetworks = append(result.VirtualNetworks, check)
+		}
+		return result
+	}
+
+	result.RecordMatches = input.RecordFound && recordsMatch(input.Records, input.ExpectedIPs)
+	switch {
+	case !input.RecordFound || len(input.Records) == 0:
+		result.Findings.Add(common.SeverityError, "a_record_missing",
+			fmt.Sprintf("The private DNS zone %s has no A record for %s", input.Zone, input.FQDN))
+	case len(input.ExpectedIPs) > 0 && !result.RecordMatches:
+		result.Findings.Add(common.SeverityError, "a_record_mismatch",
+			fmt.Sprintf("The A record of %s points to %s but the API server %s is at %s", input.FQDN, strings.Join(result.Records, ", "), strings.ReplaceAll(input.Access, "_", " "), strings.Join(result.ExpectedIPs, ", ")))
+	}
+
+	for _, vnet := range input.VirtualNetworks {
+		result.VirtualNetworks = append(result.VirtualNetworks, result.checkVirtualNetwork(input, vnet))
+	}
+	return result
+}
+
+// checkVirtualNetwork checks the zone link and DNS servers of a virtual network
+func (r *Result) checkVirtualNetwork(input Input, vnet VirtualNetwork) VirtualNetworkCheck {
+	check := VirtualNetworkCheck{VirtualNetworkID: vnet.ID, Role: vnet.Role, DNSServers: vnet.DNSServers}
+	name := nameFromID(vnet.ID)
+
+	for _, link := range input.Links {
+		if strings.EqualFold(link.VirtualNetworkID, vnet.ID) {
+			check.Linked = true
+			check.LinkState = link.State
+		}
+	}
+
+	customDNS := slices.ContainsFunc(vnet.DNSServers, func(server string) bool { return server != AzureDNSAddress })
+	switch {
+	case customDNS:
+		// Custom DNS servers answer for the VNet, so the zone link of this VNet does not matter;
+		// the servers must forward the zone to Azure DNS from a linked VNet
+		check.Resolution = ResolutionCustomDNS
+		r.Findings.Add(common.SeverityWarning, "custom_dns_servers",
+			fmt.Sprintf("The %s VNet %s uses custom DNS servers %s; they must forward %s to %s from a VNet linked to the zone", vnet.Role, name, strings.Join(vnet.DNSServers, ", "), input.Zone, AzureDNSAddress))
+	case !check.Linked:
+		check.Resolution = ResolutionFails
+		severity := common.SeverityError
+		if vnet.Role == RoleClient {
+			severity = common.SeverityWarning
+		}
+		r.Findings.Add(severity, "vnet_not_linked",
+			fmt.Sprintf("The %s VNet %s is not linked to the private DNS zone %s, so it cannot resolve %s", vnet.Role, name, input.Zone, input.FQDN))
+	case check.LinkState != "" && !strings.EqualFold(check.LinkState, linkStateCompleted):
+		check.Resolution = ResolutionFails
+		r.Findings.Add(common.SeverityWarning, "vnet_link_not_ready",
+			fmt.Sprintf("The link of the %s VNet %s to the private DNS zone is %s", vnet.Role, name, check.LinkState))
+	case !r.RecordMatches && len(input.ExpectedIPs) > 0:
+		check.Resolution = ResolutionFails
+	default:
+		check.Resolution = ResolutionOK
+	}
+	return check
+}
+
+// recordsMatch reports whether the records contain every expected address
+func recordsMatch(records, expected []netip.Addr) bool {
+	if len(records) == 0 {
+		return false
+	}
+	for _, address := range expected {
+		if !slices.Contains(records, address) {
+			return false
+		}
+	}
+	return true
+}
+
+func addressStrings(addresses []netip.Addr) []string {
+	values := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		values = append(values, address.String())
+	}
+	return values
+}
+
+// nameFromID returns the last segment of a resource ID
+func nameFromID(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
@@ -0,0 +1,137 @@
+package apiserverdns
+
+import (
+	"net/netip"
+	"testing"
+)
+
+const (
+	testFQDN       = "aks-abc123.0f1e2d3c.privatelink.eastus.azmk8s.io"
+	testZone       = "0f1e2d3c.privatelink.eastus.azmk8s.io"
+	testNodeVNetID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/aks-vnet"
+	testHubVNetID  = "/subscriptions/sub/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub"
+)
+
+func testInput() Input {
+	return Input{
+		FQDN:        testFQDN,
+		ZoneMode:    ZoneModeSystem,
+		Zone:        testZone,
+		RecordFound: true,
+		Records:     []netip.Addr{netip.MustParseAddr("10.224.0.4")},
+		ExpectedIPs: []netip.Addr{netip.MustParseAddr("10.224.0.4")},
+		Access:      AccessPrivateEndpoint,
+		Links: []Link{
+			{Name: "node-link", VirtualNetworkID: testNodeVNetID, State: "Completed"},
+		},
+		VirtualNetworks: []VirtualNetwork{
+			{ID: testNodeVNetID, Role: RoleNode},
+		},
+	}
+}
+
+func TestRelativeRecordName(t *testing.T) {
+	name, ok := RelativeRecordName(testFQDN+".", testZone)
+	if !ok || name != "aks-abc123" {
+		t.Errorf("Expected aks-abc123, got %q ok=%v", name, ok)
+	}
+	if _, ok := RelativeRecordName(testFQDN, "privatelink.westus.azmk8s.io"); ok {
+		t.Error("Expected FQDN outside the zone to be rejected")
+	}
+	if SystemZoneName(testFQDN) != testZone {
+		t.Errorf("Expected system zone %s, got %s", testZone, SystemZoneName(testFQDN))
+	}
+}
+
+func TestAnalyzeHealthy(t *testing.T) {
+	result := Analyze(testInput())
+
+	if !result.RecordMatches || len(result.Findings) != 0 {
+		t.Errorf("Expected a matching record without findings, got %+v", result)
+	}
+	if len(result.VirtualNetworks) != 1 || result.VirtualNetworks[0].Resolution != ResolutionOK {
+		t.Errorf("Expected the node VNet to resolve, got %+v", result.VirtualNetworks)
+	}
+}
+
+func TestAnalyzeRecordProblems(t *testing.T) {
+	input := testInput()
+	input.Records = []netip.Addr{netip.MustParseAddr("10.224.0.9")}
+	result := Analyze(input)
+	if result.RecordMatches || !result.Findings.Has("a_record_mismatch") {
+		t.Errorf("Expected a_record_mismatch, got %+v", result.Findings)
+	}
+	if result.VirtualNetworks[0].Resolution != ResolutionFails {
+		t.Errorf("Expected resolution to fail with a stale record, got %s", result.VirtualNetworks[0].Resolution)
+	}
+
+	input = testInput()
+	input.RecordFound = false
+	input.Records = nil
+	if result := Analyze(input); !result.Findings.Has("a_record_missing") {
+		t.Errorf("Expected a_record_missing, got %+v", result.Findings)
+	}
+
+	input = testInput()
+	input.ExpectedIPs = nil
+	if result := Analyze(input); !result.Findings.Has("api_server_ip_unknown") || result.VirtualNetworks[0].Resolution != ResolutionOK {
+		t.Errorf("Expected api_server_ip_unknown without failing resolution, got %+v", result)
+	}
+}
+
+func TestAnalyzeVirtualNetworkLinks(t *testing.T) {
+	input := testInput()
+	input.Links[0].State = "InProgress"
+	input.VirtualNetworks = append(input.VirtualNetworks,
+		VirtualNetwork{ID: testHubVNetID, Role: RoleClient},
+		VirtualNetwork{ID: testHubVNetID + "2", Role: RoleClient, DNSServers: []string{"10.0.0.4"}},
+	)
+
+	result := Analyze(input)
+	for _, code := range []string{"vnet_link_not_ready", "vnet_not_linked", "custom_dns_servers"} {
+		if !result.Findings.Has(code) {
+			t.Errorf("Expected %s finding, got %+v", code, result.Findings)
+		}
+	}
+	expected := []string{ResolutionFails, ResolutionFails, ResolutionCustomDNS}
+	for i, check := range result.VirtualNetworks {
+		if check.Resolution != expected[i] {
+			t.Errorf("Expected resolution %s for %s, got %s", expected[i], check.VirtualNetworkID, check.Resolution)
+		}
+	}
+	if result.VirtualNetworks[1].Linked {
+		t.Error("Expected the client VNet to be reported as not linked")
+	}
+
+	// Listing only Azure DNS is equivalent to the default resolver
+	input = testInput()
+	input.VirtualNetworks[0].DNSServers = []string{AzureDNSAddress}
+	if result := Analyze(input); result.VirtualNetworks[0].Resolution != ResolutionOK {
+		t.Errorf("Expected Azure DNS to resolve through the link, got %+v", result.VirtualNetworks[0])
+	}
+}
+
+func TestAnalyzePublicDNS(t *testing.T) {
+	input := testInput()
+	input.ZoneMode = ZoneModeNone
+	input.Zone = ""
+	input.PublicRecords = []netip.Addr{netip.MustParseAddr("10.224.0.4")}
+
+	result := Analyze(input)
+	if !result.RecordMatches || len(result.Findings) != 0 || result.VirtualNetworks[0].Resolution != ResolutionOK {
+		t.Errorf("Expected public DNS to resolve the private address, got %+v", result)
+	}
+
+	input.PublicRecords = nil
+	result = Analyze(input)
+	if !result.Findings.Has("public_record_missing") || result.VirtualNetworks[0].Resolution != ResolutionFails {
+		t.Errorf("Expected public_record_missing, got %+v", result)
+	}
+}
+
+func TestAnalyzeMissingFQDN(t *testing.T) {
+	result := Analyze(Input{ZoneMode: ZoneModeSystem})
+	if !result.Findings.Has("private_fqdn_missing") {
+		t.Errorf("Expected private_fqdn_missing, got %+v", result.Findings)
+	}
+}
@@ -0,0 +1,74 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/egressrules"
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/tools"
+)
+
+// GetAksNetworkDiagnosticsHandler returns a handler for the aks_network_diagnostics command
+func GetAksNetworkDiagnosticsHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	serviceTagsFile, egressRulesFile := "", ""
+	if cfg != nil {
+		serviceTagsFile = cfg.ServiceTagsFile
+		egressRulesFile = cfg.EgressRulesFile
+	}
+	serviceTags := flowcheck.NewServiceTagStore(serviceTagsFile)
+	egressRules := egressrules.NewCatalogStore(egressRulesFile)
+	monitorEnabled := cfg != nil && components.IsComponentEnabled("monitor", cfg.EnabledComponents)
+
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		// Extract operation parameter
+		operation, ok := params["operation"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing or invalid 'operation' parameter")
+		}
+
+		// Validate operation
+		if !ValidateNetworkDiagnosticsOperation(operation) {
+			supportedOps := GetSupportedNetworkDiagnosticsOperations()
+			return "", fmt.Errorf("unsupported operation: %s. Supported operations: %v", operation, supportedOps)
+		}
+
+		mergedParams, err := common.MergeOperationParams(params)
+		if err != nil {
+			return "", err
+		}
+
+		// Extract common AKS parameters
+		subID, rg, clusterName, err := common.ExtractAKSParameters(mergedParams)
+		if err != nil {
+			return "", err
+		}
+
+		ctx, cancel := common.WithConfigTimeout(ctx, cfg)
+		defer cancel()
+
+		// Handle different operations
+		switch operation {
+		case string(OpNetworkFlowCheck):
+			return handleNetworkFlowCheck(ctx, client, serviceTags, mergedParams, subID, rg, clusterName)
+		case string(OpEgressAnalysis):
+			return handleEgressAnalysis(ctx, client, serviceTags, mergedParams, subID, rg, clusterName)
+		case string(OpOutboundCapacity):
+			return handleOutboundCapacity(ctx, client, monitorEnabled, mergedParams, subID, rg, clusterName)
+		case string(OpIPCapacity):
+			return handleIPCapacity(ctx, client, mergedParams, subID, rg, clusterName)
+		case string(OpPrivateDNS):
+			return handlePrivateDNS(ctx, client, mergedParams, subID, rg, clusterName)
+		case string(OpEgressConformance):
+			return handleEgressConformance(ctx, client, egressRules, mergedParams, subID, rg, clusterName)
+		case string(OpServiceLoadBalancer):
+			return handleServiceLoadBalancer(ctx, client, serviceTags, mergedParams, subID, rg, clusterName)
+		default:
+			return "", fmt.Errorf("operation '%s' not implemented", operation)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func TestGetAksNetworkDiagnosticsHandlerValidation(t *testing.T) {
+	cfg := &config.ConfigData{}
+	handler := GetAksNetworkDiagnosticsHandler(nil, cfg)
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		expectError string
+	}{
+		{
+			name:        "missing operation",
+			params:      map[string]interface{}{},
+			expectError: "missing or invalid 'operation' parameter",
+		},
+		{
+			name:        "unsupported operation",
+			params:      map[string]interface{}{"operation": "traceroute"},
+			expectError: "unsupported operation: traceroute",
+		},
+		{
+			name: "invalid parameters JSON",
+			params: map[string]interface{}{
+				"operation":  string(OpNetworkFlowCheck),
+				"parameters": "{not json",
+			},
+			expectError: "failed to parse parameters JSON",
+		},
+		{
+			name: "missing cluster name",
+			params: map[string]interface{}{
+				"operation":       string(OpNetworkFlowCheck),
+				"subscription_id": "sub-123",
+				"resource_group":  "rg-test",
+			},
+			expectError: "missing or invalid cluster_name parameter",
+		},
+		{
+			name: "invalid flow parameters",
+			params: map[string]interface{}{
+				"operation":       string(OpNetworkFlowCheck),
+				"subscription_id": "sub-123",
+				"resource_group":  "rg-test",
+				"cluster_name":    "cluster",
+				"parameters":      `{"source":"Internet","destination":"10.0.0.4","port":443}`,
+			},
+			expectError: "must be a node pool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), tt.params, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+			if result != "" {
+				t.Error("Expected empty result on error")
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package egress
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+)
+
+// Cluster outbound types, matching the AKS networkProfile.outboundType values
+const (
+	OutboundTypeLoadBalancer           = "loadBalancer"
+	OutboundTypeManagedNATGateway      = "managedNATGateway"
+	OutboundTypeUserAssignedNATGateway = "userAssignedNATGateway"
+	OutboundTypeUserDefinedRouting     = "userDefinedRouting"
+	OutboundTypeNone                   = "none"
+	OutboundTypeBlock                  = "block"
+)
+
+// Egress paths for traffic leaving a node
+const (
+	PathVirtualNetwork        = "VirtualNetwork"
+	PathVirtualAppliance      = "VirtualAppliance"
+	PathVirtualNetworkGateway = "VirtualNetworkGateway"
+	PathNATGateway            = "NATGateway"
+	PathLoadBalancer          = "LoadBalancerOutboundRules"
+	PathDefaultOutboundAccess = "DefaultOutboundAccess"
+	PathBlocked               = "Blocked"
+	PathDropped               = "Dropped"
+)
+
+// Input is the network configuration of a node subnet used to analyze egress
+type Input struct {
+	OutboundType string
+	// SubnetPrefixes are the address prefixes of the node subnet
+	SubnetPrefixes []netip.Prefix
+	// VNetPrefixes and PeeredPrefixes are the address spaces of the VNet and of its connected peerings
+	VNetPrefixes   []netip.Prefix
+	PeeredPrefixes []netip.Prefix
+	// RouteTableID is empty when no route table is associated with the subnet
+	RouteTableID string
+	Routes       []Route
+	// NATGatewayID is empty when no NAT gateway is associated with the subnet
+	NATGatewayID string
+	// HasPublicLoadBalancer is set when the cluster exposes services through a public load balancer
+	HasPublicLoadBalancer bool
+	// APIServerAddresses are the addresses nodes use to reach the API server
+	APIServerAddresses []netip.Addr
+	// PrivateAPIServer is set for private clusters and API server VNet integration
+	PrivateAPIServer bool
+}
+
+// Hop is the effective route and egress path for a destination
+type Hop struct {
+	Destination    string      `json:"destination"`
+	EffectiveRoute *RouteMatch `json:"effective_route,omitempty"`
+	NextHopType    string      `json:"next_hop_type"`
+	NextHopIP      string      `json:"next_hop_ip,omitempty"`
+	Path           string      `json:"path"`
+	Notes          []string    `json:"notes,omitempty"`
+}
+
+// Analysis is the egress analysis of a node subnet
+type Analysis struct {
+	Destination *Hop            `json:"destination,omitempty"`
+	APIServer   []Hop           `json:"api_server,omitempty"`
+	Findings    common.Findings `json:"findings"`
+}
+
+// Analyze resolves the effective next hop for the destination and the API server and
+// checks the subnet routing against the cluster outbound type
+func Analyze(input Input, destination netip.Addr, serviceTags *flowcheck.ServiceTagStore) Analysis {
+	routes := append(SystemRoutes(input.VNetPrefixes, input.PeeredPrefixes), input.Routes...)
+	analysis := Analysis{Findings: common.Findings{}}
+
+	if destination.IsValid() {
+		hop := resolveHop(input, routes, destination, serviceTags)
+		analysis.Destination = &hop
+		analysis.Findings = append(analysis.Findings, hopFindings(input, hop, "destination "+hop.Destination)...)
+	}
+
+	for _, address := range input.APIServerAddresses {
+		hop := resolveHop(input, routes, address, serviceTags)
+		analysis.APIServer = append(analysis.APIServer, hop)
+		analysis.Findings = append(analysis.Findings, apiServerFindings(input, hop)...)
+	}
+
+	analysis.Findings = append(analysis.Findings, outboundTypeFindings(input)...)
+	analysis.Findings = append(analysis.Findings, asymmetricRouteFindings(input)...)
+	return analysis
+}
+
+// resolveHop finds the effective route for an address and the egress path it takes
+func resolveHop(input Input, routes []Route, address netip.Addr, serviceTags *flowcheck.ServiceTagStore) Hop {
+	hop := Hop{Destination: address.String()}
+
+	match, notes := EffectiveRoute(routes, address, serviceTags)
+	hop.Notes = notes
+	if match == nil {
+		hop.NextHopType = NextHopNone
+		hop.Path = PathDropped
+		return hop
+	}
+
+	hop.EffectiveRoute = match
+	hop.NextHopType = match.Route.NextHopType
+	hop.NextHopIP = match.Route.NextHopIP
+	hop.Path = egressPath(input, match.Route.NextHopType)
+	return hop
+}
+
+// egressPath maps a next hop type to the way traffic leaves the subnet. Internet bound
+// traffic uses the subnet NAT gateway first, then the load balancer outbound rules.
+func egressPath(input Input, nextHopType string) string {
+	switch {
+	case strings.EqualFold(nextHopType, NextHopVnetLocal), strings.EqualFold(nextHopType, NextHopVNetPeering):
+		return PathVirtualNetwork
+	case strings.EqualFold(nextHopType, NextHopVirtualAppliance):
+		return PathVirtualAppliance
+	case strings.EqualFold(nextHopType, NextHopVirtualNetworkGateway):
+		return PathVirtualNetworkGateway
+	case strings.EqualFold(nextHopType, NextHopInternet):
+		switch {
+		case input.OutboundType == OutboundTypeBlock:
+			return PathBlocked
+		case input.NATGatewayID != "":
+			return PathNATGateway
+		case input.OutboundType == OutboundTypeLoadBalancer:
+			return PathLoadBalancer
+		default:
+			return PathDefaultOutboundAccess
+		}
+	default:
+		return PathDropped
+	}
+}
+
+// hopFindings flags a hop that drops traffic or points at an unreachable appliance
+func hopFindings(input Input, hop Hop, target string) []common.Finding {
+	var findings []common.Finding
+
+	switch hop.Path {
+	case PathDropped:
+		findings = append(findings, common.Finding{
+			Severity: common.SeverityError,
+			Code:     "route_dropped",
+			Message:  fmt.Sprintf("Traffic to %s is dropped: %s", target, describeRoute(hop)),
+		})
+	case PathVirtualAppliance:
+		if finding := applianceFinding(input, hop, target); finding != nil {
+			findings = append(findings, *finding)
+		}
+	case PathDefaultOutboundAccess:
+		findings = append(findings, common.Finding{
+			Severity: common.SeverityWarning,
+			Code:     "default_outbound_access",
+			Message: fmt.Sprintf("Traffic to %s goes to the internet without a NAT gateway or load balancer outbound rule and relies on default outbound access, which is being retired",
+				target),
+		})
+	case PathBlocked:
+		findings = append(findings, common.Finding{
+			Severity: common.SeverityError,
+			Code:     "outbound_blocked",
+			Message:  fmt.Sprintf("Traffic to %s goes to the internet but the cluster outbound type is block", target),
+		})
+	}
+
+	return findings
+}
+
+// applianceFinding flags a virtual appliance next hop outside the VNet and its peerings
+func applianceFinding(input Input, hop Hop, target string) *common.Finding {
+	nextHop, err := netip.ParseAddr(hop.NextHopIP)
+	if err != nil {
+		return &common.Finding{
+			Severity: common.SeverityError,
+			Code:     "appliance_next_hop_invalid",
+			Message:  fmt.Sprintf("Traffic to %s is routed to a virtual appliance with an invalid next hop IP '%s': %s", target, hop.NextHopIP, describeRoute(hop)),
+		}
+	}
+
+	for _, prefix := range append(append([]netip.Prefix{}, input.VNetPrefixes...), input.PeeredPrefixes...) {
+		if prefix.Contains(nextHop) {
+			return nil
+		}
+	}
+	return &common.Finding{
+		Severity: common.SeverityError,
+		Code:     "appliance_unreachable",
+		Message: fmt.Sprintf("Traffic to %s is routed to virtual appliance %s, which is outside the VNet and its connected peerings: %s",
+			target, hop.NextHopIP, describeRoute(hop)),
+	}
+}
+
+// apiServerFindings checks that nodes have a usable route to the API server
+func apiServerFindings(input Input, hop Hop) []common.Finding {
+	target := "the API server (" + hop.Destination + ")"
+	findings := hopFindings(input, hop, target)
+	for i := range findings {
+		if findings[i].Code == "route_dropped" {
+			findings[i].Code = "api_server_route_missing"
+		}
+	}
+
+	if input.PrivateAPIServer && hop.Path != PathVirtualNetwork && hop.Path != PathDropped {
+		findings = append(findings, common.Finding{
+			Severity: common.SeverityWarning,
+			Code:     "api_server_route_indirect",
+			Message: fmt.Sprintf("The private API server %s is not routed within the virtual network but via %s: %s",
+				hop.Destination, hop.Path, describeRoute(hop)),
+		})
+	}
+	if !input.PrivateAPIServer && hop.Path == PathVirtualAppliance {
+		findings = append(findings, common.Finding{
+			Severity: common.SeverityInfo,
+			Code:     "api_server_via_appliance",
+			Message: fmt.Sprintf("The API server %s is reached through virtual appliance %s, which must allow TCP 443 to it",
+				hop.Destination, hop.NextHopIP),
+		})
+	}
+
+	return findings
+}
+
+// outboundTypeFindings checks the subnet routing against the cluster outbound type
+func outboundTypeFindings(input Input) []common.Finding {
+	var findings []common.Finding
+	defaultRoute := FindRoute(input.Routes, defaultRoutePrefix)
+
+	switch input.OutboundType {
+	case OutboundTypeUserDefinedRouting:
+		switch {
+		case input.RouteTableID == "":
+			findings = append(findings, common.Finding{
+				Severity: common.SeverityError,
+				Code:     "udr_route_table_missing",
+				Message:  "The cluster uses userDefinedRouting but no route table is associated with the node subnet",
+			})
+		case defaultRoute == nil:
+			findings = append(findings, common.Finding{
+				Severity: common.SeverityError,
+				Code:     "udr_default_route_missing",
+				Message:  "The cluster uses userDefinedRouting but the route table has no 0.0.0.0/0 route",
+			})
+		case !strings.EqualFold(defaultRoute.NextHopType, NextHopVirtualAppliance) && !strings.EqualFold(defaultRoute.NextHopType, NextHopVirtualNetworkGateway):
+			findings = append(findings, common.Finding{
+				Severity: common.SeverityWarning,
+				Code:     "udr_default_route_not_appliance",
+				Message: fmt.Sprintf("The cluster uses userDefinedRouting but route %s sends 0.0.0.0/0 to %s instead of a firewall or virtual appliance",
+					defaultRoute.Name, defaultRoute.NextHopType),
+			})
+		}
+	case OutboundTypeUserAssignedNATGateway, OutboundTypeManagedNATGateway:
+		if input.NATGatewayID == "" {
+			findings = append(findings, common.Finding{
+				Severity: common.SeverityError,
+				Code:     "nat_gateway_missing",
+				Message:  fmt.Sprintf("The cluster uses %s but no NAT gateway is associated with the node subnet", input.OutboundType),
+			})
+		}
+		fallthrough
+	case OutboundTypeLoadBalancer:
+		if defaultRoute != nil && strings.EqualFold(defaultRoute.NextHopType, NextHopVirtualAppliance) {
+			findings = append(findings, common.Finding{
+				Severity: common.SeverityWarning,
+				Code:     "default_route_overrides_outbound_type",
+				Message: fmt.Sprintf("Route %s sends 0.0.0.0/0 to virtual appliance %s, so internet traffic bypasses the %s outbound configuration",
+					defaultRoute.Name, defaultRoute.NextHopIP, input.OutboundType),
+			})
+		}
+	}
+
+	return findings
+}
+
+// asymmetricRouteFindings flags routes that send traffic through an appliance on one
+// direction only
+func asymmetricRouteFindings(input Input) []common.Finding {
+	var findings []common.Finding
+
+	for _, route := range input.Routes {
+		if !strings.EqualFold(route.NextHopType, NextHopVirtualAppliance) {
+			continue
+		}
+		prefix, err := flowcheck.ParsePrefix(route.AddressPrefix)
+		if err != nil || prefix.Bits() == 0 {
+			continue
+		}
+		for _, vnetPrefix := range input.VNetPrefixes {
+			if prefix.Overlaps(vnetPrefix) {
+				findings = append(findings, common.Finding{
+					Severity: common.SeverityWarning,
+					Code:     "asymmetric_route",
+					Message: fmt.Sprintf("Route %s sends VNet address range %s to virtual appliance %s. Return traffic from the other side is delivered directly unless its subnet has a matching route, which causes asymmetric routing",
+						route.Name, route.AddressPrefix, route.NextHopIP),
+				})
+				break
+			}
+		}
+	}
+
+	defaultRoute := FindRoute(input.Routes, defaultRoutePrefix)
+	if input.HasPublicLoadBalancer && defaultRoute != nil && strings.EqualFold(defaultRoute.NextHopType, NextHopVirtualAppliance) {
+		findings = append(findings, common.Finding{
+			Severity: common.SeverityWarning,
+			Code:     "asymmetric_route",
+			Message: fmt.Sprintf("Route %s sends 0.0.0.0/0 to virtual appliance %s while the cluster has a public load balancer. Replies to inbound connections through the load balancer are routed through the appliance and dropped, unless routes for the client ranges use next hop Internet",
+				defaultRoute.Name, defaultRoute.NextHopIP),
+		})
+	}
+
+	return findings
+}
+
+// describeRoute describes the route used by a hop
+func describeRoute(hop Hop) string {
+	if hop.EffectiveRoute == nil {
+		return "no route matches"
+	}
+	route := hop.EffectiveRoute.Route
+	name := route.Name
+	if name == "" {
+		name = strings.ToLower(route.Source) + " route"
+	} else {
+		name = "route " + name
+	}
+	return fmt.Sprintf("%s %s next hop %s", name, route.AddressPrefix, route.NextHopType)
+}
@@ -0,0 +1,136 @@
+package egress
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func testInput(outboundType string, routes ...Route) Input {
+	input := Input{
+		OutboundType:   outboundType,
+		SubnetPrefixes: []netip.Prefix{netip.MustParsePrefix("10.224.0.0/16")},
+		VNetPrefixes:   []netip.Prefix{netip.MustParsePrefix("10.224.0.0/12")},
+		Routes:         routes,
+	}
+	if len(routes) > 0 {
+		input.RouteTableID = "route-table"
+	}
+	return input
+}
+
+func TestAnalyzeEgressPath(t *testing.T) {
+	firewall := userRoute("default-to-firewall", "0.0.0.0/0", NextHopVirtualAppliance, "10.224.255.4")
+
+	tests := []struct {
+		name  string
+		input Input
+		path  string
+	}{
+		{"load balancer", testInput(OutboundTypeLoadBalancer), PathLoadBalancer},
+		{"nat gateway", func() Input {
+			input := testInput(OutboundTypeUserAssignedNATGateway)
+			input.NATGatewayID = "nat-gateway"
+			return input
+		}(), PathNATGateway},
+		{"user defined routing", testInput(OutboundTypeUserDefinedRouting, firewall), PathVirtualAppliance},
+		{"block", testInput(OutboundTypeBlock), PathBlocked},
+		{"none", testInput(OutboundTypeNone), PathDefaultOutboundAccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := Analyze(tt.input, netip.MustParseAddr("20.50.1.1"), nil)
+			if analysis.Destination == nil {
+				t.Fatal("Expected destination hop")
+			}
+			if analysis.Destination.Path != tt.path {
+				t.Errorf("Expected path %s, got %s", tt.path, analysis.Destination.Path)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUserDefinedRoutingFindings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		code  string
+	}{
+		{"missing route table", testInput(OutboundTypeUserDefinedRouting), "udr_route_table_missing"},
+		{"missing default route", testInput(OutboundTypeUserDefinedRouting,
+			userRoute("onprem", "192.168.0.0/16", NextHopVirtualNetworkGateway, "")), "udr_default_route_missing"},
+		{"default route to internet", testInput(OutboundTypeUserDefinedRouting,
+			userRoute("default", "0.0.0.0/0", NextHopInternet, "")), "udr_default_route_not_appliance"},
+		{"unreachable appliance", testInput(OutboundTypeUserDefinedRouting,
+			userRoute("default", "0.0.0.0/0", NextHopVirtualAppliance, "172.16.0.4")), "appliance_unreachable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := Analyze(tt.input, netip.MustParseAddr("20.50.1.1"), nil)
+			if !analysis.Findings.Has(tt.code) {
+				t.Errorf("Expected finding %s, got %+v", tt.code, analysis.Findings)
+			}
+		})
+	}
+}
+
+func TestAnalyzeNATGatewayMissing(t *testing.T) {
+	analysis := Analyze(testInput(OutboundTypeUserAssignedNATGateway), netip.Addr{}, nil)
+	if !analysis.Findings.Has("nat_gateway_missing") {
+		t.Errorf("Expected nat_gateway_missing finding, got %+v", analysis.Findings)
+	}
+	if analysis.Destination != nil {
+		t.Error("Expected no destination hop without a destination")
+	}
+}
+
+func TestAnalyzeAPIServerRoute(t *testing.T) {
+	input := testInput(OutboundTypeUserDefinedRouting,
+		userRoute("default", "0.0.0.0/0", NextHopVirtualAppliance, "10.224.255.4"),
+		userRoute("blackhole", "20.60.0.0/16", NextHopNone, ""),
+	)
+	input.APIServerAddresses = []netip.Addr{netip.MustParseAddr("20.60.1.1")}
+
+	analysis := Analyze(input, netip.Addr{}, nil)
+	if len(analysis.APIServer) != 1 || analysis.APIServer[0].Path != PathDropped {
+		t.Fatalf("Expected the API server route to be dropped, got %+v", analysis.APIServer)
+	}
+	if !analysis.Findings.Has("api_server_route_missing") {
+		t.Errorf("Expected api_server_route_missing finding, got %+v", analysis.Findings)
+	}
+
+	input.Routes = input.Routes[:1]
+	analysis = Analyze(input, netip.Addr{}, nil)
+	if !analysis.Findings.Has("api_server_via_appliance") {
+		t.Errorf("Expected api_server_via_appliance finding, got %+v", analysis.Findings)
+	}
+
+	input.PrivateAPIServer = true
+	analysis = Analyze(input, netip.Addr{}, nil)
+	if !analysis.Findings.Has("api_server_route_indirect") {
+		t.Errorf("Expected api_server_route_indirect finding, got %+v", analysis.Findings)
+	}
+}
+
+func TestAnalyzeAsymmetricRoutes(t *testing.T) {
+	firewall := userRoute("default", "0.0.0.0/0", NextHopVirtualAppliance, "10.224.255.4")
+	intraVNet := userRoute("spoke-via-firewall", "10.230.0.0/16", NextHopVirtualAppliance, "10.224.255.4")
+
+	analysis := Analyze(testInput(OutboundTypeUserDefinedRouting, firewall, intraVNet), netip.Addr{}, nil)
+	if !analysis.Findings.Has("asymmetric_route") {
+		t.Errorf("Expected asymmetric_route finding for intra-VNet route, got %+v", analysis.Findings)
+	}
+
+	input := testInput(OutboundTypeLoadBalancer, firewall)
+	input.HasPublicLoadBalancer = true
+	analysis = Analyze(input, netip.Addr{}, nil)
+	if !analysis.Findings.Has("asymmetric_route") || !analysis.Findings.Has("default_route_overrides_outbound_type") {
+		t.Errorf("Expected asymmetric and override findings, got %+v", analysis.Findings)
+	}
+
+	analysis = Analyze(testInput(OutboundTypeLoadBalancer), netip.Addr{}, nil)
+	if len(analysis.Findings) != 0 {
+		t.Errorf("Expected no findings for a default load balancer setup, got %+v", analysis.Findings)
+	}
+}
@@ -0,0 +1,183 @@
+package egress
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// Next hop types, matching the route table API values
+const (
+	NextHopVnetLocal             = "VnetLocal"
+	NextHopVNetPeering           = "VNetPeering"
+	NextHopInternet              = "Internet"
+	NextHopVirtualAppliance      = "VirtualAppliance"
+	NextHopVirtualNetworkGateway = "VirtualNetworkGateway"
+	NextHopNone                  = "None"
+)
+
+// Route sources, matching the effective routes API values
+const (
+	RouteSourceUser    = "User"
+	RouteSourceDefault = "Default"
+)
+
+// Prefixes of the default routes to the internet
+const (
+	defaultRoutePrefix     = "0.0.0.0/0"
+	defaultIPv6RoutePrefix = "::/0"
+)
+
+// droppedPrefixes are the private and shared address ranges Azure drops by default
+// unless they are part of the virtual network address space
+var droppedPrefixes = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10"}
+
+// Route is a system or user defined route
+type Route struct {
+	Name          string `json:"name,omitempty"`
+	AddressPrefix string `json:"address_prefix"`
+	NextHopType   string `json:"next_hop_type"`
+	NextHopIP     string `json:"next_hop_ip,omitempty"`
+	Source        string `json:"source"`
+}
+
+// RouteMatch is the route selected for a destination
+type RouteMatch struct {
+	Route Route `json:"route"`
+	// MatchedPrefix is the prefix that matched, which differs from the route address prefix for service tag routes
+	MatchedPrefix string `json:"matched_prefix"`
+}
+
+// SystemRoutes returns the default routes Azure creates for a subnet. The VNet and
+// peered address spaces are routed within Azure, everything else goes to the internet
+// except the private and shared address ranges, which are dropped.
+func SystemRoutes(vnetPrefixes, peeredPrefixes []netip.Prefix) []Route {
+	var routes []Route
+	for _, prefix := range vnetPrefixes {
+		routes = append(routes, Route{AddressPrefix: prefix.String(), NextHopType: NextHopVnetLocal, Source: RouteSourceDefault})
+	}
+	for _, prefix := range peeredPrefixes {
+		routes = append(routes, Route{AddressPrefix: prefix.String(), NextHopType: NextHopVNetPeering, Source: RouteSourceDefault})
+	}
+	routes = append(routes,
+		Route{AddressPrefix: defaultRoutePrefix, NextHopType: NextHopInternet, Source: RouteSourceDefault},
+		Route{AddressPrefix: defaultIPv6RoutePrefix, NextHopType: NextHopInternet, Source: RouteSourceDefault},
+	)
+	for _, prefix := range droppedPrefixes {
+		routes = append(routes, Route{AddressPrefix: prefix, NextHopType: NextHopNone, Source: RouteSourceDefault})
+	}
+	return routes
+}
+
+// RoutesFromTable returns the user defined routes of a route table
+func RoutesFromTable(routeTable *armnetwork.RouteTable) []Route {
+	if routeTable == nil || routeTable.Properties == nil {
+		return nil
+	}
+
+	var routes []Route
+	for _, route := range routeTable.Properties.Routes {
+		if route == nil || route.Properties == nil || route.Properties.AddressPrefix == nil {
+			continue
+		}
+		converted := Route{
+			AddressPrefix: *route.Properties.AddressPrefix,
+			Source:        RouteSourceUser,
+		}
+		if route.Name != nil {
+			converted.Name = *route.Name
+		}
+		if route.Properties.NextHopType != nil {
+			converted.NextHopType = string(*route.Properties.NextHopType)
+		}
+		if route.Properties.NextHopIPAddress != nil {
+			converted.NextHopIP = *route.Properties.NextHopIPAddress
+		}
+		routes = append(routes, converted)
+	}
+	return routes
+}
+
+// FindRoute returns the user defined route with the given address prefix, or nil
+func FindRoute(routes []Route, addressPrefix string) *Route {
+	for i := range routes {
+		if routes[i].Source == RouteSourceUser && strings.EqualFold(routes[i].AddressPrefix, addressPrefix) {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// candidate is a route prefix that contains the destination
+type candidate struct {
+	route      Route
+	prefix     netip.Prefix
+	serviceTag bool
+}
+
+// EffectiveRoute selects the route Azure uses for a destination: the longest matching
+// prefix wins, user defined routes win over system routes with the same prefix, and
+// explicit prefixes win over service tag prefixes of the same length. Routes using a
+// service tag are expanded with the service tags file; tags that cannot be resolved
+// are reported in the returned notes.
+func EffectiveRoute(routes []Route, destination netip.Addr, serviceTags *flowcheck.ServiceTagStore) (*RouteMatch, []string) {
+	var best *candidate
+	var notes []string
+
+	for _, route := range routes {
+		for _, c := range routeCandidates(route, destination, serviceTags, &notes) {
+			if best == nil || betterCandidate(c, *best) {
+				selected := c
+				best = &selected
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, notes
+	}
+	return &RouteMatch{Route: best.route, MatchedPrefix: best.prefix.String()}, notes
+}
+
+// routeCandidates returns the prefixes of a route that contain the destination
+func routeCandidates(route Route, destination netip.Addr, serviceTags *flowcheck.ServiceTagStore, notes *[]string) []candidate {
+	if prefix, err := flowcheck.ParsePrefix(route.AddressPrefix); err == nil {
+		if prefix.Contains(destination) {
+			return []candidate{{route: route, prefix: prefix}}
+		}
+		return nil
+	}
+
+	prefixes, ok, err := serviceTags.LookupPrefixes(route.AddressPrefix)
+	if err != nil || !ok {
+		*notes = append(*notes, fmt.Sprintf("route %s uses service tag %s which could not be resolved. Configure an up to date service tags file to evaluate it",
+			route.Name, route.AddressPrefix))
+		return nil
+	}
+
+	var candidates []candidate
+	for _, prefix := range prefixes {
+		if prefix.Contains(destination) {
+			candidates = append(candidates, candidate{route: route, prefix: prefix, serviceTag: true})
+		}
+	}
+	return candidates
+}
+
+// betterCandidate reports whether c takes precedence over current
+func betterCandidate(c, current candidate) bool {
+	if c.prefix.Bits() != current.prefix.Bits() {
+		return c.prefix.Bits() > current.prefix.Bits()
+	}
+	if c.route.Source != current.route.Source {
+		return c.route.Source == RouteSourceUser
+	}
+	if c.serviceTag != current.serviceTag {
+		return !c.serviceTag
+	}
+	// Keep the first route for equal system routes, so the VNet routes win over the dropped ranges
+	return false
+}
@@ -0,0 +1,160 @@
+package egress
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func userRoute(name, prefix, nextHopType, nextHopIP string) Route {
+	return Route{Name: name, AddressPrefix: prefix, NextHopType: nextHopType, NextHopIP: nextHopIP, Source: RouteSourceUser}
+}
+
+func testSystemRoutes() []Route {
+	return SystemRoutes(
+		[]netip.Prefix{netip.MustParsePrefix("10.224.0.0/12")},
+		[]netip.Prefix{netip.MustParsePrefix("10.100.0.0/16")},
+	)
+}
+
+func TestEffectiveRouteSystemRoutes(t *testing.T) {
+	routes := testSystemRoutes()
+
+	tests := []struct {
+		destination string
+		nextHopType string
+		prefix      string
+	}{
+		{"10.224.1.4", NextHopVnetLocal, "10.224.0.0/12"},
+		{"10.100.0.4", NextHopVNetPeering, "10.100.0.0/16"},
+		{"10.1.0.4", NextHopNone, "10.0.0.0/8"},
+		{"192.168.1.1", NextHopNone, "192.168.0.0/16"},
+		{"20.50.1.1", NextHopInternet, "0.0.0.0/0"},
+		{"2001:db8::1", NextHopInternet, "::/0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			match, _ := EffectiveRoute(routes, netip.MustParseAddr(tt.destination), nil)
+			if match == nil {
+				t.Fatal("Expected a route to match")
+			}
+			if match.Route.NextHopType != tt.nextHopType || match.MatchedPrefix != tt.prefix {
+				t.Errorf("Expected %s via %s, got %s via %s", tt.nextHopType, tt.prefix, match.Route.NextHopType, match.MatchedPrefix)
+			}
+		})
+	}
+}
+
+func TestEffectiveRouteUserRoutes(t *testing.T) {
+	routes := append(testSystemRoutes(),
+		userRoute("default-to-firewall", "0.0.0.0/0", NextHopVirtualAppliance, "10.224.255.4"),
+		userRoute("firewall-public-ip", "20.50.1.1/32", NextHopInternet, ""),
+		userRoute("onprem", "10.1.0.0/16", NextHopVirtualNetworkGateway, ""),
+	)
+
+	tests := []struct {
+		destination string
+		route       string
+	}{
+		{"8.8.8.8", "default-to-firewall"},
+		{"20.50.1.1", "firewall-public-ip"},
+		{"10.1.2.3", "onprem"},
+		{"10.224.0.10", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			match, _ := EffectiveRoute(routes, netip.MustParseAddr(tt.destination), nil)
+			if match == nil {
+				t.Fatal("Expected a route to match")
+			}
+			if match.Route.Name != tt.route {
+				t.Errorf("Expected route %q, got %q", tt.route, match.Route.Name)
+			}
+		})
+	}
+}
+
+func TestEffectiveRouteUserRouteWinsOverSystemRoute(t *testing.T) {
+	routes := append(testSystemRoutes(), userRoute("vnet-to-firewall", "10.224.0.0/12", NextHopVirtualAppliance, "10.224.255.4"))
+
+	match, _ := EffectiveRoute(routes, netip.MustParseAddr("10.224.1.4"), nil)
+	if match == nil || match.Route.Name != "vnet-to-firewall" {
+		t.Errorf("Expected user route to override the system route, got %+v", match)
+	}
+}
+
+func TestEffectiveRouteServiceTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ServiceTags_Public.json")
+	data := `{"changeNumber": 1, "values": [{"name": "AzureCloud", "properties": {"addressPrefixes": ["20.50.0.0/16"]}}]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write service tags file: %v", err)
+	}
+	store := flowcheck.NewServiceTagStore(path)
+
+	routes := append(testSystemRoutes(),
+		userRoute("default-to-firewall", "0.0.0.0/0", NextHopVirtualAppliance, "10.224.255.4"),
+		userRoute("azure-direct", "AzureCloud", NextHopInternet, ""),
+		userRoute("explicit", "20.50.0.0/16", NextHopVirtualAppliance, "10.224.255.4"),
+	)
+
+	match, notes := EffectiveRoute(routes, netip.MustParseAddr("20.50.1.1"), store)
+	if len(notes) != 0 {
+		t.Errorf("Expected no notes, got %v", notes)
+	}
+	if match == nil || match.Route.Name != "explicit" {
+		t.Errorf("Expected explicit prefix to win over service tag of the same length, got %+v", match)
+	}
+
+	routes = routes[:len(routes)-1]
+	match, _ = EffectiveRoute(routes, netip.MustParseAddr("20.50.1.1"), store)
+	if match == nil || match.Route.Name != "azure-direct" || match.MatchedPrefix != "20.50.0.0/16" {
+		t.Errorf("Expected service tag route to win over the default route, got %+v", match)
+	}
+
+	_, notes = EffectiveRoute(routes, netip.MustParseAddr("20.50.1.1"), nil)
+	if len(notes) != 1 || !strings.Contains(notes[0], "AzureCloud") {
+		t.Errorf("Expected a note about the unresolved service tag, got %v", notes)
+	}
+}
+
+func TestRoutesFromTable(t *testing.T) {
+	routeTable := &armnetwork.RouteTable{
+		Properties: &armnetwork.RouteTablePropertiesFormat{
+			Routes: []*armnetwork.Route{
+				{
+					Name: to.Ptr("default"),
+					Properties: &armnetwork.RoutePropertiesFormat{
+						AddressPrefix:    to.Ptr("0.0.0.0/0"),
+						NextHopType:      to.Ptr(armnetwork.RouteNextHopTypeVirtualAppliance),
+						NextHopIPAddress: to.Ptr("10.0.0.4"),
+					},
+				},
+				{Name: to.Ptr("invalid")},
+			},
+		},
+	}
+
+	routes := RoutesFromTable(routeTable)
+	if len(routes) != 1 {
+		t.Fatalf("Expected 1 route, got %d", len(routes))
+	}
+	expected := userRoute("default", "0.0.0.0/0", NextHopVirtualAppliance, "10.0.0.4")
+	if routes[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, routes[0])
+	}
+
+	if FindRoute(routes, "0.0.0.0/0") == nil {
+		t.Error("Expected to find the default route")
+	}
+	if RoutesFromTable(nil) != nil {
+		t.Error("Expected no routes for nil route table")
+	}
+}
@@ -0,0 +1,352 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/egress"
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// EgressAnalysisResult is the result of an egress analysis
+type EgressAnalysisResult struct {
+	OutboundType      string                 `json:"outbound_type"`
+	Destination       string                 `json:"destination,omitempty"`
+	APIServer         string                 `json:"api_server,omitempty"`
+	LoadBalancerRules []LoadBalancerOutbound `json:"load_balancer_outbound_rules,omitempty"`
+	NodePools         []NodePoolEgress       `json:"node_pools"`
+	Notes             []string               `json:"notes,omitempty"`
+}
+
+// NodePoolEgress is the egress analysis of a node pool subnet
+type NodePoolEgress struct {
+	NodePool     string              `json:"node_pool"`
+	SubnetID     string              `json:"subnet_id"`
+	RouteTableID string              `json:"route_table_id,omitempty"`
+	NATGateway   *NATGatewayOutbound `json:"nat_gateway,omitempty"`
+	egress.Analysis
+}
+
+// LoadBalancerOutbound is an outbound rule of a cluster load balancer
+type LoadBalancerOutbound struct {
+	LoadBalancer           string `json:"load_balancer"`
+	Rule                   string `json:"rule"`
+	Protocol               string `json:"protocol,omitempty"`
+	FrontendIPs            int    `json:"frontend_ips"`
+	AllocatedOutboundPorts int32  `json:"allocated_outbound_ports"`
+	IdleTimeoutMinutes     int32  `json:"idle_timeout_minutes,omitempty"`
+}
+
+// NATGatewayOutbound summarizes the NAT gateway associated with a subnet
+type NATGatewayOutbound struct {
+	ID                 string `json:"id"`
+	PublicIPs          int    `json:"public_ips"`
+	PublicIPPrefixes   int    `json:"public_ip_prefixes"`
+	IdleTimeoutMinutes int32  `json:"idle_timeout_minutes,omitempty"`
+}
+
+// handleEgressAnalysis analyzes the egress path of the cluster node pools
+func handleEgressAnalysis(ctx context.Context, client *azureclient.AzureClient, serviceTags *flowcheck.ServiceTagStore, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	destination, err := parseEgressDestination(params)
+	if err != nil {
+		return "", err
+	}
+	nodePool, _ := params["node_pool"].(string)
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, nodePool)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve node pool networks: %v", err)
+	}
+
+	result := &EgressAnalysisResult{
+		OutboundType: clusterOutboundType(cluster),
+		NodePools:    []NodePoolEgress{},
+	}
+	if destination.IsValid() {
+		result.Destination = destination.String()
+	}
+
+	apiServer, apiServerAddresses, privateAPIServer, notes := resolveAPIServerAddresses(ctx, client, cluster)
+	result.APIServer = apiServer
+	result.Notes = append(result.Notes, notes...)
+
+	loadBalancers, hasPublicLoadBalancer, err := loadBalancerOutboundRules(ctx, client, cluster)
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("could not load cluster load balancers: %v", err))
+	}
+	result.LoadBalancerRules = loadBalancers
+
+	for _, network := range networks {
+		poolEgress := NodePoolEgress{NodePool: network.NodePool, SubnetID: network.SubnetID}
+		input, natGatewayID, err := loadEgressInput(ctx, client, network)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("node pool %s: %v", network.NodePool, err))
+			continue
+		}
+		input.OutboundType = result.OutboundType
+		input.HasPublicLoadBalancer = hasPublicLoadBalancer
+		input.APIServerAddresses = apiServerAddresses
+		input.PrivateAPIServer = privateAPIServer
+
+		poolEgress.RouteTableID = input.RouteTableID
+		if natGatewayID != "" {
+			poolEgress.NATGateway = loadNATGatewayOutbound(ctx, client, natGatewayID)
+		}
+		poolEgress.Analysis = egress.Analyze(input, destination, serviceTags)
+		result.NodePools = append(result.NodePools, poolEgress)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal egress analysis to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// parseEgressDestination parses the optional destination IP address of an egress analysis
+func parseEgressDestination(params map[string]interface{}) (netip.Addr, error) {
+	value, _ := params["destination"].(string)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return netip.Addr{}, nil
+	}
+
+	destination, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid destination '%s', must be an IP address", value)
+	}
+	return destination.Unmap(), nil
+}
+
+// clusterOutboundType returns the outbound type of a cluster, defaulting to loadBalancer
+func clusterOutboundType(cluster *armcontainerservice.ManagedCluster) string {
+	if cluster != nil && cluster.Properties != nil && cluster.Properties.NetworkProfile != nil &&
+		cluster.Properties.NetworkProfile.OutboundType != nil {
+		return string(*cluster.Properties.NetworkProfile.OutboundType)
+	}
+	return egress.OutboundTypeLoadBalancer
+}
+
+// loadEgressInput loads the routing configuration of a node pool subnet
+func loadEgressInput(ctx context.Context, client *azureclient.AzureClient, network resourcehelpers.NodePoolNetwork) (egress.Input, string, error) {
+	input := egress.Input{RouteTableID: network.RouteTableID}
+	if len(network.Errors) > 0 {
+		return input, "", fmt.Errorf("%s", strings.Join(network.Errors, "; "))
+	}
+	if network.SubnetID == "" {
+		return input, "", fmt.Errorf("could not determine subnet")
+	}
+
+	subnet, err := resourcehelpers.GetSubnetByID(ctx, client, network.SubnetID)
+	if err != nil {
+		return input, "", err
+	}
+	if input.SubnetPrefixes, err = resourcehelpers.GetSubnetAddressPrefixes(subnet); err != nil {
+		return input, "", err
+	}
+	if input.VNetPrefixes, input.PeeredPrefixes, err = resourcehelpers.GetVirtualNetworkAddressSpace(ctx, client, network.SubnetID); err != nil {
+		return input, "", err
+	}
+
+	var natGatewayID string
+	if subnet.Properties.NatGateway != nil && subnet.Properties.NatGateway.ID != nil {
+		natGatewayID = *subnet.Properties.NatGateway.ID
+		input.NATGatewayID = natGatewayID
+	}
+
+	if input.RouteTableID != "" {
+		parsedRouteTableID, err := arm.ParseResourceID(input.RouteTableID)
+		if err != nil {
+			return input, "", fmt.Errorf("failed to parse route table ID: %v", err)
+		}
+		routeTable, err := client.GetRouteTable(ctx, parsedRouteTableID.SubscriptionID, parsedRouteTableID.ResourceGroupName, parsedRouteTableID.Name)
+		if err != nil {
+			return input, "", fmt.Errorf("failed to get route table: %v", err)
+		}
+		input.Routes = egress.RoutesFromTable(routeTable)
+	}
+
+	return input, natGatewayID, nil
+}
+
+// resolveAPIServerAddresses returns the addresses nodes use to reach the API server. Private clusters
+// are reached through the private endpoint and public clusters through the public FQDN, which is
+// resolved with DNS.
+func resolveAPIServerAddresses(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster) (string, []netip.Addr, bool, []string) {
+	if cluster == nil || cluster.Properties == nil {
+		return "", nil, false, nil
+	}
+	props := cluster.Properties
+	access := props.APIServerAccessProfile
+
+	if access != nil && access.EnablePrivateCluster != nil && *access.EnablePrivateCluster {
+		name := ""
+		if props.PrivateFQDN != nil {
+			name = *props.PrivateFQDN
+		}
+		addresses, err := privateEndpointAddresses(ctx, client, cluster)
+		if err != nil {
+			return name, nil, true, []string{fmt.Sprintf("could not resolve the API server private endpoint: %v", err)}
+		}
+		return name, addresses, true, nil
+	}
+
+	if props.Fqdn == nil {
+		return "", nil, false, nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", *props.Fqdn)
+	if err != nil {
+		return *props.Fqdn, nil, false, []string{fmt.Sprintf("could not resolve the API server FQDN %s: %v", *props.Fqdn, err)}
+	}
+	addresses := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, ip.Unmap())
+	}
+	return *props.Fqdn, addresses, false, nil
+}
+
+// privateEndpointAddresses returns the IP addresses of the API server private endpoint
+func privateEndpointAddresses(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster) ([]netip.Addr, error) {
+	privateEndpointID, err := resourcehelpers.GetPrivateEndpointIDFromAKS(ctx, cluster, client)
+	if err != nil {
+		return nil, err
+	}
+	if privateEndpointID == "" {
+		return nil, fmt.Errorf("no private endpoint found")
+	}
+
+	privateEndpoint, err := client.GetPrivateEndpointByID(ctx, privateEndpointID)
+	if err != nil {
+		return nil, err
+	}
+	if privateEndpoint.Properties == nil {
+		return nil, fmt.Errorf("private endpoint has no properties")
+	}
+
+	var addresses []netip.Addr
+	for _, dnsConfig := range privateEndpoint.Properties.CustomDNSConfigs {
+		if dnsConfig == nil {
+			continue
+		}
+		for _, value := range dnsConfig.IPAddresses {
+			if value == nil {
+				continue
+			}
+			if address, err := netip.ParseAddr(*value); err == nil {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("private endpoint has no IP addresses")
+	}
+	return addresses, nil
+}
+
+// loadBalancerOutboundRules returns the outbound rules of the cluster load balancers and
+// whether any of them has a public frontend
+func loadBalancerOutboundRules(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster) ([]LoadBalancerOutbound, bool, error) {
+	lbIDs, err := resourcehelpers.GetLoadBalancerIDsFromAKS(ctx, cluster, client)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var rules []LoadBalancerOutbound
+	hasPublicFrontend := false
+	for _, lbID := range lbIDs {
+		parsedLBID, err := arm.ParseResourceID(lbID)
+		if err != nil {
+			return rules, hasPublicFrontend, fmt.Errorf("failed to parse load balancer ID: %v", err)
+		}
+		lb, err := client.GetLoadBalancer(ctx, parsedLBID.SubscriptionID, parsedLBID.ResourceGroupName, parsedLBID.Name)
+		if err != nil {
+			return rules, hasPublicFrontend, err
+		}
+		lbRules, public := outboundRulesFromLoadBalancer(lb)
+		rules = append(rules, lbRules...)
+		hasPublicFrontend = hasPublicFrontend || public
+	}
+	return rules, hasPublicFrontend, nil
+}
+
+// outboundRulesFromLoadBalancer flattens the outbound rules of a load balancer and reports
+// whether it has a public frontend
+func outboundRulesFromLoadBalancer(lb *armnetwork.LoadBalancer) ([]LoadBalancerOutbound, bool) {
+	if lb == nil || lb.Properties == nil {
+		return nil, false
+	}
+
+	name := ""
+	if lb.Name != nil {
+		name = *lb.Name
+	}
+
+	public := false
+	for _, frontend := range lb.Properties.FrontendIPConfigurations {
+		if frontend != nil && frontend.Properties != nil && frontend.Properties.PublicIPAddress != nil {
+			public = true
+		}
+	}
+
+	var rules []LoadBalancerOutbound
+	for _, rule := range lb.Properties.OutboundRules {
+		if rule == nil || rule.Properties == nil {
+			continue
+		}
+		outbound := LoadBalancerOutbound{
+			LoadBalancer: name,
+			FrontendIPs:  len(rule.Properties.FrontendIPConfigurations),
+		}
+		if rule.Name != nil {
+			outbound.Rule = *rule.Name
+		}
+		if rule.Properties.Protocol != nil {
+			outbound.Protocol = string(*rule.Properties.Protocol)
+		}
+		if rule.Properties.AllocatedOutboundPorts != nil {
+			outbound.AllocatedOutboundPorts = *rule.Properties.AllocatedOutboundPorts
+		}
+		if rule.Properties.IdleTimeoutInMinutes != nil {
+			outbound.IdleTimeoutMinutes = *rule.Properties.IdleTimeoutInMinutes
+		}
+		rules = append(rules, outbound)
+	}
+	return rules, public
+}
+
+// loadNATGatewayOutbound summarizes a NAT gateway, returning only its ID if it cannot be loaded
+func loadNATGatewayOutbound(ctx context.Context, client *azureclient.AzureClient, natGatewayID string) *NATGatewayOutbound {
+	summary := &NATGatewayOutbound{ID: natGatewayID}
+
+	parsedID, err := arm.ParseResourceID(natGatewayID)
+	if err != nil {
+		return summary
+	}
+	natGateway, err := client.GetNatGateway(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+	if err != nil || natGateway.Properties == nil {
+		return summary
+	}
+
+	summary.PublicIPs = len(natGateway.Properties.PublicIPAddresses)
+	summary.PublicIPPrefixes = len(natGateway.Properties.PublicIPPrefixes)
+	if natGateway.Properties.IdleTimeoutInMinutes != nil {
+		summary.IdleTimeoutMinutes = *natGateway.Properties.IdleTimeoutInMinutes
+	}
+	return summary
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/egress"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func TestParseEgressDestination(t *testing.T) {
+	destination, err := parseEgressDestination(map[string]interface{}{"destination": " 20.50.1.1 "})
+	if err != nil || destination.String() != "20.50.1.1" {
+		t.Errorf("Expected 20.50.1.1, got %v err=%v", destination, err)
+	}
+
+	destination, err = parseEgressDestination(map[string]interface{}{})
+	if err != nil || destination.IsValid() {
+		t.Errorf("Expected no destination, got %v err=%v", destination, err)
+	}
+
+	if _, err := parseEgressDestination(map[string]interface{}{"destination": "mcr.microsoft.com"}); err == nil {
+		t.Error("Expected error for host name destination")
+	}
+}
+
+func TestClusterOutboundType(t *testing.T) {
+	if got := clusterOutboundType(&armcontainerservice.ManagedCluster{}); got != egress.OutboundTypeLoadBalancer {
+		t.Errorf("Expected default outbound type loadBalancer, got %s", got)
+	}
+
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			NetworkProfile: &armcontainerservice.NetworkProfile{
+				OutboundType: to.Ptr(armcontainerservice.OutboundTypeUserDefinedRouting),
+			},
+		},
+	}
+	if got := clusterOutboundType(cluster); got != egress.OutboundTypeUserDefinedRouting {
+		t.Errorf("Expected userDefinedRouting, got %s", got)
+	}
+}
+
+func TestOutboundRulesFromLoadBalancer(t *testing.T) {
+	lb := &armnetwork.LoadBalancer{
+		Name: to.Ptr("kubernetes"),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PublicIPAddress: &armnetwork.PublicIPAddress{ID: to.Ptr("pip")}}},
+			},
+			OutboundRules: []*armnetwork.OutboundRule{
+				{
+					Name: to.Ptr("aksOutboundRule"),
+					Properties: &armnetwork.OutboundRulePropertiesFormat{
+						Protocol:                 to.Ptr(armnetwork.LoadBalancerOutboundRuleProtocolAll),
+						AllocatedOutboundPorts:   to.Ptr[int32](1024),
+						IdleTimeoutInMinutes:     to.Ptr[int32](30),
+						FrontendIPConfigurations: []*armnetwork.SubResource{{ID: to.Ptr("frontend")}},
+					},
+				},
+			},
+		},
+	}
+
+	rules, public := outboundRulesFromLoadBalancer(lb)
+	if !public {
+		t.Error("Expected load balancer to have a public frontend")
+	}
+	expected := LoadBalancerOutbound{
+		LoadBalancer:           "kubernetes",
+		Rule:                   "aksOutboundRule",
+		Protocol:               "All",
+		FrontendIPs:            1,
+		AllocatedOutboundPorts: 1024,
+		IdleTimeoutMinutes:     30,
+	}
+	if len(rules) != 1 || rules[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, rules)
+	}
+
+	if rules, public := outboundRulesFromLoadBalancer(nil); rules != nil || public {
+		t.Error("Expected no rules for nil load balancer")
+	}
+}
+
+func TestEgressAnalysisInvalidDestination(t *testing.T) {
+	cfg := &config.ConfigData{}
+	handler := GetAksNetworkDiagnosticsHandler(nil, cfg)
+
+	params := map[string]interface{}{
+		"operation":       string(OpEgressAnalysis),
+		"subscription_id": "sub-123",
+		"resource_group":  "rg-test",
+		"cluster_name":    "cluster",
+		"parameters":      `{"destination":"not-an-ip"}`,
+	}
+	_, err := handler.Handle(context.Background(), params, cfg)
+	if err == nil || !strings.Contains(err.Error(), "invalid destination") {
+		t.Errorf("Expected invalid destination error, got %v", err)
+	}
+}
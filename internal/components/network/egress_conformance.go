@@ -0,0 +1,325 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/egress"
+	"github.com/Azure/aks-mcp/internal/components/network/egressrules"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// maxBasePolicyDepth bounds the firewall policy inheritance chain that is followed
+const maxBasePolicyDepth = 5
+
+// EgressConformanceResult is the result of checking the required AKS egress rules against the firewalls on the egress path
+type EgressConformanceResult struct {
+	RuleList  EgressRuleList        `json:"rule_list"`
+	NodePools []NodePoolEgressPath  `json:"node_pools"`
+	Firewalls []FirewallConformance `json:"firewalls"`
+	// RequiredRules lists the rules when no firewall could be evaluated
+	RequiredRules []egressrules.Rule `json:"required_rules,omitempty"`
+	Notes         []string           `json:"notes,omitempty"`
+}
+
+// EgressRuleList describes the rule list selected for the cluster
+type EgressRuleList struct {
+	Version   string   `json:"version"`
+	Reference string   `json:"reference,omitempty"`
+	Source    string   `json:"source"`
+	Cloud     string   `json:"cloud"`
+	Region    string   `json:"region"`
+	Features  []string `json:"features"`
+	Rules     int      `json:"rules"`
+}
+
+// NodePoolEgressPath is the default route of a node pool subnet and the firewall it leads to
+type NodePoolEgressPath struct {
+	NodePool    string `json:"node_pool"`
+	SubnetID    string `json:"subnet_id,omitempty"`
+	NextHopType string `json:"next_hop_type,omitempty"`
+	NextHopIP   string `json:"next_hop_ip,omitempty"`
+	FirewallID  string `json:"firewall_id,omitempty"`
+}
+
+// FirewallConformance is the conformance of an Azure Firewall with the required rules
+type FirewallConformance struct {
+	ID        string   `json:"id"`
+	PolicyID  string   `json:"policy_id,omitempty"`
+	NodePools []string `json:"node_pools"`
+	egressrules.Conformance
+}
+
+// firewallPath groups the node pools that egress through the same firewall
+type firewallPath struct {
+	firewall  *armnetwork.AzureFirewall
+	nodePools []string
+	sources   []netip.Prefix
+}
+
+// handleEgressConformance checks the required AKS outbound rules against the Azure Firewall on the egress path
+func handleEgressConformance(ctx context.Context, client *azureclient.AzureClient, rules *egressrules.CatalogStore, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	nodePool, _ := params["node_pool"].(string)
+	firewallID, err := parseFirewallID(params)
+	if err != nil {
+		return "", err
+	}
+
+	catalog, source, err := rules.Load()
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	selection := egressRuleSelection(cluster)
+	if cloud, _ := params["cloud"].(string); strings.TrimSpace(cloud) != "" {
+		selection.Cloud = strings.TrimSpace(cloud)
+	}
+	required := catalog.Select(selection)
+
+	result := &EgressConformanceResult{
+		RuleList: EgressRuleList{
+			Version:   catalog.Version,
+			Reference: catalog.Reference,
+			Source:    source,
+			Cloud:     selection.Cloud,
+			Region:    selection.Region,
+			Features:  selection.Features,
+			Rules:     len(required),
+		},
+		NodePools: []NodePoolEgressPath{},
+		Firewalls: []FirewallConformance{},
+	}
+
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, nodePool)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve node pool networks: %v", err)
+	}
+
+	paths, order := resolveFirewallPaths(ctx, client, networks, subID, firewallID, result)
+	for _, id := range order {
+		path := paths[id]
+		conformance := FirewallConformance{ID: id, NodePools: path.nodePools}
+		firewall, notes := loadFirewallRules(ctx, client, path.firewall)
+		result.Notes = append(result.Notes, notes...)
+		conformance.PolicyID = firewall.PolicyID
+		conformance.Conformance = egressrules.Evaluate(required, firewall, path.sources)
+		result.Firewalls = append(result.Firewalls, conformance)
+	}
+	if len(result.Firewalls) == 0 {
+		result.RequiredRules = required
+		if clusterOutboundType(cluster) != egress.OutboundTypeUserDefinedRouting {
+			result.Notes = append(result.Notes, fmt.Sprintf("The cluster uses outbound type %s and no Azure Firewall was found on the egress path; the required rules are listed for reference", clusterOutboundType(cluster)))
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal egress conformance to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// egressRuleSelection returns the cloud, region, API server FQDN and features that select the required rules
+func egressRuleSelection(cluster *armcontainerservice.ManagedCluster) egressrules.Selection {
+	selection := egressrules.Selection{Features: []string{}}
+	if cluster.Location != nil {
+		selection.Region = strings.ToLower(strings.ReplaceAll(*cluster.Location, " ", ""))
+	}
+	selection.Cloud = egressrules.CloudForRegion(selection.Region)
+
+	props := cluster.Properties
+	if props == nil {
+		return selection
+	}
+	if props.Fqdn != nil {
+		selection.ClusterFQDN = *props.Fqdn
+	}
+
+	addFeature := func(feature string) {
+		if !slices.Contains(selection.Features, feature) {
+			selection.Features = append(selection.Features, feature)
+		}
+	}
+	if !isPrivateCluster(cluster) {
+		addFeature(egressrules.FeaturePublicAPIServer)
+	}
+	for _, pool := range props.AgentPoolProfiles {
+		if pool == nil {
+			continue
+		}
+		if pool.OSType != nil && *pool.OSType == armcontainerservice.OSTypeWindows {
+			addFeature(egressrules.FeatureWindows)
+		} else {
+			addFeature(egressrules.FeatureLinux)
+		}
+		if pool.VMSize != nil && strings.HasPrefix(strings.ToLower(*pool.VMSize), "standard_n") {
+			addFeature(egressrules.FeatureGPU)
+		}
+	}
+
+	addons := map[string]string{
+		"omsagent":                     egressrules.FeatureMonitoring,
+		"azurepolicy":                  egressrules.FeatureAzurePolicy,
+		"azurekeyvaultsecretsprovider": egressrules.FeatureKeyVaultSecretsProvider,
+	}
+	for name, addon := range props.AddonProfiles {
+		feature, ok := addons[strings.ToLower(name)]
+		if ok && addon != nil && addon.Enabled != nil && *addon.Enabled {
+			addFeature(feature)
+		}
+	}
+	if monitor := props.AzureMonitorProfile; monitor != nil && monitor.Metrics != nil && monitor.Metrics.Enabled != nil && *monitor.Metrics.Enabled {
+		addFeature(egressrules.FeatureMonitoring)
+	}
+	if security := props.SecurityProfile; security != nil && security.Defender != nil && security.Defender.SecurityMonitoring != nil &&
+		security.Defender.SecurityMonitoring.Enabled != nil && *security.Defender.SecurityMonitoring.Enabled {
+		addFeature(egressrules.FeatureDefender)
+	}
+	slices.Sort(selection.Features)
+	return selection
+}
+
+// parseFirewallID parses the optional firewall_id parameter
+func parseFirewallID(params map[string]interface{}) (*arm.ResourceID, error) {
+	value, _ := params["firewall_id"].(string)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := arm.ParseResourceID(value)
+	if err != nil || !strings.EqualFold(parsed.ResourceType.String(), "Microsoft.Network/azureFirewalls") {
+		return nil, fmt.Errorf("invalid firewall_id '%s', must be an Azure Firewall resource ID", value)
+	}
+	return parsed, nil
+}
+
+// resolveFirewallPaths finds the Azure Firewall each node pool egresses through from the default
+// route of its subnet, or uses the given firewall for every node pool. It returns the firewalls by
+// ID in the order they were found.
+func resolveFirewallPaths(ctx context.Context, client *azureclient.AzureClient, networks []resourcehelpers.NodePoolNetwork, subID string, firewallID *arm.ResourceID, result *EgressConformanceResult) (map[string]*firewallPath, []string) {
+	paths := map[string]*firewallPath{}
+	var order []string
+
+	var explicitFirewall *armnetwork.AzureFirewall
+	if firewallID != nil {
+		firewall, err := client.GetAzureFirewall(ctx, firewallID.SubscriptionID, firewallID.ResourceGroupName, firewallID.Name)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("firewall %s: %v", firewallID, err))
+			return paths, order
+		}
+		explicitFirewall = firewall
+	}
+
+	for _, network := range networks {
+		poolPath := NodePoolEgressPath{NodePool: network.NodePool, SubnetID: network.SubnetID}
+		input, _, err := loadEgressInput(ctx, client, network)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("node pool %s: %v", network.NodePool, err))
+		}
+		if route := egress.FindRoute(input.Routes, "0.0.0.0/0"); route != nil {
+			poolPath.NextHopType = route.NextHopType
+			poolPath.NextHopIP = route.NextHopIP
+		}
+
+		firewall := explicitFirewall
+		if firewall == nil && poolPath.NextHopType == egress.NextHopVirtualAppliance && poolPath.NextHopIP != "" {
+			firewall, err = findFirewallByPrivateIP(ctx, client, subID, poolPath.NextHopIP)
+			if err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("node pool %s: %v", network.NodePool, err))
+			}
+		}
+
+		if firewall != nil && firewall.ID != nil {
+			id := *firewall.ID
+			poolPath.FirewallID = id
+			if _, ok := paths[id]; !ok {
+				paths[id] = &firewallPath{firewall: firewall}
+				order = append(order, id)
+			}
+			paths[id].nodePools = append(paths[id].nodePools, network.NodePool)
+			paths[id].sources = append(paths[id].sources, input.SubnetPrefixes...)
+		}
+		result.NodePools = append(result.NodePools, poolPath)
+	}
+	return paths, order
+}
+
+// findFirewallByPrivateIP finds the Azure Firewall of the subscription with the given private IP
+func findFirewallByPrivateIP(ctx context.Context, client *azureclient.AzureClient, subID, address string) (*armnetwork.AzureFirewall, error) {
+	firewalls, err := client.ListAzureFirewalls(ctx, subID)
+	if err != nil {
+		return nil, err
+	}
+	for _, firewall := range firewalls {
+		if slices.Contains(egressrules.FirewallPrivateIPs(firewall), address) {
+			return firewall, nil
+		}
+	}
+	return nil, fmt.Errorf("the default route next hop %s is not an Azure Firewall in subscription %s; pass firewall_id if the firewall is in another subscription", address, subID)
+}
+
+// loadFirewallRules loads the rules of a firewall from its policy, including inherited base
+// policies, or from its classic rule collections
+func loadFirewallRules(ctx context.Context, client *azureclient.AzureClient, firewall *armnetwork.AzureFirewall) (egressrules.Firewall, []string) {
+	result := egressrules.Firewall{}
+	if firewall.ID != nil {
+		result.ID = *firewall.ID
+	}
+	if firewall.Properties == nil || firewall.Properties.FirewallPolicy == nil || firewall.Properties.FirewallPolicy.ID == nil {
+		result.Rules = egressrules.RulesFromAzureFirewall(firewall)
+		result.DNSProxy = egressrules.FirewallDNSProxy(firewall)
+		return result, nil
+	}
+
+	var notes []string
+	var chain [][]*armnetwork.FirewallPolicyRuleCollectionGroup
+	policyID := *firewall.Properties.FirewallPolicy.ID
+	result.PolicyID = policyID
+	for depth := 0; policyID != "" && depth < maxBasePolicyDepth; depth++ {
+		parsed, err := arm.ParseResourceID(policyID)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("failed to parse firewall policy ID %s: %v", policyID, err))
+			break
+		}
+		policy, err := client.GetFirewallPolicy(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("firewall policy %s: %v", policyID, err))
+			break
+		}
+		groups, err := client.ListFirewallPolicyRuleCollectionGroups(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("firewall policy %s: %v", policyID, err))
+			break
+		}
+		result.DNSProxy = result.DNSProxy || egressrules.PolicyDNSProxy(policy)
+		chain = append(chain, groups)
+
+		policyID = ""
+		if policy.Properties != nil && policy.Properties.BasePolicy != nil && policy.Properties.BasePolicy.ID != nil {
+			policyID = *policy.Properties.BasePolicy.ID
+		}
+	}
+
+	// The root base policy is processed first
+	for i := range chain {
+		level := len(chain) - 1 - i
+		result.Rules = append(result.Rules, egressrules.RulesFromPolicy(chain[i], level)...)
+	}
+	return result, notes
+}
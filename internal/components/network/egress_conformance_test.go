@@ -0,0 +1,96 @@
+package network
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/egressrules"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func TestEgressRuleSelection(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Location: to.Ptr("East US"),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			Fqdn: to.Ptr("aks-abc.hcp.eastus.azmk8s.io"),
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("system"), OSType: to.Ptr(armcontainerservice.OSTypeLinux), VMSize: to.Ptr("Standard_D4s_v5")},
+				{Name: to.Ptr("gpu"), VMSize: to.Ptr("Standard_NC6s_v3")},
+				{Name: to.Ptr("win"), OSType: to.Ptr(armcontainerservice.OSTypeWindows)},
+			},
+			AddonProfiles: map[string]*armcontainerservice.ManagedClusterAddonProfile{
+				"omsagent":    {Enabled: to.Ptr(true)},
+				"azurepolicy": {Enabled: to.Ptr(false)},
+			},
+		},
+	}
+
+	selection := egressRuleSelection(cluster)
+	if selection.Region != "eastus" || selection.Cloud != egressrules.CloudPublic || selection.ClusterFQDN != "aks-abc.hcp.eastus.azmk8s.io" {
+		t.Errorf("Unexpected selection: %+v", selection)
+	}
+	expected := []string{egressrules.FeatureGPU, egressrules.FeatureLinux, egressrules.FeatureMonitoring, egressrules.FeaturePublicAPIServer, egressrules.FeatureWindows}
+	if !slices.Equal(selection.Features, expected) {
+		t.Errorf("Expected features %v, got %v", expected, selection.Features)
+	}
+
+	cluster.Properties.APIServerAccessProfile = &armcontainerservice.ManagedClusterAPIServerAccessProfile{EnablePrivateCluster: to.Ptr(true)}
+	if slices.Contains(egressRuleSelection(cluster).Features, egressrules.FeaturePublicAPIServer) {
+		t.Error("Expected no public API server feature for a private cluster")
+	}
+}
+
+func TestParseFirewallID(t *testing.T) {
+	firewallID := "/subscriptions/hub/resourceGroups/hub-rg/providers/Microsoft.Network/azureFirewalls/hub-fw"
+	parsed, err := parseFirewallID(map[string]interface{}{"firewall_id": firewallID})
+	if err != nil || parsed.SubscriptionID != "hub" || parsed.Name != "hub-fw" {
+		t.Errorf("Expected the parsed firewall ID, got %v %v", parsed, err)
+	}
+	if parsed, err := parseFirewallID(map[string]interface{}{}); err != nil || parsed != nil {
+		t.Errorf("Expected no firewall, got %v %v", parsed, err)
+	}
+	if _, err := parseFirewallID(map[string]interface{}{"firewall_id": "/subscriptions/hub/resourceGroups/hub-rg/providers/Microsoft.Network/firewallPolicies/policy"}); err == nil {
+		t.Error("Expected error for a firewall policy ID")
+	}
+}
+
+func TestHandleEgressConformanceValidation(t *testing.T) {
+	_, err := handleEgressConformance(context.Background(), nil, egressrules.NewCatalogStore(""), map[string]interface{}{"firewall_id": "bad"}, "sub", "rg", "aks")
+	if err == nil || !strings.Contains(err.Error(), "firewall_id") {
+		t.Errorf("Expected firewall_id validation error, got %v", err)
+	}
+
+	_, err = handleEgressConformance(context.Background(), nil, egressrules.NewCatalogStore("/nonexistent/rules.json"), map[string]interface{}{}, "sub", "rg", "aks")
+	if err == nil || !strings.Contains(err.Error(), "egress rules file") {
+		t.Errorf("Expected rules file error, got %v", err)
+	}
+}
+
+func TestLoadFirewallRulesClassic(t *testing.T) {
+	firewall := &armnetwork.AzureFirewall{
+		ID: to.Ptr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/azureFirewalls/fw"),
+		Properties: &armnetwork.AzureFirewallPropertiesFormat{
+			ApplicationRuleCollections: []*armnetwork.AzureFirewallApplicationRuleCollection{
+				{
+					Name: to.Ptr("aks"),
+					Properties: &armnetwork.AzureFirewallApplicationRuleCollectionPropertiesFormat{
+						Priority: to.Ptr[int32](100),
+						Rules: []*armnetwork.AzureFirewallApplicationRule{
+							{Name: to.Ptr("tag"), SourceAddresses: []*string{to.Ptr("*")}, FqdnTags: []*string{to.Ptr("AzureKubernetesService")}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Classic rules are read from the firewall without further calls
+	result, notes := loadFirewallRules(context.Background(), nil, firewall)
+	if len(notes) != 0 || result.PolicyID != "" || len(result.Rules) != 1 || result.Rules[0].Collection != "aks" {
+		t.Errorf("Unexpected firewall rules: %+v %v", result, notes)
+	}
+}
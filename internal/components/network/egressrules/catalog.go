@@ -0,0 +1,215 @@
+// Package egressrules checks the outbound rules required by AKS against the Azure Firewall
+// rules on the egress path of a locked-down cluster.
+package egressrules
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule kinds, matching the Azure Firewall rule types that can allow them
+const (
+	KindApplication = "application"
+	KindNetwork     = "network"
+)
+
+// Azure clouds of the rule list
+const (
+	CloudPublic       = "AzureCloud"
+	CloudChina        = "AzureChinaCloud"
+	CloudUSGovernment = "AzureUSGovernment"
+)
+
+// Cluster features that select additional rules
+const (
+	FeatureLinux                   = "linux"
+	FeatureWindows                 = "windows"
+	FeatureGPU                     = "gpu"
+	FeatureMonitoring              = "monitoring"
+	FeatureAzurePolicy             = "azure_policy"
+	FeatureDefender                = "defender"
+	FeatureKeyVaultSecretsProvider = "keyvault_secrets_provider"
+	FeaturePublicAPIServer         = "public_api_server"
+)
+
+// Placeholders substituted in rule FQDNs
+const (
+	placeholderClusterFQDN = "{cluster_fqdn}"
+	placeholderRegion      = "{region}"
+)
+
+// SourceEmbedded is the source of the rule list that ships with the binary
+const SourceEmbedded = "embedded"
+
+//go:embed rules.json
+var embeddedRules []byte
+
+// Rule is a required outbound endpoint of AKS
+type Rule struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	FQDN     string   `json:"fqdn"`
+	Protocol string   `json:"protocol"`
+	Port     int32    `json:"port"`
+	Clouds   []string `json:"clouds,omitempty"`
+	Features []string `json:"features,omitempty"`
+	// Optional rules are recommended but clusters keep working without them
+	Optional bool `json:"optional,omitempty"`
+	// FQDNTag is set when the AzureKubernetesService FQDN tag covers the rule
+	FQDNTag bool   `json:"fqdn_tag,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// Catalog is a versioned list of required AKS outbound rules
+type Catalog struct {
+	Version   string `json:"version"`
+	Reference string `json:"reference,omitempty"`
+	Rules     []Rule `json:"rules"`
+}
+
+// Selection describes the cluster the rules are selected for
+type Selection struct {
+	Cloud       string
+	Region      string
+	ClusterFQDN string
+	Features    []string
+}
+
+// CloudForRegion returns the Azure cloud of a region name
+func CloudForRegion(region string) string {
+	region = strings.ToLower(region)
+	switch {
+	case strings.HasPrefix(region, "china"):
+		return CloudChina
+	case strings.HasPrefix(region, "usgov"), strings.HasPrefix(region, "usdod"):
+		return CloudUSGovernment
+	default:
+		return CloudPublic
+	}
+}
+
+// Select returns the rules that apply to the cloud and features of a cluster, with the cluster
+// FQDN and region substituted. Rules whose placeholders cannot be resolved are skipped.
+func (c *Catalog) Select(selection Selection) []Rule {
+	var rules []Rule
+	for _, rule := range c.Rules {
+		if len(rule.Clouds) > 0 && !slices.ContainsFunc(rule.Clouds, func(cloud string) bool { return strings.EqualFold(cloud, selection.Cloud) }) {
+			continue
+		}
+		if !hasFeatures(selection.Features, rule.Features) {
+			continue
+		}
+
+		rule.FQDN = strings.ReplaceAll(rule.FQDN, placeholderClusterFQDN, strings.ToLower(selection.ClusterFQDN))
+		rule.FQDN = strings.ReplaceAll(rule.FQDN, placeholderRegion, strings.ToLower(selection.Region))
+		if rule.FQDN == "" || strings.HasPrefix(rule.FQDN, ".") || strings.Contains(rule.FQDN, "{") {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// hasFeatures reports whether every required feature is enabled
+func hasFeatures(enabled, required []string) bool {
+	for _, feature := range required {
+		if !slices.Contains(enabled, feature) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCatalog parses and validates a rule list
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse egress rules: %v", err)
+	}
+	if catalog.Version == "" {
+		return nil, fmt.Errorf("egress rules have no version")
+	}
+
+	for i, rule := range catalog.Rules {
+		if rule.Name == "" || rule.FQDN == "" {
+			return nil, fmt.Errorf("egress rule %d must have a name and an fqdn", i)
+		}
+		if rule.Port <= 0 || rule.Port > 65535 {
+			return nil, fmt.Errorf("egress rule %s has an invalid port %d", rule.Name, rule.Port)
+		}
+		switch rule.Kind {
+		case KindApplication:
+			if !strings.EqualFold(rule.Protocol, ProtocolHTTP) && !strings.EqualFold(rule.Protocol, ProtocolHTTPS) {
+				return nil, fmt.Errorf("application egress rule %s must use Http or Https, got %s", rule.Name, rule.Protocol)
+			}
+		case KindNetwork:
+			if !strings.EqualFold(rule.Protocol, ProtocolTCP) && !strings.EqualFold(rule.Protocol, ProtocolUDP) {
+				return nil, fmt.Errorf("network egress rule %s must use TCP or UDP, got %s", rule.Name, rule.Protocol)
+			}
+		default:
+			return nil, fmt.Errorf("egress rule %s has an invalid kind %s", rule.Name, rule.Kind)
+		}
+	}
+	return &catalog, nil
+}
+
+// parseEmbeddedCatalog parses the rule list that ships with the binary once
+var parseEmbeddedCatalog = sync.OnceValues(func() (*Catalog, error) {
+	return ParseCatalog(embeddedRules)
+})
+
+// CatalogStore loads the required egress rules. The rule list embedded in the binary is used
+// unless a rule file is configured; the file is reloaded whenever it changes.
+type CatalogStore struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	catalog *Catalog
+}
+
+// NewCatalogStore creates a store reading the rule file at path.
+// An empty path yields a store with the embedded rule list.
+func NewCatalogStore(path string) *CatalogStore {
+	return &CatalogStore{path: path}
+}
+
+// Load returns the rule list and its source, which is the file path or "embedded"
+func (s *CatalogStore) Load() (*Catalog, string, error) {
+	if s == nil || s.path == "" {
+		catalog, err := parseEmbeddedCatalog()
+		return catalog, SourceEmbedded, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, s.path, fmt.Errorf("failed to read egress rules file: %v", err)
+	}
+	if s.catalog != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.catalog, s.path, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, s.path, fmt.Errorf("failed to read egress rules file: %v", err)
+	}
+	catalog, err := ParseCatalog(data)
+	if err != nil {
+		return nil, s.path, err
+	}
+
+	s.catalog = catalog
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return catalog, s.path, nil
+}
@@ -0,0 +1,118 @@
+package egressrules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func ruleNames(rules []Rule) map[string]Rule {
+	names := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		names[rule.Name] = rule
+	}
+	return names
+}
+
+func TestEmbeddedCatalog(t *testing.T) {
+	catalog, source, err := NewCatalogStore("").Load()
+	if err != nil {
+		t.Fatalf("Unexpected error loading the embedded rules: %v", err)
+	}
+	if source != SourceEmbedded || catalog.Version == "" || len(catalog.Rules) == 0 {
+		t.Errorf("Expected a versioned embedded rule list, got source %s version %q with %d rules", source, catalog.Version, len(catalog.Rules))
+	}
+}
+
+func TestCatalogSelect(t *testing.T) {
+	catalog, _, err := NewCatalogStore("").Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rules := ruleNames(catalog.Select(Selection{
+		Cloud:       CloudPublic,
+		Region:      "EastUS",
+		ClusterFQDN: "aks-abc.hcp.eastus.azmk8s.io",
+		Features:    []string{FeatureLinux, FeatureMonitoring, FeaturePublicAPIServer},
+	}))
+	if rules["api-server"].FQDN != "aks-abc.hcp.eastus.azmk8s.io" {
+		t.Errorf("Expected the cluster FQDN to be substituted, got %q", rules["api-server"].FQDN)
+	}
+	if rules["monitor-ingest"].FQDN != "eastus.ingest.monitor.azure.com" {
+		t.Errorf("Expected the region to be substituted, got %q", rules["monitor-ingest"].FQDN)
+	}
+	for _, name := range []string{"ntp", "management"} {
+		if _, ok := rules[name]; !ok {
+			t.Errorf("Expected rule %s to be selected", name)
+		}
+	}
+	for _, name := range []string{"management-china", "nvidia-github", "windows-go", "policy-data"} {
+		if _, ok := rules[name]; ok {
+			t.Errorf("Expected rule %s not to be selected", name)
+		}
+	}
+
+	// Private clusters reach the API server through the private endpoint
+	private := ruleNames(catalog.Select(Selection{Cloud: CloudChina, Region: "chinanorth3", Features: []string{FeatureLinux}}))
+	if _, ok := private["api-server"]; ok {
+		t.Error("Expected no API server rule for a private cluster")
+	}
+	if _, ok := private["management-china"]; !ok {
+		t.Error("Expected the Azure China management endpoint")
+	}
+}
+
+func TestCloudForRegion(t *testing.T) {
+	tests := map[string]string{
+		"westeurope":    CloudPublic,
+		"chinaeast2":    CloudChina,
+		"usgovvirginia": CloudUSGovernment,
+		"usdodeast":     CloudUSGovernment,
+	}
+	for region, expected := range tests {
+		if cloud := CloudForRegion(region); cloud != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, region, cloud)
+		}
+	}
+}
+
+func TestCatalogStoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	content := `{"version":"custom-1","rules":[{"name":"proxy","kind":"application","fqdn":"proxy.contoso.com","protocol":"Https","port":443}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+
+	catalog, source, err := NewCatalogStore(path).Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if source != path || catalog.Version != "custom-1" || len(catalog.Rules) != 1 {
+		t.Errorf("Expected the rules of the file, got source %s version %s with %d rules", source, catalog.Version, len(catalog.Rules))
+	}
+
+	if _, _, err := NewCatalogStore(filepath.Join(t.TempDir(), "missing.json")).Load(); err == nil {
+		t.Error("Expected error for a missing rules file")
+	}
+}
+
+func TestParseCatalogValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"no version", `{"rules":[]}`},
+		{"invalid kind", `{"version":"1","rules":[{"name":"a","kind":"nat","fqdn":"a.com","protocol":"TCP","port":1}]}`},
+		{"udp application rule", `{"version":"1","rules":[{"name":"a","kind":"application","fqdn":"a.com","protocol":"UDP","port":1}]}`},
+		{"invalid port", `{"version":"1","rules":[{"name":"a","kind":"network","fqdn":"a.com","protocol":"TCP","port":0}]}`},
+		{"invalid json", `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCatalog([]byte(tt.content)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package egressrules
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+)
+
+// Protocols of required rules and Azure Firewall rules
+const (
+	ProtocolHTTP  = "Http"
+	ProtocolHTTPS = "Https"
+	ProtocolTCP   = "TCP"
+	ProtocolUDP   = "UDP"
+	ProtocolAny   = "Any"
+)
+
+// Azure Firewall rule collection actions
+const (
+	ActionAllow = "Allow"
+	ActionDeny  = "Deny"
+)
+
+// Conformance status of a required rule
+const (
+	StatusAllowed    = "allowed"
+	StatusDenied     = "denied"
+	StatusMissing    = "missing"
+	StatusUnverified = "unverified"
+)
+
+// FQDNTagAKS is the Azure Firewall FQDN tag that covers the required AKS endpoints
+const FQDNTagAKS = "AzureKubernetesService"
+
+// ApplicationProtocol is a protocol and port of an application rule
+type ApplicationProtocol struct {
+	Type string
+	Port int32
+}
+
+// FirewallRule is an application or network rule of an Azure Firewall or firewall policy
+type FirewallRule struct {
+	Name       string
+	Collection string
+	Action     string
+	Kind       string
+	// PolicyLevel orders inherited rules: base policy rules are processed before the rules of the child policy
+	PolicyLevel   int
+	GroupPriority int32
+	Priority      int32
+
+	SourceAddresses   []string
+	HasSourceIPGroups bool
+
+	// Application rules
+	Protocols   []ApplicationProtocol
+	TargetFQDNs []string
+	FQDNTags    []string
+
+	// Network rules
+	NetworkProtocols       []string
+	DestinationAddresses   []string
+	DestinationFQDNs       []string
+	DestinationPorts       []string
+	HasDestinationIPGroups bool
+}
+
+// Firewall holds the rules of an Azure Firewall on the egress path
+type Firewall struct {
+	ID       string
+	PolicyID string
+	// DNSProxy is required for network rules with FQDN destinations
+	DNSProxy bool
+	Rules    []FirewallRule
+}
+
+// Check is the conformance of a required rule
+type Check struct {
+	Rule
+	Status      string `json:"status"`
+	MatchedRule string `json:"matched_rule,omitempty"`
+	Note        string `json:"note,omitempty"`
+}
+
+// Summary counts the required rules by status
+type Summary struct {
+	Allowed    int `json:"allowed"`
+	Denied     int `json:"denied"`
+	Missing    int `json:"missing"`
+	Unverified int `json:"unverified"`
+}
+
+// Conformance is the result of checking the required rules against a firewall
+type Conformance struct {
+	Summary  Summary         `json:"summary"`
+	Checks   []Check         `json:"checks"`
+	Findings common.Findings `json:"findings,omitempty"`
+}
+
+// match is the outcome of matching a required rule against a firewall rule
+type match int
+
+const (
+	matchNone match = iota
+	matchUncertain
+	matchCertain
+)
+
+// Evaluate checks every required rule against the firewall rules in Azure Firewall processing
+// order: network rules before application rules, then by policy level, rule collection group
+// priority and rule collection priority. The first matching rule decides. sources are the node
+// subnet prefixes the traffic originates from.
+func Evaluate(required []Rule, firewall Firewall, sources []netip.Prefix) Conformance {
+	rules := slices.Clone(firewall.Rules)
+	slices.SortStableFunc(rules, func(a, b FirewallRule) int {
+		return cmp.Or(
+			cmp.Compare(kindOrder(a.Kind), kindOrder(b.Kind)),
+			cmp.Compare(a.PolicyLevel, b.PolicyLevel),
+			cmp.Compare(a.GroupPriority, b.GroupPriority),
+			cmp.Compare(a.Priority, b.Priority),
+		)
+	})
+
+	conformance := Conformance{Checks: []Check{}}
+	needsDNSProxy := false
+	for _, rule := range required {
+		check := Check{Rule: rule, Status: StatusMissing}
+		for _, firewallRule := range rules {
+			result, viaNetworkFQDN := matchRule(rule, firewallRule, sources)
+			if result == matchNone {
+				continue
+			}
+			check.MatchedRule = firewallRule.Collection + "/" + firewallRule.Name
+			switch {
+			case result == matchUncertain:
+				check.Status = StatusUnverified
+				check.Note = "the matching rule uses IP groups, service tags or source ranges that cannot be compared with the node subnets"
+			case firewallRule.Action == ActionDeny:
+				check.Status = StatusDenied
+			default:
+				check.Status = StatusAllowed
+				if viaNetworkFQDN && !firewall.DNSProxy {
+					needsDNSProxy = true
+					check.Note = "allowed by a network rule with an FQDN destination, which needs DNS proxy on the firewall"
+				}
+			}
+			break
+		}
+		conformance.add(check)
+	}
+
+	if needsDNSProxy {
+		conformance.Findings.Add(common.SeverityWarning, "dns_proxy_disabled",
+			"Network rules with FQDN destinations allow required traffic but DNS proxy is not enabled on the firewall, so the FQDNs may resolve to different addresses on the nodes and the firewall")
+	}
+	return conformance
+}
+
+// add records a check in the summary and reports the rules that are not allowed
+func (c *Conformance) add(check Check) {
+	c.Checks = append(c.Checks, check)
+	target := fmt.Sprintf("%s %s:%d", check.FQDN, check.Protocol, check.Port)
+
+	switch check.Status {
+	case StatusAllowed:
+		c.Summary.Allowed++
+	case StatusUnverified:
+		c.Summary.Unverified++
+		c.Findings.Add(common.SeverityInfo, "rule_unverified",
+			fmt.Sprintf("Could not verify whether %s is allowed; rule %s matches it only partially", target, check.MatchedRule))
+	case StatusDenied, StatusMissing:
+		if check.Status == StatusDenied {
+			c.Summary.Denied++
+		} else {
+			c.Summary.Missing++
+		}
+		severity, code := common.SeverityError, "required_rule_"+check.Status
+		if check.Optional {
+			severity, code = common.SeverityWarning, "optional_rule_"+check.Status
+		}
+		message := fmt.Sprintf("%s (%s) is not allowed by the firewall", target, check.Purpose)
+		if check.Status == StatusDenied {
+			message = fmt.Sprintf("%s (%s) is denied by rule %s", target, check.Purpose, check.MatchedRule)
+		}
+		c.Findings.Add(severity, code, message)
+	}
+}
+
+// kindOrder returns the processing order of a rule kind
+func kindOrder(kind string) int {
+	if kind == KindNetwork {
+		return 0
+	}
+	return 1
+}
+
+// matchRule matches a required rule against a firewall rule. It also reports whether the match
+// is a network rule with an FQDN destination, which depends on the firewall DNS proxy.
+func matchRule(required Rule, rule FirewallRule, sources []netip.Prefix) (match, bool) {
+	source := matchSources(rule, sources)
+	if source == matchNone {
+		return matchNone, false
+	}
+
+	var destination match
+	viaNetworkFQDN := false
+	switch rule.Kind {
+	case KindApplication:
+		destination = matchApplicationRule(required, rule)
+	case KindNetwork:
+		destination, viaNetworkFQDN = matchNetworkRule(required, rule)
+	}
+	return min(source, destination), viaNetworkFQDN
+}
+
+// matchSources checks that the rule sources contain the node subnets
+func matchSources(rule FirewallRule, sources []netip.Prefix) match {
+	if len(rule.SourceAddresses) == 0 && !rule.HasSourceIPGroups {
+		return matchNone
+	}
+	if slices.Contains(rule.SourceAddresses, "*") {
+		return matchCertain
+	}
+
+	var prefixes []netip.Prefix
+	uncertain := rule.HasSourceIPGroups || len(sources) == 0
+	for _, value := range rule.SourceAddresses {
+		prefix, err := flowcheck.ParsePrefix(value)
+		if err != nil {
+			// Address ranges such as 10.0.0.1-10.0.0.9
+			uncertain = true
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	ruleSet := flowcheck.NewAddressSet(prefixes)
+	sourceSet := flowcheck.NewAddressSet(sources)
+	switch {
+	case len(sources) > 0 && ruleSet.Covers(sourceSet):
+		return matchCertain
+	case uncertain || ruleSet.Overlaps(sourceSet):
+		return matchUncertain
+	default:
+		return matchNone
+	}
+}
+
+// matchApplicationRule matches a required application rule against an application rule. Network
+// requirements such as NTP over UDP cannot be allowed by application rules.
+func matchApplicationRule(required Rule, rule FirewallRule) match {
+	if required.Kind != KindApplication {
+		return matchNone
+	}
+	if !slices.ContainsFunc(rule.Protocols, func(protocol ApplicationProtocol) bool {
+		return strings.EqualFold(protocol.Type, required.Protocol) && protocol.Port == required.Port
+	}) {
+		return matchNone
+	}
+
+	if required.FQDNTag && slices.ContainsFunc(rule.FQDNTags, func(tag string) bool { return strings.EqualFold(tag, FQDNTagAKS) }) {
+		return matchCertain
+	}
+	if slices.ContainsFunc(rule.TargetFQDNs, func(pattern string) bool { return fqdnMatches(pattern, required.FQDN) }) {
+		return matchCertain
+	}
+	return matchNone
+}
+
+// matchNetworkRule matches a required rule against a network rule
+func matchNetworkRule(required Rule, rule FirewallRule) (match, bool) {
+	protocol := ProtocolTCP
+	if required.Kind == KindNetwork {
+		protocol = required.Protocol
+	}
+	if !slices.ContainsFunc(rule.NetworkProtocols, func(value string) bool {
+		return strings.EqualFold(value, protocol) || strings.EqualFold(value, ProtocolAny)
+	}) {
+		return matchNone, false
+	}
+	if !slices.ContainsFunc(rule.DestinationPorts, func(value string) bool { return portMatches(value, required.Port) }) {
+		return matchNone, false
+	}
+
+	if slices.Contains(rule.DestinationAddresses, "*") {
+		return matchCertain, false
+	}
+	if slices.ContainsFunc(rule.DestinationFQDNs, func(pattern string) bool { return fqdnMatches(pattern, required.FQDN) }) {
+		return matchCertain, true
+	}
+	// Service tags such as AzureCloud may contain the addresses of the FQDN
+	if rule.HasDestinationIPGroups || slices.ContainsFunc(rule.DestinationAddresses, isServiceTag) {
+		return matchUncertain, false
+	}
+	return matchNone, false
+}
+
+// fqdnMatches reports whether a firewall FQDN pattern covers a required FQDN. A wildcard pattern
+// such as *.example.com matches subdomains, and wildcard requirements are covered by equal or
+// broader wildcards.
+func fqdnMatches(pattern, fqdn string) bool {
+	pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+	if pattern == "*" || pattern == fqdn {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(pattern, "*")
+	return ok && strings.HasPrefix(suffix, ".") && strings.HasSuffix(fqdn, suffix)
+}
+
+// portMatches reports whether a destination port entry (a port, a range or *) contains the port
+func portMatches(value string, port int32) bool {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return true
+	}
+	low, high, isRange := strings.Cut(value, "-")
+	if !isRange {
+		high = low
+	}
+	lowPort, err := strconv.ParseInt(strings.TrimSpace(low), 10, 32)
+	if err != nil {
+		return false
+	}
+	highPort, err := strconv.ParseInt(strings.TrimSpace(high), 10, 32)
+	if err != nil {
+		return false
+	}
+	return int64(port) >= lowPort && int64(port) <= highPort
+}
+
+// isServiceTag reports whether a destination address is a service tag rather than an IP or prefix
+func isServiceTag(value string) bool {
+	_, err := flowcheck.ParsePrefix(value)
+	return err != nil && !strings.Contains(value, "-")
+}
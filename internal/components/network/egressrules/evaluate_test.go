@@ -0,0 +1,143 @@
+package egressrules
+
+import (
+	"net/netip"
+	"testing"
+)
+
+var nodeSubnets = []netip.Prefix{netip.MustParsePrefix("10.224.0.0/16")}
+
+func requiredRules() []Rule {
+	return []Rule{
+		{Name: "mcr", Kind: KindApplication, FQDN: "mcr.microsoft.com", Protocol: ProtocolHTTPS, Port: 443, FQDNTag: true, Purpose: "images"},
+		{Name: "mcr-data", Kind: KindApplication, FQDN: "*.data.mcr.microsoft.com", Protocol: ProtocolHTTPS, Port: 443, FQDNTag: true, Purpose: "images"},
+		{Name: "ntp", Kind: KindNetwork, FQDN: "ntp.ubuntu.com", Protocol: ProtocolUDP, Port: 123, Purpose: "time"},
+		{Name: "ubuntu", Kind: KindApplication, FQDN: "security.ubuntu.com", Protocol: ProtocolHTTP, Port: 80, Optional: true, Purpose: "updates"},
+	}
+}
+
+func checkStatuses(conformance Conformance) map[string]string {
+	statuses := map[string]string{}
+	for _, check := range conformance.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestEvaluateFQDNTagAndNetworkRules(t *testing.T) {
+	firewall := Firewall{
+		DNSProxy: true,
+		Rules: []FirewallRule{
+			{Name: "aks", Collection: "aks-app", Action: ActionAllow, Kind: KindApplication, Priority: 200,
+				SourceAddresses: []string{"10.224.0.0/12"}, Protocols: []ApplicationProtocol{{Type: "Https", Port: 443}}, FQDNTags: []string{"AzureKubernetesService"}},
+			{Name: "ntp", Collection: "aks-net", Action: ActionAllow, Kind: KindNetwork, Priority: 100,
+				SourceAddresses: []string{"*"}, NetworkProtocols: []string{"UDP"}, DestinationFQDNs: []string{"ntp.ubuntu.com"}, DestinationPorts: []string{"123"}},
+		},
+	}
+
+	conformance := Evaluate(requiredRules(), firewall, nodeSubnets)
+	statuses := checkStatuses(conformance)
+	expected := map[string]string{"mcr": StatusAllowed, "mcr-data": StatusAllowed, "ntp": StatusAllowed, "ubuntu": StatusMissing}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %s", name, status, statuses[name])
+		}
+	}
+	if conformance.Summary.Allowed != 3 || conformance.Summary.Missing != 1 {
+		t.Errorf("Unexpected summary: %+v", conformance.Summary)
+	}
+	if !conformance.Findings.Has("optional_rule_missing") || conformance.Findings.Has("dns_proxy_disabled") {
+		t.Errorf("Expected only the optional rule finding, got %+v", conformance.Findings)
+	}
+}
+
+func TestEvaluateProcessingOrder(t *testing.T) {
+	// Network rules are processed before application rules, whatever their priority
+	firewall := Firewall{
+		Rules: []FirewallRule{
+			{Name: "allow-mcr", Collection: "allow", Action: ActionAllow, Kind: KindApplication, Priority: 100,
+				SourceAddresses: []string{"*"}, Protocols: []ApplicationProtocol{{Type: "Https", Port: 443}}, TargetFQDNs: []string{"*.microsoft.com"}},
+			{Name: "deny-https", Collection: "deny", Action: ActionDeny, Kind: KindNetwork, Priority: 4000,
+				SourceAddresses: []string{"*"}, NetworkProtocols: []string{"TCP"}, DestinationAddresses: []string{"*"}, DestinationPorts: []string{"443"}},
+		},
+	}
+	conformance := Evaluate(requiredRules()[:1], firewall, nodeSubnets)
+	if conformance.Checks[0].Status != StatusDenied || conformance.Checks[0].MatchedRule != "deny/deny-https" {
+		t.Errorf("Expected the network deny rule to win, got %+v", conformance.Checks[0])
+	}
+	if !conformance.Findings.Has("required_rule_denied") {
+		t.Errorf("Expected required_rule_denied, got %+v", conformance.Findings)
+	}
+
+	// Base policy rules are processed before the child policy
+	firewall.Rules[1].Kind = KindApplication
+	firewall.Rules[1].Protocols = []ApplicationProtocol{{Type: "Https", Port: 443}}
+	firewall.Rules[1].TargetFQDNs = []string{"*"}
+	firewall.Rules[0].PolicyLevel = 1
+	if conformance := Evaluate(requiredRules()[:1], firewall, nodeSubnets); conformance.Checks[0].Status != StatusDenied {
+		t.Errorf("Expected the base policy deny rule to win, got %+v", conformance.Checks[0])
+	}
+}
+
+func TestEvaluateSourcesAndUnverifiedMatches(t *testing.T) {
+	rule := FirewallRule{Name: "mcr", Collection: "app", Action: ActionAllow, Kind: KindApplication,
+		Protocols: []ApplicationProtocol{{Type: "Https", Port: 443}}, TargetFQDNs: []string{"mcr.microsoft.com"}}
+
+	tests := []struct {
+		name     string
+		modify   func(rule *FirewallRule)
+		expected string
+	}{
+		{"other subnet", func(rule *FirewallRule) { rule.SourceAddresses = []string{"10.1.0.0/16"} }, StatusMissing},
+		{"partial overlap", func(rule *FirewallRule) { rule.SourceAddresses = []string{"10.224.0.0/24"} }, StatusUnverified},
+		{"ip groups", func(rule *FirewallRule) { rule.HasSourceIPGroups = true }, StatusUnverified},
+		{"covering prefix", func(rule *FirewallRule) { rule.SourceAddresses = []string{"10.0.0.0/8"} }, StatusAllowed},
+		{"wrong port", func(rule *FirewallRule) {
+			rule.SourceAddresses = []string{"*"}
+			rule.Protocols = []ApplicationProtocol{{Type: "Https", Port: 8443}}
+		}, StatusMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := rule
+			tt.modify(&modified)
+			conformance := Evaluate(requiredRules()[:1], Firewall{Rules: []FirewallRule{modified}}, nodeSubnets)
+			if conformance.Checks[0].Status != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, conformance.Checks[0].Status)
+			}
+		})
+	}
+}
+
+func TestEvaluateNetworkRuleFQDNNeedsDNSProxy(t *testing.T) {
+	firewall := Firewall{
+		Rules: []FirewallRule{
+			{Name: "ntp", Collection: "net", Action: ActionAllow, Kind: KindNetwork, SourceAddresses: []string{"*"},
+				NetworkProtocols: []string{"Any"}, DestinationFQDNs: []string{"ntp.ubuntu.com"}, DestinationPorts: []string{"1-1024"}},
+		},
+	}
+	conformance := Evaluate(requiredRules()[2:3], firewall, nodeSubnets)
+	if conformance.Checks[0].Status != StatusAllowed || !conformance.Findings.Has("dns_proxy_disabled") {
+		t.Errorf("Expected NTP allowed with a DNS proxy warning, got %+v", conformance)
+	}
+}
+
+func TestFQDNMatches(t *testing.T) {
+	tests := []struct {
+		pattern, fqdn string
+		expected      bool
+	}{
+		{"mcr.microsoft.com", "MCR.microsoft.com", true},
+		{"*.microsoft.com", "mcr.microsoft.com", true},
+		{"*.mcr.microsoft.com", "*.data.mcr.microsoft.com", true},
+		{"*.data.mcr.microsoft.com", "*.mcr.microsoft.com", false},
+		{"*.microsoft.com", "microsoft.com", false},
+		{"*", "anything.example.com", true},
+		{"*microsoft.com", "notmicrosoft.com", false},
+	}
+	for _, tt := range tests {
+		if actual := fqdnMatches(tt.pattern, tt.fqdn); actual != tt.expected {
+			t.Errorf("Expected fqdnMatches(%s, %s) = %v, got %v", tt.pattern, tt.fqdn, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package egressrules
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// dnsProxyProperty is the additional property that enables DNS proxy on a firewall without a policy
+const dnsProxyProperty = "Network.DNS.EnableProxy"
+
+// RulesFromAzureFirewall converts the classic application and network rule collections of a firewall
+func RulesFromAzureFirewall(firewall *armnetwork.AzureFirewall) []FirewallRule {
+	if firewall == nil || firewall.Properties == nil {
+		return nil
+	}
+
+	var rules []FirewallRule
+	for _, collection := range firewall.Properties.NetworkRuleCollections {
+		if collection == nil || collection.Properties == nil {
+			continue
+		}
+		base := FirewallRule{
+			Collection: stringValue(collection.Name),
+			Action:     classicAction(collection.Properties.Action),
+			Kind:       KindNetwork,
+			Priority:   int32Value(collection.Properties.Priority),
+		}
+		for _, rule := range collection.Properties.Rules {
+			if rule == nil {
+				continue
+			}
+			converted := base
+			converted.Name = stringValue(rule.Name)
+			converted.SourceAddresses = stringValues(rule.SourceAddresses)
+			converted.HasSourceIPGroups = len(rule.SourceIPGroups) > 0
+			for _, protocol := range rule.Protocols {
+				if protocol != nil {
+					converted.NetworkProtocols = append(converted.NetworkProtocols, string(*protocol))
+				}
+			}
+			converted.DestinationAddresses = stringValues(rule.DestinationAddresses)
+			converted.DestinationFQDNs = stringValues(rule.DestinationFqdns)
+			converted.DestinationPorts = stringValues(rule.DestinationPorts)
+			converted.HasDestinationIPGroups = len(rule.DestinationIPGroups) > 0
+			rules = append(rules, converted)
+		}
+	}
+
+	for _, collection := range firewall.Properties.ApplicationRuleCollections {
+		if collection == nil || collection.Properties == nil {
+			continue
+		}
+		base := FirewallRule{
+			Collection: stringValue(collection.Name),
+			Action:     classicAction(collection.Properties.Action),
+			Kind:       KindApplication,
+			Priority:   int32Value(collection.Properties.Priority),
+		}
+		for _, rule := range collection.Properties.Rules {
+			if rule == nil {
+				continue
+			}
+			converted := base
+			converted.Name = stringValue(rule.Name)
+			converted.SourceAddresses = stringValues(rule.SourceAddresses)
+			converted.HasSourceIPGroups = len(rule.SourceIPGroups) > 0
+			for _, protocol := range rule.Protocols {
+				if protocol != nil && protocol.ProtocolType != nil {
+					converted.Protocols = append(converted.Protocols, ApplicationProtocol{Type: string(*protocol.ProtocolType), Port: int32Value(protocol.Port)})
+				}
+			}
+			converted.TargetFQDNs = stringValues(rule.TargetFqdns)
+			converted.FQDNTags = stringValues(rule.FqdnTags)
+			rules = append(rules, converted)
+		}
+	}
+	return rules
+}
+
+// RulesFromPolicy converts the filter rule collections of firewall policy rule collection groups.
+// level is the inheritance level of the policy, 0 for the root base policy.
+func RulesFromPolicy(groups []*armnetwork.FirewallPolicyRuleCollectionGroup, level int) []FirewallRule {
+	var rules []FirewallRule
+	for _, group := range groups {
+		if group == nil || group.Properties == nil {
+			continue
+		}
+		for _, classification := range group.Properties.RuleCollections {
+			collection, ok := classification.(*armnetwork.FirewallPolicyFilterRuleCollection)
+			if !ok || collection == nil {
+				// NAT rule collections do not allow egress
+				continue
+			}
+			base := FirewallRule{
+				Collection:    stringValue(group.Name) + "/" + stringValue(collection.Name),
+				Action:        ActionAllow,
+				PolicyLevel:   level,
+				GroupPriority: int32Value(group.Properties.Priority),
+				Priority:      int32Value(collection.Priority),
+			}
+			if collection.Action != nil && collection.Action.Type != nil {
+				base.Action = string(*collection.Action.Type)
+			}
+			for _, rule := range collection.Rules {
+				if converted, ok := policyRule(base, rule); ok {
+					rules = append(rules, converted)
+				}
+			}
+		}
+	}
+	return rules
+}
+
+// policyRule converts an application or network rule of a firewall policy
+func policyRule(base FirewallRule, classification armnetwork.FirewallPolicyRuleClassification) (FirewallRule, bool) {
+	converted := base
+	switch rule := classification.(type) {
+	case *armnetwork.ApplicationRule:
+		converted.Kind = KindApplication
+		converted.Name = stringValue(rule.Name)
+		converted.SourceAddresses = stringValues(rule.SourceAddresses)
+		converted.HasSourceIPGroups = len(rule.SourceIPGroups) > 0
+		for _, protocol := range rule.Protocols {
+			if protocol != nil && protocol.ProtocolType != nil {
+				converted.Protocols = append(converted.Protocols, ApplicationProtocol{Type: string(*protocol.ProtocolType), Port: int32Value(protocol.Port)})
+			}
+		}
+		converted.TargetFQDNs = stringValues(rule.TargetFqdns)
+		converted.FQDNTags = stringValues(rule.FqdnTags)
+	case *armnetwork.Rule:
+		converted.Kind = KindNetwork
+		converted.Name = stringValue(rule.Name)
+		converted.SourceAddresses = stringValues(rule.SourceAddresses)
+		converted.HasSourceIPGroups = len(rule.SourceIPGroups) > 0
+		for _, protocol := range rule.IPProtocols {
+			if protocol != nil {
+				converted.NetworkProtocols = append(converted.NetworkProtocols, string(*protocol))
+			}
+		}
+		converted.DestinationAddresses = stringValues(rule.DestinationAddresses)
+		converted.DestinationFQDNs = stringValues(rule.DestinationFqdns)
+		converted.DestinationPorts = stringValues(rule.DestinationPorts)
+		converted.HasDestinationIPGroups = len(rule.DestinationIPGroups) > 0
+	default:
+		return FirewallRule{}, false
+	}
+	return converted, true
+}
+
+// FirewallDNSProxy reports whether DNS proxy is enabled on a firewall without a policy
+func FirewallDNSProxy(firewall *armnetwork.AzureFirewall) bool {
+	if firewall == nil || firewall.Properties == nil {
+		return false
+	}
+	value := firewall.Properties.AdditionalProperties[dnsProxyProperty]
+	return value != nil && strings.EqualFold(*value, "true")
+}
+
+// PolicyDNSProxy reports whether a firewall policy enables DNS proxy
+func PolicyDNSProxy(policy *armnetwork.FirewallPolicy) bool {
+	if policy == nil || policy.Properties == nil || policy.Properties.DNSSettings == nil {
+		return false
+	}
+	enabled := policy.Properties.DNSSettings.EnableProxy
+	return enabled != nil && *enabled
+}
+
+// FirewallPrivateIPs returns the private IPs of a firewall, including the hub IP of a secured virtual hub
+func FirewallPrivateIPs(firewall *armnetwork.AzureFirewall) []string {
+	if firewall == nil || firewall.Properties == nil {
+		return nil
+	}
+
+	var addresses []string
+	for _, ipConfig := range firewall.Properties.IPConfigurations {
+		if ipConfig != nil && ipConfig.Properties != nil && ipConfig.Properties.PrivateIPAddress != nil {
+			addresses = append(addresses, *ipConfig.Properties.PrivateIPAddress)
+		}
+	}
+	if hub := firewall.Properties.HubIPAddresses; hub != nil && hub.PrivateIPAddress != nil {
+		addresses = append(addresses, *hub.PrivateIPAddress)
+	}
+	return addresses
+}
+
+// classicAction returns the action of a classic rule collection, defaulting to Allow
+func classicAction(action *armnetwork.AzureFirewallRCAction) string {
+	if action != nil && action.Type != nil {
+		return string(*action.Type)
+	}
+	return ActionAllow
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func int32Value(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func stringValues(values []*string) []string {
+	var result []string
+	for _, value := range values {
+		if value != nil {
+			result = append(result, *value)
+		}
+	}
+	return result
+}
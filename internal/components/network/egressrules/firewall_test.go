@@ -0,0 +1,127 @@
+package egressrules
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func TestRulesFromAzureFirewall(t *testing.T) {
+	firewall := &armnetwork.AzureFirewall{
+		Properties: &armnetwork.AzureFirewallPropertiesFormat{
+			AdditionalProperties: map[string]*string{"Network.DNS.EnableProxy": to.Ptr("true")},
+			IPConfigurations: []*armnetwork.AzureFirewallIPConfiguration{
+				{Properties: &armnetwork.AzureFirewallIPConfigurationPropertiesFormat{PrivateIPAddress: to.Ptr("10.0.1.4")}},
+			},
+			NetworkRuleCollections: []*armnetwork.AzureFirewallNetworkRuleCollection{
+				{
+					Name: to.Ptr("net"),
+					Properties: &armnetwork.AzureFirewallNetworkRuleCollectionPropertiesFormat{
+						Priority: to.Ptr[int32](100),
+						Action:   &armnetwork.AzureFirewallRCAction{Type: to.Ptr(armnetwork.AzureFirewallRCActionTypeAllow)},
+						Rules: []*armnetwork.AzureFirewallNetworkRule{
+							{
+								Name:             to.Ptr("ntp"),
+								SourceAddresses:  []*string{to.Ptr("*")},
+								Protocols:        []*armnetwork.AzureFirewallNetworkRuleProtocol{to.Ptr(armnetwork.AzureFirewallNetworkRuleProtocolUDP)},
+								DestinationFqdns: []*string{to.Ptr("ntp.ubuntu.com")},
+								DestinationPorts: []*string{to.Ptr("123")},
+							},
+						},
+					},
+				},
+			},
+			ApplicationRuleCollections: []*armnetwork.AzureFirewallApplicationRuleCollection{
+				{
+					Name: to.Ptr("app"),
+					Properties: &armnetwork.AzureFirewallApplicationRuleCollectionPropertiesFormat{
+						Priority: to.Ptr[int32](200),
+						Action:   &armnetwork.AzureFirewallRCAction{Type: to.Ptr(armnetwork.AzureFirewallRCActionTypeDeny)},
+						Rules: []*armnetwork.AzureFirewallApplicationRule{
+							{
+								Name:           to.Ptr("block"),
+								SourceIPGroups: []*string{to.Ptr("/ipGroups/nodes")},
+								Protocols:      []*armnetwork.AzureFirewallApplicationRuleProtocol{{ProtocolType: to.Ptr(armnetwork.AzureFirewallApplicationRuleProtocolTypeHTTPS), Port: to.Ptr[int32](443)}},
+								TargetFqdns:    []*string{to.Ptr("*.example.com")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rules := RulesFromAzureFirewall(firewall)
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rules))
+	}
+	network, application := rules[0], rules[1]
+	if network.Kind != KindNetwork || network.Collection != "net" || network.NetworkProtocols[0] != "UDP" || network.DestinationFQDNs[0] != "ntp.ubuntu.com" {
+		t.Errorf("Unexpected network rule: %+v", network)
+	}
+	if application.Kind != KindApplication || application.Action != ActionDeny || !application.HasSourceIPGroups || application.Protocols[0] != (ApplicationProtocol{Type: "Https", Port: 443}) {
+		t.Errorf("Unexpected application rule: %+v", application)
+	}
+	if !FirewallDNSProxy(firewall) {
+		t.Error("Expected DNS proxy to be enabled")
+	}
+	if addresses := FirewallPrivateIPs(firewall); len(addresses) != 1 || addresses[0] != "10.0.1.4" {
+		t.Errorf("Expected the firewall private IP, got %v", addresses)
+	}
+}
+
+func TestRulesFromPolicy(t *testing.T) {
+	groups := []*armnetwork.FirewallPolicyRuleCollectionGroup{
+		{
+			Name: to.Ptr("aks"),
+			Properties: &armnetwork.FirewallPolicyRuleCollectionGroupProperties{
+				Priority: to.Ptr[int32](300),
+				RuleCollections: []armnetwork.FirewallPolicyRuleCollectionClassification{
+					&armnetwork.FirewallPolicyNatRuleCollection{Name: to.Ptr("dnat")},
+					&armnetwork.FirewallPolicyFilterRuleCollection{
+						Name:     to.Ptr("egress"),
+						Priority: to.Ptr[int32](100),
+						Action:   &armnetwork.FirewallPolicyFilterRuleCollectionAction{Type: to.Ptr(armnetwork.FirewallPolicyFilterRuleCollectionActionTypeAllow)},
+						Rules: []armnetwork.FirewallPolicyRuleClassification{
+							&armnetwork.ApplicationRule{
+								Name:            to.Ptr("aks-tag"),
+								SourceAddresses: []*string{to.Ptr("10.224.0.0/16")},
+								Protocols:       []*armnetwork.FirewallPolicyRuleApplicationProtocol{{ProtocolType: to.Ptr(armnetwork.FirewallPolicyRuleApplicationProtocolTypeHTTPS), Port: to.Ptr[int32](443)}},
+								FqdnTags:        []*string{to.Ptr("AzureKubernetesService")},
+							},
+							&armnetwork.Rule{
+								Name:                 to.Ptr("ntp"),
+								SourceAddresses:      []*string{to.Ptr("*")},
+								IPProtocols:          []*armnetwork.FirewallPolicyRuleNetworkProtocol{to.Ptr(armnetwork.FirewallPolicyRuleNetworkProtocolUDP)},
+								DestinationAddresses: []*string{to.Ptr("*")},
+								DestinationPorts:     []*string{to.Ptr("123")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rules := RulesFromPolicy(groups, 1)
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules without the NAT collection, got %d", len(rules))
+	}
+	for _, rule := range rules {
+		if rule.Collection != "aks/egress" || rule.GroupPriority != 300 || rule.Priority != 100 || rule.PolicyLevel != 1 || rule.Action != ActionAllow {
+			t.Errorf("Unexpected rule ordering fields: %+v", rule)
+		}
+	}
+	if rules[0].Kind != KindApplication || rules[0].FQDNTags[0] != FQDNTagAKS {
+		t.Errorf("Unexpected application rule: %+v", rules[0])
+	}
+	if rules[1].Kind != KindNetwork || rules[1].NetworkProtocols[0] != "UDP" {
+		t.Errorf("Unexpected network rule: %+v", rules[1])
+	}
+
+	policy := &armnetwork.FirewallPolicy{Properties: &armnetwork.FirewallPolicyPropertiesFormat{DNSSettings: &armnetwork.DNSSettings{EnableProxy: to.Ptr(true)}}}
+	if !PolicyDNSProxy(policy) {
+		t.Error("Expected DNS proxy to be enabled on the policy")
+	}
+}
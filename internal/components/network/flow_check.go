@@ -0,0 +1,358 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// nodePoolEndpointPrefix marks a flow endpoint that refers to the nodes of a node pool
+const nodePoolEndpointPrefix = "nodepool:"
+
+// FlowCheckResult is the result of a network flow check
+type FlowCheckResult struct {
+	Source                  string              `json:"source"`
+	Destination             string              `json:"destination"`
+	Protocol                string              `json:"protocol"`
+	Port                    *int                `json:"port,omitempty"`
+	SourcePort              *int                `json:"source_port,omitempty"`
+	Access                  string              `json:"access"`
+	Checks                  []NodePoolFlowCheck `json:"checks"`
+	ServiceTagsChangeNumber int                 `json:"service_tags_change_number,omitempty"`
+}
+
+// NodePoolFlowCheck is the NSG evaluation of a flow at one node pool
+type NodePoolFlowCheck struct {
+	NodePool string `json:"node_pool"`
+	SubnetID string `json:"subnet_id"`
+	flowcheck.Result
+}
+
+// flowCheckParams holds the validated parameters of a network flow check
+type flowCheckParams struct {
+	Source          string
+	Destination     string
+	Protocol        string
+	DestinationPort int
+	SourcePort      int
+}
+
+// nodePoolFlowContext holds the network configuration of a node pool needed to evaluate flows
+type nodePoolFlowContext struct {
+	name           string
+	subnetID       string
+	addresses      []netip.Prefix
+	virtualNetwork []netip.Prefix
+	subnetNSG      flowcheck.SecurityGroup
+	nicNSG         flowcheck.SecurityGroup
+}
+
+// handleNetworkFlowCheck evaluates a flow against the NSGs of the node pools at its ends
+func handleNetworkFlowCheck(ctx context.Context, client *azureclient.AzureClient, serviceTags *flowcheck.ServiceTagStore, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	flowParams, err := parseFlowCheckParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	pools := make(map[string]*nodePoolFlowContext)
+	for _, spec := range []string{flowParams.Source, flowParams.Destination} {
+		name, ok := parseNodePoolEndpoint(spec)
+		if !ok {
+			continue
+		}
+		if _, loaded := pools[strings.ToLower(name)]; loaded {
+			continue
+		}
+
+		pool, err := loadNodePoolFlowContext(ctx, client, cluster, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to load network configuration of node pool %s: %v", name, err)
+		}
+		pools[strings.ToLower(name)] = pool
+	}
+
+	result, err := evaluateFlowCheck(flowParams, pools, serviceTags)
+	if err != nil {
+		return "", err
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal flow check result to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// evaluateFlowCheck evaluates the flow outbound at the source node pool and inbound at the destination node pool
+func evaluateFlowCheck(params *flowCheckParams, pools map[string]*nodePoolFlowContext, serviceTags *flowcheck.ServiceTagStore) (*FlowCheckResult, error) {
+	result := &FlowCheckResult{
+		Source:      params.Source,
+		Destination: params.Destination,
+		Protocol:    params.Protocol,
+		Access:      flowcheck.AccessAllow,
+	}
+	if params.DestinationPort >= 0 {
+		result.Port = &params.DestinationPort
+	}
+	if params.SourcePort >= 0 {
+		result.SourcePort = &params.SourcePort
+	}
+
+	checks := []struct {
+		spec      string
+		direction string
+	}{
+		{params.Source, flowcheck.DirectionOutbound},
+		{params.Destination, flowcheck.DirectionInbound},
+	}
+
+	for _, check := range checks {
+		name, ok := parseNodePoolEndpoint(check.spec)
+		if !ok {
+			continue
+		}
+		pool, ok := pools[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("network configuration of node pool %s is not loaded", name)
+		}
+
+		// The VirtualNetwork tag depends on the VNet of the node pool being evaluated
+		resolver := flowcheck.NewResolver(pool.virtualNetwork, serviceTags)
+		source, err := resolveFlowEndpoint(params.Source, pools, resolver)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source: %w", err)
+		}
+		destination, err := resolveFlowEndpoint(params.Destination, pools, resolver)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination: %w", err)
+		}
+
+		flow := flowcheck.Flow{
+			Direction:       check.direction,
+			Source:          source,
+			Destination:     destination,
+			Protocol:        params.Protocol,
+			SourcePort:      params.SourcePort,
+			DestinationPort: params.DestinationPort,
+		}
+
+		evaluation := flowcheck.Evaluate(flow, pool.subnetNSG, pool.nicNSG, resolver)
+		if evaluation.Access == flowcheck.AccessDeny {
+			result.Access = flowcheck.AccessDeny
+		}
+		result.Checks = append(result.Checks, NodePoolFlowCheck{
+			NodePool: pool.name,
+			SubnetID: pool.subnetID,
+			Result:   evaluation,
+		})
+	}
+
+	// Read after evaluation so it reflects the service tags file loaded by the lookups
+	result.ServiceTagsChangeNumber = serviceTags.ChangeNumber()
+	return result, nil
+}
+
+// loadNodePoolFlowContext loads the subnet, address prefixes and NSGs of a node pool
+func loadNodePoolFlowContext(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, name string) (*nodePoolFlowContext, error) {
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("node pool %s not found", name)
+	}
+	network := networks[0]
+	if len(network.Errors) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(network.Errors, "; "))
+	}
+	if network.SubnetID == "" {
+		return nil, fmt.Errorf("could not determine subnet")
+	}
+
+	subnet, err := resourcehelpers.GetSubnetByID(ctx, client, network.SubnetID)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := resourcehelpers.GetSubnetAddressPrefixes(subnet)
+	if err != nil {
+		return nil, err
+	}
+	virtualNetwork, err := resourcehelpers.GetVirtualNetworkPrefixes(ctx, client, network.SubnetID)
+	if err != nil {
+		return nil, err
+	}
+
+	subnetNSG, err := loadSecurityGroup(ctx, client, flowcheck.LevelSubnet, network.NSGID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subnet NSG: %v", err)
+	}
+
+	nicNSGID, err := resourcehelpers.GetNodePoolNICNSGID(ctx, cluster, client, network.NodePool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve NIC NSG: %v", err)
+	}
+	nicNSG, err := loadSecurityGroup(ctx, client, flowcheck.LevelNIC, nicNSGID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load NIC NSG: %v", err)
+	}
+
+	return &nodePoolFlowContext{
+		name:           network.NodePool,
+		subnetID:       network.SubnetID,
+		addresses:      addresses,
+		virtualNetwork: virtualNetwork,
+		subnetNSG:      subnetNSG,
+		nicNSG:         nicNSG,
+	}, nil
+}
+
+// parseNodePoolEndpoint returns the node pool name of a "nodepool:<name>" endpoint
+func parseNodePoolEndpoint(spec string) (string, bool) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) <= len(nodePoolEndpointPrefix) || !strings.EqualFold(spec[:len(nodePoolEndpointPrefix)], nodePoolEndpointPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(spec[len(nodePoolEndpointPrefix):]), true
+}
+
+// resolveFlowEndpoint resolves a node pool, IP address, CIDR prefix or service tag endpoint
+func resolveFlowEndpoint(spec string, pools map[string]*nodePoolFlowContext, resolver *flowcheck.Resolver) (flowcheck.Endpoint, error) {
+	endpoint := flowcheck.Endpoint{Spec: spec}
+
+	if name, ok := parseNodePoolEndpoint(spec); ok {
+		pool, ok := pools[strings.ToLower(name)]
+		if !ok {
+			return endpoint, fmt.Errorf("network configuration of node pool %s is not loaded", name)
+		}
+		endpoint.Addresses = flowcheck.NewAddressSet(pool.addresses)
+		return endpoint, nil
+	}
+
+	if flowcheck.IsServiceTag(spec) {
+		endpoint.Tag = strings.TrimSpace(spec)
+	}
+
+	addresses, err := resolver.Resolve(spec)
+	if err != nil {
+		return endpoint, err
+	}
+	endpoint.Addresses = addresses
+	return endpoint, nil
+}
+
+// parseFlowCheckParams validates the parameters of a network flow check
+func parseFlowCheckParams(params map[string]interface{}) (*flowCheckParams, error) {
+	result := &flowCheckParams{}
+
+	source, _ := params["source"].(string)
+	destination, _ := params["destination"].(string)
+	result.Source = strings.TrimSpace(source)
+	result.Destination = strings.TrimSpace(destination)
+	if result.Source == "" {
+		return nil, fmt.Errorf("missing required parameter 'source'")
+	}
+	if result.Destination == "" {
+		return nil, fmt.Errorf("missing required parameter 'destination'")
+	}
+
+	_, sourceIsPool := parseNodePoolEndpoint(result.Source)
+	_, destinationIsPool := parseNodePoolEndpoint(result.Destination)
+	if !sourceIsPool && !destinationIsPool {
+		return nil, fmt.Errorf("at least one of source or destination must be a node pool, given as %s<name>", nodePoolEndpointPrefix)
+	}
+
+	protocol, _ := params["protocol"].(string)
+	switch strings.ToLower(strings.TrimSpace(protocol)) {
+	case "", "tcp":
+		result.Protocol = flowcheck.ProtocolTCP
+	case "udp":
+		result.Protocol = flowcheck.ProtocolUDP
+	case "icmp":
+		result.Protocol = flowcheck.ProtocolICMP
+	case "*", "any":
+		result.Protocol = flowcheck.TagAny
+	default:
+		return nil, fmt.Errorf("invalid protocol '%s'. Supported protocols: Tcp, Udp, Icmp, *", protocol)
+	}
+
+	var err error
+	if result.DestinationPort, err = portParameter(params, "port"); err != nil {
+		return nil, err
+	}
+	if result.DestinationPort < 0 && result.Protocol != flowcheck.ProtocolICMP && result.Protocol != flowcheck.TagAny {
+		return nil, fmt.Errorf("missing required parameter 'port' for protocol %s", result.Protocol)
+	}
+	if result.SourcePort, err = portParameter(params, "source_port"); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// portParameter reads an optional port given as a number or a string, returning -1 when absent
+func portParameter(params map[string]interface{}, key string) (int, error) {
+	var port int
+	switch value := params[key].(type) {
+	case nil:
+		return -1, nil
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, fmt.Errorf("invalid %s '%v', must be an integer between 0 and 65535", key, value)
+		}
+		port = int(value)
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return -1, nil
+		}
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s '%s', must be an integer between 0 and 65535", key, value)
+		}
+		port = parsed
+	default:
+		return 0, fmt.Errorf("invalid %s, must be an integer between 0 and 65535", key)
+	}
+
+	if port < 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid %s '%d', must be an integer between 0 and 65535", key, port)
+	}
+	return port, nil
+}
+
+// loadSecurityGroup loads the rules of an NSG associated at the given level
+func loadSecurityGroup(ctx context.Context, client *azureclient.AzureClient, level, nsgID string) (flowcheck.SecurityGroup, error) {
+	group := flowcheck.SecurityGroup{Level: level, ID: nsgID}
+	if nsgID == "" {
+		return group, nil
+	}
+
+	parsedNSGID, err := arm.ParseResourceID(nsgID)
+	if err != nil {
+		return group, fmt.Errorf("failed to parse NSG ID: %v", err)
+	}
+
+	nsg, err := client.GetNetworkSecurityGroup(ctx, parsedNSGID.SubscriptionID, parsedNSGID.ResourceGroupName, parsedNSGID.Name)
+	if err != nil {
+		return group, err
+	}
+
+	group.Rules = flowcheck.RulesFromNSG(nsg)
+	return group, nil
+}
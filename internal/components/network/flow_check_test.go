@@ -0,0 +1,233 @@
+package network
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+)
+
+func TestParseFlowCheckParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		expectError string
+		protocol    string
+		port        int
+		sourcePort  int
+	}{
+		{
+			name:       "defaults to tcp",
+			params:     map[string]interface{}{"source": "Internet", "destination": "nodepool:nodepool1", "port": float64(443)},
+			protocol:   flowcheck.ProtocolTCP,
+			port:       443,
+			sourcePort: -1,
+		},
+		{
+			name:       "port as string and protocol case insensitive",
+			params:     map[string]interface{}{"source": "nodepool:np1", "destination": "10.0.0.4", "port": "53", "protocol": "udp", "source_port": "1024"},
+			protocol:   flowcheck.ProtocolUDP,
+			port:       53,
+			sourcePort: 1024,
+		},
+		{
+			name:       "icmp without port",
+			params:     map[string]interface{}{"source": "nodepool:np1", "destination": "10.0.0.4", "protocol": "Icmp"},
+			protocol:   flowcheck.ProtocolICMP,
+			port:       -1,
+			sourcePort: -1,
+		},
+		{
+			name:        "missing source",
+			params:      map[string]interface{}{"destination": "nodepool:np1", "port": float64(443)},
+			expectError: "missing required parameter 'source'",
+		},
+		{
+			name:        "missing destination",
+			params:      map[string]interface{}{"source": "nodepool:np1", "port": float64(443)},
+			expectError: "missing required parameter 'destination'",
+		},
+		{
+			name:        "no node pool endpoint",
+			params:      map[string]interface{}{"source": "Internet", "destination": "10.0.0.4", "port": float64(443)},
+			expectError: "must be a node pool",
+		},
+		{
+			name:        "missing port for tcp",
+			params:      map[string]interface{}{"source": "Internet", "destination": "nodepool:np1"},
+			expectError: "missing required parameter 'port'",
+		},
+		{
+			name:        "port out of range",
+			params:      map[string]interface{}{"source": "Internet", "destination": "nodepool:np1", "port": float64(70000)},
+			expectError: "must be an integer between 0 and 65535",
+		},
+		{
+			name:        "invalid protocol",
+			params:      map[string]interface{}{"source": "Internet", "destination": "nodepool:np1", "port": float64(443), "protocol": "Sctp"},
+			expectError: "invalid protocol",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseFlowCheckParams(tt.params)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Protocol != tt.protocol {
+				t.Errorf("Expected protocol %s, got %s", tt.protocol, result.Protocol)
+			}
+			if result.DestinationPort != tt.port {
+				t.Errorf("Expected port %d, got %d", tt.port, result.DestinationPort)
+			}
+			if result.SourcePort != tt.sourcePort {
+				t.Errorf("Expected source port %d, got %d", tt.sourcePort, result.SourcePort)
+			}
+		})
+	}
+}
+
+func TestParseNodePoolEndpoint(t *testing.T) {
+	if name, ok := parseNodePoolEndpoint("NodePool:userpool"); !ok || name != "userpool" {
+		t.Errorf("Expected node pool userpool, got %q ok=%v", name, ok)
+	}
+	for _, spec := range []string{"nodepool:", "10.0.0.0/24", "Internet"} {
+		if _, ok := parseNodePoolEndpoint(spec); ok {
+			t.Errorf("Expected %q not to be a node pool endpoint", spec)
+		}
+	}
+}
+
+func testNodePoolFlowContext() *nodePoolFlowContext {
+	nsgRules := append([]flowcheck.Rule{
+		{
+			Name:                "DenyInternetHTTP",
+			Priority:            100,
+			Direction:           flowcheck.DirectionInbound,
+			Access:              flowcheck.AccessDeny,
+			Protocol:            flowcheck.ProtocolTCP,
+			SourcePrefixes:      []string{flowcheck.TagInternet},
+			SourcePorts:         []string{flowcheck.TagAny},
+			DestinationPrefixes: []string{flowcheck.TagAny},
+			DestinationPorts:    []string{"80"},
+		},
+	}, flowcheck.DefaultRules()...)
+
+	return &nodePoolFlowContext{
+		name:           "nodepool1",
+		subnetID:       "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/aks-subnet",
+		addresses:      []netip.Prefix{netip.MustParsePrefix("10.224.0.0/16")},
+		virtualNetwork: []netip.Prefix{netip.MustParsePrefix("10.224.0.0/12")},
+		subnetNSG:      flowcheck.SecurityGroup{Level: flowcheck.LevelSubnet},
+		nicNSG:         flowcheck.SecurityGroup{Level: flowcheck.LevelNIC, ID: "nic-nsg", Rules: nsgRules},
+	}
+}
+
+func TestEvaluateFlowCheck(t *testing.T) {
+	pools := map[string]*nodePoolFlowContext{"nodepool1": testNodePoolFlowContext()}
+
+	tests := []struct {
+		name        string
+		params      *flowCheckParams
+		access      string
+		matchedRule string
+		direction   string
+	}{
+		{
+			name:        "internet http denied by custom rule",
+			params:      &flowCheckParams{Source: "Internet", Destination: "nodepool:nodepool1", Protocol: flowcheck.ProtocolTCP, DestinationPort: 80, SourcePort: -1},
+			access:      flowcheck.AccessDeny,
+			matchedRule: "DenyInternetHTTP",
+			direction:   flowcheck.DirectionInbound,
+		},
+		{
+			name:        "internet https denied by default rule",
+			params:      &flowCheckParams{Source: "Internet", Destination: "nodepool:nodepool1", Protocol: flowcheck.ProtocolTCP, DestinationPort: 443, SourcePort: -1},
+			access:      flowcheck.AccessDeny,
+			matchedRule: "DenyAllInBound",
+			direction:   flowcheck.DirectionInbound,
+		},
+		{
+			name:        "vnet address allowed",
+			params:      &flowCheckParams{Source: "10.225.0.4", Destination: "nodepool:nodepool1", Protocol: flowcheck.ProtocolTCP, DestinationPort: 443, SourcePort: -1},
+			access:      flowcheck.AccessAllow,
+			matchedRule: "AllowVnetInBound",
+			direction:   flowcheck.DirectionInbound,
+		},
+		{
+			name:        "outbound to internet allowed",
+			params:      &flowCheckParams{Source: "nodepool:nodepool1", Destination: "8.8.8.8", Protocol: flowcheck.ProtocolUDP, DestinationPort: 53, SourcePort: -1},
+			access:      flowcheck.AccessAllow,
+			matchedRule: "AllowInternetOutBound",
+			direction:   flowcheck.DirectionOutbound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := evaluateFlowCheck(tt.params, pools, flowcheck.NewServiceTagStore(""))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Access != tt.access {
+				t.Errorf("Expected access %s, got %s", tt.access, result.Access)
+			}
+			if len(result.Checks) != 1 {
+				t.Fatalf("Expected 1 node pool check, got %d", len(result.Checks))
+			}
+			check := result.Checks[0]
+			if check.Direction != tt.direction {
+				t.Errorf("Expected direction %s, got %s", tt.direction, check.Direction)
+			}
+
+			var matched string
+			for _, evaluation := range check.Evaluations {
+				if evaluation.MatchedRule != nil {
+					matched = evaluation.MatchedRule.Name
+				}
+			}
+			if matched != tt.matchedRule {
+				t.Errorf("Expected matching rule %s, got %s", tt.matchedRule, matched)
+			}
+		})
+	}
+}
+
+func TestEvaluateFlowCheckBetweenNodePools(t *testing.T) {
+	pools := map[string]*nodePoolFlowContext{"nodepool1": testNodePoolFlowContext()}
+	params := &flowCheckParams{Source: "nodepool:nodepool1", Destination: "nodepool:NodePool1", Protocol: flowcheck.ProtocolTCP, DestinationPort: 10250, SourcePort: -1}
+
+	result, err := evaluateFlowCheck(params, pools, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Checks) != 2 {
+		t.Fatalf("Expected outbound and inbound checks, got %d", len(result.Checks))
+	}
+	if result.Access != flowcheck.AccessAllow {
+		t.Errorf("Expected intra-VNet flow to be allowed, got %s", result.Access)
+	}
+}
+
+func TestResolveFlowEndpointUnknownServiceTag(t *testing.T) {
+	resolver := flowcheck.NewResolver(nil, flowcheck.NewServiceTagStore(""))
+	if _, err := resolveFlowEndpoint("Storage.westeurope", nil, resolver); err == nil {
+		t.Error("Expected error for service tag without a service tags file")
+	}
+
+	endpoint, err := resolveFlowEndpoint("Internet", nil, resolver)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if endpoint.Tag != "Internet" {
+		t.Errorf("Expected endpoint tag Internet, got %q", endpoint.Tag)
+	}
+}
@@ -0,0 +1,201 @@
+// Package flowcheck evaluates network flows against Azure network security groups.
+package flowcheck
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// addrRange is an inclusive range of IP addresses of a single address family
+type addrRange struct {
+	first netip.Addr
+	last  netip.Addr
+}
+
+// AddressSet is a set of IP addresses. A complement set holds every address outside its ranges,
+// which is how the Internet service tag is modelled.
+type AddressSet struct {
+	ranges     []addrRange
+	complement bool
+}
+
+// anyAddressSet matches every address
+var anyAddressSet = AddressSet{complement: true}
+
+// NewAddressSet builds an address set from prefixes
+func NewAddressSet(prefixes []netip.Prefix) AddressSet {
+	set := AddressSet{}
+	for _, prefix := range prefixes {
+		set.ranges = append(set.ranges, prefixRange(prefix))
+	}
+	set.ranges = mergeRanges(set.ranges)
+	return set
+}
+
+// ParsePrefix parses an IP address or CIDR prefix
+func ParsePrefix(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR prefix %s: %v", value, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %s: %v", value, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// IsEmpty reports whether the set holds no address
+func (s AddressSet) IsEmpty() bool {
+	return !s.complement && len(s.ranges) == 0
+}
+
+// Covers reports whether every address of other is in the set
+func (s AddressSet) Covers(other AddressSet) bool {
+	if other.complement {
+		// Only a complement set excluding a subset of what other excludes can cover it
+		if !s.complement {
+			return false
+		}
+		for _, r := range s.ranges {
+			if !coveredBy(r, other.ranges) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, r := range other.ranges {
+		if s.complement {
+			if overlapsAny(r, s.ranges) {
+				return false
+			}
+		} else if !coveredBy(r, s.ranges) {
+			return false
+		}
+	}
+	return true
+}
+
+// Overlaps reports whether the sets share at least one address
+func (s AddressSet) Overlaps(other AddressSet) bool {
+	switch {
+	case s.complement && other.complement:
+		return true
+	case s.complement:
+		return overlapsComplement(other.ranges, s.ranges)
+	case other.complement:
+		return overlapsComplement(s.ranges, other.ranges)
+	}
+
+	for _, r := range other.ranges {
+		if overlapsAny(r, s.ranges) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the set as a list of ranges
+func (s AddressSet) String() string {
+	var parts []string
+	for _, r := range s.ranges {
+		if r.first == r.last {
+			parts = append(parts, r.first.String())
+		} else {
+			parts = append(parts, r.first.String()+"-"+r.last.String())
+		}
+	}
+	if s.complement {
+		if len(parts) == 0 {
+			return "*"
+		}
+		return "not(" + strings.Join(parts, ", ") + ")"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// overlapsComplement reports whether any address of ranges falls outside excluded
+func overlapsComplement(ranges, excluded []addrRange) bool {
+	for _, r := range ranges {
+		if !coveredBy(r, excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixRange returns the first and last address of a prefix
+func prefixRange(prefix netip.Prefix) addrRange {
+	prefix = prefix.Masked()
+	first := prefix.Addr()
+	bytes := first.AsSlice()
+	for bit := prefix.Bits(); bit < first.BitLen(); bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	last, _ := netip.AddrFromSlice(bytes)
+	return addrRange{first: first, last: last}
+}
+
+// mergeRanges sorts ranges and merges overlapping or adjacent ones
+func mergeRanges(ranges []addrRange) []addrRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].first.BitLen() != ranges[j].first.BitLen() {
+			return ranges[i].first.BitLen() < ranges[j].first.BitLen()
+		}
+		return ranges[i].first.Less(ranges[j].first)
+	})
+
+	merged := []addrRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		current := &merged[len(merged)-1]
+		next := current.last.Next()
+		sameFamily := current.first.BitLen() == r.first.BitLen()
+		if sameFamily && (r.first.Compare(current.last) <= 0 || (next.IsValid() && r.first == next)) {
+			if r.last.Compare(current.last) > 0 {
+				current.last = r.last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// coveredBy reports whether r is fully inside the union of the merged ranges
+func coveredBy(r addrRange, ranges []addrRange) bool {
+	for _, candidate := range ranges {
+		if candidate.first.BitLen() != r.first.BitLen() {
+			continue
+		}
+		if candidate.first.Compare(r.first) <= 0 && candidate.last.Compare(r.last) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsAny reports whether r shares an address with any of the ranges
+func overlapsAny(r addrRange, ranges []addrRange) bool {
+	for _, candidate := range ranges {
+		if candidate.first.BitLen() != r.first.BitLen() {
+			continue
+		}
+		if candidate.first.Compare(r.last) <= 0 && r.first.Compare(candidate.last) <= 0 {
+			return true
+		}
+	}
+	return false
+}
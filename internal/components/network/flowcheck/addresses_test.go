@@ -0,0 +1,95 @@
+package flowcheck
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func mustSet(t *testing.T, values ...string) AddressSet {
+	t.Helper()
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		prefix, err := ParsePrefix(value)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", value, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return NewAddressSet(prefixes)
+}
+
+func TestParsePrefix(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{"10.0.0.4", "10.0.0.4/32", false},
+		{"10.0.0.5/24", "10.0.0.0/24", false},
+		{" 192.168.1.0/24 ", "192.168.1.0/24", false},
+		{"fd00::1", "fd00::1/128", false},
+		{"10.0.0.0/33", "", true},
+		{"Internet", "", true},
+	}
+
+	for _, tc := range testCases {
+		prefix, err := ParsePrefix(tc.value)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected error for %q", tc.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", tc.value, err)
+			continue
+		}
+		if prefix.String() != tc.expected {
+			t.Errorf("For %q expected %s, got %s", tc.value, tc.expected, prefix)
+		}
+	}
+}
+
+func TestAddressSetCoversAndOverlaps(t *testing.T) {
+	vnet := mustSet(t, "10.0.0.0/16")
+	split := mustSet(t, "10.0.0.0/17", "10.0.128.0/17")
+	internet := AddressSet{ranges: vnet.ranges, complement: true}
+
+	testCases := []struct {
+		name     string
+		set      AddressSet
+		other    AddressSet
+		covers   bool
+		overlaps bool
+	}{
+		{"single IP inside", vnet, mustSet(t, "10.0.1.4"), true, true},
+		{"single IP outside", vnet, mustSet(t, "10.1.0.4"), false, false},
+		{"adjacent ranges are merged", split, vnet, true, true},
+		{"partial overlap", mustSet(t, "10.0.0.0/24"), mustSet(t, "10.0.0.0/23"), false, true},
+		{"any covers everything", anyAddressSet, mustSet(t, "8.8.8.8"), true, true},
+		{"any covers internet", anyAddressSet, internet, true, true},
+		{"internet covers public IP", internet, mustSet(t, "8.8.8.8"), true, true},
+		{"internet excludes vnet", internet, mustSet(t, "10.0.5.5"), false, false},
+		{"internet partially overlaps range spanning vnet", internet, mustSet(t, "10.0.0.0/8"), false, true},
+		{"plain set does not cover internet", vnet, internet, false, false},
+		{"address families do not mix", vnet, mustSet(t, "fd00::1"), false, false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.set.Covers(tc.other); got != tc.covers {
+			t.Errorf("%s: expected covers %v, got %v", tc.name, tc.covers, got)
+		}
+		if got := tc.set.Overlaps(tc.other); got != tc.overlaps {
+			t.Errorf("%s: expected overlaps %v, got %v", tc.name, tc.overlaps, got)
+		}
+	}
+}
+
+func TestAddressSetString(t *testing.T) {
+	if got := mustSet(t, "10.0.0.0/31", "10.0.0.4").String(); got != "10.0.0.0-10.0.0.1, 10.0.0.4" {
+		t.Errorf("Unexpected string %s", got)
+	}
+	if got := anyAddressSet.String(); got != "*" {
+		t.Errorf("Expected *, got %s", got)
+	}
+}
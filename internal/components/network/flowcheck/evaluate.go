@@ -0,0 +1,268 @@
+package flowcheck
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Protocols accepted for a flow
+const (
+	ProtocolTCP  = "Tcp"
+	ProtocolUDP  = "Udp"
+	ProtocolICMP = "Icmp"
+)
+
+// Levels at which a network security group can be associated with a node
+const (
+	LevelSubnet = "subnet"
+	LevelNIC    = "nic"
+)
+
+// Endpoint is the source or destination of a flow
+type Endpoint struct {
+	// Spec is the endpoint as given by the caller
+	Spec string
+	// Tag is set when the endpoint is a service tag, so rules naming the same tag match it exactly
+	Tag string
+	// Addresses are the addresses the endpoint covers
+	Addresses AddressSet
+}
+
+// Flow is a network flow evaluated from the perspective of one node pool
+type Flow struct {
+	Direction   string
+	Source      Endpoint
+	Destination Endpoint
+	Protocol    string
+	// SourcePort and DestinationPort are -1 when unknown
+	SourcePort      int
+	DestinationPort int
+}
+
+// SecurityGroup is a network security group associated at a given level.
+// An empty ID means no NSG is associated at that level.
+type SecurityGroup struct {
+	Level string
+	ID    string
+	Rules []Rule
+}
+
+// PartialMatch is a rule that matches part of a flow, ahead of the deciding rule
+type PartialMatch struct {
+	Rule     string `json:"rule"`
+	Priority int32  `json:"priority"`
+	Access   string `json:"access"`
+	Reason   string `json:"reason"`
+}
+
+// NSGEvaluation is the verdict of a single network security group
+type NSGEvaluation struct {
+	Level          string         `json:"level"`
+	NSGID          string         `json:"nsg_id,omitempty"`
+	Access         string         `json:"access"`
+	MatchedRule    *Rule          `json:"matched_rule,omitempty"`
+	PartialMatches []PartialMatch `json:"partial_matches,omitempty"`
+	Note           string         `json:"note,omitempty"`
+}
+
+// Result is the verdict for a flow across the network security groups on its path
+type Result struct {
+	Direction   string          `json:"direction"`
+	Access      string          `json:"access"`
+	Evaluations []NSGEvaluation `json:"evaluations"`
+}
+
+// matchKind is how far a rule matches a flow
+type matchKind int
+
+const (
+	noMatch matchKind = iota
+	partialMatch
+	fullMatch
+)
+
+// Evaluate evaluates a flow against the subnet and NIC network security groups in the order
+// Azure applies them: subnet then NIC for inbound flows, NIC then subnet for outbound flows.
+// The flow is allowed only if every associated NSG allows it.
+func Evaluate(flow Flow, subnet, nic SecurityGroup, resolver *Resolver) Result {
+	groups := []SecurityGroup{subnet, nic}
+	if flow.Direction == DirectionOutbound {
+		groups = []SecurityGroup{nic, subnet}
+	}
+
+	result := Result{Direction: flow.Direction, Access: AccessAllow}
+	for _, group := range groups {
+		evaluation := EvaluateNSG(flow, group, resolver)
+		result.Evaluations = append(result.Evaluations, evaluation)
+		if evaluation.Access == AccessDeny {
+			// Traffic denied by the first NSG never reaches the second
+			result.Access = AccessDeny
+			break
+		}
+	}
+	return result
+}
+
+// EvaluateNSG evaluates a flow against the rules of a single network security group in priority order
+func EvaluateNSG(flow Flow, group SecurityGroup, resolver *Resolver) NSGEvaluation {
+	evaluation := NSGEvaluation{Level: group.Level, NSGID: group.ID}
+	if group.ID == "" {
+		evaluation.Access = AccessAllow
+		evaluation.Note = fmt.Sprintf("no network security group associated at %s level", group.Level)
+		return evaluation
+	}
+
+	rules := make([]Rule, 0, len(group.Rules))
+	for _, rule := range group.Rules {
+		if strings.EqualFold(rule.Direction, flow.Direction) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority < rules[j].Priority })
+
+	for _, rule := range rules {
+		kind, reason := matchRule(flow, rule, resolver)
+		switch kind {
+		case fullMatch:
+			matched := rule
+			evaluation.MatchedRule = &matched
+			evaluation.Access = rule.Access
+			return evaluation
+		case partialMatch:
+			evaluation.PartialMatches = append(evaluation.PartialMatches, PartialMatch{
+				Rule:     rule.Name,
+				Priority: rule.Priority,
+				Access:   rule.Access,
+				Reason:   reason,
+			})
+		}
+	}
+
+	// Every NSG ends with a deny all rule, so this only happens when the rule list is incomplete
+	evaluation.Access = AccessDeny
+	evaluation.Note = "no rule matched the flow"
+	return evaluation
+}
+
+// matchRule reports how far a rule matches a flow, with the reason of a partial match
+func matchRule(flow Flow, rule Rule, resolver *Resolver) (matchKind, string) {
+	checks := []func() (matchKind, string){
+		func() (matchKind, string) { return matchProtocol(flow.Protocol, rule.Protocol) },
+		func() (matchKind, string) {
+			return matchAddresses(flow.Source, rule.SourcePrefixes, rule.SourceASGs, "source", resolver)
+		},
+		func() (matchKind, string) {
+			return matchAddresses(flow.Destination, rule.DestinationPrefixes, rule.DestinationASGs, "destination", resolver)
+		},
+		func() (matchKind, string) {
+			return matchPorts(flow.Protocol, flow.SourcePort, rule.SourcePorts, "source")
+		},
+		func() (matchKind, string) {
+			return matchPorts(flow.Protocol, flow.DestinationPort, rule.DestinationPorts, "destination")
+		},
+	}
+
+	result := fullMatch
+	var reasons []string
+	for _, check := range checks {
+		kind, reason := check()
+		if kind == noMatch {
+			return noMatch, ""
+		}
+		if kind == partialMatch {
+			result = partialMatch
+			reasons = append(reasons, reason)
+		}
+	}
+	return result, strings.Join(reasons, "; ")
+}
+
+// matchProtocol matches the flow protocol against the rule protocol
+func matchProtocol(flowProtocol, ruleProtocol string) (matchKind, string) {
+	switch {
+	case ruleProtocol == TagAny:
+		return fullMatch, ""
+	case flowProtocol == TagAny:
+		return partialMatch, fmt.Sprintf("rule only applies to %s", ruleProtocol)
+	case strings.EqualFold(flowProtocol, ruleProtocol):
+		return fullMatch, ""
+	default:
+		return noMatch, ""
+	}
+}
+
+// matchAddresses matches a flow endpoint against the address prefixes or application security groups of a rule
+func matchAddresses(endpoint Endpoint, prefixes, asgs []string, side string, resolver *Resolver) (matchKind, string) {
+	if len(asgs) > 0 {
+		return partialMatch, fmt.Sprintf("%s application security group membership is not evaluated", side)
+	}
+
+	result := noMatch
+	var reasons []string
+	for _, prefix := range prefixes {
+		if endpoint.Tag != "" && strings.EqualFold(prefix, endpoint.Tag) {
+			return fullMatch, ""
+		}
+
+		set, err := resolver.Resolve(prefix)
+		if err != nil {
+			result = partialMatch
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		if set.Covers(endpoint.Addresses) {
+			return fullMatch, ""
+		}
+		if set.Overlaps(endpoint.Addresses) {
+			result = partialMatch
+			reasons = append(reasons, fmt.Sprintf("%s prefix %s covers only part of %s", side, prefix, endpoint.Spec))
+		}
+	}
+	return result, strings.Join(reasons, "; ")
+}
+
+// matchPorts matches a flow port against the port ranges of a rule. Ports do not apply to ICMP.
+func matchPorts(protocol string, port int, ranges []string, side string) (matchKind, string) {
+	if strings.EqualFold(protocol, ProtocolICMP) || len(ranges) == 0 {
+		return fullMatch, ""
+	}
+
+	for _, portRange := range ranges {
+		if portRange == TagAny {
+			return fullMatch, ""
+		}
+	}
+	if port < 0 {
+		return partialMatch, fmt.Sprintf("rule only applies to %s ports %s", side, strings.Join(ranges, ","))
+	}
+
+	for _, portRange := range ranges {
+		low, high, err := parsePortRange(portRange)
+		if err != nil {
+			continue
+		}
+		if port >= low && port <= high {
+			return fullMatch, ""
+		}
+	}
+	return noMatch, ""
+}
+
+// parsePortRange parses a port or port range such as 443 or 1000-2000
+func parsePortRange(value string) (int, int, error) {
+	lowText, highText, isRange := strings.Cut(strings.TrimSpace(value), "-")
+	low, err := strconv.Atoi(strings.TrimSpace(lowText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %s", value)
+	}
+	if !isRange {
+		return low, low, nil
+	}
+	high, err := strconv.Atoi(strings.TrimSpace(highText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %s", value)
+	}
+	return low, high, nil
+}
@@ -0,0 +1,300 @@
+package flowcheck
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func testResolver() *Resolver {
+	return NewResolver([]netip.Prefix{netip.MustParsePrefix("10.224.0.0/16")}, nil)
+}
+
+func endpoint(t *testing.T, value string) Endpoint {
+	t.Helper()
+	return Endpoint{Spec: value, Addresses: mustSet(t, value)}
+}
+
+func customRule(name string, priority int32, direction, access, protocol, source, destination, port string) Rule {
+	return Rule{
+		Name:                name,
+		Priority:            priority,
+		Direction:           direction,
+		Access:              access,
+		Protocol:            protocol,
+		SourcePrefixes:      []string{source},
+		SourcePorts:         []string{TagAny},
+		DestinationPrefixes: []string{destination},
+		DestinationPorts:    []string{port},
+	}
+}
+
+func TestEvaluateInbound(t *testing.T) {
+	resolver := testResolver()
+	subnetNSG := SecurityGroup{
+		Level: LevelSubnet,
+		ID:    "subnet-nsg",
+		Rules: append([]Rule{
+			customRule("allow-https", 100, DirectionInbound, AccessAllow, ProtocolTCP, TagInternet, "*", "443"),
+			customRule("allow-range", 110, DirectionInbound, AccessAllow, ProtocolTCP, TagInternet, "*", "30000-32767"),
+		}, DefaultRules()...),
+	}
+	noNIC := SecurityGroup{Level: LevelNIC}
+
+	baseFlow := Flow{
+		Direction:       DirectionInbound,
+		Source:          endpoint(t, "203.0.113.10"),
+		Destination:     endpoint(t, "10.224.0.4"),
+		Protocol:        ProtocolTCP,
+		SourcePort:      -1,
+		DestinationPort: 443,
+	}
+
+	t.Run("allowed by custom rule", func(t *testing.T) {
+		result := Evaluate(baseFlow, subnetNSG, noNIC, resolver)
+		if result.Access != AccessAllow {
+			t.Fatalf("Expected Allow, got %s", result.Access)
+		}
+		if len(result.Evaluations) != 2 {
+			t.Fatalf("Expected 2 evaluations, got %d", len(result.Evaluations))
+		}
+		if rule := result.Evaluations[0].MatchedRule; rule == nil || rule.Name != "allow-https" {
+			t.Errorf("Expected allow-https to match, got %+v", rule)
+		}
+		if result.Evaluations[1].NSGID != "" || result.Evaluations[1].Note == "" {
+			t.Errorf("Expected NIC level without NSG, got %+v", result.Evaluations[1])
+		}
+	})
+
+	t.Run("port range", func(t *testing.T) {
+		flow := baseFlow
+		flow.DestinationPort = 31000
+		result := Evaluate(flow, subnetNSG, noNIC, resolver)
+		if rule := result.Evaluations[0].MatchedRule; rule == nil || rule.Name != "allow-range" {
+			t.Errorf("Expected allow-range to match, got %+v", rule)
+		}
+	})
+
+	t.Run("denied by default rule", func(t *testing.T) {
+		flow := baseFlow
+		flow.DestinationPort = 22
+		result := Evaluate(flow, subnetNSG, noNIC, resolver)
+		if result.Access != AccessDeny {
+			t.Fatalf("Expected Deny, got %s", result.Access)
+		}
+		rule := result.Evaluations[0].MatchedRule
+		if rule == nil || rule.Name != "DenyAllInBound" || !rule.Default {
+			t.Errorf("Expected DenyAllInBound default rule, got %+v", rule)
+		}
+		if len(result.Evaluations) != 1 {
+			t.Errorf("Expected evaluation to stop at the denying NSG, got %d evaluations", len(result.Evaluations))
+		}
+	})
+
+	t.Run("vnet traffic allowed by default rule", func(t *testing.T) {
+		flow := baseFlow
+		flow.Source = endpoint(t, "10.224.1.5")
+		flow.DestinationPort = 22
+		result := Evaluate(flow, subnetNSG, noNIC, resolver)
+		if rule := result.Evaluations[0].MatchedRule; rule == nil || rule.Name != "AllowVnetInBound" {
+			t.Errorf("Expected AllowVnetInBound to match, got %+v", rule)
+		}
+	})
+
+	t.Run("load balancer probes allowed by default rule", func(t *testing.T) {
+		// The Azure host IP is part of the VirtualNetwork tag, so AllowVnetInBound already admits probes
+		flow := baseFlow
+		flow.Source = Endpoint{Spec: TagAzureLoadBalancer, Tag: TagAzureLoadBalancer, Addresses: mustSet(t, "168.63.129.16")}
+		flow.DestinationPort = 10256
+		result := Evaluate(flow, subnetNSG, noNIC, resolver)
+		if result.Access != AccessAllow || result.Evaluations[0].MatchedRule == nil || !result.Evaluations[0].MatchedRule.Default {
+			t.Errorf("Expected a default rule to allow load balancer probes, got %+v", result.Evaluations[0])
+		}
+
+		// A custom rule on the tag takes precedence over the default rules
+		allowProbe := customRule("allow-probe", 200, DirectionInbound, AccessAllow, TagAny, TagAzureLoadBalancer, TagAny, "10256")
+		group := SecurityGroup{Level: LevelSubnet, ID: "nsg", Rules: append([]Rule{allowProbe}, DefaultRules()...)}
+		evaluation := EvaluateNSG(flow, group, resolver)
+		if evaluation.MatchedRule == nil || evaluation.MatchedRule.Name != "allow-probe" {
+			t.Errorf("Expected allow-probe to match, got %+v", evaluation.MatchedRule)
+		}
+	})
+
+	t.Run("NIC NSG denies after subnet allows", func(t *testing.T) {
+		nicNSG := SecurityGroup{
+			Level: LevelNIC,
+			ID:    "nic-nsg",
+			Rules: append([]Rule{
+				customRule("deny-https", 200, DirectionInbound, AccessDeny, TagAny, TagAny, TagAny, "443"),
+			}, DefaultRules()...),
+		}
+		result := Evaluate(baseFlow, subnetNSG, nicNSG, resolver)
+		if result.Access != AccessDeny {
+			t.Fatalf("Expected Deny, got %s", result.Access)
+		}
+		if result.Evaluations[0].Level != LevelSubnet || result.Evaluations[1].Level != LevelNIC {
+			t.Errorf("Expected subnet then NIC evaluation for inbound flows")
+		}
+		if rule := result.Evaluations[1].MatchedRule; rule == nil || rule.Name != "deny-https" {
+			t.Errorf("Expected deny-https to match, got %+v", rule)
+		}
+	})
+}
+
+func TestEvaluateOutbound(t *testing.T) {
+	resolver := testResolver()
+	subnetNSG := SecurityGroup{Level: LevelSubnet, ID: "subnet-nsg", Rules: DefaultRules()}
+	nicNSG := SecurityGroup{
+		Level: LevelNIC,
+		ID:    "nic-nsg",
+		Rules: append([]Rule{
+			customRule("deny-internet", 4000, DirectionOutbound, AccessDeny, TagAny, TagAny, TagInternet, TagAny),
+		}, DefaultRules()...),
+	}
+
+	flow := Flow{
+		Direction:       DirectionOutbound,
+		Source:          endpoint(t, "10.224.0.4"),
+		Destination:     endpoint(t, "20.60.1.1"),
+		Protocol:        ProtocolTCP,
+		SourcePort:      -1,
+		DestinationPort: 443,
+	}
+
+	result := Evaluate(flow, subnetNSG, nicNSG, resolver)
+	if result.Access != AccessDeny {
+		t.Fatalf("Expected Deny, got %s", result.Access)
+	}
+	if len(result.Evaluations) != 1 || result.Evaluations[0].Level != LevelNIC {
+		t.Fatalf("Expected outbound evaluation to start and stop at the NIC NSG, got %+v", result.Evaluations)
+	}
+}
+
+func TestEvaluateServiceTagsAndPartialMatches(t *testing.T) {
+	resolver := testResolver()
+
+	t.Run("service tag endpoint matches rule with the same tag", func(t *testing.T) {
+		group := SecurityGroup{
+			Level: LevelSubnet,
+			ID:    "nsg",
+			Rules: append([]Rule{
+				customRule("deny-storage", 100, DirectionOutbound, AccessDeny, TagAny, TagAny, "Storage", TagAny),
+			}, DefaultRules()...),
+		}
+		flow := Flow{
+			Direction:       DirectionOutbound,
+			Source:          endpoint(t, "10.224.0.4"),
+			Destination:     Endpoint{Spec: "Storage", Tag: "Storage", Addresses: mustSet(t, "20.60.0.0/16")},
+			Protocol:        ProtocolTCP,
+			SourcePort:      -1,
+			DestinationPort: 443,
+		}
+
+		evaluation := EvaluateNSG(flow, group, resolver)
+		if evaluation.Access != AccessDeny || evaluation.MatchedRule == nil || evaluation.MatchedRule.Name != "deny-storage" {
+			t.Errorf("Expected deny-storage to match, got %+v", evaluation)
+		}
+	})
+
+	t.Run("unresolvable and ASG rules are reported as partial matches", func(t *testing.T) {
+		asgRule := customRule("allow-asg", 100, DirectionInbound, AccessAllow, TagAny, TagAny, TagAny, "443")
+		asgRule.DestinationPrefixes = nil
+		asgRule.DestinationASGs = []string{"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/web"}
+
+		group := SecurityGroup{
+			Level: LevelSubnet,
+			ID:    "nsg",
+			Rules: append([]Rule{
+				asgRule,
+				customRule("allow-sql", 110, DirectionInbound, AccessAllow, TagAny, "Sql.WestEurope", TagAny, "443"),
+			}, DefaultRules()...),
+		}
+		flow := Flow{
+			Direction:       DirectionInbound,
+			Source:          endpoint(t, "203.0.113.10"),
+			Destination:     endpoint(t, "10.224.0.4"),
+			Protocol:        ProtocolTCP,
+			SourcePort:      -1,
+			DestinationPort: 443,
+		}
+
+		evaluation := EvaluateNSG(flow, group, resolver)
+		if evaluation.Access != AccessDeny || evaluation.MatchedRule.Name != "DenyAllInBound" {
+			t.Errorf("Expected DenyAllInBound to decide, got %+v", evaluation)
+		}
+		if len(evaluation.PartialMatches) != 2 {
+			t.Fatalf("Expected 2 partial matches, got %+v", evaluation.PartialMatches)
+		}
+		if !strings.Contains(evaluation.PartialMatches[0].Reason, "application security group") {
+			t.Errorf("Unexpected ASG reason: %s", evaluation.PartialMatches[0].Reason)
+		}
+		if !strings.Contains(evaluation.PartialMatches[1].Reason, "Sql.WestEurope") {
+			t.Errorf("Unexpected service tag reason: %s", evaluation.PartialMatches[1].Reason)
+		}
+	})
+
+	t.Run("protocol mismatch", func(t *testing.T) {
+		group := SecurityGroup{
+			Level: LevelSubnet,
+			ID:    "nsg",
+			Rules: append([]Rule{
+				customRule("allow-dns", 100, DirectionOutbound, AccessAllow, ProtocolUDP, TagAny, TagAny, "53"),
+				customRule("deny-dns", 110, DirectionOutbound, AccessDeny, TagAny, TagAny, TagAny, "53"),
+			}, DefaultRules()...),
+		}
+		flow := Flow{
+			Direction:       DirectionOutbound,
+			Source:          endpoint(t, "10.224.0.4"),
+			Destination:     endpoint(t, "10.224.0.10"),
+			Protocol:        ProtocolTCP,
+			SourcePort:      -1,
+			DestinationPort: 53,
+		}
+
+		evaluation := EvaluateNSG(flow, group, resolver)
+		if evaluation.MatchedRule == nil || evaluation.MatchedRule.Name != "deny-dns" {
+			t.Errorf("Expected deny-dns to match TCP flow, got %+v", evaluation.MatchedRule)
+		}
+	})
+}
+
+func TestRulesFromNSG(t *testing.T) {
+	nsg := &armnetwork.SecurityGroup{
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{
+			SecurityRules: []*armnetwork.SecurityRule{
+				{
+					Name: to.Ptr("allow-web"),
+					Properties: &armnetwork.SecurityRulePropertiesFormat{
+						Priority:                 to.Ptr[int32](100),
+						Direction:                to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+						Access:                   to.Ptr(armnetwork.SecurityRuleAccessAllow),
+						Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+						SourceAddressPrefix:      to.Ptr("Internet"),
+						SourcePortRange:          to.Ptr("*"),
+						DestinationAddressPrefix: to.Ptr("*"),
+						DestinationPortRanges:    []*string{to.Ptr("80"), to.Ptr("443")},
+					},
+				},
+			},
+		},
+	}
+
+	rules := RulesFromNSG(nsg)
+	if len(rules) != 1+len(DefaultRules()) {
+		t.Fatalf("Expected custom rule plus built-in default rules, got %d rules", len(rules))
+	}
+
+	rule := rules[0]
+	if rule.Name != "allow-web" || rule.Priority != 100 || rule.Direction != DirectionInbound || rule.Access != AccessAllow || rule.Protocol != ProtocolTCP {
+		t.Errorf("Unexpected converted rule: %+v", rule)
+	}
+	if len(rule.DestinationPorts) != 2 || rule.SourcePrefixes[0] != "Internet" || rule.Default {
+		t.Errorf("Unexpected converted rule lists: %+v", rule)
+	}
+	if !rules[len(rules)-1].Default {
+		t.Error("Expected default rules after custom rules")
+	}
+}
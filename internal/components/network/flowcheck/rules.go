@@ -0,0 +1,204 @@
+package flowcheck
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// Rule directions and access values, matching the NSG API values
+const (
+	DirectionInbound  = "Inbound"
+	DirectionOutbound = "Outbound"
+	AccessAllow       = "Allow"
+	AccessDeny        = "Deny"
+)
+
+// Rule is a network security group rule with its address and port lists flattened
+type Rule struct {
+	Name                string   `json:"name"`
+	Priority            int32    `json:"priority"`
+	Direction           string   `json:"direction"`
+	Access              string   `json:"access"`
+	Protocol            string   `json:"protocol"`
+	SourcePrefixes      []string `json:"source_address_prefixes,omitempty"`
+	SourcePorts         []string `json:"source_port_ranges,omitempty"`
+	DestinationPrefixes []string `json:"destination_address_prefixes,omitempty"`
+	DestinationPorts    []string `json:"destination_port_ranges,omitempty"`
+	SourceASGs          []string `json:"source_application_security_groups,omitempty"`
+	DestinationASGs     []string `json:"destination_application_security_groups,omitempty"`
+	Default             bool     `json:"default"`
+}
+
+// DefaultRules returns the default security rules Azure adds to every network security group
+func DefaultRules() []Rule {
+	return []Rule{
+		defaultRule("AllowVnetInBound", 65000, DirectionInbound, AccessAllow, TagVirtualNetwork, TagVirtualNetwork),
+		defaultRule("AllowAzureLoadBalancerInBound", 65001, DirectionInbound, AccessAllow, TagAzureLoadBalancer, TagAny),
+		defaultRule("DenyAllInBound", 65500, DirectionInbound, AccessDeny, TagAny, TagAny),
+		defaultRule("AllowVnetOutBound", 65000, DirectionOutbound, AccessAllow, TagVirtualNetwork, TagVirtualNetwork),
+		defaultRule("AllowInternetOutBound", 65001, DirectionOutbound, AccessAllow, TagAny, TagInternet),
+		defaultRule("DenyAllOutBound", 65500, DirectionOutbound, AccessDeny, TagAny, TagAny),
+	}
+}
+
+// defaultRule builds a default rule applying to all protocols and ports
+func defaultRule(name string, priority int32, direction, access, source, destination string) Rule {
+	return Rule{
+		Name:                name,
+		Priority:            priority,
+		Direction:           direction,
+		Access:              access,
+		Protocol:            TagAny,
+		SourcePrefixes:      []string{source},
+		SourcePorts:         []string{TagAny},
+		DestinationPrefixes: []string{destination},
+		DestinationPorts:    []string{TagAny},
+		Default:             true,
+	}
+}
+
+// RulesFromNSG returns the custom and default rules of a network security group.
+// The built-in default rules are used when the NSG does not report its default rules.
+func RulesFromNSG(nsg *armnetwork.SecurityGroup) []Rule {
+	if nsg == nil || nsg.Properties == nil {
+		return DefaultRules()
+	}
+
+	var rules []Rule
+	for _, rule := range nsg.Properties.SecurityRules {
+		if converted, ok := convertRule(rule, false); ok {
+			rules = append(rules, converted)
+		}
+	}
+
+	var defaults []Rule
+	for _, rule := range nsg.Properties.DefaultSecurityRules {
+		if converted, ok := convertRule(rule, true); ok {
+			defaults = append(defaults, converted)
+		}
+	}
+	if len(defaults) == 0 {
+		defaults = DefaultRules()
+	}
+
+	return append(rules, defaults...)
+}
+
+// convertRule flattens an NSG security rule
+func convertRule(rule *armnetwork.SecurityRule, isDefault bool) (Rule, bool) {
+	if rule == nil || rule.Properties == nil {
+		return Rule{}, false
+	}
+	props := rule.Properties
+
+	converted := Rule{
+		Default:             isDefault,
+		SourcePrefixes:      mergeValues(props.SourceAddressPrefix, props.SourceAddressPrefixes),
+		SourcePorts:         mergeValues(props.SourcePortRange, props.SourcePortRanges),
+		DestinationPrefixes: mergeValues(props.DestinationAddressPrefix, props.DestinationAddressPrefixes),
+		DestinationPorts:    mergeValues(props.DestinationPortRange, props.DestinationPortRanges),
+		SourceASGs:          asgIDs(props.SourceApplicationSecurityGroups),
+		DestinationASGs:     asgIDs(props.DestinationApplicationSecurityGroups),
+		Protocol:            TagAny,
+	}
+	if rule.Name != nil {
+		converted.Name = *rule.Name
+	}
+	if props.Priority != nil {
+		converted.Priority = *props.Priority
+	}
+	if props.Direction != nil {
+		converted.Direction = string(*props.Direction)
+	}
+	if props.Access != nil {
+		converted.Access = string(*props.Access)
+	}
+	if props.Protocol != nil {
+		converted.Protocol = string(*props.Protocol)
+	}
+
+	return converted, true
+}
+
+// mergeValues combines the single and list forms of an NSG rule field
+func mergeValues(single *string, list []*string) []string {
+	var values []string
+	if single != nil && *single != "" {
+		values = append(values, *single)
+	}
+	for _, value := range list {
+		if value != nil && *value != "" {
+			values = append(values, *value)
+		}
+	}
+	return values
+}
+
+// asgIDs returns the IDs of application security groups
+func asgIDs(groups []*armnetwork.ApplicationSecurityGroup) []string {
+	var ids []string
+	for _, group := range groups {
+		if group != nil && group.ID != nil {
+			ids = append(ids, *group.ID)
+		}
+	}
+	return ids
+}
+
+// Resolver expands the address prefixes and service tags used by NSG rules and flow endpoints
+type Resolver struct {
+	virtualNetwork AddressSet
+	serviceTags    *ServiceTagStore
+}
+
+// NewResolver creates a resolver. The VirtualNetwork tag covers the given prefixes, which should
+// include the VNet address space and the address space of peered VNets, plus the Azure host IP.
+func NewResolver(virtualNetworkPrefixes []netip.Prefix, serviceTags *ServiceTagStore) *Resolver {
+	prefixes := append(append([]netip.Prefix{}, virtualNetworkPrefixes...), azureHostIP)
+	return &Resolver{
+		virtualNetwork: NewAddressSet(prefixes),
+		serviceTags:    serviceTags,
+	}
+}
+
+// Resolve returns the addresses of an IP, CIDR, '*', built-in tag or service tag
+func (r *Resolver) Resolve(value string) (AddressSet, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == TagAny || strings.EqualFold(value, "Any"):
+		return anyAddressSet, nil
+	case strings.EqualFold(value, TagVirtualNetwork):
+		return r.virtualNetwork, nil
+	case strings.EqualFold(value, TagAzureLoadBalancer):
+		return NewAddressSet([]netip.Prefix{azureHostIP}), nil
+	case strings.EqualFold(value, TagInternet):
+		// The Internet tag covers the address space outside the virtual network
+		return AddressSet{ranges: r.virtualNetwork.ranges, complement: true}, nil
+	}
+
+	if prefix, err := ParsePrefix(value); err == nil {
+		return NewAddressSet([]netip.Prefix{prefix}), nil
+	}
+
+	set, ok, err := r.serviceTags.Lookup(value)
+	if err != nil {
+		return AddressSet{}, err
+	}
+	if !ok {
+		return AddressSet{}, fmt.Errorf("unknown address prefix or service tag %s. Configure an up to date service tags file to resolve service tags", value)
+	}
+	return set, nil
+}
+
+// IsServiceTag reports whether value names a tag rather than an IP address or CIDR prefix
+func IsServiceTag(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == TagAny || value == "" {
+		return false
+	}
+	_, err := ParsePrefix(value)
+	return err != nil
+}
@@ -0,0 +1,153 @@
+package flowcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in service tags that are not part of the service tags file
+const (
+	TagAny               = "*"
+	TagVirtualNetwork    = "VirtualNetwork"
+	TagAzureLoadBalancer = "AzureLoadBalancer"
+	TagInternet          = "Internet"
+)
+
+// azureHostIP is the virtual public IP used by the Azure platform, including the load balancer health probes
+var azureHostIP = netip.MustParsePrefix("168.63.129.16/32")
+
+// serviceTagsFile is the format of the Azure IP Ranges and Service Tags download file
+type serviceTagsFile struct {
+	ChangeNumber int    `json:"changeNumber"`
+	Cloud        string `json:"cloud"`
+	Values       []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			AddressPrefixes []string `json:"addressPrefixes"`
+		} `json:"properties"`
+	} `json:"values"`
+}
+
+// serviceTag is a service tag with its prefixes as published and their merged address set
+type serviceTag struct {
+	prefixes []netip.Prefix
+	set      AddressSet
+}
+
+// ServiceTagStore resolves Azure service tags from a local service tags file.
+// The file uses the format of the Azure IP Ranges and Service Tags download and
+// is reloaded whenever it changes, so it can be updated without restarting the server.
+type ServiceTagStore struct {
+	path string
+
+	mu           sync.Mutex
+	modTime      time.Time
+	size         int64
+	tags         map[string]serviceTag
+	changeNumber int
+}
+
+// NewServiceTagStore creates a store reading the service tags file at path.
+// An empty path yields a store with only the built-in tags.
+func NewServiceTagStore(path string) *ServiceTagStore {
+	return &ServiceTagStore{path: path}
+}
+
+// Lookup resolves a service tag from the service tags file, ignoring case.
+// Regional tags such as AzureCloud.westeurope are looked up by their full name.
+func (s *ServiceTagStore) Lookup(name string) (AddressSet, bool, error) {
+	tag, ok, err := s.lookup(name)
+	return tag.set, ok, err
+}
+
+// LookupPrefixes returns the address prefixes of a service tag as published in the
+// service tags file. Route tables need them to compare prefix lengths.
+func (s *ServiceTagStore) LookupPrefixes(name string) ([]netip.Prefix, bool, error) {
+	tag, ok, err := s.lookup(name)
+	return tag.prefixes, ok, err
+}
+
+// lookup returns a service tag, reloading the service tags file if it changed
+func (s *ServiceTagStore) lookup(name string) (serviceTag, bool, error) {
+	if s == nil || s.path == "" {
+		return serviceTag{}, false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return serviceTag{}, false, err
+	}
+
+	tag, ok := s.tags[strings.ToLower(name)]
+	return tag, ok, nil
+}
+
+// ChangeNumber returns the change number of the loaded service tags file
+func (s *ServiceTagStore) ChangeNumber() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.changeNumber
+}
+
+// reload reads the service tags file if it changed since it was last loaded
+func (s *ServiceTagStore) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read service tags file: %v", err)
+	}
+	if s.tags != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read service tags file: %v", err)
+	}
+
+	tags, changeNumber, err := parseServiceTags(data)
+	if err != nil {
+		return err
+	}
+
+	s.tags = tags
+	s.changeNumber = changeNumber
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return nil
+}
+
+// parseServiceTags parses a service tags file into service tags keyed by lower case tag name
+func parseServiceTags(data []byte) (map[string]serviceTag, int, error) {
+	var file serviceTagsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse service tags file: %v", err)
+	}
+
+	tags := make(map[string]serviceTag, len(file.Values))
+	for _, value := range file.Values {
+		if value.Name == "" {
+			continue
+		}
+		prefixes := make([]netip.Prefix, 0, len(value.Properties.AddressPrefixes))
+		for _, raw := range value.Properties.AddressPrefixes {
+			prefix, err := ParsePrefix(raw)
+			if err != nil {
+				return nil, 0, fmt.Errorf("service tag %s: %v", value.Name, err)
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		tags[strings.ToLower(value.Name)] = serviceTag{prefixes: prefixes, set: NewAddressSet(prefixes)}
+	}
+
+	return tags, file.ChangeNumber, nil
+}
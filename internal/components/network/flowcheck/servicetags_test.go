@@ -0,0 +1,77 @@
+package flowcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testServiceTags = `{
+  "changeNumber": 42,
+  "cloud": "Public",
+  "values": [
+    {"name": "AzureCloud.westeurope", "id": "AzureCloud.westeurope", "properties": {"addressPrefixes": ["20.50.0.0/16", "40.74.0.0/18"]}},
+    {"name": "Storage", "id": "Storage", "properties": {"addressPrefixes": ["20.60.0.0/16"]}}
+  ]
+}`
+
+func TestServiceTagStoreLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ServiceTags_Public.json")
+	if err := os.WriteFile(path, []byte(testServiceTags), 0600); err != nil {
+		t.Fatalf("failed to write service tags file: %v", err)
+	}
+
+	store := NewServiceTagStore(path)
+
+	set, ok, err := store.Lookup("azurecloud.WestEurope")
+	if err != nil || !ok {
+		t.Fatalf("Expected tag to be found, got ok=%v err=%v", ok, err)
+	}
+	if !set.Covers(mustSet(t, "40.74.1.1")) {
+		t.Errorf("Expected AzureCloud.westeurope to cover 40.74.1.1")
+	}
+	if store.ChangeNumber() != 42 {
+		t.Errorf("Expected change number 42, got %d", store.ChangeNumber())
+	}
+
+	prefixes, ok, err := store.LookupPrefixes("Storage")
+	if err != nil || !ok || len(prefixes) != 1 || prefixes[0].String() != "20.60.0.0/16" {
+		t.Errorf("Expected Storage prefixes [20.60.0.0/16], got %v ok=%v err=%v", prefixes, ok, err)
+	}
+
+	if _, ok, _ := store.Lookup("Sql"); ok {
+		t.Error("Expected unknown tag not to be found")
+	}
+
+	// Updating the file is picked up without recreating the store
+	updated := `{"changeNumber": 43, "values": [{"name": "Sql", "properties": {"addressPrefixes": ["13.66.0.0/24"]}}]}`
+	if err := os.WriteFile(path, []byte(updated), 0600); err != nil {
+		t.Fatalf("failed to update service tags file: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to update file times: %v", err)
+	}
+
+	if _, ok, err := store.Lookup("Sql"); err != nil || !ok {
+		t.Errorf("Expected updated tag to be found, got ok=%v err=%v", ok, err)
+	}
+	if store.ChangeNumber() != 43 {
+		t.Errorf("Expected change number 43, got %d", store.ChangeNumber())
+	}
+}
+
+func TestServiceTagStoreErrors(t *testing.T) {
+	if _, ok, err := NewServiceTagStore("").Lookup("Storage"); ok || err != nil {
+		t.Errorf("Expected store without file to find nothing, got ok=%v err=%v", ok, err)
+	}
+
+	if _, _, err := NewServiceTagStore(filepath.Join(t.TempDir(), "missing.json")).Lookup("Storage"); err == nil {
+		t.Error("Expected error for missing service tags file")
+	}
+
+	if _, _, err := parseServiceTags([]byte(`{"values": [{"name": "Bad", "properties": {"addressPrefixes": ["not-a-prefix"]}}]}`)); err == nil {
+		t.Error("Expected error for invalid address prefix")
+	}
+}
@@ -71,8 +71,17 @@ func GetNSGInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigData)
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
 		}
 
-		// Get the NSG ID from the cluster
-		nsgID, err := resourcehelpers.GetNSGIDFromAKS(ctx, cluster, client)
+		// Get the NSG ID from the cluster, or from the subnet of the requested node pool
+		var nsgID string
+		if nodePool, _ := params["node_pool"].(string); nodePool != "" {
+			var subnetID string
+			subnetID, err = resourcehelpers.GetNodePoolSubnetID(ctx, cluster, client, nodePool)
+			if err == nil {
+				nsgID, err = resourcehelpers.GetNSGIDFromSubnet(ctx, client, subnetID)
+			}
+		} else {
+			nsgID, err = resourcehelpers.GetNSGIDFromAKS(ctx, cluster, client)
+		}
 		if err != nil {
 			return "", fmt.Errorf("failed to get NSG ID: %v", err)
 		}
@@ -111,8 +120,17 @@ func GetRouteTableInfoHandler(client *azureclient.AzureClient, cfg *config.Confi
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
 		}
 
-		// Get the RouteTable ID from the cluster
-		rtID, err := resourcehelpers.GetRouteTableIDFromAKS(ctx, cluster, client)
+		// Get the RouteTable ID from the cluster, or from the subnet of the requested node pool
+		var rtID string
+		if nodePool, _ := params["node_pool"].(string); nodePool != "" {
+			var subnetID string
+			subnetID, err = resourcehelpers.GetNodePoolSubnetID(ctx, cluster, client, nodePool)
+			if err == nil {
+				rtID, err = resourcehelpers.GetRouteTableIDFromSubnet(ctx, client, subnetID)
+			}
+		} else {
+			rtID, err = resourcehelpers.GetRouteTableIDFromAKS(ctx, cluster, client)
+		}
 		if err != nil {
 			return "", fmt.Errorf("failed to get RouteTable ID: %v", err)
 		}
@@ -165,8 +183,13 @@ func GetSubnetInfoHandler(client *azureclient.AzureClient, cfg *config.ConfigDat
 			return "", fmt.Errorf("failed to get cluster details: %v", err)
 		}
 
-		// Get the Subnet ID from the cluster
-		subnetID, err := resourcehelpers.GetSubnetIDFromAKS(ctx, cluster, client)
+		// Get the Subnet ID from the cluster, or of the requested node pool
+		var subnetID string
+		if nodePool, _ := params["node_pool"].(string); nodePool != "" {
+			subnetID, err = resourcehelpers.GetNodePoolSubnetID(ctx, cluster, client, nodePool)
+		} else {
+			subnetID, err = resourcehelpers.GetSubnetIDFromAKS(ctx, cluster, client)
+		}
 		if err != nil {
 			return "", fmt.Errorf("failed to get Subnet ID: %v", err)
 		}
@@ -315,6 +338,41 @@ func GetPrivateEndpointInfoHandler(client *azureclient.AzureClient, cfg *config.
 	})
 }
 
+// GetNodePoolNetworksHandler returns a handler for the per node pool network mapping
+func GetNodePoolNetworksHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		// Extract parameters
+		subID, rg, clusterName, err := common.ExtractAKSParameters(params)
+		if err != nil {
+			return "", err
+		}
+		nodePool, _ := params["node_pool"].(string)
+
+		cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get cluster details: %v", err)
+		}
+
+		// Resolve the subnet, pod subnet, NSG and route table of each node pool
+		networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, nodePool)
+		if err != nil {
+			return "", fmt.Errorf("failed to get node pool networks: %v", err)
+		}
+
+		result := map[string]interface{}{
+			"count":      len(networks),
+			"node_pools": networks,
+		}
+
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal node pool networks to JSON: %v", err)
+		}
+
+		return string(resultJSON), nil
+	})
+}
+
 // GetAksNetworkResourcesHandler returns a handler for the aks_network_resources command
 func GetAksNetworkResourcesHandler(client *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
 	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
@@ -322,9 +380,11 @@ func GetAksNetworkResourcesHandler(client *azureclient.AzureClient, cfg *config.
 		if err != nil {
 			return "", err
 		}
+		nodePool, _ := params["node_pool"].(string)
+		format, _ := params["format"].(string)
 
 		// Handle resource type
-		return handleNetworkResourceType(ctx, client, resourceType, subID, rg, clusterName)
+		return handleNetworkResourceType(ctx, client, resourceType, subID, rg, clusterName, nodePool, format)
 	})
 }
 
@@ -352,22 +412,26 @@ func validateNetworkParams(params map[string]interface{}) (string, string, strin
 }
 
 // handleNetworkResourceType routes to the appropriate resource handler based on type
-func handleNetworkResourceType(ctx context.Context, client *azureclient.AzureClient, resourceType, subID, rg, clusterName string) (string, error) {
+func handleNetworkResourceType(ctx context.Context, client *azureclient.AzureClient, resourceType, subID, rg, clusterName, nodePool, format string) (string, error) {
 	switch resourceType {
 	case string(ResourceTypeAll):
-		return handleAllNetworkResources(ctx, client, subID, rg, clusterName)
+		return handleAllNetworkResources(ctx, client, subID, rg, clusterName, nodePool)
 	case string(ResourceTypeVNet):
-		return handleVNetResource(ctx, client, subID, rg, clusterName)
+		return handleVNetResource(ctx, client, subID, rg, clusterName, nodePool)
 	case string(ResourceTypeNSG):
-		return handleNSGResource(ctx, client, subID, rg, clusterName)
+		return handleNSGResource(ctx, client, subID, rg, clusterName, nodePool)
 	case string(ResourceTypeRouteTable):
-		return handleRouteTableResource(ctx, client, subID, rg, clusterName)
+		return handleRouteTableResource(ctx, client, subID, rg, clusterName, nodePool)
 	case string(ResourceTypeSubnet):
-		return handleSubnetResource(ctx, client, subID, rg, clusterName)
+		return handleSubnetResource(ctx, client, subID, rg, clusterName, nodePool)
 	case string(ResourceTypeLoadBalancer):
-		return handleLoadBalancerResource(ctx, client, subID, rg, clusterName)
+		return handleLoadBalancerResource(ctx, client, subID, rg, clusterName, nodePool)
 	case string(ResourceTypePrivateEndpoint):
-		return handlePrivateEndpointResource(ctx, client, subID, rg, clusterName)
+		return handlePrivateEndpointResource(ctx, client, subID, rg, clusterName, nodePool)
+	case string(ResourceTypeNodePools):
+		return handleNodePoolsResource(ctx, client, subID, rg, clusterName, nodePool)
+	case string(ResourceTypeTopology):
+		return handleTopologyResource(ctx, client, subID, rg, clusterName, nodePool, format)
 	default:
 		return "", fmt.Errorf("resource type '%s' not implemented", resourceType)
 	}
@@ -375,22 +439,23 @@ func handleNetworkResourceType(ctx context.Context, client *azureclient.AzureCli
 
 // Helper functions for different resource types
 
-func handleAllNetworkResources(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleAllNetworkResources(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	result := make(map[string]interface{})
 
 	// Collect results and errors for each resource type
-	resourceHandlers := map[string]func(context.Context, *azureclient.AzureClient, string, string, string) (string, error){
+	resourceHandlers := map[string]func(context.Context, *azureclient.AzureClient, string, string, string, string) (string, error){
 		"vnet":             handleVNetResource,
 		"nsg":              handleNSGResource,
 		"route_table":      handleRouteTableResource,
 		"subnet":           handleSubnetResource,
 		"load_balancer":    handleLoadBalancerResource,
 		"private_endpoint": handlePrivateEndpointResource,
+		"node_pools":       handleNodePoolsResource,
 	}
 
 	// Process each resource type and preserve error context
 	for resourceType, handler := range resourceHandlers {
-		resourceResult, err := handler(ctx, client, subID, rg, clusterName)
+		resourceResult, err := handler(ctx, client, subID, rg, clusterName, nodePool)
 		if err != nil {
 			// Preserve original error context and type for debugging
 			result[resourceType+"_error"] = map[string]interface{}{
@@ -410,7 +475,7 @@ func handleAllNetworkResources(ctx context.Context, client *azureclient.AzureCli
 	return string(resultJSON), nil
 }
 
-func handleVNetResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleVNetResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	// Use the existing VNet handler logic
 	handler := GetVNetInfoHandler(client, nil)
 	params := map[string]interface{}{
@@ -421,40 +486,43 @@ func handleVNetResource(ctx context.Context, client *azureclient.AzureClient, su
 	return handler.Handle(ctx, params, nil)
 }
 
-func handleNSGResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleNSGResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	// Use the existing NSG handler logic
 	handler := GetNSGInfoHandler(client, nil)
 	params := map[string]interface{}{
 		"subscription_id": subID,
 		"resource_group":  rg,
 		"cluster_name":    clusterName,
+		"node_pool":       nodePool,
 	}
 	return handler.Handle(ctx, params, nil)
 }
 
-func handleRouteTableResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleRouteTableResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	// Use the existing Route Table handler logic
 	handler := GetRouteTableInfoHandler(client, nil)
 	params := map[string]interface{}{
 		"subscription_id": subID,
 		"resource_group":  rg,
 		"cluster_name":    clusterName,
+		"node_pool":       nodePool,
 	}
 	return handler.Handle(ctx, params, nil)
 }
 
-func handleSubnetResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleSubnetResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	// Use the existing Subnet handler logic
 	handler := GetSubnetInfoHandler(client, nil)
 	params := map[string]interface{}{
 		"subscription_id": subID,
 		"resource_group":  rg,
 		"cluster_name":    clusterName,
+		"node_pool":       nodePool,
 	}
 	return handler.Handle(ctx, params, nil)
 }
 
-func handleLoadBalancerResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handleLoadBalancerResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	// Use the existing Load Balancer handler logic
 	handler := GetLoadBalancersInfoHandler(client, nil)
 	params := map[string]interface{}{
@@ -465,7 +533,7 @@ func handleLoadBalancerResource(ctx context.Context, client *azureclient.AzureCl
 	return handler.Handle(ctx, params, nil)
 }
 
-func handlePrivateEndpointResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName string) (string, error) {
+func handlePrivateEndpointResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
 	// Use the existing Private Endpoint handler logic
 	handler := GetPrivateEndpointInfoHandler(client, nil)
 	params := map[string]interface{}{
@@ -475,3 +543,15 @@ func handlePrivateEndpointResource(ctx context.Context, client *azureclient.Azur
 	}
 	return handler.Handle(ctx, params, nil)
 }
+
+func handleNodePoolsResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool string) (string, error) {
+	// Use the node pool network mapping handler logic
+	handler := GetNodePoolNetworksHandler(client, nil)
+	params := map[string]interface{}{
+		"subscription_id": subID,
+		"resource_group":  rg,
+		"cluster_name":    clusterName,
+		"node_pool":       nodePool,
+	}
+	return handler.Handle(ctx, params, nil)
+}
@@ -226,3 +226,27 @@ func TestGetPrivateEndpointInfoHandlerValidation(t *testing.T) {
 	// Note: Testing with valid parameters and actual Azure client calls
 	// would require integration tests with mocked Azure services
 }
+
+// TestGetNodePoolNetworksHandlerValidation tests the node pool networks handler parameter validation
+func TestGetNodePoolNetworksHandlerValidation(t *testing.T) {
+	cfg := &config.ConfigData{}
+
+	params := map[string]interface{}{
+		"subscription_id": "sub-123",
+		"resource_group":  "rg-test",
+		"node_pool":       "nodepool1",
+	}
+
+	handler := GetNodePoolNetworksHandler(nil, cfg)
+	result, err := handler.Handle(context.Background(), params, cfg)
+
+	if err == nil {
+		t.Fatal("Expected error for missing cluster_name")
+	}
+	if result != "" {
+		t.Error("Expected empty result on error")
+	}
+	if err.Error() != "missing or invalid cluster_name parameter" {
+		t.Errorf("Expected 'missing or invalid cluster_name parameter' error, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/ipam"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// IPCapacityResult is the IP address capacity plan of a cluster
+type IPCapacityResult struct {
+	ipam.Plan
+	Notes []string `json:"notes,omitempty"`
+}
+
+// handleIPCapacity plans how many more nodes each node pool can reach before its subnet, pod
+// subnet or pod CIDR runs out of addresses, and whether an upgrade surge would be blocked
+func handleIPCapacity(ctx context.Context, client *azureclient.AzureClient, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	nodePool, _ := params["node_pool"].(string)
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	// Every pool is planned because pools share the pod CIDR and subnets; node_pool only scopes the output
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve node pool networks: %v", err)
+	}
+
+	input, notes := ipCapacityInput(cluster, networks)
+	input.Subnets, notes = loadIPCapacitySubnets(ctx, client, input.NodePools, notes)
+
+	result := &IPCapacityResult{Plan: ipam.PlanCapacity(input), Notes: notes}
+	if nodePool != "" {
+		if err := scopeIPCapacityResult(result, nodePool); err != nil {
+			return "", err
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal IP capacity to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// ipCapacityInput builds the planner input from the cluster network profile, the agent pool
+// profiles and the resolved node pool subnets
+func ipCapacityInput(cluster *armcontainerservice.ManagedCluster, networks []resourcehelpers.NodePoolNetwork) (ipam.Input, []string) {
+	input := ipam.Input{}
+	var notes []string
+	if cluster.Properties == nil {
+		return input, notes
+	}
+
+	if profile := cluster.Properties.NetworkProfile; profile != nil {
+		if profile.NetworkPlugin != nil {
+			input.NetworkPlugin = string(*profile.NetworkPlugin)
+		}
+		podCIDRs := profile.PodCidrs
+		if profile.PodCidr != nil {
+			podCIDRs = append([]*string{profile.PodCidr}, podCIDRs...)
+		}
+		for _, value := range podCIDRs {
+			if value == nil {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(*value)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("invalid pod CIDR %s: %v", *value, err))
+				continue
+			}
+			if prefix.Addr().Is4() {
+				input.PodCIDR = prefix
+				break
+			}
+		}
+	}
+	if strings.EqualFold(input.NetworkPlugin, "azure") {
+		notes = append(notes, "The network plugin mode is not returned by the API version in use; Azure CNI overlay is inferred from the cluster pod CIDR")
+	}
+
+	subnets := map[string]resourcehelpers.NodePoolNetwork{}
+	for _, network := range networks {
+		subnets[network.NodePool] = network
+		for _, message := range network.Errors {
+			notes = append(notes, fmt.Sprintf("node pool %s: %s", network.NodePool, message))
+		}
+	}
+
+	for _, profile := range cluster.Properties.AgentPoolProfiles {
+		if profile == nil || profile.Name == nil {
+			continue
+		}
+		pool := ipam.NodePool{
+			Name:        *profile.Name,
+			SubnetID:    subnets[*profile.Name].SubnetID,
+			PodSubnetID: subnets[*profile.Name].PodSubnetID,
+		}
+		if profile.Count != nil {
+			pool.Count = int(*profile.Count)
+		}
+		if profile.EnableAutoScaling != nil && *profile.EnableAutoScaling && profile.MaxCount != nil {
+			pool.MaxCount = int(*profile.MaxCount)
+		}
+		if profile.MaxPods != nil {
+			pool.MaxPods = int(*profile.MaxPods)
+		}
+		if profile.UpgradeSettings != nil && profile.UpgradeSettings.MaxSurge != nil {
+			pool.MaxSurge = *profile.UpgradeSettings.MaxSurge
+		}
+		input.NodePools = append(input.NodePools, pool)
+	}
+	return input, notes
+}
+
+// loadIPCapacitySubnets reads the address prefixes and IP configurations of the node and pod subnets
+func loadIPCapacitySubnets(ctx context.Context, client *azureclient.AzureClient, pools []ipam.NodePool, notes []string) (map[string]ipam.Subnet, []string) {
+	subnets := map[string]ipam.Subnet{}
+	failed := map[string]bool{}
+	for _, pool := range pools {
+		for _, subnetID := range []string{pool.SubnetID, pool.PodSubnetID} {
+			key := strings.ToLower(subnetID)
+			if subnetID == "" || failed[key] {
+				continue
+			}
+			if _, ok := subnets[key]; ok {
+				continue
+			}
+
+			subnet, err := resourcehelpers.GetSubnetByID(ctx, client, subnetID)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("subnet %s: %v", subnetID, err))
+				failed[key] = true
+				continue
+			}
+			prefixes, err := resourcehelpers.GetSubnetAddressPrefixes(subnet)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("subnet %s: %v", subnetID, err))
+				failed[key] = true
+				continue
+			}
+
+			usedIPs := 0
+			if subnet.Properties != nil {
+				usedIPs = len(subnet.Properties.IPConfigurations)
+			}
+			subnets[key] = ipam.Subnet{ID: subnetID, Prefixes: prefixes, UsedIPs: usedIPs}
+		}
+	}
+	return subnets, notes
+}
+
+// scopeIPCapacityResult keeps only the given node pool and the subnets it uses
+func scopeIPCapacityResult(result *IPCapacityResult, nodePool string) error {
+	var pool *ipam.NodePoolPlan
+	for i := range result.NodePools {
+		if result.NodePools[i].NodePool == nodePool {
+			pool = &result.NodePools[i]
+		}
+	}
+	if pool == nil {
+		return fmt.Errorf("node pool %s not found in cluster", nodePool)
+	}
+
+	subnets := []ipam.SubnetPlan{}
+	for _, subnet := range result.Subnets {
+		if strings.EqualFold(subnet.ID, pool.SubnetID) || strings.EqualFold(subnet.ID, pool.PodSubnetID) {
+			subnets = append(subnets, subnet)
+		}
+	}
+	result.Subnets = subnets
+	result.NodePools = []ipam.NodePoolPlan{*pool}
+	return nil
+}
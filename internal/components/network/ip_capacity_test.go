@@ -0,0 +1,78 @@
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/ipam"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+func TestIPCapacityInput(t *testing.T) {
+	const subnetID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/nodes"
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			NetworkProfile: &armcontainerservice.NetworkProfile{
+				NetworkPlugin: to.Ptr(armcontainerservice.NetworkPluginAzure),
+				PodCidrs:      []*string{to.Ptr("fd12::/64"), to.Ptr("10.244.0.0/16")},
+			},
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{
+					Name:              to.Ptr("system"),
+					Count:             to.Ptr[int32](3),
+					EnableAutoScaling: to.Ptr(true),
+					MaxCount:          to.Ptr[int32](10),
+					MaxPods:           to.Ptr[int32](110),
+					UpgradeSettings:   &armcontainerservice.AgentPoolUpgradeSettings{MaxSurge: to.Ptr("33%")},
+				},
+				{Name: to.Ptr("user"), Count: to.Ptr[int32](2), MaxCount: to.Ptr[int32](20)},
+			},
+		},
+	}
+	networks := []resourcehelpers.NodePoolNetwork{
+		{NodePool: "system", SubnetID: subnetID},
+		{NodePool: "user", SubnetID: subnetID, Errors: []string{"failed to get subnet"}},
+	}
+
+	input, notes := ipCapacityInput(cluster, networks)
+	if input.NetworkPlugin != "azure" || input.PodCIDR.String() != "10.244.0.0/16" {
+		t.Errorf("Expected azure plugin with the IPv4 pod CIDR, got %s %s", input.NetworkPlugin, input.PodCIDR)
+	}
+	if len(input.NodePools) != 2 {
+		t.Fatalf("Expected 2 node pools, got %d", len(input.NodePools))
+	}
+	system := input.NodePools[0]
+	if system.Count != 3 || system.MaxCount != 10 || system.MaxPods != 110 || system.MaxSurge != "33%" || system.SubnetID != subnetID {
+		t.Errorf("Unexpected system pool input: %+v", system)
+	}
+	if input.NodePools[1].MaxCount != 0 {
+		t.Errorf("Expected the max count of a pool without autoscaling to be ignored, got %d", input.NodePools[1].MaxCount)
+	}
+	if len(notes) != 2 || !strings.Contains(notes[0], "inferred") || !strings.Contains(notes[1], "node pool user") {
+		t.Errorf("Expected the overlay inference and node pool error notes, got %v", notes)
+	}
+}
+
+func TestScopeIPCapacityResult(t *testing.T) {
+	result := &IPCapacityResult{
+		Plan: ipam.Plan{
+			Subnets: []ipam.SubnetPlan{{ID: "/subnets/a"}, {ID: "/subnets/b"}, {ID: "/subnets/pods"}},
+			NodePools: []ipam.NodePoolPlan{
+				{NodePool: "one", SubnetID: "/subnets/a", PodSubnetID: "/subnets/PODS"},
+				{NodePool: "two", SubnetID: "/subnets/b"},
+			},
+		},
+	}
+
+	if err := scopeIPCapacityResult(result, "missing"); err == nil {
+		t.Error("Expected error for an unknown node pool")
+	}
+	if err := scopeIPCapacityResult(result, "one"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.NodePools) != 1 || len(result.Subnets) != 2 || result.Subnets[1].ID != "/subnets/pods" {
+		t.Errorf("Expected node pool one with its node and pod subnets, got %+v", result.Plan)
+	}
+}
@@ -0,0 +1,363 @@
+// Package ipam plans the subnet IP address capacity of AKS node pools for the supported CNI modes.
+package ipam
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+// Network modes of a node pool, derived from the network plugin, the pod CIDR and the pod subnet
+const (
+	// ModeAzureCNI reserves an IP per pod from the node subnet when a node is created
+	ModeAzureCNI = "azure_cni"
+	// ModeAzureCNIOverlay takes pod IPs from the cluster pod CIDR, one /24 per node
+	ModeAzureCNIOverlay = "azure_cni_overlay"
+	// ModeAzureCNIPodSubnet takes pod IPs dynamically from a separate pod subnet
+	ModeAzureCNIPodSubnet = "azure_cni_pod_subnet"
+	// ModeKubenet takes pod IPs from the cluster pod CIDR, one /24 per node
+	ModeKubenet = "kubenet"
+	// ModeNone is a cluster without a pre-installed CNI, which only uses node IPs
+	ModeNone = "none"
+)
+
+const (
+	// AzureReservedIPs is the number of addresses Azure reserves in every subnet prefix
+	AzureReservedIPs = 5
+	// NodePodCIDRBits is the prefix length of the pod CIDR block assigned to each node
+	NodePodCIDRBits = 24
+	// KubenetMaxNodes is the route table limit on the number of kubenet nodes
+	KubenetMaxNodes = 400
+	// DefaultMaxSurge is the surge used by AKS when the node pool does not set one
+	DefaultMaxSurge = "1"
+)
+
+// defaultMaxPods is the AKS default max pods per node for each mode
+var defaultMaxPods = map[string]int{
+	ModeAzureCNI:          30,
+	ModeAzureCNIOverlay:   250,
+	ModeAzureCNIPodSubnet: 30,
+	ModeKubenet:           110,
+	ModeNone:              250,
+}
+
+// Subnet is a subnet with its address prefixes and the IP configurations already using it
+type Subnet struct {
+	ID       string
+	Prefixes []netip.Prefix
+	UsedIPs  int
+}
+
+// NodePool is the scaling and network configuration of a node pool
+type NodePool struct {
+	Name        string
+	Count       int
+	MaxCount    int
+	MaxPods     int
+	MaxSurge    string
+	SubnetID    string
+	PodSubnetID string
+}
+
+// Input is the cluster network configuration to plan
+type Input struct {
+	NetworkPlugin string
+	PodCIDR       netip.Prefix
+	NodePools     []NodePool
+	// Subnets holds the node and pod subnets keyed by lower case resource ID
+	Subnets map[string]Subnet
+}
+
+// SubnetPlan is the IP usage of a node or pod subnet
+type SubnetPlan struct {
+	ID              string   `json:"id"`
+	AddressPrefixes []string `json:"address_prefixes"`
+	UsableIPs       int      `json:"usable_ips"`
+	UsedIPs         int      `json:"used_ips"`
+	AvailableIPs    int      `json:"available_ips"`
+	NodePools       []string `json:"node_pools"`
+	// IPsForMaxCount is the number of IPs needed to scale every pool in the subnet to its maximum
+	IPsForMaxCount int `json:"ips_for_max_count"`
+}
+
+// NodePoolPlan is the IP capacity of a node pool
+type NodePoolPlan struct {
+	NodePool         string          `json:"node_pool"`
+	Mode             string          `json:"mode"`
+	SubnetID         string          `json:"subnet_id,omitempty"`
+	PodSubnetID      string          `json:"pod_subnet_id,omitempty"`
+	Count            int             `json:"count"`
+	MaxCount         int             `json:"max_count,omitempty"`
+	MaxPods          int             `json:"max_pods"`
+	NodeSubnetIPs    int             `json:"node_subnet_ips_per_node"`
+	PodSubnetIPs     int             `json:"pod_subnet_ips_per_node,omitempty"`
+	AdditionalNodes  int             `json:"additional_nodes"`
+	MaxReachable     int             `json:"max_reachable_nodes"`
+	LimitedBy        string          `json:"limited_by,omitempty"`
+	SurgeNodes       int             `json:"surge_nodes"`
+	SurgeIPsRequired int             `json:"surge_ips_required"`
+	SurgeBlocked     bool            `json:"surge_blocked"`
+	Findings         common.Findings `json:"findings,omitempty"`
+}
+
+// Plan is the IP capacity plan of a cluster
+type Plan struct {
+	NetworkPlugin  string          `json:"network_plugin"`
+	PodCIDR        string          `json:"pod_cidr,omitempty"`
+	PodCIDRNodes   int             `json:"pod_cidr_node_capacity,omitempty"`
+	Subnets        []SubnetPlan    `json:"subnets"`
+	NodePools      []NodePoolPlan  `json:"node_pools"`
+	Findings       common.Findings `json:"findings,omitempty"`
+	subnetIndex    map[string]int
+	clusterNodes   int
+	remainingNodes int
+}
+
+// PoolMode returns the network mode of a node pool
+func PoolMode(networkPlugin string, podCIDR netip.Prefix, podSubnetID string) string {
+	switch strings.ToLower(networkPlugin) {
+	case "kubenet":
+		return ModeKubenet
+	case "none":
+		return ModeNone
+	}
+	switch {
+	case podSubnetID != "":
+		return ModeAzureCNIPodSubnet
+	case podCIDR.IsValid():
+		return ModeAzureCNIOverlay
+	default:
+		return ModeAzureCNI
+	}
+}
+
+// UsableIPs returns the number of IPv4 addresses of the prefixes that Azure lets resources use
+func UsableIPs(prefixes []netip.Prefix) int {
+	usable := 0
+	for _, prefix := range prefixes {
+		if !prefix.Addr().Is4() {
+			continue
+		}
+		usable += max((1<<(32-prefix.Bits()))-AzureReservedIPs, 0)
+	}
+	return usable
+}
+
+// SurgeNodes returns the number of extra nodes created during an upgrade for a max surge of
+// either a node count or a percentage of the pool size rounded up
+func SurgeNodes(maxSurge string, count int) (int, error) {
+	maxSurge = strings.TrimSpace(maxSurge)
+	if maxSurge == "" {
+		maxSurge = DefaultMaxSurge
+	}
+
+	if percent, ok := strings.CutSuffix(maxSurge, "%"); ok {
+		value, err := strconv.Atoi(percent)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid max surge %q", maxSurge)
+		}
+		return int(math.Ceil(float64(count) * float64(value) / 100)), nil
+	}
+
+	value, err := strconv.Atoi(maxSurge)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid max surge %q", maxSurge)
+	}
+	return value, nil
+}
+
+// PlanCapacity computes how many more nodes each pool can reach before its node subnet, pod
+// subnet or pod CIDR runs out of addresses, and whether an upgrade surge fits in the free IPs.
+// Pools sharing a subnet are evaluated independently against the free IPs of the subnet.
+func PlanCapacity(input Input) Plan {
+	plan := Plan{
+		NetworkPlugin: input.NetworkPlugin,
+		Subnets:       []SubnetPlan{},
+		NodePools:     []NodePoolPlan{},
+		subnetIndex:   map[string]int{},
+	}
+
+	totalMaxNodes := 0
+	for _, pool := range input.NodePools {
+		plan.clusterNodes += pool.Count
+		totalMaxNodes += max(pool.Count, pool.MaxCount)
+	}
+	if input.PodCIDR.IsValid() && input.PodCIDR.Bits() <= NodePodCIDRBits {
+		plan.PodCIDR = input.PodCIDR.String()
+		plan.PodCIDRNodes = 1 << (NodePodCIDRBits - input.PodCIDR.Bits())
+		plan.remainingNodes = plan.PodCIDRNodes - plan.clusterNodes
+		if totalMaxNodes > plan.PodCIDRNodes {
+			plan.Findings.Add(common.SeverityWarning, "pod_cidr_too_small",
+				fmt.Sprintf("The pod CIDR %s has room for %d nodes but the node pools can scale to %d", plan.PodCIDR, plan.PodCIDRNodes, totalMaxNodes))
+		}
+	}
+	if strings.EqualFold(input.NetworkPlugin, "kubenet") && totalMaxNodes > KubenetMaxNodes {
+		plan.Findings.Add(common.SeverityWarning, "kubenet_node_limit",
+			fmt.Sprintf("Kubenet supports up to %d nodes because of the route table limit, but the node pools can scale to %d", KubenetMaxNodes, totalMaxNodes))
+	}
+
+	for _, pool := range input.NodePools {
+		plan.NodePools = append(plan.NodePools, plan.planNodePool(input, pool))
+	}
+
+	for i := range plan.Subnets {
+		subnet := &plan.Subnets[i]
+		if subnet.IPsForMaxCount > subnet.AvailableIPs && len(subnet.NodePools) > 1 {
+			plan.Findings.Add(common.SeverityWarning, "shared_subnet_exhausted",
+				fmt.Sprintf("Scaling node pools %s to their maximum needs %d IPs but subnet %s has %d available", strings.Join(subnet.NodePools, ", "), subnet.IPsForMaxCount, nameFromID(subnet.ID), subnet.AvailableIPs))
+		}
+	}
+	return plan
+}
+
+// planNodePool computes the capacity of a single node pool
+func (plan *Plan) planNodePool(input Input, pool NodePool) NodePoolPlan {
+	mode := PoolMode(input.NetworkPlugin, input.PodCIDR, pool.PodSubnetID)
+	result := NodePoolPlan{
+		NodePool:    pool.Name,
+		Mode:        mode,
+		SubnetID:    pool.SubnetID,
+		PodSubnetID: pool.PodSubnetID,
+		Count:       pool.Count,
+		MaxCount:    pool.MaxCount,
+		MaxPods:     pool.MaxPods,
+	}
+	if result.MaxPods <= 0 {
+		result.MaxPods = defaultMaxPods[mode]
+	}
+
+	// Azure CNI reserves the node IP and an IP per pod from the node subnet up front; the other
+	// modes only take the node IP from it
+	result.NodeSubnetIPs = 1
+	if mode == ModeAzureCNI {
+		result.NodeSubnetIPs = result.MaxPods + 1
+	}
+	if mode == ModeAzureCNIPodSubnet {
+		result.PodSubnetIPs = result.MaxPods
+	}
+
+	surge, err := SurgeNodes(pool.MaxSurge, pool.Count)
+	if err != nil {
+		result.Findings.Add(common.SeverityWarning, "invalid_max_surge", err.Error())
+		surge, _ = SurgeNodes(DefaultMaxSurge, pool.Count)
+	}
+	result.SurgeNodes = surge
+	targetNodes := max(pool.Count, pool.MaxCount)
+
+	result.AdditionalNodes = math.MaxInt
+	nodeSubnet := plan.subnetPlan(input, pool.SubnetID, pool.Name, result.NodeSubnetIPs*(targetNodes-pool.Count))
+	if nodeSubnet != nil {
+		result.SurgeIPsRequired = surge * result.NodeSubnetIPs
+		result.limit(nodeSubnet.AvailableIPs/result.NodeSubnetIPs, "node_subnet")
+		if result.SurgeIPsRequired > nodeSubnet.AvailableIPs {
+			result.SurgeBlocked = true
+			result.Findings.Add(common.SeverityError, "surge_blocked_node_subnet",
+				fmt.Sprintf("An upgrade surge of %d nodes needs %d IPs but node subnet %s has %d available", surge, result.SurgeIPsRequired, nameFromID(nodeSubnet.ID), nodeSubnet.AvailableIPs))
+		}
+	} else if pool.SubnetID != "" {
+		result.Findings.Add(common.SeverityWarning, "node_subnet_unknown", fmt.Sprintf("Node subnet %s could not be read", pool.SubnetID))
+	}
+
+	if mode == ModeAzureCNIPodSubnet {
+		podSubnet := plan.subnetPlan(input, pool.PodSubnetID, pool.Name, result.PodSubnetIPs*(targetNodes-pool.Count))
+		if podSubnet != nil {
+			podSurgeIPs := surge * result.PodSubnetIPs
+			result.limit(podSubnet.AvailableIPs/result.PodSubnetIPs, "pod_subnet")
+			if podSurgeIPs > podSubnet.AvailableIPs {
+				result.SurgeBlocked = true
+				result.Findings.Add(common.SeverityError, "surge_blocked_pod_subnet",
+					fmt.Sprintf("An upgrade surge of %d nodes can need up to %d pod IPs but pod subnet %s has %d available", surge, podSurgeIPs, nameFromID(podSubnet.ID), podSubnet.AvailableIPs))
+			}
+		} else {
+			result.Findings.Add(common.SeverityWarning, "pod_subnet_unknown", fmt.Sprintf("Pod subnet %s could not be read", pool.PodSubnetID))
+		}
+	}
+
+	if (mode == ModeAzureCNIOverlay || mode == ModeKubenet) && plan.PodCIDRNodes > 0 {
+		result.limit(max(plan.remainingNodes, 0), "pod_cidr")
+		if surge > plan.remainingNodes {
+			result.SurgeBlocked = true
+			result.Findings.Add(common.SeverityError, "surge_blocked_pod_cidr",
+				fmt.Sprintf("An upgrade surge of %d nodes needs %d more /%d pod CIDR blocks but only %d are free", surge, surge, NodePodCIDRBits, max(plan.remainingNodes, 0)))
+		}
+	}
+	if mode == ModeKubenet {
+		freeRoutes := max(KubenetMaxNodes-plan.clusterNodes, 0)
+		result.limit(freeRoutes, "kubenet_route_limit")
+		if surge > freeRoutes {
+			result.SurgeBlocked = true
+			result.Findings.Add(common.SeverityError, "surge_blocked_kubenet_route_limit",
+				fmt.Sprintf("An upgrade surge of %d nodes exceeds the %d nodes left under the kubenet limit of %d", surge, freeRoutes, KubenetMaxNodes))
+		}
+	}
+
+	if result.AdditionalNodes == math.MaxInt {
+		result.AdditionalNodes = 0
+		result.LimitedBy = "unknown"
+		return result
+	}
+	result.MaxReachable = pool.Count + result.AdditionalNodes
+
+	if result.AdditionalNodes == 0 {
+		result.Findings.Add(common.SeverityError, "no_room_to_scale",
+			fmt.Sprintf("The node pool cannot add nodes, limited by the %s", strings.ReplaceAll(result.LimitedBy, "_", " ")))
+	} else if pool.MaxCount > result.MaxReachable {
+		result.Findings.Add(common.SeverityWarning, "max_count_unreachable",
+			fmt.Sprintf("The autoscaler maximum of %d nodes cannot be reached; the %s allows %d nodes", pool.MaxCount, strings.ReplaceAll(result.LimitedBy, "_", " "), result.MaxReachable))
+	}
+	return result
+}
+
+// subnetPlan returns the plan of a subnet, creating it on first use, and records the node pool and
+// the IPs it needs to reach its maximum node count. It returns nil when the subnet is unknown.
+func (plan *Plan) subnetPlan(input Input, subnetID, nodePool string, ipsForMaxCount int) *SubnetPlan {
+	if subnetID == "" {
+		return nil
+	}
+	key := strings.ToLower(subnetID)
+
+	index, ok := plan.subnetIndex[key]
+	if !ok {
+		subnet, found := input.Subnets[key]
+		if !found {
+			return nil
+		}
+		usable := UsableIPs(subnet.Prefixes)
+		prefixes := make([]string, 0, len(subnet.Prefixes))
+		for _, prefix := range subnet.Prefixes {
+			prefixes = append(prefixes, prefix.String())
+		}
+		index = len(plan.Subnets)
+		plan.subnetIndex[key] = index
+		plan.Subnets = append(plan.Subnets, SubnetPlan{
+			ID:              subnetID,
+			AddressPrefixes: prefixes,
+			UsableIPs:       usable,
+			UsedIPs:         subnet.UsedIPs,
+			AvailableIPs:    max(usable-subnet.UsedIPs, 0),
+			NodePools:       []string{},
+		})
+	}
+
+	subnetPlan := &plan.Subnets[index]
+	subnetPlan.NodePools = append(subnetPlan.NodePools, nodePool)
+	subnetPlan.IPsForMaxCount += ipsForMaxCount
+	return subnetPlan
+}
+
+// limit caps the additional nodes of a pool and records the limiting resource
+func (p *NodePoolPlan) limit(nodes int, resource string) {
+	if nodes < p.AdditionalNodes {
+		p.AdditionalNodes = nodes
+		p.LimitedBy = resource
+	}
+}
+
+// nameFromID returns the last segment of a resource ID
+func nameFromID(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
@@ -0,0 +1,219 @@
+package ipam
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+const (
+	testNodeSubnetID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/nodes"
+	testPodSubnetID  = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pods"
+)
+
+func testSubnets(nodePrefix string, nodeUsed int, podPrefix string, podUsed int) map[string]Subnet {
+	subnets := map[string]Subnet{
+		strings.ToLower(testNodeSubnetID): {ID: testNodeSubnetID, Prefixes: []netip.Prefix{netip.MustParsePrefix(nodePrefix)}, UsedIPs: nodeUsed},
+	}
+	if podPrefix != "" {
+		subnets[strings.ToLower(testPodSubnetID)] = Subnet{ID: testPodSubnetID, Prefixes: []netip.Prefix{netip.MustParsePrefix(podPrefix)}, UsedIPs: podUsed}
+	}
+	return subnets
+}
+
+func TestPoolMode(t *testing.T) {
+	podCIDR := netip.MustParsePrefix("192.168.0.0/16")
+	tests := []struct {
+		plugin      string
+		podCIDR     netip.Prefix
+		podSubnetID string
+		expected    string
+	}{
+		{"azure", netip.Prefix{}, "", ModeAzureCNI},
+		{"azure", podCIDR, "", ModeAzureCNIOverlay},
+		{"azure", netip.Prefix{}, testPodSubnetID, ModeAzureCNIPodSubnet},
+		{"kubenet", podCIDR, "", ModeKubenet},
+		{"none", netip.Prefix{}, "", ModeNone},
+	}
+
+	for _, tt := range tests {
+		if got := PoolMode(tt.plugin, tt.podCIDR, tt.podSubnetID); got != tt.expected {
+			t.Errorf("Expected mode %s for plugin %s, got %s", tt.expected, tt.plugin, got)
+		}
+	}
+}
+
+func TestUsableIPs(t *testing.T) {
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/24"),
+		netip.MustParsePrefix("10.0.1.0/29"),
+		netip.MustParsePrefix("fd00::/64"),
+	}
+	if got := UsableIPs(prefixes); got != 251+3 {
+		t.Errorf("Expected 254 usable IPs, got %d", got)
+	}
+}
+
+func TestSurgeNodes(t *testing.T) {
+	tests := []struct {
+		maxSurge    string
+		count       int
+		expected    int
+		expectError bool
+	}{
+		{"", 10, 1, false},
+		{"3", 10, 3, false},
+		{"33%", 10, 4, false},
+		{"100%", 5, 5, false},
+		{"abc", 5, 0, true},
+		{"-1", 5, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := SurgeNodes(tt.maxSurge, tt.count)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("Expected error for max surge %q", tt.maxSurge)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("Expected %d surge nodes for %q, got %d err=%v", tt.expected, tt.maxSurge, got, err)
+		}
+	}
+}
+
+func TestPlanCapacityAzureCNI(t *testing.T) {
+	// A /24 has 251 usable IPs; 3 nodes with 30 pods use 93
+	plan := PlanCapacity(Input{
+		NetworkPlugin: "azure",
+		NodePools: []NodePool{
+			{Name: "system", Count: 3, MaxCount: 10, MaxPods: 30, MaxSurge: "2", SubnetID: testNodeSubnetID},
+		},
+		Subnets: testSubnets("10.0.0.0/24", 93, "", 0),
+	})
+
+	pool := plan.NodePools[0]
+	if pool.Mode != ModeAzureCNI || pool.NodeSubnetIPs != 31 {
+		t.Fatalf("Expected Azure CNI with 31 IPs per node, got %+v", pool)
+	}
+	if pool.AdditionalNodes != 5 || pool.MaxReachable != 8 || pool.LimitedBy != "node_subnet" {
+		t.Errorf("Expected 5 more nodes limited by the node subnet, got %+v", pool)
+	}
+	if pool.SurgeIPsRequired != 62 || pool.SurgeBlocked {
+		t.Errorf("Expected a 62 IP surge that fits, got %+v", pool)
+	}
+	if !pool.Findings.Has("max_count_unreachable") {
+		t.Errorf("Expected max_count_unreachable finding, got %+v", pool.Findings)
+	}
+	if plan.Subnets[0].AvailableIPs != 158 || plan.Subnets[0].IPsForMaxCount != 217 {
+		t.Errorf("Unexpected subnet plan: %+v", plan.Subnets[0])
+	}
+}
+
+func TestPlanCapacitySurgeBlocked(t *testing.T) {
+	plan := PlanCapacity(Input{
+		NetworkPlugin: "azure",
+		NodePools: []NodePool{
+			{Name: "user", Count: 7, MaxPods: 30, MaxSurge: "50%", SubnetID: testNodeSubnetID},
+		},
+		Subnets: testSubnets("10.0.0.0/24", 217, "", 0),
+	})
+
+	pool := plan.NodePools[0]
+	if pool.SurgeNodes != 4 || !pool.SurgeBlocked || !pool.Findings.Has("surge_blocked_node_subnet") {
+		t.Errorf("Expected a blocked surge of 4 nodes, got %+v", pool)
+	}
+	if pool.AdditionalNodes != 1 {
+		t.Errorf("Expected room for 1 more node, got %d", pool.AdditionalNodes)
+	}
+}
+
+func TestPlanCapacityOverlay(t *testing.T) {
+	plan := PlanCapacity(Input{
+		NetworkPlugin: "azure",
+		PodCIDR:       netip.MustParsePrefix("10.244.0.0/22"),
+		NodePools: []NodePool{
+			{Name: "system", Count: 2, MaxCount: 6, SubnetID: testNodeSubnetID},
+		},
+		Subnets: testSubnets("10.0.0.0/24", 2, "", 0),
+	})
+
+	if plan.PodCIDRNodes != 4 || !plan.Findings.Has("pod_cidr_too_small") {
+		t.Errorf("Expected a pod CIDR for 4 nodes that is too small, got %+v", plan)
+	}
+	pool := plan.NodePools[0]
+	if pool.Mode != ModeAzureCNIOverlay || pool.NodeSubnetIPs != 1 || pool.MaxPods != 250 {
+		t.Errorf("Expected overlay with 1 node IP and 250 default pods, got %+v", pool)
+	}
+	if pool.AdditionalNodes != 2 || pool.LimitedBy != "pod_cidr" {
+		t.Errorf("Expected 2 more nodes limited by the pod CIDR, got %+v", pool)
+	}
+}
+
+func TestPlanCapacityPodSubnet(t *testing.T) {
+	plan := PlanCapacity(Input{
+		NetworkPlugin: "azure",
+		NodePools: []NodePool{
+			{Name: "user", Count: 4, MaxCount: 8, MaxPods: 50, SubnetID: testNodeSubnetID, PodSubnetID: testPodSubnetID},
+		},
+		Subnets: testSubnets("10.0.0.0/24", 4, "10.1.0.0/24", 200),
+	})
+
+	pool := plan.NodePools[0]
+	if pool.Mode != ModeAzureCNIPodSubnet || pool.PodSubnetIPs != 50 {
+		t.Fatalf("Expected dynamic pod subnet mode, got %+v", pool)
+	}
+	if pool.AdditionalNodes != 1 || pool.LimitedBy != "pod_subnet" {
+		t.Errorf("Expected 1 more node limited by the pod subnet, got %+v", pool)
+	}
+	if pool.SurgeBlocked {
+		t.Errorf("Expected the default surge of 1 node to fit, got %+v", pool.Findings)
+	}
+	if len(plan.Subnets) != 2 {
+		t.Errorf("Expected node and pod subnet plans, got %+v", plan.Subnets)
+	}
+}
+
+func TestPlanCapacitySharedSubnet(t *testing.T) {
+	plan := PlanCapacity(Input{
+		NetworkPlugin: "azure",
+		NodePools: []NodePool{
+			{Name: "a", Count: 1, MaxCount: 5, MaxPods: 30, SubnetID: testNodeSubnetID},
+			{Name: "b", Count: 1, MaxCount: 5, MaxPods: 30, SubnetID: strings.ToUpper(testNodeSubnetID)},
+		},
+		Subnets: testSubnets("10.0.0.0/24", 62, "", 0),
+	})
+
+	if len(plan.Subnets) != 1 || len(plan.Subnets[0].NodePools) != 2 {
+		t.Fatalf("Expected both pools in one subnet plan, got %+v", plan.Subnets)
+	}
+	if !plan.Findings.Has("shared_subnet_exhausted") {
+		t.Errorf("Expected shared_subnet_exhausted finding, got %+v", plan.Findings)
+	}
+}
+
+func TestPlanCapacityKubenetAndUnknownSubnet(t *testing.T) {
+	plan := PlanCapacity(Input{
+		NetworkPlugin: "kubenet",
+		PodCIDR:       netip.MustParsePrefix("10.240.0.0/12"),
+		NodePools: []NodePool{
+			{Name: "big", Count: 399, MaxCount: 500, SubnetID: testNodeSubnetID},
+			{Name: "lost", Count: 1, SubnetID: testPodSubnetID},
+		},
+		Subnets: testSubnets("10.0.0.0/16", 400, "", 0),
+	})
+
+	if !plan.Findings.Has("kubenet_node_limit") {
+		t.Errorf("Expected kubenet_node_limit finding, got %+v", plan.Findings)
+	}
+	big := plan.NodePools[0]
+	if big.AdditionalNodes != 0 || big.LimitedBy != "kubenet_route_limit" || !big.Findings.Has("no_room_to_scale") ||
+		!big.Findings.Has("surge_blocked_kubenet_route_limit") {
+		t.Errorf("Expected no room limited by the kubenet route limit, got %+v", big)
+	}
+	lost := plan.NodePools[1]
+	if !lost.Findings.Has("node_subnet_unknown") {
+		t.Errorf("Expected node_subnet_unknown finding, got %+v", lost.Findings)
+	}
+}
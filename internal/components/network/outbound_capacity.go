@@ -0,0 +1,604 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/egress"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/components/network/snat"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// snatMetricsWindow is the time range of the SNAT metrics included in the outbound capacity report
+const snatMetricsWindow = time.Hour
+
+// snatUtilizationThreshold is the used to allocated SNAT port ratio above which exhaustion is reported
+const snatUtilizationThreshold = 0.8
+
+// OutboundCapacityResult is the SNAT port capacity report of a cluster
+type OutboundCapacityResult struct {
+	OutboundType        string                       `json:"outbound_type"`
+	CurrentNodes        int                          `json:"current_nodes"`
+	TargetNodes         int                          `json:"target_nodes"`
+	NodePools           []NodePoolNodeCount          `json:"node_pools"`
+	LoadBalancerProfile *LoadBalancerProfileSummary  `json:"load_balancer_profile,omitempty"`
+	LoadBalancerRules   []LoadBalancerRuleCapacity   `json:"load_balancer_outbound_rules,omitempty"`
+	NATGateways         []NATGatewayOutboundCapacity `json:"nat_gateways,omitempty"`
+	Notes               []string                     `json:"notes,omitempty"`
+}
+
+// NodePoolNodeCount is the current and maximum node count of a node pool
+type NodePoolNodeCount struct {
+	NodePool          string `json:"node_pool"`
+	Count             int    `json:"count"`
+	MaxCount          int    `json:"max_count,omitempty"`
+	EnableAutoScaling bool   `json:"enable_auto_scaling"`
+}
+
+// LoadBalancerProfileSummary is the outbound configuration of the cluster load balancer profile
+type LoadBalancerProfileSummary struct {
+	ManagedOutboundIPs     int `json:"managed_outbound_ips,omitempty"`
+	OutboundIPs            int `json:"outbound_ips,omitempty"`
+	OutboundIPPrefixes     int `json:"outbound_ip_prefixes,omitempty"`
+	AllocatedOutboundPorts int `json:"allocated_outbound_ports"`
+	IdleTimeoutMinutes     int `json:"idle_timeout_minutes,omitempty"`
+}
+
+// LoadBalancerRuleCapacity is the SNAT port capacity of a load balancer outbound rule
+type LoadBalancerRuleCapacity struct {
+	LoadBalancer string       `json:"load_balancer"`
+	Rule         string       `json:"rule"`
+	Protocol     string       `json:"protocol,omitempty"`
+	Metrics      *SNATMetrics `json:"metrics,omitempty"`
+	snat.LoadBalancerCapacity
+}
+
+// NATGatewayOutboundCapacity is the SNAT port capacity of a NAT gateway used by node subnets
+type NATGatewayOutboundCapacity struct {
+	ID        string       `json:"id"`
+	NodePools []string     `json:"node_pools"`
+	Metrics   *SNATMetrics `json:"metrics,omitempty"`
+	snat.NATGatewayCapacity
+}
+
+// SNATMetrics summarizes the Azure Monitor SNAT metrics of a load balancer or NAT gateway
+type SNATMetrics struct {
+	Timespan          string   `json:"timespan"`
+	MaxUsedPorts      *float64 `json:"max_used_snat_ports,omitempty"`
+	MaxAllocatedPorts *float64 `json:"max_allocated_snat_ports,omitempty"`
+	FailedConnections *float64 `json:"failed_snat_connections,omitempty"`
+}
+
+// natGatewayUsage groups the node pools whose subnets use the same NAT gateway
+type natGatewayUsage struct {
+	id        string
+	nodePools []string
+	nodes     int
+	target    int
+}
+
+// handleOutboundCapacity reports the SNAT port headroom of the cluster egress and the changes
+// needed to reach a target node count
+func handleOutboundCapacity(ctx context.Context, client *azureclient.AzureClient, monitorEnabled bool, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	targetNodes, err := targetNodeCountParameter(params)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	result := &OutboundCapacityResult{
+		OutboundType:        clusterOutboundType(cluster),
+		NodePools:           clusterNodePoolCounts(cluster),
+		LoadBalancerProfile: loadBalancerProfileSummary(cluster),
+	}
+	for _, pool := range result.NodePools {
+		result.CurrentNodes += pool.Count
+	}
+	result.TargetNodes = targetNodes
+	if result.TargetNodes == 0 {
+		result.TargetNodes = maxNodeCount(result.NodePools)
+	}
+	if !monitorEnabled {
+		result.Notes = append(result.Notes, "SNAT metrics are not included because the monitor component is disabled")
+	}
+
+	switch result.OutboundType {
+	case egress.OutboundTypeLoadBalancer:
+		addLoadBalancerCapacity(ctx, client, cluster, monitorEnabled, result)
+	case egress.OutboundTypeUserDefinedRouting:
+		result.Notes = append(result.Notes, "Outbound traffic is SNATed by the network virtual appliance or firewall of the user defined routes; its SNAT capacity is not analyzed")
+	case egress.OutboundTypeNone, egress.OutboundTypeBlock:
+		result.Notes = append(result.Notes, fmt.Sprintf("Outbound type %s provides no SNAT ports", result.OutboundType))
+	}
+
+	// A NAT gateway on a node subnet takes precedence over the load balancer outbound rules
+	addNATGatewayCapacity(ctx, client, cluster, monitorEnabled, result)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal outbound capacity to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// targetNodeCountParameter parses the optional target node count, returning 0 when it is not set
+func targetNodeCountParameter(params map[string]interface{}) (int, error) {
+	var target int
+	switch value := params["target_node_count"].(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, fmt.Errorf("invalid target_node_count '%v', must be a positive integer", value)
+		}
+		target = int(value)
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return 0, nil
+		}
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("invalid target_node_count '%s', must be a positive integer", value)
+		}
+		target = parsed
+	default:
+		return 0, fmt.Errorf("invalid target_node_count, must be a positive integer")
+	}
+
+	if target <= 0 {
+		return 0, fmt.Errorf("invalid target_node_count '%d', must be a positive integer", target)
+	}
+	return target, nil
+}
+
+// clusterNodePoolCounts returns the node counts of the cluster node pools
+func clusterNodePoolCounts(cluster *armcontainerservice.ManagedCluster) []NodePoolNodeCount {
+	counts := []NodePoolNodeCount{}
+	if cluster.Properties == nil {
+		return counts
+	}
+
+	for _, profile := range cluster.Properties.AgentPoolProfiles {
+		if profile == nil || profile.Name == nil {
+			continue
+		}
+		count := NodePoolNodeCount{NodePool: *profile.Name}
+		if profile.Count != nil {
+			count.Count = int(*profile.Count)
+		}
+		if profile.EnableAutoScaling != nil && *profile.EnableAutoScaling {
+			count.EnableAutoScaling = true
+			if profile.MaxCount != nil {
+				count.MaxCount = int(*profile.MaxCount)
+			}
+		}
+		counts = append(counts, count)
+	}
+	return counts
+}
+
+// maxNodeCount returns the node count of the cluster with every autoscaled pool at its maximum
+func maxNodeCount(pools []NodePoolNodeCount) int {
+	nodes := 0
+	for _, pool := range pools {
+		nodes += max(pool.Count, pool.MaxCount)
+	}
+	return nodes
+}
+
+// loadBalancerProfileSummary returns the outbound settings of the cluster load balancer profile
+func loadBalancerProfileSummary(cluster *armcontainerservice.ManagedCluster) *LoadBalancerProfileSummary {
+	if cluster.Properties == nil || cluster.Properties.NetworkProfile == nil || cluster.Properties.NetworkProfile.LoadBalancerProfile == nil {
+		return nil
+	}
+	profile := cluster.Properties.NetworkProfile.LoadBalancerProfile
+
+	summary := &LoadBalancerProfileSummary{}
+	if profile.ManagedOutboundIPs != nil && profile.ManagedOutboundIPs.Count != nil {
+		summary.ManagedOutboundIPs = int(*profile.ManagedOutboundIPs.Count)
+	}
+	if profile.OutboundIPs != nil {
+		summary.OutboundIPs = len(profile.OutboundIPs.PublicIPs)
+	}
+	if profile.OutboundIPPrefixes != nil {
+		summary.OutboundIPPrefixes = len(profile.OutboundIPPrefixes.PublicIPPrefixes)
+	}
+	if profile.AllocatedOutboundPorts != nil {
+		summary.AllocatedOutboundPorts = int(*profile.AllocatedOutboundPorts)
+	}
+	if profile.IdleTimeoutInMinutes != nil {
+		summary.IdleTimeoutMinutes = int(*profile.IdleTimeoutInMinutes)
+	}
+	return summary
+}
+
+// addLoadBalancerCapacity analyzes the outbound rules of the cluster load balancers
+func addLoadBalancerCapacity(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, monitorEnabled bool, result *OutboundCapacityResult) {
+	lbIDs, err := resourcehelpers.GetLoadBalancerIDsFromAKS(ctx, cluster, client)
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("could not load cluster load balancers: %v", err))
+		return
+	}
+
+	for _, lbID := range lbIDs {
+		parsedID, err := arm.ParseResourceID(lbID)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("load balancer %s: %v", lbID, err))
+			continue
+		}
+		lb, err := client.GetLoadBalancer(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("load balancer %s: %v", lbID, err))
+			continue
+		}
+
+		var metrics *SNATMetrics
+		if monitorEnabled {
+			metrics, err = loadBalancerSNATMetrics(ctx, client, parsedID.SubscriptionID, lbID)
+			if err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("could not read SNAT metrics of load balancer %s: %v", parsedID.Name, err))
+			}
+		}
+
+		rules, _ := outboundRulesFromLoadBalancer(lb)
+		for _, rule := range rules {
+			ips, notes := outboundRuleIPCount(ctx, client, lb, rule.Rule)
+			result.Notes = append(result.Notes, notes...)
+
+			ruleCapacity := LoadBalancerRuleCapacity{
+				LoadBalancer: rule.LoadBalancer,
+				Rule:         rule.Rule,
+				Protocol:     rule.Protocol,
+				Metrics:      metrics,
+				LoadBalancerCapacity: snat.AnalyzeLoadBalancer(snat.LoadBalancerInput{
+					FrontendIPs:    ips,
+					AllocatedPorts: int(rule.AllocatedOutboundPorts),
+					IdleTimeout:    int(rule.IdleTimeoutMinutes),
+					CurrentNodes:   result.CurrentNodes,
+					TargetNodes:    result.TargetNodes,
+				}),
+			}
+			ruleCapacity.Findings = append(ruleCapacity.Findings, snatMetricFindings(metrics)...)
+			result.LoadBalancerRules = append(result.LoadBalancerRules, ruleCapacity)
+		}
+	}
+
+	if len(result.LoadBalancerRules) == 0 {
+		result.Notes = append(result.Notes, "no load balancer outbound rules found for the loadBalancer outbound type")
+	}
+	if profile := result.LoadBalancerProfile; profile != nil && (profile.OutboundIPs > 0 || profile.OutboundIPPrefixes > 0) {
+		result.Notes = append(result.Notes, "The cluster uses its own outbound IPs or prefixes; add IPs with --load-balancer-outbound-ips or --load-balancer-outbound-ip-prefixes instead of the managed outbound IP count")
+	}
+}
+
+// outboundRuleIPCount counts the public IPs of the frontends of a load balancer outbound rule,
+// expanding public IP prefixes to the number of addresses they contain
+func outboundRuleIPCount(ctx context.Context, client *azureclient.AzureClient, lb *armnetwork.LoadBalancer, ruleName string) (int, []string) {
+	frontends := map[string]*armnetwork.FrontendIPConfiguration{}
+	for _, frontend := range lb.Properties.FrontendIPConfigurations {
+		if frontend != nil && frontend.ID != nil {
+			frontends[strings.ToLower(*frontend.ID)] = frontend
+		}
+	}
+
+	var rule *armnetwork.OutboundRule
+	for _, candidate := range lb.Properties.OutboundRules {
+		if candidate != nil && candidate.Name != nil && *candidate.Name == ruleName {
+			rule = candidate
+		}
+	}
+	if rule == nil || rule.Properties == nil {
+		return 0, nil
+	}
+
+	ips := 0
+	var notes []string
+	for _, ref := range rule.Properties.FrontendIPConfigurations {
+		if ref == nil || ref.ID == nil {
+			continue
+		}
+		frontend, ok := frontends[strings.ToLower(*ref.ID)]
+		if !ok || frontend.Properties == nil {
+			// Frontends that are not expanded in the load balancer are counted as a single IP
+			ips++
+			continue
+		}
+		switch {
+		case frontend.Properties.PublicIPAddress != nil:
+			ips++
+		case frontend.Properties.PublicIPPrefix != nil && frontend.Properties.PublicIPPrefix.ID != nil:
+			size, err := publicIPPrefixSize(ctx, client, *frontend.Properties.PublicIPPrefix.ID)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("public IP prefix %s: %v", *frontend.Properties.PublicIPPrefix.ID, err))
+				size = 1
+			}
+			ips += size
+		}
+	}
+	return ips, notes
+}
+
+// publicIPPrefixSize returns the number of addresses of a public IP prefix
+func publicIPPrefixSize(ctx context.Context, client *azureclient.AzureClient, prefixID string) (int, error) {
+	parsedID, err := arm.ParseResourceID(prefixID)
+	if err != nil {
+		return 0, err
+	}
+	prefix, err := client.GetPublicIPPrefix(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+	if err != nil {
+		return 0, err
+	}
+	if prefix.Properties == nil || prefix.Properties.PrefixLength == nil {
+		return 0, fmt.Errorf("prefix length is not set")
+	}
+	return prefixAddressCount(int(*prefix.Properties.PrefixLength)), nil
+}
+
+// prefixAddressCount returns the number of IPv4 addresses of a prefix length
+func prefixAddressCount(length int) int {
+	if length < 0 || length > 32 {
+		return 0
+	}
+	return 1 << (32 - length)
+}
+
+// addNATGatewayCapacity analyzes the NAT gateways associated with the node subnets
+func addNATGatewayCapacity(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, monitorEnabled bool, result *OutboundCapacityResult) {
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, "")
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("could not resolve node pool networks: %v", err))
+		return
+	}
+
+	usages := natGatewayUsages(ctx, client, networks, result)
+	for _, usage := range usages {
+		capacity := NATGatewayOutboundCapacity{ID: usage.id, NodePools: usage.nodePools}
+
+		publicIPs, idleTimeout, err := natGatewayIPCount(ctx, client, usage.id)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("NAT gateway %s: %v", usage.id, err))
+			continue
+		}
+		if monitorEnabled {
+			capacity.Metrics, err = natGatewaySNATMetrics(ctx, client, usage.id)
+			if err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("could not read SNAT metrics of NAT gateway %s: %v", usage.id, err))
+			}
+		}
+
+		capacity.NATGatewayCapacity = snat.AnalyzeNATGateway(snat.NATGatewayInput{
+			PublicIPs:    publicIPs,
+			IdleTimeout:  idleTimeout,
+			CurrentNodes: usage.nodes,
+			TargetNodes:  usage.target,
+		})
+		capacity.Findings = append(capacity.Findings, snatMetricFindings(capacity.Metrics)...)
+		result.NATGateways = append(result.NATGateways, capacity)
+	}
+}
+
+// natGatewayUsages groups node pools by the NAT gateway of their subnet. The target node count is
+// split across NAT gateways in proportion to their current share of the nodes.
+func natGatewayUsages(ctx context.Context, client *azureclient.AzureClient, networks []resourcehelpers.NodePoolNetwork, result *OutboundCapacityResult) []*natGatewayUsage {
+	counts := map[string]NodePoolNodeCount{}
+	for _, pool := range result.NodePools {
+		counts[pool.NodePool] = pool
+	}
+
+	var usages []*natGatewayUsage
+	byID := map[string]*natGatewayUsage{}
+	for _, network := range networks {
+		if network.SubnetID == "" {
+			continue
+		}
+		subnet, err := resourcehelpers.GetSubnetByID(ctx, client, network.SubnetID)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("node pool %s: %v", network.NodePool, err))
+			continue
+		}
+		if subnet.Properties == nil || subnet.Properties.NatGateway == nil || subnet.Properties.NatGateway.ID == nil {
+			continue
+		}
+
+		natGatewayID := *subnet.Properties.NatGateway.ID
+		usage, ok := byID[strings.ToLower(natGatewayID)]
+		if !ok {
+			usage = &natGatewayUsage{id: natGatewayID}
+			byID[strings.ToLower(natGatewayID)] = usage
+			usages = append(usages, usage)
+		}
+		pool := counts[network.NodePool]
+		usage.nodePools = append(usage.nodePools, network.NodePool)
+		usage.nodes += pool.Count
+		usage.target += max(pool.Count, pool.MaxCount)
+	}
+
+	maxNodes := maxNodeCount(result.NodePools)
+	if maxNodes > 0 && result.TargetNodes != maxNodes {
+		for _, usage := range usages {
+			usage.target = int(math.Ceil(float64(result.TargetNodes) * float64(usage.target) / float64(maxNodes)))
+		}
+	}
+	return usages
+}
+
+// natGatewayIPCount returns the number of public IPs of a NAT gateway, including the addresses
+// of its public IP prefixes, and its idle timeout
+func natGatewayIPCount(ctx context.Context, client *azureclient.AzureClient, natGatewayID string) (int, int, error) {
+	parsedID, err := arm.ParseResourceID(natGatewayID)
+	if err != nil {
+		return 0, 0, err
+	}
+	natGateway, err := client.GetNatGateway(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+	if err != nil {
+		return 0, 0, err
+	}
+	if natGateway.Properties == nil {
+		return 0, 0, nil
+	}
+
+	ips := len(natGateway.Properties.PublicIPAddresses)
+	for _, prefix := range natGateway.Properties.PublicIPPrefixes {
+		if prefix == nil || prefix.ID == nil {
+			continue
+		}
+		size, err := publicIPPrefixSize(ctx, client, *prefix.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("public IP prefix %s: %v", *prefix.ID, err)
+		}
+		ips += size
+	}
+
+	idleTimeout := 0
+	if natGateway.Properties.IdleTimeoutInMinutes != nil {
+		idleTimeout = int(*natGateway.Properties.IdleTimeoutInMinutes)
+	}
+	return ips, idleTimeout, nil
+}
+
+// loadBalancerSNATMetrics reads the peak used and allocated SNAT ports and the failed SNAT
+// connections of a load balancer over the metrics window
+func loadBalancerSNATMetrics(ctx context.Context, client *azureclient.AzureClient, subscriptionID, lbID string) (*SNATMetrics, error) {
+	timespan := snatMetricsTimespan(time.Now().UTC())
+	ports, err := client.ListMetrics(ctx, subscriptionID, lbID, &armmonitor.MetricsClientListOptions{
+		Metricnames: to.Ptr("UsedSnatPorts,AllocatedSnatPorts"),
+		Aggregation: to.Ptr("Maximum"),
+		Timespan:    to.Ptr(timespan),
+	})
+	if err != nil {
+		return nil, err
+	}
+	failed, err := client.ListMetrics(ctx, subscriptionID, lbID, &armmonitor.MetricsClientListOptions{
+		Metricnames: to.Ptr("SnatConnectionCount"),
+		Aggregation: to.Ptr("Total"),
+		Filter:      to.Ptr("ConnectionState eq 'failed'"),
+		Timespan:    to.Ptr(timespan),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SNATMetrics{
+		Timespan:          timespan,
+		MaxUsedPorts:      metricMaximum(ports, "UsedSnatPorts"),
+		MaxAllocatedPorts: metricMaximum(ports, "AllocatedSnatPorts"),
+		FailedConnections: metricTotal(failed, "SnatConnectionCount"),
+	}, nil
+}
+
+// natGatewaySNATMetrics reads the failed SNAT connections of a NAT gateway over the metrics window
+func natGatewaySNATMetrics(ctx context.Context, client *azureclient.AzureClient, natGatewayID string) (*SNATMetrics, error) {
+	parsedID, err := arm.ParseResourceID(natGatewayID)
+	if err != nil {
+		return nil, err
+	}
+
+	timespan := snatMetricsTimespan(time.Now().UTC())
+	failed, err := client.ListMetrics(ctx, parsedID.SubscriptionID, natGatewayID, &armmonitor.MetricsClientListOptions{
+		Metricnames: to.Ptr("SNATConnectionCount"),
+		Aggregation: to.Ptr("Total"),
+		Filter:      to.Ptr("ConnectionState eq 'Failed'"),
+		Timespan:    to.Ptr(timespan),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SNATMetrics{
+		Timespan:          timespan,
+		FailedConnections: metricTotal(failed, "SNATConnectionCount"),
+	}, nil
+}
+
+// snatMetricsTimespan returns the ISO 8601 timespan of the metrics window ending at now
+func snatMetricsTimespan(now time.Time) string {
+	return fmt.Sprintf("%s/%s", now.Add(-snatMetricsWindow).Format(time.RFC3339), now.Format(time.RFC3339))
+}
+
+// metricMaximum returns the largest Maximum data point of a metric, or nil if it has no data
+func metricMaximum(response *armmonitor.Response, name string) *float64 {
+	var peak *float64
+	for _, value := range metricValues(response, name) {
+		if value.Maximum != nil && (peak == nil || *value.Maximum > *peak) {
+			peak = to.Ptr(*value.Maximum)
+		}
+	}
+	return peak
+}
+
+// metricTotal returns the sum of the Total data points of a metric, or nil if it has no data
+func metricTotal(response *armmonitor.Response, name string) *float64 {
+	var total *float64
+	for _, value := range metricValues(response, name) {
+		if value.Total != nil {
+			if total == nil {
+				total = to.Ptr(0.0)
+			}
+			*total += *value.Total
+		}
+	}
+	return total
+}
+
+// metricValues returns the data points of all time series of a metric
+func metricValues(response *armmonitor.Response, name string) []*armmonitor.MetricValue {
+	if response == nil {
+		return nil
+	}
+
+	var values []*armmonitor.MetricValue
+	for _, metric := range response.Value {
+		if metric == nil || metric.Name == nil || metric.Name.Value == nil || !strings.EqualFold(*metric.Name.Value, name) {
+			continue
+		}
+		for _, series := range metric.Timeseries {
+			if series == nil {
+				continue
+			}
+			for _, value := range series.Data {
+				if value != nil {
+					values = append(values, value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// snatMetricFindings reports failed SNAT connections and high SNAT port utilization
+func snatMetricFindings(metrics *SNATMetrics) common.Findings {
+	if metrics == nil {
+		return nil
+	}
+
+	var findings common.Findings
+	if metrics.FailedConnections != nil && *metrics.FailedConnections > 0 {
+		findings.Add(common.SeverityError, "snat_connection_failures",
+			fmt.Sprintf("%.0f SNAT connections failed in the last hour", *metrics.FailedConnections))
+	}
+	if metrics.MaxUsedPorts != nil && metrics.MaxAllocatedPorts != nil && *metrics.MaxAllocatedPorts > 0 {
+		utilization := *metrics.MaxUsedPorts / *metrics.MaxAllocatedPorts
+		if utilization >= snatUtilizationThreshold {
+			findings.Add(common.SeverityWarning, "snat_port_utilization_high",
+				fmt.Sprintf("Peak SNAT port usage reached %.0f%% of the allocated ports in the last hour", utilization*100))
+		}
+	}
+	return findings
+}
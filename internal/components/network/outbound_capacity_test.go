@@ -0,0 +1,192 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func TestTargetNodeCountParameter(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       interface{}
+		expected    int
+		expectError bool
+	}{
+		{"not set", nil, 0, false},
+		{"number", float64(200), 200, false},
+		{"string", " 150 ", 150, false},
+		{"empty string", "", 0, false},
+		{"fraction", 1.5, 0, true},
+		{"zero", float64(0), 0, true},
+		{"not a number", "many", 0, true},
+		{"wrong type", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{}
+			if tt.value != nil {
+				params["target_node_count"] = tt.value
+			}
+			got, err := targetNodeCountParameter(params)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %v", tt.value)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("Expected %d, got %d err=%v", tt.expected, got, err)
+			}
+		})
+	}
+}
+
+func TestClusterNodePoolCounts(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("system"), Count: to.Ptr[int32](3)},
+				{Name: to.Ptr("user"), Count: to.Ptr[int32](5), EnableAutoScaling: to.Ptr(true), MaxCount: to.Ptr[int32](20)},
+				{Name: to.Ptr("disabled"), Count: to.Ptr[int32](2), EnableAutoScaling: to.Ptr(false), MaxCount: to.Ptr[int32](10)},
+			},
+		},
+	}
+
+	pools := clusterNodePoolCounts(cluster)
+	if len(pools) != 3 || !pools[1].EnableAutoScaling || pools[1].MaxCount != 20 || pools[2].MaxCount != 0 {
+		t.Errorf("Unexpected node pool counts: %+v", pools)
+	}
+	if got := maxNodeCount(pools); got != 25 {
+		t.Errorf("Expected 25 nodes at the autoscaler maximum, got %d", got)
+	}
+}
+
+func TestLoadBalancerProfileSummary(t *testing.T) {
+	if loadBalancerProfileSummary(&armcontainerservice.ManagedCluster{}) != nil {
+		t.Error("Expected no summary without a load balancer profile")
+	}
+
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			NetworkProfile: &armcontainerservice.NetworkProfile{
+				LoadBalancerProfile: &armcontainerservice.ManagedClusterLoadBalancerProfile{
+					ManagedOutboundIPs:     &armcontainerservice.ManagedClusterLoadBalancerProfileManagedOutboundIPs{Count: to.Ptr[int32](2)},
+					AllocatedOutboundPorts: to.Ptr[int32](4000),
+					IdleTimeoutInMinutes:   to.Ptr[int32](30),
+				},
+			},
+		},
+	}
+	summary := loadBalancerProfileSummary(cluster)
+	if summary == nil || summary.ManagedOutboundIPs != 2 || summary.AllocatedOutboundPorts != 4000 || summary.IdleTimeoutMinutes != 30 {
+		t.Errorf("Unexpected load balancer profile summary: %+v", summary)
+	}
+}
+
+func TestOutboundRuleIPCount(t *testing.T) {
+	const lbID = "/subscriptions/sub/resourceGroups/mc_rg/providers/Microsoft.Network/loadBalancers/kubernetes"
+	lb := &armnetwork.LoadBalancer{
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{ID: to.Ptr(lbID + "/frontendIPConfigurations/ip1"), Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+					PublicIPAddress: &armnetwork.PublicIPAddress{ID: to.Ptr("pip1")},
+				}},
+				{ID: to.Ptr(lbID + "/frontendIPConfigurations/ip2"), Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+					PublicIPAddress: &armnetwork.PublicIPAddress{ID: to.Ptr("pip2")},
+				}},
+			},
+			OutboundRules: []*armnetwork.OutboundRule{
+				{Name: to.Ptr("aksOutboundRule"), Properties: &armnetwork.OutboundRulePropertiesFormat{
+					FrontendIPConfigurations: []*armnetwork.SubResource{
+						{ID: to.Ptr(lbID + "/frontendIPConfigurations/IP1")},
+						{ID: to.Ptr(lbID + "/frontendIPConfigurations/ip2")},
+					},
+				}},
+			},
+		},
+	}
+
+	ips, notes := outboundRuleIPCount(context.Background(), nil, lb, "aksOutboundRule")
+	if ips != 2 || len(notes) != 0 {
+		t.Errorf("Expected 2 IPs without notes, got %d %v", ips, notes)
+	}
+	if ips, _ := outboundRuleIPCount(context.Background(), nil, lb, "missing"); ips != 0 {
+		t.Errorf("Expected 0 IPs for an unknown rule, got %d", ips)
+	}
+}
+
+func TestPrefixAddressCount(t *testing.T) {
+	if prefixAddressCount(28) != 16 || prefixAddressCount(31) != 2 || prefixAddressCount(40) != 0 {
+		t.Error("Unexpected prefix address counts")
+	}
+}
+
+func TestSNATMetricsSummary(t *testing.T) {
+	response := &armmonitor.Response{
+		Value: []*armmonitor.Metric{
+			{
+				Name: &armmonitor.LocalizableString{Value: to.Ptr("UsedSnatPorts")},
+				Timeseries: []*armmonitor.TimeSeriesElement{
+					{Data: []*armmonitor.MetricValue{{Maximum: to.Ptr(100.0)}, {Maximum: to.Ptr(900.0)}, {}}},
+				},
+			},
+			{
+				Name: &armmonitor.LocalizableString{Value: to.Ptr("SnatConnectionCount")},
+				Timeseries: []*armmonitor.TimeSeriesElement{
+					{Data: []*armmonitor.MetricValue{{Total: to.Ptr(3.0)}, {Total: to.Ptr(4.0)}}},
+				},
+			},
+		},
+	}
+
+	if used := metricMaximum(response, "usedsnatports"); used == nil || *used != 900 {
+		t.Errorf("Expected peak of 900 used ports, got %v", used)
+	}
+	if failed := metricTotal(response, "SnatConnectionCount"); failed == nil || *failed != 7 {
+		t.Errorf("Expected 7 failed connections, got %v", failed)
+	}
+	if metricMaximum(response, "AllocatedSnatPorts") != nil || metricTotal(nil, "SnatConnectionCount") != nil {
+		t.Error("Expected nil for metrics without data")
+	}
+
+	findings := snatMetricFindings(&SNATMetrics{
+		MaxUsedPorts:      to.Ptr(900.0),
+		MaxAllocatedPorts: to.Ptr(1024.0),
+		FailedConnections: to.Ptr(7.0),
+	})
+	if len(findings) != 2 || findings[0].Code != "snat_connection_failures" || findings[1].Code != "snat_port_utilization_high" {
+		t.Errorf("Expected failure and utilization findings, got %+v", findings)
+	}
+	if findings[0].Severity != common.SeverityError {
+		t.Errorf("Expected failed connections to be an error, got %s", findings[0].Severity)
+	}
+	if snatMetricFindings(&SNATMetrics{FailedConnections: to.Ptr(0.0)}) != nil {
+		t.Error("Expected no findings for healthy metrics")
+	}
+}
+
+func TestOutboundCapacityInvalidTarget(t *testing.T) {
+	cfg := &config.ConfigData{}
+	handler := GetAksNetworkDiagnosticsHandler(nil, cfg)
+
+	params := map[string]interface{}{
+		"operation":       string(OpOutboundCapacity),
+		"subscription_id": "sub-123",
+		"resource_group":  "rg-test",
+		"cluster_name":    "cluster",
+		"parameters":      `{"target_node_count": -5}`,
+	}
+	_, err := handler.Handle(context.Background(), params, cfg)
+	if err == nil || !strings.Contains(err.Error(), "invalid target_node_count") {
+		t.Errorf("Expected invalid target_node_count error, got %v", err)
+	}
+}
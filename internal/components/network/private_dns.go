@@ -0,0 +1,394 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/apiserverdns"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+)
+
+// apiServerLoadBalancerName is the internal load balancer AKS creates for API server VNet integration
+const apiServerLoadBalancerName = "kube-apiserver"
+
+// PrivateDNSResult is the result of the private cluster DNS diagnostic
+type PrivateDNSResult struct {
+	apiserverdns.Result
+	PrivateEndpointID string   `json:"private_endpoint_id,omitempty"`
+	Notes             []string `json:"notes,omitempty"`
+}
+
+// handlePrivateDNS checks that the private FQDN of a private cluster resolves to the API server
+// private endpoint or VNet integration load balancer from the node VNets and an optional client VNet
+func handlePrivateDNS(ctx context.Context, client *azureclient.AzureClient, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	clientVNetID, err := parseClientVNetID(params)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+	if !isPrivateCluster(cluster) {
+		return "", fmt.Errorf("cluster %s is not a private cluster; its API server is reached through the public FQDN", clusterName)
+	}
+
+	result := &PrivateDNSResult{}
+	input := apiserverdns.Input{}
+	if cluster.Properties.PrivateFQDN != nil {
+		input.FQDN = *cluster.Properties.PrivateFQDN
+	}
+
+	input.Access, input.ExpectedIPs, result.PrivateEndpointID, result.Notes = resolveAPIServerPrivateAddresses(ctx, client, cluster, subID)
+
+	zoneSubID, zoneRG := "", ""
+	input.ZoneMode, input.Zone, zoneSubID, zoneRG, err = clusterPrivateDNSZone(cluster, subID)
+	if err != nil {
+		return "", err
+	}
+
+	if input.ZoneMode == apiserverdns.ZoneModeNone {
+		input.PublicRecords, err = lookupIPv4(ctx, input.FQDN)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("could not resolve %s in public DNS: %v", input.FQDN, err))
+		}
+	} else if input.FQDN != "" {
+		notes := loadPrivateDNSZoneRecords(ctx, client, zoneSubID, zoneRG, &input)
+		result.Notes = append(result.Notes, notes...)
+	}
+
+	vnets, notes := privateDNSVirtualNetworks(ctx, client, cluster, clientVNetID)
+	input.VirtualNetworks = vnets
+	result.Notes = append(result.Notes, notes...)
+
+	result.Result = apiserverdns.Analyze(input)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private DNS diagnostics to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// parseClientVNetID validates the optional client_vnet_id parameter
+func parseClientVNetID(params map[string]interface{}) (string, error) {
+	value, _ := params["client_vnet_id"].(string)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	parsed, err := arm.ParseResourceID(value)
+	if err != nil || !strings.EqualFold(parsed.ResourceType.String(), "Microsoft.Network/virtualNetworks") {
+		return "", fmt.Errorf("invalid client_vnet_id '%s', must be a virtual network resource ID", value)
+	}
+	return value, nil
+}
+
+// isPrivateCluster reports whether the API server of the cluster is private
+func isPrivateCluster(cluster *armcontainerservice.ManagedCluster) bool {
+	if cluster.Properties == nil || cluster.Properties.APIServerAccessProfile == nil {
+		return false
+	}
+	private := cluster.Properties.APIServerAccessProfile.EnablePrivateCluster
+	return private != nil && *private
+}
+
+// clusterPrivateDNSZone returns the private DNS zone mode of a private cluster and the name,
+// subscription and resource group of its zone. The system zone lives in the node resource group.
+func clusterPrivateDNSZone(cluster *armcontainerservice.ManagedCluster, subID string) (string, string, string, string, error) {
+	zoneSetting := ""
+	if access := cluster.Properties.APIServerAccessProfile; access != nil && access.PrivateDNSZone != nil {
+		zoneSetting = strings.TrimSpace(*access.PrivateDNSZone)
+	}
+
+	switch strings.ToLower(zoneSetting) {
+	case "", apiserverdns.ZoneModeSystem:
+		nodeRG := ""
+		if cluster.Properties.NodeResourceGroup != nil {
+			nodeRG = *cluster.Properties.NodeResourceGroup
+		}
+		fqdn := ""
+		if cluster.Properties.PrivateFQDN != nil {
+			fqdn = *cluster.Properties.PrivateFQDN
+		}
+		return apiserverdns.ZoneModeSystem, apiserverdns.SystemZoneName(fqdn), subID, nodeRG, nil
+	case apiserverdns.ZoneModeNone:
+		return apiserverdns.ZoneModeNone, "", "", "", nil
+	}
+
+	parsed, err := arm.ParseResourceID(zoneSetting)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to parse private DNS zone ID %s: %v", zoneSetting, err)
+	}
+	return apiserverdns.ZoneModeCustom, parsed.Name, parsed.SubscriptionID, parsed.ResourceGroupName, nil
+}
+
+// resolveAPIServerPrivateAddresses returns how the API server is exposed in the VNet and its
+// addresses: the NIC IPs of the private endpoint, or the frontend of the API server load balancer
+// for VNet integration clusters, which have no private endpoint
+func resolveAPIServerPrivateAddresses(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, subID string) (string, []netip.Addr, string, []string) {
+	var notes []string
+
+	privateEndpointID, err := resourcehelpers.GetPrivateEndpointIDFromAKS(ctx, cluster, client)
+	if err != nil {
+		notes = append(notes, fmt.Sprintf("could not find the API server private endpoint: %v", err))
+	}
+	if privateEndpointID != "" {
+		addresses, peNotes := privateEndpointNICAddresses(ctx, client, privateEndpointID)
+		return apiserverdns.AccessPrivateEndpoint, addresses, privateEndpointID, append(notes, peNotes...)
+	}
+
+	// The API version in use does not expose enableVnetIntegration, so VNet integration is
+	// detected from the API server load balancer in the node resource group
+	if cluster.Properties.NodeResourceGroup != nil {
+		lb, err := client.GetLoadBalancer(ctx, subID, *cluster.Properties.NodeResourceGroup, apiServerLoadBalancerName)
+		if err == nil {
+			return apiserverdns.AccessVNetIntegration, loadBalancerFrontendAddresses(lb), "", notes
+		}
+		notes = append(notes, fmt.Sprintf("no private endpoint or %s load balancer found: %v", apiServerLoadBalancerName, err))
+	}
+	return apiserverdns.AccessPrivateEndpoint, nil, "", notes
+}
+
+// privateEndpointNICAddresses returns the private IPs of the network interfaces of a private
+// endpoint, falling back to its custom DNS configs when the interfaces cannot be read
+func privateEndpointNICAddresses(ctx context.Context, client *azureclient.AzureClient, privateEndpointID string) ([]netip.Addr, []string) {
+	privateEndpoint, err := client.GetPrivateEndpointByID(ctx, privateEndpointID)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("private endpoint %s: %v", privateEndpointID, err)}
+	}
+	if privateEndpoint.Properties == nil {
+		return nil, []string{fmt.Sprintf("private endpoint %s has no properties", privateEndpointID)}
+	}
+
+	var addresses []netip.Addr
+	var notes []string
+	for _, nicRef := range privateEndpoint.Properties.NetworkInterfaces {
+		if nicRef == nil || nicRef.ID == nil {
+			continue
+		}
+		nicAddresses, err := networkInterfaceAddresses(ctx, client, *nicRef.ID)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("network interface %s: %v", *nicRef.ID, err))
+			continue
+		}
+		addresses = append(addresses, nicAddresses...)
+	}
+	if len(addresses) > 0 {
+		return addresses, notes
+	}
+
+	for _, dnsConfig := range privateEndpoint.Properties.CustomDNSConfigs {
+		if dnsConfig == nil {
+			continue
+		}
+		for _, value := range dnsConfig.IPAddresses {
+			if value == nil {
+				continue
+			}
+			if address, err := netip.ParseAddr(*value); err == nil {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses, notes
+}
+
+// networkInterfaceAddresses returns the private IPs of a network interface
+func networkInterfaceAddresses(ctx context.Context, client *azureclient.AzureClient, nicID string) ([]netip.Addr, error) {
+	parsed, err := arm.ParseResourceID(nicID)
+	if err != nil {
+		return nil, err
+	}
+	nic, err := client.GetNetworkInterface(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	if err != nil {
+		return nil, err
+	}
+	if nic.Properties == nil {
+		return nil, nil
+	}
+
+	var addresses []netip.Addr
+	for _, ipConfig := range nic.Properties.IPConfigurations {
+		if ipConfig == nil || ipConfig.Properties == nil || ipConfig.Properties.PrivateIPAddress == nil {
+			continue
+		}
+		if address, err := netip.ParseAddr(*ipConfig.Properties.PrivateIPAddress); err == nil {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// loadBalancerFrontendAddresses returns the private frontend IPs of a load balancer
+func loadBalancerFrontendAddresses(lb *armnetwork.LoadBalancer) []netip.Addr {
+	if lb == nil || lb.Properties == nil {
+		return nil
+	}
+
+	var addresses []netip.Addr
+	for _, frontend := range lb.Properties.FrontendIPConfigurations {
+		if frontend == nil || frontend.Properties == nil || frontend.Properties.PrivateIPAddress == nil {
+			continue
+		}
+		if address, err := netip.ParseAddr(*frontend.Properties.PrivateIPAddress); err == nil {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// loadPrivateDNSZoneRecords reads the A record of the FQDN and the virtual network links of the zone
+func loadPrivateDNSZoneRecords(ctx context.Context, client *azureclient.AzureClient, zoneSubID, zoneRG string, input *apiserverdns.Input) []string {
+	var notes []string
+
+	relativeName, ok := apiserverdns.RelativeRecordName(input.FQDN, input.Zone)
+	if !ok {
+		notes = append(notes, fmt.Sprintf("the private FQDN %s is not in the private DNS zone %s", input.FQDN, input.Zone))
+	} else {
+		recordSet, err := client.GetPrivateDNSRecordSet(ctx, zoneSubID, zoneRG, input.Zone, armprivatedns.RecordTypeA, relativeName)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("could not read the A record %s: %v", relativeName, err))
+		} else {
+			input.RecordFound = true
+			input.Records = recordSetAddresses(recordSet)
+		}
+	}
+
+	links, err := client.ListPrivateDNSZoneLinks(ctx, zoneSubID, zoneRG, input.Zone)
+	if err != nil {
+		return append(notes, fmt.Sprintf("could not list the links of the private DNS zone %s: %v", input.Zone, err))
+	}
+	input.Links = privateDNSZoneLinks(links)
+	return notes
+}
+
+// recordSetAddresses returns the IPv4 addresses of an A record set
+func recordSetAddresses(recordSet *armprivatedns.RecordSet) []netip.Addr {
+	if recordSet == nil || recordSet.Properties == nil {
+		return nil
+	}
+
+	var addresses []netip.Addr
+	for _, record := range recordSet.Properties.ARecords {
+		if record == nil || record.IPv4Address == nil {
+			continue
+		}
+		if address, err := netip.ParseAddr(*record.IPv4Address); err == nil {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// privateDNSZoneLinks converts the virtual network links of a private DNS zone
+func privateDNSZoneLinks(links []*armprivatedns.VirtualNetworkLink) []apiserverdns.Link {
+	var result []apiserverdns.Link
+	for _, link := range links {
+		if link == nil || link.Properties == nil || link.Properties.VirtualNetwork == nil || link.Properties.VirtualNetwork.ID == nil {
+			continue
+		}
+		converted := apiserverdns.Link{VirtualNetworkID: *link.Properties.VirtualNetwork.ID}
+		if link.Name != nil {
+			converted.Name = *link.Name
+		}
+		if link.Properties.VirtualNetworkLinkState != nil {
+			converted.State = string(*link.Properties.VirtualNetworkLinkState)
+		}
+		if link.Properties.RegistrationEnabled != nil {
+			converted.RegistrationEnabled = *link.Properties.RegistrationEnabled
+		}
+		result = append(result, converted)
+	}
+	return result
+}
+
+// privateDNSVirtualNetworks returns the node VNets of the cluster and the optional client VNet with
+// their DNS servers
+func privateDNSVirtualNetworks(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, clientVNetID string) ([]apiserverdns.VirtualNetwork, []string) {
+	var notes []string
+	var vnetIDs []string
+	seen := map[string]bool{}
+
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, "")
+	if err != nil {
+		notes = append(notes, fmt.Sprintf("could not resolve node pool networks: %v", err))
+	}
+	for _, network := range networks {
+		parsed, err := arm.ParseResourceID(network.SubnetID)
+		if err != nil || parsed.Parent == nil {
+			continue
+		}
+		vnetID := parsed.Parent.String()
+		if !seen[strings.ToLower(vnetID)] {
+			seen[strings.ToLower(vnetID)] = true
+			vnetIDs = append(vnetIDs, vnetID)
+		}
+	}
+
+	var vnets []apiserverdns.VirtualNetwork
+	for _, vnetID := range vnetIDs {
+		vnet, err := loadDNSVirtualNetwork(ctx, client, vnetID, apiserverdns.RoleNode)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("virtual network %s: %v", vnetID, err))
+		}
+		vnets = append(vnets, vnet)
+	}
+	if clientVNetID != "" {
+		vnet, err := loadDNSVirtualNetwork(ctx, client, clientVNetID, apiserverdns.RoleClient)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("virtual network %s: %v", clientVNetID, err))
+		}
+		vnets = append(vnets, vnet)
+	}
+	return vnets, notes
+}
+
+// loadDNSVirtualNetwork reads the DNS servers of a virtual network. The VNet is returned even when
+// it cannot be read so that its zone link is still checked.
+func loadDNSVirtualNetwork(ctx context.Context, client *azureclient.AzureClient, vnetID, role string) (apiserverdns.VirtualNetwork, error) {
+	result := apiserverdns.VirtualNetwork{ID: vnetID, Role: role}
+
+	parsed, err := arm.ParseResourceID(vnetID)
+	if err != nil {
+		return result, err
+	}
+	vnet, err := client.GetVirtualNetwork(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+	if err != nil {
+		return result, err
+	}
+	if vnet.Properties != nil && vnet.Properties.DhcpOptions != nil {
+		for _, server := range vnet.Properties.DhcpOptions.DNSServers {
+			if server != nil {
+				result.DNSServers = append(result.DNSServers, *server)
+			}
+		}
+	}
+	return result, nil
+}
+
+// lookupIPv4 resolves the IPv4 addresses of a host name
+func lookupIPv4(ctx context.Context, host string) ([]netip.Addr, error) {
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", host)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, ip.Unmap())
+	}
+	return addresses, nil
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/apiserverdns"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+)
+
+func privateCluster(privateDNSZone *string) *armcontainerservice.ManagedCluster {
+	return &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			PrivateFQDN:       to.Ptr("aks-abc123.0f1e2d3c.privatelink.eastus.azmk8s.io"),
+			NodeResourceGroup: to.Ptr("MC_rg_aks_eastus"),
+			APIServerAccessProfile: &armcontainerservice.ManagedClusterAPIServerAccessProfile{
+				EnablePrivateCluster: to.Ptr(true),
+				PrivateDNSZone:       privateDNSZone,
+			},
+		},
+	}
+}
+
+func TestClusterPrivateDNSZone(t *testing.T) {
+	mode, zone, sub, rg, err := clusterPrivateDNSZone(privateCluster(nil), "sub")
+	if err != nil || mode != apiserverdns.ZoneModeSystem || zone != "0f1e2d3c.privatelink.eastus.azmk8s.io" || sub != "sub" || rg != "MC_rg_aks_eastus" {
+		t.Errorf("Expected the system zone in the node resource group, got %s %s %s %s %v", mode, zone, sub, rg, err)
+	}
+
+	customZoneID := "/subscriptions/dns-sub/resourceGroups/dns-rg/providers/Microsoft.Network/privateDnsZones/privatelink.eastus.azmk8s.io"
+	mode, zone, sub, rg, err = clusterPrivateDNSZone(privateCluster(to.Ptr(customZoneID)), "sub")
+	if err != nil || mode != apiserverdns.ZoneModeCustom || zone != "privatelink.eastus.azmk8s.io" || sub != "dns-sub" || rg != "dns-rg" {
+		t.Errorf("Expected the custom zone, got %s %s %s %s %v", mode, zone, sub, rg, err)
+	}
+
+	if mode, _, _, _, _ := clusterPrivateDNSZone(privateCluster(to.Ptr("None")), "sub"); mode != apiserverdns.ZoneModeNone {
+		t.Errorf("Expected zone mode none, got %s", mode)
+	}
+	if _, _, _, _, err := clusterPrivateDNSZone(privateCluster(to.Ptr("not-a-resource-id")), "sub"); err == nil {
+		t.Error("Expected error for an invalid private DNS zone ID")
+	}
+}
+
+func TestParseClientVNetID(t *testing.T) {
+	vnetID := "/subscriptions/sub/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet"
+	if value, err := parseClientVNetID(map[string]interface{}{"client_vnet_id": vnetID}); err != nil || value != vnetID {
+		t.Errorf("Expected %s, got %s %v", vnetID, value, err)
+	}
+	if value, err := parseClientVNetID(map[string]interface{}{}); err != nil || value != "" {
+		t.Errorf("Expected no client VNet, got %s %v", value, err)
+	}
+	if _, err := parseClientVNetID(map[string]interface{}{"client_vnet_id": vnetID + "/subnets/default"}); err == nil {
+		t.Error("Expected error for a subnet ID")
+	}
+}
+
+func TestPrivateDNSConversions(t *testing.T) {
+	recordSet := &armprivatedns.RecordSet{
+		Properties: &armprivatedns.RecordSetProperties{
+			ARecords: []*armprivatedns.ARecord{{IPv4Address: to.Ptr("10.224.0.4")}, {IPv4Address: to.Ptr("invalid")}},
+		},
+	}
+	if addresses := recordSetAddresses(recordSet); len(addresses) != 1 || addresses[0].String() != "10.224.0.4" {
+		t.Errorf("Expected one A record address, got %v", addresses)
+	}
+
+	links := privateDNSZoneLinks([]*armprivatedns.VirtualNetworkLink{
+		{
+			Name: to.Ptr("node-link"),
+			Properties: &armprivatedns.VirtualNetworkLinkProperties{
+				VirtualNetwork:          &armprivatedns.SubResource{ID: to.Ptr("/vnets/node")},
+				VirtualNetworkLinkState: to.Ptr(armprivatedns.VirtualNetworkLinkStateCompleted),
+			},
+		},
+		{Name: to.Ptr("broken"), Properties: &armprivatedns.VirtualNetworkLinkProperties{}},
+	})
+	if len(links) != 1 || links[0].Name != "node-link" || links[0].VirtualNetworkID != "/vnets/node" || links[0].State != "Completed" {
+		t.Errorf("Unexpected links: %+v", links)
+	}
+
+	lb := &armnetwork.LoadBalancer{
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PrivateIPAddress: to.Ptr("10.226.0.4")}},
+				{Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{}},
+			},
+		},
+	}
+	if addresses := loadBalancerFrontendAddresses(lb); len(addresses) != 1 || addresses[0].String() != "10.226.0.4" {
+		t.Errorf("Expected the API server load balancer frontend, got %v", addresses)
+	}
+}
+
+func TestHandlePrivateDNSValidation(t *testing.T) {
+	_, err := handlePrivateDNS(context.Background(), nil, map[string]interface{}{"client_vnet_id": "bad"}, "sub", "rg", "aks")
+	if err == nil || !strings.Contains(err.Error(), "client_vnet_id") {
+		t.Errorf("Expected client_vnet_id validation error, got %v", err)
+	}
+}
+
+func TestIsPrivateCluster(t *testing.T) {
+	if !isPrivateCluster(privateCluster(nil)) {
+		t.Error("Expected a private cluster")
+	}
+	if isPrivateCluster(&armcontainerservice.ManagedCluster{Properties: &armcontainerservice.ManagedClusterProperties{}}) {
+		t.Error("Expected a cluster without an API server access profile to be public")
+	}
+}
@@ -17,6 +17,8 @@ const (
 	ResourceTypeSubnet          NetworkResourceType = "subnet"
 	ResourceTypeLoadBalancer    NetworkResourceType = "load_balancer"
 	ResourceTypePrivateEndpoint NetworkResourceType = "private_endpoint"
+	ResourceTypeNodePools       NetworkResourceType = "node_pools"
+	ResourceTypeTopology        NetworkResourceType = "topology"
 )
 
 // RegisterAksNetworkResources registers the network resources tool
@@ -31,11 +33,21 @@ Supported resource types:
 - subnet: Get Subnet information
 - load_balancer: Get Load Balancer information
 - private_endpoint: Get Private Endpoint information (private clusters only)
+- node_pools: Get the subnet, pod subnet, NSG and route table of each node pool
+- topology: Get a graph linking the cluster to its node pools, subnets, NSGs, route tables, VNet, peerings,
+  load balancers, public IPs, NAT gateways and private endpoint. Use format to get it as JSON (default),
+  Mermaid or Graphviz DOT text.
+
+Use node_pool to scope nsg, route_table, subnet, node_pools and topology to a single node pool.
+Without it, nsg, route_table and subnet describe the system node pool subnet.
 
 Examples:
 - Get all network resources: resource_type="all"
 - Get VNet info: resource_type="vnet"
-- Get NSG info: resource_type="nsg"`
+- Get NSG info: resource_type="nsg"
+- Get the subnet of a node pool: resource_type="subnet", node_pool="gpupool"
+- Get the per node pool network mapping: resource_type="node_pools"
+- Draw the network topology: resource_type="topology", format="mermaid"`
 
 	return mcp.NewTool("aks_network_resources",
 		mcp.WithDescription(description),
@@ -57,6 +69,12 @@ Examples:
 			mcp.Description("Name of the AKS cluster"),
 			mcp.Required(),
 		),
+		mcp.WithString("node_pool",
+			mcp.Description("Optional node pool name to scope the nsg, route_table, subnet, node_pools and topology resource types to"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format of the topology resource type: json (default), mermaid or dot"),
+		),
 		mcp.WithString("filters",
 			mcp.Description("Optional filters for the query"),
 		),
@@ -69,6 +87,7 @@ func ValidateNetworkResourceType(resourceType string) bool {
 		string(ResourceTypeAll), string(ResourceTypeVNet), string(ResourceTypeNSG),
 		string(ResourceTypeRouteTable), string(ResourceTypeSubnet),
 		string(ResourceTypeLoadBalancer), string(ResourceTypePrivateEndpoint),
+		string(ResourceTypeNodePools), string(ResourceTypeTopology),
 	}
 
 	return slices.Contains(supportedTypes, resourceType)
@@ -80,5 +99,139 @@ func GetSupportedNetworkResourceTypes() []string {
 		string(ResourceTypeAll), string(ResourceTypeVNet), string(ResourceTypeNSG),
 		string(ResourceTypeRouteTable), string(ResourceTypeSubnet),
 		string(ResourceTypeLoadBalancer), string(ResourceTypePrivateEndpoint),
+		string(ResourceTypeNodePools), string(ResourceTypeTopology),
+	}
+}
+
+// NetworkDiagnosticsOperationType defines the type of network diagnostics operation
+type NetworkDiagnosticsOperationType string
+
+const (
+	OpNetworkFlowCheck    NetworkDiagnosticsOperationType = "network_flow_check"
+	OpEgressAnalysis      NetworkDiagnosticsOperationType = "egress_analysis"
+	OpOutboundCapacity    NetworkDiagnosticsOperationType = "outbound_capacity"
+	OpIPCapacity          NetworkDiagnosticsOperationType = "ip_capacity"
+	OpPrivateDNS          NetworkDiagnosticsOperationType = "private_dns"
+	OpEgressConformance   NetworkDiagnosticsOperationType = "egress_conformance"
+	OpServiceLoadBalancer NetworkDiagnosticsOperationType = "service_load_balancer"
+)
+
+// RegisterAksNetworkDiagnostics registers the network diagnostics tool
+func RegisterAksNetworkDiagnostics() mcp.Tool {
+	description := `Network diagnostics for AKS clusters that evaluate Azure networking configuration instead of returning raw resources.
+
+Supported operations:
+
+1. network_flow_check - Evaluate whether a flow is allowed by the network security groups of a node pool
+   Evaluates the subnet NSG and the NIC (VMSS) NSG in Azure's priority order, including the default rules,
+   and reports Allow or Deny with the exact matching rule of each NSG.
+   Inbound flows are checked against the subnet NSG then the NIC NSG, outbound flows the other way round.
+   Required parameters: source, destination, port (not needed for Icmp or protocol "*")
+   Optional: protocol (Tcp, Udp, Icmp or *, default Tcp), source_port
+   source and destination accept an IP address, a CIDR prefix, a service tag (e.g. Internet, VirtualNetwork,
+   AzureLoadBalancer, Storage.westeurope) or a node pool as "nodepool:<name>". At least one of them must be a node pool.
+   Service tags other than the built-in ones are resolved from the service tags file configured with --service-tags-file.
+
+2. egress_analysis - Analyze the egress path and user defined routes of the node subnets
+   Combines the cluster outbound type, the node subnet route table (longest prefix match over system and user
+   defined routes), the subnet NAT gateway and the load balancer outbound rules. Reports the effective next hop
+   for the destination and the API server, and flags misconfigurations such as a missing default route for
+   userDefinedRouting, a missing route to the API server, unreachable appliances and asymmetric routes.
+   Optional: destination (IP address), node_pool (analyze a single node pool)
+
+3. outbound_capacity - Report the SNAT port headroom of the cluster egress
+   Combines the load balancer outbound rules (allocated ports per instance and frontend IPs, including IP prefixes),
+   the cluster load balancer profile, the node count of each node pool and the NAT gateways of the node subnets
+   (public IPs and idle timeout). Computes how many nodes the SNAT ports can serve and recommends the outbound
+   IP count or allocated ports needed for the target node count. Includes the load balancer and NAT gateway SNAT
+   metrics of the last hour when the monitor component is enabled.
+   Optional: target_node_count (defaults to the sum of the node pool counts, using the autoscaler maximum when enabled)
+
+4. ip_capacity - Plan the IP address capacity of the node pools
+   Combines the network plugin, the pod CIDR, each node pool's count, autoscaler maximum, max pods, max surge,
+   node subnet and pod subnet with the subnet prefixes and their current IP configurations. Azure CNI reserves
+   max pods + 1 IPs per node in the node subnet, dynamic pod subnets take up to max pods IPs per node from the
+   pod subnet, and overlay and kubenet use one node subnet IP and one /24 of the pod CIDR per node.
+   Reports how many more nodes each pool can reach, what limits it and whether an upgrade surge would be blocked.
+   Optional: node_pool (report a single node pool)
+
+5. private_dns - Check that the API server of a private cluster resolves from the node and client VNets
+   Reads the private FQDN and private DNS zone of the cluster (system, custom or none), compares the A record
+   with the private endpoint NIC IP (or the kube-apiserver load balancer for API server VNet integration) and
+   checks that the zone is linked to every node VNet and to the client VNet. VNets with custom DNS servers are
+   flagged because they must forward the zone to Azure DNS. Clusters without a zone are checked in public DNS.
+   Optional: client_vnet_id (resource ID of a VNet that runs kubectl, e.g. a hub or jumpbox VNet)
+
+6. egress_conformance - Check the required AKS outbound FQDNs and ports against the Azure Firewall on the egress path
+   Selects the required rules (API server FQDN, mcr.microsoft.com, login.microsoftonline.com, packages, NTP, ...)
+   for the cluster cloud, region and features (Windows, GPU, monitoring, Azure Policy, Defender, Key Vault) from a
+   versioned rule list embedded in the binary or the file configured with --egress-rules-file. Finds the Azure
+   Firewall behind the default route of each node subnet and evaluates its application and network rule collections
+   or firewall policy (including base policies) in processing order. Reports each rule as allowed, denied, missing
+   or unverified, and flags a missing DNS proxy for network rules with FQDNs.
+   Optional: node_pool, firewall_id (Azure Firewall resource ID, e.g. in a hub subscription), cloud (AzureCloud,
+   AzureChinaCloud or AzureUSGovernment, inferred from the region by default)
+
+7. service_load_balancer - Map a Kubernetes LoadBalancer service to its Azure load balancer configuration
+   Reads the service, its ready endpoints and their nodes from the cluster API using the current kubeconfig
+   context, which must point at the cluster. Finds the frontend IP configuration by the service IP (or the name
+   derived from the service UID) and maps each port to its load balancing rule, health probe and backend pool,
+   following the azure-load-balancer annotations (internal, floating IP, health probe ports and protocol).
+   Flags a missing frontend or rule, a probe on a port nothing serves health checks on, endpoint nodes missing
+   from the backend pool, and NSG rules of the backing node pools that block the traffic or the health probes.
+   Required parameters: service ("<namespace>/<name>" or "<name>")
+   Optional: namespace (default "default")
+
+Examples:
+- Check HTTPS from the internet to a node pool: operation="network_flow_check", parameters="{\"source\":\"Internet\", \"destination\":\"nodepool:nodepool1\", \"port\":443}"
+- Check DNS from a node pool to a custom DNS server: operation="network_flow_check", parameters="{\"source\":\"nodepool:nodepool1\", \"destination\":\"10.0.0.4\", \"port\":53, \"protocol\":\"Udp\"}"
+- Find the next hop to a public address: operation="egress_analysis", parameters="{\"destination\":\"20.50.1.1\"}"
+- Check SNAT headroom for 200 nodes: operation="outbound_capacity", parameters="{\"target_node_count\":200}"
+- Check whether a node pool can scale and surge: operation="ip_capacity", parameters="{\"node_pool\":\"nodepool1\"}"
+- Check that a hub VNet resolves the private API server: operation="private_dns", parameters="{\"client_vnet_id\":\"/subscriptions/<sub>/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet\"}"
+- Find the required AKS endpoints blocked by the hub firewall: operation="egress_conformance", parameters="{\"firewall_id\":\"/subscriptions/<sub>/resourceGroups/hub/providers/Microsoft.Network/azureFirewalls/hub-fw\"}"
+- Check why a service is unreachable: operation="service_load_balancer", parameters="{\"service\":\"ingress-nginx/ingress-nginx-controller\"}"`
+
+	return mcp.NewTool("aks_network_diagnostics",
+		mcp.WithDescription(description),
+		mcp.WithTitleAnnotation("Azure Network Diagnostics"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("The network diagnostics operation to perform: 'network_flow_check' (effective NSG evaluation of a flow), 'egress_analysis' (effective route and egress path), 'outbound_capacity' (SNAT port headroom), 'ip_capacity' (subnet IP capacity planner), 'private_dns' (private cluster API server DNS), 'egress_conformance' (required egress rules against Azure Firewall), 'service_load_balancer' (LoadBalancer service to Azure load balancer mapping)"),
+		),
+		mcp.WithString("subscription_id",
+			mcp.Description("Azure Subscription ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("resource_group",
+			mcp.Description("Azure Resource Group containing the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Name of the AKS cluster"),
+			mcp.Required(),
+		),
+		mcp.WithString("parameters",
+			mcp.Description("JSON string with operation parameters. network_flow_check: source, destination, port, protocol, source_port. egress_analysis: destination, node_pool. outbound_capacity: target_node_count. ip_capacity: node_pool. private_dns: client_vnet_id. egress_conformance: node_pool, firewall_id, cloud. service_load_balancer: service, namespace"),
+		),
+	)
+}
+
+// ValidateNetworkDiagnosticsOperation checks if the network diagnostics operation is supported
+func ValidateNetworkDiagnosticsOperation(operation string) bool {
+	return slices.Contains(GetSupportedNetworkDiagnosticsOperations(), operation)
+}
+
+// GetSupportedNetworkDiagnosticsOperations returns all supported network diagnostics operations
+func GetSupportedNetworkDiagnosticsOperations() []string {
+	return []string{
+		string(OpNetworkFlowCheck),
+		string(OpEgressAnalysis),
+		string(OpOutboundCapacity),
+		string(OpIPCapacity),
+		string(OpPrivateDNS),
+		string(OpEgressConformance),
+		string(OpServiceLoadBalancer),
 	}
 }
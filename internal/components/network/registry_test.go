@@ -61,3 +61,54 @@ func TestGetPrivateEndpointInfoHandler(t *testing.T) {
 		t.Error("Expected handler to be non-nil")
 	}
 }
+
+func TestGetNodePoolNetworksHandler(t *testing.T) {
+	mockClient := &azureclient.AzureClient{}
+	cfg := &config.ConfigData{}
+
+	handler := GetNodePoolNetworksHandler(mockClient, cfg)
+
+	if handler == nil {
+		t.Error("Expected handler to be non-nil")
+	}
+}
+
+func TestValidateNetworkResourceType(t *testing.T) {
+	for _, resourceType := range GetSupportedNetworkResourceTypes() {
+		if !ValidateNetworkResourceType(resourceType) {
+			t.Errorf("Expected resource type %s to be valid", resourceType)
+		}
+	}
+
+	if !ValidateNetworkResourceType("node_pools") {
+		t.Error("Expected node_pools resource type to be valid")
+	}
+	if ValidateNetworkResourceType("node_pool") {
+		t.Error("Expected node_pool resource type to be invalid")
+	}
+}
+
+func TestValidateNetworkDiagnosticsOperation(t *testing.T) {
+	for _, operation := range GetSupportedNetworkDiagnosticsOperations() {
+		if !ValidateNetworkDiagnosticsOperation(operation) {
+			t.Errorf("Expected operation %s to be valid", operation)
+		}
+	}
+
+	if ValidateNetworkDiagnosticsOperation("invalid_operation") {
+		t.Error("Expected invalid_operation to be invalid")
+	}
+}
+
+func TestRegisterAksNetworkDiagnostics(t *testing.T) {
+	tool := RegisterAksNetworkDiagnostics()
+
+	if tool.Name != "aks_network_diagnostics" {
+		t.Errorf("Expected tool name aks_network_diagnostics, got %s", tool.Name)
+	}
+	for _, param := range []string{"operation", "cluster_name", "parameters"} {
+		if _, ok := tool.InputSchema.Properties[param]; !ok {
+			t.Errorf("Expected parameter %s to be defined", param)
+		}
+	}
+}
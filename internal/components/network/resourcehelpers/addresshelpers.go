@@ -0,0 +1,150 @@
+package resourcehelpers
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/compute"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// GetSubnetAddressPrefixes returns the address prefixes of a subnet
+func GetSubnetAddressPrefixes(subnet *armnetwork.Subnet) ([]netip.Prefix, error) {
+	if subnet == nil || subnet.Properties == nil {
+		return nil, fmt.Errorf("subnet has no properties")
+	}
+
+	values := subnet.Properties.AddressPrefixes
+	if subnet.Properties.AddressPrefix != nil {
+		values = append([]*string{subnet.Properties.AddressPrefix}, values...)
+	}
+
+	return parsePrefixes(values)
+}
+
+// GetVirtualNetworkPrefixes returns the address space of the VNet containing a subnet together
+// with the address space of its peered VNets, which is what the VirtualNetwork service tag covers.
+func GetVirtualNetworkPrefixes(ctx context.Context, client *azureclient.AzureClient, subnetID string) ([]netip.Prefix, error) {
+	local, peered, err := GetVirtualNetworkAddressSpace(ctx, client, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	return append(local, peered...), nil
+}
+
+// GetVirtualNetworkAddressSpace returns the address space of the VNet containing a subnet and,
+// separately, the address space of its connected peerings.
+func GetVirtualNetworkAddressSpace(ctx context.Context, client *azureclient.AzureClient, subnetID string) ([]netip.Prefix, []netip.Prefix, error) {
+	parsedSubnetID, err := arm.ParseResourceID(subnetID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse subnet ID: %v", err)
+	}
+	if parsedSubnetID.Parent == nil {
+		return nil, nil, fmt.Errorf("could not determine VNet from subnet ID: %s", subnetID)
+	}
+
+	vnet, err := client.GetVirtualNetwork(ctx,
+		parsedSubnetID.SubscriptionID,
+		parsedSubnetID.ResourceGroupName,
+		parsedSubnetID.Parent.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get VNet details: %v", err)
+	}
+
+	return virtualNetworkAddressSpace(vnet)
+}
+
+// virtualNetworkAddressSpace collects the address space of a VNet and of its connected peerings
+func virtualNetworkAddressSpace(vnet *armnetwork.VirtualNetwork) ([]netip.Prefix, []netip.Prefix, error) {
+	if vnet == nil || vnet.Properties == nil {
+		return nil, nil, fmt.Errorf("VNet has no properties")
+	}
+
+	var localValues, peeredValues []*string
+	if vnet.Properties.AddressSpace != nil {
+		localValues = vnet.Properties.AddressSpace.AddressPrefixes
+	}
+	for _, peering := range vnet.Properties.VirtualNetworkPeerings {
+		if peering == nil || peering.Properties == nil || peering.Properties.RemoteAddressSpace == nil {
+			continue
+		}
+		if peering.Properties.PeeringState != nil && *peering.Properties.PeeringState != armnetwork.VirtualNetworkPeeringStateConnected {
+			continue
+		}
+		peeredValues = append(peeredValues, peering.Properties.RemoteAddressSpace.AddressPrefixes...)
+	}
+
+	local, err := parsePrefixes(localValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	peered, err := parsePrefixes(peeredValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	return local, peered, nil
+}
+
+// GetNodePoolNICNSGID returns the ID of the network security group attached to the
+// network interfaces of a node pool's VMSS, or an empty string if none is attached.
+func GetNodePoolNICNSGID(ctx context.Context, cluster *armcontainerservice.ManagedCluster, client *azureclient.AzureClient, nodePool string) (string, error) {
+	vmssID, err := compute.GetVMSSIDFromNodePool(ctx, cluster, nodePool, client)
+	if err != nil {
+		return "", err
+	}
+
+	parsedVMSSID, err := arm.ParseResourceID(vmssID)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse VMSS ID: %v", err)
+	}
+
+	vmss, err := client.GetVMSS(ctx, parsedVMSSID.SubscriptionID, parsedVMSSID.ResourceGroupName, parsedVMSSID.Name)
+	if err != nil {
+		return "", err
+	}
+
+	return vmssNICNSGID(vmss), nil
+}
+
+// vmssNICNSGID returns the NSG of the primary network interface configuration of a VMSS
+func vmssNICNSGID(vmss *armcompute.VirtualMachineScaleSet) string {
+	if vmss == nil || vmss.Properties == nil || vmss.Properties.VirtualMachineProfile == nil ||
+		vmss.Properties.VirtualMachineProfile.NetworkProfile == nil {
+		return ""
+	}
+
+	var nsgID string
+	for _, nic := range vmss.Properties.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations {
+		if nic == nil || nic.Properties == nil || nic.Properties.NetworkSecurityGroup == nil || nic.Properties.NetworkSecurityGroup.ID == nil {
+			continue
+		}
+		if nic.Properties.Primary != nil && *nic.Properties.Primary {
+			return *nic.Properties.NetworkSecurityGroup.ID
+		}
+		if nsgID == "" {
+			nsgID = *nic.Properties.NetworkSecurityGroup.ID
+		}
+	}
+	return nsgID
+}
+
+// parsePrefixes parses CIDR prefixes, skipping empty values
+func parsePrefixes(values []*string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		if value == nil || *value == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(*value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address prefix %s: %v", *value, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
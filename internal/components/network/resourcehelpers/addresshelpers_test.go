@@ -0,0 +1,105 @@
+package resourcehelpers
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+func TestGetSubnetAddressPrefixes(t *testing.T) {
+	subnet := &armnetwork.Subnet{
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			AddressPrefix:   to.Ptr("10.224.0.0/16"),
+			AddressPrefixes: []*string{to.Ptr("fd00:1::/64"), to.Ptr("")},
+		},
+	}
+
+	prefixes, err := GetSubnetAddressPrefixes(subnet)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(prefixes) != 2 || prefixes[0].String() != "10.224.0.0/16" || prefixes[1].String() != "fd00:1::/64" {
+		t.Errorf("Unexpected prefixes: %v", prefixes)
+	}
+
+	subnet.Properties.AddressPrefix = to.Ptr("not-a-prefix")
+	if _, err := GetSubnetAddressPrefixes(subnet); err == nil {
+		t.Error("Expected error for invalid address prefix")
+	}
+
+	if _, err := GetSubnetAddressPrefixes(&armnetwork.Subnet{}); err == nil {
+		t.Error("Expected error for subnet without properties")
+	}
+}
+
+func TestVirtualNetworkAddressSpace(t *testing.T) {
+	peering := func(state armnetwork.VirtualNetworkPeeringState, prefix string) *armnetwork.VirtualNetworkPeering {
+		return &armnetwork.VirtualNetworkPeering{
+			Properties: &armnetwork.VirtualNetworkPeeringPropertiesFormat{
+				PeeringState:       to.Ptr(state),
+				RemoteAddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr(prefix)}},
+			},
+		}
+	}
+
+	vnet := &armnetwork.VirtualNetwork{
+		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+			AddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr("10.224.0.0/12")}},
+			VirtualNetworkPeerings: []*armnetwork.VirtualNetworkPeering{
+				peering(armnetwork.VirtualNetworkPeeringStateConnected, "10.100.0.0/16"),
+				peering(armnetwork.VirtualNetworkPeeringStateDisconnected, "10.200.0.0/16"),
+			},
+		},
+	}
+
+	local, peered, err := virtualNetworkAddressSpace(vnet)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(local) != 1 || local[0].String() != "10.224.0.0/12" {
+		t.Errorf("Expected VNet prefix 10.224.0.0/12, got %v", local)
+	}
+	if len(peered) != 1 || peered[0].String() != "10.100.0.0/16" {
+		t.Errorf("Expected connected peering prefix 10.100.0.0/16, got %v", peered)
+	}
+
+	if _, _, err := virtualNetworkAddressSpace(&armnetwork.VirtualNetwork{}); err == nil {
+		t.Error("Expected error for VNet without properties")
+	}
+}
+
+func TestVMSSNICNSGID(t *testing.T) {
+	nic := func(primary bool, nsgID string) *armcompute.VirtualMachineScaleSetNetworkConfiguration {
+		config := &armcompute.VirtualMachineScaleSetNetworkConfiguration{
+			Properties: &armcompute.VirtualMachineScaleSetNetworkConfigurationProperties{Primary: to.Ptr(primary)},
+		}
+		if nsgID != "" {
+			config.Properties.NetworkSecurityGroup = &armcompute.SubResource{ID: to.Ptr(nsgID)}
+		}
+		return config
+	}
+	vmss := func(nics ...*armcompute.VirtualMachineScaleSetNetworkConfiguration) *armcompute.VirtualMachineScaleSet {
+		return &armcompute.VirtualMachineScaleSet{
+			Properties: &armcompute.VirtualMachineScaleSetProperties{
+				VirtualMachineProfile: &armcompute.VirtualMachineScaleSetVMProfile{
+					NetworkProfile: &armcompute.VirtualMachineScaleSetNetworkProfile{NetworkInterfaceConfigurations: nics},
+				},
+			},
+		}
+	}
+
+	if got := vmssNICNSGID(vmss(nic(false, "secondary-nsg"), nic(true, "primary-nsg"))); got != "primary-nsg" {
+		t.Errorf("Expected primary NIC NSG, got %q", got)
+	}
+	if got := vmssNICNSGID(vmss(nic(true, ""), nic(false, "secondary-nsg"))); got != "secondary-nsg" {
+		t.Errorf("Expected fallback to secondary NIC NSG, got %q", got)
+	}
+	if got := vmssNICNSGID(vmss(nic(true, ""))); got != "" {
+		t.Errorf("Expected no NSG, got %q", got)
+	}
+	if got := vmssNICNSGID(nil); got != "" {
+		t.Errorf("Expected no NSG for nil VMSS, got %q", got)
+	}
+}
@@ -147,6 +147,65 @@ func TestGetSubnetIDFromAKS(t *testing.T) {
 			t.Error("Expected error for cluster with nil properties")
 		}
 	})
+
+	t.Run("prefers the system node pool subnet", func(t *testing.T) {
+		userSubnetID := "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.Network/virtualNetworks/myVNet/subnets/user"
+		systemSubnetID := "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.Network/virtualNetworks/myVNet/subnets/system"
+		userMode := armcontainerservice.AgentPoolModeUser
+		systemMode := armcontainerservice.AgentPoolModeSystem
+
+		cluster := &armcontainerservice.ManagedCluster{
+			Properties: &armcontainerservice.ManagedClusterProperties{
+				AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+					{Mode: &userMode, VnetSubnetID: &userSubnetID},
+					{Mode: &systemMode, VnetSubnetID: &systemSubnetID},
+				},
+			},
+		}
+
+		subnetID, err := GetSubnetIDFromAKS(ctx, cluster, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if subnetID != systemSubnetID {
+			t.Errorf("Expected subnet ID %s, got %s", systemSubnetID, subnetID)
+		}
+	})
+}
+
+// TestGetNodePoolSubnetID tests the subnet ID resolution for a single node pool
+func TestGetNodePoolSubnetID(t *testing.T) {
+	ctx := context.Background()
+	pool1SubnetID := "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.Network/virtualNetworks/myVNet/subnets/pool1"
+	pool2SubnetID := "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.Network/virtualNetworks/myVNet/subnets/pool2"
+	pool1 := "pool1"
+	pool2 := "pool2"
+
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: &pool1, VnetSubnetID: &pool1SubnetID},
+				{Name: &pool2, VnetSubnetID: &pool2SubnetID},
+			},
+		},
+	}
+
+	t.Run("returns the subnet of the named node pool", func(t *testing.T) {
+		subnetID, err := GetNodePoolSubnetID(ctx, cluster, nil, "pool2")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if subnetID != pool2SubnetID {
+			t.Errorf("Expected subnet ID %s, got %s", pool2SubnetID, subnetID)
+		}
+	})
+
+	t.Run("unknown node pool", func(t *testing.T) {
+		_, err := GetNodePoolSubnetID(ctx, cluster, nil, "pool3")
+		if err == nil {
+			t.Error("Expected error for unknown node pool")
+		}
+	})
 }
 
 // TestParseResourceID tests the resource ID parsing functionality
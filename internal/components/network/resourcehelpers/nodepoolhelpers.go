@@ -0,0 +1,145 @@
+package resourcehelpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// NodePoolNetwork describes the network resources used by a single AKS node pool
+type NodePoolNetwork struct {
+	NodePool              string   `json:"node_pool"`
+	Mode                  string   `json:"mode,omitempty"`
+	OSType                string   `json:"os_type,omitempty"`
+	ManagedVNet           bool     `json:"managed_vnet"`
+	SubnetID              string   `json:"subnet_id,omitempty"`
+	NSGID                 string   `json:"nsg_id,omitempty"`
+	RouteTableID          string   `json:"route_table_id,omitempty"`
+	PodSubnetID           string   `json:"pod_subnet_id,omitempty"`
+	PodSubnetNSGID        string   `json:"pod_subnet_nsg_id,omitempty"`
+	PodSubnetRouteTableID string   `json:"pod_subnet_route_table_id,omitempty"`
+	Errors                []string `json:"errors,omitempty"`
+}
+
+// GetNodePoolNetworks resolves the subnet, pod subnet, NSG and route table of every node pool.
+// When nodePool is set only that node pool is returned. Lookup failures for a single node pool
+// are reported in its Errors rather than failing the whole mapping.
+func GetNodePoolNetworks(
+	ctx context.Context,
+	cluster *armcontainerservice.ManagedCluster,
+	client *azureclient.AzureClient,
+	nodePool string,
+) ([]NodePoolNetwork, error) {
+	return buildNodePoolNetworks(cluster, nodePool,
+		func() (string, error) { return getManagedSubnetID(ctx, cluster, client) },
+		func(subnetID string) (*armnetwork.Subnet, error) { return GetSubnetByID(ctx, client, subnetID) },
+	)
+}
+
+// buildNodePoolNetworks builds the node pool network mapping using the given lookups.
+// The managed VNet subnet is resolved at most once and only if a node pool needs it.
+func buildNodePoolNetworks(
+	cluster *armcontainerservice.ManagedCluster,
+	nodePool string,
+	managedSubnetID func() (string, error),
+	getSubnet func(string) (*armnetwork.Subnet, error),
+) ([]NodePoolNetwork, error) {
+	if cluster == nil || cluster.Properties == nil {
+		return nil, fmt.Errorf("invalid cluster or cluster properties")
+	}
+
+	var pools []*armcontainerservice.ManagedClusterAgentPoolProfile
+	if nodePool != "" {
+		pool, err := findAgentPoolProfile(cluster, nodePool)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	} else {
+		for _, pool := range cluster.Properties.AgentPoolProfiles {
+			if pool != nil {
+				pools = append(pools, pool)
+			}
+		}
+	}
+
+	var (
+		managedResolved bool
+		managedID       string
+		managedErr      error
+	)
+	subnets := make(map[string]*armnetwork.Subnet)
+	subnetErrs := make(map[string]error)
+	lookupSubnet := func(subnetID string) (*armnetwork.Subnet, error) {
+		// Node pools frequently share subnets, so each subnet is fetched once
+		key := strings.ToLower(subnetID)
+		if subnet, ok := subnets[key]; ok {
+			return subnet, nil
+		}
+		if err, ok := subnetErrs[key]; ok {
+			return nil, err
+		}
+		subnet, err := getSubnet(subnetID)
+		if err != nil {
+			subnetErrs[key] = err
+			return nil, err
+		}
+		subnets[key] = subnet
+		return subnet, nil
+	}
+
+	networks := make([]NodePoolNetwork, 0, len(pools))
+	for _, pool := range pools {
+		network := NodePoolNetwork{}
+		if pool.Name != nil {
+			network.NodePool = *pool.Name
+		}
+		if pool.Mode != nil {
+			network.Mode = string(*pool.Mode)
+		}
+		if pool.OSType != nil {
+			network.OSType = string(*pool.OSType)
+		}
+
+		if pool.VnetSubnetID != nil && *pool.VnetSubnetID != "" {
+			network.SubnetID = *pool.VnetSubnetID
+		} else {
+			network.ManagedVNet = true
+			if !managedResolved {
+				managedID, managedErr = managedSubnetID()
+				managedResolved = true
+			}
+			if managedErr != nil {
+				network.Errors = append(network.Errors, fmt.Sprintf("failed to resolve managed VNet subnet: %v", managedErr))
+			}
+			network.SubnetID = managedID
+		}
+
+		if network.SubnetID != "" {
+			if subnet, err := lookupSubnet(network.SubnetID); err != nil {
+				network.Errors = append(network.Errors, fmt.Sprintf("subnet %s: %v", network.SubnetID, err))
+			} else {
+				network.NSGID = subnetNSGID(subnet)
+				network.RouteTableID = subnetRouteTableID(subnet)
+			}
+		}
+
+		if pool.PodSubnetID != nil && *pool.PodSubnetID != "" {
+			network.PodSubnetID = *pool.PodSubnetID
+			if subnet, err := lookupSubnet(network.PodSubnetID); err != nil {
+				network.Errors = append(network.Errors, fmt.Sprintf("pod subnet %s: %v", network.PodSubnetID, err))
+			} else {
+				network.PodSubnetNSGID = subnetNSGID(subnet)
+				network.PodSubnetRouteTableID = subnetRouteTableID(subnet)
+			}
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
@@ -0,0 +1,183 @@
+package resourcehelpers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+const (
+	testVNetID        = "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/myRG/providers/Microsoft.Network/virtualNetworks/myVNet"
+	testNodeSubnetID  = testVNetID + "/subnets/nodes"
+	testGPUSubnetID   = testVNetID + "/subnets/gpu-nodes"
+	testPodSubnetID   = testVNetID + "/subnets/pods"
+	testManagedSubnet = "/subscriptions/12345678-1234-1234-1234-123456789012/resourceGroups/MC_myRG/providers/Microsoft.Network/virtualNetworks/aks-vnet-123/subnets/aks-subnet"
+)
+
+func testSubnet(nsgID, routeTableID string) *armnetwork.Subnet {
+	subnet := &armnetwork.Subnet{Properties: &armnetwork.SubnetPropertiesFormat{}}
+	if nsgID != "" {
+		subnet.Properties.NetworkSecurityGroup = &armnetwork.SecurityGroup{ID: to.Ptr(nsgID)}
+	}
+	if routeTableID != "" {
+		subnet.Properties.RouteTable = &armnetwork.RouteTable{ID: to.Ptr(routeTableID)}
+	}
+	return subnet
+}
+
+func TestBuildNodePoolNetworks(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{
+					Name:         to.Ptr("system"),
+					Mode:         to.Ptr(armcontainerservice.AgentPoolModeSystem),
+					OSType:       to.Ptr(armcontainerservice.OSTypeLinux),
+					VnetSubnetID: to.Ptr(testNodeSubnetID),
+					PodSubnetID:  to.Ptr(testPodSubnetID),
+				},
+				{
+					Name:         to.Ptr("gpu"),
+					Mode:         to.Ptr(armcontainerservice.AgentPoolModeUser),
+					VnetSubnetID: to.Ptr(testGPUSubnetID),
+					PodSubnetID:  to.Ptr(testPodSubnetID),
+				},
+			},
+		},
+	}
+
+	subnets := map[string]*armnetwork.Subnet{
+		testNodeSubnetID: testSubnet("nodes-nsg", "nodes-rt"),
+		testGPUSubnetID:  testSubnet("gpu-nsg", ""),
+		testPodSubnetID:  testSubnet("pods-nsg", "pods-rt"),
+	}
+	lookups := make(map[string]int)
+	getSubnet := func(subnetID string) (*armnetwork.Subnet, error) {
+		lookups[subnetID]++
+		return subnets[subnetID], nil
+	}
+	managedSubnet := func() (string, error) {
+		t.Fatal("managed VNet subnet should not be resolved when all node pools set a subnet")
+		return "", nil
+	}
+
+	t.Run("all node pools", func(t *testing.T) {
+		networks, err := buildNodePoolNetworks(cluster, "", managedSubnet, getSubnet)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(networks) != 2 {
+			t.Fatalf("Expected 2 node pools, got %d", len(networks))
+		}
+
+		system := networks[0]
+		if system.NodePool != "system" || system.Mode != "System" || system.OSType != "Linux" {
+			t.Errorf("Unexpected node pool metadata: %+v", system)
+		}
+		if system.SubnetID != testNodeSubnetID || system.NSGID != "nodes-nsg" || system.RouteTableID != "nodes-rt" {
+			t.Errorf("Unexpected node subnet resources: %+v", system)
+		}
+		if system.PodSubnetID != testPodSubnetID || system.PodSubnetNSGID != "pods-nsg" || system.PodSubnetRouteTableID != "pods-rt" {
+			t.Errorf("Unexpected pod subnet resources: %+v", system)
+		}
+
+		gpu := networks[1]
+		if gpu.SubnetID != testGPUSubnetID || gpu.NSGID != "gpu-nsg" || gpu.RouteTableID != "" {
+			t.Errorf("Unexpected GPU node pool resources: %+v", gpu)
+		}
+		if gpu.ManagedVNet || len(gpu.Errors) != 0 {
+			t.Errorf("Unexpected GPU node pool state: %+v", gpu)
+		}
+
+		// The shared pod subnet is only fetched once
+		if lookups[testPodSubnetID] != 1 {
+			t.Errorf("Expected pod subnet to be fetched once, got %d", lookups[testPodSubnetID])
+		}
+	})
+
+	t.Run("node pool filter", func(t *testing.T) {
+		networks, err := buildNodePoolNetworks(cluster, "GPU", managedSubnet, getSubnet)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(networks) != 1 || networks[0].NodePool != "gpu" {
+			t.Fatalf("Expected only the gpu node pool, got %+v", networks)
+		}
+	})
+
+	t.Run("unknown node pool", func(t *testing.T) {
+		_, err := buildNodePoolNetworks(cluster, "missing", managedSubnet, getSubnet)
+		if err == nil {
+			t.Fatal("Expected error for unknown node pool")
+		}
+		if !strings.Contains(err.Error(), "system, gpu") {
+			t.Errorf("Expected error to list available node pools, got %v", err)
+		}
+	})
+
+	t.Run("nil cluster properties", func(t *testing.T) {
+		_, err := buildNodePoolNetworks(&armcontainerservice.ManagedCluster{}, "", managedSubnet, getSubnet)
+		if err == nil {
+			t.Error("Expected error for cluster with nil properties")
+		}
+	})
+}
+
+func TestBuildNodePoolNetworksManagedVNet(t *testing.T) {
+	cluster := &armcontainerservice.ManagedCluster{
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{Name: to.Ptr("nodepool1")},
+				{Name: to.Ptr("nodepool2")},
+			},
+		},
+	}
+
+	t.Run("managed subnet resolved once", func(t *testing.T) {
+		resolved := 0
+		managedSubnet := func() (string, error) {
+			resolved++
+			return testManagedSubnet, nil
+		}
+		getSubnet := func(string) (*armnetwork.Subnet, error) {
+			return testSubnet("aks-agentpool-nsg", ""), nil
+		}
+
+		networks, err := buildNodePoolNetworks(cluster, "", managedSubnet, getSubnet)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resolved != 1 {
+			t.Errorf("Expected managed subnet to be resolved once, got %d", resolved)
+		}
+		for _, network := range networks {
+			if !network.ManagedVNet || network.SubnetID != testManagedSubnet || network.NSGID != "aks-agentpool-nsg" {
+				t.Errorf("Unexpected managed VNet node pool: %+v", network)
+			}
+		}
+	})
+
+	t.Run("lookup errors are reported per node pool", func(t *testing.T) {
+		managedSubnet := func() (string, error) {
+			return "", errors.New("no VNet")
+		}
+		getSubnet := func(string) (*armnetwork.Subnet, error) {
+			t.Fatal("subnet should not be fetched without a subnet ID")
+			return nil, nil
+		}
+
+		networks, err := buildNodePoolNetworks(cluster, "", managedSubnet, getSubnet)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, network := range networks {
+			if len(network.Errors) != 1 || !strings.Contains(network.Errors[0], "no VNet") {
+				t.Errorf("Expected managed VNet error for %s, got %v", network.NodePool, network.Errors)
+			}
+		}
+	})
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/Azure/aks-mcp/internal/azureclient"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
 )
 
 // GetNSGIDFromAKS attempts to find a network security group associated with an AKS cluster.
@@ -28,47 +28,38 @@ func GetNSGIDFromAKS(
 		return "", fmt.Errorf("no subnet found for AKS cluster: %v", err)
 	}
 
-	// Parse subnet ID to get subscription, resource group, vnet name and subnet name
-	parsedSubnetID, err := arm.ParseResourceID(subnetID)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse subnet ID: %v", err)
-	}
+	return GetNSGIDFromSubnet(ctx, client, subnetID)
+}
 
-	// Check if this is a subnet resource
-	if parsedSubnetID.ResourceType.String() != "Microsoft.Network/virtualNetworks/subnets" {
-		return "", fmt.Errorf("invalid subnet ID format: %s", subnetID)
+// GetNSGIDFromSubnet returns the ID of the network security group attached to a subnet.
+// It returns an error if no NSG is attached.
+func GetNSGIDFromSubnet(ctx context.Context, client *azureclient.AzureClient, subnetID string) (string, error) {
+	subnet, err := GetSubnetByID(ctx, client, subnetID)
+	if err != nil {
+		return "", err
 	}
 
-	// Get the subscription ID from the subnet ID
-	subscriptionID := parsedSubnetID.SubscriptionID
-	resourceGroup := parsedSubnetID.ResourceGroupName
-	subnetName := parsedSubnetID.Name
-
-	// Get VNet name from parent resource
-	var vnetName string
-	if parsedSubnetID.Parent != nil {
-		vnetName = parsedSubnetID.Parent.Name
-	} else {
-		return "", fmt.Errorf("could not determine VNet name from subnet ID: %s", subnetID)
+	// Check if the subnet has an NSG attached
+	nsgID := subnetNSGID(subnet)
+	if nsgID == "" {
+		return "", fmt.Errorf("no network security group attached to subnet %s", subnetName(subnet, subnetID))
 	}
 
-	// Get subnet details to find attached NSG
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
-	}
+	return nsgID, nil
+}
 
-	subnet, err := clients.SubnetsClient.Get(ctx, resourceGroup, vnetName, subnetName, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to get subnet details: %v", err)
+// subnetNSGID returns the ID of the NSG attached to a subnet, or an empty string
+func subnetNSGID(subnet *armnetwork.Subnet) string {
+	if subnet == nil || subnet.Properties == nil || subnet.Properties.NetworkSecurityGroup == nil || subnet.Properties.NetworkSecurityGroup.ID == nil {
+		return ""
 	}
+	return *subnet.Properties.NetworkSecurityGroup.ID
+}
 
-	// Check if the subnet has an NSG attached
-	if subnet.Properties == nil || subnet.Properties.NetworkSecurityGroup == nil || subnet.Properties.NetworkSecurityGroup.ID == nil {
-		return "", fmt.Errorf("no network security group attached to subnet %s", subnetName)
+// subnetName returns the subnet name, falling back to its resource ID
+func subnetName(subnet *armnetwork.Subnet, subnetID string) string {
+	if subnet != nil && subnet.Name != nil {
+		return *subnet.Name
 	}
-
-	nsgID := *subnet.Properties.NetworkSecurityGroup.ID
-
-	return nsgID, nil
+	return subnetID
 }
@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/Azure/aks-mcp/internal/azureclient"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
 )
 
 // GetRouteTableIDFromAKS attempts to find a route table associated with an AKS cluster.
@@ -28,48 +28,24 @@ func GetRouteTableIDFromAKS(
 		return "", fmt.Errorf("no subnet found for AKS cluster: %v", err)
 	}
 
-	// Parse subnet ID to get subscription, resource group, vnet name and subnet name
-	parsedSubnetID, err := arm.ParseResourceID(subnetID)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse subnet ID: %v", err)
-	}
-
-	// Check if this is a subnet resource
-	if parsedSubnetID.ResourceType.String() != "Microsoft.Network/virtualNetworks/subnets" {
-		return "", fmt.Errorf("invalid subnet ID format: %s", subnetID)
-	}
-
-	// Get the subscription ID from the subnet ID
-	subscriptionID := parsedSubnetID.SubscriptionID
-	resourceGroup := parsedSubnetID.ResourceGroupName
-	subnetName := parsedSubnetID.Name
-
-	// Get VNet name from parent resource
-	var vnetName string
-	if parsedSubnetID.Parent != nil {
-		vnetName = parsedSubnetID.Parent.Name
-	} else {
-		return "", fmt.Errorf("could not determine VNet name from subnet ID: %s", subnetID)
-	}
+	return GetRouteTableIDFromSubnet(ctx, client, subnetID)
+}
 
-	// Get subnet details to find attached route table
-	clients, err := client.GetOrCreateClientsForSubscription(subscriptionID)
+// GetRouteTableIDFromSubnet returns the ID of the route table attached to a subnet.
+// If no route table is attached, it returns an empty string and no error (this is a valid state).
+func GetRouteTableIDFromSubnet(ctx context.Context, client *azureclient.AzureClient, subnetID string) (string, error) {
+	subnet, err := GetSubnetByID(ctx, client, subnetID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get clients for subscription %s: %v", subscriptionID, err)
+		return "", err
 	}
 
-	subnet, err := clients.SubnetsClient.Get(ctx, resourceGroup, vnetName, subnetName, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to get subnet details: %v", err)
-	}
+	return subnetRouteTableID(subnet), nil
+}
 
-	// Check if the subnet has a route table attached
-	if subnet.Properties == nil || subnet.Properties.RouteTable == nil || subnet.Properties.RouteTable.ID == nil {
-		// No route table attached - this is a valid configuration state
-		return "", nil
+// subnetRouteTableID returns the ID of the route table attached to a subnet, or an empty string
+func subnetRouteTableID(subnet *armnetwork.Subnet) string {
+	if subnet == nil || subnet.Properties == nil || subnet.Properties.RouteTable == nil || subnet.Properties.RouteTable.ID == nil {
+		return ""
 	}
-
-	routeTableID := *subnet.Properties.RouteTable.ID
-
-	return routeTableID, nil
+	return *subnet.Properties.RouteTable.ID
 }
@@ -3,27 +3,116 @@ package resourcehelpers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/aks-mcp/internal/azureclient"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
 )
 
-// GetSubnetIDFromAKS extracts subnet ID from an AKS cluster.
-// It tries to get the subnet ID from the agent pool profiles first.
-// If not found, it will try to find the VNet in the node resource group, and then
-// look for a subnet with the name 'aks-subnet' or use the first subnet if not found.
+// managedSubnetName is the subnet name AKS uses in the VNet it manages in the node resource group
+const managedSubnetName = "aks-subnet"
+
+// GetSubnetIDFromAKS extracts the node subnet ID of an AKS cluster.
+// It prefers the subnet of the first System mode node pool, then the first node pool with a subnet.
+// If no node pool sets a subnet, the cluster uses the AKS managed VNet in the node resource group,
+// whose subnet is named 'aks-subnet'. Use GetNodePoolSubnetID or GetNodePoolNetworks when
+// node pools may use different subnets.
 func GetSubnetIDFromAKS(ctx context.Context, cluster *armcontainerservice.ManagedCluster, client *azureclient.AzureClient) (string, error) {
-	// First, try to get subnet ID directly from agent pool profiles
-	if cluster.Properties != nil && cluster.Properties.AgentPoolProfiles != nil {
+	if cluster != nil && cluster.Properties != nil {
+		var firstSubnetID string
 		for _, pool := range cluster.Properties.AgentPoolProfiles {
-			if pool.VnetSubnetID != nil && *pool.VnetSubnetID != "" {
+			if pool == nil || pool.VnetSubnetID == nil || *pool.VnetSubnetID == "" {
+				continue
+			}
+			if pool.Mode != nil && *pool.Mode == armcontainerservice.AgentPoolModeSystem {
 				return *pool.VnetSubnetID, nil
 			}
+			if firstSubnetID == "" {
+				firstSubnetID = *pool.VnetSubnetID
+			}
 		}
+		if firstSubnetID != "" {
+			return firstSubnetID, nil
+		}
+	}
+
+	return getManagedSubnetID(ctx, cluster, client)
+}
+
+// GetNodePoolSubnetID returns the node subnet ID of the named node pool.
+// Node pools without a subnet use the AKS managed VNet subnet.
+func GetNodePoolSubnetID(ctx context.Context, cluster *armcontainerservice.ManagedCluster, client *azureclient.AzureClient, nodePool string) (string, error) {
+	pool, err := findAgentPoolProfile(cluster, nodePool)
+	if err != nil {
+		return "", err
+	}
+
+	if pool.VnetSubnetID != nil && *pool.VnetSubnetID != "" {
+		return *pool.VnetSubnetID, nil
+	}
+
+	return getManagedSubnetID(ctx, cluster, client)
+}
+
+// GetSubnetByID retrieves a subnet from its resource ID
+func GetSubnetByID(ctx context.Context, client *azureclient.AzureClient, subnetID string) (*armnetwork.Subnet, error) {
+	// Parse subnet ID to get subscription, resource group, vnet name and subnet name
+	parsedSubnetID, err := arm.ParseResourceID(subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subnet ID: %v", err)
+	}
+
+	// Check if this is a subnet resource
+	if parsedSubnetID.ResourceType.String() != "Microsoft.Network/virtualNetworks/subnets" {
+		return nil, fmt.Errorf("invalid subnet ID format: %s", subnetID)
 	}
 
-	// If we couldn't find a subnet ID in the agent pool profiles, try to find the VNet first
+	// Get VNet name from parent resource
+	if parsedSubnetID.Parent == nil {
+		return nil, fmt.Errorf("could not determine VNet name from subnet ID: %s", subnetID)
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("azure client is required to get subnet %s", subnetID)
+	}
+
+	subnet, err := client.GetSubnet(ctx,
+		parsedSubnetID.SubscriptionID,
+		parsedSubnetID.ResourceGroupName,
+		parsedSubnetID.Parent.Name,
+		parsedSubnetID.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet details: %v", err)
+	}
+
+	return subnet, nil
+}
+
+// findAgentPoolProfile returns the agent pool profile with the given name
+func findAgentPoolProfile(cluster *armcontainerservice.ManagedCluster, nodePool string) (*armcontainerservice.ManagedClusterAgentPoolProfile, error) {
+	if cluster == nil || cluster.Properties == nil {
+		return nil, fmt.Errorf("invalid cluster or cluster properties")
+	}
+
+	var names []string
+	for _, pool := range cluster.Properties.AgentPoolProfiles {
+		if pool == nil || pool.Name == nil {
+			continue
+		}
+		if strings.EqualFold(*pool.Name, nodePool) {
+			return pool, nil
+		}
+		names = append(names, *pool.Name)
+	}
+
+	return nil, fmt.Errorf("node pool %s not found in cluster. Available node pools: %s", nodePool, strings.Join(names, ", "))
+}
+
+// getManagedSubnetID finds the subnet of the AKS managed VNet in the node resource group.
+// It looks for the 'aks-subnet' subnet and uses the first subnet if not found.
+func getManagedSubnetID(ctx context.Context, cluster *armcontainerservice.ManagedCluster, client *azureclient.AzureClient) (string, error) {
 	vnetID, err := GetVNetIDFromAKS(ctx, cluster, client)
 	if err != nil || vnetID == "" {
 		return "", fmt.Errorf("could not find VNet for AKS cluster: %v", err)
@@ -45,20 +134,20 @@ func GetSubnetIDFromAKS(ctx context.Context, cluster *armcontainerservice.Manage
 	}
 
 	// If VNet has no subnets, return error
-	if vnet.Properties == nil || vnet.Properties.Subnets == nil || len(vnet.Properties.Subnets) == 0 {
+	if vnet.Properties == nil || len(vnet.Properties.Subnets) == 0 {
 		return "", fmt.Errorf("VNet has no subnets")
 	}
 
-	// First try to find a subnet with name "aks-subnet"
+	// First try to find the subnet AKS creates in its managed VNet
 	for _, subnet := range vnet.Properties.Subnets {
-		if subnet.Name != nil && *subnet.Name == "aks-subnet" {
+		if subnet.Name != nil && *subnet.Name == managedSubnetName {
 			if subnet.ID != nil {
 				return *subnet.ID, nil
 			}
 		}
 	}
 
-	// If no "aks-subnet" found, use the first subnet
+	// If no managed subnet found, use the first subnet
 	if vnet.Properties.Subnets[0].ID != nil {
 		return *vnet.Properties.Subnets[0].ID, nil
 	}
@@ -0,0 +1,351 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/components/network/servicelb"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Purposes of the NSG checks of a service
+const (
+	serviceNSGCheckTraffic = "traffic"
+	serviceNSGCheckProbe   = "probe"
+)
+
+// ServiceLoadBalancerResult is the mapping of a Kubernetes LoadBalancer service to its Azure load balancer
+type ServiceLoadBalancerResult struct {
+	servicelb.Result
+	NSGChecks []ServiceNSGCheck `json:"nsg_checks,omitempty"`
+	Notes     []string          `json:"notes,omitempty"`
+}
+
+// ServiceNSGCheck is the NSG evaluation of load balanced or health probe traffic at one node pool
+type ServiceNSGCheck struct {
+	NodePool    string `json:"node_pool"`
+	Purpose     string `json:"purpose"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Protocol    string `json:"protocol"`
+	Port        int32  `json:"port"`
+	flowcheck.Result
+}
+
+// handleServiceLoadBalancer maps a LoadBalancer service to the frontend, rules, probes, backend
+// pools and NSG rules of the cluster load balancers
+func handleServiceLoadBalancer(ctx context.Context, client *azureclient.AzureClient, serviceTags *flowcheck.ServiceTagStore, params map[string]interface{}, subID, rg, clusterName string) (string, error) {
+	namespace, name, err := parseServiceReference(params)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	kubeClient, err := common.KubernetesClientForCluster(cluster)
+	if err != nil {
+		return "", err
+	}
+	service, err := kubeClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get service %s/%s: %v", namespace, name, err)
+	}
+
+	result := &ServiceLoadBalancerResult{}
+	input := servicelb.Input{Service: service}
+	input.EndpointNodes, input.EndpointsKnown, result.Notes = loadServiceEndpointNodes(ctx, kubeClient, service)
+
+	lbIDs, err := resourcehelpers.GetLoadBalancerIDsFromAKS(ctx, cluster, client)
+	if err != nil {
+		return "", fmt.Errorf("failed to find cluster load balancers: %v", err)
+	}
+	for _, lbID := range lbIDs {
+		lb, err := loadBalancerByID(ctx, client, lbID)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("could not load load balancer %s: %v", lbID, err))
+			continue
+		}
+		input.LoadBalancers = append(input.LoadBalancers, lb)
+	}
+	var notes []string
+	input.PublicIPs, notes = loadFrontendPublicIPs(ctx, client, input.LoadBalancers)
+	result.Notes = append(result.Notes, notes...)
+
+	result.Result = servicelb.Analyze(input)
+	if result.Frontend != nil {
+		notes = checkServiceNSGs(ctx, client, cluster, serviceTags, service, result)
+		result.Notes = append(result.Notes, notes...)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal service load balancer mapping to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// parseServiceReference reads the service as "<namespace>/<name>" or as a name with an optional namespace parameter
+func parseServiceReference(params map[string]interface{}) (string, string, error) {
+	service, _ := params["service"].(string)
+	service = strings.TrimSpace(service)
+	if service == "" {
+		return "", "", fmt.Errorf("missing required parameter 'service'")
+	}
+
+	namespace, _ := params["namespace"].(string)
+	namespace = strings.TrimSpace(namespace)
+	name := service
+	if before, after, found := strings.Cut(service, "/"); found {
+		if namespace != "" && namespace != before {
+			return "", "", fmt.Errorf("service '%s' conflicts with namespace '%s'", service, namespace)
+		}
+		namespace, name = before, after
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	if name == "" || namespace == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid service '%s', expected <namespace>/<name> or <name>", service)
+	}
+	return namespace, name, nil
+}
+
+// loadServiceEndpointNodes returns the nodes hosting ready endpoints of the service
+func loadServiceEndpointNodes(ctx context.Context, kubeClient kubernetes.Interface, service *corev1.Service) ([]servicelb.Node, bool, []string) {
+	endpointSlices, err := kubeClient.DiscoveryV1().EndpointSlices(service.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "kubernetes.io/service-name=" + service.Name,
+	})
+	if err != nil {
+		return nil, false, []string{fmt.Sprintf("could not list endpoint slices: %v", err)}
+	}
+
+	var names []string
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			if ready && endpoint.NodeName != nil && !slices.Contains(names, *endpoint.NodeName) {
+				names = append(names, *endpoint.NodeName)
+			}
+		}
+	}
+
+	var nodes []servicelb.Node
+	var notes []string
+	for _, name := range names {
+		node, err := kubeClient.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("could not get node %s: %v", name, err))
+			continue
+		}
+		nodes = append(nodes, nodeSummary(node))
+	}
+	return nodes, true, notes
+}
+
+// nodeSummary returns the name, provider ID and internal IP of a node
+func nodeSummary(node *corev1.Node) servicelb.Node {
+	summary := servicelb.Node{Name: node.Name, ProviderID: node.Spec.ProviderID}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			summary.InternalIP = address.Address
+			break
+		}
+	}
+	return summary
+}
+
+// loadBalancerByID loads a load balancer by resource ID
+func loadBalancerByID(ctx context.Context, client *azureclient.AzureClient, lbID string) (*armnetwork.LoadBalancer, error) {
+	parsed, err := arm.ParseResourceID(lbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse load balancer ID: %v", err)
+	}
+	return client.GetLoadBalancer(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+}
+
+// loadFrontendPublicIPs returns the addresses of the public IPs of the load balancer frontends, keyed by lower case ID
+func loadFrontendPublicIPs(ctx context.Context, client *azureclient.AzureClient, lbs []*armnetwork.LoadBalancer) (map[string]string, []string) {
+	addresses := make(map[string]string)
+	var notes []string
+	for _, lb := range lbs {
+		if lb.Properties == nil {
+			continue
+		}
+		for _, config := range lb.Properties.FrontendIPConfigurations {
+			if config == nil || config.Properties == nil || config.Properties.PublicIPAddress == nil || config.Properties.PublicIPAddress.ID == nil {
+				continue
+			}
+			pipID := *config.Properties.PublicIPAddress.ID
+			parsed, err := arm.ParseResourceID(pipID)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("could not parse public IP ID %s: %v", pipID, err))
+				continue
+			}
+			pip, err := client.GetPublicIPAddress(ctx, parsed.SubscriptionID, parsed.ResourceGroupName, parsed.Name)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("could not load public IP %s: %v", pipID, err))
+				continue
+			}
+			if pip.Properties != nil && pip.Properties.IPAddress != nil {
+				addresses[strings.ToLower(pipID)] = *pip.Properties.IPAddress
+			}
+		}
+	}
+	return addresses, notes
+}
+
+// checkServiceNSGs evaluates the NSGs of the backing node pools for the load balanced traffic
+// and the health probes of every mapped port
+func checkServiceNSGs(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, serviceTags *flowcheck.ServiceTagStore, service *corev1.Service, result *ServiceLoadBalancerResult) []string {
+	nodePools := result.NodePools
+	if len(nodePools) == 0 {
+		nodePools = clusterNodePoolNames(cluster)
+	}
+
+	var notes []string
+	pools := make(map[string]*nodePoolFlowContext)
+	for _, name := range nodePools {
+		pool, err := loadNodePoolFlowContext(ctx, client, cluster, name)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("could not load network configuration of node pool %s: %v", name, err))
+			continue
+		}
+		pools[strings.ToLower(name)] = pool
+	}
+
+	sources := serviceTrafficSources(service, result.Service.Internal)
+	for _, name := range nodePools {
+		pool, ok := pools[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		// The VirtualNetwork tag depends on the VNet of the node pool being evaluated
+		resolver := flowcheck.NewResolver(pool.virtualNetwork, serviceTags)
+		nodes := nodePoolEndpointPrefix + pool.name
+
+		for _, port := range result.Ports {
+			if port.Rule == nil {
+				continue
+			}
+			protocol := flowcheck.ProtocolTCP
+			if strings.EqualFold(port.Protocol, string(corev1.ProtocolUDP)) {
+				protocol = flowcheck.ProtocolUDP
+			}
+
+			// With floating IP the packets keep the frontend IP as destination
+			destination := nodes
+			if port.Rule.FloatingIP && result.Frontend.IP != "" {
+				destination = result.Frontend.IP
+			}
+			for _, source := range sources {
+				check, err := evaluateServiceFlow(pools, resolver, pool, serviceNSGCheckTraffic, source, destination, protocol, port.Rule.BackendPort)
+				if err != nil {
+					notes = append(notes, fmt.Sprintf("could not evaluate traffic from %s: %v", source, err))
+					continue
+				}
+				result.NSGChecks = append(result.NSGChecks, check)
+				if check.Access == flowcheck.AccessDeny {
+					result.Findings.Add(common.SeverityError, "nsg_blocks_traffic",
+						fmt.Sprintf("The NSGs of node pool %s deny %s/%d from %s to %s", pool.name, protocol, port.Rule.BackendPort, source, destination))
+				}
+			}
+
+			if port.Probe == nil {
+				continue
+			}
+			check, err := evaluateServiceFlow(pools, resolver, pool, serviceNSGCheckProbe, flowcheck.TagAzureLoadBalancer, nodes, flowcheck.ProtocolTCP, port.Probe.Port)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("could not evaluate health probe traffic: %v", err))
+				continue
+			}
+			result.NSGChecks = append(result.NSGChecks, check)
+			if check.Access == flowcheck.AccessDeny {
+				result.Findings.Add(common.SeverityError, "nsg_blocks_probe",
+					fmt.Sprintf("The NSGs of node pool %s deny health probe %s on port %d from %s", pool.name, port.Probe.Name, port.Probe.Port, flowcheck.TagAzureLoadBalancer))
+			}
+		}
+	}
+	return notes
+}
+
+// evaluateServiceFlow evaluates an inbound flow to a node pool
+func evaluateServiceFlow(pools map[string]*nodePoolFlowContext, resolver *flowcheck.Resolver, pool *nodePoolFlowContext, purpose, source, destination, protocol string, port int32) (ServiceNSGCheck, error) {
+	check := ServiceNSGCheck{NodePool: pool.name, Purpose: purpose, Source: source, Destination: destination, Protocol: protocol, Port: port}
+	sourceEndpoint, err := resolveFlowEndpoint(source, pools, resolver)
+	if err != nil {
+		return check, err
+	}
+	destinationEndpoint, err := resolveFlowEndpoint(destination, pools, resolver)
+	if err != nil {
+		return check, err
+	}
+
+	flow := flowcheck.Flow{
+		Direction:       flowcheck.DirectionInbound,
+		Source:          sourceEndpoint,
+		Destination:     destinationEndpoint,
+		Protocol:        protocol,
+		SourcePort:      -1,
+		DestinationPort: int(port),
+	}
+	check.Result = flowcheck.Evaluate(flow, pool.subnetNSG, pool.nicNSG, resolver)
+	return check, nil
+}
+
+// serviceTrafficSources returns the sources allowed to reach the service: its load balancer
+// source ranges, else the allowed service tags and IP ranges annotations, else Internet for
+// public and VirtualNetwork for internal services
+func serviceTrafficSources(service *corev1.Service, internal bool) []string {
+	var sources []string
+	for _, value := range service.Spec.LoadBalancerSourceRanges {
+		if value = strings.TrimSpace(value); value != "" {
+			sources = append(sources, value)
+		}
+	}
+	if len(sources) == 0 {
+		for _, annotation := range []string{servicelb.AnnotationAllowedServiceTags, servicelb.AnnotationAllowedIPRanges} {
+			for _, value := range strings.Split(service.Annotations[annotation], ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					sources = append(sources, value)
+				}
+			}
+		}
+	}
+	if len(sources) > 0 {
+		return sources
+	}
+	if internal {
+		return []string{flowcheck.TagVirtualNetwork}
+	}
+	return []string{flowcheck.TagInternet}
+}
+
+// clusterNodePoolNames returns the names of the node pools of the cluster
+func clusterNodePoolNames(cluster *armcontainerservice.ManagedCluster) []string {
+	var names []string
+	if cluster.Properties == nil {
+		return names
+	}
+	for _, profile := range cluster.Properties.AgentPoolProfiles {
+		if profile != nil && profile.Name != nil && !slices.Contains(names, *profile.Name) {
+			names = append(names, *profile.Name)
+		}
+	}
+	return names
+}
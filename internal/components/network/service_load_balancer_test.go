@@ -0,0 +1,84 @@
+package network
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/components/network/flowcheck"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseServiceReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		namespace string
+		service   string
+		wantErr   bool
+	}{
+		{"qualified", map[string]interface{}{"service": "ingress/nginx"}, "ingress", "nginx", false},
+		{"namespace parameter", map[string]interface{}{"service": "nginx", "namespace": "ingress"}, "ingress", "nginx", false},
+		{"default namespace", map[string]interface{}{"service": "web"}, "default", "web", false},
+		{"missing", map[string]interface{}{}, "", "", true},
+		{"conflicting namespace", map[string]interface{}{"service": "ingress/nginx", "namespace": "other"}, "", "", true},
+		{"too many segments", map[string]interface{}{"service": "a/b/c"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace, service, err := parseServiceReference(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if namespace != tt.namespace || service != tt.service {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.namespace, tt.service, namespace, service)
+			}
+		})
+	}
+}
+
+func TestServiceTrafficSources(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+	if sources := serviceTrafficSources(service, false); !slices.Equal(sources, []string{flowcheck.TagInternet}) {
+		t.Errorf("Expected Internet for a public service, got %v", sources)
+	}
+	if sources := serviceTrafficSources(service, true); !slices.Equal(sources, []string{flowcheck.TagVirtualNetwork}) {
+		t.Errorf("Expected VirtualNetwork for an internal service, got %v", sources)
+	}
+
+	service.Annotations["service.beta.kubernetes.io/azure-allowed-service-tags"] = "AzureFrontDoor.Backend"
+	service.Annotations["service.beta.kubernetes.io/azure-allowed-ip-ranges"] = "203.0.113.0/24, 198.51.100.0/24"
+	expected := []string{"AzureFrontDoor.Backend", "203.0.113.0/24", "198.51.100.0/24"}
+	if sources := serviceTrafficSources(service, false); !slices.Equal(sources, expected) {
+		t.Errorf("Expected %v, got %v", expected, sources)
+	}
+
+	service.Spec.LoadBalancerSourceRanges = []string{"192.0.2.0/24"}
+	if sources := serviceTrafficSources(service, false); !slices.Equal(sources, []string{"192.0.2.0/24"}) {
+		t.Errorf("Expected the source ranges to take precedence, got %v", sources)
+	}
+}
+
+func TestNodeSummary(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "aks-nodepool1-12345678-vmss000000"},
+		Spec:       corev1.NodeSpec{ProviderID: "azure:///subscriptions/sub/resourceGroups/mc-rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss/virtualMachines/0"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: "aks-nodepool1-12345678-vmss000000"},
+			{Type: corev1.NodeInternalIP, Address: "10.224.0.4"},
+		}},
+	}
+	summary := nodeSummary(node)
+	if summary.Name != node.Name || summary.InternalIP != "10.224.0.4" || summary.ProviderID != node.Spec.ProviderID {
+		t.Errorf("Unexpected node summary: %+v", summary)
+	}
+}
+
+func TestHandleServiceLoadBalancerValidation(t *testing.T) {
+	_, err := handleServiceLoadBalancer(context.Background(), nil, flowcheck.NewServiceTagStore(""), map[string]interface{}{}, "sub", "rg", "aks")
+	if err == nil || !strings.Contains(err.Error(), "service") {
+		t.Errorf("Expected service validation error, got %v", err)
+	}
+}
@@ -0,0 +1,508 @@
+// Package servicelb maps a Kubernetes LoadBalancer service to the Azure load balancer frontend,
+// rules, health probes and backend pools the cloud provider created for it.
+package servicelb
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Service annotations of the Azure cloud provider
+const (
+	AnnotationInternal               = "service.beta.kubernetes.io/azure-load-balancer-internal"
+	AnnotationDisableFloatingIP      = "service.beta.kubernetes.io/azure-disable-load-balancer-floating-ip"
+	AnnotationAllowedServiceTags     = "service.beta.kubernetes.io/azure-allowed-service-tags"
+	AnnotationAllowedIPRanges        = "service.beta.kubernetes.io/azure-allowed-ip-ranges"
+	AnnotationHealthProbeProtocol    = "service.beta.kubernetes.io/azure-load-balancer-health-probe-protocol"
+	AnnotationHealthProbeRequestPath = "service.beta.kubernetes.io/azure-load-balancer-health-probe-request-path"
+
+	// Per port annotations are formatted with the service port
+	annotationPortHealthProbePort = "service.beta.kubernetes.io/port_%d_health-probe_port"
+	annotationPortNoProbeRule     = "service.beta.kubernetes.io/port_%d_no_probe_rule"
+	annotationPortNoLBRule        = "service.beta.kubernetes.io/port_%d_no_lb_rule"
+)
+
+// SharedProbePort is the kube-proxy health port probed by the shared health probe of services
+// with the Cluster traffic policy
+const SharedProbePort = 10256
+
+// Ways the frontend of a service is found
+const (
+	MatchedByIP   = "ip"
+	MatchedByName = "name"
+)
+
+// Node is a node that hosts ready endpoints of the service
+type Node struct {
+	Name       string
+	ProviderID string
+	InternalIP string
+}
+
+// Input holds the service and the load balancers of the node resource group
+type Input struct {
+	Service       *corev1.Service
+	LoadBalancers []*armnetwork.LoadBalancer
+	// PublicIPs maps lower case public IP resource IDs to their addresses
+	PublicIPs map[string]string
+	// EndpointNodes are the nodes with ready endpoints; EndpointsKnown is false when they could not be read
+	EndpointNodes  []Node
+	EndpointsKnown bool
+}
+
+// ServiceSummary describes the service fields that drive the load balancer configuration
+type ServiceSummary struct {
+	Namespace             string   `json:"namespace"`
+	Name                  string   `json:"name"`
+	Type                  string   `json:"type"`
+	Internal              bool     `json:"internal"`
+	IngressIPs            []string `json:"ingress_ips"`
+	ExternalTrafficPolicy string   `json:"external_traffic_policy,omitempty"`
+	HealthCheckNodePort   int32    `json:"health_check_node_port,omitempty"`
+	FloatingIP            bool     `json:"floating_ip"`
+	SourceRanges          []string `json:"source_ranges,omitempty"`
+	EndpointNodes         []string `json:"endpoint_nodes,omitempty"`
+}
+
+// Frontend is the frontend IP configuration of the service
+type Frontend struct {
+	LoadBalancer string `json:"load_balancer"`
+	Name         string `json:"name"`
+	ID           string `json:"id"`
+	IP           string `json:"ip,omitempty"`
+	MatchedBy    string `json:"matched_by"`
+}
+
+// Rule is a load balancing rule of a service port
+type Rule struct {
+	Name          string `json:"name"`
+	FrontendPort  int32  `json:"frontend_port"`
+	BackendPort   int32  `json:"backend_port"`
+	FloatingIP    bool   `json:"floating_ip"`
+	BackendPoolID string `json:"backend_pool_id,omitempty"`
+}
+
+// Probe is the health probe of a load balancing rule
+type Probe struct {
+	Name        string `json:"name"`
+	Protocol    string `json:"protocol"`
+	Port        int32  `json:"port"`
+	RequestPath string `json:"request_path,omitempty"`
+}
+
+// PortMapping maps a service port to its load balancing rule and health probe
+type PortMapping struct {
+	Name     string `json:"name,omitempty"`
+	Protocol string `json:"protocol"`
+	Port     int32  `json:"port"`
+	NodePort int32  `json:"node_port,omitempty"`
+	// TargetPort is the port traffic reaches on the nodes: the service port with floating IP, the node port without
+	TargetPort         int32   `json:"target_port"`
+	Rule               *Rule   `json:"rule,omitempty"`
+	Probe              *Probe  `json:"probe,omitempty"`
+	ExpectedProbePorts []int32 `json:"expected_probe_ports,omitempty"`
+}
+
+// BackendPool is a backend pool used by the rules of the service
+type BackendPool struct {
+	Name                 string   `json:"name"`
+	ID                   string   `json:"id"`
+	Members              int      `json:"members"`
+	ScaleSets            []string `json:"scale_sets,omitempty"`
+	MissingEndpointNodes []string `json:"missing_endpoint_nodes,omitempty"`
+}
+
+// Result is the mapping of a service to its load balancer configuration
+type Result struct {
+	Service      ServiceSummary `json:"service"`
+	Frontend     *Frontend      `json:"frontend,omitempty"`
+	Ports        []PortMapping  `json:"ports"`
+	BackendPools []BackendPool  `json:"backend_pools"`
+	// NodePools are the AKS node pools whose scale sets are members of the backend pools
+	NodePools []string        `json:"node_pools,omitempty"`
+	Findings  common.Findings `json:"findings,omitempty"`
+}
+
+// DefaultLoadBalancerName returns the base name the cloud provider gives to the frontend, rules
+// and probes of a service: "a" followed by the service UID without dashes, truncated to 32 characters
+func DefaultLoadBalancerName(uid string) string {
+	name := "a" + strings.ReplaceAll(uid, "-", "")
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// IsInternal reports whether the service requests an internal load balancer
+func IsInternal(service *corev1.Service) bool {
+	return strings.EqualFold(service.Annotations[AnnotationInternal], "true")
+}
+
+// FloatingIP reports whether the load balancing rules of the service use floating IP
+func FloatingIP(service *corev1.Service) bool {
+	return !strings.EqualFold(service.Annotations[AnnotationDisableFloatingIP], "true")
+}
+
+// Analyze maps the service to its frontend, rules, probes and backend pools and flags mismatches
+func Analyze(input Input) Result {
+	service := input.Service
+	result := Result{
+		Service:      summarizeService(service, input.EndpointNodes),
+		Ports:        []PortMapping{},
+		BackendPools: []BackendPool{},
+	}
+
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		result.Findings.Add(common.SeverityError, "not_load_balancer_service",
+			fmt.Sprintf("Service %s/%s is of type %s, not LoadBalancer", service.Namespace, service.Name, service.Spec.Type))
+		return result
+	}
+	if len(result.Service.IngressIPs) == 0 {
+		result.Findings.Add(common.SeverityWarning, "service_pending",
+			"The service has no load balancer IP yet; check the service events for cloud provider errors")
+	}
+	if input.EndpointsKnown && len(input.EndpointNodes) == 0 {
+		result.Findings.Add(common.SeverityWarning, "no_ready_endpoints",
+			"The service has no ready endpoints, so the load balancer has nothing to forward to")
+	}
+
+	lb, frontend := findFrontend(input, result.Service.IngressIPs, DefaultLoadBalancerName(string(service.UID)))
+	if frontend == nil {
+		kind := "public"
+		if result.Service.Internal {
+			kind = "internal"
+		}
+		result.Findings.Add(common.SeverityError, "frontend_missing",
+			fmt.Sprintf("No frontend IP configuration of the %s load balancers matches the service IP or name", kind))
+		return result
+	}
+	result.Frontend = frontend
+
+	poolIDs := []string{}
+	for _, port := range service.Spec.Ports {
+		mapping := result.mapPort(service, lb, frontend, port)
+		if mapping.Rule != nil && mapping.Rule.BackendPoolID != "" && !slices.ContainsFunc(poolIDs, func(id string) bool { return strings.EqualFold(id, mapping.Rule.BackendPoolID) }) {
+			poolIDs = append(poolIDs, mapping.Rule.BackendPoolID)
+		}
+		result.Ports = append(result.Ports, mapping)
+	}
+
+	for _, poolID := range poolIDs {
+		result.checkBackendPool(lb, poolID, input)
+	}
+	return result
+}
+
+// summarizeService extracts the fields of the service that drive the load balancer configuration
+func summarizeService(service *corev1.Service, endpointNodes []Node) ServiceSummary {
+	summary := ServiceSummary{
+		Namespace:             service.Namespace,
+		Name:                  service.Name,
+		Type:                  string(service.Spec.Type),
+		Internal:              IsInternal(service),
+		IngressIPs:            []string{},
+		ExternalTrafficPolicy: string(service.Spec.ExternalTrafficPolicy),
+		HealthCheckNodePort:   service.Spec.HealthCheckNodePort,
+		FloatingIP:            FloatingIP(service),
+		SourceRanges:          service.Spec.LoadBalancerSourceRanges,
+	}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			summary.IngressIPs = append(summary.IngressIPs, ingress.IP)
+		}
+	}
+	for _, node := range endpointNodes {
+		summary.EndpointNodes = append(summary.EndpointNodes, node.Name)
+	}
+	return summary
+}
+
+// findFrontend finds the frontend IP configuration of the service by its ingress IP, falling back
+// to the name the cloud provider derives from the service UID
+func findFrontend(input Input, ingressIPs []string, baseName string) (*armnetwork.LoadBalancer, *Frontend) {
+	var byName *Frontend
+	var byNameLB *armnetwork.LoadBalancer
+
+	for _, lb := range input.LoadBalancers {
+		if lb == nil || lb.Properties == nil {
+			continue
+		}
+		for _, config := range lb.Properties.FrontendIPConfigurations {
+			if config == nil || config.ID == nil || config.Name == nil {
+				continue
+			}
+			frontend := &Frontend{LoadBalancer: stringValue(lb.Name), Name: *config.Name, ID: *config.ID, IP: frontendAddress(config, input.PublicIPs)}
+			if frontend.IP != "" && slices.Contains(ingressIPs, frontend.IP) {
+				frontend.MatchedBy = MatchedByIP
+				return lb, frontend
+			}
+			if byName == nil && (strings.EqualFold(frontend.Name, baseName) || strings.HasPrefix(strings.ToLower(frontend.Name), strings.ToLower(baseName)+"-")) {
+				frontend.MatchedBy = MatchedByName
+				byName, byNameLB = frontend, lb
+			}
+		}
+	}
+	return byNameLB, byName
+}
+
+// frontendAddress returns the private IP of an internal frontend or the address of its public IP
+func frontendAddress(config *armnetwork.FrontendIPConfiguration, publicIPs map[string]string) string {
+	if config.Properties == nil {
+		return ""
+	}
+	if config.Properties.PrivateIPAddress != nil {
+		return *config.Properties.PrivateIPAddress
+	}
+	if config.Properties.PublicIPAddress != nil && config.Properties.PublicIPAddress.ID != nil {
+		return publicIPs[strings.ToLower(*config.Properties.PublicIPAddress.ID)]
+	}
+	return ""
+}
+
+// mapPort finds the load balancing rule and health probe of a service port
+func (r *Result) mapPort(service *corev1.Service, lb *armnetwork.LoadBalancer, frontend *Frontend, port corev1.ServicePort) PortMapping {
+	protocol := string(port.Protocol)
+	if protocol == "" {
+		protocol = string(corev1.ProtocolTCP)
+	}
+	mapping := PortMapping{Name: port.Name, Protocol: protocol, Port: port.Port, NodePort: port.NodePort, TargetPort: port.Port}
+	if !r.Service.FloatingIP {
+		mapping.TargetPort = port.NodePort
+	}
+	target := fmt.Sprintf("%s/%d", protocol, port.Port)
+
+	if strings.EqualFold(service.Annotations[fmt.Sprintf(annotationPortNoLBRule, port.Port)], "true") {
+		return mapping
+	}
+
+	rule := findRule(lb, frontend.ID, protocol, port.Port)
+	if rule == nil {
+		r.Findings.Add(common.SeverityError, "lb_rule_missing",
+			fmt.Sprintf("The load balancer has no rule for %s on frontend %s", target, frontend.Name))
+		return mapping
+	}
+	mapping.Rule = convertRule(rule)
+
+	if mapping.Rule.FloatingIP != r.Service.FloatingIP {
+		r.Findings.Add(common.SeverityWarning, "floating_ip_mismatch",
+			fmt.Sprintf("Rule %s has floating IP %v but the service annotations expect %v", mapping.Rule.Name, mapping.Rule.FloatingIP, r.Service.FloatingIP))
+	}
+	if !mapping.Rule.FloatingIP && port.NodePort != 0 && mapping.Rule.BackendPort != port.NodePort {
+		r.Findings.Add(common.SeverityError, "backend_port_mismatch",
+			fmt.Sprintf("Rule %s forwards to backend port %d but the node port of %s is %d", mapping.Rule.Name, mapping.Rule.BackendPort, target, port.NodePort))
+	}
+
+	if strings.EqualFold(service.Annotations[fmt.Sprintf(annotationPortNoProbeRule, port.Port)], "true") {
+		return mapping
+	}
+	mapping.ExpectedProbePorts = expectedProbePorts(service, port)
+	if rule.Properties.Probe == nil || rule.Properties.Probe.ID == nil {
+		r.Findings.Add(common.SeverityWarning, "probe_missing",
+			fmt.Sprintf("Rule %s has no health probe", mapping.Rule.Name))
+		return mapping
+	}
+	probe := findProbe(lb, *rule.Properties.Probe.ID)
+	if probe == nil {
+		r.Findings.Add(common.SeverityError, "probe_missing",
+			fmt.Sprintf("The health probe of rule %s does not exist", mapping.Rule.Name))
+		return mapping
+	}
+	mapping.Probe = probe
+
+	if len(mapping.ExpectedProbePorts) > 0 && !slices.Contains(mapping.ExpectedProbePorts, probe.Port) {
+		r.Findings.Add(common.SeverityError, "probe_port_mismatch",
+			fmt.Sprintf("Probe %s checks port %d but nothing serves health checks for %s there; expected one of %v", probe.Name, probe.Port, target, mapping.ExpectedProbePorts))
+	}
+	if service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal && !strings.EqualFold(probe.Protocol, string(armnetwork.ProbeProtocolHTTP)) {
+		r.Findings.Add(common.SeverityWarning, "probe_protocol_mismatch",
+			fmt.Sprintf("Probe %s uses %s but the Local traffic policy needs an HTTP probe on /healthz so only nodes with endpoints receive traffic", probe.Name, probe.Protocol))
+	}
+	return mapping
+}
+
+// expectedProbePorts returns the ports a health probe of the service port may check: the health
+// check node port for the Local traffic policy, otherwise the node port, the shared kube-proxy
+// health port or the port set with the health-probe_port annotation
+func expectedProbePorts(service *corev1.Service, port corev1.ServicePort) []int32 {
+	if service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal && service.Spec.HealthCheckNodePort != 0 {
+		return []int32{service.Spec.HealthCheckNodePort}
+	}
+
+	var ports []int32
+	if value := strings.TrimSpace(service.Annotations[fmt.Sprintf(annotationPortHealthProbePort, port.Port)]); value != "" {
+		if number, err := strconv.ParseInt(value, 10, 32); err == nil {
+			ports = append(ports, int32(number))
+		} else {
+			// The annotation can name another service port, whose node port is probed
+			for _, other := range service.Spec.Ports {
+				if other.Name == value && other.NodePort != 0 {
+					ports = append(ports, other.NodePort)
+				}
+			}
+		}
+		return ports
+	}
+	if port.NodePort != 0 {
+		ports = append(ports, port.NodePort)
+	}
+	return append(ports, SharedProbePort)
+}
+
+// findRule finds the load balancing rule of a frontend, protocol and port
+func findRule(lb *armnetwork.LoadBalancer, frontendID, protocol string, port int32) *armnetwork.LoadBalancingRule {
+	for _, rule := range lb.Properties.LoadBalancingRules {
+		if rule == nil || rule.Properties == nil || rule.Properties.FrontendIPConfiguration == nil || rule.Properties.FrontendIPConfiguration.ID == nil {
+			continue
+		}
+		if !strings.EqualFold(*rule.Properties.FrontendIPConfiguration.ID, frontendID) {
+			continue
+		}
+		ruleProtocol := ""
+		if rule.Properties.Protocol != nil {
+			ruleProtocol = string(*rule.Properties.Protocol)
+		}
+		// HA ports rules use protocol All and port 0
+		if ruleProtocol == string(armnetwork.TransportProtocolAll) && int32Value(rule.Properties.FrontendPort) == 0 {
+			return rule
+		}
+		if strings.EqualFold(ruleProtocol, protocol) && int32Value(rule.Properties.FrontendPort) == port {
+			return rule
+		}
+	}
+	return nil
+}
+
+// convertRule summarizes a load balancing rule
+func convertRule(rule *armnetwork.LoadBalancingRule) *Rule {
+	converted := &Rule{
+		Name:         stringValue(rule.Name),
+		FrontendPort: int32Value(rule.Properties.FrontendPort),
+		BackendPort:  int32Value(rule.Properties.BackendPort),
+		FloatingIP:   rule.Properties.EnableFloatingIP != nil && *rule.Properties.EnableFloatingIP,
+	}
+	if rule.Properties.BackendAddressPool != nil && rule.Properties.BackendAddressPool.ID != nil {
+		converted.BackendPoolID = *rule.Properties.BackendAddressPool.ID
+	} else if len(rule.Properties.BackendAddressPools) > 0 && rule.Properties.BackendAddressPools[0].ID != nil {
+		converted.BackendPoolID = *rule.Properties.BackendAddressPools[0].ID
+	}
+	return converted
+}
+
+// findProbe finds a health probe of the load balancer by ID
+func findProbe(lb *armnetwork.LoadBalancer, probeID string) *Probe {
+	for _, probe := range lb.Properties.Probes {
+		if probe == nil || probe.ID == nil || !strings.EqualFold(*probe.ID, probeID) || probe.Properties == nil {
+			continue
+		}
+		converted := &Probe{Name: stringValue(probe.Name), Port: int32Value(probe.Properties.Port), RequestPath: stringValue(probe.Properties.RequestPath)}
+		if probe.Properties.Protocol != nil {
+			converted.Protocol = string(*probe.Properties.Protocol)
+		}
+		return converted
+	}
+	return nil
+}
+
+// checkBackendPool reports the members of a backend pool and the endpoint nodes missing from it
+func (r *Result) checkBackendPool(lb *armnetwork.LoadBalancer, poolID string, input Input) {
+	var pool *armnetwork.BackendAddressPool
+	for _, candidate := range lb.Properties.BackendAddressPools {
+		if candidate != nil && candidate.ID != nil && strings.EqualFold(*candidate.ID, poolID) {
+			pool = candidate
+		}
+	}
+	if pool == nil || pool.Properties == nil {
+		r.Findings.Add(common.SeverityError, "backend_pool_missing", fmt.Sprintf("Backend pool %s does not exist", poolID))
+		return
+	}
+
+	summary := BackendPool{Name: stringValue(pool.Name), ID: poolID}
+	var memberIDs, memberIPs []string
+	for _, ipConfig := range pool.Properties.BackendIPConfigurations {
+		if ipConfig != nil && ipConfig.ID != nil {
+			memberIDs = append(memberIDs, strings.ToLower(*ipConfig.ID))
+			if scaleSet := scaleSetName(*ipConfig.ID); scaleSet != "" && !slices.Contains(summary.ScaleSets, scaleSet) {
+				summary.ScaleSets = append(summary.ScaleSets, scaleSet)
+			}
+		}
+	}
+	for _, address := range pool.Properties.LoadBalancerBackendAddresses {
+		if address != nil && address.Properties != nil && address.Properties.IPAddress != nil {
+			memberIPs = append(memberIPs, *address.Properties.IPAddress)
+		}
+	}
+	summary.Members = len(memberIDs) + len(memberIPs)
+	if summary.Members == 0 {
+		r.Findings.Add(common.SeverityError, "backend_pool_empty", fmt.Sprintf("Backend pool %s has no members", summary.Name))
+	}
+
+	for _, node := range input.EndpointNodes {
+		if summary.Members > 0 && !isPoolMember(node, memberIDs, memberIPs) {
+			summary.MissingEndpointNodes = append(summary.MissingEndpointNodes, node.Name)
+		}
+	}
+	if len(summary.MissingEndpointNodes) > 0 {
+		r.Findings.Add(common.SeverityError, "endpoint_node_not_in_pool",
+			fmt.Sprintf("Nodes %s host ready endpoints but are not in backend pool %s", strings.Join(summary.MissingEndpointNodes, ", "), summary.Name))
+	}
+
+	for _, scaleSet := range summary.ScaleSets {
+		if nodePool := NodePoolFromScaleSet(scaleSet); nodePool != "" && !slices.Contains(r.NodePools, nodePool) {
+			r.NodePools = append(r.NodePools, nodePool)
+		}
+	}
+	r.BackendPools = append(r.BackendPools, summary)
+}
+
+// isPoolMember reports whether a node is in the backend pool, by the VMSS instance of its provider
+// ID for NIC based pools or by its internal IP for IP based pools
+func isPoolMember(node Node, memberIDs, memberIPs []string) bool {
+	if node.InternalIP != "" && slices.Contains(memberIPs, node.InternalIP) {
+		return true
+	}
+	instance := strings.ToLower(strings.TrimPrefix(node.ProviderID, "azure://"))
+	if instance == "" {
+		return false
+	}
+	return slices.ContainsFunc(memberIDs, func(id string) bool { return strings.HasPrefix(id, instance+"/networkinterfaces/") })
+}
+
+// scaleSetName returns the scale set of a VMSS NIC IP configuration ID
+func scaleSetName(ipConfigID string) string {
+	segments := strings.Split(ipConfigID, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if strings.EqualFold(segments[i], "virtualMachineScaleSets") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// NodePoolFromScaleSet returns the node pool of an AKS scale set named aks-<pool>-<hash>-vmss
+func NodePoolFromScaleSet(scaleSet string) string {
+	parts := strings.Split(scaleSet, "-")
+	if len(parts) < 4 || parts[0] != "aks" || parts[len(parts)-1] != "vmss" {
+		return ""
+	}
+	return strings.Join(parts[1:len(parts)-2], "-")
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func int32Value(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,243 @@
+package servicelb
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testLB       = "/subscriptions/sub/resourceGroups/mc-rg/providers/Microsoft.Network/loadBalancers/kubernetes"
+	testUID      = "0a1b2c3d-4e5f-6789-abcd-ef0123456789"
+	testBase     = "a0a1b2c3d4e5f6789abcdef012345678"
+	testScaleSet = "/subscriptions/sub/resourceGroups/mc-rg/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss"
+	testPIP      = "/subscriptions/sub/resourceGroups/mc-rg/providers/Microsoft.Network/publicIPAddresses/kubernetes-" + testBase
+)
+
+func testService(policy corev1.ServiceExternalTrafficPolicy) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: testUID},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: policy,
+			Ports:                 []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, NodePort: 31080}},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "20.1.2.3"}}},
+		},
+	}
+}
+
+func testLoadBalancer(probePort int32, poolMembers ...string) *armnetwork.LoadBalancer {
+	var ipConfigs []*armnetwork.InterfaceIPConfiguration
+	for _, member := range poolMembers {
+		ipConfigs = append(ipConfigs, &armnetwork.InterfaceIPConfiguration{ID: to.Ptr(member)})
+	}
+	return &armnetwork.LoadBalancer{
+		Name: to.Ptr("kubernetes"),
+		ID:   to.Ptr(testLB),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{
+					ID:   to.Ptr(testLB + "/frontendIPConfigurations/" + testBase),
+					Name: to.Ptr(testBase),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+						PublicIPAddress: &armnetwork.PublicIPAddress{ID: to.Ptr(testPIP)},
+					},
+				},
+			},
+			LoadBalancingRules: []*armnetwork.LoadBalancingRule{
+				{
+					Name: to.Ptr(testBase + "-TCP-80"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: to.Ptr(testLB + "/frontendIPConfigurations/" + testBase)},
+						BackendAddressPool:      &armnetwork.SubResource{ID: to.Ptr(testLB + "/backendAddressPools/kubernetes")},
+						Probe:                   &armnetwork.SubResource{ID: to.Ptr(testLB + "/probes/" + testBase + "-TCP-80")},
+						Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+						FrontendPort:            to.Ptr[int32](80),
+						BackendPort:             to.Ptr[int32](80),
+						EnableFloatingIP:        to.Ptr(true),
+					},
+				},
+			},
+			Probes: []*armnetwork.Probe{
+				{
+					ID:   to.Ptr(testLB + "/probes/" + testBase + "-TCP-80"),
+					Name: to.Ptr(testBase + "-TCP-80"),
+					Properties: &armnetwork.ProbePropertiesFormat{
+						Protocol: to.Ptr(armnetwork.ProbeProtocolTCP),
+						Port:     to.Ptr(probePort),
+					},
+				},
+			},
+			BackendAddressPools: []*armnetwork.BackendAddressPool{
+				{
+					ID:         to.Ptr(testLB + "/backendAddressPools/kubernetes"),
+					Name:       to.Ptr("kubernetes"),
+					Properties: &armnetwork.BackendAddressPoolPropertiesFormat{BackendIPConfigurations: ipConfigs},
+				},
+			},
+		},
+	}
+}
+
+func TestAnalyzeHealthyService(t *testing.T) {
+	member := testScaleSet + "/virtualMachines/0/networkInterfaces/nic/ipConfigurations/ipconfig1"
+	result := Analyze(Input{
+		Service:        testService(corev1.ServiceExternalTrafficPolicyCluster),
+		LoadBalancers:  []*armnetwork.LoadBalancer{testLoadBalancer(31080, member)},
+		PublicIPs:      map[string]string{strings.ToLower(testPIP): "20.1.2.3"},
+		EndpointNodes:  []Node{{Name: "aks-nodepool1-12345678-vmss000000", ProviderID: "azure://" + testScaleSet + "/virtualMachines/0"}},
+		EndpointsKnown: true,
+	})
+
+	if len(result.Findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", result.Findings)
+	}
+	if result.Frontend == nil || result.Frontend.MatchedBy != MatchedByIP || result.Frontend.IP != "20.1.2.3" {
+		t.Fatalf("Expected the frontend matched by IP, got %+v", result.Frontend)
+	}
+	if len(result.Ports) != 1 || result.Ports[0].Rule == nil || result.Ports[0].Probe == nil || result.Ports[0].TargetPort != 80 {
+		t.Errorf("Unexpected port mapping: %+v", result.Ports)
+	}
+	if len(result.BackendPools) != 1 || result.BackendPools[0].Members != 1 {
+		t.Errorf("Unexpected backend pools: %+v", result.BackendPools)
+	}
+	if !slices.Equal(result.NodePools, []string{"nodepool1"}) {
+		t.Errorf("Expected node pool nodepool1, got %v", result.NodePools)
+	}
+}
+
+func TestAnalyzeMismatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  func() *corev1.Service
+		lb       func() *armnetwork.LoadBalancer
+		nodes    []Node
+		expected []string
+	}{
+		{
+			name:     "not a load balancer",
+			service:  func() *corev1.Service { s := testService(""); s.Spec.Type = corev1.ServiceTypeClusterIP; return s },
+			lb:       func() *armnetwork.LoadBalancer { return testLoadBalancer(31080) },
+			expected: []string{"not_load_balancer_service"},
+		},
+		{
+			name: "missing frontend",
+			service: func() *corev1.Service {
+				s := testService("")
+				s.UID = "ffffffff-0000-0000-0000-000000000000"
+				s.Status.LoadBalancer.Ingress = nil
+				return s
+			},
+			lb:       func() *armnetwork.LoadBalancer { return testLoadBalancer(31080) },
+			expected: []string{"service_pending", "frontend_missing"},
+		},
+		{
+			name: "missing rule",
+			service: func() *corev1.Service {
+				s := testService("")
+				s.Spec.Ports[0].Port = 443
+				return s
+			},
+			lb:       func() *armnetwork.LoadBalancer { return testLoadBalancer(31080) },
+			expected: []string{"lb_rule_missing"},
+		},
+		{
+			name:     "probe port without health server",
+			service:  func() *corev1.Service { return testService(corev1.ServiceExternalTrafficPolicyCluster) },
+			lb:       func() *armnetwork.LoadBalancer { return testLoadBalancer(8080) },
+			expected: []string{"probe_port_mismatch", "backend_pool_empty"},
+		},
+		{
+			name: "local policy probe",
+			service: func() *corev1.Service {
+				s := testService(corev1.ServiceExternalTrafficPolicyLocal)
+				s.Spec.HealthCheckNodePort = 32000
+				return s
+			},
+			lb:       func() *armnetwork.LoadBalancer { return testLoadBalancer(31080) },
+			expected: []string{"probe_port_mismatch", "probe_protocol_mismatch", "backend_pool_empty"},
+		},
+		{
+			name: "floating IP disabled",
+			service: func() *corev1.Service {
+				s := testService("")
+				s.Annotations = map[string]string{AnnotationDisableFloatingIP: "true"}
+				return s
+			},
+			lb:       func() *armnetwork.LoadBalancer { return testLoadBalancer(31080) },
+			expected: []string{"floating_ip_mismatch", "backend_pool_empty"},
+		},
+		{
+			name:    "endpoint node outside the pool",
+			service: func() *corev1.Service { return testService("") },
+			lb: func() *armnetwork.LoadBalancer {
+				return testLoadBalancer(31080, testScaleSet+"/virtualMachines/1/networkInterfaces/nic/ipConfigurations/ipconfig1")
+			},
+			nodes:    []Node{{Name: "node0", ProviderID: "azure://" + testScaleSet + "/virtualMachines/0"}},
+			expected: []string{"endpoint_node_not_in_pool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Analyze(Input{
+				Service:        tt.service(),
+				LoadBalancers:  []*armnetwork.LoadBalancer{tt.lb()},
+				PublicIPs:      map[string]string{strings.ToLower(testPIP): "20.1.2.3"},
+				EndpointNodes:  tt.nodes,
+				EndpointsKnown: tt.nodes != nil,
+			})
+			if codes := result.Findings.Codes(); !slices.Equal(codes, tt.expected) {
+				t.Errorf("Expected findings %v, got %v", tt.expected, codes)
+			}
+		})
+	}
+}
+
+func TestAnalyzeMatchesFrontendByName(t *testing.T) {
+	service := testService("")
+	service.Status.LoadBalancer.Ingress = nil
+	result := Analyze(Input{Service: service, LoadBalancers: []*armnetwork.LoadBalancer{testLoadBalancer(31080)}})
+	if result.Frontend == nil || result.Frontend.MatchedBy != MatchedByName {
+		t.Errorf("Expected the frontend matched by name, got %+v", result.Frontend)
+	}
+}
+
+func TestExpectedProbePortsAnnotation(t *testing.T) {
+	service := testService("")
+	service.Annotations = map[string]string{"service.beta.kubernetes.io/port_80_health-probe_port": "8080"}
+	if ports := expectedProbePorts(service, service.Spec.Ports[0]); !slices.Equal(ports, []int32{8080}) {
+		t.Errorf("Expected the annotated probe port, got %v", ports)
+	}
+
+	service.Annotations["service.beta.kubernetes.io/port_80_health-probe_port"] = "http"
+	if ports := expectedProbePorts(service, service.Spec.Ports[0]); !slices.Equal(ports, []int32{31080}) {
+		t.Errorf("Expected the node port of the named port, got %v", ports)
+	}
+}
+
+func TestNodePoolFromScaleSet(t *testing.T) {
+	tests := map[string]string{
+		"aks-nodepool1-12345678-vmss": "nodepool1",
+		"aks-user-pool-12345678-vmss": "user-pool",
+		"custom-vmss":                 "",
+	}
+	for scaleSet, expected := range tests {
+		if nodePool := NodePoolFromScaleSet(scaleSet); nodePool != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, scaleSet, nodePool)
+		}
+	}
+}
+
+func TestDefaultLoadBalancerName(t *testing.T) {
+	if name := DefaultLoadBalancerName(testUID); name != testBase {
+		t.Errorf("Expected %s, got %s", testBase, name)
+	}
+}
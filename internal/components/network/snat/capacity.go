@@ -0,0 +1,263 @@
+// Package snat computes the outbound SNAT port capacity of AKS load balancer and NAT gateway egress.
+package snat
+
+import (
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/components/common"
+)
+
+const (
+	// PortsPerLoadBalancerIP is the number of SNAT ports a load balancer frontend IP provides
+	PortsPerLoadBalancerIP = 64000
+	// PortsPerNATGatewayIP is the number of SNAT ports a NAT gateway public IP provides
+	PortsPerNATGatewayIP = 64512
+	// MaxNATGatewayIPs is the maximum number of public IPs a NAT gateway supports
+	MaxNATGatewayIPs = 16
+	// MaxManagedOutboundIPs is the maximum number of AKS managed outbound IPs on the load balancer
+	MaxManagedOutboundIPs = 100
+	// MaxAllocatedPorts is the maximum number of ports an outbound rule can allocate per instance
+	MaxAllocatedPorts = 64000
+	// PortGranularity is the multiple allocated outbound ports must be set to
+	PortGranularity = 8
+	// RecommendedPortsPerNode is the per-node port allocation below which SNAT exhaustion becomes likely
+	RecommendedPortsPerNode = 1024
+	// DefaultNATGatewayIdleTimeout is the default NAT gateway TCP idle timeout in minutes
+	DefaultNATGatewayIdleTimeout = 4
+)
+
+// defaultAllocationTiers is Azure's default SNAT port allocation per backend pool size
+// for a load balancer with a single frontend IP
+var defaultAllocationTiers = []struct {
+	maxPoolSize int
+	ports       int
+}{
+	{50, 1024},
+	{100, 512},
+	{200, 256},
+	{400, 128},
+	{800, 64},
+	{1000, 32},
+}
+
+// Recommendation is a configuration change that provides enough ports for the target node count
+type Recommendation struct {
+	Setting string `json:"setting"`
+	Value   int    `json:"value"`
+	Command string `json:"command,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// LoadBalancerInput describes the outbound rule configuration of the cluster load balancer
+type LoadBalancerInput struct {
+	// FrontendIPs is the number of public IPs used by the outbound rule, including IPs of prefixes
+	FrontendIPs int
+	// AllocatedPorts is the allocated outbound ports per instance, 0 for Azure's default allocation
+	AllocatedPorts int
+	IdleTimeout    int
+	CurrentNodes   int
+	TargetNodes    int
+}
+
+// LoadBalancerCapacity is the SNAT port capacity of the load balancer outbound rule
+type LoadBalancerCapacity struct {
+	FrontendIPs          int              `json:"frontend_ips"`
+	TotalPorts           int              `json:"total_ports"`
+	DefaultAllocation    bool             `json:"default_allocation"`
+	PortsPerNode         int              `json:"ports_per_node"`
+	PortsPerNodeAtTarget int              `json:"ports_per_node_at_target"`
+	MaxNodes             int              `json:"max_nodes"`
+	CurrentNodes         int              `json:"current_nodes"`
+	TargetNodes          int              `json:"target_nodes"`
+	Headroom             int              `json:"headroom"`
+	IdleTimeoutMinutes   int              `json:"idle_timeout_minutes,omitempty"`
+	Recommendations      []Recommendation `json:"recommendations,omitempty"`
+	Findings             common.Findings  `json:"findings,omitempty"`
+}
+
+// NATGatewayInput describes a NAT gateway and the nodes whose subnets use it
+type NATGatewayInput struct {
+	// PublicIPs is the number of public IPs of the NAT gateway, including IPs of prefixes
+	PublicIPs    int
+	IdleTimeout  int
+	CurrentNodes int
+	TargetNodes  int
+}
+
+// NATGatewayCapacity is the SNAT port capacity of a NAT gateway
+type NATGatewayCapacity struct {
+	PublicIPs            int              `json:"public_ips"`
+	TotalPorts           int              `json:"total_ports"`
+	PortsPerNode         int              `json:"ports_per_node"`
+	PortsPerNodeAtTarget int              `json:"ports_per_node_at_target"`
+	MaxNodes             int              `json:"max_nodes"`
+	CurrentNodes         int              `json:"current_nodes"`
+	TargetNodes          int              `json:"target_nodes"`
+	Headroom             int              `json:"headroom"`
+	IdleTimeoutMinutes   int              `json:"idle_timeout_minutes"`
+	Recommendations      []Recommendation `json:"recommendations,omitempty"`
+	Findings             common.Findings  `json:"findings,omitempty"`
+}
+
+// DefaultAllocatedPorts returns the ports Azure allocates per instance with default allocation,
+// or 0 when the backend pool is too large for default allocation
+func DefaultAllocatedPorts(poolSize int) int {
+	for _, tier := range defaultAllocationTiers {
+		if poolSize <= tier.maxPoolSize {
+			return tier.ports
+		}
+	}
+	return 0
+}
+
+// AnalyzeLoadBalancer computes the node capacity of the load balancer outbound rule and the
+// IP or port changes required to reach the target node count
+func AnalyzeLoadBalancer(input LoadBalancerInput) LoadBalancerCapacity {
+	capacity := LoadBalancerCapacity{
+		FrontendIPs:        input.FrontendIPs,
+		TotalPorts:         input.FrontendIPs * PortsPerLoadBalancerIP,
+		DefaultAllocation:  input.AllocatedPorts == 0,
+		CurrentNodes:       input.CurrentNodes,
+		TargetNodes:        input.TargetNodes,
+		IdleTimeoutMinutes: input.IdleTimeout,
+	}
+	if input.FrontendIPs == 0 {
+		capacity.Findings.Add(common.SeverityError, "no_outbound_ips", "The outbound rule has no frontend IPs, so nodes have no SNAT ports")
+		return capacity
+	}
+
+	if capacity.DefaultAllocation {
+		analyzeDefaultAllocation(&capacity)
+	} else {
+		capacity.PortsPerNode = input.AllocatedPorts
+		capacity.PortsPerNodeAtTarget = input.AllocatedPorts
+		capacity.MaxNodes = capacity.TotalPorts / input.AllocatedPorts
+	}
+	capacity.Headroom = capacity.MaxNodes - input.CurrentNodes
+
+	switch {
+	case input.CurrentNodes > capacity.MaxNodes:
+		capacity.Findings.Add(common.SeverityError, "capacity_exceeded",
+			fmt.Sprintf("%d nodes exceed the %d nodes the outbound rule can allocate ports for; scale and upgrade operations will fail", input.CurrentNodes, capacity.MaxNodes))
+	case input.TargetNodes > capacity.MaxNodes:
+		capacity.Findings.Add(common.SeverityWarning, "target_exceeds_capacity",
+			fmt.Sprintf("The target of %d nodes exceeds the %d nodes the outbound rule can allocate ports for", input.TargetNodes, capacity.MaxNodes))
+	}
+	if capacity.PortsPerNodeAtTarget > 0 && capacity.PortsPerNodeAtTarget < RecommendedPortsPerNode {
+		capacity.Findings.Add(common.SeverityWarning, "low_ports_per_node",
+			fmt.Sprintf("Nodes get %d ports at the target node count, below the recommended %d; workloads with many outbound connections may exhaust SNAT ports", capacity.PortsPerNodeAtTarget, RecommendedPortsPerNode))
+	}
+
+	if input.TargetNodes > 0 && (input.TargetNodes > capacity.MaxNodes || capacity.PortsPerNodeAtTarget < RecommendedPortsPerNode) {
+		capacity.recommend(input)
+	}
+	return capacity
+}
+
+// analyzeDefaultAllocation applies Azure's default port allocation, which is based on the backend
+// pool size and only uses the ports of a single frontend IP
+func analyzeDefaultAllocation(capacity *LoadBalancerCapacity) {
+	capacity.PortsPerNode = DefaultAllocatedPorts(capacity.CurrentNodes)
+	capacity.PortsPerNodeAtTarget = DefaultAllocatedPorts(capacity.TargetNodes)
+	capacity.MaxNodes = defaultAllocationTiers[len(defaultAllocationTiers)-1].maxPoolSize
+
+	capacity.Findings.Add(common.SeverityInfo, "default_port_allocation",
+		"The outbound rule uses default port allocation, so ports per node shrink as the cluster grows")
+	if capacity.FrontendIPs > 1 {
+		capacity.Findings.Add(common.SeverityWarning, "default_allocation_unused_ips",
+			fmt.Sprintf("Default port allocation does not use the ports of the additional %d frontend IPs; set allocated outbound ports explicitly", capacity.FrontendIPs-1))
+	}
+}
+
+// recommend adds the IP count or per-node port changes that provide ports for the target node count
+func (c *LoadBalancerCapacity) recommend(input LoadBalancerInput) {
+	ports := input.AllocatedPorts
+	if ports == 0 || ports < RecommendedPortsPerNode {
+		ports = RecommendedPortsPerNode
+	}
+
+	ipsNeeded := ceilDiv(input.TargetNodes*ports, PortsPerLoadBalancerIP)
+	if ipsNeeded <= MaxManagedOutboundIPs {
+		c.Recommendations = append(c.Recommendations, Recommendation{
+			Setting: "managed_outbound_ip_count",
+			Value:   ipsNeeded,
+			Command: fmt.Sprintf("az aks update --load-balancer-managed-outbound-ip-count %d --load-balancer-outbound-ports %d", ipsNeeded, ports),
+			Reason:  fmt.Sprintf("%d frontend IPs provide %d ports for each of %d nodes", ipsNeeded, ports, input.TargetNodes),
+		})
+	} else {
+		c.Findings.Add(common.SeverityWarning, "too_many_ips_needed",
+			fmt.Sprintf("%d frontend IPs would be needed, more than the %d managed outbound IPs supported; consider a NAT gateway", ipsNeeded, MaxManagedOutboundIPs))
+	}
+
+	portsPerNode := min(c.TotalPorts/input.TargetNodes/PortGranularity*PortGranularity, MaxAllocatedPorts)
+	if portsPerNode >= PortGranularity && portsPerNode != input.AllocatedPorts {
+		reason := fmt.Sprintf("The current %d frontend IPs provide %d ports for each of %d nodes", c.FrontendIPs, portsPerNode, input.TargetNodes)
+		if portsPerNode < RecommendedPortsPerNode {
+			reason += fmt.Sprintf(", below the recommended %d", RecommendedPortsPerNode)
+		}
+		c.Recommendations = append(c.Recommendations, Recommendation{
+			Setting: "allocated_outbound_ports",
+			Value:   portsPerNode,
+			Command: fmt.Sprintf("az aks update --load-balancer-outbound-ports %d", portsPerNode),
+			Reason:  reason,
+		})
+	}
+}
+
+// AnalyzeNATGateway computes the SNAT port capacity of a NAT gateway. NAT gateway allocates ports
+// on demand, so the node capacity is the number of nodes that can each use the recommended ports.
+func AnalyzeNATGateway(input NATGatewayInput) NATGatewayCapacity {
+	capacity := NATGatewayCapacity{
+		PublicIPs:          input.PublicIPs,
+		TotalPorts:         input.PublicIPs * PortsPerNATGatewayIP,
+		CurrentNodes:       input.CurrentNodes,
+		TargetNodes:        input.TargetNodes,
+		IdleTimeoutMinutes: input.IdleTimeout,
+	}
+	if capacity.IdleTimeoutMinutes == 0 {
+		capacity.IdleTimeoutMinutes = DefaultNATGatewayIdleTimeout
+	}
+	if input.PublicIPs == 0 {
+		capacity.Findings.Add(common.SeverityError, "no_outbound_ips", "The NAT gateway has no public IPs or prefixes, so nodes have no outbound connectivity")
+		return capacity
+	}
+
+	capacity.PortsPerNode = perNode(capacity.TotalPorts, input.CurrentNodes)
+	capacity.PortsPerNodeAtTarget = perNode(capacity.TotalPorts, input.TargetNodes)
+	capacity.MaxNodes = capacity.TotalPorts / RecommendedPortsPerNode
+	capacity.Headroom = capacity.MaxNodes - input.CurrentNodes
+
+	if input.TargetNodes > capacity.MaxNodes {
+		ipsNeeded := ceilDiv(input.TargetNodes*RecommendedPortsPerNode, PortsPerNATGatewayIP)
+		if ipsNeeded <= MaxNATGatewayIPs {
+			capacity.Recommendations = append(capacity.Recommendations, Recommendation{
+				Setting: "nat_gateway_public_ips",
+				Value:   ipsNeeded,
+				Command: fmt.Sprintf("az aks update --nat-gateway-managed-outbound-ip-count %d", ipsNeeded),
+				Reason:  fmt.Sprintf("%d public IPs provide %d ports for each of %d nodes", ipsNeeded, RecommendedPortsPerNode, input.TargetNodes),
+			})
+		} else {
+			capacity.Findings.Add(common.SeverityWarning, "too_many_ips_needed",
+				fmt.Sprintf("%d public IPs would be needed for %d ports per node, more than the %d a NAT gateway supports; split node pools across subnets with separate NAT gateways", ipsNeeded, RecommendedPortsPerNode, MaxNATGatewayIPs))
+		}
+		capacity.Findings.Add(common.SeverityWarning, "target_exceeds_capacity",
+			fmt.Sprintf("At %d nodes each node averages %d ports, below the recommended %d", input.TargetNodes, capacity.PortsPerNodeAtTarget, RecommendedPortsPerNode))
+	}
+
+	if capacity.IdleTimeoutMinutes > DefaultNATGatewayIdleTimeout {
+		capacity.Findings.Add(common.SeverityInfo, "long_idle_timeout",
+			fmt.Sprintf("The idle timeout of %d minutes holds ports of idle connections longer than the default %d minutes", capacity.IdleTimeoutMinutes, DefaultNATGatewayIdleTimeout))
+	}
+	return capacity
+}
+
+func perNode(totalPorts, nodes int) int {
+	if nodes <= 0 {
+		return totalPorts
+	}
+	return totalPorts / nodes
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
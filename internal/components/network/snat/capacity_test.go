@@ -0,0 +1,143 @@
+package snat
+
+import "testing"
+
+func recommendation(recommendations []Recommendation, setting string) (Recommendation, bool) {
+	for _, r := range recommendations {
+		if r.Setting == setting {
+			return r, true
+		}
+	}
+	return Recommendation{}, false
+}
+
+func TestDefaultAllocatedPorts(t *testing.T) {
+	tests := []struct {
+		poolSize int
+		expected int
+	}{
+		{1, 1024},
+		{50, 1024},
+		{51, 512},
+		{200, 256},
+		{400, 128},
+		{800, 64},
+		{1000, 32},
+		{1001, 0},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultAllocatedPorts(tt.poolSize); got != tt.expected {
+			t.Errorf("Expected %d ports for pool size %d, got %d", tt.expected, tt.poolSize, got)
+		}
+	}
+}
+
+func TestAnalyzeLoadBalancerExplicitAllocation(t *testing.T) {
+	capacity := AnalyzeLoadBalancer(LoadBalancerInput{FrontendIPs: 2, AllocatedPorts: 4000, CurrentNodes: 20, TargetNodes: 40})
+
+	if capacity.TotalPorts != 128000 || capacity.MaxNodes != 32 || capacity.Headroom != 12 {
+		t.Errorf("Expected 128000 ports for 32 nodes with headroom 12, got %+v", capacity)
+	}
+	if !capacity.Findings.Has("target_exceeds_capacity") {
+		t.Errorf("Expected target_exceeds_capacity finding, got %+v", capacity.Findings)
+	}
+
+	ips, ok := recommendation(capacity.Recommendations, "managed_outbound_ip_count")
+	if !ok || ips.Value != 3 {
+		t.Errorf("Expected 3 outbound IPs to be recommended, got %+v", capacity.Recommendations)
+	}
+	ports, ok := recommendation(capacity.Recommendations, "allocated_outbound_ports")
+	if !ok || ports.Value != 3200 {
+		t.Errorf("Expected 3200 ports per node to be recommended, got %+v", capacity.Recommendations)
+	}
+}
+
+func TestAnalyzeLoadBalancerWithinCapacity(t *testing.T) {
+	capacity := AnalyzeLoadBalancer(LoadBalancerInput{FrontendIPs: 1, AllocatedPorts: 1024, CurrentNodes: 10, TargetNodes: 60})
+
+	if capacity.MaxNodes != 62 || capacity.Headroom != 52 {
+		t.Errorf("Expected 62 max nodes and headroom 52, got %+v", capacity)
+	}
+	if len(capacity.Findings) != 0 || len(capacity.Recommendations) != 0 {
+		t.Errorf("Expected no findings or recommendations, got %+v %+v", capacity.Findings, capacity.Recommendations)
+	}
+}
+
+func TestAnalyzeLoadBalancerCapacityExceeded(t *testing.T) {
+	capacity := AnalyzeLoadBalancer(LoadBalancerInput{FrontendIPs: 1, AllocatedPorts: 8000, CurrentNodes: 10, TargetNodes: 10})
+
+	if capacity.Headroom != -2 || !capacity.Findings.Has("capacity_exceeded") {
+		t.Errorf("Expected capacity_exceeded with headroom -2, got %+v", capacity)
+	}
+	ports, ok := recommendation(capacity.Recommendations, "allocated_outbound_ports")
+	if !ok || ports.Value != 6400 {
+		t.Errorf("Expected 6400 ports per node to be recommended, got %+v", capacity.Recommendations)
+	}
+}
+
+func TestAnalyzeLoadBalancerDefaultAllocation(t *testing.T) {
+	capacity := AnalyzeLoadBalancer(LoadBalancerInput{FrontendIPs: 2, CurrentNodes: 30, TargetNodes: 120})
+
+	if !capacity.DefaultAllocation || capacity.PortsPerNode != 1024 || capacity.PortsPerNodeAtTarget != 256 {
+		t.Errorf("Expected default allocation of 1024 then 256 ports, got %+v", capacity)
+	}
+	for _, code := range []string{"default_port_allocation", "default_allocation_unused_ips", "low_ports_per_node"} {
+		if !capacity.Findings.Has(code) {
+			t.Errorf("Expected %s finding, got %+v", code, capacity.Findings)
+		}
+	}
+
+	ips, ok := recommendation(capacity.Recommendations, "managed_outbound_ip_count")
+	if !ok || ips.Value != 2 {
+		t.Errorf("Expected 2 outbound IPs with explicit allocation, got %+v", capacity.Recommendations)
+	}
+	ports, ok := recommendation(capacity.Recommendations, "allocated_outbound_ports")
+	if !ok || ports.Value != 1064 {
+		t.Errorf("Expected 1064 ports per node with the current IPs, got %+v", capacity.Recommendations)
+	}
+}
+
+func TestAnalyzeLoadBalancerWithoutIPs(t *testing.T) {
+	capacity := AnalyzeLoadBalancer(LoadBalancerInput{CurrentNodes: 3, TargetNodes: 3})
+
+	if !capacity.Findings.Has("no_outbound_ips") || len(capacity.Recommendations) != 0 {
+		t.Errorf("Expected only a no_outbound_ips finding, got %+v", capacity)
+	}
+}
+
+func TestAnalyzeNATGateway(t *testing.T) {
+	capacity := AnalyzeNATGateway(NATGatewayInput{PublicIPs: 1, CurrentNodes: 10, TargetNodes: 100, IdleTimeout: 30})
+
+	if capacity.TotalPorts != 64512 || capacity.MaxNodes != 63 || capacity.Headroom != 53 {
+		t.Errorf("Expected 64512 ports for 63 nodes, got %+v", capacity)
+	}
+	if capacity.PortsPerNode != 6451 || capacity.PortsPerNodeAtTarget != 645 {
+		t.Errorf("Expected 6451 and 645 ports per node, got %+v", capacity)
+	}
+	ips, ok := recommendation(capacity.Recommendations, "nat_gateway_public_ips")
+	if !ok || ips.Value != 2 {
+		t.Errorf("Expected 2 public IPs to be recommended, got %+v", capacity.Recommendations)
+	}
+	for _, code := range []string{"target_exceeds_capacity", "long_idle_timeout"} {
+		if !capacity.Findings.Has(code) {
+			t.Errorf("Expected %s finding, got %+v", code, capacity.Findings)
+		}
+	}
+}
+
+func TestAnalyzeNATGatewayLimits(t *testing.T) {
+	capacity := AnalyzeNATGateway(NATGatewayInput{PublicIPs: 16, CurrentNodes: 500, TargetNodes: 1100})
+
+	if capacity.IdleTimeoutMinutes != DefaultNATGatewayIdleTimeout {
+		t.Errorf("Expected default idle timeout, got %d", capacity.IdleTimeoutMinutes)
+	}
+	if len(capacity.Recommendations) != 0 || !capacity.Findings.Has("too_many_ips_needed") {
+		t.Errorf("Expected too_many_ips_needed instead of a recommendation, got %+v", capacity)
+	}
+
+	empty := AnalyzeNATGateway(NATGatewayInput{CurrentNodes: 3})
+	if !empty.Findings.Has("no_outbound_ips") {
+		t.Errorf("Expected no_outbound_ips finding, got %+v", empty.Findings)
+	}
+}
@@ -0,0 +1,172 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/components/network/resourcehelpers"
+	"github.com/Azure/aks-mcp/internal/components/network/topology"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+)
+
+// handleTopologyResource builds the network topology graph of a cluster and renders it in the requested format
+func handleTopologyResource(ctx context.Context, client *azureclient.AzureClient, subID, rg, clusterName, nodePool, format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = topology.FormatJSON
+	}
+	if !slices.Contains(topology.SupportedFormats(), format) {
+		return "", fmt.Errorf("unsupported topology format: %s. Supported formats: %v", format, topology.SupportedFormats())
+	}
+
+	cluster, err := common.GetClusterDetails(ctx, client, subID, rg, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster details: %v", err)
+	}
+
+	graph, err := buildClusterTopology(ctx, client, cluster, nodePool)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case topology.FormatMermaid:
+		return topology.RenderMermaid(graph), nil
+	case topology.FormatDOT:
+		return topology.RenderDOT(graph), nil
+	default:
+		resultJSON, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal topology to JSON: %v", err)
+		}
+		return string(resultJSON), nil
+	}
+}
+
+// buildClusterTopology links the cluster to its node pools, subnets, NSGs, route tables, VNets,
+// peerings, load balancers, public IPs, NAT gateways and API server private endpoint. Resources
+// that cannot be read are reported in the graph errors instead of failing the whole graph.
+func buildClusterTopology(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, nodePool string) (*topology.Graph, error) {
+	graph := topology.NewGraph()
+	clusterID := topology.AddCluster(graph, cluster)
+	if clusterID == "" {
+		return nil, fmt.Errorf("cluster has no resource ID")
+	}
+
+	networks, err := resourcehelpers.GetNodePoolNetworks(ctx, cluster, client, nodePool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node pool networks: %v", err)
+	}
+	for _, network := range networks {
+		topology.AddNodePool(graph, clusterID, network.NodePool, network.Mode, network.SubnetID, network.PodSubnetID)
+		for _, message := range network.Errors {
+			graph.AddError(fmt.Sprintf("node pool %s: %s", network.NodePool, message))
+		}
+	}
+
+	// Load balancers and the private endpoint can add subnets, so they are read before the subnets
+	addClusterLoadBalancers(ctx, client, cluster, clusterID, graph)
+	addClusterPrivateEndpoint(ctx, client, cluster, clusterID, graph)
+
+	for _, node := range graph.NodesOfKind(topology.KindSubnet) {
+		subnet, err := resourcehelpers.GetSubnetByID(ctx, client, node.ID)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("subnet %s: %v", node.ID, err))
+			continue
+		}
+		topology.AddSubnet(graph, subnet)
+	}
+
+	// Only the VNets holding cluster subnets are expanded, not the VNets they are peered with
+	for _, node := range graph.NodesOfKind(topology.KindVirtualNetwork) {
+		parsedID, err := arm.ParseResourceID(node.ID)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("virtual network %s: %v", node.ID, err))
+			continue
+		}
+		vnet, err := client.GetVirtualNetwork(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("virtual network %s: %v", node.ID, err))
+			continue
+		}
+		topology.AddVirtualNetwork(graph, vnet)
+	}
+
+	for _, node := range graph.NodesOfKind(topology.KindNATGateway) {
+		parsedID, err := arm.ParseResourceID(node.ID)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("NAT gateway %s: %v", node.ID, err))
+			continue
+		}
+		natGateway, err := client.GetNatGateway(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("NAT gateway %s: %v", node.ID, err))
+			continue
+		}
+		topology.AddNATGateway(graph, natGateway)
+	}
+
+	for _, node := range graph.NodesOfKind(topology.KindPublicIP) {
+		parsedID, err := arm.ParseResourceID(node.ID)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("public IP %s: %v", node.ID, err))
+			continue
+		}
+		publicIP, err := client.GetPublicIPAddress(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("public IP %s: %v", node.ID, err))
+			continue
+		}
+		topology.AddPublicIP(graph, publicIP)
+	}
+
+	return graph, nil
+}
+
+// addClusterLoadBalancers adds the load balancers in the cluster node resource group
+func addClusterLoadBalancers(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, clusterID string, graph *topology.Graph) {
+	lbIDs, err := resourcehelpers.GetLoadBalancerIDsFromAKS(ctx, cluster, client)
+	if err != nil {
+		graph.AddError(fmt.Sprintf("load balancers: %v", err))
+		return
+	}
+
+	for _, lbID := range lbIDs {
+		parsedID, err := arm.ParseResourceID(lbID)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("load balancer %s: %v", lbID, err))
+			continue
+		}
+		lb, err := client.GetLoadBalancer(ctx, parsedID.SubscriptionID, parsedID.ResourceGroupName, parsedID.Name)
+		if err != nil {
+			graph.AddError(fmt.Sprintf("load balancer %s: %v", lbID, err))
+			continue
+		}
+		topology.AddLoadBalancer(graph, clusterID, lb)
+	}
+}
+
+// addClusterPrivateEndpoint adds the API server private endpoint of a private cluster
+func addClusterPrivateEndpoint(ctx context.Context, client *azureclient.AzureClient, cluster *armcontainerservice.ManagedCluster, clusterID string, graph *topology.Graph) {
+	privateEndpointID, err := resourcehelpers.GetPrivateEndpointIDFromAKS(ctx, cluster, client)
+	if err != nil {
+		graph.AddError(fmt.Sprintf("private endpoint: %v", err))
+		return
+	}
+	if privateEndpointID == "" {
+		return
+	}
+
+	privateEndpoint, err := client.GetPrivateEndpointByID(ctx, privateEndpointID)
+	if err != nil {
+		graph.AddError(fmt.Sprintf("private endpoint %s: %v", privateEndpointID, err))
+		return
+	}
+	topology.AddPrivateEndpoint(graph, clusterID, privateEndpoint)
+}
@@ -0,0 +1,148 @@
+package topology
+
+import (
+	"sort"
+	"strings"
+)
+
+// Node kinds
+const (
+	KindCluster         = "cluster"
+	KindNodePool        = "node_pool"
+	KindSubnet          = "subnet"
+	KindVirtualNetwork  = "virtual_network"
+	KindNSG             = "network_security_group"
+	KindRouteTable      = "route_table"
+	KindLoadBalancer    = "load_balancer"
+	KindPublicIP        = "public_ip"
+	KindNATGateway      = "nat_gateway"
+	KindPrivateEndpoint = "private_endpoint"
+)
+
+// Edge relations
+const (
+	RelationHasNodePool     = "has_node_pool"
+	RelationUsesSubnet      = "uses_subnet"
+	RelationUsesPodSubnet   = "uses_pod_subnet"
+	RelationInVirtualNet    = "in_virtual_network"
+	RelationSecuredBy       = "secured_by"
+	RelationRoutedBy        = "routed_by"
+	RelationEgressVia       = "egress_via"
+	RelationPeeredWith      = "peered_with"
+	RelationUsesLB          = "uses_load_balancer"
+	RelationFrontend        = "frontend"
+	RelationUsesPublicIP    = "uses_public_ip"
+	RelationAPIServerAccess = "api_server_endpoint"
+	RelationInSubnet        = "in_subnet"
+)
+
+// Node is a resource in the topology graph
+type Node struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Edge is a directed link between two resources
+type Edge struct {
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Relation   string            `json:"relation"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Graph is the network topology of a cluster. Resource IDs are used as node IDs
+// and compared case-insensitively, as Azure resource IDs are.
+type Graph struct {
+	Nodes  []Node   `json:"nodes"`
+	Edges  []Edge   `json:"edges"`
+	Errors []string `json:"errors,omitempty"`
+
+	nodeIndex map[string]int
+	edgeIndex map[string]bool
+}
+
+// NewGraph creates an empty graph
+func NewGraph() *Graph {
+	return &Graph{
+		Nodes:     []Node{},
+		Edges:     []Edge{},
+		nodeIndex: make(map[string]int),
+		edgeIndex: make(map[string]bool),
+	}
+}
+
+// AddNode adds a node, merging its properties into an existing node with the same ID
+func (g *Graph) AddNode(node Node) {
+	if node.ID == "" {
+		return
+	}
+	if node.Name == "" {
+		node.Name = nameFromID(node.ID)
+	}
+
+	key := strings.ToLower(node.ID)
+	if index, ok := g.nodeIndex[key]; ok {
+		existing := &g.Nodes[index]
+		for name, value := range node.Properties {
+			if existing.Properties == nil {
+				existing.Properties = make(map[string]string)
+			}
+			existing.Properties[name] = value
+		}
+		return
+	}
+
+	g.nodeIndex[key] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, node)
+}
+
+// AddEdge adds an edge between two nodes unless the same edge already exists
+func (g *Graph) AddEdge(edge Edge) {
+	if edge.From == "" || edge.To == "" {
+		return
+	}
+
+	key := strings.ToLower(edge.From) + "|" + strings.ToLower(edge.To) + "|" + edge.Relation
+	if g.edgeIndex[key] {
+		return
+	}
+	g.edgeIndex[key] = true
+	g.Edges = append(g.Edges, edge)
+}
+
+// AddError records a resource that could not be added to the graph
+func (g *Graph) AddError(message string) {
+	g.Errors = append(g.Errors, message)
+}
+
+// Node returns the node with the given ID
+func (g *Graph) Node(id string) (Node, bool) {
+	index, ok := g.nodeIndex[strings.ToLower(id)]
+	if !ok {
+		return Node{}, false
+	}
+	return g.Nodes[index], true
+}
+
+// NodesOfKind returns the nodes of a kind, sorted by name
+func (g *Graph) NodesOfKind(kind string) []Node {
+	var nodes []Node
+	for _, node := range g.Nodes {
+		if node.Kind == kind {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// nameFromID returns the last segment of a resource ID
+func nameFromID(id string) string {
+	id = strings.TrimRight(id, "/")
+	if index := strings.LastIndex(id, "/"); index >= 0 {
+		return id[index+1:]
+	}
+	return id
+}
@@ -0,0 +1,52 @@
+package topology
+
+import "testing"
+
+func TestGraphDeduplicatesNodesAndEdges(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(Node{ID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet", Kind: KindVirtualNetwork})
+	g.AddNode(Node{
+		ID:         "/subscriptions/sub/resourcegroups/RG/providers/Microsoft.Network/virtualNetworks/vnet",
+		Kind:       KindVirtualNetwork,
+		Properties: map[string]string{"address_prefixes": "10.0.0.0/8"},
+	})
+
+	if len(g.Nodes) != 1 {
+		t.Fatalf("Expected IDs differing in case to be merged, got %d nodes", len(g.Nodes))
+	}
+	if g.Nodes[0].Name != "vnet" {
+		t.Errorf("Expected name to default to the last ID segment, got %q", g.Nodes[0].Name)
+	}
+	if g.Nodes[0].Properties["address_prefixes"] != "10.0.0.0/8" {
+		t.Errorf("Expected properties to be merged, got %v", g.Nodes[0].Properties)
+	}
+
+	g.AddEdge(Edge{From: "a", To: "b", Relation: RelationSecuredBy})
+	g.AddEdge(Edge{From: "A", To: "B", Relation: RelationSecuredBy})
+	g.AddEdge(Edge{From: "a", To: "b", Relation: RelationRoutedBy})
+	g.AddEdge(Edge{From: "a", To: "", Relation: RelationRoutedBy})
+	if len(g.Edges) != 2 {
+		t.Errorf("Expected 2 distinct edges, got %d", len(g.Edges))
+	}
+
+	g.AddNode(Node{Kind: KindSubnet})
+	if len(g.Nodes) != 1 {
+		t.Error("Expected node without ID to be ignored")
+	}
+}
+
+func TestGraphNodesOfKind(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(Node{ID: "/x/subnets/b", Kind: KindSubnet})
+	g.AddNode(Node{ID: "/x/subnets/a", Kind: KindSubnet})
+	g.AddNode(Node{ID: "/x/nsg", Kind: KindNSG})
+
+	subnets := g.NodesOfKind(KindSubnet)
+	if len(subnets) != 2 || subnets[0].Name != "a" || subnets[1].Name != "b" {
+		t.Errorf("Expected subnets a and b sorted by name, got %+v", subnets)
+	}
+
+	if _, ok := g.Node("/X/NSG"); !ok {
+		t.Error("Expected node lookup to ignore case")
+	}
+}
@@ -0,0 +1,146 @@
+package topology
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatJSON    = "json"
+	FormatMermaid = "mermaid"
+	FormatDOT     = "dot"
+)
+
+// kindLabels are the display names of node kinds
+var kindLabels = map[string]string{
+	KindCluster:         "AKS cluster",
+	KindNodePool:        "Node pool",
+	KindSubnet:          "Subnet",
+	KindVirtualNetwork:  "VNet",
+	KindNSG:             "NSG",
+	KindRouteTable:      "Route table",
+	KindLoadBalancer:    "Load balancer",
+	KindPublicIP:        "Public IP",
+	KindNATGateway:      "NAT gateway",
+	KindPrivateEndpoint: "Private endpoint",
+}
+
+// dotShapes are the Graphviz shapes of node kinds
+var dotShapes = map[string]string{
+	KindCluster:         "doubleoctagon",
+	KindNodePool:        "box3d",
+	KindSubnet:          "box",
+	KindVirtualNetwork:  "folder",
+	KindNSG:             "octagon",
+	KindRouteTable:      "cds",
+	KindLoadBalancer:    "diamond",
+	KindPublicIP:        "ellipse",
+	KindNATGateway:      "hexagon",
+	KindPrivateEndpoint: "component",
+}
+
+// SupportedFormats returns the output formats of a topology graph
+func SupportedFormats() []string {
+	return []string{FormatJSON, FormatMermaid, FormatDOT}
+}
+
+// nodeLabel returns the display label of a node
+func nodeLabel(node Node) string {
+	kind, ok := kindLabels[node.Kind]
+	if !ok {
+		kind = node.Kind
+	}
+	label := kind + ": " + node.Name
+	if prefixes := node.Properties["address_prefixes"]; prefixes != "" {
+		label += " (" + prefixes + ")"
+	}
+	if ip := node.Properties["ip_address"]; ip != "" {
+		label += " (" + ip + ")"
+	}
+	return label
+}
+
+// shortIDs assigns short identifiers to nodes, as resource IDs are not valid Mermaid or DOT identifiers
+func shortIDs(g *Graph) map[string]string {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[strings.ToLower(node.ID)] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
+
+// RenderMermaid renders the graph as a Mermaid flowchart
+func RenderMermaid(g *Graph) string {
+	ids := shortIDs(g)
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	for _, node := range g.Nodes {
+		id := ids[strings.ToLower(node.ID)]
+		label := mermaidEscape(nodeLabel(node))
+		switch node.Kind {
+		case KindCluster:
+			fmt.Fprintf(&b, "    %s{{\"%s\"}}\n", id, label)
+		case KindVirtualNetwork:
+			fmt.Fprintf(&b, "    %s[(\"%s\")]\n", id, label)
+		case KindPublicIP:
+			fmt.Fprintf(&b, "    %s((\"%s\"))\n", id, label)
+		case KindLoadBalancer, KindNATGateway:
+			fmt.Fprintf(&b, "    %s{\"%s\"}\n", id, label)
+		default:
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, label)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		from, fromOK := ids[strings.ToLower(edge.From)]
+		to, toOK := ids[strings.ToLower(edge.To)]
+		if !fromOK || !toOK {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s -->|%s| %s\n", from, mermaidEscape(edge.Relation), to)
+	}
+
+	return b.String()
+}
+
+// RenderDOT renders the graph in the Graphviz DOT language
+func RenderDOT(g *Graph) string {
+	ids := shortIDs(g)
+	var b strings.Builder
+	b.WriteString("digraph topology {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [fontname=\"Helvetica\"];\n")
+
+	for _, node := range g.Nodes {
+		shape, ok := dotShapes[node.Kind]
+		if !ok {
+			shape = "box"
+		}
+		fmt.Fprintf(&b, "    %s [label=\"%s\", shape=%s, tooltip=\"%s\"];\n",
+			ids[strings.ToLower(node.ID)], dotEscape(nodeLabel(node)), shape, dotEscape(node.ID))
+	}
+
+	for _, edge := range g.Edges {
+		from, fromOK := ids[strings.ToLower(edge.From)]
+		to, toOK := ids[strings.ToLower(edge.To)]
+		if !fromOK || !toOK {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s -> %s [label=\"%s\"];\n", from, to, dotEscape(edge.Relation))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// mermaidEscape replaces the characters that end a quoted Mermaid label
+func mermaidEscape(value string) string {
+	return strings.NewReplacer("\"", "#quot;", "|", "#124;", "\n", " ").Replace(value)
+}
+
+// dotEscape escapes a DOT quoted string
+func dotEscape(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value)
+}
@@ -0,0 +1,55 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	g := NewGraph()
+	g.AddNode(Node{ID: "/clusters/aks", Kind: KindCluster})
+	g.AddNode(Node{ID: "/vnets/vnet/subnets/aks-subnet", Kind: KindSubnet, Properties: map[string]string{"address_prefixes": "10.224.0.0/16"}})
+	g.AddNode(Node{ID: "/pips/pip", Kind: KindPublicIP, Name: `odd "name"`, Properties: map[string]string{"ip_address": "20.1.2.3"}})
+	g.AddEdge(Edge{From: "/clusters/aks", To: "/vnets/vnet/subnets/aks-subnet", Relation: RelationUsesSubnet})
+	g.AddEdge(Edge{From: "/clusters/aks", To: "/pips/pip", Relation: RelationUsesPublicIP})
+	g.AddEdge(Edge{From: "/clusters/aks", To: "/unknown", Relation: RelationFrontend})
+	return g
+}
+
+func TestRenderMermaid(t *testing.T) {
+	output := RenderMermaid(testGraph())
+
+	for _, expected := range []string{
+		"flowchart LR\n",
+		`n0{{"AKS cluster: aks"}}`,
+		`n1["Subnet: aks-subnet (10.224.0.0/16)"]`,
+		`n2(("Public IP: odd #quot;name#quot; (20.1.2.3)"))`,
+		"n0 -->|uses_subnet| n1",
+		"n0 -->|uses_public_ip| n2",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected Mermaid output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, RelationFrontend) {
+		t.Error("Expected edges to unknown nodes to be skipped")
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	output := RenderDOT(testGraph())
+
+	for _, expected := range []string{
+		"digraph topology {\n",
+		`n0 [label="AKS cluster: aks", shape=doubleoctagon, tooltip="/clusters/aks"];`,
+		`n2 [label="Public IP: odd \"name\" (20.1.2.3)", shape=ellipse`,
+		`n0 -> n1 [label="uses_subnet"];`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected DOT output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if !strings.HasSuffix(output, "}\n") {
+		t.Error("Expected DOT output to close the graph")
+	}
+}
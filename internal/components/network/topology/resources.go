@@ -0,0 +1,277 @@
+package topology
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+// AddCluster adds the cluster node and returns its ID
+func AddCluster(g *Graph, cluster *armcontainerservice.ManagedCluster) string {
+	if cluster == nil || cluster.ID == nil {
+		return ""
+	}
+
+	properties := make(map[string]string)
+	if cluster.Location != nil {
+		properties["location"] = *cluster.Location
+	}
+	if cluster.Properties != nil {
+		if profile := cluster.Properties.NetworkProfile; profile != nil {
+			if profile.NetworkPlugin != nil {
+				properties["network_plugin"] = string(*profile.NetworkPlugin)
+			}
+			if profile.OutboundType != nil {
+				properties["outbound_type"] = string(*profile.OutboundType)
+			}
+		}
+		if access := cluster.Properties.APIServerAccessProfile; access != nil && access.EnablePrivateCluster != nil && *access.EnablePrivateCluster {
+			properties["private_cluster"] = "true"
+		}
+	}
+
+	node := Node{ID: *cluster.ID, Kind: KindCluster, Properties: properties}
+	if cluster.Name != nil {
+		node.Name = *cluster.Name
+	}
+	g.AddNode(node)
+	return *cluster.ID
+}
+
+// AddNodePool adds a node pool of the cluster and links it to its node and pod subnets
+func AddNodePool(g *Graph, clusterID, name, mode, subnetID, podSubnetID string) string {
+	if clusterID == "" || name == "" {
+		return ""
+	}
+
+	id := clusterID + "/agentPools/" + name
+	node := Node{ID: id, Kind: KindNodePool, Name: name}
+	if mode != "" {
+		node.Properties = map[string]string{"mode": mode}
+	}
+	g.AddNode(node)
+	g.AddEdge(Edge{From: clusterID, To: id, Relation: RelationHasNodePool})
+
+	if subnetID != "" {
+		g.AddNode(Node{ID: subnetID, Kind: KindSubnet})
+		g.AddEdge(Edge{From: id, To: subnetID, Relation: RelationUsesSubnet})
+	}
+	if podSubnetID != "" {
+		g.AddNode(Node{ID: podSubnetID, Kind: KindSubnet})
+		g.AddEdge(Edge{From: id, To: podSubnetID, Relation: RelationUsesPodSubnet})
+	}
+	return id
+}
+
+// AddSubnet adds a subnet and links it to its VNet, NSG, route table and NAT gateway
+func AddSubnet(g *Graph, subnet *armnetwork.Subnet) {
+	if subnet == nil || subnet.ID == nil {
+		return
+	}
+	id := *subnet.ID
+
+	node := Node{ID: id, Kind: KindSubnet}
+	if subnet.Properties != nil {
+		if prefixes := subnetPrefixes(subnet.Properties); prefixes != "" {
+			node.Properties = map[string]string{"address_prefixes": prefixes}
+		}
+	}
+	g.AddNode(node)
+
+	if vnetID := parentID(id, "/subnets/"); vnetID != "" {
+		g.AddNode(Node{ID: vnetID, Kind: KindVirtualNetwork})
+		g.AddEdge(Edge{From: id, To: vnetID, Relation: RelationInVirtualNet})
+	}
+
+	if subnet.Properties == nil {
+		return
+	}
+	if nsg := subnet.Properties.NetworkSecurityGroup; nsg != nil && nsg.ID != nil {
+		g.AddNode(Node{ID: *nsg.ID, Kind: KindNSG})
+		g.AddEdge(Edge{From: id, To: *nsg.ID, Relation: RelationSecuredBy})
+	}
+	if routeTable := subnet.Properties.RouteTable; routeTable != nil && routeTable.ID != nil {
+		g.AddNode(Node{ID: *routeTable.ID, Kind: KindRouteTable})
+		g.AddEdge(Edge{From: id, To: *routeTable.ID, Relation: RelationRoutedBy})
+	}
+	if natGateway := subnet.Properties.NatGateway; natGateway != nil && natGateway.ID != nil {
+		g.AddNode(Node{ID: *natGateway.ID, Kind: KindNATGateway})
+		g.AddEdge(Edge{From: id, To: *natGateway.ID, Relation: RelationEgressVia})
+	}
+}
+
+// AddVirtualNetwork adds a VNet and its peerings
+func AddVirtualNetwork(g *Graph, vnet *armnetwork.VirtualNetwork) {
+	if vnet == nil || vnet.ID == nil {
+		return
+	}
+	id := *vnet.ID
+
+	node := Node{ID: id, Kind: KindVirtualNetwork}
+	if vnet.Properties != nil && vnet.Properties.AddressSpace != nil {
+		if prefixes := joinValues(vnet.Properties.AddressSpace.AddressPrefixes); prefixes != "" {
+			node.Properties = map[string]string{"address_prefixes": prefixes}
+		}
+	}
+	g.AddNode(node)
+
+	if vnet.Properties == nil {
+		return
+	}
+	for _, peering := range vnet.Properties.VirtualNetworkPeerings {
+		if peering == nil || peering.Properties == nil || peering.Properties.RemoteVirtualNetwork == nil ||
+			peering.Properties.RemoteVirtualNetwork.ID == nil {
+			continue
+		}
+		remoteID := *peering.Properties.RemoteVirtualNetwork.ID
+
+		remote := Node{ID: remoteID, Kind: KindVirtualNetwork}
+		if peering.Properties.RemoteAddressSpace != nil {
+			if prefixes := joinValues(peering.Properties.RemoteAddressSpace.AddressPrefixes); prefixes != "" {
+				remote.Properties = map[string]string{"address_prefixes": prefixes}
+			}
+		}
+		g.AddNode(remote)
+
+		edge := Edge{From: id, To: remoteID, Relation: RelationPeeredWith}
+		if peering.Properties.PeeringState != nil {
+			edge.Properties = map[string]string{"peering_state": string(*peering.Properties.PeeringState)}
+		}
+		g.AddEdge(edge)
+	}
+}
+
+// AddLoadBalancer adds a cluster load balancer with its public IP and subnet frontends
+func AddLoadBalancer(g *Graph, clusterID string, lb *armnetwork.LoadBalancer) {
+	if lb == nil || lb.ID == nil {
+		return
+	}
+	id := *lb.ID
+
+	node := Node{ID: id, Kind: KindLoadBalancer}
+	if lb.SKU != nil && lb.SKU.Name != nil {
+		node.Properties = map[string]string{"sku": string(*lb.SKU.Name)}
+	}
+	g.AddNode(node)
+	g.AddEdge(Edge{From: clusterID, To: id, Relation: RelationUsesLB})
+
+	if lb.Properties == nil {
+		return
+	}
+	for _, frontend := range lb.Properties.FrontendIPConfigurations {
+		if frontend == nil || frontend.Properties == nil {
+			continue
+		}
+		properties := map[string]string{}
+		if frontend.Name != nil {
+			properties["frontend"] = *frontend.Name
+		}
+
+		if publicIP := frontend.Properties.PublicIPAddress; publicIP != nil && publicIP.ID != nil {
+			g.AddNode(Node{ID: *publicIP.ID, Kind: KindPublicIP})
+			g.AddEdge(Edge{From: id, To: *publicIP.ID, Relation: RelationFrontend, Properties: properties})
+		}
+		if subnet := frontend.Properties.Subnet; subnet != nil && subnet.ID != nil {
+			if frontend.Properties.PrivateIPAddress != nil {
+				properties["private_ip"] = *frontend.Properties.PrivateIPAddress
+			}
+			g.AddNode(Node{ID: *subnet.ID, Kind: KindSubnet})
+			g.AddEdge(Edge{From: id, To: *subnet.ID, Relation: RelationFrontend, Properties: properties})
+		}
+	}
+}
+
+// AddPublicIP sets the address of a public IP node
+func AddPublicIP(g *Graph, publicIP *armnetwork.PublicIPAddress) {
+	if publicIP == nil || publicIP.ID == nil {
+		return
+	}
+	node := Node{ID: *publicIP.ID, Kind: KindPublicIP}
+	if publicIP.Properties != nil && publicIP.Properties.IPAddress != nil {
+		node.Properties = map[string]string{"ip_address": *publicIP.Properties.IPAddress}
+	}
+	g.AddNode(node)
+}
+
+// AddNATGateway adds a NAT gateway and its public IPs
+func AddNATGateway(g *Graph, natGateway *armnetwork.NatGateway) {
+	if natGateway == nil || natGateway.ID == nil {
+		return
+	}
+	id := *natGateway.ID
+	g.AddNode(Node{ID: id, Kind: KindNATGateway})
+
+	if natGateway.Properties == nil {
+		return
+	}
+	for _, publicIP := range natGateway.Properties.PublicIPAddresses {
+		if publicIP != nil && publicIP.ID != nil {
+			g.AddNode(Node{ID: *publicIP.ID, Kind: KindPublicIP})
+			g.AddEdge(Edge{From: id, To: *publicIP.ID, Relation: RelationUsesPublicIP})
+		}
+	}
+}
+
+// AddPrivateEndpoint adds the API server private endpoint and links it to its subnet
+func AddPrivateEndpoint(g *Graph, clusterID string, privateEndpoint *armnetwork.PrivateEndpoint) {
+	if privateEndpoint == nil || privateEndpoint.ID == nil {
+		return
+	}
+	id := *privateEndpoint.ID
+
+	node := Node{ID: id, Kind: KindPrivateEndpoint}
+	if privateEndpoint.Properties != nil {
+		var ips []string
+		for _, dnsConfig := range privateEndpoint.Properties.CustomDNSConfigs {
+			if dnsConfig == nil {
+				continue
+			}
+			for _, ip := range dnsConfig.IPAddresses {
+				if ip != nil {
+					ips = append(ips, *ip)
+				}
+			}
+		}
+		if len(ips) > 0 {
+			node.Properties = map[string]string{"ip_address": strings.Join(ips, ", ")}
+		}
+	}
+	g.AddNode(node)
+	g.AddEdge(Edge{From: clusterID, To: id, Relation: RelationAPIServerAccess})
+
+	if privateEndpoint.Properties != nil && privateEndpoint.Properties.Subnet != nil && privateEndpoint.Properties.Subnet.ID != nil {
+		subnetID := *privateEndpoint.Properties.Subnet.ID
+		g.AddNode(Node{ID: subnetID, Kind: KindSubnet})
+		g.AddEdge(Edge{From: id, To: subnetID, Relation: RelationInSubnet})
+	}
+}
+
+// subnetPrefixes returns the address prefixes of a subnet
+func subnetPrefixes(props *armnetwork.SubnetPropertiesFormat) string {
+	values := props.AddressPrefixes
+	if props.AddressPrefix != nil {
+		values = append([]*string{props.AddressPrefix}, values...)
+	}
+	return joinValues(values)
+}
+
+// joinValues joins non-empty values with commas
+func joinValues(values []*string) string {
+	var parts []string
+	for _, value := range values {
+		if value != nil && *value != "" {
+			parts = append(parts, *value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parentID returns the ID of the parent resource of a child resource ID, or an empty string
+func parentID(id, childSegment string) string {
+	index := strings.LastIndex(strings.ToLower(id), strings.ToLower(childSegment))
+	if index <= 0 {
+		return ""
+	}
+	return id[:index]
+}
@@ -0,0 +1,192 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2"
+)
+
+const (
+	testClusterID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks"
+	testVNetID    = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet"
+	testSubnetID  = testVNetID + "/subnets/aks-subnet"
+)
+
+func hasEdge(g *Graph, from, to, relation string) bool {
+	for _, edge := range g.Edges {
+		if edge.From == from && edge.To == to && edge.Relation == relation {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAddClusterAndNodePool(t *testing.T) {
+	g := NewGraph()
+	cluster := &armcontainerservice.ManagedCluster{
+		ID:   to.Ptr(testClusterID),
+		Name: to.Ptr("aks"),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			NetworkProfile: &armcontainerservice.NetworkProfile{
+				NetworkPlugin: to.Ptr(armcontainerservice.NetworkPluginAzure),
+				OutboundType:  to.Ptr(armcontainerservice.OutboundTypeUserDefinedRouting),
+			},
+		},
+	}
+
+	clusterID := AddCluster(g, cluster)
+	if clusterID != testClusterID {
+		t.Fatalf("Expected cluster ID %s, got %s", testClusterID, clusterID)
+	}
+	node, _ := g.Node(clusterID)
+	if node.Properties["outbound_type"] != "userDefinedRouting" || node.Properties["network_plugin"] != "azure" {
+		t.Errorf("Unexpected cluster properties: %v", node.Properties)
+	}
+
+	podSubnetID := testVNetID + "/subnets/pods"
+	poolID := AddNodePool(g, clusterID, "nodepool1", "System", testSubnetID, podSubnetID)
+	if !hasEdge(g, clusterID, poolID, RelationHasNodePool) ||
+		!hasEdge(g, poolID, testSubnetID, RelationUsesSubnet) ||
+		!hasEdge(g, poolID, podSubnetID, RelationUsesPodSubnet) {
+		t.Errorf("Expected node pool edges, got %+v", g.Edges)
+	}
+
+	if AddCluster(g, &armcontainerservice.ManagedCluster{}) != "" {
+		t.Error("Expected cluster without ID to be ignored")
+	}
+}
+
+func TestAddSubnet(t *testing.T) {
+	g := NewGraph()
+	nsgID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg"
+	routeTableID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/routeTables/rt"
+	natGatewayID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/natGateways/nat"
+
+	AddSubnet(g, &armnetwork.Subnet{
+		ID: to.Ptr(testSubnetID),
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			AddressPrefix:        to.Ptr("10.224.0.0/16"),
+			NetworkSecurityGroup: &armnetwork.SecurityGroup{ID: to.Ptr(nsgID)},
+			RouteTable:           &armnetwork.RouteTable{ID: to.Ptr(routeTableID)},
+			NatGateway:           &armnetwork.SubResource{ID: to.Ptr(natGatewayID)},
+		},
+	})
+
+	for _, edge := range []struct{ to, relation string }{
+		{testVNetID, RelationInVirtualNet},
+		{nsgID, RelationSecuredBy},
+		{routeTableID, RelationRoutedBy},
+		{natGatewayID, RelationEgressVia},
+	} {
+		if !hasEdge(g, testSubnetID, edge.to, edge.relation) {
+			t.Errorf("Expected %s edge to %s", edge.relation, edge.to)
+		}
+	}
+
+	node, _ := g.Node(testSubnetID)
+	if node.Properties["address_prefixes"] != "10.224.0.0/16" {
+		t.Errorf("Expected subnet address prefix, got %v", node.Properties)
+	}
+}
+
+func TestAddVirtualNetwork(t *testing.T) {
+	g := NewGraph()
+	hubID := "/subscriptions/hub/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub"
+
+	AddVirtualNetwork(g, &armnetwork.VirtualNetwork{
+		ID: to.Ptr(testVNetID),
+		Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+			AddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr("10.224.0.0/12")}},
+			VirtualNetworkPeerings: []*armnetwork.VirtualNetworkPeering{
+				{
+					Properties: &armnetwork.VirtualNetworkPeeringPropertiesFormat{
+						RemoteVirtualNetwork: &armnetwork.SubResource{ID: to.Ptr(hubID)},
+						RemoteAddressSpace:   &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr("10.0.0.0/16")}},
+						PeeringState:         to.Ptr(armnetwork.VirtualNetworkPeeringStateConnected),
+					},
+				},
+			},
+		},
+	})
+
+	if !hasEdge(g, testVNetID, hubID, RelationPeeredWith) {
+		t.Fatalf("Expected peering edge, got %+v", g.Edges)
+	}
+	if g.Edges[0].Properties["peering_state"] != "Connected" {
+		t.Errorf("Expected peering state on the edge, got %v", g.Edges[0].Properties)
+	}
+	hub, _ := g.Node(hubID)
+	if hub.Kind != KindVirtualNetwork || hub.Properties["address_prefixes"] != "10.0.0.0/16" {
+		t.Errorf("Unexpected peered VNet node: %+v", hub)
+	}
+}
+
+func TestAddLoadBalancerAndNATGateway(t *testing.T) {
+	g := NewGraph()
+	lbID := "/subscriptions/sub/resourceGroups/mc_rg/providers/Microsoft.Network/loadBalancers/kubernetes"
+	publicIPID := "/subscriptions/sub/resourceGroups/mc_rg/providers/Microsoft.Network/publicIPAddresses/pip"
+	natGatewayID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/natGateways/nat"
+	natIPID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/nat-ip"
+
+	AddLoadBalancer(g, testClusterID, &armnetwork.LoadBalancer{
+		ID: to.Ptr(lbID),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{Name: to.Ptr("public"), Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+					PublicIPAddress: &armnetwork.PublicIPAddress{ID: to.Ptr(publicIPID)},
+				}},
+				{Name: to.Ptr("internal"), Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+					Subnet:           &armnetwork.Subnet{ID: to.Ptr(testSubnetID)},
+					PrivateIPAddress: to.Ptr("10.224.0.100"),
+				}},
+			},
+		},
+	})
+	AddNATGateway(g, &armnetwork.NatGateway{
+		ID: to.Ptr(natGatewayID),
+		Properties: &armnetwork.NatGatewayPropertiesFormat{
+			PublicIPAddresses: []*armnetwork.SubResource{{ID: to.Ptr(natIPID)}},
+		},
+	})
+	AddPublicIP(g, &armnetwork.PublicIPAddress{
+		ID:         to.Ptr(publicIPID),
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: to.Ptr("20.1.2.3")},
+	})
+
+	if !hasEdge(g, testClusterID, lbID, RelationUsesLB) ||
+		!hasEdge(g, lbID, publicIPID, RelationFrontend) ||
+		!hasEdge(g, lbID, testSubnetID, RelationFrontend) ||
+		!hasEdge(g, natGatewayID, natIPID, RelationUsesPublicIP) {
+		t.Errorf("Missing expected edges, got %+v", g.Edges)
+	}
+	publicIP, _ := g.Node(publicIPID)
+	if publicIP.Properties["ip_address"] != "20.1.2.3" {
+		t.Errorf("Expected public IP address, got %v", publicIP.Properties)
+	}
+}
+
+func TestAddPrivateEndpoint(t *testing.T) {
+	g := NewGraph()
+	privateEndpointID := "/subscriptions/sub/resourceGroups/mc_rg/providers/Microsoft.Network/privateEndpoints/kube-apiserver"
+
+	AddPrivateEndpoint(g, testClusterID, &armnetwork.PrivateEndpoint{
+		ID: to.Ptr(privateEndpointID),
+		Properties: &armnetwork.PrivateEndpointProperties{
+			Subnet: &armnetwork.Subnet{ID: to.Ptr(testSubnetID)},
+			CustomDNSConfigs: []*armnetwork.CustomDNSConfigPropertiesFormat{
+				{IPAddresses: []*string{to.Ptr("10.224.0.5")}},
+			},
+		},
+	})
+
+	if !hasEdge(g, testClusterID, privateEndpointID, RelationAPIServerAccess) ||
+		!hasEdge(g, privateEndpointID, testSubnetID, RelationInSubnet) {
+		t.Errorf("Missing expected edges, got %+v", g.Edges)
+	}
+	node, _ := g.Node(privateEndpointID)
+	if node.Properties["ip_address"] != "10.224.0.5" {
+		t.Errorf("Expected private endpoint IP, got %v", node.Properties)
+	}
+}
@@ -0,0 +1,29 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+)
+
+func TestTopologyResourceInvalidFormat(t *testing.T) {
+	cfg := &config.ConfigData{}
+	handler := GetAksNetworkResourcesHandler(nil, cfg)
+
+	params := map[string]interface{}{
+		"resource_type":   string(ResourceTypeTopology),
+		"subscription_id": "sub-123",
+		"resource_group":  "rg-test",
+		"cluster_name":    "cluster",
+		"format":          "svg",
+	}
+	result, err := handler.Handle(context.Background(), params, cfg)
+	if err == nil || !strings.Contains(err.Error(), "unsupported topology format: svg") {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+	if result != "" {
+		t.Error("Expected empty result on error")
+	}
+}
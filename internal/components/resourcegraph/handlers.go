@@ -0,0 +1,179 @@
+// Package resourcegraph provides the Azure Resource Graph inventory tool for AKS.
+package resourcegraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Azure/aks-mcp/internal/azureclient"
+	"github.com/Azure/aks-mcp/internal/components/common"
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/aks-mcp/internal/logger"
+	"github.com/Azure/aks-mcp/internal/tools"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+)
+
+// QueryResult is the paginated result of a Resource Graph query
+type QueryResult struct {
+	Template        string   `json:"template,omitempty"`
+	Query           string   `json:"query"`
+	Subscriptions   []string `json:"subscriptions"`
+	Count           int64    `json:"count"`
+	TotalRecords    int64    `json:"total_records"`
+	ResultTruncated bool     `json:"result_truncated"`
+	SkipToken       string   `json:"skip_token,omitempty"`
+	Data            any      `json:"data"`
+}
+
+// =============================================================================
+// Resource Graph Handlers
+// =============================================================================
+
+// GetAksResourceGraphHandler returns a handler for the aks_resource_graph tool
+func GetAksResourceGraphHandler(azClient *azureclient.AzureClient, cfg *config.ConfigData) tools.ResourceHandler {
+	return tools.ResourceHandlerFunc(func(ctx context.Context, params map[string]interface{}, _ *config.ConfigData) (string, error) {
+		return HandleAksResourceGraph(ctx, params, azClient, cfg)
+	})
+}
+
+// HandleAksResourceGraph routes aks_resource_graph operations
+func HandleAksResourceGraph(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	operation, ok := params["operation"].(string)
+	if !ok || operation == "" {
+		return "", fmt.Errorf("missing or invalid operation parameter")
+	}
+
+	switch operation {
+	case string(OpListTemplates):
+		return handleListTemplates()
+	case string(OpTemplate):
+		return handleTemplateQuery(ctx, params, azClient, cfg)
+	case string(OpQuery):
+		return handleFreeFormQuery(ctx, params, azClient, cfg)
+	default:
+		return "", fmt.Errorf("invalid operation '%s', must be one of: list_templates, template, query", operation)
+	}
+}
+
+// handleListTemplates returns the available query templates
+func handleListTemplates() (string, error) {
+	resultJSON, err := json.MarshalIndent(GetQueryTemplates(), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal templates to JSON: %v", err)
+	}
+	return string(resultJSON), nil
+}
+
+// handleTemplateQuery renders and runs a curated query template
+func handleTemplateQuery(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	templateName, ok := params["template"].(string)
+	if !ok || templateName == "" {
+		return "", fmt.Errorf("missing or invalid template parameter")
+	}
+
+	values, err := parseTemplateParameters(params)
+	if err != nil {
+		return "", err
+	}
+
+	query, err := BuildTemplateQuery(templateName, values)
+	if err != nil {
+		return "", err
+	}
+
+	return runQuery(ctx, params, azClient, cfg, templateName, query)
+}
+
+// handleFreeFormQuery validates and runs a free-form read-only query
+func handleFreeFormQuery(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData) (string, error) {
+	query, ok := params["query"].(string)
+	if !ok || query == "" {
+		return "", fmt.Errorf("missing or invalid query parameter")
+	}
+
+	if err := ValidateReadOnlyQuery(query); err != nil {
+		return "", fmt.Errorf("invalid query: %w", err)
+	}
+
+	return runQuery(ctx, params, azClient, cfg, "", query)
+}
+
+// runQuery executes a query against the resolved subscriptions and formats one page of results
+func runQuery(ctx context.Context, params map[string]interface{}, azClient *azureclient.AzureClient, cfg *config.ConfigData, templateName, query string) (string, error) {
+	if azClient == nil {
+		return "", fmt.Errorf("azure client is required but not provided")
+	}
+
+	requested, _ := params["subscription_ids"].(string)
+	subscriptions, err := common.ResolveSubscriptions(requested, cfg, common.GetDefaultSubscriptionID)
+	if err != nil {
+		return "", err
+	}
+
+	top := GetTop(params)
+	skipToken, _ := params["skip_token"].(string)
+
+	logger.Debugf("[RESOURCE_GRAPH] Running query over %d subscriptions (top=%d, paged=%t): %s", len(subscriptions), top, skipToken != "", query)
+
+	resp, err := azClient.QueryResourceGraph(ctx, query, subscriptions, top, skipToken)
+	if err != nil {
+		return "", err
+	}
+
+	result := newQueryResult(templateName, query, subscriptions, resp)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query result to JSON: %v", err)
+	}
+
+	return string(resultJSON), nil
+}
+
+// newQueryResult converts a Resource Graph response into a QueryResult
+func newQueryResult(templateName, query string, subscriptions []string, resp *armresourcegraph.QueryResponse) QueryResult {
+	result := QueryResult{
+		Template:      templateName,
+		Query:         query,
+		Subscriptions: subscriptions,
+		Data:          resp.Data,
+	}
+	if resp.Count != nil {
+		result.Count = *resp.Count
+	}
+	if resp.TotalRecords != nil {
+		result.TotalRecords = *resp.TotalRecords
+	}
+	if resp.ResultTruncated != nil {
+		result.ResultTruncated = *resp.ResultTruncated == armresourcegraph.ResultTruncatedTrue
+	}
+	if resp.SkipToken != nil {
+		result.SkipToken = *resp.SkipToken
+	}
+	return result
+}
+
+// parseTemplateParameters parses the JSON parameters string into template parameter values
+func parseTemplateParameters(params map[string]interface{}) (map[string]string, error) {
+	values := make(map[string]string)
+
+	parametersStr, ok := params["parameters"].(string)
+	if !ok || parametersStr == "" {
+		return values, nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(parametersStr), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters JSON: %w", err)
+	}
+
+	for key, value := range raw {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("parameter '%s' must be a string", key)
+		}
+		values[key] = str
+	}
+
+	return values, nil
+}
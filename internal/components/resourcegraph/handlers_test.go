@@ -0,0 +1,88 @@
+package resourcegraph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Azure/aks-mcp/internal/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+)
+
+func TestRegisterAksResourceGraphTool(t *testing.T) {
+	tool := RegisterAksResourceGraphTool()
+	if tool.Name != "aks_resource_graph" {
+		t.Errorf("Expected tool name 'aks_resource_graph', got '%s'", tool.Name)
+	}
+	if tool.Description == "" {
+		t.Error("Expected tool description to be set")
+	}
+}
+
+func TestHandleAksResourceGraph_Validation(t *testing.T) {
+	cfg := &config.ConfigData{}
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{name: "missing operation", params: map[string]interface{}{}, wantErr: "missing or invalid operation"},
+		{name: "invalid operation", params: map[string]interface{}{"operation": "delete"}, wantErr: "invalid operation"},
+		{name: "template without name", params: map[string]interface{}{"operation": "template"}, wantErr: "missing or invalid template"},
+		{name: "template with bad parameters JSON", params: map[string]interface{}{"operation": "template", "template": "clusters", "parameters": "{"}, wantErr: "failed to parse parameters"},
+		{name: "template with non-string parameter", params: map[string]interface{}{"operation": "template", "template": "clusters", "parameters": `{"location": 1}`}, wantErr: "must be a string"},
+		{name: "query without query", params: map[string]interface{}{"operation": "query"}, wantErr: "missing or invalid query"},
+		{name: "query rejected", params: map[string]interface{}{"operation": "query", "query": ".show tables"}, wantErr: "invalid query"},
+		{name: "valid query without client", params: map[string]interface{}{"operation": "query", "query": "resources | take 1"}, wantErr: "azure client is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := HandleAksResourceGraph(context.Background(), tt.params, nil, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHandleAksResourceGraph_ListTemplates(t *testing.T) {
+	result, err := HandleAksResourceGraph(context.Background(), map[string]interface{}{"operation": "list_templates"}, nil, &config.ConfigData{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var templates []QueryTemplate
+	if err := json.Unmarshal([]byte(result), &templates); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(templates) != len(queryTemplates) {
+		t.Errorf("Expected %d templates, got %d", len(queryTemplates), len(templates))
+	}
+}
+
+func TestNewQueryResult(t *testing.T) {
+	resp := &armresourcegraph.QueryResponse{
+		Count:           to.Ptr[int64](2),
+		TotalRecords:    to.Ptr[int64](10),
+		ResultTruncated: to.Ptr(armresourcegraph.ResultTruncatedFalse),
+		SkipToken:       to.Ptr("next-page"),
+		Data:            []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}},
+	}
+
+	result := newQueryResult("clusters", "resources", []string{"sub"}, resp)
+	if result.Count != 2 || result.TotalRecords != 10 {
+		t.Errorf("Unexpected counts: %+v", result)
+	}
+	if result.ResultTruncated {
+		t.Error("Expected result not to be truncated")
+	}
+	if result.SkipToken != "next-page" {
+		t.Errorf("Expected skip token 'next-page', got '%s'", result.SkipToken)
+	}
+	if result.Template != "clusters" {
+		t.Errorf("Expected template 'clusters', got '%s'", result.Template)
+	}
+}
@@ -0,0 +1,66 @@
+package resourcegraph
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResourceGraphOperationType defines the type of resource graph operation
+type ResourceGraphOperationType string
+
+const (
+	OpListTemplates ResourceGraphOperationType = "list_templates"
+	OpTemplate      ResourceGraphOperationType = "template"
+	OpQuery         ResourceGraphOperationType = "query"
+)
+
+// RegisterAksResourceGraphTool registers the aks_resource_graph tool
+func RegisterAksResourceGraphTool() mcp.Tool {
+	description := `Query Azure Resource Graph for inventory across AKS clusters and related resources.
+
+Supported Operations:
+- list_templates: List the curated query templates and their parameters
+- template: Run a curated, parameterized query template
+- query: Run a free-form read-only KQL query against Resource Graph tables
+
+Subscription scope:
+- Queries run against the subscriptions in subscription_ids, which must be part of the server's allowed subscriptions when --allowed-subscriptions is set
+- When subscription_ids is omitted, all allowed subscriptions are queried, or the default subscription when no allowlist is set
+
+Pagination:
+- Use top to set the page size (default 100, max 1000)
+- Pass the returned skip_token to fetch the next page with the same query and scope
+
+Examples:
+- Clusters on a Kubernetes version: operation="template", template="clusters_by_version", parameters="{\"kubernetes_version\":\"1.28\"}"
+- Node pools on a VM size: operation="template", template="node_pools_by_vm_size", parameters="{\"vm_size\":\"Standard_D4s_v3\"}"
+- Public IPs in node resource groups: operation="template", template="public_ips_in_node_resource_groups"
+- Free-form query: operation="query", query="resources | where type =~ 'microsoft.containerservice/managedclusters' | summarize count() by location"`
+
+	return mcp.NewTool("aks_resource_graph",
+		mcp.WithDescription(description),
+		mcp.WithTitleAnnotation("Azure Resource Graph"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("The operation to perform: 'list_templates', 'template' or 'query'"),
+		),
+		mcp.WithString("template",
+			mcp.Description("Name of the query template to run (required for 'template' operation)"),
+		),
+		mcp.WithString("parameters",
+			mcp.Description("JSON string with template parameters (for 'template' operation)"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Read-only KQL query (required for 'query' operation)"),
+		),
+		mcp.WithString("subscription_ids",
+			mcp.Description("Comma-separated list of subscription IDs to query (defaults to all allowed subscriptions)"),
+		),
+		mcp.WithNumber("top",
+			mcp.Description("Maximum number of rows per page (default: 100, max: 1000)"),
+		),
+		mcp.WithString("skip_token",
+			mcp.Description("Continuation token returned by a previous call to fetch the next page"),
+		),
+	)
+}
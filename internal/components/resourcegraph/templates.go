@@ -0,0 +1,284 @@
+package resourcegraph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// QueryTemplate is a curated, parameterized Resource Graph query
+type QueryTemplate struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Parameters  []TemplateParameter `json:"parameters,omitempty"`
+	source      string              // Table selection and column extraction
+	projection  string              // Trailing project/order clauses applied after parameter filters
+}
+
+// TemplateParameter describes a single template parameter and how it filters the query
+type TemplateParameter struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Required    bool           `json:"required"`
+	pattern     *regexp.Regexp // Allowed values for the parameter
+	filter      string         // KQL predicate; {value} is replaced with the quoted parameter value
+}
+
+// Parameter value patterns. Values are additionally quoted as KQL string literals.
+var (
+	kubernetesVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+	vmSizePattern            = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+	clusterNamePattern       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+	nodePoolNamePattern      = regexp.MustCompile(`^[a-z0-9]{1,12}$`)
+	resourceGroupPattern     = regexp.MustCompile(`^[-\w.()]{1,90}$`)
+	locationPattern          = regexp.MustCompile(`^[A-Za-z0-9]{1,40}$`)
+	networkPluginPattern     = regexp.MustCompile(`^(?i)(azure|kubenet|none)$`)
+	outboundTypePattern      = regexp.MustCompile(`^(?i)(loadBalancer|userDefinedRouting|managedNATGateway|userAssignedNATGateway|none)$`)
+)
+
+// Common optional filters shared by several templates
+var (
+	clusterNameParam = TemplateParameter{
+		Name:        "cluster_name",
+		Description: "Only include resources belonging to this AKS cluster",
+		pattern:     clusterNamePattern,
+		filter:      "clusterName =~ {value}",
+	}
+	resourceGroupParam = TemplateParameter{
+		Name:        "resource_group",
+		Description: "Only include clusters in this resource group",
+		pattern:     resourceGroupPattern,
+		filter:      "resourceGroup =~ {value}",
+	}
+	locationParam = TemplateParameter{
+		Name:        "location",
+		Description: "Only include clusters in this Azure region (e.g. eastus)",
+		pattern:     locationPattern,
+		filter:      "location =~ {value}",
+	}
+)
+
+// managedClustersSource selects AKS clusters with commonly used properties extracted
+const managedClustersSource = `resources
+| where type =~ 'microsoft.containerservice/managedclusters'
+| extend clusterName = name,
+    kubernetesVersion = tostring(properties.kubernetesVersion),
+    provisioningState = tostring(properties.provisioningState),
+    powerState = tostring(properties.powerState.code),
+    nodeResourceGroup = tostring(properties.nodeResourceGroup),
+    networkPlugin = tostring(properties.networkProfile.networkPlugin),
+    networkPluginMode = tostring(properties.networkProfile.networkPluginMode),
+    networkPolicy = tostring(properties.networkProfile.networkPolicy),
+    outboundType = tostring(properties.networkProfile.outboundType),
+    privateCluster = tobool(properties.apiServerAccessProfile.enablePrivateCluster)`
+
+// agentPoolsSource expands the agent pool profiles of every AKS cluster into one row per node pool
+const agentPoolsSource = `resources
+| where type =~ 'microsoft.containerservice/managedclusters'
+| mv-expand pool = properties.agentPoolProfiles
+| extend clusterName = name,
+    nodePool = tostring(pool.name),
+    mode = tostring(pool.mode),
+    vmSize = tostring(pool.vmSize),
+    nodeCount = toint(pool['count']),
+    minCount = toint(pool.minCount),
+    maxCount = toint(pool.maxCount),
+    osType = tostring(pool.osType),
+    osSKU = tostring(pool.osSKU),
+    orchestratorVersion = tostring(pool.orchestratorVersion),
+    nodeImageVersion = tostring(pool.nodeImageVersion),
+    vnetSubnetId = tostring(pool.vnetSubnetID)`
+
+// nodeResourceGroupSource selects resources of the given type that live in an AKS node resource group
+func nodeResourceGroupSource(resourceType string) string {
+	return fmt.Sprintf(`resources
+| where type =~ '%s'
+| extend nodeResourceGroup = tolower(resourceGroup)
+| join kind=inner (
+    resources
+    | where type =~ 'microsoft.containerservice/managedclusters'
+    | project clusterName = name, clusterId = id, nodeResourceGroup = tolower(tostring(properties.nodeResourceGroup))
+  ) on nodeResourceGroup`, resourceType)
+}
+
+// queryTemplates contains all curated query templates keyed by name
+var queryTemplates = map[string]QueryTemplate{
+	"clusters": {
+		Name:        "clusters",
+		Description: "List AKS clusters with version, state and network configuration",
+		Parameters:  []TemplateParameter{resourceGroupParam, locationParam},
+		source:      managedClustersSource,
+		projection:  "| project clusterName, resourceGroup, subscriptionId, location, kubernetesVersion, provisioningState, powerState, networkPlugin, outboundType, privateCluster, id\n| order by clusterName asc",
+	},
+	"clusters_by_version": {
+		Name:        "clusters_by_version",
+		Description: "List AKS clusters running a Kubernetes version (e.g. 1.28 matches every 1.28.x patch)",
+		Parameters: []TemplateParameter{
+			{
+				Name:        "kubernetes_version",
+				Description: "Kubernetes minor (1.28) or patch (1.28.5) version",
+				Required:    true,
+				pattern:     kubernetesVersionPattern,
+				filter:      "kubernetesVersion == {value} or kubernetesVersion startswith strcat({value}, '.')",
+			},
+			resourceGroupParam,
+			locationParam,
+		},
+		source:     managedClustersSource,
+		projection: "| project clusterName, resourceGroup, subscriptionId, location, kubernetesVersion, provisioningState, id\n| order by kubernetesVersion asc, clusterName asc",
+	},
+	"failed_clusters": {
+		Name:        "failed_clusters",
+		Description: "List AKS clusters that are not in the Succeeded provisioning state or not Running",
+		Parameters:  []TemplateParameter{resourceGroupParam, locationParam},
+		source:      managedClustersSource + "\n| where provisioningState != 'Succeeded' or powerState != 'Running'",
+		projection:  "| project clusterName, resourceGroup, subscriptionId, location, provisioningState, powerState, kubernetesVersion, id\n| order by clusterName asc",
+	},
+	"private_clusters": {
+		Name:        "private_clusters",
+		Description: "List private AKS clusters and their private API server FQDN",
+		Parameters:  []TemplateParameter{resourceGroupParam, locationParam},
+		source:      managedClustersSource + "\n| where privateCluster == true\n| extend privateFQDN = tostring(properties.privateFQDN), privateDNSZone = tostring(properties.apiServerAccessProfile.privateDNSZone)",
+		projection:  "| project clusterName, resourceGroup, subscriptionId, location, privateFQDN, privateDNSZone, id\n| order by clusterName asc",
+	},
+	"cluster_networking": {
+		Name:        "cluster_networking",
+		Description: "List the network profile of AKS clusters, optionally filtered by network plugin or outbound type",
+		Parameters: []TemplateParameter{
+			{
+				Name:        "network_plugin",
+				Description: "Network plugin: azure, kubenet or none",
+				pattern:     networkPluginPattern,
+				filter:      "networkPlugin =~ {value}",
+			},
+			{
+				Name:        "outbound_type",
+				Description: "Outbound type: loadBalancer, userDefinedRouting, managedNATGateway, userAssignedNATGateway or none",
+				pattern:     outboundTypePattern,
+				filter:      "outboundType =~ {value}",
+			},
+			resourceGroupParam,
+			locationParam,
+		},
+		source:     managedClustersSource + "\n| extend podCidr = tostring(properties.networkProfile.podCidr), serviceCidr = tostring(properties.networkProfile.serviceCidr)",
+		projection: "| project clusterName, resourceGroup, subscriptionId, location, networkPlugin, networkPluginMode, networkPolicy, outboundType, podCidr, serviceCidr, id\n| order by clusterName asc",
+	},
+	"node_pools": {
+		Name:        "node_pools",
+		Description: "List node pools of AKS clusters with size, count, OS and image versions",
+		Parameters: []TemplateParameter{
+			clusterNameParam,
+			{
+				Name:        "node_pool",
+				Description: "Only include node pools with this name",
+				pattern:     nodePoolNamePattern,
+				filter:      "nodePool =~ {value}",
+			},
+			resourceGroupParam,
+		},
+		source:     agentPoolsSource,
+		projection: "| project clusterName, resourceGroup, subscriptionId, nodePool, mode, vmSize, nodeCount, minCount, maxCount, osType, osSKU, orchestratorVersion, nodeImageVersion, vnetSubnetId, id\n| order by clusterName asc, nodePool asc",
+	},
+	"node_pools_by_vm_size": {
+		Name:        "node_pools_by_vm_size",
+		Description: "List node pools using a VM size (e.g. Standard_D4s_v3)",
+		Parameters: []TemplateParameter{
+			{
+				Name:        "vm_size",
+				Description: "VM size of the node pool",
+				Required:    true,
+				pattern:     vmSizePattern,
+				filter:      "vmSize =~ {value}",
+			},
+			resourceGroupParam,
+		},
+		source:     agentPoolsSource,
+		projection: "| project clusterName, resourceGroup, subscriptionId, nodePool, vmSize, nodeCount, osType, orchestratorVersion, id\n| order by clusterName asc, nodePool asc",
+	},
+	"public_ips_in_node_resource_groups": {
+		Name:        "public_ips_in_node_resource_groups",
+		Description: "List public IP addresses in AKS node resource groups",
+		Parameters:  []TemplateParameter{clusterNameParam},
+		source:      nodeResourceGroupSource("microsoft.network/publicipaddresses"),
+		projection:  "| project name, clusterName, nodeResourceGroup, subscriptionId, location, ipAddress = tostring(properties.ipAddress), allocationMethod = tostring(properties.publicIPAllocationMethod), sku = tostring(sku.name), associatedTo = tostring(properties.ipConfiguration.id), id\n| order by clusterName asc, name asc",
+	},
+	"load_balancers_in_node_resource_groups": {
+		Name:        "load_balancers_in_node_resource_groups",
+		Description: "List load balancers in AKS node resource groups with frontend and rule counts",
+		Parameters:  []TemplateParameter{clusterNameParam},
+		source:      nodeResourceGroupSource("microsoft.network/loadbalancers"),
+		projection:  "| project name, clusterName, nodeResourceGroup, subscriptionId, location, sku = tostring(sku.name), frontendCount = array_length(properties.frontendIPConfigurations), ruleCount = array_length(properties.loadBalancingRules), outboundRuleCount = array_length(properties.outboundRules), id\n| order by clusterName asc, name asc",
+	},
+}
+
+// GetQueryTemplates returns all query templates sorted by name
+func GetQueryTemplates() []QueryTemplate {
+	templates := make([]QueryTemplate, 0, len(queryTemplates))
+	for _, template := range queryTemplates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// GetQueryTemplateNames returns the names of all query templates sorted alphabetically
+func GetQueryTemplateNames() []string {
+	names := make([]string, 0, len(queryTemplates))
+	for name := range queryTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuildTemplateQuery renders the named template with the given parameter values.
+// Every value is validated against the parameter pattern and quoted as a KQL string literal.
+func BuildTemplateQuery(name string, values map[string]string) (string, error) {
+	template, ok := queryTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template: %s. Available templates: %s", name, strings.Join(GetQueryTemplateNames(), ", "))
+	}
+
+	known := make(map[string]bool, len(template.Parameters))
+	for _, param := range template.Parameters {
+		known[param.Name] = true
+	}
+	for key := range values {
+		if !known[key] {
+			return "", fmt.Errorf("unknown parameter '%s' for template %s", key, name)
+		}
+	}
+
+	var query strings.Builder
+	query.WriteString(template.source)
+
+	for _, param := range template.Parameters {
+		value := strings.TrimSpace(values[param.Name])
+		if value == "" {
+			if param.Required {
+				return "", fmt.Errorf("missing required parameter '%s' for template %s", param.Name, name)
+			}
+			continue
+		}
+		if !param.pattern.MatchString(value) {
+			return "", fmt.Errorf("invalid value %q for parameter '%s'", value, param.Name)
+		}
+		query.WriteString("\n| where ")
+		query.WriteString(strings.ReplaceAll(param.filter, "{value}", quoteKQLString(value)))
+	}
+
+	query.WriteString("\n")
+	query.WriteString(template.projection)
+
+	return query.String(), nil
+}
+
+// quoteKQLString returns value as a single-quoted KQL string literal
+func quoteKQLString(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `'`, `\'`)
+	return "'" + escaped + "'"
+}
@@ -0,0 +1,134 @@
+package resourcegraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetQueryTemplates_SortedAndComplete(t *testing.T) {
+	templates := GetQueryTemplates()
+	if len(templates) != len(queryTemplates) {
+		t.Fatalf("Expected %d templates, got %d", len(queryTemplates), len(templates))
+	}
+
+	for i := 1; i < len(templates); i++ {
+		if templates[i-1].Name >= templates[i].Name {
+			t.Errorf("Templates not sorted: %s before %s", templates[i-1].Name, templates[i].Name)
+		}
+	}
+
+	for _, template := range templates {
+		if template.Description == "" {
+			t.Errorf("Template %s has no description", template.Name)
+		}
+		for _, param := range template.Parameters {
+			if param.pattern == nil {
+				t.Errorf("Template %s parameter %s has no validation pattern", template.Name, param.Name)
+			}
+			if !strings.Contains(param.filter, "{value}") {
+				t.Errorf("Template %s parameter %s filter does not reference {value}", template.Name, param.Name)
+			}
+		}
+	}
+}
+
+func TestBuildTemplateQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		values      map[string]string
+		wantErr     string
+		wantContain []string
+	}{
+		{
+			name:     "clusters by minor version",
+			template: "clusters_by_version",
+			values:   map[string]string{"kubernetes_version": "1.28"},
+			wantContain: []string{
+				"microsoft.containerservice/managedclusters",
+				"kubernetesVersion == '1.28' or kubernetesVersion startswith strcat('1.28', '.')",
+			},
+		},
+		{
+			name:     "node pools by vm size with resource group",
+			template: "node_pools_by_vm_size",
+			values:   map[string]string{"vm_size": "Standard_D4s_v3", "resource_group": "my-rg"},
+			wantContain: []string{
+				"mv-expand pool = properties.agentPoolProfiles",
+				"| where vmSize =~ 'Standard_D4s_v3'",
+				"| where resourceGroup =~ 'my-rg'",
+			},
+		},
+		{
+			name:     "public IPs without filters",
+			template: "public_ips_in_node_resource_groups",
+			values:   map[string]string{},
+			wantContain: []string{
+				"microsoft.network/publicipaddresses",
+				"join kind=inner",
+			},
+		},
+		{
+			name:     "unknown template",
+			template: "drop_everything",
+			wantErr:  "unknown template",
+		},
+		{
+			name:     "missing required parameter",
+			template: "clusters_by_version",
+			values:   map[string]string{},
+			wantErr:  "missing required parameter 'kubernetes_version'",
+		},
+		{
+			name:     "unknown parameter",
+			template: "clusters",
+			values:   map[string]string{"vm_size": "Standard_D4s_v3"},
+			wantErr:  "unknown parameter 'vm_size'",
+		},
+		{
+			name:     "injection attempt rejected by pattern",
+			template: "node_pools_by_vm_size",
+			values:   map[string]string{"vm_size": "x' or 1==1 //"},
+			wantErr:  "invalid value",
+		},
+		{
+			name:     "invalid version rejected",
+			template: "clusters_by_version",
+			values:   map[string]string{"kubernetes_version": "latest"},
+			wantErr:  "invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := BuildTemplateQuery(tt.template, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(query, want) {
+					t.Errorf("Expected query to contain %q, got:\n%s", want, query)
+				}
+			}
+		})
+	}
+}
+
+func TestQuoteKQLString(t *testing.T) {
+	tests := map[string]string{
+		"plain":     "'plain'",
+		"it's":      `'it\'s'`,
+		`back\last`: `'back\\last'`,
+	}
+	for input, want := range tests {
+		if got := quoteKQLString(input); got != want {
+			t.Errorf("quoteKQLString(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package resourcegraph
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Constants for resource graph query configuration
+const (
+	MaxQueryLength = 4000
+	DefaultTop     = 100
+	MaxTop         = 1000
+)
+
+// allowedTables lists the Resource Graph tables a free-form query may read from
+var allowedTables = []string{
+	"resources",
+	"resourcecontainers",
+	"advisorresources",
+	"healthresources",
+	"kubernetesconfigurationresources",
+	"policyresources",
+	"securityresources",
+	"servicehealthresources",
+	"resourcechanges",
+}
+
+var (
+	// disallowedQueryPattern matches KQL features that reach outside Resource Graph or change query behavior
+	disallowedQueryPattern = regexp.MustCompile(`(?i)\b(externaldata|external_table|evaluate)\b|\b(cluster|database|workspace|app|adx)\s*\(`)
+	// controlCommandPattern matches management commands, which always start with a dot
+	controlCommandPattern = regexp.MustCompile(`(?m)^\s*\.`)
+	// setStatementPattern matches query option statements such as "set notruncation"
+	setStatementPattern = regexp.MustCompile(`(?i)^\s*set\s`)
+	// letStatementPattern matches let statements preceding the tabular expression
+	letStatementPattern = regexp.MustCompile(`(?i)^\s*let\s`)
+	// leadingIdentifierPattern captures the first identifier of a tabular expression
+	leadingIdentifierPattern = regexp.MustCompile(`^\s*\(?\s*([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// ValidateReadOnlyQuery validates a free-form KQL query for the query operation.
+// Only tabular expressions over Resource Graph tables are allowed, optionally preceded by let statements.
+func ValidateReadOnlyQuery(query string) error {
+	trimmed := strings.TrimSpace(query)
+	if trimmed == "" {
+		return fmt.Errorf("query cannot be empty")
+	}
+	if len(trimmed) > MaxQueryLength {
+		return fmt.Errorf("query cannot exceed %d characters, got %d", MaxQueryLength, len(trimmed))
+	}
+	if controlCommandPattern.MatchString(trimmed) {
+		return fmt.Errorf("management commands are not allowed")
+	}
+	if match := disallowedQueryPattern.FindString(trimmed); match != "" {
+		return fmt.Errorf("query uses disallowed operator or function: %s", strings.TrimRight(match, " \t("))
+	}
+
+	statements := strings.Split(strings.TrimRight(trimmed, "; \n\t"), ";")
+	for _, statement := range statements[:len(statements)-1] {
+		if setStatementPattern.MatchString(statement) {
+			return fmt.Errorf("set statements are not allowed")
+		}
+		if !letStatementPattern.MatchString(statement) {
+			return fmt.Errorf("only let statements may precede the query expression")
+		}
+	}
+
+	expression := statements[len(statements)-1]
+	match := leadingIdentifierPattern.FindStringSubmatch(expression)
+	if match == nil {
+		return fmt.Errorf("query must start with a Resource Graph table name")
+	}
+	if !slices.Contains(allowedTables, strings.ToLower(match[1])) {
+		return fmt.Errorf("table '%s' is not allowed. Allowed tables: %s", match[1], strings.Join(allowedTables, ", "))
+	}
+
+	return nil
+}
+
+// GetTop extracts and clamps the top parameter
+func GetTop(params map[string]interface{}) int32 {
+	top, ok := params["top"].(float64)
+	if !ok || top < 1 {
+		return DefaultTop
+	}
+	if top > MaxTop {
+		return MaxTop
+	}
+	return int32(top)
+}
@@ -0,0 +1,63 @@
+package resourcegraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{name: "simple resources query", query: "resources | where type =~ 'microsoft.containerservice/managedclusters' | project name"},
+		{name: "mixed case table", query: "Resources | summarize count() by type"},
+		{name: "resource containers", query: "resourcecontainers | where type =~ 'microsoft.resources/subscriptions'"},
+		{name: "let statement", query: "let rg = 'my-rg';\nresources | where resourceGroup == rg"},
+		{name: "trailing semicolon", query: "resources | take 5;"},
+		{name: "empty", query: "   ", wantErr: "cannot be empty"},
+		{name: "too long", query: "resources | where name == '" + strings.Repeat("a", MaxQueryLength) + "'", wantErr: "cannot exceed"},
+		{name: "control command", query: ".show tables", wantErr: "management commands"},
+		{name: "control command after newline", query: "resources\n.drop table x", wantErr: "management commands"},
+		{name: "externaldata", query: "externaldata (x:string) ['https://example.com']", wantErr: "externaldata"},
+		{name: "cross cluster", query: "resources | union cluster('other').database('db').T", wantErr: "cluster"},
+		{name: "set statement", query: "set notruncation; resources", wantErr: "set statements"},
+		{name: "non-let statement before query", query: "resources; resources", wantErr: "only let statements"},
+		{name: "unknown table", query: "SecurityEvent | take 10", wantErr: "not allowed"},
+		{name: "no table", query: "| take 10", wantErr: "must start with"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReadOnlyQuery(tt.query)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetTop(t *testing.T) {
+	tests := []struct {
+		params map[string]interface{}
+		want   int32
+	}{
+		{map[string]interface{}{}, DefaultTop},
+		{map[string]interface{}{"top": float64(25)}, 25},
+		{map[string]interface{}{"top": float64(0)}, DefaultTop},
+		{map[string]interface{}{"top": float64(5000)}, MaxTop},
+		{map[string]interface{}{"top": "10"}, DefaultTop},
+	}
+	for _, tt := range tests {
+		if got := GetTop(tt.params); got != tt.want {
+			t.Errorf("GetTop(%v) = %d, want %d", tt.params, got, tt.want)
+		}
+	}
+}
@@ -30,6 +30,9 @@ type ConfigData struct {
 	TelemetryService     *telemetry.Service
 	UseLegacyTools       bool
 	DefaultAKSResourceID string
+	AllowedSubscriptions []string
+	ServiceTagsFile      string
+	EgressRulesFile      string
 }
 
 // NewConfig creates and returns a new configuration instance.
@@ -49,9 +52,12 @@ func NewConfig() *ConfigData {
 func (cfg *ConfigData) ParseFlags() {
 	flag.IntVar(&cfg.Timeout, "timeout", 600, "Timeout for command execution in seconds, default is 600s")
 	flag.StringVar(&cfg.AccessLevel, "access-level", "readonly", "Access level (readonly, readwrite, admin)")
-	enabledComponents := flag.String("enabled-components", "", "Comma-separated list of enabled components (empty means all components enabled). Available: az_cli,monitor,fleet,network,compute,detectors,advisor,inspektorgadget,kubectl,helm,cilium,hubble")
+	enabledComponents := flag.String("enabled-components", "", "Comma-separated list of enabled components (empty means all components enabled). Available: az_cli,monitor,fleet,network,compute,detectors,advisor,resourcegraph,inspektorgadget,kubectl,helm,cilium,hubble")
 	flag.StringVar(&cfg.AllowNamespaces, "allow-namespaces", "", "Comma-separated list of allowed Kubernetes namespaces (empty means all namespaces)")
+	allowedSubscriptions := flag.String("allowed-subscriptions", "", "Comma-separated list of Azure subscription IDs that cross-subscription tools (e.g. aks_resource_graph) may query. Empty means unrestricted.")
 	flag.StringVar(&cfg.DefaultAKSResourceID, "default-aks-resource-id", "", "Default AKS cluster resource ID used when aks_resource_id is not supplied by the caller (e.g. /subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.ContainerService/managedClusters/{cluster}). Falls back to AZURE_AKS_RESOURCE_ID env var.")
+	flag.StringVar(&cfg.ServiceTagsFile, "service-tags-file", "", "Path to a local Azure IP Ranges and Service Tags JSON file used to resolve service tags in network flow checks. Reloaded when the file changes. Falls back to AZURE_SERVICE_TAGS_FILE env var.")
+	flag.StringVar(&cfg.EgressRulesFile, "egress-rules-file", "", "Path to a JSON file overriding the required AKS egress rules embedded in the binary, used by egress conformance checks. Reloaded when the file changes. Falls back to AZURE_EGRESS_RULES_FILE env var.")
 	flag.StringVar(&cfg.LogLevel, "log-level", "info", "Log level (debug, info, warn, error)")
 	flag.StringVar(&cfg.OTLPEndpoint, "otlp-endpoint", "", "OTLP endpoint for OpenTelemetry traces (e.g. localhost:4317)")
 
@@ -79,6 +85,17 @@ func (cfg *ConfigData) ParseFlags() {
 	if cfg.DefaultAKSResourceID == "" {
 		cfg.DefaultAKSResourceID = os.Getenv("AZURE_AKS_RESOURCE_ID")
 	}
+	if cfg.ServiceTagsFile == "" {
+		cfg.ServiceTagsFile = os.Getenv("AZURE_SERVICE_TAGS_FILE")
+	}
+	if cfg.EgressRulesFile == "" {
+		cfg.EgressRulesFile = os.Getenv("AZURE_EGRESS_RULES_FILE")
+	}
+	for _, subscription := range strings.Split(*allowedSubscriptions, ",") {
+		if subscription = strings.TrimSpace(subscription); subscription != "" {
+			cfg.AllowedSubscriptions = append(cfg.AllowedSubscriptions, subscription)
+		}
+	}
 	if *enabledComponents != "" {
 		for _, component := range strings.Split(*enabledComponents, ",") {
 			if component = strings.TrimSpace(component); component != "" {
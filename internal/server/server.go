@@ -18,6 +18,7 @@ import (
 	"github.com/Azure/aks-mcp/internal/components/inspektorgadget"
 	"github.com/Azure/aks-mcp/internal/components/monitor"
 	"github.com/Azure/aks-mcp/internal/components/network"
+	"github.com/Azure/aks-mcp/internal/components/resourcegraph"
 	"github.com/Azure/aks-mcp/internal/config"
 	"github.com/Azure/aks-mcp/internal/k8s"
 	"github.com/Azure/aks-mcp/internal/logger"
@@ -156,7 +157,7 @@ func (s *Service) Run() error {
 	return server.ServeStdio(s.mcpServer)
 }
 
-// registerAzureComponents registers all Azure tools (AKS operations, monitoring, fleet, network, compute, detectors, advisor)
+// registerAzureComponents registers all Azure tools (AKS operations, monitoring, fleet, network, compute, detectors, advisor, resource graph)
 func (s *Service) registerAzureComponents() {
 	logger.Infof("Registering Azure Components...")
 
@@ -201,6 +202,11 @@ func (s *Service) registerAzureComponents() {
 		s.registerAdvisorComponent()
 	}
 
+	// Azure Resource Graph Component
+	if components.IsComponentEnabled("resourcegraph", s.cfg.EnabledComponents) {
+		s.registerResourceGraphComponent()
+	}
+
 	// Register Inspektor Gadget tools for observability
 	if components.IsComponentEnabled("inspektorgadget", s.cfg.EnabledComponents) {
 		s.registerInspektorGadgetComponent()
@@ -317,7 +323,14 @@ func (s *Service) registerFleetComponent() {
 func (s *Service) registerAdvisorComponent() {
 	logger.Debugf("Registering advisor tool: aks_advisor_recommendation")
 	advisorTool := advisor.RegisterAdvisorRecommendationTool()
-	s.mcpServer.AddTool(advisorTool, tools.CreateResourceHandler(advisor.GetAdvisorRecommendationHandler(s.cfg), s.cfg))
+	s.mcpServer.AddTool(advisorTool, tools.CreateResourceHandler(advisor.GetAdvisorRecommendationHandler(s.azClient, s.cfg), s.cfg))
+}
+
+// registerResourceGraphComponent registers Azure Resource Graph inventory tools
+func (s *Service) registerResourceGraphComponent() {
+	logger.Debugf("Registering resource graph tool: aks_resource_graph")
+	resourceGraphTool := resourcegraph.RegisterAksResourceGraphTool()
+	s.mcpServer.AddTool(resourceGraphTool, tools.CreateResourceHandler(resourcegraph.GetAksResourceGraphHandler(s.azClient, s.cfg), s.cfg))
 }
 
 // registerNetworkComponent registers network-related Azure resource tools
@@ -328,6 +341,11 @@ func (s *Service) registerNetworkComponent() {
 	logger.Debugf("Registering network tool: aks_network_resources")
 	networkTool := network.RegisterAksNetworkResources()
 	s.mcpServer.AddTool(networkTool, tools.CreateResourceHandler(network.GetAksNetworkResourcesHandler(s.azClient, s.cfg), s.cfg))
+
+	// Register network diagnostics tool
+	logger.Debugf("Registering network tool: aks_network_diagnostics")
+	networkDiagnosticsTool := network.RegisterAksNetworkDiagnostics()
+	s.mcpServer.AddTool(networkDiagnosticsTool, tools.CreateResourceHandler(network.GetAksNetworkDiagnosticsHandler(s.azClient, s.cfg), s.cfg))
 }
 
 // registerComputeComponent registers compute-related Azure resource tools (VMSS/VM)
@@ -344,6 +362,25 @@ func (s *Service) registerComputeComponent() {
 	nodeLogsTool := compute.RegisterCollectAKSNodeLogsTool()
 	s.mcpServer.AddTool(nodeLogsTool, tools.CreateResourceHandler(compute.CollectAKSNodeLogsHandler(s.azClient, s.cfg), s.cfg))
 
+	// Register AKS node diagnostics tool
+	logger.Debugf("Registering compute tool: collect_aks_node_diagnostics")
+	nodeDiagnosticsTool := compute.RegisterCollectAKSNodeDiagnosticsTool()
+	s.mcpServer.AddTool(nodeDiagnosticsTool, tools.CreateResourceHandler(compute.CollectAKSNodeDiagnosticsHandler(s.azClient, s.cfg), s.cfg))
+
+	// Register run command job tools used by the async mode of the node logs and diagnostics tools
+	logger.Debugf("Registering compute tool: get_job_status")
+	jobStatusTool := compute.RegisterGetJobStatusTool()
+	s.mcpServer.AddTool(jobStatusTool, tools.CreateResourceHandler(compute.GetJobStatusHandler(s.cfg), s.cfg))
+
+	logger.Debugf("Registering compute tool: get_job_result")
+	jobResultTool := compute.RegisterGetJobResultTool()
+	s.mcpServer.AddTool(jobResultTool, tools.CreateResourceHandler(compute.GetJobResultHandler(s.cfg), s.cfg))
+
+	// Register AKS node pool diagnostics tool
+	logger.Debugf("Registering compute tool: aks_node_pool_diagnostics")
+	nodePoolDiagnosticsTool := compute.RegisterAksNodePoolDiagnostics()
+	s.mcpServer.AddTool(nodePoolDiagnosticsTool, tools.CreateResourceHandler(compute.GetAksNodePoolDiagnosticsHandler(s.azClient, s.cfg), s.cfg))
+
 	// Register unified compute operations tool (only if using legacy tools)
 	if s.cfg.UseLegacyTools {
 		logger.Debugf("Registering compute tool: az_compute_operations")